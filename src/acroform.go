@@ -0,0 +1,984 @@
+package pdfjet
+
+/**
+ * acroform.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/edragoev1/pdfjet/src/compressor"
+)
+
+// FormField is field of the interactive form (AcroForm) of PDF read by PDF.Read.
+type FormField struct {
+	Name     string   // The fully qualified name - the partial names of the field and its ancestors joined with periods
+	Type     string   // Text, CheckBox, RadioButton, ComboBox, ListBox, PushButton or Signature
+	Value    string   // The text, the selected choice or the on state of the button - Off if the button is not on
+	Options  []string // The export values of the choices or the on states of the check box or radio button
+	ReadOnly bool
+	ref      pdfRef   // The terminal field object
+	widgets  []pdfRef // The widget annotations - the field object itself if the field has single widget merged with it
+	attrs    PDFDict  // The field attributes including the inherited ones
+}
+
+// The field flags.
+const (
+	fieldReadOnly   = 1 << 0
+	fieldRequired   = 1 << 1
+	fieldMultiline  = 1 << 12
+	fieldPassword   = 1 << 13
+	fieldNoToggle   = 1 << 14
+	fieldRadio      = 1 << 15
+	fieldPushButton = 1 << 16
+	fieldCombo      = 1 << 17
+	fieldEdit       = 1 << 18
+	fieldComb       = 1 << 24
+)
+
+// The inheritable field attributes. The choice options are not inheritable but are read with them.
+var inheritableFieldKeys = []string{"FT", "Ff", "V", "DV", "DA", "Q", "MaxLen", "Opt"}
+
+// GetFormFields returns the terminal fields of the interactive form of PDF read by PDF.Read.
+// The program exits if the form cannot be read. Use GetFormFieldsErr to handle the error.
+func (pdf *PDF) GetFormFields(objects []*PDFobj) []*FormField {
+	fields, err := pdf.GetFormFieldsErr(objects)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return fields
+}
+
+// GetFormFieldsErr returns the terminal fields of the interactive form of PDF read by PDF.Read
+// in the order of the form field tree. Returns empty list if the PDF has no interactive form.
+// Returns MalformedInputError if the form cannot be read.
+func (pdf *PDF) GetFormFieldsErr(objects []*PDFobj) (_ []*FormField, err error) {
+	defer recoverMalformedInput("PDF", &err)
+	return newAcroForm(&objects).fields, nil
+}
+
+// SetFormFieldValue sets the value of the form field.
+// The program exits if the value cannot be set. Use SetFormFieldValueErr to handle the error.
+func (pdf *PDF) SetFormFieldValue(name, value string, objects *[]*PDFobj) {
+	if err := pdf.SetFormFieldValueErr(name, value, objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// SetFormFieldValueErr sets the value of the form field with the fully qualified name
+// and regenerates the appearance streams of its widget annotations.
+// The value of text field is the text, the value of combo box or list box is
+// the export value or the text of the option and the value of check box or radio button
+// is one of its on states or Off. The appearance uses the font of the field default appearance
+// or Helvetica if that font cannot show the text. The XFA form is removed from the interactive form,
+// so that the viewers show the changed AcroForm fields. Use PDF.AddObjects to write the objects.
+// Returns UnsupportedFeatureError if no font can show the text and
+// MalformedInputError if the form cannot be read.
+func (pdf *PDF) SetFormFieldValueErr(name, value string, objects *[]*PDFobj) (err error) {
+	defer recoverMalformedInput("PDF", &err)
+
+	form := newAcroForm(objects)
+	var field *FormField
+	for _, f := range form.fields {
+		if f.Name == name {
+			field = f
+			break
+		}
+	}
+	if field == nil {
+		return fmt.Errorf("pdfjet: form field %s not found", name)
+	}
+	switch field.Type {
+	case "Text":
+		err = form.setText(field, value)
+	case "CheckBox", "RadioButton":
+		err = form.setButton(field, value)
+	case "ComboBox", "ListBox":
+		err = form.setChoice(field, value)
+	default:
+		return fmt.Errorf("pdfjet: cannot set value of %s field %s", field.Type, name)
+	}
+	if err != nil {
+		return err
+	}
+	if _, ok := form.dict["XFA"]; ok {
+		acroForm := form.catalog.getAcroForm(objects)
+		acroForm.dict = removeKey(acroForm.dict, "/XFA")
+	}
+	return nil
+}
+
+// FlattenFormFields draws the form fields into the page content and removes the interactive form.
+// The program exits if the fields cannot be flattened. Use FlattenFormFieldsErr to handle the error.
+func (pdf *PDF) FlattenFormFields(objects *[]*PDFobj) {
+	if err := pdf.FlattenFormFieldsErr(objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// FlattenFormFieldsErr draws the normal appearances of the visible widget annotations
+// into the page content, removes the widget annotations from the pages and
+// removes the interactive form from the document catalog. Use PDF.AddObjects to write the objects.
+// Returns MalformedInputError if the form cannot be read.
+func (pdf *PDF) FlattenFormFieldsErr(objects *[]*PDFobj) (err error) {
+	defer recoverMalformedInput("PDF", &err)
+
+	form := newAcroForm(objects)
+	for _, obj := range *objects {
+		if isPageObject(obj) {
+			form.flattenPage(obj)
+		}
+	}
+	if form.catalog != nil {
+		form.catalog.dict = removeKey(form.catalog.dict, "/AcroForm")
+	}
+	return nil
+}
+
+// acroForm reads and changes the interactive form of PDF read by PDF.Read.
+type acroForm struct {
+	o         *pdfObjects
+	objects   *[]*PDFobj
+	catalog   *PDFobj
+	dict      PDFDict // The interactive form dictionary
+	fields    []*FormField
+	fonts     map[string]*textFont
+	coreFonts map[string]pdfRef // The fonts added for the appearance streams
+}
+
+func newAcroForm(objects *[]*PDFobj) *acroForm {
+	form := &acroForm{
+		o:         newPDFObjects(*objects),
+		objects:   objects,
+		fonts:     make(map[string]*textFont),
+		coreFonts: make(map[string]pdfRef),
+	}
+	for _, obj := range *objects {
+		if obj.getValue("/Type") == "/Catalog" {
+			form.catalog = obj
+			catalog, _ := form.o.getValue(obj).(PDFDict)
+			form.dict = form.o.getDict(catalog["AcroForm"])
+			break
+		}
+	}
+	visited := make(map[pdfRef]bool)
+	for _, field := range form.o.getArray(form.dict["Fields"]) {
+		form.readField(field, "", PDFDict{}, visited)
+	}
+	return form
+}
+
+// readField reads the field and its descendants. The kids without partial name are widget annotations.
+func (form *acroForm) readField(value any, parentName string, inherited PDFDict, visited map[pdfRef]bool) {
+	o := form.o
+	ref, ok := value.(pdfRef)
+	if !ok || visited[ref] {
+		return
+	}
+	visited[ref] = true
+	dict := o.getDict(ref)
+	if dict == nil {
+		return
+	}
+	name := parentName
+	if partialName, ok := o.resolve(dict["T"]).(PDFString); ok {
+		if name != "" {
+			name += "."
+		}
+		name += decodeTextString(partialName)
+	}
+	attrs := make(PDFDict, len(inheritableFieldKeys))
+	for key, value := range inherited {
+		attrs[key] = value
+	}
+	for _, key := range inheritableFieldKeys {
+		if value, ok := dict[key]; ok {
+			attrs[key] = value
+		}
+	}
+	kids := o.getArray(dict["Kids"])
+	widgets := make([]pdfRef, 0, len(kids))
+	for _, kid := range kids {
+		if _, ok := o.getDict(kid)["T"]; ok {
+			form.readField(kid, name, attrs, visited)
+		} else if widget, ok := kid.(pdfRef); ok {
+			widgets = append(widgets, widget)
+		}
+	}
+	if len(kids) == 0 {
+		widgets = append(widgets, ref)
+	}
+	if len(widgets) == 0 {
+		return
+	}
+
+	field := &FormField{Name: name, ref: ref, widgets: widgets, attrs: attrs}
+	flags := int(o.getNumber(attrs["Ff"], 0.0))
+	field.ReadOnly = flags&fieldReadOnly != 0
+	switch o.getName(attrs["FT"]) {
+	case "Tx":
+		field.Type = "Text"
+	case "Btn":
+		if flags&fieldPushButton != 0 {
+			field.Type = "PushButton"
+		} else if flags&fieldRadio != 0 {
+			field.Type = "RadioButton"
+		} else {
+			field.Type = "CheckBox"
+		}
+	case "Ch":
+		if flags&fieldCombo != 0 {
+			field.Type = "ComboBox"
+		} else {
+			field.Type = "ListBox"
+		}
+	case "Sig":
+		field.Type = "Signature"
+	default:
+		return
+	}
+	field.Value = form.getText(attrs["V"])
+	switch field.Type {
+	case "CheckBox", "RadioButton":
+		for _, widget := range widgets {
+			for _, state := range form.getStates(widget) {
+				if state != "Off" && !contains(field.Options, state) {
+					field.Options = append(field.Options, state)
+				}
+			}
+			if state := o.getName(o.getDict(widget)["AS"]); field.Value == "" && state != "Off" {
+				field.Value = state
+			}
+		}
+		if field.Value == "" {
+			field.Value = "Off"
+		}
+	case "ComboBox", "ListBox":
+		for _, option := range form.getOptions(attrs) {
+			field.Options = append(field.Options, option[0])
+		}
+	}
+	form.fields = append(form.fields, field)
+}
+
+// getText returns the text of text string or name value. The first element of array is used.
+func (form *acroForm) getText(value any) string {
+	switch value := form.o.resolve(value).(type) {
+	case PDFString:
+		return decodeTextString(value)
+	case PDFName:
+		return string(value)
+	case []any:
+		if len(value) > 0 {
+			return form.getText(value[0])
+		}
+	}
+	return ""
+}
+
+// getOptions returns the export value and the text of the choice field options.
+func (form *acroForm) getOptions(attrs PDFDict) [][2]string {
+	options := make([][2]string, 0)
+	for _, option := range form.o.getArray(attrs["Opt"]) {
+		if pair := form.o.getArray(option); len(pair) == 2 {
+			options = append(options, [2]string{form.getText(pair[0]), form.getText(pair[1])})
+		} else {
+			text := form.getText(option)
+			options = append(options, [2]string{text, text})
+		}
+	}
+	return options
+}
+
+// getStates returns the names of the normal appearance states of the widget annotation.
+func (form *acroForm) getStates(widget pdfRef) []string {
+	o := form.o
+	states := o.getDict(o.getDict(o.getDict(widget)["AP"])["N"])
+	if _, ok := states["BBox"]; ok {
+		return nil // The appearance stream without states
+	}
+	return getSortedKeys(states)
+}
+
+// setEntry sets the value of the key in the object dictionary or removes the key if the value is nil.
+func (form *acroForm) setEntry(ref pdfRef, key string, value any) {
+	obj := form.o.getObject(int(ref))
+	if value == nil {
+		obj.dict = removeKey(obj.dict, "/"+key)
+	} else {
+		obj.dict = setValueTokens(obj.dict, "/"+key, appendPDFTokens(nil, value))
+	}
+	delete(form.o.values, obj.number)
+}
+
+// newObject adds new object with the dictionary tokens.
+func (form *acroForm) newObject(dict []string) *PDFobj {
+	obj := NewPDFobj()
+	obj.dict = dict
+	obj.number = len(*form.objects) + 1
+	*form.objects = append(*form.objects, obj)
+	form.o.objects = *form.objects
+	return obj
+}
+
+// newStream adds new compressed stream object.
+func (form *acroForm) newStream(dict PDFDict, data []byte) pdfRef {
+	compressed := compressor.Deflate(data)
+	dict["Filter"] = PDFName("FlateDecode")
+	dict["Length"] = float64(len(compressed))
+	obj := form.newObject(appendPDFTokens(nil, dict))
+	obj.stream = compressed
+	obj.data = data
+	return pdfRef(obj.number)
+}
+
+func (form *acroForm) setText(field *FormField, value string) error {
+	maxLen := int(form.o.getNumber(field.attrs["MaxLen"], 0.0))
+	if maxLen > 0 && utf8.RuneCountInString(value) > maxLen {
+		return fmt.Errorf("pdfjet: the value of field %s is longer than %d characters", field.Name, maxLen)
+	}
+	for _, widget := range field.widgets {
+		if err := form.writeTextAppearance(field, widget, value); err != nil {
+			return err
+		}
+	}
+	form.setEntry(field.ref, "V", encodeTextString(value))
+	form.setEntry(field.ref, "RV", nil) // The rich text value would be shown instead of the value
+	field.Value = value
+	return nil
+}
+
+func (form *acroForm) setChoice(field *FormField, value string) error {
+	options := form.getOptions(field.attrs)
+	index := -1
+	for i, option := range options {
+		if option[0] == value {
+			index = i
+			break
+		}
+	}
+	for i, option := range options {
+		if index == -1 && option[1] == value {
+			index = i
+		}
+	}
+	flags := int(form.o.getNumber(field.attrs["Ff"], 0.0))
+	if index == -1 && (field.Type != "ComboBox" || flags&fieldEdit == 0) {
+		return fmt.Errorf("pdfjet: %s is not option of field %s", value, field.Name)
+	}
+	export, text := value, value
+	if index != -1 {
+		export, text = options[index][0], options[index][1]
+	}
+	for _, widget := range field.widgets {
+		var err error
+		if field.Type == "ComboBox" {
+			err = form.writeTextAppearance(field, widget, text)
+		} else {
+			err = form.writeListAppearance(field, widget, options, index)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if index != -1 {
+		form.setEntry(field.ref, "I", []any{float64(index)})
+	} else {
+		form.setEntry(field.ref, "I", nil)
+	}
+	form.setEntry(field.ref, "V", encodeTextString(export))
+	field.Value = export
+	return nil
+}
+
+func (form *acroForm) setButton(field *FormField, value string) error {
+	if value != "Off" && len(field.Options) > 0 && !contains(field.Options, value) {
+		return fmt.Errorf("pdfjet: %s is not state of field %s", value, field.Name)
+	}
+	form.setEntry(field.ref, "V", PDFName(value))
+	for _, widget := range field.widgets {
+		states := form.getStates(widget)
+		if len(states) == 0 && value != "Off" {
+			form.writeButtonAppearance(field, widget, value)
+			states = append(states, value)
+		}
+		if contains(states, value) {
+			form.setEntry(widget, "AS", PDFName(value))
+		} else {
+			form.setEntry(widget, "AS", PDFName("Off"))
+		}
+	}
+	field.Value = value
+	return nil
+}
+
+// appearance is the normal appearance stream of widget annotation.
+// The bounding box is the annotation rectangle rotated by the MK /R rotation.
+type appearance struct {
+	form       *acroForm
+	width      float64
+	height     float64
+	border     float64 // The border width or 0.0 if the border is not drawn
+	rotation   int
+	fontName   string  // The default appearance font
+	fontSize   float64 // The default appearance font size - 0.0 for auto size
+	color      *ContentOperation
+	fonts      PDFDict // The fonts used by the appearance
+	operations []*ContentOperation
+}
+
+// newAppearance returns the appearance with the widget background and border.
+// Returns nil if the widget has no rectangle.
+func (form *acroForm) newAppearance(field *FormField, widget PDFDict) *appearance {
+	o := form.o
+	rect := o.getArray(widget["Rect"])
+	if len(rect) != 4 {
+		return nil
+	}
+	a := &appearance{form: form, fonts: PDFDict{}, color: NewContentOperation("g", 0.0)}
+	a.width = math.Abs(o.getNumber(rect[2], 0.0) - o.getNumber(rect[0], 0.0))
+	a.height = math.Abs(o.getNumber(rect[3], 0.0) - o.getNumber(rect[1], 0.0))
+	mk := o.getDict(widget["MK"])
+	a.rotation = (int(o.getNumber(mk["R"], 0.0))%360 + 360) % 360
+	if a.rotation == 90 || a.rotation == 270 {
+		a.width, a.height = a.height, a.width
+	}
+
+	da := widget["DA"]
+	if da == nil {
+		da = field.attrs["DA"]
+	}
+	if da == nil {
+		da = form.dict["DA"]
+	}
+	if str, ok := o.resolve(da).(PDFString); ok {
+		if stream, err := NewContentStreamErr(str); err == nil {
+			for _, op := range stream.Operations {
+				switch op.Operator {
+				case "Tf":
+					a.fontName = op.GetName(0)
+					a.fontSize = op.GetNumber(1)
+				case "g", "rg", "k":
+					a.color = op
+				}
+			}
+		}
+	}
+
+	if color := getColorOperation(o, mk["BG"], false); color != nil {
+		a.operations = append(a.operations,
+			color,
+			NewContentOperation("re", 0.0, 0.0, roundCoordinate(a.width), roundCoordinate(a.height)),
+			NewContentOperation("f"))
+	}
+	if color := getColorOperation(o, mk["BC"], true); color != nil {
+		a.border = 1.0
+		if bs := o.getDict(widget["BS"]); bs != nil {
+			a.border = o.getNumber(bs["W"], 1.0)
+		} else if border := o.getArray(widget["Border"]); len(border) >= 3 {
+			a.border = o.getNumber(border[2], 1.0)
+		}
+		if a.border > 0.0 {
+			a.operations = append(a.operations,
+				color,
+				NewContentOperation("w", roundCoordinate(a.border)),
+				NewContentOperation("re",
+					roundCoordinate(a.border/2.0), roundCoordinate(a.border/2.0),
+					roundCoordinate(a.width-a.border), roundCoordinate(a.height-a.border)),
+				NewContentOperation("S"))
+		}
+	}
+	return a
+}
+
+// getColorOperation returns the color operation of the MK color array or nil if the color is transparent.
+func getColorOperation(o *pdfObjects, value any, stroke bool) *ContentOperation {
+	components := o.getArray(value)
+	operands := make([]any, len(components))
+	for i, component := range components {
+		operands[i] = o.getNumber(component, 0.0)
+	}
+	operators := map[int]string{1: "g", 3: "rg", 4: "k"}
+	operator, ok := operators[len(operands)]
+	if !ok {
+		return nil
+	}
+	if stroke {
+		operator = strings.ToUpper(operator)
+	}
+	return NewContentOperation(operator, operands...)
+}
+
+// getFont returns the name and the font of the appearance that can show the texts.
+func (a *appearance) getFont(texts ...string) (string, *textFont, error) {
+	o := a.form.o
+	fonts := o.getDict(o.getDict(a.form.dict["DR"])["Font"])
+	if value, ok := fonts[a.fontName]; ok {
+		font := a.form.getTextFont(a.fontName, value)
+		if canEncode(font, texts) {
+			a.fonts[a.fontName] = value
+			return a.fontName, font, nil
+		}
+	}
+	font := a.form.getCoreFont("Helvetica")
+	if canEncode(font, texts) {
+		a.fonts["Helv"] = a.form.addCoreFont("Helvetica")
+		return "Helv", font, nil
+	}
+	return "", nil, unsupported("form field text %q that the font %s cannot show", strings.Join(texts, " "), a.fontName)
+}
+
+func canEncode(font *textFont, texts []string) bool {
+	for _, text := range texts {
+		if _, ok := font.encode(text); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// getTextFont returns the font of the default resources.
+func (form *acroForm) getTextFont(name string, value any) *textFont {
+	if font, ok := form.fonts[name]; ok {
+		return font
+	}
+	font := newTextFont(form.o, form.o.getDict(value))
+	form.fonts[name] = font
+	return font
+}
+
+// getCoreFont returns the standard font used when the default resources have no suitable font.
+func (form *acroForm) getCoreFont(name string) *textFont {
+	return form.getTextFont("/"+name, getCoreFontDict(name)) // The resource names do not start with slash
+}
+
+// addCoreFont adds the standard font object used by the appearance streams.
+func (form *acroForm) addCoreFont(name string) pdfRef {
+	ref, ok := form.coreFonts[name]
+	if !ok {
+		ref = pdfRef(form.newObject(appendPDFTokens(nil, getCoreFontDict(name))).number)
+		form.coreFonts[name] = ref
+	}
+	return ref
+}
+
+func getCoreFontDict(name string) PDFDict {
+	dict := PDFDict{"Type": PDFName("Font"), "Subtype": PDFName("Type1"), "BaseFont": PDFName(name)}
+	if name != "ZapfDingbats" {
+		dict["Encoding"] = PDFName("WinAnsiEncoding")
+	}
+	return dict
+}
+
+// showText adds the text operations.
+func (a *appearance) showText(font *textFont, name string, size float64, lines []string, positions [][2]float64) {
+	a.operations = append(a.operations,
+		NewContentOperation("BT"),
+		NewContentOperation("Tf", PDFName(name), roundCoordinate(size)),
+		a.color)
+	for i, line := range lines {
+		str, _ := font.encode(line)
+		a.operations = append(a.operations,
+			NewContentOperation("Tm", 1.0, 0.0, 0.0, 1.0, roundCoordinate(positions[i][0]), roundCoordinate(positions[i][1])),
+			NewContentOperation("Tj", PDFString(str)))
+	}
+	a.operations = append(a.operations, NewContentOperation("ET"))
+}
+
+// beginText begins the marked content of the variable text clipped inside the border.
+func (a *appearance) beginText() {
+	inset := a.border + 1.0
+	a.operations = append(a.operations,
+		NewContentOperation("BMC", PDFName("Tx")),
+		NewContentOperation("q"),
+		NewContentOperation("re",
+			roundCoordinate(inset), roundCoordinate(inset),
+			roundCoordinate(math.Max(a.width-2.0*inset, 0.0)), roundCoordinate(math.Max(a.height-2.0*inset, 0.0))),
+		NewContentOperation("W"),
+		NewContentOperation("n"))
+}
+
+func (a *appearance) endText() {
+	a.operations = append(a.operations, NewContentOperation("Q"), NewContentOperation("EMC"))
+}
+
+// write adds the appearance stream object.
+func (a *appearance) write() pdfRef {
+	dict := PDFDict{
+		"Type":    PDFName("XObject"),
+		"Subtype": PDFName("Form"),
+		"BBox":    []any{0.0, 0.0, roundCoordinate(a.width), roundCoordinate(a.height)},
+	}
+	switch a.rotation {
+	case 90:
+		dict["Matrix"] = []any{0.0, 1.0, -1.0, 0.0, 0.0, 0.0}
+	case 180:
+		dict["Matrix"] = []any{-1.0, 0.0, 0.0, -1.0, 0.0, 0.0}
+	case 270:
+		dict["Matrix"] = []any{0.0, -1.0, 1.0, 0.0, 0.0, 0.0}
+	}
+	if len(a.fonts) > 0 {
+		dict["Resources"] = PDFDict{"Font": a.fonts}
+	}
+	return a.form.newStream(dict, (&ContentStream{Operations: a.operations}).Bytes())
+}
+
+// getQuadding returns the alignment of the variable text - 0 left, 1 centered and 2 right.
+func (form *acroForm) getQuadding(field *FormField, widget PDFDict) int {
+	for _, value := range []any{widget["Q"], field.attrs["Q"], form.dict["Q"]} {
+		if value != nil {
+			return int(form.o.getNumber(value, 0.0))
+		}
+	}
+	return 0
+}
+
+// writeTextAppearance writes the appearance of text field or combo box with the text.
+func (form *acroForm) writeTextAppearance(field *FormField, widget pdfRef, text string) error {
+	dict := form.o.getDict(widget)
+	a := form.newAppearance(field, dict)
+	if a == nil {
+		return nil
+	}
+	flags := int(form.o.getNumber(field.attrs["Ff"], 0.0))
+	maxLen := int(form.o.getNumber(field.attrs["MaxLen"], 0.0))
+	multiline := field.Type == "Text" && flags&fieldMultiline != 0
+	if field.Type == "Text" && flags&fieldPassword != 0 {
+		text = strings.Repeat("*", utf8.RuneCountInString(text))
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	if !multiline {
+		text = strings.ReplaceAll(text, "\n", " ")
+	}
+	name, font, err := a.getFont(strings.Split(text, "\n")...)
+	if err != nil {
+		return err
+	}
+	width := func(line string, size float64) float64 {
+		str, _ := font.encode(line)
+		return font.getTextWidth(str) * size
+	}
+
+	padding := a.border + 2.0
+	size := a.fontSize
+	var lines []string
+	var positions [][2]float64
+	if multiline {
+		if size == 0.0 {
+			size = 12.0
+			for size > 4.0 && float64(len(wrapText(text, a.width-2.0*padding, size, width)))*size*1.15 > a.height-2.0*padding {
+				size -= 0.5
+			}
+		}
+		lines = wrapText(text, a.width-2.0*padding, size, width)
+		y := a.height - padding - 0.8*size
+		for _, line := range lines {
+			positions = append(positions, [2]float64{alignText(form.getQuadding(field, dict), width(line, size), a.width, padding), y})
+			y -= 1.15 * size
+		}
+	} else {
+		if size == 0.0 {
+			size = math.Min((a.height-2.0*a.border-2.0)/1.15, 12.0)
+			if w := width(text, size); w > a.width-2.0*padding && w > 0.0 {
+				size *= (a.width - 2.0*padding) / w
+			}
+			size = math.Max(size, 4.0)
+		}
+		y := a.height/2.0 - 0.3*size
+		if field.Type == "Text" && flags&fieldComb != 0 && maxLen > 0 {
+			cell := a.width / float64(maxLen)
+			for i, r := range []rune(text) {
+				lines = append(lines, string(r))
+				positions = append(positions, [2]float64{float64(i)*cell + (cell-width(string(r), size))/2.0, y})
+			}
+		} else {
+			lines = []string{text}
+			positions = [][2]float64{{alignText(form.getQuadding(field, dict), width(text, size), a.width, padding), y}}
+		}
+	}
+
+	a.beginText()
+	a.showText(font, name, size, lines, positions)
+	a.endText()
+	form.setEntry(widget, "AP", PDFDict{"N": a.write()})
+	return nil
+}
+
+// alignText returns the x coordinate of the text aligned inside the box.
+func alignText(quadding int, textWidth, boxWidth, padding float64) float64 {
+	switch quadding {
+	case 1:
+		return (boxWidth - textWidth) / 2.0
+	case 2:
+		return boxWidth - padding - textWidth
+	}
+	return padding
+}
+
+// wrapText breaks the text into lines not wider than the width.
+// The words wider than the width are broken between the characters.
+func wrapText(text string, maxWidth, size float64, width func(string, float64) float64) []string {
+	lines := make([]string, 0)
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Split(paragraph, " ") {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if width(candidate, size) <= maxWidth || line == "" && width(word, size) <= maxWidth {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = ""
+			for _, r := range word {
+				if line != "" && width(line+string(r), size) > maxWidth {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// writeListAppearance writes the appearance of list box with the selected option highlighted.
+func (form *acroForm) writeListAppearance(field *FormField, widget pdfRef, options [][2]string, selected int) error {
+	o := form.o
+	a := form.newAppearance(field, o.getDict(widget))
+	if a == nil {
+		return nil
+	}
+	texts := make([]string, len(options))
+	for i, option := range options {
+		texts[i] = option[1]
+	}
+	name, font, err := a.getFont(texts...)
+	if err != nil {
+		return err
+	}
+	size := a.fontSize
+	if size == 0.0 {
+		size = 12.0
+	}
+	leading := 1.15 * size
+	padding := a.border + 1.0
+	rows := int((a.height - 2.0*padding) / leading)
+	if rows < 1 {
+		rows = 1
+	}
+	first := int(o.getNumber(o.getDict(field.ref)["TI"], 0.0)) // The top index
+	if selected >= first+rows {
+		first = selected - rows + 1
+	} else if selected >= 0 && selected < first {
+		first = selected
+	}
+	var lines []string
+	var positions [][2]float64
+	a.beginText()
+	for i := first; i >= 0 && i < len(options) && i <= first+rows; i++ {
+		y := a.height - padding - float64(i-first+1)*leading
+		if i == selected {
+			a.operations = append(a.operations,
+				NewContentOperation("rg", 0.6, 0.75, 0.85),
+				NewContentOperation("re", roundCoordinate(padding), roundCoordinate(y),
+					roundCoordinate(a.width-2.0*padding), roundCoordinate(leading)),
+				NewContentOperation("f"))
+		}
+		lines = append(lines, texts[i])
+		positions = append(positions, [2]float64{padding + 1.0, y + 0.25*size})
+	}
+	a.showText(font, name, size, lines, positions)
+	a.endText()
+	form.setEntry(widget, "AP", PDFDict{"N": a.write()})
+	return nil
+}
+
+// writeButtonAppearance writes the on and Off appearances of check box or radio button without appearances.
+// The on appearance shows the ZapfDingbats character of the MK /CA caption - check mark or bullet by default.
+func (form *acroForm) writeButtonAppearance(field *FormField, widget pdfRef, state string) {
+	o := form.o
+	dict := o.getDict(widget)
+	on := form.newAppearance(field, dict)
+	off := form.newAppearance(field, dict)
+	if on == nil {
+		return
+	}
+	caption := "4" // The check mark
+	if field.Type == "RadioButton" {
+		caption = "l" // The bullet
+	}
+	if str, ok := o.resolve(o.getDict(dict["MK"])["CA"]).(PDFString); ok && len(str) > 0 {
+		caption = string(str[:1])
+	}
+	// The default appearance font is used if it is ZapfDingbats.
+	name, font := "ZaDb", form.getCoreFont("ZapfDingbats")
+	value, ok := o.getDict(o.getDict(form.dict["DR"])["Font"])[on.fontName]
+	if ok && form.getTextFont(on.fontName, value).name == "ZapfDingbats" {
+		name, font = on.fontName, form.getTextFont(on.fontName, value)
+	} else {
+		value = form.addCoreFont("ZapfDingbats")
+	}
+	size := on.fontSize
+	if size == 0.0 {
+		size = 0.8 * math.Min(on.width, on.height)
+	}
+	x := (on.width - font.getWidth(int(caption[0]), 1)*size) / 2.0
+	y := (on.height - 0.7*size) / 2.0
+	on.fonts[name] = value
+	on.operations = append(on.operations, NewContentOperation("q"))
+	on.showText(font, name, size, []string{caption}, [][2]float64{{x, y}})
+	on.operations = append(on.operations, NewContentOperation("Q"))
+	form.setEntry(widget, "AP", PDFDict{"N": PDFDict{state: on.write(), "Off": off.write()}})
+}
+
+// flattenPage draws the appearances of the widget annotations of the page into the page content
+// and removes the widget annotations.
+func (form *acroForm) flattenPage(obj *PDFobj) {
+	o := form.o
+	page, _ := o.getValue(obj).(PDFDict)
+	annotations := o.getArray(page["Annots"])
+	kept := make([]any, 0, len(annotations))
+	resources := copyDict(o.getDict(o.getInherited(page, "Resources")))
+	xobjects := copyDict(o.getDict(resources["XObject"]))
+	operations := []*ContentOperation{NewContentOperation("Q")}
+	for _, annotation := range annotations {
+		dict := o.getDict(annotation)
+		if o.getName(dict["Subtype"]) != "Widget" {
+			kept = append(kept, annotation)
+			continue
+		}
+		if ref, matrix := form.getWidgetAppearance(dict); ref != 0 {
+			name := "Fm1"
+			for i := 2; xobjects[name] != nil; i++ {
+				name = "Fm" + strconv.Itoa(i)
+			}
+			xobjects[name] = ref
+			operations = append(operations,
+				NewContentOperation("q"),
+				NewContentOperation("cm", matrix...),
+				NewContentOperation("Do", PDFName(name)),
+				NewContentOperation("Q"))
+		}
+	}
+	if len(kept) == len(annotations) {
+		return
+	}
+	obj.removeEntries("/Annots")
+	if len(kept) > 0 {
+		obj.insertEntries(appendPDFTokens([]string{"/Annots"}, kept)...)
+	}
+	if len(operations) == 1 {
+		return // No visible widgets
+	}
+
+	resources["XObject"] = xobjects
+	ref := form.newObject(appendPDFTokens(nil, resources)).number
+	obj.removeEntries("/Resources")
+	obj.insertEntries("/Resources", strconv.Itoa(ref), "0", "R")
+
+	// The page content is enclosed in q and Q so that the appearances are drawn in the default user space.
+	contents := []any{form.newStream(PDFDict{}, []byte("q\n"))}
+	switch value := o.resolve(page["Contents"]).(type) {
+	case []any:
+		contents = append(contents, value...)
+	case PDFDict:
+		contents = append(contents, page["Contents"])
+	}
+	contents = append(contents, form.newStream(PDFDict{}, (&ContentStream{Operations: operations}).Bytes()))
+	obj.removeEntries("/Contents")
+	obj.insertEntries(appendPDFTokens([]string{"/Contents"}, contents)...)
+}
+
+// getWidgetAppearance returns the normal appearance stream of the visible widget annotation and
+// the matrix that maps the appearance bounding box to the annotation rectangle.
+// Returns 0 if the widget is hidden or has no appearance for its state.
+func (form *acroForm) getWidgetAppearance(widget PDFDict) (pdfRef, []any) {
+	o := form.o
+	if flags := int(o.getNumber(widget["F"], 0.0)); flags&(2|32) != 0 {
+		return 0, nil // Hidden or NoView
+	}
+	normal := o.getDict(widget["AP"])["N"]
+	stream := o.getDict(normal)
+	if _, ok := stream["BBox"]; !ok {
+		normal = stream[o.getName(widget["AS"])] // The appearance states
+		stream = o.getDict(normal)
+	}
+	ref, ok := normal.(pdfRef)
+	bbox := o.getArray(stream["BBox"])
+	rect := o.getArray(widget["Rect"])
+	if !ok || len(bbox) != 4 || len(rect) != 4 {
+		return 0, nil
+	}
+	matrix, ok := getMatrix(o, o.getArray(stream["Matrix"]))
+	if !ok {
+		matrix = [6]float64{1.0, 0.0, 0.0, 1.0, 0.0, 0.0}
+	}
+	box := transformBox(matrix,
+		o.getNumber(bbox[0], 0.0), o.getNumber(bbox[1], 0.0), o.getNumber(bbox[2], 0.0), o.getNumber(bbox[3], 0.0))
+	area := transformBox([6]float64{1.0, 0.0, 0.0, 1.0, 0.0, 0.0},
+		o.getNumber(rect[0], 0.0), o.getNumber(rect[1], 0.0), o.getNumber(rect[2], 0.0), o.getNumber(rect[3], 0.0))
+	if box[2]-box[0] == 0.0 || box[3]-box[1] == 0.0 {
+		return 0, nil
+	}
+	sx := (area[2] - area[0]) / (box[2] - box[0])
+	sy := (area[3] - area[1]) / (box[3] - box[1])
+	return ref, []any{
+		roundCoordinate(sx), 0.0, 0.0, roundCoordinate(sy),
+		roundCoordinate(area[0] - box[0]*sx), roundCoordinate(area[1] - box[1]*sy),
+	}
+}
+
+func copyDict(dict PDFDict) PDFDict {
+	copied := make(PDFDict, len(dict))
+	for key, value := range dict {
+		copied[key] = value
+	}
+	return copied
+}
+
+// encodeTextString returns the ASCII text as it is and other text in UTF-16BE with byte order mark.
+func encodeTextString(text string) PDFString {
+	for _, r := range text {
+		if r >= 0x80 {
+			str := PDFString{0xFE, 0xFF}
+			for _, unit := range utf16.Encode([]rune(text)) {
+				str = append(str, byte(unit>>8), byte(unit))
+			}
+			return str
+		}
+	}
+	return PDFString(text)
+}
@@ -29,4 +29,5 @@ const (
 	Left = iota
 	Right
 	Center
+	Justify
 )
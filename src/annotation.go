@@ -34,6 +34,8 @@ type Annotation struct {
 	actualText     *string
 	altDescription *string
 	fileAttachment *FileAttachment
+	signature      *Signature
+	widget         *widget
 }
 
 // NewAnnotation is the constructor used to create annotation objects.
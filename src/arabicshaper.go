@@ -0,0 +1,132 @@
+package pdfjet
+
+/**
+ * arabicshaper.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import "unicode"
+
+// The Arabic joining types. See the Unicode Standard, chapter 9.2 "Arabic".
+const (
+	joiningNone        = iota // U - the letter does not join
+	joiningRight              // R - the letter joins to the previous letter only
+	joiningDual               // D - the letter joins on both sides
+	joiningCausing            // C - tatweel and ZWJ, join on both sides without changing shape
+	joiningTransparent        // T - the marks are skipped
+)
+
+// The masks of the Arabic positional forms.
+const (
+	arabicIsolMask uint32 = 1 << (iota + 1)
+	arabicFinaMask
+	arabicMediMask
+	arabicInitMask
+)
+
+// The Arabic letters that join to the previous letter only.
+var arabicRightJoining = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0622, 0x0625, 1}, {0x0627, 0x0629, 2}, {0x062F, 0x0632, 1},
+		{0x0648, 0x0671, 41}, {0x0672, 0x0673, 1}, {0x0675, 0x0677, 1},
+		{0x0688, 0x0699, 1}, {0x06C0, 0x06C3, 3}, {0x06C4, 0x06CB, 1},
+		{0x06CD, 0x06CF, 2}, {0x06D2, 0x06D3, 1}, {0x06D5, 0x06EE, 25},
+		{0x06EF, 0x0759, 106}, {0x075A, 0x075B, 1}, {0x076B, 0x076C, 1},
+		{0x0771, 0x0773, 2}, {0x0774, 0x0778, 4}, {0x0779, 0x08AA, 305},
+		{0x08AB, 0x08AC, 1}, {0x08AE, 0x08B1, 3}, {0x08B2, 0x08B9, 7},
+	},
+}
+
+// The Arabic letters that do not join.
+var arabicNonJoining = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0621, 0x0674, 83}, {0x08AD, 0x08AD, 1},
+	},
+}
+
+// getJoiningType returns the joining type of the character.
+// The Arabic letters not listed as right joining or non-joining are dual joining.
+func getJoiningType(ch rune) int {
+	switch {
+	case ch == 0x0640 || ch == 0x07FA || ch == 0x200D:
+		return joiningCausing
+	case ch == 0x200C:
+		return joiningNone
+	case unicode.In(ch, unicode.Mn, unicode.Me, unicode.Cf):
+		return joiningTransparent
+	case ch < 0x0620 || ch > 0x08FF || !unicode.Is(unicode.Lo, ch):
+		return joiningNone
+	case unicode.Is(arabicRightJoining, ch):
+		return joiningRight
+	case unicode.Is(arabicNonJoining, ch):
+		return joiningNone
+	}
+	return joiningDual
+}
+
+// shapeArabic applies the positional forms - isol, fina, medi and init - and the Arabic ligatures.
+func (s *otShaper) shapeArabic() {
+	s.setJoiningMasks()
+	s.substitute(getGlobalFeatures([]string{"ccmp", "locl"}))
+	s.substitute([]otFeature{
+		{"isol", arabicIsolMask},
+		{"fina", arabicFinaMask},
+		{"medi", arabicMediMask},
+		{"init", arabicInitMask},
+	})
+	s.substitute(getGlobalFeatures([]string{"rlig"}))
+	s.substitute(getGlobalFeatures([]string{"rclt", "calt", "liga", "clig", "mset"}))
+}
+
+// setJoiningMasks sets the mask of the positional form of each letter
+// from the joining types of the letters before and after it.
+func (s *otShaper) setJoiningMasks() {
+	prev := -1 // The previous letter that is not transparent
+	prevJoinsNext := false
+	for i := range s.glyphs {
+		joining := getJoiningType(s.runes[s.glyphs[i].cluster])
+		if joining == joiningTransparent {
+			continue
+		}
+		joinsPrev := prevJoinsNext && joining != joiningNone
+		if joinsPrev && prev >= 0 {
+			// The previous letter joins this one: isol -> fina or init -> medi
+			switch s.glyphs[prev].mask {
+			case globalMask | arabicIsolMask:
+				s.glyphs[prev].mask = globalMask | arabicInitMask
+			case globalMask | arabicFinaMask:
+				s.glyphs[prev].mask = globalMask | arabicMediMask
+			}
+		}
+		if joining == joiningRight || joining == joiningDual {
+			if joinsPrev {
+				s.glyphs[i].mask = globalMask | arabicFinaMask
+			} else {
+				s.glyphs[i].mask = globalMask | arabicIsolMask
+			}
+			prev = i
+		} else {
+			prev = -1 // The join causing characters and the non-joining characters keep their shape
+		}
+		prevJoinsNext = joining == joiningDual || joining == joiningCausing
+	}
+}
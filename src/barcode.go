@@ -124,6 +124,50 @@ func NewBarcode(barcodeType int, text string) *Barcode {
 	return barcode
 }
 
+// NewBarcodeErr constructs barcode objects and validates the content string,
+// so that drawing the barcode will not fail later.
+// Returns UnsupportedFeatureError for unknown barcode type or characters that cannot be encoded.
+// @param type the type of the barcode.
+// @param str the content string of the barcode.
+func NewBarcodeErr(barcodeType int, text string) (*Barcode, error) {
+	barcode := NewBarcode(barcodeType, text)
+	if err := barcode.validate(); err != nil {
+		return nil, err
+	}
+	return barcode, nil
+}
+
+// validate returns UnsupportedFeatureError if the barcode cannot be drawn.
+func (barcode *Barcode) validate() error {
+	text := barcode.text
+	switch barcode.barcodeType {
+	case Upc:
+		if len(text) < 11 {
+			return unsupported("UPC barcode with less than 11 digits: %s", text)
+		}
+		for i := 0; i < len(text); i++ {
+			if text[i] < '0' || text[i] > '9' {
+				return unsupported("UPC barcode character %q", text[i])
+			}
+		}
+	case CODE128:
+		for _, ch := range text {
+			if ch > 255 {
+				return unsupported("Code128 barcode character %q", ch)
+			}
+		}
+	case CODE39:
+		for i := 0; i < len(text); i++ {
+			if barcode.tableB[text[i]] == "" {
+				return unsupported("Code39 barcode character %q", text[i])
+			}
+		}
+	default:
+		return unsupported("barcode type %d", barcode.barcodeType)
+	}
+	return nil
+}
+
 // SetLocation sets the location where this barcode will be drawn on the page.
 // @param x1 the x coordinate of the top left corner of the barcode.
 // @param y1 the y coordinate of the top left corner of the barcode.
@@ -158,22 +202,30 @@ func (barcode *Barcode) SetFont(font *Font) {
 }
 
 // DrawOn draws this barcode on the specified page.
+// The program exits if the barcode cannot be drawn. Use DrawOnErr to handle the error.
 func (barcode *Barcode) DrawOn(page *Page) []float32 {
-	switch barcode.barcodeType {
-	case Upc:
-		return barcode.drawCodeUPC(page, barcode.x1, barcode.y1)
-	case CODE128:
-		return barcode.drawCode128(page, barcode.x1, barcode.y1)
-	case CODE39:
-		return barcode.drawCode39(page, barcode.x1, barcode.y1)
-	default:
-		log.Fatal("Unsupported Barcode Type.")
+	xy, err := barcode.DrawOnErr(page)
+	if err != nil {
+		log.Fatal(err)
 	}
-	return []float32{0.0, 0.0}
+	return xy
+}
+
+// DrawOnErr draws this barcode on the specified page.
+// Returns UnsupportedFeatureError for unknown barcode type or characters that cannot be encoded.
+func (barcode *Barcode) DrawOnErr(page *Page) ([]float32, error) {
+	if err := barcode.validate(); err != nil {
+		return nil, err
+	}
+	return barcode.drawOnPageAtLocation(page, barcode.x1, barcode.y1), nil
 }
 
 // drawOnPageAtLocation draws this barcode on the specified page at the spacified location.
+// Nothing is drawn if the barcode cannot be drawn - the cells of the table have no error to return.
 func (barcode *Barcode) drawOnPageAtLocation(page *Page, x1, y1 float32) []float32 {
+	if barcode.validate() != nil {
+		return []float32{0.0, 0.0}
+	}
 	switch barcode.barcodeType {
 case Upc:
 		return barcode.drawCodeUPC(page, x1, y1)
@@ -181,8 +233,6 @@ case Upc:
 		return barcode.drawCode128(page, x1, y1)
 	case CODE39:
 		return barcode.drawCode39(page, x1, y1)
-	default:
-		log.Fatal("Unsupported Barcode Type.")
 	}
 	return []float32{0.0, 0.0}
 }
@@ -399,10 +449,6 @@ func (barcode *Barcode) drawCode39(page *Page, x1, y1 float32) []float32 {
 	if barcode.direction == LeftToRight {
 		for i := 0; i < len(barcode.text); i++ {
 			code := barcode.tableB[barcode.text[i]]
-			if code == "" {
-				log.Fatal("The input string '" + barcode.text +
-					"' contains characters that are invalid in a Code39 barcode.")
-			}
 			for _, ch := range code {
 				if ch == 'w' {
 					x += barcode.m1
@@ -430,10 +476,6 @@ func (barcode *Barcode) drawCode39(page *Page, x1, y1 float32) []float32 {
 	} else if barcode.direction == TopToBottom {
 		for i := 0; i < len(barcode.text); i++ {
 			code := barcode.tableB[barcode.text[i]]
-			if code == "" {
-				log.Fatal("The input string '" + barcode.text +
-					"' contains characters that are invalid in a Code39 barcode.")
-			}
 			for _, ch := range code {
 				if ch == 'w' {
 					y += barcode.m1
@@ -464,10 +506,6 @@ func (barcode *Barcode) drawCode39(page *Page, x1, y1 float32) []float32 {
 		var height float32
 		for i := 0; i < len(barcode.text); i++ {
 			code := barcode.tableB[barcode.text[i]]
-			if code == "" {
-				log.Fatal("The input string '" + barcode.text +
-					"' contains characters that are invalid in a Code39 barcode.")
-			}
 			for _, ch := range code {
 				if ch == 'w' || ch == 'b' {
 					height += barcode.m1
@@ -59,8 +59,20 @@ const (
 )
 
 // NewBarcode2D constructor for 2D barcodes.
+// The program exits if the string cannot be encoded. Use NewBarcode2DErr to handle the error.
 // @param str the specified string.
 func NewBarcode2D(str string) *Barcode2D {
+	barcode, err := NewBarcode2DErr(str)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return barcode
+}
+
+// NewBarcode2DErr constructor for 2D barcodes.
+// Returns UnsupportedFeatureError if the string contains characters that cannot be encoded.
+// @param str the specified string.
+func NewBarcode2DErr(str string) (*Barcode2D, error) {
 	barcode := new(Barcode2D)
 	barcode.str = str
 	barcode.w1 = 0.75
@@ -71,7 +83,7 @@ func NewBarcode2D(str string) *Barcode2D {
 
 	for _, ch := range str {
 		if ch > 126 {
-			log.Fatal("The string contains unencodable characters.")
+			return nil, unsupported("PDF417 barcode character %q", ch)
 		}
 	}
 
@@ -123,7 +135,7 @@ func NewBarcode2D(str string) *Barcode2D {
 		barcode.codewords[index+barcode.cols+1] = lrBuffer[i]
 	}
 
-	return barcode
+	return barcode, nil
 }
 
 // SetLocation sets the location of this barcode on the page.
@@ -24,6 +24,8 @@ OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
 SOFTWARE.
 */
 
+import "sort"
+
 /**
  *  Provides BIDI processing for Arabic and Hebrew.
  *
@@ -77,6 +79,9 @@ func isArabicLetter(ch rune) bool {
 
 // ReorderVisually reorders the string so that Arabic and Hebrew text flows from right
 // to left while numbers and Latin text flows from left to right.
+// The reordered string is enclosed in LRM, LRO and PDF marks so that it is not
+// reordered again when drawn. The text drawn on the page and in the text boxes is
+// reordered by the Unicode Bidirectional Algorithm and does not need this method.
 // @param str the input string.
 // @return the reordered string.
 func ReorderVisually(text string) string {
@@ -167,7 +172,7 @@ func ReorderVisually(text string) string {
 		}
 	}
 
-	return string(buf3)
+	return "\u200E\u202D" + string(buf3) + "\u202C"
 }
 
 func isArabic(ch rune) bool {
@@ -217,3 +222,658 @@ func processText(buf []rune) []rune {
 	}
 	return buf3
 }
+
+// bidiRun is a run of text at one embedding level. The text is in logical order;
+// the runs at odd levels are drawn from right to left.
+type bidiRun struct {
+	text  string
+	level int
+}
+
+// bidiStatus is an entry of the directional status stack.
+type bidiStatus struct {
+	level    int
+	override int // bidiL, bidiR or bidiON when there is no override
+	isolate  bool
+}
+
+// The maximum explicit embedding level and the depth of the bracket pair stack.
+const (
+	bidiMaxDepth        = 125
+	bidiMaxBracketPairs = 63
+)
+
+// bidiParagraph holds the state of the bidirectional algorithm applied to one line of text.
+// See Unicode Standard Annex #9 "Unicode Bidirectional Algorithm".
+type bidiParagraph struct {
+	runes       []rune
+	initial     []int  // The original bidi classes
+	classes     []int  // The resolved bidi classes
+	levels      []int  // The resolved embedding levels
+	removed     []bool // The characters removed by rule X9
+	matchingPDI []int  // The matching PDI of each isolate initiator or -1
+	level       int    // The paragraph embedding level
+}
+
+// getBidiRuns applies the Unicode Bidirectional Algorithm to the line of text and
+// returns the runs in visual order. Pass -1 as the paragraph level to get it from
+// the first strong character. The bidi control characters are removed from the runs
+// and the mirrored characters are replaced in the right-to-left runs.
+func getBidiRuns(text string, level int) []bidiRun {
+	if level <= 0 && !hasRightToLeft(text) {
+		return []bidiRun{{text, 0}}
+	}
+	p := newBidiParagraph([]rune(text), level)
+	p.resolveExplicitLevels()
+	for _, sequence := range p.getIsolatingRunSequences() {
+		p.resolveSequence(sequence)
+	}
+	p.assignRemovedLevels()
+	p.resetWhitespaceLevels()
+	return p.getVisualRuns()
+}
+
+// hasRightToLeft returns true if the text has right-to-left characters,
+// Arabic numbers or the controls that could start right-to-left run.
+func hasRightToLeft(text string) bool {
+	for _, ch := range text {
+		switch getBidiClass(ch) {
+		case bidiR, bidiAL, bidiAN, bidiRLE, bidiRLO, bidiRLI, bidiFSI:
+			return true
+		}
+	}
+	return false
+}
+
+// getParagraphLevel returns the embedding level of the paragraph - 1 if the first strong
+// character outside the isolates is right-to-left, otherwise 0. (Rules P2 and P3)
+func getParagraphLevel(runes []rune) int {
+	classes := make([]int, len(runes))
+	for i, ch := range runes {
+		classes[i] = getBidiClass(ch)
+	}
+	if getFirstStrong(classes, 0) == bidiR {
+		return 1
+	}
+	return 0
+}
+
+// getFirstStrong returns bidiL, bidiR or -1 for the first strong character starting
+// at position i. The characters inside the isolates are skipped and the search stops
+// at the PDI that closes the isolate the search starts in.
+func getFirstStrong(classes []int, i int) int {
+	depth := 0
+	for ; i < len(classes); i++ {
+		switch classes[i] {
+		case bidiL:
+			if depth == 0 {
+				return bidiL
+			}
+		case bidiR, bidiAL:
+			if depth == 0 {
+				return bidiR
+			}
+		case bidiLRI, bidiRLI, bidiFSI:
+			depth++
+		case bidiPDI:
+			if depth == 0 {
+				return -1
+			}
+			depth--
+		case bidiB:
+			return -1
+		}
+	}
+	return -1
+}
+
+func newBidiParagraph(runes []rune, level int) *bidiParagraph {
+	n := len(runes)
+	p := &bidiParagraph{
+		runes:       runes,
+		initial:     make([]int, n),
+		classes:     make([]int, n),
+		levels:      make([]int, n),
+		removed:     make([]bool, n),
+		matchingPDI: make([]int, n),
+		level:       level,
+	}
+	for i, ch := range runes {
+		p.initial[i] = getBidiClass(ch)
+		p.classes[i] = p.initial[i]
+	}
+	if p.level < 0 {
+		p.level = getParagraphLevel(runes)
+	}
+	p.findMatchingPDIs()
+	return p
+}
+
+// findMatchingPDIs finds the PDI that closes each isolate initiator. (BD9)
+func (p *bidiParagraph) findMatchingPDIs() {
+	var stack []int
+	for i, class := range p.classes {
+		p.matchingPDI[i] = -1
+		switch class {
+		case bidiLRI, bidiRLI, bidiFSI:
+			stack = append(stack, i)
+		case bidiPDI:
+			if len(stack) > 0 {
+				p.matchingPDI[stack[len(stack)-1]] = i
+				stack = stack[:len(stack)-1]
+			}
+		case bidiB:
+			stack = stack[:0]
+		}
+	}
+}
+
+// isMatchedPDI returns true if the character at position i is PDI that closes an isolate.
+func (p *bidiParagraph) isMatchedPDI(i int) bool {
+	if p.classes[i] != bidiPDI {
+		return false
+	}
+	for j := i - 1; j >= 0; j-- {
+		if p.matchingPDI[j] == i {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveExplicitLevels applies the explicit embeddings, overrides and isolates. (Rules X1 to X9)
+func (p *bidiParagraph) resolveExplicitLevels() {
+	stack := []bidiStatus{{p.level, bidiON, false}}
+	overflowIsolates, overflowEmbeddings, validIsolates := 0, 0, 0
+	for i, class := range p.initial {
+		top := stack[len(stack)-1]
+		switch class {
+		case bidiRLE, bidiLRE, bidiRLO, bidiLRO, bidiRLI, bidiLRI, bidiFSI:
+			isolate := class == bidiRLI || class == bidiLRI || class == bidiFSI
+			rightToLeft := class == bidiRLE || class == bidiRLO || class == bidiRLI
+			if class == bidiFSI {
+				rightToLeft = getFirstStrong(p.initial, i+1) == bidiR
+			}
+			p.levels[i] = top.level
+			if isolate && top.override != bidiON {
+				p.classes[i] = top.override
+			}
+			level := (top.level + 2) &^ 1
+			if rightToLeft {
+				level = (top.level + 1) | 1
+			}
+			if level <= bidiMaxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				override := bidiON
+				if class == bidiRLO {
+					override = bidiR
+				} else if class == bidiLRO {
+					override = bidiL
+				}
+				if isolate {
+					validIsolates++
+				}
+				stack = append(stack, bidiStatus{level, override, isolate})
+			} else if isolate {
+				overflowIsolates++
+			} else if overflowIsolates == 0 {
+				overflowEmbeddings++
+			}
+		case bidiPDI:
+			if overflowIsolates > 0 {
+				overflowIsolates--
+			} else if validIsolates > 0 {
+				overflowEmbeddings = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolates--
+			}
+			top = stack[len(stack)-1]
+			p.levels[i] = top.level
+			if top.override != bidiON {
+				p.classes[i] = top.override
+			}
+		case bidiPDF:
+			if overflowIsolates > 0 {
+				// The PDF is inside an overflow isolate
+			} else if overflowEmbeddings > 0 {
+				overflowEmbeddings--
+			} else if !top.isolate && len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			p.levels[i] = top.level
+		case bidiB:
+			p.levels[i] = p.level
+		case bidiBN:
+			p.levels[i] = top.level
+		default:
+			p.levels[i] = top.level
+			if top.override != bidiON {
+				p.classes[i] = top.override
+			}
+		}
+		switch class {
+		case bidiRLE, bidiLRE, bidiRLO, bidiLRO, bidiPDF, bidiBN:
+			p.removed[i] = true
+		}
+	}
+}
+
+// getIsolatingRunSequences returns the positions of the characters in each isolating run sequence. (BD13)
+func (p *bidiParagraph) getIsolatingRunSequences() [][]int {
+	// The level runs of the characters not removed by X9
+	var runs [][]int
+	for i := range p.runes {
+		if p.removed[i] {
+			continue
+		}
+		if len(runs) > 0 {
+			run := runs[len(runs)-1]
+			if p.levels[run[len(run)-1]] == p.levels[i] {
+				runs[len(runs)-1] = append(run, i)
+				continue
+			}
+		}
+		runs = append(runs, []int{i})
+	}
+	runStartingAt := make(map[int]int)
+	for k, run := range runs {
+		runStartingAt[run[0]] = k
+	}
+	var sequences [][]int
+	for _, run := range runs {
+		if p.isMatchedPDI(run[0]) {
+			continue // The run continues the sequence of its isolate initiator
+		}
+		sequence := append([]int(nil), run...)
+		for {
+			last := sequence[len(sequence)-1]
+			pdi := p.matchingPDI[last]
+			if pdi < 0 {
+				break
+			}
+			k, ok := runStartingAt[pdi]
+			if !ok {
+				break
+			}
+			sequence = append(sequence, runs[k]...)
+		}
+		sequences = append(sequences, sequence)
+	}
+	return sequences
+}
+
+// resolveSequence resolves the weak types, the neutral types and the implicit levels
+// of the isolating run sequence. (Rules X10, W1 to W7, N0 to N2, I1 and I2)
+func (p *bidiParagraph) resolveSequence(sequence []int) {
+	first, last := sequence[0], sequence[len(sequence)-1]
+	level := p.levels[first]
+	prevLevel := p.level
+	for i := first - 1; i >= 0; i-- {
+		if !p.removed[i] {
+			prevLevel = p.levels[i]
+			break
+		}
+	}
+	nextLevel := p.level
+	switch p.classes[last] {
+	case bidiLRI, bidiRLI, bidiFSI:
+	default:
+		for i := last + 1; i < len(p.runes); i++ {
+			if !p.removed[i] {
+				nextLevel = p.levels[i]
+				break
+			}
+		}
+	}
+	sos := getLevelDirection(level)
+	if prevLevel > level {
+		sos = getLevelDirection(prevLevel)
+	}
+	eos := getLevelDirection(level)
+	if nextLevel > level {
+		eos = getLevelDirection(nextLevel)
+	}
+
+	types := make([]int, len(sequence))
+	for k, i := range sequence {
+		types[k] = p.classes[i]
+	}
+	resolveWeakTypes(types, sos)
+	p.resolveBrackets(sequence, types, sos, level)
+	resolveNeutralTypes(types, sos, eos, level)
+	for k, i := range sequence {
+		p.classes[i] = types[k]
+		p.levels[i] = getImplicitLevel(types[k], level)
+	}
+}
+
+// getLevelDirection returns the direction of the embedding level.
+func getLevelDirection(level int) int {
+	if level%2 == 1 {
+		return bidiR
+	}
+	return bidiL
+}
+
+// resolveWeakTypes applies the rules W1 to W7.
+func resolveWeakTypes(types []int, sos int) {
+	for k, t := range types { // W1
+		if t != bidiNSM {
+			continue
+		}
+		if k == 0 {
+			types[k] = sos
+		} else {
+			switch types[k-1] {
+			case bidiLRI, bidiRLI, bidiFSI, bidiPDI:
+				types[k] = bidiON
+			default:
+				types[k] = types[k-1]
+			}
+		}
+	}
+	strong := sos
+	for k, t := range types { // W2 and W3
+		switch t {
+		case bidiL, bidiR, bidiAL:
+			strong = t
+		case bidiEN:
+			if strong == bidiAL {
+				types[k] = bidiAN
+			}
+		}
+	}
+	for k, t := range types {
+		if t == bidiAL {
+			types[k] = bidiR
+		}
+	}
+	for k := 1; k < len(types)-1; k++ { // W4
+		prev, next := types[k-1], types[k+1]
+		if types[k] == bidiES && prev == bidiEN && next == bidiEN {
+			types[k] = bidiEN
+		} else if types[k] == bidiCS && prev == next && (prev == bidiEN || prev == bidiAN) {
+			types[k] = prev
+		}
+	}
+	for k := 0; k < len(types); k++ { // W5
+		if types[k] != bidiET {
+			continue
+		}
+		end := k
+		for end < len(types) && types[end] == bidiET {
+			end++
+		}
+		if (k > 0 && types[k-1] == bidiEN) || (end < len(types) && types[end] == bidiEN) {
+			for ; k < end; k++ {
+				types[k] = bidiEN
+			}
+		}
+		k = end - 1
+	}
+	for k, t := range types { // W6
+		if t == bidiES || t == bidiET || t == bidiCS {
+			types[k] = bidiON
+		}
+	}
+	strong = sos
+	for k, t := range types { // W7
+		switch t {
+		case bidiL, bidiR:
+			strong = t
+		case bidiEN:
+			if strong == bidiL {
+				types[k] = bidiL
+			}
+		}
+	}
+}
+
+// resolveBrackets resolves the paired brackets to the embedding direction
+// or to the direction of the context. (Rule N0)
+func (p *bidiParagraph) resolveBrackets(sequence, types []int, sos, level int) {
+	embedding := getLevelDirection(level)
+	for _, pair := range p.getBracketPairs(sequence, types) {
+		open, close := pair[0], pair[1]
+		direction := bidiON
+		for k := open + 1; k < close; k++ {
+			strong := getStrongDirection(types[k])
+			if strong == embedding {
+				direction = embedding
+				break
+			}
+			if strong != bidiON {
+				direction = strong
+			}
+		}
+		if direction == bidiON {
+			continue // No strong types inside the brackets
+		}
+		if direction != embedding {
+			// The opposite direction is used if the context before the brackets has it.
+			context := sos
+			for k := open - 1; k >= 0; k-- {
+				if strong := getStrongDirection(types[k]); strong != bidiON {
+					context = strong
+					break
+				}
+			}
+			if context != direction {
+				direction = embedding
+			}
+		}
+		for _, k := range []int{open, close} {
+			types[k] = direction
+			// The nonspacing marks after the bracket take its type.
+			for k++; k < len(types) && p.initial[sequence[k]] == bidiNSM; k++ {
+				types[k] = direction
+			}
+		}
+	}
+}
+
+// getStrongDirection returns bidiL or bidiR for the types treated as strong by rule N0 or bidiON.
+func getStrongDirection(t int) int {
+	switch t {
+	case bidiL:
+		return bidiL
+	case bidiR, bidiEN, bidiAN:
+		return bidiR
+	}
+	return bidiON
+}
+
+// getBracketPairs returns the positions of the paired brackets in the sequence
+// sorted by the position of the opening bracket. (BD16)
+func (p *bidiParagraph) getBracketPairs(sequence, types []int) [][2]int {
+	type opening struct {
+		bracket  rune
+		position int
+	}
+	var stack []opening
+	var pairs [][2]int
+	for k, i := range sequence {
+		if types[k] != bidiON {
+			continue
+		}
+		ch := getCanonicalBracket(p.runes[i])
+		other, isOpening := getPairedBracket(ch)
+		if other == 0 {
+			continue
+		}
+		if isOpening {
+			if len(stack) == bidiMaxBracketPairs {
+				break
+			}
+			stack = append(stack, opening{other, k})
+			continue
+		}
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].bracket == ch {
+				pairs = append(pairs, [2]int{stack[j].position, k})
+				stack = stack[:j]
+				break
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	return pairs
+}
+
+// getCanonicalBracket returns the bracket the angle bracket is canonically equivalent to.
+func getCanonicalBracket(ch rune) rune {
+	switch ch {
+	case 0x2329:
+		return 0x3008
+	case 0x232A:
+		return 0x3009
+	}
+	return ch
+}
+
+// resolveNeutralTypes resolves the sequences of neutral and isolate formatting
+// characters from the strong types around them. (Rules N1 and N2)
+func resolveNeutralTypes(types []int, sos, eos, level int) {
+	for k := 0; k < len(types); k++ {
+		if !isNeutralOrIsolate(types[k]) {
+			continue
+		}
+		end := k
+		for end < len(types) && isNeutralOrIsolate(types[end]) {
+			end++
+		}
+		before := sos
+		if k > 0 {
+			before = getStrongDirection(types[k-1])
+		}
+		after := eos
+		if end < len(types) {
+			after = getStrongDirection(types[end])
+		}
+		direction := getLevelDirection(level)
+		if before == after {
+			direction = before
+		}
+		for ; k < end; k++ {
+			types[k] = direction
+		}
+		k = end - 1
+	}
+}
+
+// isNeutralOrIsolate returns true if the type is resolved by the rules N1 and N2.
+func isNeutralOrIsolate(t int) bool {
+	switch t {
+	case bidiB, bidiS, bidiWS, bidiON, bidiLRI, bidiRLI, bidiFSI, bidiPDI:
+		return true
+	}
+	return false
+}
+
+// getImplicitLevel returns the level of the character with resolved type. (Rules I1 and I2)
+func getImplicitLevel(t, level int) int {
+	if level%2 == 0 {
+		switch t {
+		case bidiR:
+			return level + 1
+		case bidiAN, bidiEN:
+			return level + 2
+		}
+	} else if t == bidiL || t == bidiEN || t == bidiAN {
+		return level + 1
+	}
+	return level
+}
+
+// assignRemovedLevels gives the characters removed by X9 the level of the previous
+// character so that they stay inside the runs. ZWJ and ZWNJ are used for shaping.
+func (p *bidiParagraph) assignRemovedLevels() {
+	for i := range p.runes {
+		if p.removed[i] {
+			if i > 0 {
+				p.levels[i] = p.levels[i-1]
+			} else {
+				p.levels[i] = p.level
+			}
+		}
+	}
+}
+
+// resetWhitespaceLevels resets the segment separators and the whitespace
+// before them and at the end of the line to the paragraph level. (Rule L1)
+func (p *bidiParagraph) resetWhitespaceLevels() {
+	trailing := true
+	for i := len(p.runes) - 1; i >= 0; i-- {
+		switch p.initial[i] {
+		case bidiS, bidiB:
+			p.levels[i] = p.level
+			trailing = true
+		case bidiWS, bidiLRI, bidiRLI, bidiFSI, bidiPDI,
+			bidiBN, bidiLRE, bidiRLE, bidiLRO, bidiRLO, bidiPDF:
+			if trailing {
+				p.levels[i] = p.level
+			}
+		default:
+			trailing = false
+		}
+	}
+}
+
+// getVisualRuns splits the line into level runs and reverses the runs
+// at each level from the highest to the lowest odd level. (Rule L2)
+func (p *bidiParagraph) getVisualRuns() []bidiRun {
+	var runs []bidiRun
+	var buf []rune
+	level := -1
+	for i, ch := range p.runes {
+		if isBidiControl(ch) {
+			continue
+		}
+		if p.levels[i] != level && len(buf) > 0 {
+			runs = append(runs, bidiRun{string(buf), level})
+			buf = buf[:0]
+		}
+		level = p.levels[i]
+		if level%2 == 1 {
+			ch = getMirror(ch) // Rule L4
+		}
+		buf = append(buf, ch)
+	}
+	if len(buf) > 0 {
+		runs = append(runs, bidiRun{string(buf), level})
+	}
+	highest, lowestOdd := 0, bidiMaxDepth+2
+	for _, run := range runs {
+		if run.level > highest {
+			highest = run.level
+		}
+		if run.level%2 == 1 && run.level < lowestOdd {
+			lowestOdd = run.level
+		}
+	}
+	for level := highest; level >= lowestOdd; level-- {
+		for i := 0; i < len(runs); i++ {
+			if runs[i].level < level {
+				continue
+			}
+			j := i
+			for j < len(runs) && runs[j].level >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				runs[a], runs[b] = runs[b], runs[a]
+			}
+			i = j
+		}
+	}
+	return runs
+}
+
+// isBidiControl returns true if the character is one of the marks
+// or the explicit formatting characters used only by the bidirectional algorithm.
+func isBidiControl(ch rune) bool {
+	return ch == 0x061C || ch == 0x200E || ch == 0x200F ||
+		(ch >= 0x202A && ch <= 0x202E) || (ch >= 0x2066 && ch <= 0x2069)
+}
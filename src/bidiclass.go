@@ -0,0 +1,366 @@
+package pdfjet
+
+/**
+ * bidiclass.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import "unicode"
+
+// The bidirectional character types. See Unicode Standard Annex #9, table 4.
+const (
+	bidiL   = iota // Left-to-right
+	bidiR          // Right-to-left
+	bidiAL         // Arabic letter
+	bidiEN         // European number
+	bidiES         // European separator
+	bidiET         // European terminator
+	bidiAN         // Arabic number
+	bidiCS         // Common separator
+	bidiNSM        // Nonspacing mark
+	bidiBN         // Boundary neutral
+	bidiB          // Paragraph separator
+	bidiS          // Segment separator
+	bidiWS         // Whitespace
+	bidiON         // Other neutral
+	bidiLRE        // Left-to-right embedding
+	bidiLRO        // Left-to-right override
+	bidiRLE        // Right-to-left embedding
+	bidiRLO        // Right-to-left override
+	bidiPDF        // Pop directional format
+	bidiLRI        // Left-to-right isolate
+	bidiRLI        // Right-to-left isolate
+	bidiFSI        // First strong isolate
+	bidiPDI        // Pop directional isolate
+)
+
+// The right-to-left characters outside the Arabic blocks, including the unassigned code points.
+var bidiRightToLeft = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0590, 0x05FF, 1}, {0x07C0, 0x085F, 1}, {0xFB1D, 0xFB4F, 1},
+	},
+	R32: []unicode.Range32{
+		{0x10800, 0x10CFF, 1}, {0x10D40, 0x10EBF, 1}, {0x10F00, 0x10F2F, 1},
+		{0x10F70, 0x10FFF, 1}, {0x1E800, 0x1EC6F, 1}, {0x1ECC0, 0x1ECFF, 1},
+		{0x1ED50, 0x1EDFF, 1}, {0x1EF00, 0x1EFFF, 1},
+	},
+}
+
+// The Arabic letters and the unassigned code points of the Arabic blocks.
+var bidiArabicLetter = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0600, 0x07BF, 1}, {0x0860, 0x08FF, 1}, {0xFB50, 0xFDCF, 1},
+		{0xFDF0, 0xFDFF, 1}, {0xFE70, 0xFEFF, 1},
+	},
+	R32: []unicode.Range32{
+		{0x10D00, 0x10D3F, 1}, {0x10EC0, 0x10EFF, 1}, {0x10F30, 0x10F6F, 1},
+		{0x1EC70, 0x1ECBF, 1}, {0x1ED00, 0x1ED4F, 1}, {0x1EE00, 0x1EEFF, 1},
+	},
+}
+
+// The characters of the Arabic blocks that are Arabic numbers.
+var bidiArabicNumber = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0600, 0x0605, 1}, {0x0660, 0x0669, 1}, {0x066B, 0x066C, 1},
+		{0x06DD, 0x0890, 435}, {0x0891, 0x08E2, 81},
+	},
+	R32: []unicode.Range32{
+		{0x10D30, 0x10D39, 1}, {0x10E60, 0x10E7E, 1},
+	},
+}
+
+// The European digits outside ASCII.
+var bidiEuropeanNumber = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x00B2, 0x00B3, 1}, {0x00B9, 0x06F0, 1591}, {0x06F1, 0x06F9, 1},
+		{0x2070, 0x2074, 4}, {0x2075, 0x2079, 1},
+		{0x2080, 0x2089, 1}, {0x2488, 0x249B, 1}, {0xFF10, 0xFF19, 1},
+	},
+	R32: []unicode.Range32{
+		{0x102E1, 0x102FB, 1}, {0x1D7CE, 0x1D7FF, 1}, {0x1F100, 0x1F10A, 1}, {0x1FBF0, 0x1FBF9, 1},
+	},
+}
+
+// The European terminators outside ASCII - degree, per mille and the currency signs.
+var bidiEuropeanTerminator = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x00A2, 0x00A5, 1}, {0x00B0, 0x00B1, 1}, {0x058F, 0x0609, 122}, {0x060A, 0x060A, 1},
+		{0x066A, 0x09F2, 904}, {0x09F3, 0x09FB, 8}, {0x0AF1, 0x0BF9, 264},
+		{0x0E3F, 0x17DB, 2460}, {0x2030, 0x2034, 1}, {0x20A0, 0x20CF, 1},
+		{0x212E, 0x2213, 229}, {0xA838, 0xA839, 1}, {0xFE5F, 0xFE69, 10},
+		{0xFE6A, 0xFF03, 153}, {0xFF04, 0xFF05, 1}, {0xFFE0, 0xFFE1, 1},
+		{0xFFE5, 0xFFE6, 1},
+	},
+}
+
+// The other neutral characters - the punctuation and the symbols shared by the scripts.
+var bidiOtherNeutral = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0021, 0x0022, 1}, {0x0026, 0x002A, 1}, {0x003B, 0x0040, 1}, {0x005B, 0x0060, 1},
+		{0x007B, 0x007E, 1}, {0x00A1, 0x00A1, 1}, {0x00A6, 0x00A9, 1}, {0x00AB, 0x00AC, 1},
+		{0x00AE, 0x00AF, 1}, {0x00B4, 0x00B4, 1}, {0x00B6, 0x00B8, 1}, {0x00BB, 0x00BF, 1},
+		{0x00D7, 0x00D7, 1}, {0x00F7, 0x00F7, 1}, {0x02B9, 0x02BA, 1}, {0x02C2, 0x02CF, 1},
+		{0x02D2, 0x02DF, 1}, {0x02E5, 0x02ED, 1}, {0x02EF, 0x02FF, 1}, {0x0374, 0x0375, 1},
+		{0x037E, 0x037E, 1}, {0x0384, 0x0385, 1}, {0x0387, 0x0387, 1}, {0x03F6, 0x03F6, 1},
+		{0x058A, 0x058A, 1}, {0x058D, 0x058E, 1}, {0x0606, 0x0607, 1}, {0x060E, 0x060F, 1},
+		{0x06DE, 0x06DE, 1}, {0x06E9, 0x06E9, 1}, {0x07F6, 0x07F9, 1}, {0x0BF3, 0x0BF8, 1},
+		{0x0BFA, 0x0BFA, 1}, {0x0C78, 0x0C7E, 1}, {0x0F3A, 0x0F3D, 1}, {0x1390, 0x1399, 1},
+		{0x1400, 0x1400, 1}, {0x169B, 0x169C, 1}, {0x17F0, 0x17F9, 1}, {0x1800, 0x180A, 1},
+		{0x1940, 0x1940, 1}, {0x1944, 0x1945, 1}, {0x19DE, 0x19FF, 1}, {0x1FBD, 0x1FBD, 1},
+		{0x1FBF, 0x1FC1, 1}, {0x1FCD, 0x1FCF, 1}, {0x1FDD, 0x1FDF, 1}, {0x1FED, 0x1FEF, 1},
+		{0x1FFD, 0x1FFE, 1}, {0x2010, 0x2027, 1}, {0x2035, 0x2043, 1}, {0x2045, 0x205E, 1},
+		{0x207C, 0x207E, 1}, {0x208C, 0x208E, 1}, {0x2100, 0x2101, 1}, {0x2103, 0x2106, 1},
+		{0x2108, 0x2109, 1}, {0x2114, 0x2114, 1}, {0x2116, 0x2118, 1}, {0x211E, 0x2123, 1},
+		{0x2125, 0x2125, 1}, {0x2127, 0x2127, 1}, {0x2129, 0x2129, 1}, {0x213A, 0x213B, 1},
+		{0x2140, 0x2144, 1}, {0x214A, 0x214D, 1}, {0x2150, 0x215F, 1}, {0x2189, 0x218B, 1},
+		{0x2190, 0x2211, 1}, {0x2214, 0x2335, 1}, {0x237B, 0x2394, 1}, {0x2396, 0x2426, 1},
+		{0x2440, 0x244A, 1}, {0x2460, 0x2487, 1}, {0x24EA, 0x26AB, 1}, {0x26AD, 0x27FF, 1},
+		{0x2900, 0x2B73, 1}, {0x2B76, 0x2B95, 1}, {0x2B97, 0x2BFF, 1}, {0x2CE5, 0x2CEA, 1},
+		{0x2CF9, 0x2CFF, 1}, {0x2E00, 0x2E5D, 1}, {0x2E80, 0x2E99, 1}, {0x2E9B, 0x2EF3, 1},
+		{0x2F00, 0x2FD5, 1}, {0x2FF0, 0x2FFB, 1}, {0x3001, 0x3004, 1}, {0x3008, 0x3020, 1},
+		{0x3030, 0x3030, 1}, {0x3036, 0x3037, 1}, {0x303D, 0x303F, 1}, {0x309B, 0x309C, 1},
+		{0x30A0, 0x30A0, 1}, {0x30FB, 0x30FB, 1}, {0x31C0, 0x31E3, 1}, {0x321D, 0x321E, 1},
+		{0x3250, 0x325F, 1}, {0x327C, 0x327E, 1}, {0x32B1, 0x32BF, 1}, {0x32CC, 0x32CF, 1},
+		{0x3377, 0x337A, 1}, {0x33DE, 0x33DF, 1}, {0x33FF, 0x33FF, 1}, {0x4DC0, 0x4DFF, 1},
+		{0xA490, 0xA4C6, 1}, {0xA60D, 0xA60F, 1}, {0xA673, 0xA673, 1}, {0xA67E, 0xA67F, 1},
+		{0xA700, 0xA721, 1}, {0xA788, 0xA788, 1}, {0xA828, 0xA82B, 1}, {0xA874, 0xA877, 1},
+		{0xAB6A, 0xAB6B, 1}, {0xFD3E, 0xFD4F, 1}, {0xFDCF, 0xFDCF, 1}, {0xFDFD, 0xFDFF, 1},
+		{0xFE10, 0xFE19, 1}, {0xFE30, 0xFE4F, 1}, {0xFE51, 0xFE51, 1}, {0xFE54, 0xFE54, 1},
+		{0xFE56, 0xFE5E, 1}, {0xFE60, 0xFE61, 1}, {0xFE64, 0xFE66, 1}, {0xFE68, 0xFE68, 1},
+		{0xFE6B, 0xFE6B, 1}, {0xFF01, 0xFF02, 1}, {0xFF06, 0xFF0A, 1}, {0xFF1B, 0xFF20, 1},
+		{0xFF3B, 0xFF40, 1}, {0xFF5B, 0xFF65, 1}, {0xFFE2, 0xFFE4, 1}, {0xFFE8, 0xFFEE, 1},
+		{0xFFF9, 0xFFFD, 1},
+	},
+	R32: []unicode.Range32{
+		{0x10101, 0x10101, 1}, {0x10140, 0x1018C, 1}, {0x10190, 0x1019C, 1}, {0x101A0, 0x101A0, 1},
+		{0x1091F, 0x1091F, 1}, {0x10B39, 0x10B3F, 1}, {0x11052, 0x11065, 1}, {0x11660, 0x1166C, 1},
+		{0x11FD5, 0x11FDC, 1}, {0x11FE1, 0x11FF1, 1}, {0x16FE2, 0x16FE2, 1}, {0x1D1E9, 0x1D1EA, 1},
+		{0x1D200, 0x1D241, 1}, {0x1D245, 0x1D245, 1}, {0x1D300, 0x1D356, 1}, {0x1D6DB, 0x1D6DB, 1},
+		{0x1D715, 0x1D715, 1}, {0x1D74F, 0x1D74F, 1}, {0x1D789, 0x1D789, 1}, {0x1D7C3, 0x1D7C3, 1},
+		{0x1EEF0, 0x1EEF1, 1}, {0x1F000, 0x1F02B, 1}, {0x1F030, 0x1F093, 1}, {0x1F0A0, 0x1F0AE, 1},
+		{0x1F0B1, 0x1F0BF, 1}, {0x1F0C1, 0x1F0CF, 1}, {0x1F0D1, 0x1F0F5, 1}, {0x1F10B, 0x1F10F, 1},
+		{0x1F12F, 0x1F12F, 1}, {0x1F16A, 0x1F16F, 1}, {0x1F1AD, 0x1F1AD, 1}, {0x1F260, 0x1F265, 1},
+		{0x1F300, 0x1F6D7, 1}, {0x1F6DD, 0x1F6EC, 1}, {0x1F6F0, 0x1F6FC, 1}, {0x1F700, 0x1F773, 1},
+		{0x1F780, 0x1F7D8, 1}, {0x1F7E0, 0x1F7EB, 1}, {0x1F7F0, 0x1F7F0, 1}, {0x1F800, 0x1F80B, 1},
+		{0x1F810, 0x1F847, 1}, {0x1F850, 0x1F859, 1}, {0x1F860, 0x1F887, 1}, {0x1F890, 0x1F8AD, 1},
+		{0x1F8B0, 0x1F8B1, 1}, {0x1F900, 0x1FA53, 1}, {0x1FA60, 0x1FA6D, 1}, {0x1FA70, 0x1FA74, 1},
+		{0x1FA78, 0x1FA7C, 1}, {0x1FA80, 0x1FA86, 1}, {0x1FA90, 0x1FAAC, 1}, {0x1FAB0, 0x1FABA, 1},
+		{0x1FAC0, 0x1FAC5, 1}, {0x1FAD0, 0x1FAD9, 1}, {0x1FAE0, 0x1FAE7, 1}, {0x1FAF0, 0x1FAF6, 1},
+		{0x1FB00, 0x1FB92, 1}, {0x1FB94, 0x1FBCA, 1},
+	},
+	LatinOffset: 14,
+}
+
+// getBidiClass returns the bidirectional character type of the character.
+// The nonspacing marks and the format characters are found by their general category.
+func getBidiClass(ch rune) int {
+	switch ch {
+	case '\t', 0x000B, 0x001F:
+		return bidiS
+	case '\n', '\r', 0x001C, 0x001D, 0x001E, 0x0085, 0x2029:
+		return bidiB
+	case 0x000C, ' ', 0x1680, 0x2028, 0x205F, 0x3000:
+		return bidiWS
+	case '+', '-', 0x207A, 0x207B, 0x208A, 0x208B, 0x2212, 0xFB29, 0xFE62, 0xFE63, 0xFF0B, 0xFF0D:
+		return bidiES
+	case '#', '$', '%':
+		return bidiET
+	case ',', '.', '/', ':', 0x00A0, 0x060C, 0x202F, 0x2044, 0xFE50, 0xFE52, 0xFE55, 0xFF0C, 0xFF0E, 0xFF0F, 0xFF1A:
+		return bidiCS
+	case 0x200E:
+		return bidiL
+	case 0x200F:
+		return bidiR
+	case 0x061C:
+		return bidiAL
+	case 0x202A:
+		return bidiLRE
+	case 0x202B:
+		return bidiRLE
+	case 0x202C:
+		return bidiPDF
+	case 0x202D:
+		return bidiLRO
+	case 0x202E:
+		return bidiRLO
+	case 0x2066:
+		return bidiLRI
+	case 0x2067:
+		return bidiRLI
+	case 0x2068:
+		return bidiFSI
+	case 0x2069:
+		return bidiPDI
+	}
+	switch {
+	case ch >= '0' && ch <= '9':
+		return bidiEN
+	case ch >= 0x2000 && ch <= 0x200A:
+		return bidiWS
+	case unicode.Is(bidiOtherNeutral, ch):
+		return bidiON
+	case unicode.In(ch, unicode.Mn, unicode.Me):
+		return bidiNSM
+	case unicode.Is(bidiArabicNumber, ch):
+		return bidiAN
+	case unicode.Is(bidiEuropeanNumber, ch):
+		return bidiEN
+	case unicode.Is(bidiEuropeanTerminator, ch):
+		return bidiET
+	case unicode.Is(unicode.Cc, ch) || (unicode.Is(unicode.Cf, ch) && ch != 0x070F) ||
+		unicode.Is(unicode.Noncharacter_Code_Point, ch):
+		return bidiBN
+	case unicode.Is(bidiArabicLetter, ch):
+		return bidiAL
+	case unicode.Is(bidiRightToLeft, ch):
+		return bidiR
+	}
+	return bidiL
+}
+
+// The opening paired brackets and their closing brackets.
+// Generated from the Bidi_Paired_Bracket property in BidiBrackets.txt.
+var bidiOpeningBrackets = map[rune]rune{
+	0x0028: 0x0029, 0x005B: 0x005D, 0x007B: 0x007D, 0x0F3A: 0x0F3B, 0x0F3C: 0x0F3D, 0x169B: 0x169C,
+	0x2045: 0x2046, 0x207D: 0x207E, 0x208D: 0x208E, 0x2308: 0x2309, 0x230A: 0x230B, 0x2329: 0x232A,
+	0x2768: 0x2769, 0x276A: 0x276B, 0x276C: 0x276D, 0x276E: 0x276F, 0x2770: 0x2771, 0x2772: 0x2773,
+	0x2774: 0x2775, 0x27C5: 0x27C6, 0x27E6: 0x27E7, 0x27E8: 0x27E9, 0x27EA: 0x27EB, 0x27EC: 0x27ED,
+	0x27EE: 0x27EF, 0x2983: 0x2984, 0x2985: 0x2986, 0x2987: 0x2988, 0x2989: 0x298A, 0x298B: 0x298C,
+	0x298D: 0x2990, 0x298F: 0x298E, 0x2991: 0x2992, 0x2993: 0x2994, 0x2995: 0x2996, 0x2997: 0x2998,
+	0x29D8: 0x29D9, 0x29DA: 0x29DB, 0x29FC: 0x29FD, 0x2E22: 0x2E23, 0x2E24: 0x2E25, 0x2E26: 0x2E27,
+	0x2E28: 0x2E29, 0x2E55: 0x2E56, 0x2E57: 0x2E58, 0x2E59: 0x2E5A, 0x2E5B: 0x2E5C, 0x3008: 0x3009,
+	0x300A: 0x300B, 0x300C: 0x300D, 0x300E: 0x300F, 0x3010: 0x3011, 0x3014: 0x3015, 0x3016: 0x3017,
+	0x3018: 0x3019, 0x301A: 0x301B, 0xFE59: 0xFE5A, 0xFE5B: 0xFE5C, 0xFE5D: 0xFE5E, 0xFF08: 0xFF09,
+	0xFF3B: 0xFF3D, 0xFF5B: 0xFF5D, 0xFF5F: 0xFF60, 0xFF62: 0xFF63,
+}
+
+// The closing paired brackets and their opening brackets.
+var bidiClosingBrackets = map[rune]rune{
+	0x0029: 0x0028, 0x005D: 0x005B, 0x007D: 0x007B, 0x0F3B: 0x0F3A, 0x0F3D: 0x0F3C, 0x169C: 0x169B,
+	0x2046: 0x2045, 0x207E: 0x207D, 0x208E: 0x208D, 0x2309: 0x2308, 0x230B: 0x230A, 0x232A: 0x2329,
+	0x2769: 0x2768, 0x276B: 0x276A, 0x276D: 0x276C, 0x276F: 0x276E, 0x2771: 0x2770, 0x2773: 0x2772,
+	0x2775: 0x2774, 0x27C6: 0x27C5, 0x27E7: 0x27E6, 0x27E9: 0x27E8, 0x27EB: 0x27EA, 0x27ED: 0x27EC,
+	0x27EF: 0x27EE, 0x2984: 0x2983, 0x2986: 0x2985, 0x2988: 0x2987, 0x298A: 0x2989, 0x298C: 0x298B,
+	0x2990: 0x298D, 0x298E: 0x298F, 0x2992: 0x2991, 0x2994: 0x2993, 0x2996: 0x2995, 0x2998: 0x2997,
+	0x29D9: 0x29D8, 0x29DB: 0x29DA, 0x29FD: 0x29FC, 0x2E23: 0x2E22, 0x2E25: 0x2E24, 0x2E27: 0x2E26,
+	0x2E29: 0x2E28, 0x2E56: 0x2E55, 0x2E58: 0x2E57, 0x2E5A: 0x2E59, 0x2E5C: 0x2E5B, 0x3009: 0x3008,
+	0x300B: 0x300A, 0x300D: 0x300C, 0x300F: 0x300E, 0x3011: 0x3010, 0x3015: 0x3014, 0x3017: 0x3016,
+	0x3019: 0x3018, 0x301B: 0x301A, 0xFE5A: 0xFE59, 0xFE5C: 0xFE5B, 0xFE5E: 0xFE5D, 0xFF09: 0xFF08,
+	0xFF3D: 0xFF3B, 0xFF5D: 0xFF5B, 0xFF60: 0xFF5F, 0xFF63: 0xFF62,
+}
+
+// The mirrored characters and their mirror images.
+// Generated from the Bidi_Mirroring_Glyph property in BidiMirroring.txt.
+var bidiMirrors = map[rune]rune{
+	0x0028: 0x0029, 0x0029: 0x0028, 0x003C: 0x003E, 0x003E: 0x003C, 0x005B: 0x005D, 0x005D: 0x005B,
+	0x007B: 0x007D, 0x007D: 0x007B, 0x00AB: 0x00BB, 0x00BB: 0x00AB, 0x0F3A: 0x0F3B, 0x0F3B: 0x0F3A,
+	0x0F3C: 0x0F3D, 0x0F3D: 0x0F3C, 0x169B: 0x169C, 0x169C: 0x169B, 0x2039: 0x203A, 0x203A: 0x2039,
+	0x2045: 0x2046, 0x2046: 0x2045, 0x207D: 0x207E, 0x207E: 0x207D, 0x208D: 0x208E, 0x208E: 0x208D,
+	0x2208: 0x220B, 0x2209: 0x220C, 0x220A: 0x220D, 0x220B: 0x2208, 0x220C: 0x2209, 0x220D: 0x220A,
+	0x2215: 0x29F5, 0x221F: 0x2BFE, 0x2220: 0x29A3, 0x2221: 0x299B, 0x2222: 0x29A0, 0x2224: 0x2AEE,
+	0x223C: 0x223D, 0x223D: 0x223C, 0x2243: 0x22CD, 0x2245: 0x224C, 0x224C: 0x2245, 0x2252: 0x2253,
+	0x2253: 0x2252, 0x2254: 0x2255, 0x2255: 0x2254, 0x2264: 0x2265, 0x2265: 0x2264, 0x2266: 0x2267,
+	0x2267: 0x2266, 0x2268: 0x2269, 0x2269: 0x2268, 0x226A: 0x226B, 0x226B: 0x226A, 0x226E: 0x226F,
+	0x226F: 0x226E, 0x2270: 0x2271, 0x2271: 0x2270, 0x2272: 0x2273, 0x2273: 0x2272, 0x2274: 0x2275,
+	0x2275: 0x2274, 0x2276: 0x2277, 0x2277: 0x2276, 0x2278: 0x2279, 0x2279: 0x2278, 0x227A: 0x227B,
+	0x227B: 0x227A, 0x227C: 0x227D, 0x227D: 0x227C, 0x227E: 0x227F, 0x227F: 0x227E, 0x2280: 0x2281,
+	0x2281: 0x2280, 0x2282: 0x2283, 0x2283: 0x2282, 0x2284: 0x2285, 0x2285: 0x2284, 0x2286: 0x2287,
+	0x2287: 0x2286, 0x2288: 0x2289, 0x2289: 0x2288, 0x228A: 0x228B, 0x228B: 0x228A, 0x228F: 0x2290,
+	0x2290: 0x228F, 0x2291: 0x2292, 0x2292: 0x2291, 0x2298: 0x29B8, 0x22A2: 0x22A3, 0x22A3: 0x22A2,
+	0x22A6: 0x2ADE, 0x22A8: 0x2AE4, 0x22A9: 0x2AE3, 0x22AB: 0x2AE5, 0x22B0: 0x22B1, 0x22B1: 0x22B0,
+	0x22B2: 0x22B3, 0x22B3: 0x22B2, 0x22B4: 0x22B5, 0x22B5: 0x22B4, 0x22B6: 0x22B7, 0x22B7: 0x22B6,
+	0x22B8: 0x27DC, 0x22C9: 0x22CA, 0x22CA: 0x22C9, 0x22CB: 0x22CC, 0x22CC: 0x22CB, 0x22CD: 0x2243,
+	0x22D0: 0x22D1, 0x22D1: 0x22D0, 0x22D6: 0x22D7, 0x22D7: 0x22D6, 0x22D8: 0x22D9, 0x22D9: 0x22D8,
+	0x22DA: 0x22DB, 0x22DB: 0x22DA, 0x22DC: 0x22DD, 0x22DD: 0x22DC, 0x22DE: 0x22DF, 0x22DF: 0x22DE,
+	0x22E0: 0x22E1, 0x22E1: 0x22E0, 0x22E2: 0x22E3, 0x22E3: 0x22E2, 0x22E4: 0x22E5, 0x22E5: 0x22E4,
+	0x22E6: 0x22E7, 0x22E7: 0x22E6, 0x22E8: 0x22E9, 0x22E9: 0x22E8, 0x22EA: 0x22EB, 0x22EB: 0x22EA,
+	0x22EC: 0x22ED, 0x22ED: 0x22EC, 0x22F0: 0x22F1, 0x22F1: 0x22F0, 0x22F2: 0x22FA, 0x22F3: 0x22FB,
+	0x22F4: 0x22FC, 0x22F6: 0x22FD, 0x22F7: 0x22FE, 0x22FA: 0x22F2, 0x22FB: 0x22F3, 0x22FC: 0x22F4,
+	0x22FD: 0x22F6, 0x22FE: 0x22F7, 0x2308: 0x2309, 0x2309: 0x2308, 0x230A: 0x230B, 0x230B: 0x230A,
+	0x2329: 0x232A, 0x232A: 0x2329, 0x2768: 0x2769, 0x2769: 0x2768, 0x276A: 0x276B, 0x276B: 0x276A,
+	0x276C: 0x276D, 0x276D: 0x276C, 0x276E: 0x276F, 0x276F: 0x276E, 0x2770: 0x2771, 0x2771: 0x2770,
+	0x2772: 0x2773, 0x2773: 0x2772, 0x2774: 0x2775, 0x2775: 0x2774, 0x27C3: 0x27C4, 0x27C4: 0x27C3,
+	0x27C5: 0x27C6, 0x27C6: 0x27C5, 0x27C8: 0x27C9, 0x27C9: 0x27C8, 0x27CB: 0x27CD, 0x27CD: 0x27CB,
+	0x27D5: 0x27D6, 0x27D6: 0x27D5, 0x27DC: 0x22B8, 0x27DD: 0x27DE, 0x27DE: 0x27DD, 0x27E2: 0x27E3,
+	0x27E3: 0x27E2, 0x27E4: 0x27E5, 0x27E5: 0x27E4, 0x27E6: 0x27E7, 0x27E7: 0x27E6, 0x27E8: 0x27E9,
+	0x27E9: 0x27E8, 0x27EA: 0x27EB, 0x27EB: 0x27EA, 0x27EC: 0x27ED, 0x27ED: 0x27EC, 0x27EE: 0x27EF,
+	0x27EF: 0x27EE, 0x2983: 0x2984, 0x2984: 0x2983, 0x2985: 0x2986, 0x2986: 0x2985, 0x2987: 0x2988,
+	0x2988: 0x2987, 0x2989: 0x298A, 0x298A: 0x2989, 0x298B: 0x298C, 0x298C: 0x298B, 0x298D: 0x2990,
+	0x298E: 0x298F, 0x298F: 0x298E, 0x2990: 0x298D, 0x2991: 0x2992, 0x2992: 0x2991, 0x2993: 0x2994,
+	0x2994: 0x2993, 0x2995: 0x2996, 0x2996: 0x2995, 0x2997: 0x2998, 0x2998: 0x2997, 0x299B: 0x2221,
+	0x29A0: 0x2222, 0x29A3: 0x2220, 0x29A4: 0x29A5, 0x29A5: 0x29A4, 0x29A8: 0x29A9, 0x29A9: 0x29A8,
+	0x29AA: 0x29AB, 0x29AB: 0x29AA, 0x29AC: 0x29AD, 0x29AD: 0x29AC, 0x29AE: 0x29AF, 0x29AF: 0x29AE,
+	0x29B8: 0x2298, 0x29C0: 0x29C1, 0x29C1: 0x29C0, 0x29C4: 0x29C5, 0x29C5: 0x29C4, 0x29CF: 0x29D0,
+	0x29D0: 0x29CF, 0x29D1: 0x29D2, 0x29D2: 0x29D1, 0x29D4: 0x29D5, 0x29D5: 0x29D4, 0x29D8: 0x29D9,
+	0x29D9: 0x29D8, 0x29DA: 0x29DB, 0x29DB: 0x29DA, 0x29E8: 0x29E9, 0x29E9: 0x29E8, 0x29F5: 0x2215,
+	0x29F8: 0x29F9, 0x29F9: 0x29F8, 0x29FC: 0x29FD, 0x29FD: 0x29FC, 0x2A2B: 0x2A2C, 0x2A2C: 0x2A2B,
+	0x2A2D: 0x2A2E, 0x2A2E: 0x2A2D, 0x2A34: 0x2A35, 0x2A35: 0x2A34, 0x2A3C: 0x2A3D, 0x2A3D: 0x2A3C,
+	0x2A64: 0x2A65, 0x2A65: 0x2A64, 0x2A79: 0x2A7A, 0x2A7A: 0x2A79, 0x2A7B: 0x2A7C, 0x2A7C: 0x2A7B,
+	0x2A7D: 0x2A7E, 0x2A7E: 0x2A7D, 0x2A7F: 0x2A80, 0x2A80: 0x2A7F, 0x2A81: 0x2A82, 0x2A82: 0x2A81,
+	0x2A83: 0x2A84, 0x2A84: 0x2A83, 0x2A85: 0x2A86, 0x2A86: 0x2A85, 0x2A87: 0x2A88, 0x2A88: 0x2A87,
+	0x2A89: 0x2A8A, 0x2A8A: 0x2A89, 0x2A8B: 0x2A8C, 0x2A8C: 0x2A8B, 0x2A8D: 0x2A8E, 0x2A8E: 0x2A8D,
+	0x2A8F: 0x2A90, 0x2A90: 0x2A8F, 0x2A91: 0x2A92, 0x2A92: 0x2A91, 0x2A93: 0x2A94, 0x2A94: 0x2A93,
+	0x2A95: 0x2A96, 0x2A96: 0x2A95, 0x2A97: 0x2A98, 0x2A98: 0x2A97, 0x2A99: 0x2A9A, 0x2A9A: 0x2A99,
+	0x2A9B: 0x2A9C, 0x2A9C: 0x2A9B, 0x2A9D: 0x2A9E, 0x2A9E: 0x2A9D, 0x2A9F: 0x2AA0, 0x2AA0: 0x2A9F,
+	0x2AA1: 0x2AA2, 0x2AA2: 0x2AA1, 0x2AA6: 0x2AA7, 0x2AA7: 0x2AA6, 0x2AA8: 0x2AA9, 0x2AA9: 0x2AA8,
+	0x2AAA: 0x2AAB, 0x2AAB: 0x2AAA, 0x2AAC: 0x2AAD, 0x2AAD: 0x2AAC, 0x2AAF: 0x2AB0, 0x2AB0: 0x2AAF,
+	0x2AB1: 0x2AB2, 0x2AB2: 0x2AB1, 0x2AB3: 0x2AB4, 0x2AB4: 0x2AB3, 0x2AB5: 0x2AB6, 0x2AB6: 0x2AB5,
+	0x2AB7: 0x2AB8, 0x2AB8: 0x2AB7, 0x2AB9: 0x2ABA, 0x2ABA: 0x2AB9, 0x2ABB: 0x2ABC, 0x2ABC: 0x2ABB,
+	0x2ABD: 0x2ABE, 0x2ABE: 0x2ABD, 0x2ABF: 0x2AC0, 0x2AC0: 0x2ABF, 0x2AC1: 0x2AC2, 0x2AC2: 0x2AC1,
+	0x2AC3: 0x2AC4, 0x2AC4: 0x2AC3, 0x2AC5: 0x2AC6, 0x2AC6: 0x2AC5, 0x2AC7: 0x2AC8, 0x2AC8: 0x2AC7,
+	0x2AC9: 0x2ACA, 0x2ACA: 0x2AC9, 0x2ACB: 0x2ACC, 0x2ACC: 0x2ACB, 0x2ACD: 0x2ACE, 0x2ACE: 0x2ACD,
+	0x2ACF: 0x2AD0, 0x2AD0: 0x2ACF, 0x2AD1: 0x2AD2, 0x2AD2: 0x2AD1, 0x2AD3: 0x2AD4, 0x2AD4: 0x2AD3,
+	0x2AD5: 0x2AD6, 0x2AD6: 0x2AD5, 0x2ADE: 0x22A6, 0x2AE3: 0x22A9, 0x2AE4: 0x22A8, 0x2AE5: 0x22AB,
+	0x2AEC: 0x2AED, 0x2AED: 0x2AEC, 0x2AEE: 0x2224, 0x2AF7: 0x2AF8, 0x2AF8: 0x2AF7, 0x2AF9: 0x2AFA,
+	0x2AFA: 0x2AF9, 0x2BFE: 0x221F, 0x2E02: 0x2E03, 0x2E03: 0x2E02, 0x2E04: 0x2E05, 0x2E05: 0x2E04,
+	0x2E09: 0x2E0A, 0x2E0A: 0x2E09, 0x2E0C: 0x2E0D, 0x2E0D: 0x2E0C, 0x2E1C: 0x2E1D, 0x2E1D: 0x2E1C,
+	0x2E20: 0x2E21, 0x2E21: 0x2E20, 0x2E22: 0x2E23, 0x2E23: 0x2E22, 0x2E24: 0x2E25, 0x2E25: 0x2E24,
+	0x2E26: 0x2E27, 0x2E27: 0x2E26, 0x2E28: 0x2E29, 0x2E29: 0x2E28, 0x2E55: 0x2E56, 0x2E56: 0x2E55,
+	0x2E57: 0x2E58, 0x2E58: 0x2E57, 0x2E59: 0x2E5A, 0x2E5A: 0x2E59, 0x2E5B: 0x2E5C, 0x2E5C: 0x2E5B,
+	0x3008: 0x3009, 0x3009: 0x3008, 0x300A: 0x300B, 0x300B: 0x300A, 0x300C: 0x300D, 0x300D: 0x300C,
+	0x300E: 0x300F, 0x300F: 0x300E, 0x3010: 0x3011, 0x3011: 0x3010, 0x3014: 0x3015, 0x3015: 0x3014,
+	0x3016: 0x3017, 0x3017: 0x3016, 0x3018: 0x3019, 0x3019: 0x3018, 0x301A: 0x301B, 0x301B: 0x301A,
+	0xFE59: 0xFE5A, 0xFE5A: 0xFE59, 0xFE5B: 0xFE5C, 0xFE5C: 0xFE5B, 0xFE5D: 0xFE5E, 0xFE5E: 0xFE5D,
+	0xFE64: 0xFE65, 0xFE65: 0xFE64, 0xFF08: 0xFF09, 0xFF09: 0xFF08, 0xFF1C: 0xFF1E, 0xFF1E: 0xFF1C,
+	0xFF3B: 0xFF3D, 0xFF3D: 0xFF3B, 0xFF5B: 0xFF5D, 0xFF5D: 0xFF5B, 0xFF5F: 0xFF60, 0xFF60: 0xFF5F,
+	0xFF62: 0xFF63, 0xFF63: 0xFF62,
+}
+
+// getPairedBracket returns the other bracket of the pair and true if the bracket opens the pair.
+// Returns 0 if the character is not a paired bracket.
+func getPairedBracket(ch rune) (rune, bool) {
+	if closing, ok := bidiOpeningBrackets[ch]; ok {
+		return closing, true
+	}
+	if opening, ok := bidiClosingBrackets[ch]; ok {
+		return opening, false
+	}
+	return 0, false
+}
+
+// getMirror returns the mirrored character drawn in right-to-left text or the character itself.
+func getMirror(ch rune) rune {
+	if mirror, ok := bidiMirrors[ch]; ok {
+		return mirror
+	}
+	return ch
+}
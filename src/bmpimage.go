@@ -56,7 +56,20 @@ const (
 )
 
 // NewBMPImage constructs bitmap image objects.
+// The program exits if the image cannot be decoded. Use NewBMPImageErr to handle the error.
 func NewBMPImage(reader io.Reader) *BMPImage {
+	image, err := NewBMPImageErr(reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return image
+}
+
+// NewBMPImageErr constructs bitmap image objects.
+// Returns MalformedInputError or UnsupportedFeatureError when the image cannot be decoded.
+func NewBMPImageErr(reader io.Reader) (_ *BMPImage, err error) {
+	defer recoverMalformedInput("BMP image", &err)
+
 	image := new(BMPImage)
 
 	bm := getNBytes(reader, 2)
@@ -90,15 +103,17 @@ func NewBMPImage(reader io.Reader) *BMPImage {
 			skipNBytes(reader, 4)
 			image.parsePalette(reader, numpalcol)
 		}
-		image.parseData(reader)
+		if _, err := image.parseData(reader); err != nil {
+			return nil, err
+		}
 	} else {
-		log.Fatal("BMP data could not be parsed!")
+		return nil, malformed("BMP image", "unknown BMP signature")
 	}
 
-	return image
+	return image, nil
 }
 
-func (image *BMPImage) parseData(reader io.Reader) []byte {
+func (image *BMPImage) parseData(reader io.Reader) ([]byte, error) {
 	// rowsize is 4 * ceil (bpp*width/32.0)
 	bmpImage := make([]byte, 3*image.w*image.h)
 	rowsize := 4 * int(math.Ceil(float64(image.bpp)*float64(image.w)/float64(32.0))) // 4 byte alignment
@@ -122,7 +137,7 @@ func (image *BMPImage) parseData(reader io.Reader) []byte {
 		case 32:
 			row = image.bit32to24(row, image.w)
 		default:
-			log.Fatal("Can only parse 1 bit, 4bit, 8bit, 16bit, 24bit and 32bit images.")
+			return nil, unsupported("BMP image with %d bits per pixel", image.bpp)
 		}
 
 		index = image.w * (image.h - i - 1) * 3
@@ -148,7 +163,7 @@ func (image *BMPImage) parseData(reader io.Reader) []byte {
 	}
 	image.deflated = compressor.Deflate(bmpImage)
 
-	return bmpImage
+	return bmpImage, nil
 }
 
 // 5 + 6 + 5 in B G R format 2 bytes to 3 bytes
@@ -25,7 +25,6 @@ SOFTWARE.
 */
 
 import (
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -128,18 +127,12 @@ func (bookmark *Bookmark) AutoNumber(textLine *TextLine) *Bookmark {
 		} else {
 			index := strings.LastIndex(*bm.prefix, ".")
 			if index == -1 {
-				temp, err := strconv.Atoi(*bm.prefix)
-				if err != nil {
-					log.Fatal(err)
-				}
+				temp, _ := strconv.Atoi(*bm.prefix) // The prefix is always generated above
 				value := strconv.Itoa(temp + 1)
 				bookmark.prefix = &value
 			} else {
 				value := (*bm.prefix)[:index] + "."
-				temp, err := strconv.Atoi((*bm.prefix)[index+1:])
-				if err != nil {
-					log.Fatal(err)
-				}
+				temp, _ := strconv.Atoi((*bm.prefix)[index+1:])
 				value += strconv.Itoa(temp + 1)
 				bookmark.prefix = &value
 			}
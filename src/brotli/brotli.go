@@ -0,0 +1,689 @@
+package brotli
+
+/**
+ * brotli.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrCorrupt is returned when the compressed data is not valid Brotli stream.
+// The returned errors wrap ErrCorrupt with the reason - use errors.Is to test for it.
+var ErrCorrupt = errors.New("brotli: corrupt input")
+
+func corrupt(reason string) error {
+	return fmt.Errorf("%w: %s", ErrCorrupt, reason)
+}
+
+// Decompress decompresses the Brotli compressed data as specified in RFC 7932.
+// The program exits if the data is not valid. Use DecompressErr to handle the error.
+func Decompress(buf []byte) []byte {
+	out, err := DecompressErr(buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return out
+}
+
+// DecompressErr decompresses the Brotli compressed data as specified in RFC 7932.
+// Returns ErrCorrupt if the data is truncated, has reserved bits set, does not end
+// with the last meta-block or has data after it.
+func DecompressErr(buf []byte) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				if !errors.Is(e, ErrCorrupt) {
+					e = corrupt("truncated or corrupt data")
+				}
+				out, err = nil, e
+				return
+			}
+			panic(r)
+		}
+	}()
+	d := &decoder{br: bitReader{buf: buf}, dists: [4]int{4, 11, 15, 16}}
+	d.decode()
+	return d.out, nil
+}
+
+// bitReader reads the bits starting from the least significant bit of each byte.
+type bitReader struct {
+	buf     []byte
+	pos     int
+	val     uint64
+	nbits   uint
+	padding uint // The number of zero bits added after the end of the input
+}
+
+func (br *bitReader) fill(n uint) {
+	for br.nbits < n {
+		if br.pos < len(br.buf) {
+			br.val |= uint64(br.buf[br.pos]) << br.nbits
+			br.pos++
+		} else {
+			br.padding += 8
+		}
+		br.nbits += 8
+	}
+}
+
+func (br *bitReader) drop(n uint) {
+	br.val >>= n
+	br.nbits -= n
+	if br.nbits < br.padding {
+		panic(corrupt("the stream is truncated"))
+	}
+}
+
+// remaining returns the number of the input bits not read yet.
+func (br *bitReader) remaining() int {
+	return int(br.nbits-br.padding) + 8*(len(br.buf)-br.pos)
+}
+
+func (br *bitReader) readBits(n uint) int {
+	if n == 0 {
+		return 0
+	}
+	br.fill(n)
+	value := int(br.val & (1<<n - 1))
+	br.drop(n)
+	return value
+}
+
+// alignToByte skips the bits up to the byte boundary. The skipped bits must be zero.
+func (br *bitReader) alignToByte() {
+	if br.readBits(br.nbits%8) != 0 {
+		panic(corrupt("the padding bits are not zero"))
+	}
+}
+
+// huffman is the canonical prefix code.
+type huffman struct {
+	counts  [16]int // The number of codes of each length
+	symbols []int   // The symbols ordered by the code
+}
+
+func newHuffman(lengths []int) *huffman {
+	h := &huffman{}
+	for _, length := range lengths {
+		h.counts[length]++
+	}
+	h.counts[0] = 0
+	for length := 1; length <= 15; length++ {
+		for symbol, l := range lengths {
+			if l == length {
+				h.symbols = append(h.symbols, symbol)
+			}
+		}
+	}
+	if len(h.symbols) == 0 {
+		panic(ErrCorrupt)
+	}
+	return h
+}
+
+func (br *bitReader) readSymbol(h *huffman) int {
+	if len(h.symbols) == 1 {
+		return h.symbols[0]
+	}
+	br.fill(15)
+	v := br.val
+	code, first, index := 0, 0, 0
+	for length := uint(1); length <= 15; length++ {
+		code |= int(v & 1)
+		v >>= 1
+		count := h.counts[length]
+		if code < first+count {
+			br.drop(length)
+			return h.symbols[index+code-first]
+		}
+		index += count
+		first += count
+		first <<= 1
+		code <<= 1
+	}
+	panic(ErrCorrupt)
+}
+
+// The order of the code length code lengths.
+var codeLengthOrder = [18]int{1, 2, 3, 4, 0, 5, 17, 6, 16, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// The fixed prefix code of the code length code lengths indexed by the next four bits.
+var codeLengthPrefixLength = [16]uint{2, 2, 2, 3, 2, 2, 2, 4, 2, 2, 2, 3, 2, 2, 2, 4}
+var codeLengthPrefixValue = [16]int{0, 4, 3, 2, 0, 4, 3, 1, 0, 4, 3, 2, 0, 4, 3, 5}
+
+// readPrefixCode reads the simple or the complex prefix code.
+func (br *bitReader) readPrefixCode(alphabetSize int) *huffman {
+	lengths := make([]int, alphabetSize)
+	hskip := br.readBits(2)
+	if hskip == 1 {
+		alphabetBits := uint(0)
+		for (1 << alphabetBits) < alphabetSize {
+			alphabetBits++
+		}
+		numSymbols := br.readBits(2) + 1
+		symbols := make([]int, numSymbols)
+		for i := range symbols {
+			symbols[i] = br.readBits(alphabetBits)
+			if symbols[i] >= alphabetSize {
+				panic(ErrCorrupt)
+			}
+		}
+		switch numSymbols {
+		case 1:
+			return &huffman{symbols: symbols}
+		case 2:
+			lengths[symbols[0]], lengths[symbols[1]] = 1, 1
+		case 3:
+			lengths[symbols[0]], lengths[symbols[1]], lengths[symbols[2]] = 1, 2, 2
+		case 4:
+			if br.readBits(1) == 0 {
+				for _, symbol := range symbols {
+					lengths[symbol] = 2
+				}
+			} else {
+				lengths[symbols[0]], lengths[symbols[1]] = 1, 2
+				lengths[symbols[2]], lengths[symbols[3]] = 3, 3
+			}
+		}
+		return newHuffman(lengths)
+	}
+
+	var codeLengthLengths [18]int
+	space, num := 32, 0
+	for i := hskip; i < 18 && space > 0; i++ {
+		br.fill(4)
+		index := br.val & 0x0F
+		br.drop(codeLengthPrefixLength[index])
+		length := codeLengthPrefixValue[index]
+		codeLengthLengths[codeLengthOrder[i]] = length
+		if length != 0 {
+			space -= 32 >> length
+			num++
+		}
+	}
+	if num != 1 && space != 0 {
+		panic(ErrCorrupt)
+	}
+	codeLengthCode := newHuffman(codeLengthLengths[:])
+
+	symbol, prevLength, repeat, repeatLength := 0, 8, 0, 0
+	space = 32768
+	for symbol < alphabetSize && space > 0 {
+		length := br.readSymbol(codeLengthCode)
+		if length < 16 {
+			repeat = 0
+			lengths[symbol] = length
+			symbol++
+			if length != 0 {
+				prevLength = length
+				space -= 32768 >> length
+			}
+			continue
+		}
+		extraBits, newLength := uint(2), prevLength
+		if length == 17 {
+			extraBits, newLength = 3, 0
+		}
+		if repeatLength != newLength {
+			repeat, repeatLength = 0, newLength
+		}
+		oldRepeat := repeat
+		if repeat > 0 {
+			repeat = (repeat - 2) << extraBits
+		}
+		repeat += br.readBits(extraBits) + 3
+		delta := repeat - oldRepeat
+		if symbol+delta > alphabetSize {
+			panic(ErrCorrupt)
+		}
+		for i := 0; i < delta; i++ {
+			lengths[symbol] = repeatLength
+			symbol++
+		}
+		if repeatLength != 0 {
+			space -= delta * (32768 >> repeatLength)
+		}
+	}
+	if space != 0 {
+		panic(ErrCorrupt)
+	}
+	return newHuffman(lengths)
+}
+
+// readCount reads the number of block types or prefix trees from 1 to 256.
+func (br *bitReader) readCount() int {
+	if br.readBits(1) == 0 {
+		return 1
+	}
+	n := uint(br.readBits(3))
+	if n == 0 {
+		return 2
+	}
+	return 1<<n + br.readBits(n) + 1
+}
+
+// The base values and the extra bits of the block length codes.
+var blockLengthBase = [26]int{
+	1, 5, 9, 13, 17, 25, 33, 41, 49, 65, 81, 97, 113, 145, 177, 209,
+	241, 305, 369, 497, 753, 1265, 2289, 4337, 8433, 16625,
+}
+var blockLengthExtra = [26]uint{
+	2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 6, 6, 7, 8, 9, 10, 11, 12, 13, 24,
+}
+
+// The base values and the extra bits of the insert and copy length codes.
+var insertLengthBase = [24]int{
+	0, 1, 2, 3, 4, 5, 6, 8, 10, 14, 18, 26, 34, 50, 66, 98,
+	130, 194, 322, 578, 1090, 2114, 6210, 22594,
+}
+var insertLengthExtra = [24]uint{
+	0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 7, 8, 9, 10, 12, 14, 24,
+}
+var copyLengthBase = [24]int{
+	2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 14, 18, 22, 30, 38, 54,
+	70, 102, 134, 198, 326, 582, 1094, 2118,
+}
+var copyLengthExtra = [24]uint{
+	0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 7, 8, 9, 10, 24,
+}
+
+// The insert and copy length code offsets of the 64 command cells.
+var commandCells = [11][2]int{
+	{0, 0}, {0, 8}, {0, 0}, {0, 8}, {8, 0}, {8, 8}, {0, 16}, {16, 0}, {8, 16}, {16, 8}, {16, 16},
+}
+
+// blockState is the block switching state of the literals, commands or distances.
+type blockState struct {
+	numTypes   int
+	typeCode   *huffman
+	lengthTree *huffman
+	blockType  int
+	prevType   int
+	remaining  int
+}
+
+func (br *bitReader) readBlockState() *blockState {
+	s := &blockState{numTypes: br.readCount(), prevType: 1, remaining: 1 << 28}
+	if s.numTypes >= 2 {
+		s.typeCode = br.readPrefixCode(s.numTypes + 2)
+		s.lengthTree = br.readPrefixCode(26)
+		s.remaining = br.readBlockLength(s.lengthTree)
+	}
+	return s
+}
+
+func (br *bitReader) readBlockLength(tree *huffman) int {
+	code := br.readSymbol(tree)
+	return blockLengthBase[code] + br.readBits(blockLengthExtra[code])
+}
+
+// next switches the block type when the block ends.
+func (s *blockState) next(br *bitReader) {
+	if s.remaining == 0 {
+		code := br.readSymbol(s.typeCode)
+		blockType := code - 2
+		switch code {
+		case 0:
+			blockType = s.prevType
+		case 1:
+			blockType = (s.blockType + 1) % s.numTypes
+		}
+		if blockType >= s.numTypes {
+			panic(ErrCorrupt)
+		}
+		s.prevType, s.blockType = s.blockType, blockType
+		s.remaining = br.readBlockLength(s.lengthTree)
+	}
+	s.remaining--
+}
+
+// readContextMap reads the context map that maps the contexts to the prefix trees.
+func (br *bitReader) readContextMap(size, numTrees int) []int {
+	contextMap := make([]int, size)
+	if numTrees < 2 {
+		return contextMap
+	}
+	maxRunLengthPrefix := 0
+	if br.readBits(1) == 1 {
+		maxRunLengthPrefix = br.readBits(4) + 1
+	}
+	tree := br.readPrefixCode(numTrees + maxRunLengthPrefix)
+	for i := 0; i < size; {
+		code := br.readSymbol(tree)
+		switch {
+		case code == 0:
+			i++
+		case code <= maxRunLengthPrefix:
+			run := 1<<uint(code) + br.readBits(uint(code))
+			if i+run > size {
+				panic(ErrCorrupt)
+			}
+			i += run
+		default:
+			contextMap[i] = code - maxRunLengthPrefix
+			i++
+		}
+	}
+	if br.readBits(1) == 1 {
+		// The inverse move-to-front transform
+		var mtf [256]int
+		for i := range mtf {
+			mtf[i] = i
+		}
+		for i, index := range contextMap {
+			value := mtf[index]
+			contextMap[i] = value
+			copy(mtf[1:index+1], mtf[:index])
+			mtf[0] = value
+		}
+	}
+	for _, tree := range contextMap {
+		if tree >= numTrees {
+			panic(ErrCorrupt)
+		}
+	}
+	return contextMap
+}
+
+// The context modes of the literals.
+const (
+	contextLSB6 = iota
+	contextMSB6
+	contextUTF8
+	contextSigned
+)
+
+// The lookup tables of the UTF8 context mode from RFC 7932 section 7.1.
+var contextLut0 = [256]int{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 4, 0, 0, 4, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	8, 12, 16, 12, 12, 20, 12, 16, 24, 28, 12, 12, 32, 12, 36, 12,
+	44, 44, 44, 44, 44, 44, 44, 44, 44, 44, 32, 32, 24, 40, 28, 12,
+	12, 48, 52, 52, 52, 48, 52, 52, 52, 48, 52, 52, 52, 52, 52, 48,
+	52, 52, 52, 52, 52, 48, 52, 52, 52, 52, 52, 24, 12, 28, 12, 12,
+	12, 56, 60, 60, 60, 56, 60, 60, 60, 56, 60, 60, 60, 60, 60, 56,
+	60, 60, 60, 60, 60, 56, 60, 60, 60, 60, 60, 24, 12, 28, 12, 0,
+	0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1,
+	0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1,
+	0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1,
+	0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1,
+	2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3,
+	2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3,
+	2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3,
+	2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3,
+}
+
+var contextLut1 = [256]int{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 1, 1, 1, 1, 1, 1,
+	1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 1, 1, 1, 1, 1,
+	1, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 1, 1, 1, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+}
+
+// contextLut2 returns the value of the Signed context mode lookup table.
+func contextLut2(b byte) int {
+	switch {
+	case b == 0:
+		return 0
+	case b < 16:
+		return 1
+	case b < 64:
+		return 2
+	case b < 128:
+		return 3
+	case b < 192:
+		return 4
+	case b < 240:
+		return 5
+	case b < 255:
+		return 6
+	}
+	return 7
+}
+
+func getContext(mode int, p1, p2 byte) int {
+	switch mode {
+	case contextLSB6:
+		return int(p1 & 0x3F)
+	case contextMSB6:
+		return int(p1 >> 2)
+	case contextUTF8:
+		return contextLut0[p1] | contextLut1[p2]
+	}
+	return contextLut2(p1)<<3 | contextLut2(p2)
+}
+
+type decoder struct {
+	br    bitReader
+	out   []byte
+	dists [4]int // The last distances, the most recent first
+}
+
+func (d *decoder) decode() {
+	br := &d.br
+	windowBits := 16
+	if br.readBits(1) == 1 {
+		if n := br.readBits(3); n != 0 {
+			windowBits = 17 + n
+		} else if n = br.readBits(3); n == 1 {
+			panic(corrupt("the window size uses reserved value")) // Large window is not part of RFC 7932
+		} else if n != 0 {
+			windowBits = 8 + n
+		} else {
+			windowBits = 17
+		}
+	}
+	windowSize := 1<<uint(windowBits) - 16
+
+	for !d.decodeNextMetaBlock(windowSize) {
+		if br.remaining() == 0 {
+			panic(corrupt("the last meta-block is missing"))
+		}
+	}
+	// The bits after the last meta-block up to the byte boundary must be zero
+	br.alignToByte()
+	if br.remaining() != 0 {
+		panic(corrupt("there is data after the last meta-block"))
+	}
+}
+
+// decodeNextMetaBlock reads the header of the next meta-block and decodes it.
+// Returns true if it was the last meta-block.
+func (d *decoder) decodeNextMetaBlock(windowSize int) bool {
+	br := &d.br
+	isLast := br.readBits(1) == 1
+	if isLast && br.readBits(1) == 1 {
+		return true // ISEMPTY
+	}
+	numNibbles := br.readBits(2) + 4
+	if numNibbles == 7 {
+		// The metadata block
+		if br.readBits(1) != 0 {
+			panic(corrupt("the reserved bit of the metadata block is set"))
+		}
+		skipBytes := uint(br.readBits(2))
+		skipLength := 0
+		if skipBytes > 0 {
+			skipLength = br.readBits(8 * skipBytes)
+			if skipBytes > 1 && skipLength>>(8*(skipBytes-1)) == 0 {
+				panic(corrupt("the metadata length has extra zero byte"))
+			}
+			skipLength++
+		}
+		br.alignToByte()
+		for i := 0; i < skipLength; i++ {
+			br.readBits(8)
+		}
+		return isLast
+	}
+	length := br.readBits(uint(4 * numNibbles))
+	if numNibbles > 4 && length>>(4*(numNibbles-1)) == 0 {
+		panic(corrupt("the meta-block length has extra zero nibble"))
+	}
+	length++
+	if !isLast && br.readBits(1) == 1 {
+		// The uncompressed block
+		br.alignToByte()
+		for i := 0; i < length; i++ {
+			d.out = append(d.out, byte(br.readBits(8)))
+		}
+		return false
+	}
+	d.decodeMetaBlock(length, windowSize)
+	return isLast
+}
+
+func (d *decoder) decodeMetaBlock(length, windowSize int) {
+	br := &d.br
+	end := len(d.out) + length
+	literals := br.readBlockState()
+	commands := br.readBlockState()
+	distances := br.readBlockState()
+	postfixBits := uint(br.readBits(2))
+	numDirect := br.readBits(4) << postfixBits
+	modes := make([]int, literals.numTypes)
+	for i := range modes {
+		modes[i] = br.readBits(2)
+	}
+	numLiteralTrees := br.readCount()
+	literalMap := br.readContextMap(64*literals.numTypes, numLiteralTrees)
+	numDistanceTrees := br.readCount()
+	distanceMap := br.readContextMap(4*distances.numTypes, numDistanceTrees)
+	literalTrees := make([]*huffman, numLiteralTrees)
+	for i := range literalTrees {
+		literalTrees[i] = br.readPrefixCode(256)
+	}
+	commandTrees := make([]*huffman, commands.numTypes)
+	for i := range commandTrees {
+		commandTrees[i] = br.readPrefixCode(704)
+	}
+	distanceTrees := make([]*huffman, numDistanceTrees)
+	for i := range distanceTrees {
+		distanceTrees[i] = br.readPrefixCode(16 + numDirect + 48<<postfixBits)
+	}
+
+	for len(d.out) < end {
+		commands.next(br)
+		command := br.readSymbol(commandTrees[commands.blockType])
+		cell := commandCells[command>>6]
+		insertCode := cell[0] + (command>>3)&7
+		copyCode := cell[1] + command&7
+		insertLength := insertLengthBase[insertCode] + br.readBits(insertLengthExtra[insertCode])
+		copyLength := copyLengthBase[copyCode] + br.readBits(copyLengthExtra[copyCode])
+		for i := 0; i < insertLength; i++ {
+			literals.next(br)
+			var p1, p2 byte
+			if n := len(d.out); n > 1 {
+				p1, p2 = d.out[n-1], d.out[n-2]
+			} else if n == 1 {
+				p1 = d.out[0]
+			}
+			context := getContext(modes[literals.blockType], p1, p2)
+			tree := literalMap[64*literals.blockType+context]
+			d.out = append(d.out, byte(br.readSymbol(literalTrees[tree])))
+		}
+		if len(d.out) >= end {
+			break
+		}
+
+		distanceCode := 0
+		if command >= 128 {
+			distances.next(br)
+			context := 3
+			if copyLength <= 4 {
+				context = copyLength - 2
+			}
+			tree := distanceMap[4*distances.blockType+context]
+			distanceCode = br.readSymbol(distanceTrees[tree])
+		}
+		distance := d.getDistance(distanceCode, postfixBits, numDirect)
+		maxDistance := len(d.out)
+		if maxDistance > windowSize {
+			maxDistance = windowSize
+		}
+		if distance > maxDistance {
+			// The static dictionary reference
+			if copyLength < 4 || copyLength > 24 {
+				panic(ErrCorrupt)
+			}
+			wordID := distance - maxDistance - 1
+			sizeBits := dictionarySizeBits[copyLength]
+			index := wordID & (1<<sizeBits - 1)
+			transformID := wordID >> sizeBits
+			if transformID >= len(transforms) {
+				panic(ErrCorrupt)
+			}
+			offset := dictionaryOffsets[copyLength] + index*copyLength
+			d.out = transformWord(d.out, dictionary[offset:offset+copyLength], transformID)
+		} else {
+			if distanceCode != 0 {
+				d.dists = [4]int{distance, d.dists[0], d.dists[1], d.dists[2]}
+			}
+			start := len(d.out) - distance
+			for i := 0; i < copyLength; i++ {
+				d.out = append(d.out, d.out[start+i])
+			}
+		}
+		if len(d.out) > end {
+			panic(ErrCorrupt)
+		}
+	}
+}
+
+// The last distance index and the offset of the short distance codes.
+var shortDistanceIndex = [16]int{0, 1, 2, 3, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1}
+var shortDistanceOffset = [16]int{0, 0, 0, 0, -1, 1, -2, 2, -3, 3, -1, 1, -2, 2, -3, 3}
+
+func (d *decoder) getDistance(code int, postfixBits uint, numDirect int) int {
+	if code < 16 {
+		distance := d.dists[shortDistanceIndex[code]] + shortDistanceOffset[code]
+		if distance <= 0 {
+			panic(ErrCorrupt)
+		}
+		return distance
+	}
+	if code < 16+numDirect {
+		return code - 15
+	}
+	code -= numDirect + 16
+	extraBits := uint(1 + code>>(postfixBits+1))
+	offset := (2 + (code>>postfixBits)&1) << extraBits
+	offset -= 4
+	return (offset+d.br.readBits(extraBits))<<postfixBits + code&(1<<postfixBits-1) + numDirect + 1
+}
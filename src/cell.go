@@ -25,8 +25,6 @@ SOFTWARE.
 */
 
 import (
-	"log"
-
 	"github.com/edragoev1/pdfjet/src/align"
 	"github.com/edragoev1/pdfjet/src/border"
 	"github.com/edragoev1/pdfjet/src/color"
@@ -39,6 +37,7 @@ import (
 type Cell struct {
 	font              *Font
 	fallbackFont      *Font
+	fontChain         *FontChain
 	text              *string
 	image             *Image
 	barcode           *Barcode
@@ -107,12 +106,28 @@ func NewCell(font *Font, text string) *Cell {
 // @param font the font.
 func (cell *Cell) SetFont(font *Font) {
 	cell.font = font
+	cell.fontChain = nil
 }
 
 // SetFallbackFont sets the fallback font for this cell.
 // @param fallbackFont the fallback font.
 func (cell *Cell) SetFallbackFont(fallbackFont *Font) {
 	cell.fallbackFont = fallbackFont
+	cell.fontChain = nil
+}
+
+// SetFontChain sets the fonts used to draw the cell text.
+// The cell is as high as the tallest font in the chain.
+// @param chain the font chain.
+func (cell *Cell) SetFontChain(chain *FontChain) {
+	cell.font = chain.GetFont()
+	cell.fallbackFont = nil
+	cell.fontChain = chain
+}
+
+// getFonts returns the font chain or the font followed by the fallback font.
+func (cell *Cell) getFonts() []*Font {
+	return getFonts(cell.fontChain, cell.font, cell.fallbackFont)
 }
 
 // GetFont returns the font used by this cell.
@@ -250,9 +265,11 @@ func (cell *Cell) GetHeight(width float32) float32 {
 	} else if cell.barcode != nil {
 		cellHeight = cell.barcode.GetHeight() + cell.topPadding + cell.bottomPadding
 	} else if cell.text != nil {
-		fontHeight := cell.font.GetHeight()
-		if cell.fallbackFont != nil && cell.fallbackFont.GetHeight() > fontHeight {
-			fontHeight = cell.fallbackFont.GetHeight()
+		fontHeight := float32(0.0)
+		for _, font := range cell.getFonts() {
+			if font.GetHeight() > fontHeight {
+				fontHeight = font.GetHeight()
+			}
 		}
 		cellHeight = fontHeight + cell.topPadding + cell.bottomPadding
 	}
@@ -361,6 +378,7 @@ func (cell *Cell) SetBorders(borders bool) {
 // SetTextAlignment sets the cell text alignment.
 // @param alignment the alignment code.
 // Supported values: align.Left, align.Right and align.Center
+// The text of align.Justify cells is drawn left aligned.
 func (cell *Cell) SetTextAlignment(alignment int) {
 	cell.properties &= 0x00CFFFFF
 	cell.properties |= (uint32(alignment) & 0x00300000)
@@ -375,6 +393,7 @@ func (cell *Cell) GetTextAlignment() int {
 // SetVerTextAlignment sets the cell text vertical alignment.
 // @param alignment the alignment code.
 // Supported values: align.Top, align.Center and align.Bottom
+// Other values are treated as align.Top.
 func (cell *Cell) SetVerTextAlignment(alignment int) {
 	cell.valign = alignment
 }
@@ -538,22 +557,20 @@ func (cell *Cell) drawBorders(page *Page, x, y, cellW, cellH float32) {
 func (cell *Cell) DrawText(page *Page, x, y, wCell, hCell float32) {
 	var xText float32
 	var yText float32
-	if cell.valign == align.Top {
-		yText = y + cell.font.ascent + cell.topPadding
-	} else if cell.valign == align.Center {
+	if cell.valign == align.Center {
 		yText = y + hCell/2 + cell.font.ascent/2
 	} else if cell.valign == align.Bottom {
 		yText = (y + hCell) - cell.bottomPadding
-	} else {
-		log.Fatal("Invalid vertical text alignment option.")
+	} else { // align.Top
+		yText = y + cell.font.ascent + cell.topPadding
 	}
 
 	page.SetPenColor(cell.pen)
 	if cell.GetTextAlignment() == align.Right {
 		if cell.compositeTextLine == nil {
-			xText = (x + wCell) - (cell.font.stringWidth(*cell.text) + cell.rightPadding)
+			xText = (x + wCell) - (getTextWidth(cell.getFonts(), *cell.text) + cell.rightPadding)
 			page.AddBMC("Span", "", *cell.text, *cell.text)
-			page.DrawStringUsingColorMap(cell.font, cell.fallbackFont, *cell.text, xText, yText, cell.brush, nil)
+			page.drawStringUsingColorMap(cell.getFonts(), *cell.text, -1, xText, yText, cell.brush, nil)
 			page.AddEMC()
 			if cell.GetUnderline() {
 				cell.UnderlineText(page, cell.font, *cell.text, xText, yText)
@@ -571,9 +588,9 @@ func (cell *Cell) DrawText(page *Page, x, y, wCell, hCell float32) {
 	} else if cell.GetTextAlignment() == align.Center {
 		if cell.compositeTextLine == nil {
 			xText = x + cell.leftPadding +
-				(((wCell - (cell.leftPadding + cell.rightPadding)) - cell.font.stringWidth(*cell.text)) / 2)
+				(((wCell - (cell.leftPadding + cell.rightPadding)) - getTextWidth(cell.getFonts(), *cell.text)) / 2)
 			page.AddBMC("Span", "", *cell.text, *cell.text)
-			page.DrawStringUsingColorMap(cell.font, cell.fallbackFont, *cell.text, xText, yText, cell.brush, nil)
+			page.drawStringUsingColorMap(cell.getFonts(), *cell.text, -1, xText, yText, cell.brush, nil)
 			page.AddEMC()
 			if cell.GetUnderline() {
 				cell.UnderlineText(page, cell.font, *cell.text, xText, yText)
@@ -589,11 +606,11 @@ func (cell *Cell) DrawText(page *Page, x, y, wCell, hCell float32) {
 			cell.compositeTextLine.DrawOn(page)
 			page.AddEMC()
 		}
-	} else if cell.GetTextAlignment() == align.Left {
+	} else { // align.Left - the single line of text cannot be justified
 		xText = x + cell.leftPadding
 		if cell.compositeTextLine == nil {
 			page.AddBMC("Span", "", *cell.text, *cell.text)
-			page.DrawStringUsingColorMap(cell.font, cell.fallbackFont, *cell.text, xText, yText, cell.brush, nil)
+			page.drawStringUsingColorMap(cell.getFonts(), *cell.text, -1, xText, yText, cell.brush, nil)
 			page.AddEMC()
 			if cell.GetUnderline() {
 				cell.UnderlineText(page, cell.font, *cell.text, xText, yText)
@@ -607,8 +624,6 @@ func (cell *Cell) DrawText(page *Page, x, y, wCell, hCell float32) {
 			cell.compositeTextLine.DrawOn(page)
 			page.AddEMC()
 		}
-	} else {
-		log.Fatal("Invalid Text Alignment!")
 	}
 
 	if cell.uri != nil || cell.key != nil {
@@ -616,7 +631,7 @@ func (cell *Cell) DrawText(page *Page, x, y, wCell, hCell float32) {
 		if cell.compositeTextLine != nil {
 			w = cell.compositeTextLine.GetWidth()
 		} else {
-			w = cell.font.stringWidth(*cell.text)
+			w = getTextWidth(cell.getFonts(), *cell.text)
 		}
 		page.AddAnnotation(NewAnnotation(
 			cell.uri,
@@ -636,7 +651,7 @@ func (cell *Cell) UnderlineText(page *Page, font *Font, text string, x, y float3
 	page.AddBMC("Span", "", "underline", "underline")
 	page.SetPenWidth(font.underlineThickness)
 	page.MoveTo(x, y+font.descent)
-	page.LineTo(x+font.stringWidth(text), y+font.descent)
+	page.LineTo(x+cell.getTextWidth(font, text), y+font.descent)
 	page.StrokePath()
 	page.AddEMC()
 }
@@ -646,11 +661,19 @@ func (cell *Cell) StrikeoutText(page *Page, font *Font, text string, x, y float3
 	page.AddBMC("Span", "", "strike out", "strike out")
 	page.SetPenWidth(font.underlineThickness)
 	page.MoveTo(x, y-font.GetAscent()/3.0)
-	page.LineTo(x+font.stringWidth(text), y-font.GetAscent()/3.0)
+	page.LineTo(x+cell.getTextWidth(font, text), y-font.GetAscent()/3.0)
 	page.StrokePath()
 	page.AddEMC()
 }
 
+// getTextWidth returns the width of the text drawn with the font and the cell's fallback fonts.
+func (cell *Cell) getTextWidth(font *Font, text string) float32 {
+	if font == cell.font {
+		return getTextWidth(cell.getFonts(), text)
+	}
+	return font.StringWidth(cell.fallbackFont, text)
+}
+
 // GetTextBox returns the cell's text box.
 func (cell *Cell) GetTextBox() *TextBox {
 	return cell.textBox
@@ -0,0 +1,468 @@
+package pdfjet
+
+/**
+ * cffsubset.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"encoding/binary"
+)
+
+// The CFF DICT operators that hold offsets.
+// See Adobe Technical Note #5176, The Compact Font Format Specification.
+const (
+	cffCharset     = 15
+	cffEncoding    = 16
+	cffCharStrings = 17
+	cffPrivate     = 18
+	cffSubrs       = 19
+	cffFDArray     = 0x0C24
+	cffFDSelect    = 0x0C25
+)
+
+// cffDictEntry is one operator with its operands in the original encoding.
+type cffDictEntry struct {
+	op       int
+	operands [][]byte
+}
+
+// cffIndex is CFF INDEX - array of variable length objects.
+type cffIndex struct {
+	items [][]byte
+	size  int // The number of bytes used by the INDEX in the font
+}
+
+// subsetCFF returns the CFF font with the charstrings of the unused glyphs replaced by endchar.
+// The glyph order, the charset and the FDSelect are not changed.
+// The global and the local subroutines are kept.
+func subsetCFF(buf []byte, used []bool) (_ []byte, err error) {
+	defer recoverMalformedInput("CFF font", &err)
+
+	if buf[0] != 1 {
+		return nil, unsupported("CFF version %d", buf[0])
+	}
+	hdrSize := int(buf[2])
+	offset := hdrSize
+	names := readCFFIndex(buf, offset)
+	offset += names.size
+	topDicts := readCFFIndex(buf, offset)
+	offset += topDicts.size
+	strs := readCFFIndex(buf, offset)
+	offset += strs.size
+	gsubrs := readCFFIndex(buf, offset)
+	offset += gsubrs.size
+	if len(topDicts.items) != 1 {
+		return nil, malformed("CFF font", "expected one Top DICT, found %d", len(topDicts.items))
+	}
+	topDict := readCFFDict(topDicts.items[0])
+	if topDict == nil {
+		return nil, malformed("CFF font", "bad Top DICT")
+	}
+	// The String INDEX and the Global Subr INDEX are copied without changes
+	strsAndGSubrs := buf[hdrSize+names.size+topDicts.size : offset]
+
+	charStringsOffset := getCFFDictInt(topDict, cffCharStrings, 0)
+	if charStringsOffset == 0 {
+		return nil, malformed("CFF font", "missing CharStrings")
+	}
+	charStrings := readCFFIndex(buf, charStringsOffset)
+	numGlyphs := len(charStrings.items)
+
+	// The tables copied without changes
+	var encoding, charset, fdSelect []byte
+	if off := getCFFDictInt(topDict, cffEncoding, 0); off > 1 {
+		size := getCFFEncodingSize(buf, off)
+		if size == 0 {
+			return nil, malformed("CFF font", "unknown Encoding format")
+		}
+		encoding = buf[off : off+size]
+	}
+	if off := getCFFDictInt(topDict, cffCharset, 0); off > 2 {
+		size := getCFFCharsetSize(buf, off, numGlyphs)
+		if size == 0 {
+			return nil, malformed("CFF font", "unknown charset format")
+		}
+		charset = buf[off : off+size]
+	}
+	if off := getCFFDictInt(topDict, cffFDSelect, 0); off != 0 {
+		size := getCFFFDSelectSize(buf, off, numGlyphs)
+		if size == 0 {
+			return nil, malformed("CFF font", "unknown FDSelect format")
+		}
+		fdSelect = buf[off : off+size]
+	}
+
+	// The new charstrings
+	endchar := []byte{14}
+	items := make([][]byte, numGlyphs)
+	for gid := range items {
+		if gid < len(used) && used[gid] {
+			items[gid] = charStrings.items[gid]
+		} else {
+			items[gid] = endchar
+		}
+	}
+	newCharStrings := writeCFFIndex(items)
+
+	// The Font DICTs of CID-keyed font
+	var fontDicts [][]cffDictEntry
+	if off := getCFFDictInt(topDict, cffFDArray, 0); off != 0 {
+		for _, item := range readCFFIndex(buf, off).items {
+			dict := readCFFDict(item)
+			if dict == nil {
+				return nil, malformed("CFF font", "bad Font DICT")
+			}
+			fontDicts = append(fontDicts, dict)
+		}
+	}
+
+	// The Private DICTs with their local subroutines.
+	// The Subrs offset is relative to the start of the Private DICT
+	// so the Private DICT is written before the subroutines.
+	privates := make([][]byte, 0)
+	getPrivate := func(dict []cffDictEntry) []byte {
+		size := getCFFDictInt(dict, cffPrivate, 0)
+		off := getCFFDictInt(dict, cffPrivate, 1)
+		if size == 0 {
+			return nil
+		}
+		private := readCFFDict(buf[off : off+size])
+		subrs := getCFFDictInt(private, cffSubrs, 0)
+		if private == nil || subrs == 0 {
+			return buf[off : off+size]
+		}
+		setCFFDictInt(private, cffSubrs, 0) // Placeholder
+		data := writeCFFDict(private)
+		setCFFDictInt(private, cffSubrs, len(data))
+		data = writeCFFDict(private)
+		index := readCFFIndex(buf, off+subrs)
+		return append(data, buf[off+subrs:off+subrs+index.size]...)
+	}
+	privates = append(privates, getPrivate(topDict))
+	for _, dict := range fontDicts {
+		privates = append(privates, getPrivate(dict))
+	}
+
+	// Compute the layout. The offsets are encoded with fixed size,
+	// so the size of the Top DICT does not depend on their values.
+	setCFFDictInt(topDict, cffCharStrings, 0)
+	setCFFDictInt(topDict, cffFDArray, 0)
+	if encoding != nil {
+		setCFFDictInt(topDict, cffEncoding, 0)
+	}
+	if charset != nil {
+		setCFFDictInt(topDict, cffCharset, 0)
+	}
+	if fdSelect != nil {
+		setCFFDictInt(topDict, cffFDSelect, 0)
+	}
+	if privates[0] != nil {
+		setCFFDictPrivate(topDict, 0, 0)
+	}
+	headerSize := hdrSize + names.size + len(writeCFFIndex([][]byte{writeCFFDict(topDict)})) + len(strsAndGSubrs)
+	position := headerSize
+	encodingOffset := position
+	position += len(encoding)
+	charsetOffset := position
+	position += len(charset)
+	fdSelectOffset := position
+	position += len(fdSelect)
+	charStringsOffset = position
+	position += len(newCharStrings)
+
+	privateOffsets := make([]int, len(privates))
+	fdArrayOffset := position
+	var newFDArray []byte
+	if fontDicts != nil {
+		// The size of the FDArray does not depend on the Private offsets.
+		newFDArray = writeCFFFontDicts(fontDicts, privates[1:], make([]int, len(fontDicts)))
+		position += len(newFDArray)
+	}
+	for i, private := range privates {
+		privateOffsets[i] = position
+		position += len(private)
+	}
+	if fontDicts != nil {
+		newFDArray = writeCFFFontDicts(fontDicts, privates[1:], privateOffsets[1:])
+		setCFFDictInt(topDict, cffFDArray, fdArrayOffset)
+	}
+
+	setCFFDictInt(topDict, cffCharStrings, charStringsOffset)
+	if encoding != nil {
+		setCFFDictInt(topDict, cffEncoding, encodingOffset)
+	}
+	if charset != nil {
+		setCFFDictInt(topDict, cffCharset, charsetOffset)
+	}
+	if fdSelect != nil {
+		setCFFDictInt(topDict, cffFDSelect, fdSelectOffset)
+	}
+	if privates[0] != nil {
+		setCFFDictPrivate(topDict, len(privates[0]), privateOffsets[0])
+	}
+
+	out := make([]byte, 0, position)
+	out = append(out, buf[:hdrSize]...)
+	out = append(out, buf[hdrSize:hdrSize+names.size]...)
+	out = append(out, writeCFFIndex([][]byte{writeCFFDict(topDict)})...)
+	out = append(out, strsAndGSubrs...)
+	out = append(out, encoding...)
+	out = append(out, charset...)
+	out = append(out, fdSelect...)
+	out = append(out, newCharStrings...)
+	out = append(out, newFDArray...)
+	for _, private := range privates {
+		out = append(out, private...)
+	}
+	return out, nil
+}
+
+// writeCFFFontDicts returns the FDArray INDEX with the Private DICT offsets updated.
+func writeCFFFontDicts(fontDicts [][]cffDictEntry, privates [][]byte, offsets []int) []byte {
+	items := make([][]byte, len(fontDicts))
+	for i, dict := range fontDicts {
+		if privates[i] != nil {
+			setCFFDictPrivate(dict, len(privates[i]), offsets[i])
+		}
+		items[i] = writeCFFDict(dict)
+	}
+	return writeCFFIndex(items)
+}
+
+// readCFFIndex reads the INDEX at the offset.
+func readCFFIndex(buf []byte, offset int) *cffIndex {
+	index := new(cffIndex)
+	count := int(binary.BigEndian.Uint16(buf[offset:]))
+	if count == 0 {
+		index.size = 2
+		return index
+	}
+	offSize := int(buf[offset+2])
+	readOffset := func(i int) int {
+		value := 0
+		for _, b := range buf[offset+3+i*offSize : offset+3+(i+1)*offSize] {
+			value = value<<8 | int(b)
+		}
+		return value
+	}
+	base := offset + 3 + (count+1)*offSize - 1
+	index.items = make([][]byte, count)
+	for i := 0; i < count; i++ {
+		index.items[i] = buf[base+readOffset(i) : base+readOffset(i+1)]
+	}
+	index.size = base + readOffset(count) - offset
+	return index
+}
+
+// writeCFFIndex returns the INDEX with the specified items.
+func writeCFFIndex(items [][]byte) []byte {
+	if len(items) == 0 {
+		return []byte{0, 0}
+	}
+	last := 1
+	for _, item := range items {
+		last += len(item)
+	}
+	offSize := 1
+	for value := last; value > 0xFF; value >>= 8 {
+		offSize++
+	}
+	out := make([]byte, 0, 3+(len(items)+1)*offSize+last)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(items)))
+	out = append(out, byte(offSize))
+	appendOffset := func(value int) {
+		for i := offSize - 1; i >= 0; i-- {
+			out = append(out, byte(value>>(8*i)))
+		}
+	}
+	offset := 1
+	appendOffset(offset)
+	for _, item := range items {
+		offset += len(item)
+		appendOffset(offset)
+	}
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+// readCFFDict returns the entries of the DICT or nil if the DICT has reserved bytes.
+func readCFFDict(data []byte) []cffDictEntry {
+	entries := make([]cffDictEntry, 0)
+	operands := make([][]byte, 0)
+	for i := 0; i < len(data); {
+		b0 := data[i]
+		start := i
+		switch {
+		case b0 == 12:
+			entries = append(entries, cffDictEntry{op: 0x0C00 | int(data[i+1]), operands: operands})
+			operands = make([][]byte, 0)
+			i += 2
+			continue
+		case b0 <= 21:
+			entries = append(entries, cffDictEntry{op: int(b0), operands: operands})
+			operands = make([][]byte, 0)
+			i++
+			continue
+		case b0 == 28:
+			i += 3
+		case b0 == 29:
+			i += 5
+		case b0 == 30: // Real number
+			i++
+			for data[i]&0x0F != 0x0F && data[i]&0xF0 != 0xF0 {
+				i++
+			}
+			i++
+		case b0 >= 32 && b0 <= 246:
+			i++
+		case b0 >= 247 && b0 <= 254:
+			i += 2
+		default:
+			return nil
+		}
+		operands = append(operands, data[start:i])
+	}
+	return entries
+}
+
+// writeCFFDict returns the encoded DICT.
+func writeCFFDict(entries []cffDictEntry) []byte {
+	out := make([]byte, 0)
+	for _, entry := range entries {
+		for _, operand := range entry.operands {
+			out = append(out, operand...)
+		}
+		if entry.op > 0xFF {
+			out = append(out, 12, byte(entry.op))
+		} else {
+			out = append(out, byte(entry.op))
+		}
+	}
+	return out
+}
+
+// getCFFDictInt returns the integer operand of the operator or zero if the operator is not present.
+func getCFFDictInt(entries []cffDictEntry, op, i int) int {
+	for _, entry := range entries {
+		if entry.op == op && i < len(entry.operands) {
+			return decodeCFFInt(entry.operands[i])
+		}
+	}
+	return 0
+}
+
+// setCFFDictInt sets the operand of the operator encoded as 32 bit integer.
+func setCFFDictInt(entries []cffDictEntry, op, value int) {
+	for i := range entries {
+		if entries[i].op == op {
+			entries[i].operands = [][]byte{encodeCFFInt(value)}
+		}
+	}
+}
+
+// setCFFDictPrivate sets the size and the offset of the Private DICT.
+func setCFFDictPrivate(entries []cffDictEntry, size, offset int) {
+	for i := range entries {
+		if entries[i].op == cffPrivate {
+			entries[i].operands = [][]byte{encodeCFFInt(size), encodeCFFInt(offset)}
+		}
+	}
+}
+
+func encodeCFFInt(value int) []byte {
+	return []byte{29, byte(value >> 24), byte(value >> 16), byte(value >> 8), byte(value)}
+}
+
+func decodeCFFInt(operand []byte) int {
+	b0 := int(operand[0])
+	switch {
+	case b0 == 28:
+		return int(int16(binary.BigEndian.Uint16(operand[1:])))
+	case b0 == 29:
+		return int(int32(binary.BigEndian.Uint32(operand[1:])))
+	case b0 >= 32 && b0 <= 246:
+		return b0 - 139
+	case b0 >= 247 && b0 <= 250:
+		return (b0-247)*256 + int(operand[1]) + 108
+	case b0 >= 251 && b0 <= 254:
+		return -(b0-251)*256 - int(operand[1]) - 108
+	}
+	return 0 // Real numbers are not used for offsets
+}
+
+// getCFFEncodingSize returns the size of the Encoding including the supplements.
+// Returns zero for unknown format.
+func getCFFEncodingSize(buf []byte, offset int) int {
+	format := buf[offset]
+	size := 0
+	switch format & 0x7F {
+	case 0:
+		size = 2 + int(buf[offset+1])
+	case 1:
+		size = 2 + 2*int(buf[offset+1])
+	default:
+		return 0
+	}
+	if format&0x80 != 0 {
+		size += 1 + 3*int(buf[offset+size])
+	}
+	return size
+}
+
+// getCFFCharsetSize returns the size of the charset.
+// The charset does not include the .notdef glyph. Returns zero for unknown format.
+func getCFFCharsetSize(buf []byte, offset, numGlyphs int) int {
+	switch buf[offset] {
+	case 0:
+		return 1 + 2*(numGlyphs-1)
+	case 1, 2:
+		rangeSize := 3
+		if buf[offset] == 2 {
+			rangeSize = 4
+		}
+		i := offset + 1
+		for covered := 1; covered < numGlyphs; i += rangeSize {
+			nLeft := int(buf[i+2])
+			if rangeSize == 4 {
+				nLeft = int(binary.BigEndian.Uint16(buf[i+2:]))
+			}
+			covered += nLeft + 1
+		}
+		return i - offset
+	}
+	return 0
+}
+
+// getCFFFDSelectSize returns the size of the FDSelect. Returns zero for unknown format.
+func getCFFFDSelectSize(buf []byte, offset, numGlyphs int) int {
+	switch buf[offset] {
+	case 0:
+		return 1 + numGlyphs
+	case 3:
+		nRanges := int(binary.BigEndian.Uint16(buf[offset+1:]))
+		return 3 + 3*nRanges + 2
+	}
+	return 0
+}
@@ -0,0 +1,155 @@
+package pdfjet
+
+/**
+ * checkboxfield.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"github.com/edragoev1/pdfjet/src/color"
+	"github.com/edragoev1/pdfjet/src/mark"
+)
+
+// CheckBoxField is interactive check box that can be checked in any PDF viewer.
+// The field is written as widget annotation with on and off appearance streams by PDF.Complete.
+type CheckBoxField struct {
+	interactiveField
+	checked     bool
+	exportValue string
+	mark        int
+	markColor   int32
+}
+
+// NewCheckBoxField constructs check box with the specified field name.
+func NewCheckBoxField(name string) *CheckBoxField {
+	field := new(CheckBoxField)
+	field.init(nil, name)
+	field.w = 12.0
+	field.h = 12.0
+	field.exportValue = "Yes"
+	field.mark = mark.Check
+	field.markColor = color.Black
+	return field
+}
+
+// SetLocation sets the location of the top left corner of the check box on the page.
+func (field *CheckBoxField) SetLocation(x, y float32) *CheckBoxField {
+	field.x = x
+	field.y = y
+	return field
+}
+
+// SetSize sets the size of the check box.
+func (field *CheckBoxField) SetSize(size float32) *CheckBoxField {
+	field.w = size
+	field.h = size
+	return field
+}
+
+// SetChecked checks or unchecks the check box.
+func (field *CheckBoxField) SetChecked(checked bool) *CheckBoxField {
+	field.checked = checked
+	return field
+}
+
+// SetExportValue sets the value of the field when the check box is checked. The default is "Yes".
+func (field *CheckBoxField) SetExportValue(exportValue string) *CheckBoxField {
+	field.exportValue = exportValue
+	return field
+}
+
+// SetMark sets the check mark - mark.Check or mark.X.
+func (field *CheckBoxField) SetMark(mark int) *CheckBoxField {
+	field.mark = mark
+	return field
+}
+
+// SetMarkColor sets the color of the check mark.
+func (field *CheckBoxField) SetMarkColor(markColor int32) *CheckBoxField {
+	field.markColor = markColor
+	return field
+}
+
+// SetToolTip sets the text shown by the viewers when the mouse is over the field.
+// The text is also used as alternate description of the field.
+func (field *CheckBoxField) SetToolTip(toolTip string) *CheckBoxField {
+	field.toolTip = toolTip
+	return field
+}
+
+// SetReadOnly sets the flag that prevents the user from changing the value.
+func (field *CheckBoxField) SetReadOnly(readOnly bool) *CheckBoxField {
+	field.setFlag(fieldReadOnly, readOnly)
+	return field
+}
+
+// SetRequired sets the flag that requires value before the form is submitted.
+func (field *CheckBoxField) SetRequired(required bool) *CheckBoxField {
+	field.setFlag(fieldRequired, required)
+	return field
+}
+
+// SetBorderColor sets the color of the border. Use color.Transparent for no border.
+func (field *CheckBoxField) SetBorderColor(borderColor int32) *CheckBoxField {
+	field.borderColor = borderColor
+	return field
+}
+
+// SetBackgroundColor sets the background color. The default is color.Transparent.
+func (field *CheckBoxField) SetBackgroundColor(backgroundColor int32) *CheckBoxField {
+	field.backgroundColor = backgroundColor
+	return field
+}
+
+// DrawOn adds the check box to the page and returns the coordinates of the bottom right corner.
+func (field *CheckBoxField) DrawOn(page *Page) [2]float32 {
+	w := field.newWidget("Btn")
+	w.state = field.exportValue
+	w.checked = field.checked
+	w.caption = "4" // The ZapfDingbats check mark
+	if field.mark == mark.X {
+		w.caption = "8"
+	}
+
+	appearance := field.newAppearance(page.pdf)
+	size := field.w
+	checkWidth := size / 5
+	appearance.SetPenWidth(checkWidth)
+	appearance.SetPenColor(field.markColor)
+	if field.mark == mark.X {
+		appearance.MoveTo(checkWidth, checkWidth)
+		appearance.LineTo(size-checkWidth, size-checkWidth)
+		appearance.MoveTo(size-checkWidth, checkWidth)
+		appearance.LineTo(checkWidth, size-checkWidth)
+	} else {
+		appearance.MoveTo(checkWidth, size/2)
+		appearance.LineTo(size/6+checkWidth, size-4.0*checkWidth/3.0)
+		appearance.LineTo(size-checkWidth, checkWidth)
+	}
+	appearance.StrokePath()
+	w.normal = page.pdf.addAppearanceStream(appearance, nil)
+	w.off = page.pdf.addAppearanceStream(field.newAppearance(page.pdf), nil)
+
+	field.addWidget(page, w, field.x, field.y, field.w, field.h)
+	page.pdf.formFields = append(page.pdf.formFields, w)
+	return [2]float32{field.x + field.w, field.y + field.h}
+}
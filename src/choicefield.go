@@ -0,0 +1,175 @@
+package pdfjet
+
+/**
+ * choicefield.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"github.com/edragoev1/pdfjet/src/align"
+)
+
+// ChoiceField is interactive combo box or list box that can be filled in any PDF viewer.
+// The field is written as widget annotation with appearance stream by PDF.Complete.
+// Please note: the viewers use the font to show the options -
+// core fonts are recommended because the embedded fonts are subset.
+type ChoiceField struct {
+	interactiveField
+	options   []string
+	value     string
+	alignment int
+}
+
+// NewComboBox constructs combo box with the specified font, field name and options.
+func NewComboBox(font *Font, name string, options []string) *ChoiceField {
+	field := new(ChoiceField)
+	field.init(font, name)
+	field.flags = fieldCombo
+	field.options = options
+	field.w = 144.0
+	field.h = font.bodyHeight + 6.0
+	field.alignment = align.Left
+	return field
+}
+
+// NewListBox constructs list box with the specified font, field name and options.
+func NewListBox(font *Font, name string, options []string) *ChoiceField {
+	field := new(ChoiceField)
+	field.init(font, name)
+	field.options = options
+	field.w = 144.0
+	field.h = float32(len(options))*font.bodyHeight + 6.0
+	field.alignment = align.Left
+	return field
+}
+
+// SetLocation sets the location of the top left corner of the field on the page.
+func (field *ChoiceField) SetLocation(x, y float32) *ChoiceField {
+	field.x = x
+	field.y = y
+	return field
+}
+
+// SetSize sets the width and height of the field.
+func (field *ChoiceField) SetSize(w, h float32) *ChoiceField {
+	field.w = w
+	field.h = h
+	return field
+}
+
+// SetValue selects the option with the specified value.
+// The value must be one of the options unless the combo box is editable.
+func (field *ChoiceField) SetValue(value string) *ChoiceField {
+	field.value = value
+	return field
+}
+
+// SetEditable sets the flag that allows the user to type value that is not one of the options.
+// Used only with combo box.
+func (field *ChoiceField) SetEditable(editable bool) *ChoiceField {
+	field.setFlag(fieldEdit, editable && field.flags&fieldCombo != 0)
+	return field
+}
+
+// SetTextAlignment sets the alignment of the text - align.Left, align.Center or align.Right.
+func (field *ChoiceField) SetTextAlignment(alignment int) *ChoiceField {
+	field.alignment = alignment
+	return field
+}
+
+// SetToolTip sets the text shown by the viewers when the mouse is over the field.
+// The text is also used as alternate description of the field.
+func (field *ChoiceField) SetToolTip(toolTip string) *ChoiceField {
+	field.toolTip = toolTip
+	return field
+}
+
+// SetReadOnly sets the flag that prevents the user from changing the value.
+func (field *ChoiceField) SetReadOnly(readOnly bool) *ChoiceField {
+	field.setFlag(fieldReadOnly, readOnly)
+	return field
+}
+
+// SetRequired sets the flag that requires value before the form is submitted.
+func (field *ChoiceField) SetRequired(required bool) *ChoiceField {
+	field.setFlag(fieldRequired, required)
+	return field
+}
+
+// SetBorderColor sets the color of the border. Use color.Transparent for no border.
+func (field *ChoiceField) SetBorderColor(borderColor int32) *ChoiceField {
+	field.borderColor = borderColor
+	return field
+}
+
+// SetBackgroundColor sets the background color. The default is color.Transparent.
+func (field *ChoiceField) SetBackgroundColor(backgroundColor int32) *ChoiceField {
+	field.backgroundColor = backgroundColor
+	return field
+}
+
+// SetTextColor sets the color of the text.
+func (field *ChoiceField) SetTextColor(textColor int32) *ChoiceField {
+	field.textColor = textColor
+	return field
+}
+
+// DrawOn adds the field to the page and returns the coordinates of the bottom right corner.
+func (field *ChoiceField) DrawOn(page *Page) [2]float32 {
+	selected := -1
+	for i, option := range field.options {
+		if option == field.value {
+			selected = i
+			break
+		}
+	}
+
+	w := field.newWidget("Ch")
+	w.options = field.options
+	w.quadding = getQuadding(field.alignment)
+	if selected >= 0 || field.flags&fieldEdit != 0 {
+		w.value = field.value
+	}
+
+	appearance := field.newAppearance(page.pdf)
+	field.beginText(appearance)
+	if field.flags&fieldCombo != 0 {
+		field.drawLine(appearance, w.value, field.alignment, field.getCenteredBaseline())
+	} else {
+		w.selected = selected
+		padding := field.borderWidth + 2.0
+		for i, option := range field.options {
+			top := padding + float32(i)*field.font.bodyHeight
+			if i == selected {
+				appearance.SetBrushColorRGB(0.6, 0.75, 0.85)
+				appearance.FillRect(field.borderWidth, top, field.w-2*field.borderWidth, field.font.bodyHeight)
+			}
+			field.drawLine(appearance, option, field.alignment, top+field.font.ascent)
+		}
+	}
+	field.endText(appearance)
+	w.normal = page.pdf.addAppearanceStream(appearance, field.font)
+
+	field.addWidget(page, w, field.x, field.y, field.w, field.h)
+	page.pdf.formFields = append(page.pdf.formFields, w)
+	return [2]float32{field.x + field.w, field.y + field.h}
+}
@@ -0,0 +1,372 @@
+package pdfjet
+
+/**
+ * colorfont.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/edragoev1/pdfjet/src/imagetype"
+)
+
+// colorGlyphs holds the color glyph tables of OpenType font.
+// The COLR glyphs are drawn as the layers of glyphs filled with the palette colors.
+// The sbix and CBDT glyphs are drawn as images.
+type colorGlyphs struct {
+	layers  map[int][]colorLayer // The COLR layers by base glyph ID
+	palette [][4]byte            // The RGBA colors of the first CPAL palette
+	sbix    []byte
+	cbdt    []byte
+	cblc    []byte
+	images  map[int]*glyphImage // The images of the bitmap glyphs by glyph ID - nil when the glyph has no bitmap
+}
+
+// colorLayer is glyph drawn with the color from the palette.
+type colorLayer struct {
+	gid          int
+	paletteIndex int // 0xFFFF is the text color
+}
+
+// glyphImage is the image of the bitmap glyph.
+// The box is in ems relative to the glyph origin.
+type glyphImage struct {
+	image               *Image
+	x, y, width, height float32
+}
+
+// pendingGlyphImage is the image of the bitmap glyph drawn after the text object.
+// The position is in text space units relative to the origin of the text.
+type pendingGlyphImage struct {
+	glyph *glyphImage
+	size  float32 // The font size
+	x, y  float32
+}
+
+// newColorGlyphs returns the color glyph tables of the font file or nil if the font has none.
+func newColorGlyphs(buf []byte) (_ *colorGlyphs, err error) {
+	defer recoverMalformedInput("OpenType color tables", &err)
+
+	tables := getSfntTables(buf)
+	color := &colorGlyphs{sbix: tables["sbix"], cbdt: tables["CBDT"], cblc: tables["CBLC"]}
+	if colr, cpal := tables["COLR"], tables["CPAL"]; colr != nil && cpal != nil {
+		numPaletteEntries := u16(cpal, 2)
+		colorRecords := cpal[u32(cpal, 8):]
+		first := u16(cpal, 12) // The first color record of the first palette
+		for i := 0; i < numPaletteEntries; i++ {
+			bgra := colorRecords[4*(first+i) : 4*(first+i)+4]
+			color.palette = append(color.palette, [4]byte{bgra[2], bgra[1], bgra[0], bgra[3]})
+		}
+		// Only the version 0 records are read - COLRv1 fonts keep them for the older renderers.
+		numBaseGlyphs := u16(colr, 2)
+		baseGlyphs := colr[u32(colr, 4):]
+		layerRecords := colr[u32(colr, 8):]
+		numLayers := u16(colr, 12)
+		color.layers = make(map[int][]colorLayer, numBaseGlyphs)
+		for i := 0; i < numBaseGlyphs; i++ {
+			first, count := u16(baseGlyphs, 6*i+2), u16(baseGlyphs, 6*i+4)
+			if first+count > numLayers {
+				return nil, malformed("OpenType color tables", "layer index %d is out of range", first+count)
+			}
+			layers := make([]colorLayer, count)
+			for j := range layers {
+				layers[j] = colorLayer{u16(layerRecords, 4*(first+j)), u16(layerRecords, 4*(first+j)+2)}
+			}
+			color.layers[u16(baseGlyphs, 6*i)] = layers
+		}
+	}
+	if color.layers == nil && color.sbix == nil && (color.cbdt == nil || color.cblc == nil) {
+		return nil, nil
+	}
+	color.images = make(map[int]*glyphImage)
+	return color, nil
+}
+
+// getGlyphImage returns the image of the bitmap glyph or nil if the glyph has no bitmap.
+// The image is added to the PDF the first time the glyph is drawn.
+func (color *colorGlyphs) getGlyphImage(pdf *PDF, gid int) *glyphImage {
+	image, ok := color.images[gid]
+	if !ok {
+		var err error
+		image, err = color.readGlyphImage(pdf, gid)
+		if err != nil {
+			image = nil // The glyph is drawn with its outline.
+		}
+		color.images[gid] = image
+	}
+	return image
+}
+
+// readGlyphImage reads the bitmap of the glyph from the best strike of sbix or CBDT table.
+func (color *colorGlyphs) readGlyphImage(pdf *PDF, gid int) (_ *glyphImage, err error) {
+	defer recoverMalformedInput("OpenType bitmap glyph", &err)
+
+	if color.sbix != nil {
+		return color.readSbixImage(pdf, gid)
+	}
+	if color.cbdt != nil && color.cblc != nil {
+		return color.readCbdtImage(pdf, gid)
+	}
+	return nil, nil
+}
+
+// readSbixImage reads the PNG or JPEG bitmap of the glyph from the strike with the largest ppem.
+func (color *colorGlyphs) readSbixImage(pdf *PDF, gid int) (*glyphImage, error) {
+	sbix := color.sbix
+	var strike []byte
+	ppem := 0
+	for i := 0; i < u32(sbix, 4); i++ {
+		s := sbix[u32(sbix, 8+4*i):]
+		if u16(s, 0) > ppem {
+			strike, ppem = s, u16(s, 0)
+		}
+	}
+	if strike == nil {
+		return nil, nil
+	}
+	for dupes := 0; dupes < 2; dupes++ {
+		offset, next := u32(strike, 4+4*gid), u32(strike, 8+4*gid)
+		if next-offset < 8 {
+			return nil, nil // The glyph has no bitmap in the strike.
+		}
+		data := strike[offset:next]
+		imageType := -1
+		switch string(data[4:8]) {
+		case "png ":
+			imageType = imagetype.PNG
+		case "jpg ":
+			imageType = imagetype.JPG
+		case "dupe":
+			gid = u16(data, 8)
+			continue
+		default:
+			return nil, unsupported("sbix graphic type '%s'", string(data[4:8]))
+		}
+		return newGlyphImage(pdf, data[8:], imageType, float32(s16(data, 0)), float32(s16(data, 2)), ppem)
+	}
+	return nil, malformed("sbix table", "chain of dupe glyphs")
+}
+
+// readCbdtImage reads the PNG bitmap of the glyph from the strike with the largest ppem.
+func (color *colorGlyphs) readCbdtImage(pdf *PDF, gid int) (*glyphImage, error) {
+	cblc := color.cblc
+	var subtable []byte
+	first, ppem := 0, 0
+	for i := 0; i < u32(cblc, 4); i++ {
+		size := cblc[8+48*i:]
+		if gid < u16(size, 40) || gid > u16(size, 42) || int(size[45]) <= ppem {
+			continue
+		}
+		array := cblc[u32(size, 0):]
+		for j := 0; j < u32(size, 8); j++ {
+			if gid >= u16(array, 8*j) && gid <= u16(array, 8*j+2) {
+				subtable = array[u32(array, 8*j+4):]
+				first, ppem = u16(array, 8*j), int(size[45])
+				break
+			}
+		}
+	}
+	if subtable == nil {
+		return nil, nil
+	}
+
+	indexFormat, imageFormat := u16(subtable, 0), u16(subtable, 2)
+	offset, next := u32(subtable, 4), 0
+	var metrics []byte // The big glyph metrics shared by the glyphs of the subtable
+	switch indexFormat {
+	case 1:
+		offset, next = offset+u32(subtable, 8+4*(gid-first)), offset+u32(subtable, 12+4*(gid-first))
+	case 2:
+		imageSize := u32(subtable, 8)
+		metrics = subtable[12:20]
+		offset += imageSize * (gid - first)
+		next = offset + imageSize
+	case 3:
+		offset, next = offset+u16(subtable, 8+2*(gid-first)), offset+u16(subtable, 10+2*(gid-first))
+	case 4, 5:
+		pairs := indexFormat == 4
+		n := u32(subtable, 8)
+		if !pairs {
+			n = u32(subtable, 20)
+		}
+		found := false
+		for i := 0; i < n; i++ {
+			if pairs && u16(subtable, 12+4*i) == gid {
+				offset, next = offset+u16(subtable, 14+4*i), offset+u16(subtable, 18+4*i)
+				found = true
+				break
+			}
+			if !pairs && u16(subtable, 24+2*i) == gid {
+				imageSize := u32(subtable, 8)
+				metrics = subtable[12:20]
+				offset += imageSize * i
+				next = offset + imageSize
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	default:
+		return nil, unsupported("CBLC index subtable format %d", indexFormat)
+	}
+	if next <= offset {
+		return nil, nil
+	}
+
+	data := color.cbdt[offset:next]
+	switch imageFormat {
+	case 17: // Small metrics
+		metrics, data = data[:5], data[5:]
+	case 18: // Big metrics
+		metrics, data = data[:8], data[8:]
+	case 19: // Metrics in CBLC table
+		if metrics == nil {
+			return nil, malformed("CBLC table", "image format 19 without the glyph metrics")
+		}
+	default:
+		return nil, unsupported("CBDT image format %d", imageFormat)
+	}
+	data = data[4 : 4+u32(data, 0)]
+	height, bearingX, bearingY := float32(metrics[0]), float32(int8(metrics[2])), float32(int8(metrics[3]))
+	return newGlyphImage(pdf, data, imagetype.PNG, bearingX, bearingY-height, ppem)
+}
+
+// newGlyphImage adds the bitmap to the PDF. The position of the lower left corner
+// of the bitmap relative to the glyph origin is in pixels of the strike.
+func newGlyphImage(pdf *PDF, data []byte, imageType int, x, y float32, ppem int) (*glyphImage, error) {
+	if ppem == 0 {
+		return nil, malformed("OpenType bitmap glyph", "strike with zero ppem")
+	}
+	image, err := NewImageErr(pdf, bytes.NewReader(data), imageType)
+	if err != nil {
+		return nil, err
+	}
+	em := float32(ppem)
+	return &glyphImage{image: image, x: x / em, y: y / em, width: image.w / em, height: image.h / em}, nil
+}
+
+// appendGlyph adds the glyph to the TJ array and moves the position of the text after it.
+// The color glyph is drawn invisible so that the text can still be copied and searched.
+// Its layers are drawn before it or its image is drawn after the text object.
+func (page *Page) appendGlyph(font *Font, gid int, dy float32) {
+	hex := toHexString(gid)
+	if color := font.color; color != nil {
+		if layers := color.layers[gid]; layers != nil {
+			page.drawColorLayers(font, layers)
+			page.appendInvisibleGlyph(hex)
+		} else if page.drawsGlyphImages && (color.sbix != nil || color.cbdt != nil) {
+			if image := color.getGlyphImage(page.pdf, gid); image != nil {
+				page.glyphImages = append(page.glyphImages,
+					pendingGlyphImage{image, font.size, page.textX, page.textRise + dy})
+				page.appendInvisibleGlyph(hex)
+			} else {
+				appendString(&page.buf, hex)
+			}
+		} else {
+			appendString(&page.buf, hex)
+		}
+	} else {
+		appendString(&page.buf, hex)
+	}
+	page.textX += page.getGlyphWidth(font, gid)*font.size/1000.0 + page.charSpacing
+}
+
+// getGlyphWidth returns the advance width of the glyph in thousandths of text space unit.
+func (page *Page) getGlyphWidth(font *Font, gid int) float32 {
+	k := 1000.0 / float64(font.unitsPerEm)
+	return float32(math.Round(k * float64(font.getAdvanceWidth(gid))))
+}
+
+// drawColorLayers draws the layers of COLR glyph on top of each other.
+// The layers are marked as artifact so that only the invisible glyph gives the text.
+// The text position is moved back after each layer.
+func (page *Page) drawColorLayers(font *Font, layers []colorLayer) {
+	brush := page.brush
+	appendString(&page.buf, ">] TJ\n/Artifact BMC\n")
+	for _, layer := range layers {
+		if layer.paletteIndex == 0xFFFF {
+			page.SetBrushColorRGB(brush[0], brush[1], brush[2])
+		} else if layer.paletteIndex < len(font.color.palette) {
+			rgba := font.color.palette[layer.paletteIndex]
+			if rgba[3] == 0 {
+				continue // The palette alpha is not applied - only the transparent layers are skipped.
+			}
+			page.SetBrushColorRGB(float32(rgba[0])/255.0, float32(rgba[1])/255.0, float32(rgba[2])/255.0)
+		} else {
+			continue
+		}
+		if font.subset != nil && layer.gid < len(font.subset.used) {
+			font.subset.used[layer.gid] = true
+		}
+		appendString(&page.buf, "[<")
+		appendString(&page.buf, toHexString(layer.gid))
+		appendString(&page.buf, ">")
+		appendFloat32(&page.buf, page.getGlyphWidth(font, layer.gid)+page.charSpacing*1000.0/font.size)
+		appendString(&page.buf, "] TJ\n")
+	}
+	page.SetBrushColorRGB(brush[0], brush[1], brush[2])
+	appendString(&page.buf, "EMC\n[<")
+}
+
+// appendInvisibleGlyph adds the glyph drawn with the invisible text rendering mode to the TJ array.
+func (page *Page) appendInvisibleGlyph(hex string) {
+	appendString(&page.buf, ">] TJ\n3 Tr\n[<")
+	appendString(&page.buf, hex)
+	appendString(&page.buf, ">] TJ\n")
+	appendInteger(&page.buf, page.renderingMode)
+	appendString(&page.buf, " Tr\n[<")
+}
+
+// drawGlyphImages draws the images of the bitmap glyphs of the text drawn at x, y.
+// The images are transformed by the text matrix so that they follow the rotated text.
+func (page *Page) drawGlyphImages(x, y float32) {
+	for _, g := range page.glyphImages {
+		appendString(&page.buf, "q\n")
+		appendByteArray(&page.buf, page.tm0)
+		appendString(&page.buf, " ")
+		appendByteArray(&page.buf, page.tm1)
+		appendString(&page.buf, " ")
+		appendByteArray(&page.buf, page.tm2)
+		appendString(&page.buf, " ")
+		appendByteArray(&page.buf, page.tm3)
+		appendString(&page.buf, " ")
+		appendFloat32(&page.buf, x)
+		appendString(&page.buf, " ")
+		appendFloat32(&page.buf, page.height-y)
+		appendString(&page.buf, " cm\n")
+		appendFloat32(&page.buf, g.glyph.width*g.size)
+		appendString(&page.buf, " 0 0 ")
+		appendFloat32(&page.buf, g.glyph.height*g.size)
+		appendString(&page.buf, " ")
+		appendFloat32(&page.buf, g.x+g.glyph.x*g.size)
+		appendString(&page.buf, " ")
+		appendFloat32(&page.buf, g.y+g.glyph.y*g.size)
+		appendString(&page.buf, " cm\n/Im")
+		appendInteger(&page.buf, g.glyph.image.objNumber)
+		appendString(&page.buf, " Do\nQ\n")
+	}
+	page.glyphImages = nil
+}
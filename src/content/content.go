@@ -30,19 +30,21 @@ import (
 	"os"
 )
 
+// OfTextFile returns the contents of the text file with all '\r' characters removed.
+// The program exits if the file cannot be read. Use OfTextFileErr to handle the error.
 func OfTextFile(fileName string) string {
-	file, err := os.Open(fileName)
+	text, err := OfTextFileErr(fileName)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer func() {
-		if err = file.Close(); err != nil {
-			log.Fatal(err)
-		}
-	}()
-	contents, err := io.ReadAll(file)
+	return text
+}
+
+// OfTextFileErr returns the contents of the text file with all '\r' characters removed.
+func OfTextFileErr(fileName string) (string, error) {
+	contents, err := os.ReadFile(fileName)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	runes := make([]rune, 0)
 	for _, ch := range string(contents) {
@@ -50,21 +52,35 @@ func OfTextFile(fileName string) string {
 			runes = append(runes, ch)
 		}
 	}
-	return string(runes)
+	return string(runes), nil
 }
 
+// OfBinaryFile returns the contents of the binary file.
+// The program exits if the file cannot be read. Use OfBinaryFileErr to handle the error.
 func OfBinaryFile(fileName string) []uint8 {
-	contents, err := os.ReadFile(fileName)
+	contents, err := OfBinaryFileErr(fileName)
 	if err != nil {
 		log.Fatal(err)
 	}
 	return contents
 }
 
+// OfBinaryFileErr returns the contents of the binary file.
+func OfBinaryFileErr(fileName string) ([]uint8, error) {
+	return os.ReadFile(fileName)
+}
+
+// GetFromReader reads all the data from the reader.
+// The program exits if the read fails. Use GetFromReaderErr to handle the error.
 func GetFromReader(reader io.Reader) []uint8 {
-	contents, err := io.ReadAll(reader)
+	contents, err := GetFromReaderErr(reader)
 	if err != nil {
 		log.Fatal(err)
 	}
 	return contents
 }
+
+// GetFromReaderErr reads all the data from the reader.
+func GetFromReaderErr(reader io.Reader) ([]uint8, error) {
+	return io.ReadAll(reader)
+}
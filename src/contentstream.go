@@ -0,0 +1,443 @@
+package pdfjet
+
+/**
+ * contentstream.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/edragoev1/pdfjet/src/compressor"
+)
+
+// ContentStream is the list of operations of page or form XObject content stream.
+// The operations can be filtered and rewritten and the stream written back
+// with PDFobj.SetContentStream.
+type ContentStream struct {
+	Operations []*ContentOperation
+}
+
+// ContentOperation is content stream operator with its operands.
+// The operands are float64 numbers, bool, nil for the null object,
+// PDFName, PDFString, []any arrays and PDFDict dictionaries.
+// The inline image is single BI operation with the image dictionary
+// and the image data as PDFString operands.
+type ContentOperation struct {
+	Operator string
+	Operands []any
+}
+
+// NewContentOperation creates operation with the operator and the operands.
+func NewContentOperation(operator string, operands ...any) *ContentOperation {
+	return &ContentOperation{Operator: operator, Operands: operands}
+}
+
+// GetNumber returns the number operand or 0.0 if the operand is not number.
+func (op *ContentOperation) GetNumber(index int) float64 {
+	if index < len(op.Operands) {
+		if number, ok := op.Operands[index].(float64); ok {
+			return number
+		}
+	}
+	return 0.0
+}
+
+// GetName returns the name operand or empty string if the operand is not name.
+func (op *ContentOperation) GetName(index int) string {
+	if index < len(op.Operands) {
+		if name, ok := op.Operands[index].(PDFName); ok {
+			return string(name)
+		}
+	}
+	return ""
+}
+
+// GetString returns the string operand or nil if the operand is not string.
+func (op *ContentOperation) GetString(index int) []byte {
+	if index < len(op.Operands) {
+		if str, ok := op.Operands[index].(PDFString); ok {
+			return str
+		}
+	}
+	return nil
+}
+
+// NewContentStream parses the content stream data.
+// The program exits if the data cannot be parsed. Use NewContentStreamErr to handle the error.
+func NewContentStream(data []byte) *ContentStream {
+	stream, err := NewContentStreamErr(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return stream
+}
+
+// NewContentStreamErr parses the content stream data - for example
+// the data of GetContentsObject. The operands left at the end of the data
+// without operator are ignored.
+// Returns MalformedInputError if the data cannot be parsed.
+func NewContentStreamErr(data []byte) (_ *ContentStream, err error) {
+	defer recoverMalformedInput("PDF content stream", &err)
+
+	stream := &ContentStream{Operations: make([]*ContentOperation, 0)}
+	lexer := newPDFLexer(data)
+	operands := make([]any, 0)
+	for {
+		value, err := lexer.next()
+		if err == io.EOF {
+			return stream, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		operator, ok := value.(pdfOperator)
+		if !ok {
+			operands = append(operands, value)
+			continue
+		}
+		switch operator {
+		case "]", ">>", ")", ">", "{", "}":
+			return nil, malformed("PDF content stream", "unexpected '%s' at offset %d", operator, lexer.pos)
+		case "BI":
+			dict, data, err := lexer.readInlineImage()
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands[:0], dict, PDFString(data))
+		}
+		stream.Operations = append(stream.Operations, &ContentOperation{Operator: string(operator), Operands: operands})
+		operands = make([]any, 0)
+	}
+}
+
+// Filter removes the operations for which keep returns false.
+func (stream *ContentStream) Filter(keep func(op *ContentOperation) bool) *ContentStream {
+	operations := make([]*ContentOperation, 0, len(stream.Operations))
+	for _, op := range stream.Operations {
+		if keep(op) {
+			operations = append(operations, op)
+		}
+	}
+	stream.Operations = operations
+	return stream
+}
+
+// Rewrite replaces each operation with the operations returned by rewrite.
+// The operation is removed when rewrite returns nil.
+func (stream *ContentStream) Rewrite(rewrite func(op *ContentOperation) []*ContentOperation) *ContentStream {
+	operations := make([]*ContentOperation, 0, len(stream.Operations))
+	for _, op := range stream.Operations {
+		operations = append(operations, rewrite(op)...)
+	}
+	stream.Operations = operations
+	return stream
+}
+
+// RemoveMarkedContent removes the marked content sequences with the tag - for example
+// "Artifact" - together with the content between BMC or BDC and the matching EMC.
+func (stream *ContentStream) RemoveMarkedContent(tag string) *ContentStream {
+	operations := make([]*ContentOperation, 0, len(stream.Operations))
+	level := 0 // The nesting level inside the removed sequence
+	for _, op := range stream.Operations {
+		switch op.Operator {
+		case "BMC", "BDC":
+			if level > 0 || op.GetName(0) == tag {
+				level++
+				continue
+			}
+		case "EMC":
+			if level > 0 {
+				level--
+				continue
+			}
+		}
+		if level == 0 {
+			operations = append(operations, op)
+		}
+	}
+	stream.Operations = operations
+	return stream
+}
+
+// Bytes returns the content stream data.
+func (stream *ContentStream) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, op := range stream.Operations {
+		if op.Operator == "BI" {
+			buf.WriteString("BI")
+			if len(op.Operands) == 2 {
+				if dict, ok := op.Operands[0].(PDFDict); ok {
+					for _, key := range getSortedKeys(dict) {
+						buf.WriteString(" ")
+						writePDFValue(&buf, PDFName(key))
+						buf.WriteString(" ")
+						writePDFValue(&buf, dict[key])
+					}
+				}
+				buf.WriteString(" ID ")
+				if data, ok := op.Operands[1].(PDFString); ok {
+					buf.Write(data)
+				}
+			}
+			buf.WriteString("\nEI\n")
+			continue
+		}
+		for _, operand := range op.Operands {
+			writePDFValue(&buf, operand)
+			buf.WriteString(" ")
+		}
+		buf.WriteString(op.Operator)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+func getSortedKeys(dict PDFDict) []string {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writePDFValue writes the value in PDF syntax.
+func writePDFValue(buf *bytes.Buffer, value any) {
+	switch value := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+	case float64:
+		buf.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	case int:
+		buf.WriteString(strconv.Itoa(value))
+	case PDFName:
+		buf.WriteByte('/')
+		for _, b := range []byte(value) {
+			if b < 0x21 || b > 0x7E || b == '#' || isPDFDelimiter(b) {
+				buf.WriteString("#")
+				buf.WriteString(strconv.FormatUint(uint64(b)|0x100, 16)[1:])
+			} else {
+				buf.WriteByte(b)
+			}
+		}
+	case PDFString:
+		writePDFString(buf, value)
+	case []byte:
+		writePDFString(buf, value)
+	case string:
+		writePDFString(buf, []byte(value))
+	case pdfRef:
+		buf.WriteString(strconv.Itoa(int(value)))
+		buf.WriteString(" 0 R")
+	case []any:
+		buf.WriteString("[")
+		for i, element := range value {
+			if i > 0 {
+				buf.WriteString(" ")
+			}
+			writePDFValue(buf, element)
+		}
+		buf.WriteString("]")
+	case PDFDict:
+		buf.WriteString("<<")
+		for i, key := range getSortedKeys(value) {
+			if i > 0 {
+				buf.WriteString(" ")
+			}
+			writePDFValue(buf, PDFName(key))
+			buf.WriteString(" ")
+			writePDFValue(buf, value[key])
+		}
+		buf.WriteString(">>")
+	}
+}
+
+// writePDFString writes the string as literal string or as hexadecimal string
+// when the string has bytes that are not printable ASCII characters.
+func writePDFString(buf *bytes.Buffer, str []byte) {
+	for _, b := range str {
+		if b < 0x20 || b > 0x7E {
+			buf.WriteString("<")
+			for _, b := range str {
+				buf.WriteString(strconv.FormatUint(uint64(b)|0x100, 16)[1:])
+			}
+			buf.WriteString(">")
+			return
+		}
+	}
+	buf.WriteString("(")
+	for _, b := range str {
+		if b == '(' || b == ')' || b == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(b)
+	}
+	buf.WriteString(")")
+}
+
+// GetContentStream returns the content stream of the page or of the form XObject.
+// The program exits if the content stream cannot be read. Use GetContentStreamErr to handle the error.
+func (obj *PDFobj) GetContentStream(objects []*PDFobj) *ContentStream {
+	stream, err := obj.GetContentStreamErr(objects)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return stream
+}
+
+// GetContentStreamErr returns the content stream of the page or of the form XObject.
+// The content streams of the page are joined in single content stream.
+// Returns MalformedInputError if the content stream cannot be parsed and
+// UnsupportedFeatureError if the stream filter is not supported.
+func (obj *PDFobj) GetContentStreamErr(objects []*PDFobj) (*ContentStream, error) {
+	o := newPDFObjects(objects)
+	dict, _ := o.getValue(obj).(PDFDict)
+	var data []byte
+	var err error
+	if o.getName(dict["Subtype"]) == "Form" {
+		data, err = o.getStreamData(pdfRef(obj.number))
+	} else if dict != nil {
+		data, err = o.getPageContents(dict)
+	} else {
+		err = malformed("PDF", "object %d is not page or form XObject", obj.number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewContentStreamErr(data)
+}
+
+// SetContentStream replaces the content of the page or of the form XObject.
+// The program exits if the object is not page or form XObject. Use SetContentStreamErr to handle the error.
+func (obj *PDFobj) SetContentStream(stream *ContentStream, objects *[]*PDFobj) {
+	if err := obj.SetContentStreamErr(stream, objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// SetContentStreamErr replaces the content of the page or of the form XObject.
+// The page gets new compressed content object. The old content objects of the page
+// are emptied unless other pages use them, so that the removed content is not
+// left in the file. Use PDF.AddObjects to write the objects.
+// Returns MalformedInputError if the object is not page or form XObject.
+func (obj *PDFobj) SetContentStreamErr(stream *ContentStream, objects *[]*PDFobj) error {
+	o := newPDFObjects(*objects)
+	dict, _ := o.getValue(obj).(PDFDict)
+	if dict == nil {
+		return malformed("PDF", "object %d is not page or form XObject", obj.number)
+	}
+	data := stream.Bytes()
+	compressed := compressor.Deflate(data)
+	if o.getName(dict["Subtype"]) == "Form" {
+		obj.removeEntries("/Length", "/Filter", "/DecodeParms")
+		obj.insertEntries("/Filter", "/FlateDecode", "/Length", strconv.Itoa(len(compressed)))
+		obj.stream = compressed
+		obj.data = data
+		return nil
+	}
+	if o.getName(dict["Type"]) != "Page" {
+		return malformed("PDF", "object %d is not page or form XObject", obj.number)
+	}
+
+	// The old content objects used only by this page are emptied.
+	old := []any{dict["Contents"]}
+	if array, ok := o.resolve(dict["Contents"]).([]any); ok {
+		old = append(old, array...)
+	}
+	shared := make(map[pdfRef]bool)
+	for _, other := range *objects {
+		if other != obj && isPageObject(other) {
+			page, _ := o.getValue(other).(PDFDict)
+			shared[getRef(page["Contents"])] = true
+			for _, ref := range o.getArray(page["Contents"]) {
+				shared[getRef(ref)] = true
+			}
+		}
+	}
+	for _, value := range old {
+		if ref := getRef(value); ref != 0 && !shared[ref] {
+			if content := o.getObject(int(ref)); content != nil && content.number == int(ref) {
+				content.dict = []string{"<<", "/Length", "0", ">>"}
+				if content.offset != 0 {
+					// The objects read from PDF have the object header and the stream keyword.
+					content.dict = append([]string{strconv.Itoa(content.number), "0", "obj"}, content.dict...)
+					content.dict = append(content.dict, "stream")
+				}
+				content.stream = []byte{}
+				content.data = []byte{}
+			}
+		}
+	}
+
+	content := NewPDFobj()
+	content.number = len(*objects) + 1
+	content.dict = append(content.dict, "<<", "/Filter", "/FlateDecode", "/Length", strconv.Itoa(len(compressed)), ">>")
+	content.stream = compressed
+	content.data = data
+	*objects = append(*objects, content)
+	obj.removeEntries("/Contents")
+	obj.insertEntries("/Contents", strconv.Itoa(content.number), "0", "R")
+	return nil
+}
+
+func getRef(value any) pdfRef {
+	ref, _ := value.(pdfRef)
+	return ref
+}
+
+// removeEntries removes the keys with their values from the object dictionary.
+func (obj *PDFobj) removeEntries(keys ...string) {
+	for _, key := range keys {
+		obj.dict = removeKey(obj.dict, key)
+	}
+}
+
+// appendPDFTokens appends the tokens of the value in the format of the objects read by PDF.Read.
+func appendPDFTokens(tokens []string, value any) []string {
+	switch value := value.(type) {
+	case pdfRef:
+		return append(tokens, strconv.Itoa(int(value)), "0", "R")
+	case []any:
+		tokens = append(tokens, "[")
+		for _, element := range value {
+			tokens = appendPDFTokens(tokens, element)
+		}
+		return append(tokens, "]")
+	case PDFDict:
+		tokens = append(tokens, "<<")
+		for _, key := range getSortedKeys(value) {
+			tokens = appendPDFTokens(tokens, PDFName(key))
+			tokens = appendPDFTokens(tokens, value[key])
+		}
+		return append(tokens, ">>")
+	}
+	var buf bytes.Buffer
+	writePDFValue(&buf, value)
+	return append(tokens, buf.String())
+}
@@ -8,16 +8,26 @@ import (
 )
 
 // Inflate inflates the input data.
+// The program exits if the data is not valid zlib data. Use InflateErr to handle the error.
 func Inflate(buf []byte) []byte {
+	inflated, err := InflateErr(buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return inflated
+}
+
+// InflateErr inflates the input data.
+func InflateErr(buf []byte) ([]byte, error) {
 	var inflated bytes.Buffer
 	reader, err := zlib.NewReader(bytes.NewBuffer(buf))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	defer reader.Close()
 	_, err = io.Copy(&inflated, reader)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	reader.Close()
-	return inflated.Bytes()
+	return inflated.Bytes(), nil
 }
@@ -0,0 +1,524 @@
+package pdfjet
+
+/**
+ * decryption.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// The padding string used by the standard security handler revisions 2 to 4.
+var passwordPadding = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41,
+	0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80,
+	0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// Crypt filter methods
+const (
+	cryptNone = iota
+	cryptRC4
+	cryptAESV2
+	cryptAESV3
+)
+
+// decryption implements the standard security handler used to read encrypted PDF.
+// Supports RC4 40 and 128 bit, AES-128 and AES-256 (revisions 2 to 6).
+type decryption struct {
+	objNumber       int    // The number of the encryption dictionary object
+	key             []byte // The file encryption key
+	r               int    // The revision of the security handler
+	stmF            int    // The crypt filter method for streams
+	strF            int    // The crypt filter method for strings
+	encryptMetadata bool
+}
+
+// newDecryption authenticates the password - user or owner - and computes the file encryption key.
+// The encryption dictionary is parsed from the raw bytes, because the O and U strings are binary.
+func newDecryption(buf []byte, encrypt *PDFobj, id []byte, password string) (*decryption, error) {
+	filter := encrypt.getValue("/Filter")
+	if filter != "/Standard" {
+		return nil, unsupported("security handler %s", filter)
+	}
+	v, _ := strconv.Atoi(encrypt.getValue("/V"))
+	r, err := strconv.Atoi(encrypt.getValue("/R"))
+	if err != nil {
+		return nil, malformedWrap("PDF", "bad /R in encryption dictionary", err)
+	}
+	p, err := strconv.Atoi(encrypt.getValue("/P"))
+	if err != nil {
+		return nil, malformedWrap("PDF", "bad /P in encryption dictionary", err)
+	}
+
+	dec := new(decryption)
+	dec.objNumber = encrypt.number
+	dec.r = r
+	dec.encryptMetadata = encrypt.getValue("/EncryptMetadata") != "false"
+
+	end := bytes.Index(buf[encrypt.offset:], []byte("endobj"))
+	if end == -1 {
+		end = len(buf) - encrypt.offset
+	}
+	raw := buf[encrypt.offset : encrypt.offset+end]
+	o := getRawString(raw, "/O")
+	u := getRawString(raw, "/U")
+	if len(o) < 32 || len(u) < 32 {
+		return nil, malformed("PDF", "bad /O or /U in encryption dictionary")
+	}
+
+	switch v {
+	case 1, 2:
+		dec.stmF = cryptRC4
+		dec.strF = cryptRC4
+	case 4, 5:
+		dec.stmF = getCryptFilter(encrypt, encrypt.getValue("/StmF"))
+		dec.strF = getCryptFilter(encrypt, encrypt.getValue("/StrF"))
+	default:
+		return nil, unsupported("encryption algorithm /V %d", v)
+	}
+
+	if r >= 5 {
+		if len(o) < 48 || len(u) < 48 {
+			return nil, malformed("PDF", "bad /O or /U in encryption dictionary")
+		}
+		pw := truncatePassword(password)
+		if bytes.Equal(dec.hash(pw, u[32:40], nil), u[0:32]) {
+			dec.key = decryptNoPadding(dec.hash(pw, u[40:48], nil), getRawString(raw, "/UE"))
+		} else if bytes.Equal(dec.hash(pw, o[32:40], u[0:48]), o[0:32]) {
+			dec.key = decryptNoPadding(dec.hash(pw, o[40:48], u[0:48]), getRawString(raw, "/OE"))
+		}
+		if len(dec.key) != 32 {
+			return nil, ErrIncorrectPassword
+		}
+		return dec, nil
+	}
+
+	length := 40
+	if r >= 3 {
+		if value := encrypt.getValue("/Length"); value != "" {
+			length, _ = strconv.Atoi(value)
+		}
+	}
+	n := length / 8
+	if r == 2 || n < 5 || n > 16 {
+		n = 5
+	}
+	permissions := make([]byte, 4)
+	binary.LittleEndian.PutUint32(permissions, uint32(int32(p)))
+
+	// Try the password as user password first
+	pw := padPassword([]byte(password))
+	dec.key = dec.computeKey(pw, o, permissions, id, n)
+	if dec.authenticate(u, id) {
+		return dec, nil
+	}
+
+	// Algorithm 7: Authenticating the owner password
+	hash := md5.Sum(pw)
+	if r >= 3 {
+		for i := 0; i < 50; i++ {
+			hash = md5.Sum(hash[:])
+		}
+	}
+	userPassword := make([]byte, 32)
+	copy(userPassword, o[0:32])
+	if r == 2 {
+		rc4Crypt(hash[:n], userPassword)
+	} else {
+		for i := 19; i >= 0; i-- {
+			rc4Crypt(xorKey(hash[:n], byte(i)), userPassword)
+		}
+	}
+	dec.key = dec.computeKey(userPassword, o, permissions, id, n)
+	if dec.authenticate(u, id) {
+		return dec, nil
+	}
+	return nil, ErrIncorrectPassword
+}
+
+// getCryptFilter returns the method of the named crypt filter from the /CF dictionary.
+func getCryptFilter(encrypt *PDFobj, name string) int {
+	if name == "" || name == "/Identity" {
+		return cryptNone
+	}
+	level := 0
+	inFilter := false
+	for i, token := range encrypt.dict {
+		if token == "<<" {
+			level++
+		} else if token == ">>" {
+			level--
+			if inFilter && level <= 2 {
+				inFilter = false
+			}
+		} else if token == name && i+1 < len(encrypt.dict) && encrypt.dict[i+1] == "<<" {
+			inFilter = true
+		} else if inFilter && token == "/CFM" && i+1 < len(encrypt.dict) {
+			switch encrypt.dict[i+1] {
+			case "/V2":
+				return cryptRC4
+			case "/AESV2":
+				return cryptAESV2
+			case "/AESV3":
+				return cryptAESV3
+			}
+			return cryptNone
+		}
+	}
+	return cryptNone
+}
+
+// hash computes the password hash for revision 5 and 6.
+func (dec *decryption) hash(password, salt, userKey []byte) []byte {
+	if dec.r == 5 {
+		h := sha256.New()
+		h.Write(password)
+		h.Write(salt)
+		h.Write(userKey)
+		return h.Sum(nil)
+	}
+	return hashR6(password, salt, userKey)
+}
+
+// computeKey is the Algorithm 2: Computing a file encryption key.
+func (dec *decryption) computeKey(password, o, permissions, id []byte, n int) []byte {
+	h := md5.New()
+	h.Write(password)
+	h.Write(o[0:32])
+	h.Write(permissions)
+	h.Write(id)
+	if dec.r >= 4 && !dec.encryptMetadata {
+		h.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF})
+	}
+	key := h.Sum(nil)[:n]
+	if dec.r >= 3 {
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(key)
+			key = sum[:n]
+		}
+	}
+	return key
+}
+
+// authenticate is the Algorithm 6: Authenticating the user password.
+func (dec *decryption) authenticate(u, id []byte) bool {
+	if dec.r == 2 {
+		buf := make([]byte, 32)
+		copy(buf, passwordPadding)
+		rc4Crypt(dec.key, buf)
+		return bytes.Equal(buf, u[0:32])
+	}
+	h := md5.New()
+	h.Write(passwordPadding)
+	h.Write(id)
+	buf := h.Sum(nil)
+	for i := 0; i < 20; i++ {
+		rc4Crypt(xorKey(dec.key, byte(i)), buf)
+	}
+	return bytes.Equal(buf, u[0:16])
+}
+
+// objectKey is the Algorithm 1: Encryption of data using the RC4 or AES algorithms.
+func (dec *decryption) objectKey(number, generation int, method int) []byte {
+	if method == cryptAESV3 {
+		return dec.key
+	}
+	h := md5.New()
+	h.Write(dec.key)
+	h.Write([]byte{
+		byte(number), byte(number >> 8), byte(number >> 16),
+		byte(generation), byte(generation >> 8)})
+	if method == cryptAESV2 {
+		h.Write([]byte("sAlT"))
+	}
+	key := h.Sum(nil)
+	if len(dec.key)+5 < 16 {
+		key = key[:len(dec.key)+5]
+	}
+	return key
+}
+
+// decrypt decrypts string or stream of the specified object.
+func (dec *decryption) decrypt(data []byte, number, generation int, method int) []byte {
+	switch method {
+	case cryptRC4:
+		buf := make([]byte, len(data))
+		copy(buf, data)
+		rc4Crypt(dec.objectKey(number, generation, method), buf)
+		return buf
+	case cryptAESV2, cryptAESV3:
+		if len(data) < 2*aes.BlockSize {
+			return []byte{} // Empty string or stream - only the IV
+		}
+		block, err := aes.NewCipher(dec.objectKey(number, generation, method))
+		if err != nil {
+			return data
+		}
+		buf := make([]byte, len(data)-aes.BlockSize)
+		copy(buf, data[aes.BlockSize:])
+		buf = buf[:len(buf)-len(buf)%aes.BlockSize]
+		cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(buf, buf)
+		padding := int(buf[len(buf)-1])
+		if padding > 0 && padding <= aes.BlockSize {
+			buf = buf[:len(buf)-padding]
+		}
+		return buf
+	}
+	return data
+}
+
+// decryptStream decrypts the stream of the object read from encrypted PDF.
+// The cross-reference streams and the metadata - when not encrypted - are left unchanged.
+func (dec *decryption) decryptStream(obj *PDFobj) {
+	if obj.number == dec.objNumber || obj.getValue("/Type") == "/XRef" {
+		return
+	}
+	if obj.getValue("/Type") == "/Metadata" && !dec.encryptMetadata {
+		return
+	}
+	if strings.HasPrefix(obj.getValue("/Filter"), "/Crypt") {
+		return // Only the Identity crypt filter is supported
+	}
+	generation, _ := strconv.Atoi(obj.dict[1])
+	obj.stream = dec.decrypt(obj.stream, obj.number, generation, dec.stmF)
+}
+
+// decryptStrings replaces the encrypted strings in the object dictionary.
+// The object is parsed again from the raw bytes with the strings decrypted,
+// because the binary strings may contain whitespace and delimiters.
+func (dec *decryption) decryptStrings(buf []byte, obj *PDFobj) {
+	if obj.number == dec.objNumber || dec.strF == cryptNone || obj.getValue("/Type") == "/XRef" {
+		return
+	}
+	generation, _ := strconv.Atoi(obj.dict[1])
+	signature := false
+
+	var sb bytes.Buffer
+	name := ""
+	i := obj.offset
+	for i < len(buf) {
+		b := buf[i]
+		if b == '<' && i+1 < len(buf) && buf[i+1] == '<' {
+			sb.WriteString("<<")
+			i += 2
+			continue
+		}
+		if b == '(' || b == '<' {
+			text, next := parseRawString(buf, i)
+			// The signature value is not encrypted
+			if !(name == "/Contents" && signature) {
+				text = dec.decrypt(text, obj.number, generation, dec.strF)
+			}
+			sb.WriteString(escapeLiteral(text))
+			i = next
+			name = ""
+			continue
+		}
+		if b == '<' || b == '>' {
+			sb.WriteByte(b) // The dictionary delimiters
+			i++
+			continue
+		}
+		if b == '%' {
+			for i < len(buf) && buf[i] != '\n' && buf[i] != '\r' {
+				i++
+			}
+			continue
+		}
+		if b == '/' {
+			j := i + 1
+			for j < len(buf) && !isDelimiterOrSpace(buf[j]) {
+				j++
+			}
+			name = string(buf[i:j])
+			if name == "/ByteRange" || name == "/Sig" {
+				signature = true
+			}
+			sb.Write(buf[i:j])
+			i = j
+			continue
+		}
+		if bytes.HasPrefix(buf[i:], []byte("endobj")) {
+			sb.WriteString("endobj")
+			break
+		}
+		if bytes.HasPrefix(buf[i:], []byte("stream")) {
+			sb.WriteString("stream\n\n")
+			break
+		}
+		sb.WriteByte(b)
+		i++
+	}
+
+	data := sb.Bytes()
+	obj.dict = getObject(data, 0, len(data)).dict
+}
+
+// rc4Crypt encrypts or decrypts the data in place.
+func rc4Crypt(key, data []byte) {
+	c, err := rc4.NewCipher(key)
+	if err != nil {
+		return
+	}
+	c.XORKeyStream(data, data)
+}
+
+// xorKey returns copy of the key with each byte XOR-ed with the value.
+func xorKey(key []byte, value byte) []byte {
+	buf := make([]byte, len(key))
+	for i, b := range key {
+		buf[i] = b ^ value
+	}
+	return buf
+}
+
+// padPassword pads or truncates the password to exactly 32 bytes.
+func padPassword(password []byte) []byte {
+	buf := make([]byte, 32)
+	n := copy(buf, password)
+	copy(buf[n:], passwordPadding)
+	return buf
+}
+
+// decryptNoPadding decrypts the data using AES-256 in CBC mode with zero IV and no padding.
+func decryptNoPadding(key, data []byte) []byte {
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil
+	}
+	iv := make([]byte, aes.BlockSize)
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, data)
+	return decrypted
+}
+
+// getRawString returns the value of the string for the specified key.
+// Returns nil if the key is not found or the value is not a string.
+func getRawString(buf []byte, key string) []byte {
+	off := 0
+	for {
+		i := bytes.Index(buf[off:], []byte(key))
+		if i == -1 {
+			return nil
+		}
+		off += i + len(key)
+		if off < len(buf) && !isDelimiterOrSpace(buf[off]) {
+			continue // For example /O matched /OE
+		}
+		for off < len(buf) && (isSpace(buf[off]) || buf[off] == '[') {
+			off++
+		}
+		if off < len(buf) && (buf[off] == '(' || buf[off] == '<') {
+			text, _ := parseRawString(buf, off)
+			return text
+		}
+		return nil
+	}
+}
+
+// parseRawString parses literal or hex string starting at the offset.
+// Returns the string bytes and the offset after the string.
+func parseRawString(buf []byte, off int) ([]byte, int) {
+	if buf[off] == '<' {
+		var sb strings.Builder
+		i := off + 1
+		for i < len(buf) && buf[i] != '>' {
+			if !isSpace(buf[i]) {
+				sb.WriteByte(buf[i])
+			}
+			i++
+		}
+		hexText := sb.String()
+		if len(hexText)%2 == 1 {
+			hexText += "0"
+		}
+		text := make([]byte, len(hexText)/2)
+		for j := range text {
+			value, _ := strconv.ParseUint(hexText[2*j:2*j+2], 16, 8)
+			text[j] = byte(value)
+		}
+		return text, i + 1
+	}
+	level := 0
+	i := off
+	for i < len(buf) {
+		b := buf[i]
+		if b == '\\' {
+			i += 2
+			continue
+		}
+		if b == '(' {
+			level++
+		} else if b == ')' {
+			level--
+			if level == 0 {
+				break
+			}
+		}
+		i++
+	}
+	if i >= len(buf) {
+		return unescapeLiteral(string(buf[off+1:])), len(buf)
+	}
+	return unescapeLiteral(string(buf[off+1 : i])), i + 1
+}
+
+// escapeLiteral returns literal string that is parsed as single token.
+// The delimiters, whitespace and the non-printable characters are written as octal escapes.
+func escapeLiteral(text []byte) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, b := range text {
+		if b <= 0x20 || b >= 0x7F || b == '(' || b == ')' || b == '\\' || b == '/' {
+			sb.WriteByte('\\')
+			sb.WriteByte('0' + (b >> 6))
+			sb.WriteByte('0' + ((b >> 3) & 7))
+			sb.WriteByte('0' + (b & 7))
+		} else {
+			sb.WriteByte(b)
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+func isSpace(b byte) bool {
+	return b == 0x00 || b == 0x09 || b == 0x0A || b == 0x0C || b == 0x0D || b == 0x20
+}
+
+func isDelimiterOrSpace(b byte) bool {
+	return isSpace(b) || strings.IndexByte("()<>[]{}/%", b) != -1
+}
@@ -45,7 +45,13 @@ func (polynomial *Polynomial) getLength() int {
 func (polynomial *Polynomial) multiply(e *Polynomial) *Polynomial {
 	num := make([]int, polynomial.getLength()+e.getLength()-1)
 	for i := 0; i < polynomial.getLength(); i++ {
+		if polynomial.get(i) == 0 {
+			continue // The product is zero
+		}
 		for j := 0; j < e.getLength(); j++ {
+			if e.get(j) == 0 {
+				continue
+			}
 			num[i+j] ^=
 				NewQRMath().gexp(NewQRMath().glog(polynomial.get(i)) +
 					NewQRMath().glog(e.get(j)))
@@ -64,7 +70,9 @@ func (polynomial *Polynomial) mod(e *Polynomial) *Polynomial {
 		num[i] = polynomial.get(i)
 	}
 	for i := 0; i < e.getLength(); i++ {
-		num[i] ^= NewQRMath().gexp(NewQRMath().glog(e.get(i)) + ratio)
+		if e.get(i) != 0 {
+			num[i] ^= NewQRMath().gexp(NewQRMath().glog(e.get(i)) + ratio)
+		}
 	}
 	return NewPolynomial(num, 0).mod(e)
 }
@@ -15,6 +15,7 @@ DENSO WAVE INCORPORATED
 */
 
 import (
+	"errors"
 	"log"
 	"strconv"
 
@@ -37,9 +38,34 @@ type QRCode struct {
 }
 
 // NewQRCode is used to create 2D QR Code barcodes.
+// The program exits if the string is too long. Use NewQRCodeErr to handle the error.
 // @param str the string to encode.
 // @param errorCorrectLevel the desired error correction level.
 func NewQRCode(str string, errorCorrectLevel int) *QRCode {
+	qrcode, err := NewQRCodeErr(str, errorCorrectLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return qrcode
+}
+
+// NewQRCodeErr is used to create 2D QR Code barcodes.
+// Returns an error if the string is too long for the error correction level.
+// @param str the string to encode.
+// @param errorCorrectLevel the desired error correction level.
+func NewQRCodeErr(str string, errorCorrectLevel int) (*QRCode, error) {
+	rsblock := new(RSBlock)
+	totalDataCount := 0
+	for _, block := range rsblock.getRSBlocks(errorCorrectLevel) {
+		totalDataCount += block.getDataCount()
+	}
+	lengthInBits := 4 + 8 + 8*len(str) // Mode, length and data bits
+	if lengthInBits > totalDataCount*8 {
+		return nil, errors.New("String length overflow. (" +
+			strconv.Itoa(lengthInBits) + ">" +
+			strconv.Itoa(totalDataCount*8) + ")")
+	}
+
 	qrcode := new(QRCode)
 	qrcode.PAD0 = 0xEC
 	qrcode.PAD1 = 0x11
@@ -47,8 +73,14 @@ func NewQRCode(str string, errorCorrectLevel int) *QRCode {
 	qrcode.moduleCount = 33 // Magic Number
 	qrcode.m1 = 2.0
 	qrcode.errorCorrectLevel = errorCorrectLevel
-	qrcode.make(false, qrcode.getBestMaskPattern())
-	return qrcode
+	maskPattern, err := qrcode.getBestMaskPattern()
+	if err != nil {
+		return nil, err
+	}
+	if err := qrcode.make(false, maskPattern); err != nil {
+		return nil, err
+	}
+	return qrcode, nil
 }
 
 // SetPosition sets the position where this barcode will be drawn on the page.
@@ -114,21 +146,23 @@ func (qrcode *QRCode) getModuleCount() int {
 	return qrcode.moduleCount
 }
 
-func (qrcode *QRCode) getBestMaskPattern() int {
+func (qrcode *QRCode) getBestMaskPattern() (int, error) {
 	minLostPoint := 0
 	pattern := 0
 	for i := 0; i < 8; i++ {
-		qrcode.make(true, i)
+		if err := qrcode.make(true, i); err != nil {
+			return 0, err
+		}
 		lostPoint := getLostPoint(qrcode)
 		if i == 0 || minLostPoint > lostPoint {
 			minLostPoint = lostPoint
 			pattern = i
 		}
 	}
-	return pattern
+	return pattern, nil
 }
 
-func (qrcode *QRCode) make(test bool, maskPattern int) {
+func (qrcode *QRCode) make(test bool, maskPattern int) error {
 	qrcode.modules = make([][]*bool, qrcode.moduleCount)
 	for i := range qrcode.modules {
 		qrcode.modules[i] = make([]*bool, qrcode.moduleCount)
@@ -142,7 +176,12 @@ func (qrcode *QRCode) make(test bool, maskPattern int) {
 	qrcode.setupTimingPattern()
 	qrcode.setupTypeInfo(test, maskPattern)
 
-	qrcode.mapData(qrcode.createData(qrcode.errorCorrectLevel), maskPattern)
+	data, err := qrcode.createData(qrcode.errorCorrectLevel)
+	if err != nil {
+		return err
+	}
+	qrcode.mapData(data, maskPattern)
+	return nil
 }
 
 func (qrcode *QRCode) mapData(data []byte, maskPattern int) {
@@ -265,7 +304,7 @@ func (qrcode *QRCode) setupTypeInfo(test bool, maskPattern int) {
 	qrcode.modules[qrcode.moduleCount-8][8] = &value
 }
 
-func (qrcode *QRCode) createData(errorCorrectLevel int) []byte {
+func (qrcode *QRCode) createData(errorCorrectLevel int) ([]byte, error) {
 	rsblock := new(RSBlock)
 	rsBlocks := rsblock.getRSBlocks(errorCorrectLevel)
 
@@ -282,7 +321,7 @@ func (qrcode *QRCode) createData(errorCorrectLevel int) []byte {
 	}
 
 	if buffer.getLengthInBits() > totalDataCount*8 {
-		log.Fatal("String length overflow. (" +
+		return nil, errors.New("String length overflow. (" +
 			strconv.Itoa(buffer.getLengthInBits()) + ">" +
 			strconv.Itoa(totalDataCount*8) + ")")
 	}
@@ -308,7 +347,7 @@ func (qrcode *QRCode) createData(errorCorrectLevel int) []byte {
 		buffer.put(qrcode.PAD1, 8)
 	}
 
-	return qrcode.createBytes(buffer, rsBlocks)
+	return qrcode.createBytes(buffer, rsBlocks), nil
 }
 
 func maxOfIntegers(a, b int) int {
@@ -13,8 +13,6 @@ The word "QR Code" is registered trademark of
 DENSO WAVE INCORPORATED
   http://www.denso-wave.com/qrcode/faqpatent-e.html
 */
-import "log"
-
 // QRMath describes the QRMath structure.
 type QRMath struct {
 	expTable [256]int
@@ -40,10 +38,8 @@ func NewQRMath() *QRMath {
 }
 
 // Glog returns the log value.
+// The index must be between 1 and 255 - the callers skip the zero coefficients.
 func (qrmath *QRMath) glog(index int) int {
-	if index < 1 {
-		log.Fatal("The index value must be between 0 and 255.")
-	}
 	return qrmath.logTable[index]
 }
 
@@ -44,24 +44,45 @@ type EmbeddedFile struct {
 	content   []byte
 }
 
+// NewEmbeddedFileAtPath embeds the file at the specified path.
+// The program exits if the file cannot be read. Use NewEmbeddedFileAtPathErr to handle the error.
 func NewEmbeddedFileAtPath(pdf *PDF, filePath string, compress bool) *EmbeddedFile {
+	file, err := NewEmbeddedFileAtPathErr(pdf, filePath, compress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return file
+}
+
+// NewEmbeddedFileAtPathErr embeds the file at the specified path.
+func NewEmbeddedFileAtPathErr(pdf *PDF, filePath string, compress bool) (*EmbeddedFile, error) {
 	fileName := filePath[strings.LastIndex(filePath, "/")+1:]
 	file, err := os.Open(filePath)
 	if err != nil {
-		log.Fatal(err)
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
 	}
 	defer file.Close()
-	return NewEmbeddedFile(pdf, fileName, bufio.NewReader(file), compress)
+	return NewEmbeddedFileErr(pdf, fileName, bufio.NewReader(file), compress)
 }
 
 // NewEmbeddedFile is the constructor.
+// The program exits if the reader fails. Use NewEmbeddedFileErr to handle the error.
 func NewEmbeddedFile(pdf *PDF, fileName string, reader io.Reader, compress bool) *EmbeddedFile {
+	file, err := NewEmbeddedFileErr(pdf, fileName, reader, compress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return file
+}
+
+// NewEmbeddedFileErr is the constructor that returns IOError if the reader fails.
+func NewEmbeddedFileErr(pdf *PDF, fileName string, reader io.Reader, compress bool) (*EmbeddedFile, error) {
 	file := new(EmbeddedFile)
 	file.fileName = fileName
 
 	buf, err := io.ReadAll(reader)
 	if err != nil {
-		log.Fatal(err)
+		return nil, &IOError{Op: "read", Path: fileName, Err: err}
 	}
 
 	if compress {
@@ -73,6 +94,7 @@ func NewEmbeddedFile(pdf *PDF, fileName string, reader io.Reader, compress bool)
 	} else {
 		file.content = buf
 	}
+	content := pdf.encrypt(file.content)
 
 	pdf.newobj()
 	pdf.appendByteArray(token.BeginDictionary)
@@ -81,20 +103,20 @@ func NewEmbeddedFile(pdf *PDF, fileName string, reader io.Reader, compress bool)
 		pdf.appendString("/Filter /FlateDecode\n")
 	}
 	pdf.appendString("/Length ")
-	pdf.appendInteger(len(file.content))
+	pdf.appendInteger(len(content))
 	pdf.appendByte('\n')
 	pdf.appendByteArray(token.EndDictionary)
 	pdf.appendByteArray(token.Stream)
-	pdf.appendByteArray(file.content)
+	pdf.appendByteArray(content)
 	pdf.appendByteArray(token.Endstream)
 	pdf.endobj()
 
 	pdf.newobj()
 	pdf.appendByteArray(token.BeginDictionary)
 	pdf.appendString("/Type /Filespec\n")
-	pdf.appendString("/F (")
-	pdf.appendString(fileName)
-	pdf.appendString(")\n")
+	pdf.appendString("/F ")
+	pdf.appendLiteral(fileName)
+	pdf.appendString("\n")
 	pdf.appendString("/EF <</F ")
 	pdf.appendInteger(pdf.getObjNumber() - 1)
 	pdf.appendString(" 0 R>>\n")
@@ -103,7 +125,7 @@ func NewEmbeddedFile(pdf *PDF, fileName string, reader io.Reader, compress bool)
 
 	file.objNumber = pdf.getObjNumber()
 
-	return file
+	return file, nil
 }
 
 // GetFileName returns the file name.
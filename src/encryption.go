@@ -0,0 +1,217 @@
+package pdfjet
+
+/**
+ * encryption.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+)
+
+// encryption implements the standard security handler revision 6 - AES-256.
+// See ISO 32000-2, 7.6.4 Standard security handler.
+type encryption struct {
+	key   []byte // The file encryption key
+	o     []byte
+	u     []byte
+	oe    []byte
+	ue    []byte
+	perms []byte
+	p     int32
+}
+
+func newEncryption(userPassword, ownerPassword string, permissions int) (*encryption, error) {
+	enc := new(encryption)
+	enc.key = make([]byte, 32)
+	if _, err := rand.Read(enc.key); err != nil {
+		return nil, err
+	}
+	if ownerPassword == "" {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		ownerPassword = hex.EncodeToString(random)
+	}
+	// Bits 7, 8 and 13 to 32 are reserved and must be 1.
+	enc.p = int32(uint32(permissions&0xF3C) | 0xFFFFF0C0)
+
+	salts := make([]byte, 32)
+	if _, err := rand.Read(salts); err != nil {
+		return nil, err
+	}
+	upw := truncatePassword(userPassword)
+	opw := truncatePassword(ownerPassword)
+
+	// Algorithm 8: Computing the U and UE values
+	enc.u = append(hashR6(upw, salts[0:8], nil), salts[0:16]...)
+	enc.ue = encryptNoPadding(hashR6(upw, salts[8:16], nil), enc.key)
+
+	// Algorithm 9: Computing the O and OE values
+	enc.o = append(hashR6(opw, salts[16:24], enc.u), salts[16:32]...)
+	enc.oe = encryptNoPadding(hashR6(opw, salts[24:32], enc.u), enc.key)
+
+	// Algorithm 10: Computing the Perms value
+	perms := make([]byte, 16)
+	binary.LittleEndian.PutUint32(perms, uint32(enc.p))
+	copy(perms[4:], []byte{0xFF, 0xFF, 0xFF, 0xFF, 'T', 'a', 'd', 'b'})
+	if _, err := rand.Read(perms[12:]); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(enc.key)
+	if err != nil {
+		return nil, err
+	}
+	enc.perms = make([]byte, 16)
+	block.Encrypt(enc.perms, perms)
+
+	return enc, nil
+}
+
+// truncatePassword returns the UTF-8 password truncated to 127 bytes.
+func truncatePassword(password string) []byte {
+	buf := []byte(password)
+	if len(buf) > 127 {
+		buf = buf[:127]
+	}
+	return buf
+}
+
+// hashR6 is the Algorithm 2.B: Computing a hash (revision 6 and later).
+func hashR6(password, salt, userKey []byte) []byte {
+	h := sha256.New()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(userKey)
+	k := h.Sum(nil)
+
+	var e []byte
+	for i := 0; i < 64 || int(e[len(e)-1]) > i-32; i++ {
+		seq := make([]byte, 0, len(password)+len(k)+len(userKey))
+		seq = append(seq, password...)
+		seq = append(seq, k...)
+		seq = append(seq, userKey...)
+		k1 := make([]byte, 0, 64*len(seq))
+		for j := 0; j < 64; j++ {
+			k1 = append(k1, seq...)
+		}
+
+		block, _ := aes.NewCipher(k[0:16])
+		e = make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+
+		sum := 0
+		for _, b := range e[0:16] {
+			sum += int(b)
+		}
+		var next hash.Hash
+		switch sum % 3 {
+		case 0:
+			next = sha256.New()
+		case 1:
+			next = sha512.New384()
+		default:
+			next = sha512.New()
+		}
+		next.Write(e)
+		k = next.Sum(nil)
+	}
+	return k[0:32]
+}
+
+// encryptNoPadding encrypts the data using AES-256 in CBC mode with zero IV and no padding.
+func encryptNoPadding(key, data []byte) []byte {
+	block, _ := aes.NewCipher(key)
+	iv := make([]byte, aes.BlockSize)
+	encrypted := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, data)
+	return encrypted
+}
+
+// encrypt encrypts string or stream using AES-256 in CBC mode.
+// The result starts with random 16 byte IV and the data is padded as described in RFC 8018.
+func (enc *encryption) encrypt(data []byte) []byte {
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	encrypted := make([]byte, aes.BlockSize+len(data)+padding)
+	rand.Read(encrypted[:aes.BlockSize])
+	copy(encrypted[aes.BlockSize:], data)
+	for i := aes.BlockSize + len(data); i < len(encrypted); i++ {
+		encrypted[i] = byte(padding)
+	}
+	block, _ := aes.NewCipher(enc.key)
+	cipher.NewCBCEncrypter(block, encrypted[:aes.BlockSize]).CryptBlocks(
+		encrypted[aes.BlockSize:], encrypted[aes.BlockSize:])
+	return encrypted
+}
+
+// unescapeLiteral returns the bytes of PDF literal string without the parentheses.
+func unescapeLiteral(text string) []byte {
+	buf := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		if b != '\\' || i == len(text)-1 {
+			buf = append(buf, b)
+			continue
+		}
+		i++
+		b = text[i]
+		switch b {
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+		case '\n':
+			// Line continuation
+		default:
+			if b >= '0' && b <= '7' {
+				value := 0
+				j := 0
+				for ; j < 3 && i+j < len(text) && text[i+j] >= '0' && text[i+j] <= '7'; j++ {
+					value = value*8 + int(text[i+j]-'0')
+				}
+				i += j - 1
+				buf = append(buf, byte(value))
+			} else {
+				buf = append(buf, b)
+			}
+		}
+	}
+	return buf
+}
@@ -0,0 +1,114 @@
+package pdfjet
+
+/**
+ * errors.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+)
+
+// ErrIncorrectPassword is returned when the encrypted PDF cannot be opened
+// with the specified password. Use errors.Is to check for it.
+var ErrIncorrectPassword = errors.New("pdfjet: incorrect password")
+
+// MalformedInputError is returned when the input data - PDF, image, font or SVG -
+// cannot be parsed. Use errors.As to inspect it.
+type MalformedInputError struct {
+	Input  string // The kind of input, for example "PNG image"
+	Reason string // What is wrong with the input
+	Err    error  // The underlying error if any
+}
+
+func (e *MalformedInputError) Error() string {
+	if e.Err != nil {
+		return "pdfjet: malformed " + e.Input + ": " + e.Reason + ": " + e.Err.Error()
+	}
+	return "pdfjet: malformed " + e.Input + ": " + e.Reason
+}
+
+// Unwrap returns the underlying error.
+func (e *MalformedInputError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedFeatureError is returned when the input is valid,
+// but uses a feature that is not supported by the library.
+type UnsupportedFeatureError struct {
+	Feature string
+}
+
+func (e *UnsupportedFeatureError) Error() string {
+	return "pdfjet: unsupported feature: " + e.Feature
+}
+
+// IOError is returned when reading the input or writing the output fails.
+type IOError struct {
+	Op   string // "open", "read", "create" or "write"
+	Path string // The file path if known
+	Err  error
+}
+
+func (e *IOError) Error() string {
+	err := e.Err
+	if pathErr, ok := err.(*fs.PathError); ok && pathErr.Path == e.Path {
+		err = pathErr.Err // The operation and the path are not repeated
+	}
+	if e.Path != "" {
+		return "pdfjet: " + e.Op + " " + e.Path + ": " + err.Error()
+	}
+	return "pdfjet: " + e.Op + ": " + err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *IOError) Unwrap() error {
+	return e.Err
+}
+
+func malformed(input, format string, args ...any) error {
+	return &MalformedInputError{Input: input, Reason: fmt.Sprintf(format, args...)}
+}
+
+func malformedWrap(input, reason string, err error) error {
+	return &MalformedInputError{Input: input, Reason: reason, Err: err}
+}
+
+func unsupported(format string, args ...any) error {
+	return &UnsupportedFeatureError{Feature: fmt.Sprintf(format, args...)}
+}
+
+// recoverMalformedInput converts the runtime panics caused by truncated or corrupt
+// input - index out of range, nil dereference - into MalformedInputError.
+// Must be deferred directly by the parsing function.
+func recoverMalformedInput(input string, err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(runtime.Error); ok {
+			*err = malformedWrap(input, "truncated or corrupt data", e)
+			return
+		}
+		panic(r)
+	}
+}
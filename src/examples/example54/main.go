@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/color"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example54 -- Incremental update that appends a stamp to the first page of existing PDF
+func Example54() {
+	original := createDocument()
+
+	file, err := os.Create("Example_54.pdf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	// The original bytes are written first and only the changed objects are appended.
+	pdf := pdfjet.NewIncrementalPDF(bufio.NewWriter(file), original)
+	objects := pdf.Read(original)
+	pages := pdf.GetPageObjects(objects)
+	page := pdfjet.NewPageFromObject(pdf, pages[0])
+	font := page.AddCoreFontResource(corefont.HelveticaBold(), &objects)
+	font.SetSize(24.0)
+	stamp := pdfjet.NewTextLine(font, "APPROVED")
+	stamp.SetLocation(400.0, 80.0)
+	stamp.SetColor(color.Red)
+	stamp.DrawOn(page)
+	page.Complete(&objects)
+	pdf.AddObjects(&objects)
+	pdf.Complete()
+
+	// The updated PDF starts with the original PDF and shows the stamp.
+	updated := content.OfBinaryFile("Example_54.pdf")
+	if !bytes.HasPrefix(updated, original) {
+		log.Fatal("Example_54: the original bytes were changed")
+	}
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects = pdf.Read(updated)
+	pages = pdf.GetPageObjects(objects)
+	if len(pages) != 2 ||
+		getPageText(pages[0], objects) != "Page 1\nAPPROVED" ||
+		getPageText(pages[1], objects) != "Page 2" {
+		log.Fatal("Example_54: the updated PDF does not have the expected text")
+	}
+}
+
+// getPageText returns the text shown with core fonts on the page - one line for each text object.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, block := range regexp.MustCompile(`(?s)BT\n(.*?)ET\n`).FindAllStringSubmatch(data, -1) {
+			var line []byte
+			for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(block[1], -1) {
+				for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+					buf, _ := hex.DecodeString(str[1])
+					line = append(line, buf...)
+				}
+			}
+			lines = append(lines, string(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// createDocument creates two page PDF.
+func createDocument() []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	font := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	for i := 1; i <= 2; i++ {
+		page := pdfjet.NewPage(pdf, letter.Portrait)
+		textLine := pdfjet.NewTextLine(font, "Page "+string(rune('0'+i)))
+		textLine.SetLocation(50.0, 80.0)
+		textLine.DrawOn(page)
+	}
+	pdf.Complete()
+	w.Flush()
+	return buf.Bytes()
+}
+
+func main() {
+	start := time.Now()
+	Example54()
+	pdfjet.PrintDuration("Example_54", time.Since(start))
+}
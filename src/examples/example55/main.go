@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"log"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example55 -- Signs generated PDF and then adds second signature to it with incremental update
+func Example55() {
+	key, cert := createCertificate("Example Signer")
+
+	// The generated PDF is signed when it is completed.
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	author := pdfjet.NewSignature(key, []*x509.Certificate{cert})
+	author.SetFieldName("Author")
+	author.SetName("Example Signer")
+	author.SetReason("I am the author of this document")
+	if err := pdf.SetSignature(author); err != nil {
+		log.Fatal(err)
+	}
+	font := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	textLine := pdfjet.NewTextLine(font, "This document is signed twice.")
+	textLine.SetLocation(50.0, 80.0)
+	textLine.DrawOn(page)
+	author.SetLocation(50.0, 100.0)
+	author.SetSize(200.0, 50.0)
+	author.DrawOn(page)
+	pdf.Complete()
+	w.Flush()
+	signed := buf.Bytes()
+
+	// The existing PDF is signed with incremental update that keeps the first signature valid.
+	file, err := os.Create("Example_55.pdf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	pdf = pdfjet.NewIncrementalPDF(bufio.NewWriter(file), signed)
+	approver := pdfjet.NewSignature(key, []*x509.Certificate{cert})
+	approver.SetFieldName("Approver")
+	approver.SetReason("I approve this document")
+	if err := pdf.SetSignature(approver); err != nil {
+		log.Fatal(err)
+	}
+	objects := pdf.Read(signed)
+	pdf.AddObjects(&objects)
+	pdf.Complete()
+
+	if n := verifySignatures(content.OfBinaryFile("Example_55.pdf"), &key.PublicKey); n != 2 {
+		log.Fatalf("Example_55: found %d valid signatures instead of 2", n)
+	}
+}
+
+func createCertificate(name string) (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return key, cert
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    asn1.RawValue
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm asn1.RawValue
+	Signature          []byte
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+var oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+// verifySignatures returns the number of signatures that cover the bytes from the start
+// of the file with the digest signed by the key.
+func verifySignatures(buf []byte, key *ecdsa.PublicKey) int {
+	valid := 0
+	re := regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+	for _, match := range re.FindAllSubmatch(buf, -1) {
+		var r [4]int
+		for i := range r {
+			r[i], _ = strconv.Atoi(string(match[i+1]))
+		}
+		if r[0] != 0 || r[2]+r[3] > len(buf) {
+			continue
+		}
+		hash := sha256.New()
+		hash.Write(buf[r[0]:r[1]])
+		hash.Write(buf[r[2] : r[2]+r[3]])
+		digest := hash.Sum(nil)
+
+		der, err := hex.DecodeString(string(buf[r[1]+1 : r[2]-1]))
+		if err != nil {
+			continue
+		}
+		var info contentInfo
+		var data signedData
+		if _, err := asn1.Unmarshal(der, &info); err != nil {
+			continue
+		}
+		if _, err := asn1.Unmarshal(info.Content.Bytes, &data); err != nil || len(data.SignerInfos) != 1 {
+			continue
+		}
+		signer := data.SignerInfos[0]
+		if !bytes.Equal(getMessageDigest(signer.SignedAttrs.Bytes), digest) {
+			continue
+		}
+		// The signed attributes are signed with the SET tag instead of the implicit tag.
+		signedAttrs := append([]byte{0x31}, signer.SignedAttrs.FullBytes[1:]...)
+		attrsDigest := sha256.Sum256(signedAttrs)
+		if ecdsa.VerifyASN1(key, attrsDigest[:], signer.Signature) {
+			valid++
+		}
+	}
+	return valid
+}
+
+func getMessageDigest(attrs []byte) []byte {
+	for len(attrs) > 0 {
+		var attr attribute
+		rest, err := asn1.Unmarshal(attrs, &attr)
+		if err != nil {
+			return nil
+		}
+		if attr.Type.Equal(oidMessageDigest) {
+			var digest []byte
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &digest); err == nil {
+				return digest
+			}
+		}
+		attrs = rest
+	}
+	return nil
+}
+
+func main() {
+	start := time.Now()
+	Example55()
+	pdfjet.PrintDuration("Example_55", time.Since(start))
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+	"github.com/edragoev1/pdfjet/src/permission"
+)
+
+// Example56 -- AES-256 encryption with user and owner passwords and permissions
+func Example56() {
+	pdf := pdfjet.NewPDFFile("Example_56.pdf")
+	// The user can print and copy the text, the owner has full access.
+	if err := pdf.SetEncryption("reader", "owner", permission.Print|permission.Copy); err != nil {
+		log.Fatal(err)
+	}
+
+	font := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	font.SetSize(14.0)
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	text := "This document is encrypted with AES-256."
+	textLine := pdfjet.NewTextLine(font, text)
+	textLine.SetLocation(50.0, 80.0)
+	textLine.DrawOn(page)
+	pdf.SetTitle("Encrypted document")
+	pdf.Complete()
+
+	// Neither the text nor the title can be found in the encrypted document.
+	buf := content.OfBinaryFile("Example_56.pdf")
+	hexText := strings.ToUpper(hex.EncodeToString([]byte(text)))
+	if bytes.Contains(buf, []byte(hexText)) ||
+		bytes.Contains(buf, []byte("Encrypted document")) {
+		log.Fatal("Example_56: the document has unencrypted text")
+	}
+
+	// The encryption dictionary uses the standard security handler revision 6.
+	encrypt := getObject(buf, getReference(buf, "/Encrypt"))
+	for _, entry := range []string{"/V 5", "/R 6", "/CFM /AESV3", "/StmF /StdCF", "/StrF /StdCF"} {
+		if !bytes.Contains(encrypt, []byte(entry)) {
+			log.Fatalf("Example_56: the encryption dictionary has no %s", entry)
+		}
+	}
+	u := getHexString(encrypt, "/U")
+	o := getHexString(encrypt, "/O")
+
+	// The user and the owner passwords are validated by their hashes, the wrong password is not.
+	if !bytes.Equal(hashR6([]byte("reader"), u[32:40], nil), u[:32]) {
+		log.Fatal("Example_56: the user password is not valid")
+	}
+	if !bytes.Equal(hashR6([]byte("owner"), o[32:40], u), o[:32]) {
+		log.Fatal("Example_56: the owner password is not valid")
+	}
+	if bytes.Equal(hashR6([]byte("guess"), u[32:40], nil), u[:32]) {
+		log.Fatal("Example_56: the wrong password is valid")
+	}
+
+	// The file key is decrypted with the user password and the permissions are checked with it.
+	key := decryptNoPadding(hashR6([]byte("reader"), u[40:48], nil), getHexString(encrypt, "/UE"))
+	perms := make([]byte, aes.BlockSize)
+	block, _ := aes.NewCipher(key)
+	block.Decrypt(perms, getHexString(encrypt, "/Perms"))
+	p := int32(binary.LittleEndian.Uint32(perms))
+	if string(perms[9:12]) != "adb" || p&(permission.Print|permission.Copy|permission.Modify) !=
+		permission.Print|permission.Copy {
+		log.Fatalf("Example_56: the permissions are %x", perms)
+	}
+	if !bytes.Contains(encrypt, []byte("/P "+strconv.Itoa(int(p)))) {
+		log.Fatalf("Example_56: the encryption dictionary does not have /P %d", p)
+	}
+
+	// The decrypted content stream shows the original text.
+	pageObj := getObject(buf, getReference(buf, "/Kids"))
+	stream := getStream(buf, getReference(pageObj, "/Contents"))
+	decrypted := make([]byte, len(stream)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, stream[:aes.BlockSize]).CryptBlocks(decrypted, stream[aes.BlockSize:])
+	decrypted = decrypted[:len(decrypted)-int(decrypted[len(decrypted)-1])]
+	reader, err := zlib.NewReader(bytes.NewReader(decrypted))
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var shown strings.Builder
+	for _, str := range regexp.MustCompile(`\[<([0-9A-F]*)>\] TJ`).FindAllSubmatch(data, -1) {
+		shown.Write(str[1])
+	}
+	if shown.String() != hexText {
+		log.Fatal("Example_56: the decrypted text is not the original text")
+	}
+}
+
+// getReference returns the number of the first object referenced after the key.
+func getReference(buf []byte, key string) int {
+	match := regexp.MustCompile(regexp.QuoteMeta(key) + `\s*\[?\s*(\d+) 0 R`).FindSubmatch(buf)
+	if match == nil {
+		log.Fatalf("Example_56: no reference after %s", key)
+	}
+	number, _ := strconv.Atoi(string(match[1]))
+	return number
+}
+
+// getObject returns the dictionary of the object.
+func getObject(buf []byte, number int) []byte {
+	match := regexp.MustCompile(`(?s)\n` + strconv.Itoa(number) + ` 0 obj\n(<<.*?\n>>)\n`).FindSubmatch(buf)
+	if match == nil {
+		log.Fatalf("Example_56: object %d not found", number)
+	}
+	return match[1]
+}
+
+// getStream returns the stream data of the object.
+func getStream(buf []byte, number int) []byte {
+	dict := getObject(buf, number)
+	match := regexp.MustCompile(`/Length (\d+)`).FindSubmatch(dict)
+	length, _ := strconv.Atoi(string(match[1]))
+	start := bytes.Index(buf, dict) + len(dict)
+	start += bytes.Index(buf[start:], []byte("stream\n")) + len("stream\n")
+	return buf[start : start+length]
+}
+
+// getHexString returns the bytes of the hexadecimal string after the key.
+func getHexString(dict []byte, key string) []byte {
+	match := regexp.MustCompile(regexp.QuoteMeta(key) + ` <([0-9A-Fa-f]*)>`).FindSubmatch(dict)
+	if match == nil {
+		log.Fatalf("Example_56: no %s in the encryption dictionary", key)
+	}
+	value, _ := hex.DecodeString(string(match[1]))
+	return value
+}
+
+// hashR6 computes the password hash as described in ISO 32000-2, Algorithm 2.B.
+func hashR6(password, salt, userKey []byte) []byte {
+	digest := sha256.Sum256(append(append(append([]byte{}, password...), salt...), userKey...))
+	k := digest[:]
+	var e []byte
+	for i := 0; i < 64 || int(e[len(e)-1]) > i-32; i++ {
+		seq := append(append(append([]byte{}, password...), k...), userKey...)
+		k1 := bytes.Repeat(seq, 64)
+		block, _ := aes.NewCipher(k[:16])
+		e = make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+		// The first 16 bytes as big-endian number modulo 3 is the sum of the bytes modulo 3.
+		sum := 0
+		for _, b := range e[:16] {
+			sum += int(b)
+		}
+		var h hash.Hash
+		switch sum % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(e)
+		k = h.Sum(nil)
+	}
+	return k[:32]
+}
+
+// decryptNoPadding decrypts the data using AES-256 in CBC mode with zero IV and no padding.
+func decryptNoPadding(key, data []byte) []byte {
+	block, _ := aes.NewCipher(key)
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(decrypted, data)
+	return decrypted
+}
+
+func main() {
+	start := time.Now()
+	Example56()
+	pdfjet.PrintDuration("Example_56", time.Since(start))
+}
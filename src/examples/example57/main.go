@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+	"github.com/edragoev1/pdfjet/src/permission"
+)
+
+// Example57 -- Reads encrypted PDF with the owner password and writes decrypted copy
+func Example57() {
+	encrypted := createEncryptedDocument()
+
+	// The document cannot be read with wrong password.
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	if _, err := reader.ReadWithPasswordErr(encrypted, "guess"); !errors.Is(err, pdfjet.ErrIncorrectPassword) {
+		log.Fatal("Example_57: the document was opened with wrong password")
+	}
+
+	pdf := pdfjet.NewPDFFile("Example_57.pdf")
+	objects := pdf.ReadWithPassword(encrypted, "owner")
+	pdf.AddObjects(&objects)
+	pdf.Complete()
+
+	// The copy is read without password and has the same text as the encrypted document.
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_57.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pages := pdf.GetPageObjects(objects)
+	if getPageText(pages[0], objects) != "Decrypted with the owner password." {
+		log.Fatal("Example_57: the text of the copy is not the original text")
+	}
+}
+
+// getPageText returns the text shown with core fonts on the page - one line for each text object.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, block := range regexp.MustCompile(`(?s)BT\n(.*?)ET\n`).FindAllStringSubmatch(data, -1) {
+			var line []byte
+			for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(block[1], -1) {
+				for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+					buf, _ := hex.DecodeString(str[1])
+					line = append(line, buf...)
+				}
+			}
+			lines = append(lines, string(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// createEncryptedDocument creates PDF with user password that only allows printing.
+func createEncryptedDocument() []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	if err := pdf.SetEncryption("reader", "owner", permission.Print); err != nil {
+		log.Fatal(err)
+	}
+	font := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	textLine := pdfjet.NewTextLine(font, "Decrypted with the owner password.")
+	textLine.SetLocation(50.0, 80.0)
+	textLine.DrawOn(page)
+	pdf.Complete()
+	w.Flush()
+	return buf.Bytes()
+}
+
+func main() {
+	start := time.Now()
+	Example57()
+	pdfjet.PrintDuration("Example_57", time.Since(start))
+}
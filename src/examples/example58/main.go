@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example58 -- Merges two PDF documents and checks the pages of the merged document
+func Example58() {
+	first := createDocument("First", 2)
+	second := createDocument("Second", 3)
+
+	pdf := pdfjet.NewPDFFile("Example_58.pdf")
+	pdf.Merge(bytes.NewReader(first), bytes.NewReader(second))
+	pdf.Complete()
+
+	// The merged document has the pages of the first document followed by the pages of the second.
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_58.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	expected := []string{
+		"First document, page 1",
+		"First document, page 2",
+		"Second document, page 1",
+		"Second document, page 2",
+		"Second document, page 3",
+	}
+	pages := pdf.GetPageObjects(objects)
+	if len(pages) != len(expected) {
+		log.Fatalf("Example_58: expected %d pages, found %d", len(expected), len(pages))
+	}
+	for i, page := range pages {
+		if getPageText(page, objects) != expected[i] {
+			log.Fatalf("Example_58: page %d does not contain %q", i+1, expected[i])
+		}
+	}
+
+	mergeDestinations()
+}
+
+// mergeDestinations merges two documents that have named destinations with the same names.
+// The destinations of the second document are renamed and its links use the new names.
+func mergeDestinations() {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	pdf.Merge(
+		bytes.NewReader(createDocumentWithDestinations("First")),
+		bytes.NewReader(createDocumentWithDestinations("Second")))
+	pdf.Complete()
+	w.Flush()
+
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects := pdf.Read(buf.Bytes())
+	pages := pdf.GetPageObjects(objects)
+	if len(pages) != 2 {
+		log.Fatalf("Example_58: expected 2 pages, found %d", len(pages))
+	}
+
+	// Each name points to the page of its own document.
+	dests := make(map[string]string)
+	for _, obj := range objects {
+		dict := strings.Join(obj.GetDict(), " ")
+		for _, match := range regexp.MustCompile(`(\(\S+\)|/[\w-]+) \[ (\d+) 0 R /(XYZ|Fit)`).FindAllStringSubmatch(dict, -1) {
+			dests[match[1]] = match[2]
+		}
+	}
+	first := pages[0].GetDict()[0] // The object number
+	second := pages[1].GetDict()[0]
+	if dests["(intro)"] != first || dests["(doc2-intro)"] != second ||
+		dests["/summary"] != first || dests["/doc2-summary"] != second {
+		log.Fatalf("Example_58: the merged destinations are %v", dests)
+	}
+
+	// The links of the second document use the new names and the strings keep their bytes.
+	for i, page := range pages {
+		prefix := []string{"", "doc2-"}[i]
+		name := []string{"First", "Second"}[i]
+		annots := ""
+		for _, number := range page.GetObjectNumbers("/Annots") {
+			annots += strings.Join(objects[number-1].GetDict(), " ") + "\n"
+		}
+		if !strings.Contains(annots, "/Dest ("+prefix+"intro)") ||
+			!strings.Contains(annots, "/D /"+prefix+"summary") ||
+			!strings.Contains(annots, "/Contents ("+name+": see  the intro /a)") {
+			log.Fatalf("Example_58: the links on page %d are %s", i+1, annots)
+		}
+	}
+}
+
+// createDocumentWithDestinations creates one page PDF with named destinations in the name tree
+// and in the /Dests dictionary and with links to them. The PDF objects are written directly,
+// because the generated documents use explicit destinations.
+func createDocumentWithDestinations(name string) []byte {
+	text := "BT /F1 12 Tf 50 700 Td (" + name + " document) Tj ET"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /Names << /Dests 5 0 R >> /Dests 6 0 R >>",
+		"<< /Type /Pages /Kids [ 3 0 R ] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [ 0 0 612 792 ] /Contents 4 0 R " +
+			"/Resources << /Font << /F1 7 0 R >> >> /Annots [ 8 0 R 9 0 R ] >>",
+		"<< /Length " + strconv.Itoa(len(text)) + " >>\nstream\n" + text + "\nendstream",
+		"<< /Names [ (intro) [ 3 0 R /XYZ 0 792 0 ] ] >>",
+		"<< /summary [ 3 0 R /Fit ] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Annot /Subtype /Link /Rect [ 50 690 150 710 ] /Dest (intro) " +
+			"/Contents (" + name + ": see  the intro /a) >>",
+		"<< /Type /Annot /Subtype /Link /Rect [ 50 660 150 680 ] /A << /S /GoTo /D /summary >> >>",
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	startxref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, startxref)
+	return buf.Bytes()
+}
+
+// getPageText returns the text shown with core fonts on the page - one line for each text object.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, block := range regexp.MustCompile(`(?s)BT\n(.*?)ET\n`).FindAllStringSubmatch(data, -1) {
+			var line []byte
+			for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(block[1], -1) {
+				for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+					buf, _ := hex.DecodeString(str[1])
+					line = append(line, buf...)
+				}
+			}
+			lines = append(lines, string(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// createDocument creates PDF with the specified number of pages.
+func createDocument(name string, numOfPages int) []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	font := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	for i := 1; i <= numOfPages; i++ {
+		page := pdfjet.NewPage(pdf, letter.Portrait)
+		textLine := pdfjet.NewTextLine(font, fmt.Sprintf("%s document, page %d", name, i))
+		textLine.SetLocation(50.0, 80.0)
+		textLine.DrawOn(page)
+	}
+	pdf.Complete()
+	w.Flush()
+	return buf.Bytes()
+}
+
+func main() {
+	start := time.Now()
+	Example58()
+	pdfjet.PrintDuration("Example_58", time.Since(start))
+}
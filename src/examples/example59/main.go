@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example59 -- Extracts pages 4 and 2 of five page document into new PDF
+func Example59() {
+	pdf := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects := pdf.Read(createDocument(5))
+
+	pdf = pdfjet.NewPDFFile("Example_59.pdf")
+	pdf.ExtractPages(objects, 4, 2)
+	pdf.Complete()
+
+	// The new document has only the extracted pages in the requested order.
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_59.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	expected := []string{"Page 4 of 5", "Page 2 of 5"}
+	pages := pdf.GetPageObjects(objects)
+	if len(pages) != len(expected) {
+		log.Fatalf("Example_59: expected %d pages, found %d", len(expected), len(pages))
+	}
+	for i, page := range pages {
+		if getPageText(page, objects) != expected[i] {
+			log.Fatalf("Example_59: page %d does not contain %q", i+1, expected[i])
+		}
+	}
+}
+
+// getPageText returns the text shown with core fonts on the page - one line for each text object.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, block := range regexp.MustCompile(`(?s)BT\n(.*?)ET\n`).FindAllStringSubmatch(data, -1) {
+			var line []byte
+			for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(block[1], -1) {
+				for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+					buf, _ := hex.DecodeString(str[1])
+					line = append(line, buf...)
+				}
+			}
+			lines = append(lines, string(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// createDocument creates PDF with the specified number of pages.
+func createDocument(numOfPages int) []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	font := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	for i := 1; i <= numOfPages; i++ {
+		page := pdfjet.NewPage(pdf, letter.Portrait)
+		textLine := pdfjet.NewTextLine(font, fmt.Sprintf("Page %d of %d", i, numOfPages))
+		textLine.SetLocation(50.0, 80.0)
+		textLine.DrawOn(page)
+	}
+	pdf.Complete()
+	w.Flush()
+	return buf.Bytes()
+}
+
+func main() {
+	start := time.Now()
+	Example59()
+	pdfjet.PrintDuration("Example_59", time.Since(start))
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example60 -- Table with link in every row written with compressed object streams
+func Example60() {
+	classic := createReport(false)
+	compressed := createReport(true)
+	if err := os.WriteFile("Example_60.pdf", compressed, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	if !bytes.Contains(compressed, []byte("/Type /ObjStm")) ||
+		!bytes.Contains(compressed, []byte("/Type /XRef")) {
+		log.Fatal("Example_60: no object streams or cross-reference stream")
+	}
+	if len(compressed) >= len(classic) {
+		log.Fatalf("Example_60: %d bytes with object streams, %d bytes without", len(compressed), len(classic))
+	}
+
+	// Both documents have the same pages and the same text.
+	text1 := extractText(classic)
+	text2 := extractText(compressed)
+	if len(text1) == 0 || len(text1) != len(text2) {
+		log.Fatalf("Example_60: %d pages without object streams, %d pages with", len(text1), len(text2))
+	}
+	for i := range text1 {
+		if text1[i] != text2[i] {
+			log.Fatalf("Example_60: the text of page %d is different", i+1)
+		}
+	}
+	if !strings.Contains(text2[len(text2)-1], "Item 300") {
+		log.Fatal("Example_60: the last row is missing")
+	}
+}
+
+// createReport creates multi-page table with URI action in the first cell of each row.
+func createReport(objectStreams bool) []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	pdf.SetObjectStreams(objectStreams)
+
+	f1 := pdfjet.NewCoreFont(pdf, corefont.HelveticaBold())
+	f2 := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	f1.SetSize(9.0)
+	f2.SetSize(9.0)
+
+	tableData := make([][]*pdfjet.Cell, 0)
+	tableData = append(tableData, []*pdfjet.Cell{
+		pdfjet.NewCell(f1, "Item"),
+		pdfjet.NewCell(f1, "Description"),
+		pdfjet.NewCell(f1, "Amount"),
+	})
+	for i := 1; i <= 300; i++ {
+		uri := fmt.Sprintf("https://example.com/items/%d", i)
+		item := pdfjet.NewCell(f2, fmt.Sprintf("Item %d", i))
+		item.SetURIAction(&uri)
+		tableData = append(tableData, []*pdfjet.Cell{
+			item,
+			pdfjet.NewCell(f2, fmt.Sprintf("Description of item %d", i)),
+			pdfjet.NewCell(f2, fmt.Sprintf("%d.%02d", i*17, i%100)),
+		})
+	}
+
+	table := pdfjet.NewTable()
+	table.SetData(tableData, pdfjet.TableWith1HeaderRow)
+	table.SetLocation(50.0, 50.0)
+	table.SetColumnWidths()
+	table.RightAlignNumbers()
+
+	pages := make([]*pdfjet.Page, 0)
+	table.DrawOnPages(pdf, &pages, letter.Portrait)
+	for _, page := range pages {
+		pdf.AddPage(page)
+	}
+	pdf.Complete()
+	w.Flush()
+	return buf.Bytes()
+}
+
+// extractText returns the text of each page of the document.
+func extractText(buf []byte) []string {
+	pdf := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	text := make([]string, 0)
+	for _, page := range pdf.GetPageObjects(objects) {
+		text = append(text, getPageText(page, objects))
+	}
+	return text
+}
+
+// getPageText returns the text shown with core fonts on the page - one line for each text object.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, block := range regexp.MustCompile(`(?s)BT\n(.*?)ET\n`).FindAllStringSubmatch(data, -1) {
+			var line []byte
+			for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(block[1], -1) {
+				for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+					buf, _ := hex.DecodeString(str[1])
+					line = append(line, buf...)
+				}
+			}
+			lines = append(lines, string(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func main() {
+	start := time.Now()
+	Example60()
+	pdfjet.PrintDuration("Example_60", time.Since(start))
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example61 -- Writes 5,000 pages with link on each page in the constant-memory streaming mode
+func Example61() {
+	const numOfPages = 5000
+
+	pdf := pdfjet.NewPDFFile("Example_61.pdf")
+	if err := pdf.SetStreaming(); err != nil {
+		log.Fatal(err)
+	}
+	font := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+
+	var heapAfter1000 uint64
+	for i := 1; i <= numOfPages; i++ {
+		page := pdfjet.NewPage(pdf, letter.Portrait)
+		uri := fmt.Sprintf("https://example.com/ledger/%d", i)
+		textLine := pdfjet.NewTextLine(font, fmt.Sprintf("Ledger page %d of %d", i, numOfPages))
+		textLine.SetLocation(50.0, 80.0)
+		textLine.SetURIAction(&uri)
+		textLine.DrawOn(page)
+		if i == 1000 {
+			heapAfter1000 = heapInUse()
+		}
+	}
+
+	// The memory used by the last 4,000 pages is only few integers per page.
+	growth := int64(heapInUse()) - int64(heapAfter1000)
+	if growth > 1<<20 {
+		log.Fatalf("Example_61: the heap grew by %d bytes after page 1000", growth)
+	}
+	pdf.Complete()
+
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_61.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pages := pdf.GetPageObjects(objects)
+	if len(pages) != numOfPages {
+		log.Fatalf("Example_61: expected %d pages, found %d", numOfPages, len(pages))
+	}
+	if getPageText(pages[numOfPages-1], objects) != "Ledger page 5000 of 5000" {
+		log.Fatal("Example_61: the last page does not have the expected text")
+	}
+}
+
+// getPageText returns the text shown with core fonts on the page - one line for each text object.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, block := range regexp.MustCompile(`(?s)BT\n(.*?)ET\n`).FindAllStringSubmatch(data, -1) {
+			var line []byte
+			for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(block[1], -1) {
+				for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+					buf, _ := hex.DecodeString(str[1])
+					line = append(line, buf...)
+				}
+			}
+			lines = append(lines, string(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// heapInUse returns the bytes allocated on the heap after garbage collection.
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func main() {
+	start := time.Now()
+	Example61()
+	pdfjet.PrintDuration("Example_61", time.Since(start))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example62 -- Embeds only the used glyphs of TrueType font and CFF based CJK font
+func Example62() {
+	subset := createDocument(true)
+	full := createDocument(false)
+	if err := os.WriteFile("Example_62.pdf", subset, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(subset)*10 > len(full) {
+		log.Fatalf("Example_62: %d bytes with subsetting, %d bytes without", len(subset), len(full))
+	}
+	tagged := regexp.MustCompile(`/BaseFont /[A-Z]{6}\+`).FindAll(subset, -1)
+	if len(tagged) == 0 || len(tagged) != bytes.Count(subset, []byte("/BaseFont /")) {
+		log.Fatal("Example_62: the font names do not have subset tag")
+	}
+
+	// The ToUnicode CMaps of the subset fonts map the glyphs back to the original text.
+	pdf := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(subset)
+	if err != nil {
+		log.Fatal(err)
+	}
+	text := getPageText(pdf.GetPageObjects(objects)[0], objects)
+	if text != "Invoice 2024-0117\n請求書 合計金額" {
+		log.Fatalf("Example_62: the text is %q", text)
+	}
+}
+
+// getPageText returns the text on the page - one line for each text object.
+// The glyph IDs are mapped back to the text with the ToUnicode CMaps of the fonts.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	cmaps := make(map[string]map[string]string)
+	fonts := regexp.MustCompile(`/Font << (.*?) >>`).FindStringSubmatch(resources)
+	for _, font := range regexp.MustCompile(`/(\w+) (\d+) 0 R`).FindAllStringSubmatch(fonts[1], -1) {
+		dict := strings.Join(getObject(objects, font[2]).GetDict(), " ")
+		if match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict); match != nil {
+			cmaps[font[1]] = getToUnicode(getObject(objects, match[1]).GetData())
+		}
+	}
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		var cmap map[string]string
+		var line strings.Builder
+		for _, op := range regexp.MustCompile(`/(\w+) [\d.]+ Tf|\[([^\]]*)\] TJ|\bET\b`).FindAllStringSubmatch(data, -1) {
+			if op[1] != "" {
+				cmap = cmaps[op[1]]
+				continue
+			}
+			if op[0] == "ET" {
+				lines = append(lines, line.String())
+				line.Reset()
+				continue
+			}
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(op[2], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					line.WriteString(cmap[str[1][i:i+4]])
+				}
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+// createDocument creates invoice header using Latin and Japanese font.
+func createDocument(subset bool) []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	pdf.SetFontSubsetting(subset)
+
+	f1 := pdfjet.NewFontFromFile(pdf, "fonts/NotoSans/NotoSans-Regular.ttf")
+	f2 := pdfjet.NewFontFromFile(pdf, "fonts/NotoSansJP/NotoSansJP-Regular.ttf.stream")
+	f1.SetSize(14.0)
+	f2.SetSize(14.0)
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	textLine := pdfjet.NewTextLine(f1, "Invoice 2024-0117")
+	textLine.SetLocation(50.0, 80.0)
+	textLine.DrawOn(page)
+
+	textLine = pdfjet.NewTextLine(f2, "請求書 合計金額")
+	textLine.SetLocation(50.0, 110.0)
+	textLine.DrawOn(page)
+
+	pdf.Complete()
+	w.Flush()
+	return buf.Bytes()
+}
+
+func main() {
+	start := time.Now()
+	Example62()
+	pdfjet.PrintDuration("Example_62", time.Since(start))
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example63 -- Ligatures, kerning and mark positioning from the GSUB and GPOS tables of the font
+func Example63() {
+	pdf := pdfjet.NewPDFFile("Example_63.pdf")
+	font := pdfjet.NewFontFromFile(pdf, "fonts/SourceSerif4/SourceSerif4-Regular.ttf")
+	font.SetSize(24.0)
+
+	// The kerning pair is narrower than the two letters drawn one by one.
+	if font.StringWidth(nil, "AV") >= font.StringWidth(nil, "A")+font.StringWidth(nil, "V") {
+		log.Fatal("Example_63: the kerning is not applied")
+	}
+	// The combining mark is positioned over the base letter and does not advance the text.
+	if font.StringWidth(nil, "e\u0301") != font.StringWidth(nil, "e") {
+		log.Fatal("Example_63: the combining mark advances the text")
+	}
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	lines := []string{
+		"The office staff affirmed the final figures.",
+		"AVATAR WAVE Toyota",
+		"Cafe\u0301 nai\u0308ve",
+		"fi ffi",
+	}
+	y := float32(80.0)
+	for _, line := range lines {
+		textLine := pdfjet.NewTextLine(font, line)
+		textLine.SetLocation(50.0, y)
+		textLine.DrawOn(page)
+		y += 40.0
+	}
+	pdf.Complete()
+
+	// The ligatures are mapped back to the letters they are made from.
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_63.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	page0 := pdf.GetPageObjects(objects)[0]
+	text := getPageText(page0, objects)
+	if !strings.HasPrefix(text, lines[0]+"\n") {
+		log.Fatalf("Example_63: the text is %q", text)
+	}
+
+	// The "fi" and "ffi" are drawn with the single ligature glyph that has single advance.
+	cmap := getTable(content.OfBinaryFile("fonts/SourceSerif4/SourceSerif4-Regular.ttf"), "cmap")
+	expected := []int{getGlyphID(cmap, 0xFB01), getGlyphID(cmap, ' '), getGlyphID(cmap, 0xFB03)}
+	glyphIDs := getGlyphIDs(page0, objects)
+	gids := glyphIDs[len(glyphIDs)-1]
+	if fmt.Sprint(gids) != fmt.Sprint(expected) {
+		log.Fatalf("Example_63: the glyph IDs of %q are %v, expected %v", lines[3], gids, expected)
+	}
+	if font.StringWidth(nil, "fi") != font.StringWidth(nil, "\uFB01") ||
+		font.StringWidth(nil, "ffi") != font.StringWidth(nil, "\uFB03") {
+		log.Fatal("Example_63: the ligatures do not have single advance")
+	}
+}
+
+// getPageText returns the text on the page - one line for each TJ operator.
+// The glyph IDs are mapped back to the text with the ToUnicode CMaps of the fonts.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	cmaps := make(map[string]map[string]string)
+	fonts := regexp.MustCompile(`/Font << (.*?) >>`).FindStringSubmatch(resources)
+	for _, font := range regexp.MustCompile(`/(\w+) (\d+) 0 R`).FindAllStringSubmatch(fonts[1], -1) {
+		dict := strings.Join(getObject(objects, font[2]).GetDict(), " ")
+		if match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict); match != nil {
+			cmaps[font[1]] = getToUnicode(getObject(objects, match[1]).GetData())
+		}
+	}
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		var cmap map[string]string
+		for _, op := range regexp.MustCompile(`/(\w+) [\d.]+ Tf|\[([^\]]*)\] TJ`).FindAllStringSubmatch(data, -1) {
+			if op[1] != "" {
+				cmap = cmaps[op[1]]
+				continue
+			}
+			var line strings.Builder
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(op[2], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					line.WriteString(cmap[str[1][i:i+4]])
+				}
+			}
+			lines = append(lines, line.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+// getGlyphIDs returns the glyph IDs shown on the page - one list for each TJ operator.
+func getGlyphIDs(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) [][]int {
+	lines := make([][]int, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(data, -1) {
+			gids := make([]int, 0)
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					gid, _ := strconv.ParseUint(str[1][i:i+4], 16, 16)
+					gids = append(gids, int(gid))
+				}
+			}
+			lines = append(lines, gids)
+		}
+	}
+	return lines
+}
+
+// getTable returns the table of the TrueType font.
+func getTable(font []byte, tag string) []byte {
+	numTables := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < numTables; i++ {
+		record := font[12+16*i:]
+		if string(record[:4]) == tag {
+			offset := binary.BigEndian.Uint32(record[8:])
+			return font[offset : offset+binary.BigEndian.Uint32(record[12:])]
+		}
+	}
+	return nil
+}
+
+// getGlyphID returns the glyph ID of the character from the format 4 subtable of the cmap table.
+func getGlyphID(cmap []byte, c rune) int {
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := cmap[4+8*i:]
+		subtable := cmap[binary.BigEndian.Uint32(record[4:]):]
+		if binary.BigEndian.Uint16(subtable) != 4 {
+			continue
+		}
+		segCount := int(binary.BigEndian.Uint16(subtable[6:])) / 2
+		for j := 0; j < segCount; j++ {
+			endCode := rune(binary.BigEndian.Uint16(subtable[14+2*j:]))
+			startCode := rune(binary.BigEndian.Uint16(subtable[16+2*segCount+2*j:]))
+			if c < startCode || c > endCode {
+				continue
+			}
+			idDelta := int(binary.BigEndian.Uint16(subtable[16+4*segCount+2*j:]))
+			rangeOffset := 16 + 6*segCount + 2*j
+			idRangeOffset := int(binary.BigEndian.Uint16(subtable[rangeOffset:]))
+			if idRangeOffset == 0 {
+				return (int(c) + idDelta) & 0xFFFF
+			}
+			gid := int(binary.BigEndian.Uint16(subtable[rangeOffset+idRangeOffset+2*int(c-startCode):]))
+			if gid == 0 {
+				return 0
+			}
+			return (gid + idDelta) & 0xFFFF
+		}
+	}
+	return 0
+}
+
+func main() {
+	start := time.Now()
+	Example63()
+	pdfjet.PrintDuration("Example_63", time.Since(start))
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example64 -- Arabic, Devanagari and Thai text shaped using the GSUB and GPOS tables of the fonts
+func Example64() {
+	pdf := pdfjet.NewPDFFile("Example_64.pdf")
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+
+	lines := []struct {
+		fontFile string
+		text     string
+		glyphs   []string // The names of the expected glyphs from left to right
+	}{
+		// The letters take their initial, medial, final and isolated forms and are shown from right to left.
+		{"fonts/NotoSansArabic/NotoSansArabic-Regular.ttf", "وثيقة التأمين الصحي",
+			[]string{"uni0649.fina", "uni062D.medi", "uni0635.medi", "uni0644.init", "uni0627"}},
+		// The vowel sign I is shown before the consonant and the reph is shown above the end of the syllable.
+		{"fonts/NotoSansDevanagari/NotoSansDevanagari-Regular.ttf", "स्वास्थ्य बीमा पॉलिसी की शर्तें",
+			[]string{"uni093F.08", "uni0932", "uni0924", "uni09470930094D0902"}},
+		// The above-base marks are stacked and the tone mark is moved out of the way of the vowel.
+		{"fonts/NotoSansThai/NotoSansThai-Regular.ttf", "กรมธรรม์ประกันสุขภาพ จำนวนเงินค้ำประกัน",
+			[]string{"uni0E21", "uni0E4C", "uni0E04", "uni0E4D", "uni0E49.small", "uni0E32"}},
+	}
+	y := float32(80.0)
+	for _, line := range lines {
+		font := pdfjet.NewFontFromFile(pdf, line.fontFile)
+		font.SetSize(20.0)
+		textLine := pdfjet.NewTextLine(font, line.text)
+		textLine.SetLocation(50.0, y)
+		textLine.DrawOn(page)
+		y += 50.0
+	}
+	pdf.Complete()
+
+	// Each line is shown with the glyphs selected by the shaper.
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_64.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	blocks := getGlyphIDs(pdf.GetPageObjects(objects)[0], objects)
+	if len(blocks) != len(lines) {
+		log.Fatalf("Example_64: expected %d lines of text, found %d", len(lines), len(blocks))
+	}
+	for i, line := range lines {
+		font, err := os.ReadFile(line.fontFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		names := getGlyphNames(font)
+		shown := make([]string, 0)
+		for _, gid := range blocks[i] {
+			shown = append(shown, names[gid])
+		}
+		if !containsInOrder(shown, line.glyphs) {
+			log.Fatalf("Example_64: line %d is shown with the glyphs %v", i+1, shown)
+		}
+	}
+}
+
+// containsInOrder returns true if all the names are found in the glyphs in the same order.
+func containsInOrder(glyphs, names []string) bool {
+	j := 0
+	for _, glyph := range glyphs {
+		if j < len(names) && glyph == names[j] {
+			j++
+		}
+	}
+	return j == len(names)
+}
+
+// getGlyphIDs returns the glyph IDs shown on the page - one list for each text object.
+func getGlyphIDs(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) [][]int {
+	blocks := make([][]int, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, block := range regexp.MustCompile(`(?s)BT\n(.*?)ET\n`).FindAllStringSubmatch(data, -1) {
+			gids := make([]int, 0)
+			for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(block[1], -1) {
+				for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+					for j := 0; j+4 <= len(str[1]); j += 4 {
+						gid, _ := strconv.ParseUint(str[1][j:j+4], 16, 16)
+						gids = append(gids, int(gid))
+					}
+				}
+			}
+			blocks = append(blocks, gids)
+		}
+	}
+	return blocks
+}
+
+// getGlyphNames returns the glyph names from the version 2.0 post table of the font.
+func getGlyphNames(font []byte) []string {
+	var post []byte
+	numTables := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < numTables; i++ {
+		record := font[12+16*i:]
+		if string(record[:4]) == "post" {
+			offset := binary.BigEndian.Uint32(record[8:])
+			post = font[offset : offset+binary.BigEndian.Uint32(record[12:])]
+		}
+	}
+	if post == nil || binary.BigEndian.Uint32(post) != 0x00020000 {
+		log.Fatal("Example_64: the font has no version 2.0 post table")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(post[32:]))
+	// The custom names are Pascal strings after the name indexes.
+	custom := make([]string, 0)
+	for offset := 34 + 2*numGlyphs; offset < len(post); offset += 1 + int(post[offset]) {
+		custom = append(custom, string(post[offset+1:offset+1+int(post[offset])]))
+	}
+	names := make([]string, numGlyphs)
+	for i := range names {
+		index := int(binary.BigEndian.Uint16(post[34+2*i:]))
+		if index >= 258 {
+			names[i] = custom[index-258]
+		} else {
+			// The standard Macintosh names are not used by the assertions.
+			names[i] = "mac" + strconv.Itoa(index)
+		}
+	}
+	return names
+}
+
+func main() {
+	start := time.Now()
+	Example64()
+	pdfjet.PrintDuration("Example_64", time.Since(start))
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example65 -- Hebrew text with embedded English, numbers and brackets reordered by the bidirectional algorithm
+func Example65() {
+	fontFile := "fonts/NotoSansHebrew/NotoSansHebrew-Regular.ttf"
+	pdf := pdfjet.NewPDFFile("Example_65.pdf")
+	font := pdfjet.NewFontFromFile(pdf, fontFile)
+	font.SetSize(14.0)
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	lines := []struct {
+		text   string
+		visual string // The characters from left to right with the mirrored brackets
+	}{
+		{"שלום לכל העולם World 123", "World 123 םלועה לכל םולש"},
+		{"The policy (פוליסה בריאות) covers 2 people.", "The policy (תואירב הסילופ) covers 2 people."},
+		{"א[ב]ג", "ג[ב]א"},
+		{"א(ב[ג]ד)ה", "ה(ד[ג]ב)א"},
+		{"א{ב(ג)ד}ה", "ה{ד(ג)ב}א"},
+		{"שלום {World} עולם", "םלוע {World} םולש"},
+		{"Hello [שלום {עולם}] World", "Hello [{םלוע} םולש] World"},
+	}
+	y := float32(80.0)
+	for _, line := range lines {
+		textLine := pdfjet.NewTextLine(font, line.text)
+		textLine.SetLocation(50.0, y)
+		textLine.DrawOn(page)
+		y += 30.0
+	}
+
+	// The paragraph is broken into lines first and then each line is reordered.
+	paragraph := "הפוליסה מכסה אשפוז (Hospitalization) וניתוחים עד 500,000 ש״ח לשנה, " +
+		"כולל טיפולים בחו״ל (Abroad) לפי תנאי הפוליסה."
+	textBox := pdfjet.NewTextBox(font)
+	textBox.SetText(paragraph)
+	textBox.SetLocation(50.0, y)
+	textBox.SetWidth(250.0)
+	textBox.SetBorders(false)
+	textBox.DrawOn(page)
+	pdf.Complete()
+
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_65.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	buf, err := os.ReadFile(fontFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmap := getTable(buf, "cmap")
+	shown := getGlyphIDs(pdf.GetPageObjects(objects)[0], objects)
+	if len(shown) < len(lines)+2 {
+		log.Fatalf("Example_65: expected at least %d lines of text, found %d", len(lines)+2, len(shown))
+	}
+
+	// The glyphs of each line from left to right are in visual order.
+	for i, line := range lines {
+		if !equal(shown[i], toGlyphIDs(cmap, line.visual)) {
+			log.Fatalf("Example_65: line %d is shown with the glyphs %v", i+1, shown[i])
+		}
+	}
+
+	// The first word of the paragraph is at the right end of the first line only.
+	first := toGlyphIDs(cmap, "הסילופה")
+	if !hasSuffix(shown[len(lines)], first) || hasSuffix(shown[len(lines)+1], first) {
+		log.Fatalf("Example_65: the paragraph is shown with the glyphs %v", shown[len(lines):])
+	}
+}
+
+// equal returns true if the glyph IDs are the same.
+func equal(gids1, gids2 []int) bool {
+	if len(gids1) != len(gids2) {
+		return false
+	}
+	for i := range gids1 {
+		if gids1[i] != gids2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSuffix returns true if the glyph IDs end with the suffix.
+func hasSuffix(gids, suffix []int) bool {
+	return len(gids) >= len(suffix) && equal(gids[len(gids)-len(suffix):], suffix)
+}
+
+// toGlyphIDs returns the glyph IDs of the characters of the text.
+func toGlyphIDs(cmap []byte, text string) []int {
+	gids := make([]int, 0)
+	for _, c := range text {
+		gids = append(gids, getGlyphID(cmap, c))
+	}
+	return gids
+}
+
+// getGlyphIDs returns the glyph IDs shown on the page - one list for each line of text.
+// The runs of a line are shown from left to right in separate text objects at the same y.
+func getGlyphIDs(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) [][]int {
+	lines := make([][]int, 0)
+	lastY := ""
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		for _, block := range regexp.MustCompile(`(?s)BT\n.*? ([\d.]+) Tm\n(.*?)ET\n`).FindAllStringSubmatch(data, -1) {
+			if block[1] != lastY {
+				lines = append(lines, make([]int, 0))
+				lastY = block[1]
+			}
+			for _, array := range regexp.MustCompile(`\[([^\]]*)\] TJ`).FindAllStringSubmatch(block[2], -1) {
+				for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(array[1], -1) {
+					for j := 0; j+4 <= len(str[1]); j += 4 {
+						gid, _ := strconv.ParseUint(str[1][j:j+4], 16, 16)
+						lines[len(lines)-1] = append(lines[len(lines)-1], int(gid))
+					}
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// getTable returns the table of the TrueType font.
+func getTable(font []byte, tag string) []byte {
+	numTables := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < numTables; i++ {
+		record := font[12+16*i:]
+		if string(record[:4]) == tag {
+			offset := binary.BigEndian.Uint32(record[8:])
+			return font[offset : offset+binary.BigEndian.Uint32(record[12:])]
+		}
+	}
+	return nil
+}
+
+// getGlyphID returns the glyph ID of the character from the format 4 subtable of the cmap table.
+func getGlyphID(cmap []byte, c rune) int {
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := cmap[4+8*i:]
+		subtable := cmap[binary.BigEndian.Uint32(record[4:]):]
+		if binary.BigEndian.Uint16(subtable) != 4 {
+			continue
+		}
+		segCount := int(binary.BigEndian.Uint16(subtable[6:])) / 2
+		for j := 0; j < segCount; j++ {
+			endCode := rune(binary.BigEndian.Uint16(subtable[14+2*j:]))
+			startCode := rune(binary.BigEndian.Uint16(subtable[16+2*segCount+2*j:]))
+			if c < startCode || c > endCode {
+				continue
+			}
+			idDelta := int(binary.BigEndian.Uint16(subtable[16+4*segCount+2*j:]))
+			rangeOffset := 16 + 6*segCount + 2*j
+			idRangeOffset := int(binary.BigEndian.Uint16(subtable[rangeOffset:]))
+			if idRangeOffset == 0 {
+				return (int(c) + idDelta) & 0xFFFF
+			}
+			gid := int(binary.BigEndian.Uint16(subtable[rangeOffset+idRangeOffset+2*int(c-startCode):]))
+			if gid == 0 {
+				return 0
+			}
+			return (gid + idDelta) & 0xFFFF
+		}
+	}
+	return 0
+}
+
+func main() {
+	start := time.Now()
+	Example65()
+	pdfjet.PrintDuration("Example_65", time.Since(start))
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// The hyphenation patterns and exceptions in the TeX format used by the hyph-utf8 project.
+const patterns = `
+% Few English patterns for the words in this example
+\patterns{
+.re1i .in1 1tion 1ca 1bur 1ize 1iza 4z1a 1tal 1pi 2i1za
+}
+\hyphenation{
+in-sur-ance re-im-burse-ment hos-pi-tal-iza-tion
+}
+`
+
+// Example66 -- Narrow text box with line breaks at hyphens and slashes, no-break spaces and hyphenation
+func Example66() {
+	pdf := pdfjet.NewPDFFile("Example_66.pdf")
+	f1 := pdfjet.NewFontFromFile(pdf, "fonts/NotoSans/NotoSans-Regular.ttf")
+	f2 := pdfjet.NewFontFromFile(pdf, "fonts/NotoSansJP/NotoSansJP-Regular.ttf.stream")
+	f1.SetSize(12.0)
+	f2.SetSize(12.0)
+
+	text := "The reimbursement of hospitalization costs under this insurance policy " +
+		"is limited to 10 000 EUR per year and/or per claim, see the well-known " +
+		"terms. 保険の条件はすべての被保険者に適用されます。"
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	textBox := pdfjet.NewTextBox(f1)
+	textBox.SetFallbackFont(f2)
+	textBox.SetHyphenator(pdfjet.NewHyphenator(strings.NewReader(patterns)))
+	textBox.SetText(text)
+	textBox.SetLocation(50.0, 50.0)
+	textBox.SetWidth(110.0)
+	textBox.DrawOn(page)
+	pdf.Complete()
+
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_66.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	lines := getLines(pdf.GetPageObjects(objects)[0], objects)
+
+	// No line is outside of the text box.
+	for _, line := range lines {
+		if line.x < 50.0 || line.x+f1.StringWidth(f2, line.text) > 160.0+0.01 {
+			log.Fatalf("Example_66: %q at %.2f is outside of the text box", line.text, line.x)
+		}
+	}
+
+	texts := make([]string, 0)
+	for _, line := range lines {
+		texts = append(texts, line.text)
+	}
+	hyphenated := 0
+	for _, line := range texts {
+		if strings.Contains(line, "10") && !strings.Contains(line, "000 EUR") {
+			log.Fatalf("Example_66: the no-break spaces are broken in %q", line)
+		}
+		if strings.HasSuffix(line, "-") && !strings.HasSuffix(line, "well-") {
+			hyphenated++
+		}
+	}
+	if hyphenated == 0 {
+		log.Fatalf("Example_66: no word is hyphenated in %q", texts)
+	}
+
+	// Only the spaces and the hyphens are added or removed at the line breaks.
+	if removeBreaks(strings.Join(texts, "")) != removeBreaks(text) {
+		log.Fatalf("Example_66: the text of the lines %q is different", texts)
+	}
+}
+
+// removeBreaks returns the text without the white space and the hyphens.
+func removeBreaks(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// textLine is a line of text shown on the page.
+type textLine struct {
+	x    float32
+	text string
+}
+
+// getLines returns the lines of text on the page. The runs of text with the same y are one line.
+// The glyph IDs are mapped back to the text with the ToUnicode CMaps of the fonts.
+func getLines(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) []*textLine {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	cmaps := make(map[string]map[string]string)
+	fonts := regexp.MustCompile(`/Font << (.*?) >>`).FindStringSubmatch(resources)
+	for _, font := range regexp.MustCompile(`/(\w+) (\d+) 0 R`).FindAllStringSubmatch(fonts[1], -1) {
+		dict := strings.Join(getObject(objects, font[2]).GetDict(), " ")
+		if match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict); match != nil {
+			cmaps[font[1]] = getToUnicode(getObject(objects, match[1]).GetData())
+		}
+	}
+	lines := make([]*textLine, 0)
+	lastY := ""
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		var cmap map[string]string
+		ops := regexp.MustCompile(`/(\w+) [\d.]+ Tf|([\d.]+) ([\d.]+) Tm|\[([^\]]*)\] TJ`)
+		for _, op := range ops.FindAllStringSubmatch(data, -1) {
+			if op[1] != "" {
+				cmap = cmaps[op[1]]
+				continue
+			}
+			if op[2] != "" {
+				if op[3] != lastY {
+					x, _ := strconv.ParseFloat(op[2], 32)
+					lines = append(lines, &textLine{x: float32(x)})
+					lastY = op[3]
+				}
+				continue
+			}
+			var text strings.Builder
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(op[4], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					text.WriteString(cmap[str[1][i:i+4]])
+				}
+			}
+			lines[len(lines)-1].text += text.String()
+		}
+	}
+	return lines
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+func main() {
+	start := time.Now()
+	Example66()
+	pdfjet.PrintDuration("Example_66", time.Since(start))
+}
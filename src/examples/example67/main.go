@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/align"
+	"github.com/edragoev1/pdfjet/src/alignment"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+const paragraph = "Justified text is spread over the whole width of the column. " +
+	"The slack of each line is distributed with the word spacing and the character spacing, " +
+	"so the spaces between the words remain in the page content and the extracted text. " +
+	"The last line of the paragraph is not justified."
+
+// Example67 -- Justified paragraphs in TextBox and TextBlock
+func Example67() {
+	pdf := pdfjet.NewPDFFile("Example_67.pdf")
+	font := pdfjet.NewFontFromFile(pdf, "fonts/NotoSans/NotoSans-Regular.ttf")
+	font.SetSize(11.0)
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	textBox := pdfjet.NewTextBox(font)
+	textBox.SetText(paragraph)
+	textBox.SetLocation(50.0, 50.0)
+	textBox.SetWidth(220.0)
+	textBox.SetTextAlignment(align.Justify)
+	textBox.DrawOn(page)
+
+	textBlock := pdfjet.NewTextBlock(font, paragraph)
+	textBlock.SetLocation(320.0, 50.0)
+	textBlock.SetWidth(220.0)
+	textBlock.SetTextAlignment(alignment.Justify)
+	textBlock.DrawOn(page)
+	pdf.Complete()
+
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_67.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	buf, err := os.ReadFile("fonts/NotoSans/NotoSans-Regular.ttf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	lines := getLines(pdf.GetPageObjects(objects)[0], objects, getAdvances(buf), 11.0)
+	checkColumn(lines, 0.0, 300.0, "TextBox")
+	checkColumn(lines, 300.0, 600.0, "TextBlock")
+}
+
+// textLine is a line of text shown on the page.
+type textLine struct {
+	x, y float32
+	end  float32 // The right end of the last glyph that is not a space
+	text string
+}
+
+// checkColumn checks that all lines of the column except the last one end at the same position
+// and that the words of the lines are separated by spaces.
+func checkColumn(textLines []*textLine, left, right float32, name string) {
+	lines := make([]*textLine, 0)
+	for _, line := range textLines {
+		if line.x >= left && line.x < right {
+			lines = append(lines, line)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].y > lines[j].y })
+	if len(lines) < 3 {
+		log.Fatalf("Example_67: %s has %d lines", name, len(lines))
+	}
+
+	words := make([]string, 0)
+	var rightEdge float32
+	for i, line := range lines {
+		words = append(words, strings.Fields(line.text)...)
+		switch {
+		case i == 0:
+			rightEdge = line.end
+		case i < len(lines)-1:
+			if math.Abs(float64(line.end-rightEdge)) > 0.5 {
+				log.Fatalf("Example_67: line %d of %s ends at %.2f, not at %.2f", i+1, name, line.end, rightEdge)
+			}
+		default:
+			if line.end > rightEdge-5.0 {
+				log.Fatalf("Example_67: the last line of %s is justified", name)
+			}
+		}
+	}
+	if strings.Join(words, " ") != paragraph {
+		log.Fatalf("Example_67: the words of %s are %q", name, strings.Join(words, " "))
+	}
+}
+
+// getLines returns the lines of text on the page - one line for each text object.
+// The glyph IDs are mapped back to the text with the ToUnicode CMap of the font and
+// the end of each line is computed from the advances of the glyphs and the TJ adjustments.
+func getLines(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj, advances []int, fontSize float32) []*textLine {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	fonts := regexp.MustCompile(`/Font << /\w+ (\d+) 0 R`).FindStringSubmatch(resources)
+	dict := strings.Join(getObject(objects, fonts[1]).GetDict(), " ")
+	match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict)
+	cmap := getToUnicode(getObject(objects, match[1]).GetData())
+
+	lines := make([]*textLine, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		blocks := regexp.MustCompile(`(?s)BT\n.*? ([\d.]+) ([\d.]+) Tm\n\[([^\]]*)\] TJ`)
+		for _, block := range blocks.FindAllStringSubmatch(data, -1) {
+			x, _ := strconv.ParseFloat(block[1], 32)
+			y, _ := strconv.ParseFloat(block[2], 32)
+			line := &textLine{x: float32(x), y: float32(y), end: float32(x)}
+			position := float32(x)
+			var text strings.Builder
+			for _, item := range regexp.MustCompile(`<([0-9A-F]*)>|(-?[\d.]+)`).FindAllStringSubmatch(block[3], -1) {
+				if item[2] != "" {
+					adjustment, _ := strconv.ParseFloat(item[2], 32)
+					position -= float32(adjustment) * fontSize / 1000.0
+					continue
+				}
+				for i := 0; i+4 <= len(item[1]); i += 4 {
+					gid, _ := strconv.ParseUint(item[1][i:i+4], 16, 16)
+					position += float32(advances[gid]) * fontSize / 1000.0
+					if cmap[item[1][i:i+4]] != " " {
+						line.end = position
+					}
+					text.WriteString(cmap[item[1][i:i+4]])
+				}
+			}
+			line.text = text.String()
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// getAdvances returns the advance widths of the glyphs of the TrueType font in 1/1000 of the font size.
+func getAdvances(font []byte) []int {
+	unitsPerEm := int(binary.BigEndian.Uint16(getTable(font, "head")[18:]))
+	numberOfHMetrics := int(binary.BigEndian.Uint16(getTable(font, "hhea")[34:]))
+	numGlyphs := int(binary.BigEndian.Uint16(getTable(font, "maxp")[4:]))
+	hmtx := getTable(font, "hmtx")
+	advances := make([]int, numGlyphs)
+	for i := range advances {
+		j := i
+		if j >= numberOfHMetrics {
+			j = numberOfHMetrics - 1
+		}
+		advances[i] = int(binary.BigEndian.Uint16(hmtx[4*j:])) * 1000 / unitsPerEm
+	}
+	return advances
+}
+
+// getTable returns the table of the TrueType font.
+func getTable(font []byte, tag string) []byte {
+	numTables := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < numTables; i++ {
+		record := font[12+16*i:]
+		if string(record[:4]) == tag {
+			offset := binary.BigEndian.Uint32(record[8:])
+			return font[offset : offset+binary.BigEndian.Uint32(record[12:])]
+		}
+	}
+	return nil
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+func main() {
+	start := time.Now()
+	Example67()
+	pdfjet.PrintDuration("Example_67", time.Since(start))
+}
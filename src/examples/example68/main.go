@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example68 -- Text that mixes Latin, Greek, Hebrew and Japanese drawn with font chain
+func Example68() {
+	pdf := pdfjet.NewPDFFile("Example_68.pdf")
+	f1 := pdfjet.NewFontFromFile(pdf, "fonts/NotoSans/NotoSans-Regular.ttf")
+	f2 := pdfjet.NewFontFromFile(pdf, "fonts/NotoSansHebrew/NotoSansHebrew-Regular.ttf")
+	f3 := pdfjet.NewFontFromFile(pdf, "fonts/NotoSansJP/NotoSansJP-Regular.ttf.stream")
+	chain := pdfjet.NewFontChain(f1, f2, f3)
+	chain.SetSize(14.0)
+
+	// The core fonts use different encoding and cannot be chained with other fonts.
+	if _, err := pdfjet.NewFontChainErr(f1, pdfjet.NewCoreFont(pdf, corefont.Helvetica())); err == nil {
+		log.Fatal("Example_68: the core font is chained with other fonts")
+	}
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	line := "Policy Πολιτική פוליסה 保険証券"
+	textLine := pdfjet.NewTextLine(f1, line)
+	textLine.SetFontChain(chain)
+	textLine.SetLocation(50.0, 80.0)
+	textLine.DrawOn(page)
+
+	paragraph := "The insured person (המבוטח) receives the certificate 保険証券 in Greek: Πιστοποιητικό ασφάλισης."
+	textBox := pdfjet.NewTextBox(f1)
+	textBox.SetFontChain(chain)
+	textBox.SetText(paragraph)
+	textBox.SetLocation(50.0, 120.0)
+	textBox.SetWidth(300.0)
+	textBox.DrawOn(page)
+
+	pdf.Complete()
+
+	// Each character is drawn with the first font in the chain that has glyph for it.
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := pdf.ReadErr(content.OfBinaryFile("Example_68.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	lines := getLines(pdf.GetPageObjects(objects)[0], objects)
+	for _, textLine := range lines {
+		for _, run := range textLine {
+			for _, r := range run.text {
+				if unicode.IsSpace(r) {
+					continue // The spaces are drawn with the font of the text before them
+				}
+				expected := "NotoSans-Regular"
+				if unicode.Is(unicode.Hebrew, r) {
+					expected = "NotoSansHebrew-Regular"
+				} else if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+					expected = "NotoSansJP-Regular"
+				}
+				if run.font != expected {
+					log.Fatalf("Example_68: %q is drawn with %s", r, run.font)
+				}
+			}
+		}
+	}
+
+	// The Hebrew words are shown from right to left.
+	texts := make([]string, 0)
+	for _, textLine := range lines {
+		var sb strings.Builder
+		for _, run := range textLine {
+			sb.WriteString(run.text)
+		}
+		texts = append(texts, sb.String())
+	}
+	if texts[0] != strings.Replace(line, "פוליסה", "הסילופ", 1) ||
+		strings.Join(texts[1:], " ") != strings.Replace(paragraph, "המבוטח", "חטובמה", 1) {
+		log.Fatalf("Example_68: the text of the lines is %q", texts)
+	}
+}
+
+// textRun is text drawn with one font.
+type textRun struct {
+	font string
+	text string
+}
+
+// getLines returns the runs of text on the page. The runs with the same y are one line.
+// The glyph IDs are mapped back to the text with the ToUnicode CMaps of the fonts.
+func getLines(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) [][]*textRun {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	names := make(map[string]string)
+	cmaps := make(map[string]map[string]string)
+	fonts := regexp.MustCompile(`/Font << (.*?) >>`).FindStringSubmatch(resources)
+	for _, font := range regexp.MustCompile(`/(\w+) (\d+) 0 R`).FindAllStringSubmatch(fonts[1], -1) {
+		dict := strings.Join(getObject(objects, font[2]).GetDict(), " ")
+		// The subset fonts have six letter tag before the name.
+		if match := regexp.MustCompile(`/BaseFont /([A-Z]{6}\+)?(\S+)`).FindStringSubmatch(dict); match != nil {
+			names[font[1]] = match[2]
+		}
+		if match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict); match != nil {
+			cmaps[font[1]] = getToUnicode(getObject(objects, match[1]).GetData())
+		}
+	}
+	lines := make([][]*textRun, 0)
+	lastY := ""
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		name := ""
+		ops := regexp.MustCompile(`/(\w+) [\d.]+ Tf|[\d.]+ ([\d.]+) Tm|\[([^\]]*)\] TJ`)
+		for _, op := range ops.FindAllStringSubmatch(data, -1) {
+			if op[1] != "" {
+				name = op[1]
+				continue
+			}
+			if op[2] != "" {
+				if op[2] != lastY {
+					lines = append(lines, make([]*textRun, 0))
+					lastY = op[2]
+				}
+				continue
+			}
+			var text strings.Builder
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(op[3], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					text.WriteString(cmaps[name][str[1][i:i+4]])
+				}
+			}
+			lines[len(lines)-1] = append(lines[len(lines)-1], &textRun{font: names[name], text: text.String()})
+		}
+	}
+	return lines
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+func main() {
+	start := time.Now()
+	Example68()
+	pdfjet.PrintDuration("Example_68", time.Since(start))
+}
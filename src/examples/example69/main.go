@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example69 -- Draws text with instances of variable font at different weights
+func Example69() {
+	pdf := pdfjet.NewPDFFile("Example_69.pdf")
+
+	// The weight axis of the variable font made from IBM Plex Sans goes from 100 to 700.
+	// At the maximum weight the advance width of every glyph is 100 units larger.
+	varFont := makeVariableFont(content.OfBinaryFile("fonts/IBMPlexSans/IBMPlexSans-Regular.ttf"))
+
+	text := "Variable Font"
+	weights := []float32{400.0, 550.0, 700.0, 900.0}
+	fonts := make([]*pdfjet.Font, len(weights))
+	for i, weight := range weights {
+		variation := pdfjet.NewFontVariation().SetAxis("wght", weight)
+		fonts[i] = pdfjet.NewVariableFont(pdf, bytes.NewReader(varFont), variation)
+		fonts[i].SetSize(20.0)
+	}
+
+	// Every glyph gets wider by the weight axis delta scaled with the normalized coordinate.
+	delta := float32(len(text)) * 100.0 * 20.0 / 1000.0
+	regular := fonts[0].StringWidth(nil, text)
+	for i, scale := range []float32{0.0, 0.5, 1.0, 1.0} {
+		width := fonts[i].StringWidth(nil, text)
+		if math.Abs(float64(width-regular-scale*delta)) > 0.01 {
+			log.Fatalf("Example_69: the width at weight %v is %v, expected %v",
+				weights[i], width, regular+scale*delta)
+		}
+	}
+
+	_, err := pdfjet.NewVariableFontErr(pdf, bytes.NewReader(varFont),
+		pdfjet.NewFontVariation().SetAxis("wdth", 75.0))
+	var unsupported *pdfjet.UnsupportedFeatureError
+	if !errors.As(err, &unsupported) {
+		log.Fatalf("Example_69: expected unsupported variation axis error, got %v", err)
+	}
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	y := float32(80.0)
+	for i, font := range fonts {
+		textLine := pdfjet.NewTextLine(font, text)
+		textLine.SetLocation(50.0, y)
+		textLine.DrawOn(page)
+
+		// The underline shows the advance width of the text.
+		line := pdfjet.NewLine(50.0, y+5.0, 50.0+font.StringWidth(nil, text), y+5.0)
+		line.DrawOn(page)
+
+		label := pdfjet.NewTextLine(fonts[0], fmt.Sprintf("wght %v", weights[i]))
+		label.SetLocation(350.0, y)
+		label.DrawOn(page)
+		y += 40.0
+	}
+	pdf.Complete()
+
+	// All the instances map their glyphs back to the text.
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := reader.ReadErr(content.OfBinaryFile("Example_69.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	extracted := getPageText(reader.GetPageObjects(objects)[0], objects)
+	if strings.Count(extracted, text) != len(weights) {
+		log.Fatalf("Example_69: the extracted text is %q", extracted)
+	}
+}
+
+// makeVariableFont adds the fvar and gvar tables with weight axis to static TrueType font.
+func makeVariableFont(buf []byte) []byte {
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(buf[4:]))
+	for i := 0; i < numTables; i++ {
+		record := buf[12+16*i:]
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		tables[string(record[:4])] = buf[offset : offset+length]
+	}
+
+	// The fvar table with the weight axis and no named instances.
+	var fvar []byte
+	fvar = binary.BigEndian.AppendUint16(fvar, 1)  // majorVersion
+	fvar = binary.BigEndian.AppendUint16(fvar, 0)  // minorVersion
+	fvar = binary.BigEndian.AppendUint16(fvar, 16) // axesArrayOffset
+	fvar = binary.BigEndian.AppendUint16(fvar, 2)  // reserved
+	fvar = binary.BigEndian.AppendUint16(fvar, 1)  // axisCount
+	fvar = binary.BigEndian.AppendUint16(fvar, 20) // axisSize
+	fvar = binary.BigEndian.AppendUint16(fvar, 0)  // instanceCount
+	fvar = binary.BigEndian.AppendUint16(fvar, 8)  // instanceSize
+	fvar = append(fvar, "wght"...)
+	fvar = binary.BigEndian.AppendUint32(fvar, 100<<16)
+	fvar = binary.BigEndian.AppendUint32(fvar, 400<<16)
+	fvar = binary.BigEndian.AppendUint32(fvar, 700<<16)
+	fvar = binary.BigEndian.AppendUint16(fvar, 0) // flags
+	fvar = binary.BigEndian.AppendUint16(fvar, 2) // axisNameID
+	tables["fvar"] = fvar
+
+	// The gvar table with one tuple for the simple and the empty glyphs.
+	// The tuple moves the advance phantom point of the glyph to the right.
+	head, maxp, loca, glyf := tables["head"], tables["maxp"], tables["loca"], tables["glyf"]
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	glyphOffset := func(gid int) int {
+		if binary.BigEndian.Uint16(head[50:]) == 1 {
+			return int(binary.BigEndian.Uint32(loca[4*gid:]))
+		}
+		return 2 * int(binary.BigEndian.Uint16(loca[2*gid:]))
+	}
+	var data []byte
+	offsets := make([]uint32, numGlyphs+1)
+	for gid := 0; gid < numGlyphs; gid++ {
+		offsets[gid] = uint32(len(data))
+		glyph := glyf[glyphOffset(gid):glyphOffset(gid+1)]
+		numPoints := 0
+		if len(glyph) > 0 {
+			numberOfContours := int(int16(binary.BigEndian.Uint16(glyph)))
+			if numberOfContours < 0 {
+				continue // Composite glyph
+			}
+			numPoints = int(binary.BigEndian.Uint16(glyph[10+2*(numberOfContours-1):])) + 1
+		}
+		xs := make([]int16, numPoints+4)
+		xs[numPoints+1] = 100
+		var deltas []byte
+		deltas = append(deltas, 0) // All points
+		deltas = appendPackedDeltas(deltas, xs)
+		deltas = appendPackedDeltas(deltas, make([]int16, numPoints+4))
+
+		data = binary.BigEndian.AppendUint16(data, 0x8000|1) // Shared point numbers and one tuple
+		data = binary.BigEndian.AppendUint16(data, 10)       // dataOffset
+		data = binary.BigEndian.AppendUint16(data, uint16(len(deltas)-1))
+		data = binary.BigEndian.AppendUint16(data, 0x8000) // Embedded peak tuple
+		data = binary.BigEndian.AppendUint16(data, 0x4000) // The peak at 1.0
+		data = append(data, deltas...)
+		for len(data)%2 != 0 {
+			data = append(data, 0)
+		}
+	}
+	offsets[numGlyphs] = uint32(len(data))
+	var gvar []byte
+	gvar = binary.BigEndian.AppendUint16(gvar, 1) // majorVersion
+	gvar = binary.BigEndian.AppendUint16(gvar, 0) // minorVersion
+	gvar = binary.BigEndian.AppendUint16(gvar, 1) // axisCount
+	gvar = binary.BigEndian.AppendUint16(gvar, 0) // sharedTupleCount
+	gvar = binary.BigEndian.AppendUint32(gvar, uint32(20+4*len(offsets)))
+	gvar = binary.BigEndian.AppendUint16(gvar, uint16(numGlyphs))
+	gvar = binary.BigEndian.AppendUint16(gvar, 1) // Long offsets
+	gvar = binary.BigEndian.AppendUint32(gvar, uint32(20+4*len(offsets)))
+	for _, offset := range offsets {
+		gvar = binary.BigEndian.AppendUint32(gvar, offset)
+	}
+	tables["gvar"] = append(gvar, data...)
+
+	return writeFont(tables)
+}
+
+// appendPackedDeltas appends the deltas as runs of zeros and runs of words.
+func appendPackedDeltas(buf []byte, deltas []int16) []byte {
+	for i := 0; i < len(deltas); {
+		if deltas[i] != 0 {
+			buf = append(buf, 0x40)
+			buf = binary.BigEndian.AppendUint16(buf, uint16(deltas[i]))
+			i++
+			continue
+		}
+		n := 0
+		for i+n < len(deltas) && deltas[i+n] == 0 && n < 64 {
+			n++
+		}
+		buf = append(buf, byte(0x80|(n-1)))
+		i += n
+	}
+	return buf
+}
+
+// writeFont writes the tables as TrueType font.
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= len(tags) {
+		entrySelector++
+	}
+	var buf []byte
+	buf = binary.BigEndian.AppendUint32(buf, 0x00010000)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(tags)))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(16<<entrySelector))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(entrySelector))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(16*len(tags)-16<<entrySelector))
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		table := tables[tag]
+		checksum := uint32(0)
+		padded := append(append([]byte(nil), table...), make([]byte, 3)...)
+		for i := 0; i < len(table); i += 4 {
+			checksum += binary.BigEndian.Uint32(padded[i:])
+		}
+		buf = append(buf, tag...)
+		buf = binary.BigEndian.AppendUint32(buf, checksum)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(offset))
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(table)))
+		offset += (len(table) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		buf = append(buf, tables[tag]...)
+		for len(buf)%4 != 0 {
+			buf = append(buf, 0)
+		}
+	}
+	return buf
+}
+
+// getPageText returns the text on the page - one line for each TJ operator.
+// The glyph IDs are mapped back to the text with the ToUnicode CMaps of the fonts.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	cmaps := make(map[string]map[string]string)
+	fonts := regexp.MustCompile(`/Font << (.*?) >>`).FindStringSubmatch(resources)
+	for _, font := range regexp.MustCompile(`/(\w+) (\d+) 0 R`).FindAllStringSubmatch(fonts[1], -1) {
+		dict := strings.Join(getObject(objects, font[2]).GetDict(), " ")
+		if match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict); match != nil {
+			cmaps[font[1]] = getToUnicode(getObject(objects, match[1]).GetData())
+		}
+	}
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		var cmap map[string]string
+		for _, op := range regexp.MustCompile(`/(\w+) [\d.]+ Tf|\[([^\]]*)\] TJ`).FindAllStringSubmatch(data, -1) {
+			if op[1] != "" {
+				cmap = cmaps[op[1]]
+				continue
+			}
+			var line strings.Builder
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(op[2], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					line.WriteString(cmap[str[1][i:i+4]])
+				}
+			}
+			lines = append(lines, line.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+func main() {
+	start := time.Now()
+	Example69()
+	pdfjet.PrintDuration("Example_69", time.Since(start))
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example70 -- Loads the faces of TrueType font collection
+func Example70() {
+	// The collection made from the regular and the bold IBM Plex Sans.
+	// The tables that are the same in both fonts are stored once.
+	collection := makeCollection(
+		content.OfBinaryFile("fonts/IBMPlexSans/IBMPlexSans-Regular.ttf"),
+		content.OfBinaryFile("fonts/IBMPlexSans/IBMPlexSans-Bold.ttf"))
+
+	names, err := pdfjet.GetCollectionFaceNames(bytes.NewReader(collection))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if strings.Join(names, ", ") != "IBM Plex Sans Regular, IBM Plex Sans Bold" {
+		log.Fatalf("Example_70: the face names are %q", names)
+	}
+
+	pdf := pdfjet.NewPDFFile("Example_70.pdf")
+	if _, err := pdfjet.NewFontFromCollectionErr(pdf, bytes.NewReader(collection), 2); err == nil {
+		log.Fatal("Example_70: expected face index out of range error")
+	}
+
+	text := "Font Collection"
+	fonts := make([]*pdfjet.Font, len(names))
+	for i := range names {
+		fonts[i] = pdfjet.NewFontFromCollection(pdf, bytes.NewReader(collection), i)
+		fonts[i].SetSize(20.0)
+	}
+
+	// The face measures the text like the standalone font.
+	bold := pdfjet.NewFontFromFile(pdf, "fonts/IBMPlexSans/IBMPlexSans-Bold.ttf")
+	bold.SetSize(20.0)
+	if fonts[1].StringWidth(nil, text) != bold.StringWidth(nil, text) {
+		log.Fatalf("Example_70: the width of the bold face is %v, expected %v",
+			fonts[1].StringWidth(nil, text), bold.StringWidth(nil, text))
+	}
+	if fonts[0].StringWidth(nil, text) == fonts[1].StringWidth(nil, text) {
+		log.Fatal("Example_70: the regular and the bold face have the same width")
+	}
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	y := float32(80.0)
+	for i, font := range fonts {
+		textLine := pdfjet.NewTextLine(font, text+" - "+names[i])
+		textLine.SetLocation(50.0, y)
+		textLine.DrawOn(page)
+		y += 40.0
+	}
+	pdf.Complete()
+
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := reader.ReadErr(content.OfBinaryFile("Example_70.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	extracted := getPageText(reader.GetPageObjects(objects)[0], objects)
+	for _, name := range names {
+		if !strings.Contains(extracted, text+" - "+name) {
+			log.Fatalf("Example_70: the extracted text is %q", extracted)
+		}
+	}
+}
+
+// makeCollection writes the fonts as TrueType collection.
+func makeCollection(fonts ...[]byte) []byte {
+	directories := 12 + 4*len(fonts)
+	for _, font := range fonts {
+		directories += 12 + 16*int(binary.BigEndian.Uint16(font[4:]))
+	}
+
+	var header, data []byte
+	header = append(header, "ttcf"...)
+	header = binary.BigEndian.AppendUint32(header, 0x00010000)
+	header = binary.BigEndian.AppendUint32(header, uint32(len(fonts)))
+	var tableDirectories []byte
+	offsets := make(map[string]int)
+	for _, font := range fonts {
+		header = binary.BigEndian.AppendUint32(header, uint32(12+4*len(fonts)+len(tableDirectories)))
+		numTables := int(binary.BigEndian.Uint16(font[4:]))
+		tableDirectories = append(tableDirectories, font[:12]...)
+		for i := 0; i < numTables; i++ {
+			record := font[12+16*i:]
+			offset := binary.BigEndian.Uint32(record[8:])
+			length := binary.BigEndian.Uint32(record[12:])
+			table := string(font[offset : offset+length])
+			if _, ok := offsets[table]; !ok {
+				offsets[table] = directories + len(data)
+				data = append(data, table...)
+				for len(data)%4 != 0 {
+					data = append(data, 0)
+				}
+			}
+			tableDirectories = append(tableDirectories, record[:8]...)
+			tableDirectories = binary.BigEndian.AppendUint32(tableDirectories, uint32(offsets[table]))
+			tableDirectories = binary.BigEndian.AppendUint32(tableDirectories, length)
+		}
+	}
+	return append(append(header, tableDirectories...), data...)
+}
+
+// getPageText returns the text on the page - one line for each TJ operator.
+// The glyph IDs are mapped back to the text with the ToUnicode CMaps of the fonts.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	cmaps := make(map[string]map[string]string)
+	fonts := regexp.MustCompile(`/Font << (.*?) >>`).FindStringSubmatch(resources)
+	for _, font := range regexp.MustCompile(`/(\w+) (\d+) 0 R`).FindAllStringSubmatch(fonts[1], -1) {
+		dict := strings.Join(getObject(objects, font[2]).GetDict(), " ")
+		if match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict); match != nil {
+			cmaps[font[1]] = getToUnicode(getObject(objects, match[1]).GetData())
+		}
+	}
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		var cmap map[string]string
+		for _, op := range regexp.MustCompile(`/(\w+) [\d.]+ Tf|\[([^\]]*)\] TJ`).FindAllStringSubmatch(data, -1) {
+			if op[1] != "" {
+				cmap = cmaps[op[1]]
+				continue
+			}
+			var line strings.Builder
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(op[2], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					line.WriteString(cmap[str[1][i:i+4]])
+				}
+			}
+			lines = append(lines, line.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+func main() {
+	start := time.Now()
+	Example70()
+	pdfjet.PrintDuration("Example_70", time.Since(start))
+}
@@ -0,0 +1,905 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example71 -- Embeds WOFF and WOFF2 web fonts
+func Example71() {
+	ttf := content.OfBinaryFile("fonts/IBMPlexSans/IBMPlexSans-Regular.ttf")
+	woff := makeWOFF(ttf)
+	woff2 := makeWOFF2(ttf)
+	if len(woff) >= len(ttf) || len(woff2) >= len(woff) {
+		log.Fatalf("Example_71: the WOFF2 font has %d bytes, the WOFF font %d bytes and the TrueType font %d bytes",
+			len(woff2), len(woff), len(ttf))
+	}
+
+	// The web fonts decode to the same tables as the TrueType font.
+	for i, buf := range [][]byte{woff, woff2} {
+		label := []string{"WOFF", "WOFF2"}[i]
+		decoded := getEmbeddedFont(buf)
+		tags, tables := getTables(ttf)
+		decodedTags, decodedTables := getTables(decoded)
+		if len(decodedTags) != len(tags) {
+			log.Fatalf("Example_71: the %s font has the tables %v", label, decodedTags)
+		}
+		for j, tag := range tags {
+			k := 0
+			for k < len(decodedTags) && decodedTags[k] != tag {
+				k++
+			}
+			if k == len(decodedTags) {
+				log.Fatalf("Example_71: the %s font has no %s table", label, tag)
+			}
+			table, decodedTable := tables[j], decodedTables[k]
+			switch tag {
+			case "glyf":
+				// The glyphs are encoded again when the glyf table is rebuilt from the points.
+				if !reflect.DeepEqual(getGlyphs(decoded), getGlyphs(ttf)) {
+					log.Fatalf("Example_71: the %s font has different glyphs", label)
+				}
+				continue
+			case "loca":
+				continue
+			case "head":
+				// The checksum of the font is different.
+				table = append(append([]byte{}, table[:8]...), table[12:]...)
+				decodedTable = append(append([]byte{}, decodedTable[:8]...), decodedTable[12:]...)
+			}
+			if !bytes.Equal(table, decodedTable) {
+				log.Fatalf("Example_71: the %s table of the %s font is different", tag, label)
+			}
+		}
+	}
+
+	pdf := pdfjet.NewPDFFile("Example_71.pdf")
+	_, err := pdfjet.NewFontErr(pdf, bytes.NewReader(woff[:len(woff)/2]))
+	var malformed *pdfjet.MalformedInputError
+	if !errors.As(err, &malformed) {
+		log.Fatalf("Example_71: expected malformed input error for truncated WOFF font, got %v", err)
+	}
+
+	labels := []string{"TrueType", "WOFF", "WOFF2"}
+	fonts := make([]*pdfjet.Font, len(labels))
+	for i, buf := range [][]byte{ttf, woff, woff2} {
+		fonts[i] = pdfjet.NewFont(pdf, bytes.NewReader(buf))
+		fonts[i].SetSize(16.0)
+	}
+
+	// The web fonts have the same metrics as the TrueType font.
+	text := "The quick brown fox jumps over the lazy dog"
+	for i, font := range fonts {
+		if font.StringWidth(nil, text) != fonts[0].StringWidth(nil, text) {
+			log.Fatalf("Example_71: the %s font width is %v, expected %v",
+				labels[i], font.StringWidth(nil, text), fonts[0].StringWidth(nil, text))
+		}
+	}
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	y := float32(80.0)
+	for i, font := range fonts {
+		textLine := pdfjet.NewTextLine(font, labels[i]+": "+text)
+		textLine.SetLocation(50.0, y)
+		textLine.DrawOn(page)
+		y += 30.0
+	}
+	pdf.Complete()
+
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := reader.ReadErr(content.OfBinaryFile("Example_71.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	extracted := getPageText(reader.GetPageObjects(objects)[0], objects)
+	for _, label := range labels {
+		if !strings.Contains(extracted, label+": "+text) {
+			log.Fatalf("Example_71: the extracted text is %q", extracted)
+		}
+	}
+}
+
+// getEmbeddedFont returns the font embedded in PDF - the web fonts are decoded to TrueType.
+func getEmbeddedFont(buf []byte) []byte {
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+	pdf := pdfjet.NewPDF(w)
+	pdf.SetFontSubsetting(false)
+	font := pdfjet.NewFont(pdf, bytes.NewReader(buf))
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	textLine := pdfjet.NewTextLine(font, "Embedded font")
+	textLine.SetLocation(50.0, 80.0)
+	textLine.DrawOn(page)
+	pdf.Complete()
+	w.Flush()
+
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	for _, obj := range reader.Read(out.Bytes()) {
+		if strings.Contains(strings.Join(obj.GetDict(), " "), "/Length1") {
+			return obj.GetData()
+		}
+	}
+	log.Fatal("Example_71: the font is not embedded")
+	return nil
+}
+
+// getTables returns the tags and the data of the font tables in the order of the table directory.
+func getTables(buf []byte) ([]string, [][]byte) {
+	numTables := int(binary.BigEndian.Uint16(buf[4:]))
+	tags := make([]string, numTables)
+	tables := make([][]byte, numTables)
+	for i := range tags {
+		record := buf[12+16*i:]
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		tags[i] = string(record[:4])
+		tables[i] = buf[offset : offset+length]
+	}
+	return tags, tables
+}
+
+// makeWOFF writes the font as WOFF 1.0 font with the tables compressed with zlib.
+func makeWOFF(ttf []byte) []byte {
+	tags, tables := getTables(ttf)
+	var directory, data []byte
+	offset := 44 + 20*len(tags)
+	for i, table := range tables {
+		var compressed bytes.Buffer
+		w := zlib.NewWriter(&compressed)
+		w.Write(table)
+		w.Close()
+		stored := table
+		if compressed.Len() < len(table) {
+			stored = compressed.Bytes()
+		}
+		directory = append(directory, tags[i]...)
+		directory = binary.BigEndian.AppendUint32(directory, uint32(offset+len(data)))
+		directory = binary.BigEndian.AppendUint32(directory, uint32(len(stored)))
+		directory = binary.BigEndian.AppendUint32(directory, uint32(len(table)))
+		directory = append(directory, ttf[12+16*i+4:12+16*i+8]...) // origChecksum
+		data = append(data, stored...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+	var header []byte
+	header = append(header, "wOFF"...)
+	header = append(header, ttf[:4]...) // flavor
+	header = binary.BigEndian.AppendUint32(header, uint32(offset+len(data)))
+	header = binary.BigEndian.AppendUint16(header, uint16(len(tags)))
+	header = binary.BigEndian.AppendUint16(header, 0) // reserved
+	header = binary.BigEndian.AppendUint32(header, uint32(len(ttf)))
+	header = binary.BigEndian.AppendUint16(header, 1) // majorVersion
+	header = append(header, make([]byte, 22)...)      // minorVersion, no metadata and private data
+	return append(append(header, directory...), data...)
+}
+
+// makeWOFF2 writes the font as WOFF2 font. The glyf and loca tables are transformed
+// and all the tables are compressed together in one Brotli stream.
+func makeWOFF2(ttf []byte) []byte {
+	tags, tables := getTables(ttf)
+	// The loca table must follow the glyf table.
+	order := make([]int, 0, len(tags))
+	for i, tag := range tags {
+		if tag == "loca" {
+			continue
+		}
+		order = append(order, i)
+		if tag == "glyf" {
+			for j := range tags {
+				if tags[j] == "loca" {
+					order = append(order, j)
+				}
+			}
+		}
+	}
+	var directory, data []byte
+	for _, i := range order {
+		tag := tags[i]
+		flags := byte(0x3F)
+		for j, known := range woff2Tags {
+			if tag == known {
+				flags = byte(j)
+			}
+		}
+		directory = append(directory, flags) // The transform version 0
+		if flags == 0x3F {
+			directory = append(directory, tag...)
+		}
+		directory = appendUIntBase128(directory, len(tables[i]))
+		switch tag {
+		case "glyf":
+			glyf := transformGlyf(ttf)
+			directory = appendUIntBase128(directory, len(glyf))
+			data = append(data, glyf...)
+		case "loca":
+			directory = appendUIntBase128(directory, 0) // Rebuilt from the glyf table
+		default:
+			data = append(data, tables[i]...)
+		}
+	}
+	compressed := brotliCompress(data)
+	var header []byte
+	header = append(header, "wOF2"...)
+	header = append(header, ttf[:4]...) // flavor
+	header = binary.BigEndian.AppendUint32(header, uint32(48+len(directory)+len(compressed)))
+	header = binary.BigEndian.AppendUint16(header, uint16(len(tags)))
+	header = binary.BigEndian.AppendUint16(header, 0) // reserved
+	header = binary.BigEndian.AppendUint32(header, uint32(len(ttf)))
+	header = binary.BigEndian.AppendUint32(header, uint32(len(compressed)))
+	header = binary.BigEndian.AppendUint16(header, 1) // majorVersion
+	header = append(header, make([]byte, 22)...)      // minorVersion, no metadata and private data
+	return append(append(header, directory...), compressed...)
+}
+
+// The known table tags of WOFF2 table directory.
+var woff2Tags = []string{
+	"cmap", "head", "hhea", "hmtx", "maxp", "name", "OS/2", "post",
+	"cvt ", "fpgm", "glyf", "loca", "prep", "CFF ", "VORG", "EBDT",
+	"EBLC", "gasp", "hdmx", "kern", "LTSH", "PCLT", "VDMX", "vhea",
+	"vmtx", "BASE", "GDEF", "GPOS", "GSUB", "EBSC", "JSTF", "MATH",
+	"CBDT", "CBLC", "COLR", "CPAL", "SVG ", "sbix", "acnt", "avar",
+	"bdat", "bloc", "bsln", "cvar", "fdsc", "feat", "fmtx", "fvar",
+	"gvar", "hsty", "just", "lcar", "mort", "morx", "opbd", "prop",
+	"trak", "Zapf", "Silf", "Glat", "Gloc", "Feat", "Sill",
+}
+
+// glyph is TrueType glyph with the point coordinates decoded.
+type glyph struct {
+	numberOfContours int
+	bbox             []byte // xMin, yMin, xMax, yMax
+	endPts           []int
+	onCurve          []bool
+	xs, ys           []int
+	components       []byte // The component records of composite glyph
+	instructions     []byte
+}
+
+// getGlyphs returns the glyphs of the TrueType font.
+func getGlyphs(ttf []byte) []*glyph {
+	tags, tables := getTables(ttf)
+	font := make(map[string][]byte)
+	for i, tag := range tags {
+		font[tag] = tables[i]
+	}
+	head, loca, glyf := font["head"], font["loca"], font["glyf"]
+	numGlyphs := int(binary.BigEndian.Uint16(font["maxp"][4:]))
+	offset := func(gid int) int {
+		if binary.BigEndian.Uint16(head[50:]) == 1 {
+			return int(binary.BigEndian.Uint32(loca[4*gid:]))
+		}
+		return 2 * int(binary.BigEndian.Uint16(loca[2*gid:]))
+	}
+	glyphs := make([]*glyph, numGlyphs)
+	for gid := range glyphs {
+		glyphs[gid] = parseGlyph(glyf[offset(gid):offset(gid+1)])
+	}
+	return glyphs
+}
+
+// parseGlyph decodes the contours and the coordinates or the components of the glyph.
+func parseGlyph(buf []byte) *glyph {
+	g := &glyph{}
+	if len(buf) == 0 {
+		return g
+	}
+	g.numberOfContours = int(int16(binary.BigEndian.Uint16(buf)))
+	g.bbox = buf[2:10]
+	pos := 10
+	if g.numberOfContours < 0 {
+		haveInstructions := false
+		for {
+			flags := binary.BigEndian.Uint16(buf[pos:])
+			start := pos
+			pos += 4
+			if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+				pos += 4
+			} else {
+				pos += 2
+			}
+			if flags&0x0008 != 0 { // WE_HAVE_A_SCALE
+				pos += 2
+			} else if flags&0x0040 != 0 { // WE_HAVE_AN_X_AND_Y_SCALE
+				pos += 4
+			} else if flags&0x0080 != 0 { // WE_HAVE_A_TWO_BY_TWO
+				pos += 8
+			}
+			g.components = append(g.components, buf[start:pos]...)
+			if flags&0x0100 != 0 { // WE_HAVE_INSTRUCTIONS
+				haveInstructions = true
+			}
+			if flags&0x0020 == 0 { // MORE_COMPONENTS
+				break
+			}
+		}
+		if haveInstructions {
+			n := int(binary.BigEndian.Uint16(buf[pos:]))
+			g.instructions = buf[pos+2 : pos+2+n]
+		}
+		return g
+	}
+	g.endPts = make([]int, g.numberOfContours)
+	for i := range g.endPts {
+		g.endPts[i] = int(binary.BigEndian.Uint16(buf[pos:]))
+		pos += 2
+	}
+	n := int(binary.BigEndian.Uint16(buf[pos:]))
+	g.instructions = buf[pos+2 : pos+2+n]
+	pos += 2 + n
+	numPoints := g.endPts[len(g.endPts)-1] + 1
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		flag := buf[pos]
+		pos++
+		flags = append(flags, flag)
+		if flag&0x08 != 0 { // REPEAT_FLAG
+			for i := 0; i < int(buf[pos]); i++ {
+				flags = append(flags, flag)
+			}
+			pos++
+		}
+	}
+	g.onCurve = make([]bool, numPoints)
+	g.xs = make([]int, numPoints)
+	g.ys = make([]int, numPoints)
+	for i, flag := range flags {
+		g.onCurve[i] = flag&0x01 != 0
+	}
+	// The short vectors have the sign in the same or positive bit, the long ones are not repeated.
+	readCoordinates := func(coordinates []int, short, same byte) {
+		value := 0
+		for i, flag := range flags {
+			if flag&short != 0 {
+				if flag&same != 0 {
+					value += int(buf[pos])
+				} else {
+					value -= int(buf[pos])
+				}
+				pos++
+			} else if flag&same == 0 {
+				value += int(int16(binary.BigEndian.Uint16(buf[pos:])))
+				pos += 2
+			}
+			coordinates[i] = value
+		}
+	}
+	readCoordinates(g.xs, 0x02, 0x10)
+	readCoordinates(g.ys, 0x04, 0x20)
+	return g
+}
+
+// transformGlyf returns the transformed glyf table of the font. The points are stored
+// as triplets and the bounding boxes that can be computed from the points are omitted.
+func transformGlyf(ttf []byte) []byte {
+	glyphs := getGlyphs(ttf)
+	var nContours, nPoints, flagStream, glyphStream, compositeStream, bboxValues, instructionStream []byte
+	bboxBitmap := make([]byte, 4*((len(glyphs)+31)/32))
+	for gid, g := range glyphs {
+		nContours = binary.BigEndian.AppendUint16(nContours, uint16(g.numberOfContours))
+		if g.numberOfContours == 0 {
+			continue
+		}
+		explicitBBox := true
+		if g.numberOfContours < 0 {
+			compositeStream = append(compositeStream, g.components...)
+			if g.instructions != nil {
+				glyphStream = append255UInt16(glyphStream, len(g.instructions))
+				instructionStream = append(instructionStream, g.instructions...)
+			}
+		} else {
+			previous := -1
+			for _, endPt := range g.endPts {
+				nPoints = append255UInt16(nPoints, endPt-previous)
+				previous = endPt
+			}
+			x, y := 0, 0
+			xMin, yMin, xMax, yMax := g.xs[0], g.ys[0], g.xs[0], g.ys[0]
+			for i := range g.xs {
+				var flag byte
+				flag, glyphStream = appendTriplet(glyphStream, g.xs[i]-x, g.ys[i]-y)
+				if !g.onCurve[i] {
+					flag |= 0x80
+				}
+				flagStream = append(flagStream, flag)
+				x, y = g.xs[i], g.ys[i]
+				xMin, yMin = minInt(xMin, x), minInt(yMin, y)
+				xMax, yMax = maxInt(xMax, x), maxInt(yMax, y)
+			}
+			glyphStream = append255UInt16(glyphStream, len(g.instructions))
+			instructionStream = append(instructionStream, g.instructions...)
+			var bbox []byte
+			for _, value := range []int{xMin, yMin, xMax, yMax} {
+				bbox = binary.BigEndian.AppendUint16(bbox, uint16(value))
+			}
+			explicitBBox = !bytes.Equal(bbox, g.bbox)
+		}
+		if explicitBBox {
+			bboxBitmap[gid>>3] |= 0x80 >> uint(gid&7)
+			bboxValues = append(bboxValues, g.bbox...)
+		}
+	}
+	tags, tables := getTables(ttf)
+	var head []byte
+	for i, tag := range tags {
+		if tag == "head" {
+			head = tables[i]
+		}
+	}
+	streams := [][]byte{nContours, nPoints, flagStream, glyphStream, compositeStream,
+		append(bboxBitmap, bboxValues...), instructionStream}
+	var glyf []byte
+	glyf = binary.BigEndian.AppendUint16(glyf, 0) // reserved
+	glyf = binary.BigEndian.AppendUint16(glyf, 0) // optionFlags
+	glyf = binary.BigEndian.AppendUint16(glyf, uint16(len(glyphs)))
+	glyf = append(glyf, head[50:52]...) // indexFormat
+	for _, stream := range streams {
+		glyf = binary.BigEndian.AppendUint32(glyf, uint32(len(stream)))
+	}
+	for _, stream := range streams {
+		glyf = append(glyf, stream...)
+	}
+	return glyf
+}
+
+// appendTriplet appends the point coordinate deltas in the shortest form and returns its flag.
+func appendTriplet(buf []byte, dx, dy int) (byte, []byte) {
+	x, y := absInt(dx), absInt(dy)
+	var xSign, ySign int
+	if dx >= 0 {
+		xSign = 1
+	}
+	if dy >= 0 {
+		ySign = 1
+	}
+	signs := xSign + 2*ySign
+	switch {
+	case dx == 0 && y < 1280:
+		return byte((y&0xF00)>>7 + ySign), append(buf, byte(y))
+	case dy == 0 && x < 1280:
+		return byte(10 + (x&0xF00)>>7 + xSign), append(buf, byte(x))
+	case x < 65 && y < 65:
+		return byte(20 + (x-1)&0x30 + ((y-1)&0x30)>>2 + signs), append(buf, byte((x-1)&0x0F<<4|(y-1)&0x0F))
+	case x < 769 && y < 769:
+		return byte(84 + 12*(((x-1)&0x300)>>8) + ((y-1)&0x300)>>6 + signs), append(buf, byte(x-1), byte(y-1))
+	case x < 4096 && y < 4096:
+		return byte(120 + signs), append(buf, byte(x>>4), byte(x&0x0F<<4|y>>8), byte(y))
+	}
+	return byte(124 + signs), append(buf, byte(x>>8), byte(x), byte(y>>8), byte(y))
+}
+
+// append255UInt16 appends the variable length integer from 0 to 65535.
+func append255UInt16(buf []byte, value int) []byte {
+	switch {
+	case value < 253:
+		return append(buf, byte(value))
+	case value < 506:
+		return append(buf, 255, byte(value-253))
+	case value < 762:
+		return append(buf, 254, byte(value-506))
+	}
+	return append(buf, 253, byte(value>>8), byte(value))
+}
+
+// appendUIntBase128 appends the variable length integer with 7 bits in each byte.
+func appendUIntBase128(buf []byte, value int) []byte {
+	n := 1
+	for value>>(7*n) != 0 {
+		n++
+	}
+	for i := n - 1; i >= 0; i-- {
+		b := byte(value>>(7*i)) & 0x7F
+		if i > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+	}
+	return buf
+}
+
+// The insert and copy length codes - the first length and the number of extra bits.
+var insertLengthBase = []int{
+	0, 1, 2, 3, 4, 5, 6, 8, 10, 14, 18, 26, 34, 50, 66, 98,
+	130, 194, 322, 578, 1090, 2114, 6210, 22594,
+}
+var insertLengthExtra = []uint{0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 7, 8, 9, 10, 12, 14, 24}
+var copyLengthBase = []int{
+	2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 14, 18, 22, 30, 38, 54,
+	70, 102, 134, 198, 326, 582, 1094, 2118,
+}
+var copyLengthExtra = []uint{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 7, 8, 9, 10, 24}
+
+// The first command code of the insert and copy length code ranges that read the distance.
+var commandCells = [3][3]int{{128, 192, 384}, {256, 320, 512}, {448, 576, 640}}
+
+// brotliCommand is the insert and copy command with the literals and the backward reference.
+type brotliCommand struct {
+	start, insertLength int // The literals
+	copyLength          int // Zero for the last command without the backward reference
+	distance            int
+}
+
+// brotliCompress returns the Brotli stream with the data in one compressed meta-block.
+// The repeated strings are replaced with backward references found with hash chains.
+// The literals, the commands and the distances are coded with their own prefix codes.
+func brotliCompress(data []byte) []byte {
+	const windowBits = 22
+	const maxDistance = 1<<windowBits - 16
+	commands := make([]brotliCommand, 0)
+	head := make([]int, 1<<16)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int, len(data))
+	hash := func(i int) int {
+		return int(binary.LittleEndian.Uint32(data[i:]) * 0x1E35A7BD >> 16)
+	}
+	insert := func(i int) {
+		if i+4 <= len(data) {
+			h := hash(i)
+			prev[i] = head[h]
+			head[h] = i
+		}
+	}
+	start := 0
+	for i := 0; i+4 <= len(data); {
+		bestLength, bestDistance := 0, 0
+		for j, chain := head[hash(i)], 0; j >= 0 && i-j <= maxDistance && chain < 64; j, chain = prev[j], chain+1 {
+			length := 0
+			for i+length < len(data) && length < 1<<16 && data[j+length] == data[i+length] {
+				length++
+			}
+			if length > bestLength {
+				bestLength, bestDistance = length, i-j
+			}
+		}
+		if bestLength < 4 {
+			insert(i)
+			i++
+			continue
+		}
+		commands = append(commands, brotliCommand{start, i - start, bestLength, bestDistance})
+		for j := i; j < i+bestLength; j++ {
+			insert(j)
+		}
+		i += bestLength
+		start = i
+	}
+	if start < len(data) {
+		commands = append(commands, brotliCommand{start, len(data) - start, 0, 0})
+	}
+
+	// The symbols and the extra bits of the commands.
+	getCode := func(base []int, length int) int {
+		code := len(base) - 1
+		for base[code] > length {
+			code--
+		}
+		return code
+	}
+	literalCounts := make([]int, 256)
+	commandCounts := make([]int, 704)
+	distanceCounts := make([]int, 64)
+	symbols := make([][2]int, len(commands)) // The command and the distance symbols
+	for i, cmd := range commands {
+		insertCode := getCode(insertLengthBase, cmd.insertLength)
+		copyCode := getCode(copyLengthBase, maxInt(cmd.copyLength, 2))
+		symbols[i][0] = commandCells[insertCode>>3][copyCode>>3] + (insertCode&7)<<3 + copyCode&7
+		commandCounts[symbols[i][0]]++
+		for _, b := range data[cmd.start : cmd.start+cmd.insertLength] {
+			literalCounts[b]++
+		}
+		if cmd.copyLength > 0 {
+			// The distance codes from 16 with no postfix bits and no direct distance codes.
+			d := cmd.distance + 3
+			extraBits := bitLength(d) - 2
+			symbols[i][1] = 16 + 2*(extraBits-1) + (d>>extraBits)&1
+			distanceCounts[symbols[i][1]]++
+		}
+	}
+	literalLengths := getCodeLengths(literalCounts, 15)
+	commandLengths := getCodeLengths(commandCounts, 15)
+	distanceLengths := getCodeLengths(distanceCounts, 15)
+
+	w := &bitWriter{}
+	w.writeBits(1, 1)             // The window size is
+	w.writeBits(windowBits-17, 3) // 2^22 bytes
+	w.writeBits(1, 1)             // ISLAST
+	w.writeBits(0, 1)             // ISLASTEMPTY
+	numNibbles := 4
+	for (len(data)-1)>>(4*numNibbles) != 0 {
+		numNibbles++
+	}
+	w.writeBits(uint64(numNibbles-4), 2)
+	w.writeBits(uint64(len(data)-1), uint(4*numNibbles))
+	w.writeBits(0, 3) // One block type for the literals, the commands and the distances
+	w.writeBits(0, 6) // NPOSTFIX and NDIRECT
+	w.writeBits(0, 2) // The context mode of the literals
+	w.writeBits(0, 2) // One literal and one distance prefix code
+	writePrefixCode(w, literalLengths)
+	writePrefixCode(w, commandLengths)
+	writePrefixCode(w, distanceLengths)
+	literalCodes := getCodes(literalLengths)
+	commandCodes := getCodes(commandLengths)
+	distanceCodes := getCodes(distanceLengths)
+	for i, cmd := range commands {
+		command := symbols[i][0]
+		w.writeCode(commandCodes[command], commandLengths[command])
+		insertCode := getCode(insertLengthBase, cmd.insertLength)
+		w.writeBits(uint64(cmd.insertLength-insertLengthBase[insertCode]), insertLengthExtra[insertCode])
+		copyCode := getCode(copyLengthBase, maxInt(cmd.copyLength, 2))
+		w.writeBits(uint64(maxInt(cmd.copyLength, 2)-copyLengthBase[copyCode]), copyLengthExtra[copyCode])
+		for _, b := range data[cmd.start : cmd.start+cmd.insertLength] {
+			w.writeCode(literalCodes[b], literalLengths[b])
+		}
+		if cmd.copyLength > 0 {
+			distance := symbols[i][1]
+			w.writeCode(distanceCodes[distance], distanceLengths[distance])
+			d := cmd.distance + 3
+			extraBits := uint(bitLength(d) - 2)
+			w.writeBits(uint64(d&(1<<extraBits-1)), extraBits)
+		}
+	}
+	w.flush()
+	return w.buf
+}
+
+// The order of the code length code lengths and their fixed prefix codes written LSB first.
+var codeLengthOrder = []int{1, 2, 3, 4, 0, 5, 17, 6, 16, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+var codeLengthPrefixCode = [6][2]uint64{{0, 2}, {7, 4}, {3, 3}, {2, 2}, {1, 2}, {15, 4}}
+
+// writePrefixCode writes the complex prefix code with the code lengths.
+// The code lengths after the last used symbol are not written.
+func writePrefixCode(w *bitWriter, lengths []int) {
+	last := len(lengths) - 1
+	for lengths[last] == 0 {
+		last--
+	}
+	counts := make([]int, 18)
+	for _, length := range lengths[:last+1] {
+		counts[length]++
+	}
+	codeLengthLengths := getCodeLengths(counts, 5)
+	w.writeBits(0, 2) // HSKIP
+	lastCodeLength := 17
+	for codeLengthLengths[codeLengthOrder[lastCodeLength]] == 0 {
+		lastCodeLength--
+	}
+	for _, symbol := range codeLengthOrder[:lastCodeLength+1] {
+		prefix := codeLengthPrefixCode[codeLengthLengths[symbol]]
+		w.writeBits(prefix[0], uint(prefix[1]))
+	}
+	codes := getCodes(codeLengthLengths)
+	for _, length := range lengths[:last+1] {
+		w.writeCode(codes[length], codeLengthLengths[length])
+	}
+}
+
+// getCodeLengths returns the lengths of the Huffman codes of the symbols.
+// The counts are halved until the longest code is not longer than the maximum length.
+// At least two symbols get codes so that the code is complete.
+func getCodeLengths(counts []int, maxLength int) []int {
+	counts = append([]int{}, counts...)
+	used := 0
+	for _, count := range counts {
+		if count > 0 {
+			used++
+		}
+	}
+	for i := 0; used < 2; i++ {
+		if counts[i] == 0 {
+			counts[i] = 1
+			used++
+		}
+	}
+	for {
+		lengths := getHuffmanLengths(counts)
+		longest := 0
+		for _, length := range lengths {
+			longest = maxInt(longest, length)
+		}
+		if longest <= maxLength {
+			return lengths
+		}
+		for i, count := range counts {
+			counts[i] = (count + 1) / 2
+		}
+	}
+}
+
+// getHuffmanLengths returns the depths of the symbols in the Huffman tree.
+func getHuffmanLengths(counts []int) []int {
+	type node struct {
+		count  int
+		parent int
+	}
+	nodes := make([]node, 0)
+	leaves := make([]int, 0) // The node of each used symbol
+	for symbol, count := range counts {
+		if count > 0 {
+			leaves = append(leaves, symbol)
+			nodes = append(nodes, node{count, -1})
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].count < nodes[j].count })
+	sort.SliceStable(leaves, func(i, j int) bool { return counts[leaves[i]] < counts[leaves[j]] })
+	// The leaves and the inner nodes are taken in the order of their counts from two queues.
+	numLeaves := len(nodes)
+	next, nextInner := 0, numLeaves
+	take := func() int {
+		if next < numLeaves && (nextInner == len(nodes) || nodes[next].count <= nodes[nextInner].count) {
+			next++
+			return next - 1
+		}
+		nextInner++
+		return nextInner - 1
+	}
+	for i := 1; i < numLeaves; i++ {
+		a, b := take(), take()
+		nodes = append(nodes, node{nodes[a].count + nodes[b].count, -1})
+		nodes[a].parent, nodes[b].parent = len(nodes)-1, len(nodes)-1
+	}
+	lengths := make([]int, len(counts))
+	for i, symbol := range leaves {
+		for n := i; nodes[n].parent >= 0; n = nodes[n].parent {
+			lengths[symbol]++
+		}
+	}
+	return lengths
+}
+
+// getCodes returns the canonical prefix codes for the code lengths.
+func getCodes(lengths []int) []uint64 {
+	codes := make([]uint64, len(lengths))
+	code := uint64(0)
+	for length := 1; length <= 15; length++ {
+		for symbol, l := range lengths {
+			if l == length {
+				codes[symbol] = code
+				code++
+			}
+		}
+		code <<= 1
+	}
+	return codes
+}
+
+// bitWriter writes the bits starting with the least significant bit of each byte.
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	count uint
+}
+
+func (w *bitWriter) writeBits(value uint64, n uint) {
+	w.bits |= value << w.count
+	w.count += n
+	for w.count >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.count -= 8
+	}
+}
+
+// writeCode writes the prefix code starting with its most significant bit.
+func (w *bitWriter) writeCode(code uint64, length int) {
+	reversed := uint64(0)
+	for i := 0; i < length; i++ {
+		reversed = reversed<<1 | code>>uint(i)&1
+	}
+	w.writeBits(reversed, uint(length))
+}
+
+func (w *bitWriter) flush() {
+	if w.count > 0 {
+		w.writeBits(0, 8-w.count)
+	}
+}
+
+func bitLength(value int) int {
+	n := 0
+	for value != 0 {
+		value >>= 1
+		n++
+	}
+	return n
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// getPageText returns the text on the page - one line for each TJ operator.
+// The glyph IDs are mapped back to the text with the ToUnicode CMaps of the fonts.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	cmaps := make(map[string]map[string]string)
+	fonts := regexp.MustCompile(`/Font << (.*?) >>`).FindStringSubmatch(resources)
+	for _, font := range regexp.MustCompile(`/(\w+) (\d+) 0 R`).FindAllStringSubmatch(fonts[1], -1) {
+		dict := strings.Join(getObject(objects, font[2]).GetDict(), " ")
+		if match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict); match != nil {
+			cmaps[font[1]] = getToUnicode(getObject(objects, match[1]).GetData())
+		}
+	}
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := string(objects[number-1].GetData())
+		var cmap map[string]string
+		for _, op := range regexp.MustCompile(`/(\w+) [\d.]+ Tf|\[([^\]]*)\] TJ`).FindAllStringSubmatch(data, -1) {
+			if op[1] != "" {
+				cmap = cmaps[op[1]]
+				continue
+			}
+			var line strings.Builder
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(op[2], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					line.WriteString(cmap[str[1][i:i+4]])
+				}
+			}
+			lines = append(lines, line.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+func main() {
+	start := time.Now()
+	Example71()
+	pdfjet.PrintDuration("Example_71", time.Since(start))
+}
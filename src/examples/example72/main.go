@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example72 -- Draws the layered COLR glyphs and the sbix bitmap glyphs in color
+func Example72() {
+	// The color font made from IBM Plex Sans has the letter O drawn as red O
+	// with blue o inside it and the letter X drawn as green bitmap.
+	colorFont := makeColorFont(content.OfBinaryFile("fonts/IBMPlexSans/IBMPlexSans-Regular.ttf"))
+
+	pdf := pdfjet.NewPDFFile("Example_72.pdf")
+	font := pdfjet.NewFont(pdf, bytes.NewReader(colorFont))
+	font.SetSize(48.0)
+
+	text := "XOXO"
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	textLine := pdfjet.NewTextLine(font, text)
+	textLine.SetLocation(50.0, 100.0)
+	textLine.DrawOn(page)
+	pdf.Complete()
+
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := reader.ReadErr(content.OfBinaryFile("Example_72.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pages := reader.GetPageObjects(objects)
+
+	// Each O is drawn as two colored layers and each X as image.
+	// The color glyphs are drawn invisible on top so that the text can be copied.
+	var colors []string
+	invisible, images := 0, 0
+	var data []byte
+	for _, number := range pages[0].GetObjectNumbers("/Contents") {
+		data = append(data, objects[number-1].GetData()...)
+	}
+	for _, op := range regexp.MustCompile(`([\d.]+) ([\d.]+) ([\d.]+) rg|(\d) Tr|/\w+ Do`).FindAllStringSubmatch(string(data), -1) {
+		switch {
+		case op[1] != "":
+			switch op[1] + " " + op[2] + " " + op[3] {
+			case "1.000 0.000 0.000":
+				colors = append(colors, "red")
+			case "0.000 0.000 1.000":
+				colors = append(colors, "blue")
+			}
+		case op[4] != "":
+			if op[4] == "3" {
+				invisible++
+			}
+		default:
+			images++
+		}
+	}
+	if strings.Join(colors, " ") != "red blue red blue" {
+		log.Fatalf("Example_72: the layer colors are %q", colors)
+	}
+	if invisible != len(text) || images != strings.Count(text, "X") {
+		log.Fatalf("Example_72: %d invisible glyphs and %d images", invisible, images)
+	}
+
+	extracted := getPageText(pages[0], objects)
+	if strings.ReplaceAll(extracted, "\n", "") != text {
+		log.Fatalf("Example_72: the extracted text is %q", extracted)
+	}
+}
+
+// makeColorFont adds the COLR, CPAL and sbix tables to static TrueType font.
+func makeColorFont(buf []byte) []byte {
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(buf[4:]))
+	for i := 0; i < numTables; i++ {
+		record := buf[12+16*i:]
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		tables[string(record[:4])] = buf[offset : offset+length]
+	}
+	gidO := getGlyphID(tables["cmap"], 'O')
+	gido := getGlyphID(tables["cmap"], 'o')
+	gidX := getGlyphID(tables["cmap"], 'X')
+	numGlyphs := int(binary.BigEndian.Uint16(tables["maxp"][4:]))
+
+	// The palette with red and blue color in BGRA order.
+	var cpal []byte
+	cpal = binary.BigEndian.AppendUint16(cpal, 0)  // version
+	cpal = binary.BigEndian.AppendUint16(cpal, 2)  // numPaletteEntries
+	cpal = binary.BigEndian.AppendUint16(cpal, 1)  // numPalettes
+	cpal = binary.BigEndian.AppendUint16(cpal, 2)  // numColorRecords
+	cpal = binary.BigEndian.AppendUint32(cpal, 14) // colorRecordsArrayOffset
+	cpal = binary.BigEndian.AppendUint16(cpal, 0)  // colorRecordIndices
+	cpal = append(cpal, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0xFF)
+	tables["CPAL"] = cpal
+
+	// The base glyph O with two layers.
+	var colr []byte
+	colr = binary.BigEndian.AppendUint16(colr, 0)  // version
+	colr = binary.BigEndian.AppendUint16(colr, 1)  // numBaseGlyphRecords
+	colr = binary.BigEndian.AppendUint32(colr, 14) // baseGlyphRecordsOffset
+	colr = binary.BigEndian.AppendUint32(colr, 20) // layerRecordsOffset
+	colr = binary.BigEndian.AppendUint16(colr, 2)  // numLayerRecords
+	colr = binary.BigEndian.AppendUint16(colr, uint16(gidO))
+	colr = binary.BigEndian.AppendUint16(colr, 0) // firstLayerIndex
+	colr = binary.BigEndian.AppendUint16(colr, 2) // numLayers
+	colr = binary.BigEndian.AppendUint16(colr, uint16(gidO))
+	colr = binary.BigEndian.AppendUint16(colr, 0) // Red
+	colr = binary.BigEndian.AppendUint16(colr, uint16(gido))
+	colr = binary.BigEndian.AppendUint16(colr, 1) // Blue
+	tables["COLR"] = colr
+
+	// The strike of 64 pixels per em with the bitmap of the glyph X.
+	bitmap := image.NewNRGBA(image.Rect(0, 0, 40, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 40; x++ {
+			if (x-20)*(x-20)+(y-24)*(y-24) < 18*18 {
+				bitmap.Set(x, y, color.NRGBA{0x00, 0x99, 0x33, 0xFF})
+			}
+		}
+	}
+	var data bytes.Buffer
+	png.Encode(&data, bitmap)
+	var strike []byte
+	strike = binary.BigEndian.AppendUint16(strike, 64) // ppem
+	strike = binary.BigEndian.AppendUint16(strike, 72) // ppi
+	glyphData := 4 + 4*(numGlyphs+1)
+	for gid := 0; gid <= numGlyphs; gid++ {
+		offset := glyphData
+		if gid > gidX {
+			offset += 8 + data.Len()
+		}
+		strike = binary.BigEndian.AppendUint32(strike, uint32(offset))
+	}
+	strike = binary.BigEndian.AppendUint16(strike, 2) // originOffsetX
+	strike = binary.BigEndian.AppendUint16(strike, 0) // originOffsetY
+	strike = append(strike, "png "...)
+	strike = append(strike, data.Bytes()...)
+	var sbix []byte
+	sbix = binary.BigEndian.AppendUint16(sbix, 1) // version
+	sbix = binary.BigEndian.AppendUint16(sbix, 1) // flags
+	sbix = binary.BigEndian.AppendUint32(sbix, 1) // numStrikes
+	sbix = binary.BigEndian.AppendUint32(sbix, 12)
+	tables["sbix"] = append(sbix, strike...)
+
+	return writeFont(tables)
+}
+
+// getGlyphID returns the glyph ID of the character from the format 4 subtable of the cmap table.
+func getGlyphID(cmap []byte, c rune) int {
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := cmap[4+8*i:]
+		subtable := cmap[binary.BigEndian.Uint32(record[4:]):]
+		if binary.BigEndian.Uint16(subtable) != 4 {
+			continue
+		}
+		segCount := int(binary.BigEndian.Uint16(subtable[6:])) / 2
+		for j := 0; j < segCount; j++ {
+			endCode := rune(binary.BigEndian.Uint16(subtable[14+2*j:]))
+			startCode := rune(binary.BigEndian.Uint16(subtable[16+2*segCount+2*j:]))
+			if c < startCode || c > endCode {
+				continue
+			}
+			idDelta := int(binary.BigEndian.Uint16(subtable[16+4*segCount+2*j:]))
+			rangeOffset := 16 + 6*segCount + 2*j
+			idRangeOffset := int(binary.BigEndian.Uint16(subtable[rangeOffset:]))
+			if idRangeOffset == 0 {
+				return (int(c) + idDelta) & 0xFFFF
+			}
+			gid := int(binary.BigEndian.Uint16(subtable[rangeOffset+idRangeOffset+2*int(c-startCode):]))
+			if gid == 0 {
+				return 0
+			}
+			return (gid + idDelta) & 0xFFFF
+		}
+	}
+	return 0
+}
+
+// writeFont writes the tables as TrueType font.
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= len(tags) {
+		entrySelector++
+	}
+	var buf []byte
+	buf = binary.BigEndian.AppendUint32(buf, 0x00010000)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(tags)))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(16<<entrySelector))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(entrySelector))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(16*len(tags)-16<<entrySelector))
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		table := tables[tag]
+		checksum := uint32(0)
+		padded := append(append([]byte(nil), table...), make([]byte, 3)...)
+		for i := 0; i < len(table); i += 4 {
+			checksum += binary.BigEndian.Uint32(padded[i:])
+		}
+		buf = append(buf, tag...)
+		buf = binary.BigEndian.AppendUint32(buf, checksum)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(offset))
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(table)))
+		offset += (len(table) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		buf = append(buf, tables[tag]...)
+		for len(buf)%4 != 0 {
+			buf = append(buf, 0)
+		}
+	}
+	return buf
+}
+
+// getPageText returns the text on the page - one line for each TJ operator.
+// The glyph IDs are mapped back to the text with the ToUnicode CMaps of the fonts.
+// The artifacts like the layers of the color glyphs are not part of the text.
+func getPageText(page *pdfjet.PDFobj, objects []*pdfjet.PDFobj) string {
+	resources := strings.Join(page.GetDict(), " ")
+	if match := regexp.MustCompile(`/Resources (\d+) 0 R`).FindStringSubmatch(resources); match != nil {
+		resources = strings.Join(getObject(objects, match[1]).GetDict(), " ")
+	}
+	cmaps := make(map[string]map[string]string)
+	fonts := regexp.MustCompile(`/Font << (.*?) >>`).FindStringSubmatch(resources)
+	for _, font := range regexp.MustCompile(`/(\w+) (\d+) 0 R`).FindAllStringSubmatch(fonts[1], -1) {
+		dict := strings.Join(getObject(objects, font[2]).GetDict(), " ")
+		if match := regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(dict); match != nil {
+			cmaps[font[1]] = getToUnicode(getObject(objects, match[1]).GetData())
+		}
+	}
+	lines := make([]string, 0)
+	for _, number := range page.GetObjectNumbers("/Contents") {
+		data := regexp.MustCompile(`(?s)/Artifact BMC.*?EMC`).ReplaceAllString(string(objects[number-1].GetData()), "")
+		var cmap map[string]string
+		for _, op := range regexp.MustCompile(`/(\w+) [\d.]+ Tf|\[([^\]]*)\] TJ`).FindAllStringSubmatch(data, -1) {
+			if op[1] != "" {
+				cmap = cmaps[op[1]]
+				continue
+			}
+			var line strings.Builder
+			for _, str := range regexp.MustCompile(`<([0-9A-F]*)>`).FindAllStringSubmatch(op[2], -1) {
+				for i := 0; i+4 <= len(str[1]); i += 4 {
+					line.WriteString(cmap[str[1][i:i+4]])
+				}
+			}
+			lines = append(lines, line.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getToUnicode returns the text of the glyph IDs in the ToUnicode CMap.
+func getToUnicode(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, match := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]*)>`).FindAllStringSubmatch(string(data), -1) {
+		buf, _ := hex.DecodeString(match[2])
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+		cmap[match[1]] = string(utf16.Decode(units))
+	}
+	return cmap
+}
+
+// getObject returns the object with the number.
+func getObject(objects []*pdfjet.PDFobj, number string) *pdfjet.PDFobj {
+	n, _ := strconv.Atoi(number)
+	return objects[n-1]
+}
+
+func main() {
+	start := time.Now()
+	Example72()
+	pdfjet.PrintDuration("Example_72", time.Since(start))
+}
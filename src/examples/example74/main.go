@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example74 -- Extracts the text with the glyph positions from generated statement
+func Example74() {
+	pdf := pdfjet.NewPDFFile("Example_74.pdf")
+	f1 := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	f2 := pdfjet.NewFontFromFile(pdf, "fonts/NotoSans/NotoSans-Regular.ttf")
+	f1.SetSize(12.0)
+	f2.SetSize(10.0)
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	lines := []string{
+		"Statement 2024-03",
+		"Opening balance 1,250.00",
+		"Überweisung an Jürgen Weiß -200.00",
+		"Closing balance 1,050.00",
+	}
+	y := float32(100.0)
+	for i, line := range lines {
+		font := f1
+		if i == 2 {
+			font = f2 // The text with characters outside of WinAnsiEncoding
+		}
+		textLine := pdfjet.NewTextLine(font, line)
+		textLine.SetLocation(50.0, y)
+		textLine.DrawOn(page)
+		y += 20.0
+	}
+	textLine := pdfjet.NewTextLine(f1, "Page 1 of 1")
+	textLine.SetLocation(500.0, 700.0)
+	textLine.SetTextDirection(90)
+	textLine.DrawOn(page)
+	pdf.Complete()
+
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := reader.ReadErr(content.OfBinaryFile("Example_74.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pages := reader.GetPageObjects(objects)
+	text, err := pages[0].ExtractTextErr(objects)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The lines in the order they are drawn followed by the rotated line.
+	expected := strings.Join(append(lines, "Page 1 of 1"), "\n")
+	if text.String() != expected {
+		log.Fatalf("Example_74: the extracted text is %q", text.String())
+	}
+
+	// The first glyph of each line is at the location of the text line.
+	glyph := text.Glyphs[0]
+	if glyph.Text != "S" || glyph.X != 50.0 || glyph.Y != 100.0 ||
+		glyph.FontSize != 12.0 || glyph.Font != "Helvetica" {
+		log.Fatalf("Example_74: the first glyph is %+v", glyph)
+	}
+	for _, g := range text.Glyphs {
+		if g.Text == "Ü" {
+			if g.X != 50.0 || g.Y != 140.0 || g.FontSize != 10.0 || g.Font != "NotoSans-Regular" {
+				log.Fatalf("Example_74: the first glyph of the third line is %+v", g)
+			}
+		}
+	}
+
+	// The rotated text goes up the page - the y coordinate of its glyphs decreases.
+	rotated := text.Glyphs[len(text.Glyphs)-len("Page 1 of 1"):]
+	for i, g := range rotated {
+		if math.Abs(float64(g.X-500.0)) > 0.01 || (i > 0 && g.Y >= rotated[i-1].Y) {
+			log.Fatalf("Example_74: the rotated glyph %d is %+v", i, g)
+		}
+	}
+	width := f1.StringWidth(nil, "Page 1 of 1")
+	last := rotated[len(rotated)-1]
+	if math.Abs(float64(700.0-last.Y+last.Width-width)) > 0.01 {
+		log.Fatalf("Example_74: the rotated text ends at %v", last.Y-last.Width)
+	}
+
+	// The page with malformed MediaBox gives error instead of exiting.
+	buf := content.OfBinaryFile("Example_74.pdf")
+	buf = bytes.Replace(buf, []byte("612.000 792.000]"), []byte("612.000 (792.0)]"), 1)
+	objects, err = reader.ReadErr(buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pages = reader.GetPageObjects(objects)
+	_, err = pages[0].ExtractTextErr(objects)
+	var malformed *pdfjet.MalformedInputError
+	if !errors.As(err, &malformed) {
+		log.Fatalf("Example_74: expected malformed input error for bad MediaBox, got %v", err)
+	}
+}
+
+func main() {
+	start := time.Now()
+	Example74()
+	pdfjet.PrintDuration("Example_74", time.Since(start))
+}
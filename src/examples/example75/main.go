@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/color"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example75 -- Removes the watermark and recolors the text of existing pages
+func Example75() {
+	pdf := pdfjet.NewPDFFile("Example_75.pdf")
+	objects := pdf.Read(createDocument())
+	for _, pageObj := range pdf.GetPageObjects(objects) {
+		stream := pageObj.GetContentStream(objects)
+
+		// The watermark is the text object with the 72 point font.
+		var operations, textObject []*pdfjet.ContentOperation
+		watermark := false
+		for _, op := range stream.Operations {
+			switch {
+			case op.Operator == "BT":
+				textObject = append(textObject[:0], op)
+				watermark = false
+			case textObject != nil && op.Operator == "ET":
+				if !watermark {
+					operations = append(append(operations, textObject...), op)
+				}
+				textObject = nil
+			case textObject != nil:
+				if op.Operator == "Tf" && op.GetNumber(1) == 72.0 {
+					watermark = true
+				}
+				textObject = append(textObject, op)
+			default:
+				operations = append(operations, op)
+			}
+		}
+		stream.Operations = operations
+
+		// The black text is recolored blue.
+		stream.Rewrite(func(op *pdfjet.ContentOperation) []*pdfjet.ContentOperation {
+			if op.Operator == "rg" && op.GetNumber(0) == 0.0 && op.GetNumber(1) == 0.0 && op.GetNumber(2) == 0.0 {
+				return []*pdfjet.ContentOperation{pdfjet.NewContentOperation("rg", 0.0, 0.0, 1.0)}
+			}
+			return []*pdfjet.ContentOperation{op}
+		})
+
+		// The serialized content stream is parsed back to the same operations.
+		if len(pdfjet.NewContentStream(stream.Bytes()).Operations) != len(stream.Operations) {
+			log.Fatal("Example_75: the content stream does not round trip")
+		}
+		pageObj.SetContentStream(stream, &objects)
+	}
+	pdf.AddObjects(&objects)
+	pdf.Complete()
+
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := reader.ReadErr(content.OfBinaryFile("Example_75.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, pageObj := range reader.GetPageObjects(objects) {
+		text := pageObj.ExtractText(objects).String()
+		if strings.Contains(text, "DRAFT") || !strings.Contains(text, "Quarterly report") {
+			log.Fatalf("Example_75: the text of page %d is %q", i+1, text)
+		}
+		for _, op := range pageObj.GetContentStream(objects).Operations {
+			if op.Operator == "rg" && op.GetNumber(0) == 0.0 && op.GetNumber(1) == 0.0 && op.GetNumber(2) == 0.0 {
+				log.Fatalf("Example_75: page %d has black text", i+1)
+			}
+		}
+	}
+}
+
+// createDocument creates two pages of report with DRAFT watermark.
+func createDocument() []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	f1 := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	f2 := pdfjet.NewCoreFont(pdf, corefont.HelveticaBold())
+	f1.SetSize(12.0)
+	f2.SetSize(72.0)
+
+	for i := 0; i < 2; i++ {
+		page := pdfjet.NewPage(pdf, letter.Portrait)
+		watermark := pdfjet.NewTextLine(f2, "DRAFT")
+		watermark.SetLocation(150.0, 500.0)
+		watermark.SetTextDirection(45)
+		watermark.SetColor(color.LightGray)
+		watermark.DrawOn(page)
+
+		textLine := pdfjet.NewTextLine(f1, "Quarterly report")
+		textLine.SetLocation(50.0, 80.0)
+		textLine.DrawOn(page)
+
+		line := pdfjet.NewLine(50.0, 90.0, 550.0, 90.0)
+		line.DrawOn(page)
+	}
+	pdf.Complete()
+	w.Flush()
+	return buf.Bytes()
+}
+
+func main() {
+	start := time.Now()
+	Example75()
+	pdfjet.PrintDuration("Example_75", time.Since(start))
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/imagetype"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example76 -- Redacts the text, the image pixels and the link inside areas of existing page
+func Example76() {
+	pdf := pdfjet.NewPDFFile("Example_76.pdf")
+	objects := pdf.Read(createDocument())
+	pageObj := pdf.GetPageObjects(objects)[0]
+	before := pageObj.ExtractText(objects)
+
+	// The social security number and the left half of the image.
+	f1 := pdfjet.NewCoreFont(pdfjet.NewPDF(bufio.NewWriter(io.Discard)), corefont.Helvetica())
+	f1.SetSize(12.0)
+	x := 50.0 + f1.StringWidth(nil, "SSN: ")
+	redactions := []*pdfjet.Redaction{
+		pdfjet.NewRedaction(x, 108.0, f1.StringWidth(nil, "123-45-6789"), 16.0),
+		pdfjet.NewRedaction(300.0, 200.0, 50.0, 100.0),
+	}
+	pageObj.Redact(redactions, &objects)
+	pdf.AddObjects(&objects)
+	pdf.Complete()
+
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := reader.ReadErr(content.OfBinaryFile("Example_76.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pageObj = reader.GetPageObjects(objects)[0]
+	after := pageObj.ExtractText(objects)
+
+	// The redacted text is gone and the other glyphs did not move.
+	text := after.String()
+	if strings.Contains(text, "123-45-6789") || !strings.Contains(text, "SSN:") ||
+		!strings.Contains(text, "Name: John Smith") || !strings.Contains(text, "Account: 9876543210") {
+		log.Fatalf("Example_76: the text after redaction is %q", text)
+	}
+	for _, g := range after.Glyphs {
+		found := false
+		for _, b := range before.Glyphs {
+			if b.Text == g.Text && b.X == g.X && b.Y == g.Y {
+				found = true
+			}
+		}
+		if !found {
+			log.Fatalf("Example_76: the glyph %+v moved", g)
+		}
+	}
+
+	// Only the link over the account number is left.
+	if annots := pageObj.GetObjectNumbers("/Annots"); len(annots) != 1 {
+		log.Fatalf("Example_76: %d annotations after redaction", len(annots))
+	}
+
+	// The left half of the white image is cleared in the redacted copy
+	// and the original image is not left in the file.
+	images := 0
+	for _, obj := range objects {
+		dict := strings.Join(obj.GetDict(), " ")
+		if !strings.Contains(dict, "/Subtype /Image") || len(obj.GetData()) == 0 {
+			continue
+		}
+		images++
+		data := obj.GetData()
+		row := 50 * 100 * 3
+		if len(data) != 100*100*3 || data[row+10*3] != 0x00 || data[row+90*3] != 0xFF {
+			log.Fatalf("Example_76: the image data is not redacted")
+		}
+	}
+	if images != 1 {
+		log.Fatalf("Example_76: %d images with data", images)
+	}
+}
+
+// createDocument creates page with personal data, white image and two links.
+func createDocument() []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	pdf := pdfjet.NewPDF(w)
+	f1 := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	f1.SetSize(12.0)
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	uri := "https://example.com/customers/1234"
+	lines := []string{"Name: John Smith", "SSN: 123-45-6789", "Account: 9876543210"}
+	for i, line := range lines {
+		textLine := pdfjet.NewTextLine(f1, line)
+		textLine.SetLocation(50.0, 100.0+float32(i)*20.0)
+		if i > 0 {
+			textLine.SetURIAction(&uri)
+		}
+		textLine.DrawOn(page)
+	}
+
+	bitmap := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			bitmap.Set(x, y, color.White)
+		}
+	}
+	var data bytes.Buffer
+	png.Encode(&data, bitmap)
+	img := pdfjet.NewImage(pdf, bytes.NewReader(data.Bytes()), imagetype.PNG)
+	img.SetLocation(300.0, 200.0)
+	img.DrawOn(page)
+
+	pdf.Complete()
+	w.Flush()
+	return buf.Bytes()
+}
+
+func main() {
+	start := time.Now()
+	Example76()
+	pdfjet.PrintDuration("Example_76", time.Since(start))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+)
+
+// writeForm returns the fillable form with text field, combo box and check box
+// as received from other producers.
+func writeForm() []byte {
+	labels := "BT /Helv 10 Tf 50 730 Td (Name:) Tj 0 -30 Td (Country:) Tj 0 -30 Td (Subscribe:) Tj ET"
+	formObjects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm 6 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R\n" +
+			"/Resources << /Font << /Helv 5 0 R >> >> /Annots [7 0 R 8 0 R 9 0 R] >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(labels)+1, labels),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Fields [7 0 R 8 0 R 9 0 R] /DR << /Font << /Helv 5 0 R >> >> /DA (/Helv 10 Tf 0 g) >>",
+		"<< /Type /Annot /Subtype /Widget /FT /Tx /T (name) /TU (Full name) /V (Your name)\n" +
+			"/Rect [120 724 320 744] /P 3 0 R /MK << /BC [0] >> >>",
+		"<< /Type /Annot /Subtype /Widget /FT /Ch /Ff 131072 /T (country)\n" +
+			"/Opt [(Canada) (France) (Germany)] /Rect [120 694 320 714] /P 3 0 R /MK << /BC [0] >> >>",
+		"<< /Type /Annot /Subtype /Widget /FT /Btn /T (subscribe)\n" +
+			"/Rect [120 668 134 682] /P 3 0 R /MK << /BC [0] >> >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(formObjects))
+	for i, object := range formObjects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	startxref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(formObjects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\n", len(formObjects)+1)
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", startxref)
+	return buf.Bytes()
+}
+
+// Example77 -- Fills the fields of existing form and flattens them
+func Example77() {
+	// Fill the fields of the form and flatten them.
+	pdf := pdfjet.NewPDFFile("Example_77.pdf")
+	objects := pdf.Read(writeForm())
+	if len(pdf.GetFormFields(objects)) != 3 {
+		log.Fatal("Example_77: the form fields were not found")
+	}
+	pdf.SetFormFieldValue("subscribe", "Yes", &objects)
+	pdf.SetFormFieldValue("name", "Jürgen Müller", &objects)
+	pdf.SetFormFieldValue("country", "Germany", &objects)
+	pdf.FlattenFormFields(&objects)
+	pdf.AddObjects(&objects)
+	pdf.Complete()
+
+	// The flattened PDF has no form fields and shows the values as page content.
+	pdf = pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects = pdf.Read(content.OfBinaryFile("Example_77.pdf"))
+	if len(pdf.GetFormFields(objects)) != 0 {
+		log.Fatal("Example_77: the form fields were not flattened")
+	}
+	text := pdf.ExtractText(objects)[0].String()
+	if !strings.Contains(text, "Jürgen Müller") || !strings.Contains(text, "Germany") {
+		log.Fatal("Example_77: the filled values are missing in the page content")
+	}
+}
+
+func main() {
+	start := time.Now()
+	Example77()
+	pdfjet.PrintDuration("Example_77", time.Since(start))
+}
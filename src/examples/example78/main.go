@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	pdfjet "github.com/edragoev1/pdfjet/src"
+	"github.com/edragoev1/pdfjet/src/content"
+	"github.com/edragoev1/pdfjet/src/corefont"
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// Example78 -- Creates onboarding form with interactive fields and reads the fields back
+func Example78() {
+	pdf := pdfjet.NewPDFFile("Example_78.pdf")
+	f1 := pdfjet.NewCoreFont(pdf, corefont.Helvetica())
+	f1.SetSize(10.0)
+
+	page := pdfjet.NewPage(pdf, letter.Portrait)
+	labels := []string{"Name:", "Address:", "Postal code:", "Newsletter:", "Plan:", "Country:", "Languages:"}
+	for i, text := range labels {
+		label := pdfjet.NewTextLine(f1, text)
+		label.SetLocation(50.0, 60.0+float32(i)*40.0)
+		label.DrawOn(page)
+	}
+
+	name := pdfjet.NewTextField(f1, "name")
+	name.SetLocation(150.0, 48.0)
+	name.SetValue("Jane Doe")
+	name.SetRequired(true)
+	name.DrawOn(page)
+
+	address := pdfjet.NewTextField(f1, "address")
+	address.SetLocation(150.0, 88.0)
+	address.SetSize(200.0, 30.0)
+	address.SetMultiline(true)
+	address.DrawOn(page)
+
+	postalCode := pdfjet.NewTextField(f1, "postalCode")
+	postalCode.SetLocation(150.0, 128.0)
+	postalCode.SetSize(100.0, 16.0)
+	postalCode.SetMaxLength(5)
+	postalCode.SetComb(true)
+	postalCode.SetValue("10115")
+	postalCode.DrawOn(page)
+
+	newsletter := pdfjet.NewCheckBoxField("newsletter")
+	newsletter.SetLocation(150.0, 168.0)
+	newsletter.SetChecked(true)
+	newsletter.DrawOn(page)
+
+	plan := pdfjet.NewRadioButtonGroup("plan")
+	plan.AddButton("Basic", 150.0, 208.0)
+	plan.AddButton("Plus", 200.0, 208.0)
+	plan.AddButton("Pro", 250.0, 208.0)
+	plan.Select("Plus")
+	plan.DrawOn(page)
+
+	country := pdfjet.NewComboBox(f1, "country", []string{"Canada", "France", "Germany"})
+	country.SetLocation(150.0, 248.0)
+	country.SetValue("France")
+	country.DrawOn(page)
+
+	languages := pdfjet.NewListBox(f1, "languages", []string{"English", "French", "German"})
+	languages.SetLocation(150.0, 288.0)
+	languages.DrawOn(page)
+
+	url := "https://example.com/onboarding"
+	submit := pdfjet.NewPushButton(f1, "submit", "Submit")
+	submit.SetLocation(150.0, 360.0)
+	submit.SetSubmitAction(&url)
+	submit.DrawOn(page)
+	pdf.Complete()
+
+	reader := pdfjet.NewPDF(bufio.NewWriter(io.Discard))
+	objects, err := reader.ReadErr(content.OfBinaryFile("Example_78.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The fields with their types, values and options.
+	expected := []string{
+		"name Text Jane Doe",
+		"address Text ",
+		"postalCode Text 10115",
+		"newsletter CheckBox Yes [Yes]",
+		"plan RadioButton Plus [Basic Plus Pro]",
+		"country ComboBox France [Canada France Germany]",
+		"languages ListBox  [English French German]",
+		"submit PushButton ",
+	}
+	fields := reader.GetFormFields(objects)
+	actual := make([]string, 0, len(fields))
+	for _, field := range fields {
+		s := field.Name + " " + field.Type + " " + field.Value
+		if len(field.Options) > 0 {
+			s += " [" + strings.Join(field.Options, " ") + "]"
+		}
+		actual = append(actual, s)
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		log.Fatalf("Example_78: the form fields are\n%s", strings.Join(actual, "\n"))
+	}
+
+	// Every widget has appearance stream so that the form looks the same in all viewers.
+	widgets := 0
+	for _, obj := range objects {
+		dict := strings.Join(obj.GetDict(), " ")
+		if strings.Contains(dict, "/Subtype /Widget") {
+			widgets++
+			if !strings.Contains(dict, "/AP") {
+				log.Fatalf("Example_78: the widget %s has no appearance stream", dict)
+			}
+		}
+	}
+	if widgets != 10 {
+		log.Fatalf("Example_78: %d widgets", widgets)
+	}
+}
+
+func main() {
+	start := time.Now()
+	Example78()
+	pdfjet.PrintDuration("Example_78", time.Since(start))
+}
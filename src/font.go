@@ -30,6 +30,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"unicode"
 
 	"github.com/edragoev1/pdfjet/src/corefont"
 )
@@ -65,6 +66,10 @@ type Font struct {
 	compressedSize         int
 	uncompressedSize       int
 	metrics                [][]int // Only used for core fonts.
+	subset                 *fontSubset
+	layout                 *otLayout    // The GSUB and GPOS tables used for shaping
+	color                  *colorGlyphs // The COLR, CPAL, sbix and CBDT tables
+	language               string       // The OpenType language system tag
 
 	// Don't change the following default values!
 	size       float32
@@ -176,10 +181,32 @@ func NewCoreFontForPDFobj(coreFont *corefont.CoreFont) *Font {
 
 // NewCJKFont is the constructor for CJK - Chinese, Japanese and Korean fonts.
 // Please see Example_04.
+// The program exits if the font name is not supported. Use NewCJKFontErr to handle the error.
 //
 // @param pdf the PDF to add this font to.
 // @param fontName the font name. Please see Example_04.
 func NewCJKFont(pdf *PDF, fontName string) *Font {
+	font, err := NewCJKFontErr(pdf, fontName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// NewCJKFontErr is the constructor for CJK - Chinese, Japanese and Korean fonts.
+// Returns UnsupportedFeatureError if the font name is not one of the supported CJK fonts.
+//
+// @param pdf the PDF to add this font to.
+// @param fontName the font name. Please see Example_04.
+func NewCJKFontErr(pdf *PDF, fontName string) (*Font, error) {
+	if !strings.HasPrefix(fontName, "AdobeMingStd") &&
+		!strings.HasPrefix(fontName, "AdobeSongStd") &&
+		!strings.HasPrefix(fontName, "STHeitiSC") &&
+		!strings.HasPrefix(fontName, "KozMinPro") &&
+		!strings.HasPrefix(fontName, "AdobeMyungjoStd") {
+		return nil, unsupported("CJK font: %s", fontName)
+	}
+
 	font := new(Font)
 	font.isCJK = true
 	font.name = fontName
@@ -215,21 +242,29 @@ func NewCJKFont(pdf *PDF, fontName string) *Font {
 	pdf.appendInteger(pdf.getObjNumber() - 1)
 	pdf.appendString(" 0 R\n")
 	pdf.appendString("/CIDSystemInfo <<\n")
-	pdf.appendString("/Registry (Adobe)\n")
+	pdf.appendString("/Registry ")
+	pdf.appendLiteral("Adobe")
+	pdf.appendString("\n")
 	if strings.HasPrefix(fontName, "AdobeMingStd") {
-		pdf.appendString("/Ordering (CNS1)\n")
+		pdf.appendString("/Ordering ")
+		pdf.appendLiteral("CNS1")
+		pdf.appendString("\n")
 		pdf.appendString("/Supplement 4\n")
 	} else if strings.HasPrefix(fontName, "AdobeSongStd") || strings.HasPrefix(fontName, "STHeitiSC") {
-		pdf.appendString("/Ordering (GB1)\n")
+		pdf.appendString("/Ordering ")
+		pdf.appendLiteral("GB1")
+		pdf.appendString("\n")
 		pdf.appendString("/Supplement 4\n")
 	} else if strings.HasPrefix(fontName, "KozMinPro") {
-		pdf.appendString("/Ordering (Japan1)\n")
+		pdf.appendString("/Ordering ")
+		pdf.appendLiteral("Japan1")
+		pdf.appendString("\n")
 		pdf.appendString("/Supplement 4\n")
 	} else if strings.HasPrefix(fontName, "AdobeMyungjoStd") {
-		pdf.appendString("/Ordering (Korea1)\n")
+		pdf.appendString("/Ordering ")
+		pdf.appendLiteral("Korea1")
+		pdf.appendString("\n")
 		pdf.appendString("/Supplement 1\n")
-	} else {
-		log.Fatal("Unsupported font: " + fontName)
 	}
 	pdf.appendString(">>\n")
 	pdf.appendString(">>\n")
@@ -253,8 +288,6 @@ func NewCJKFont(pdf *PDF, fontName string) *Font {
 	} else if strings.HasPrefix(fontName, "AdobeMyungjoStd") {
 		pdf.appendString(fontName + "-UniKS-UCS2-H\n")
 		pdf.appendString("/Encoding /UniKS-UCS2-H\n")
-	} else {
-		log.Fatal("Unsupported font: " + fontName)
 	}
 	pdf.appendString("/DescendantFonts [")
 	pdf.appendInteger(pdf.getObjNumber() - 1)
@@ -264,47 +297,94 @@ func NewCJKFont(pdf *PDF, fontName string) *Font {
 	font.objNumber = pdf.getObjNumber()
 	pdf.fonts = append(pdf.fonts, font)
 
-	return font
+	return font, nil
 }
 
 // NewFontStream1 constructs font object from .ttf.stream and add it to the PDF
+// The program exits if the stream cannot be read. Use NewFontStream1Err to handle the error.
 func NewFontStream1(pdf *PDF, reader io.Reader) *Font {
+	font, err := NewFontStream1Err(pdf, reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// NewFontStream1Err constructs font object from .ttf.stream and add it to the PDF
+func NewFontStream1Err(pdf *PDF, reader io.Reader) (*Font, error) {
 	font := new(Font)
-	FontStream1(pdf, font, reader)
+	if err := fontStream1(pdf, font, reader); err != nil {
+		return nil, err
+	}
 	font.SetSize(defaultFontSize)
-	return font
+	return font, nil
 }
 
 // NewFontStream2 constructs font object from .ttf.stream and add it to the array of PDFobj
+// The program exits if the stream cannot be read. Use NewFontStream2Err to handle the error.
 func NewFontStream2(objects *[]*PDFobj, reader io.Reader) *Font {
+	font, err := NewFontStream2Err(objects, reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// NewFontStream2Err constructs font object from .ttf.stream and add it to the array of PDFobj
+func NewFontStream2Err(objects *[]*PDFobj, reader io.Reader) (*Font, error) {
 	font := new(Font)
-	FontStream2(objects, font, reader)
+	if err := fontStream2(objects, font, reader); err != nil {
+		return nil, err
+	}
 	font.SetSize(defaultFontSize)
-	return font
+	return font, nil
 }
 
-// NewFont constructs font object from OpenType and TrueType font.
+// NewFont constructs font object from OpenType, TrueType, WOFF or WOFF2 font.
+// The program exits if the font cannot be parsed. Use NewFontErr to handle the error.
 func NewFont(pdf *PDF, reader io.Reader) *Font {
+	font, err := NewFontErr(pdf, reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// NewFontErr constructs font object from OpenType, TrueType, WOFF or WOFF2 font.
+// Nothing is written to the PDF unless the font is parsed successfully.
+// The COLR glyphs are drawn in the colors of the first CPAL palette and
+// the sbix and CBDT glyphs are drawn as images.
+func NewFontErr(pdf *PDF, reader io.Reader) (*Font, error) {
 	font := new(Font)
-	registerOpenTypeFont(pdf, font, reader)
+	if err := registerOpenTypeFont(pdf, font, reader); err != nil {
+		return nil, err
+	}
 	font.SetSize(defaultFontSize)
-	return font
+	return font, nil
 }
 
+// NewFontFromFile constructs font object from .ttf, .otf, .woff, .woff2 or .ttf.stream file.
+// The program exits if the font cannot be loaded. Use NewFontFromFileErr to handle the error.
 func NewFontFromFile(pdf *PDF, filePath string) *Font {
-	var font *Font
-	f, err := os.Open(filePath)
+	font, err := NewFontFromFileErr(pdf, filePath)
 	if err != nil {
 		log.Fatal(err)
 	}
+	return font
+}
+
+// NewFontFromFileErr constructs font object from .ttf, .otf, .woff, .woff2 or .ttf.stream file.
+func NewFontFromFileErr(pdf *PDF, filePath string) (*Font, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
+	}
 	defer f.Close()
 	reader := bufio.NewReader(f)
 	if strings.HasSuffix(filePath, ".stream") {
-		font = NewFontStream1(pdf, reader)
-	} else {
-		font = NewFont(pdf, reader)
+		return NewFontStream1Err(pdf, reader)
 	}
-	return font
+	return NewFontErr(pdf, reader)
 }
 
 // SetSize sets the size of this font.
@@ -369,6 +449,23 @@ func (font *Font) GetFitChars(text string, width float32) int {
 	}
 
 	runes := []rune(text)
+	if glyphs := font.shape(runes, false); glyphs != nil {
+		for i, g := range glyphs {
+			w -= float32(g.advance)
+			if w < 0 {
+				// The reordered glyphs of the syllable may come from the characters before this one.
+				fitChars := g.cluster
+				for _, g := range glyphs[i+1:] {
+					if g.cluster < fitChars {
+						fitChars = g.cluster
+					}
+				}
+				return fitChars
+			}
+		}
+		return len(runes)
+	}
+
 	i := 0
 	for i < len(runes) {
 		c1 := runes[i]
@@ -467,8 +564,13 @@ func (font *Font) stringWidth(str string) float32 {
 				}
 			}
 		}
+	} else if glyphs := font.shape(runes, false); glyphs != nil {
+		width = float32(shapedWidth(glyphs))
 	} else {
 		for _, c1 := range runes {
+			if isDefaultIgnorable(c1) && !font.hasGlyph(c1) {
+				continue
+			}
 			if font.unicodeToGID[c1] < len(font.advanceWidth) {
 				width += float32(font.advanceWidth[font.unicodeToGID[c1]])
 			} else {
@@ -482,28 +584,89 @@ func (font *Font) stringWidth(str string) float32 {
 
 // StringWidth returns the width of text string drawn using main and fallback fonts.
 func (font *Font) StringWidth(fallbackFont *Font, text string) float32 {
+	return getTextWidth(font.getFallbackChain(fallbackFont), text)
+}
+
+// getTextWidth returns the width of the text drawn with the fonts.
+func getTextWidth(fonts []*Font, text string) float32 {
 	var width float32 = 0.0
+	for _, run := range getFontRuns(fonts, text) {
+		width += run.font.stringWidth(run.text)
+	}
+	return width
+}
 
-	if font.isCoreFont || font.isCJK || fallbackFont == nil || fallbackFont.isCoreFont || fallbackFont.isCJK {
-		return font.stringWidth(text)
+// countGlyphs returns the number of the spaces and the number of the glyphs drawn for the text.
+// The word spacing is added after each space and the character spacing after each glyph.
+func countGlyphs(fonts []*Font, text string) (int, int) {
+	spaces := 0
+	glyphs := 0
+	for _, run := range getFontRuns(fonts, text) {
+		runes := []rune(run.text)
+		spaces += strings.Count(run.text, " ")
+		if run.font.isCoreFont || run.font.isCJK {
+			glyphs += len(runes)
+		} else if shaped := run.font.shape(runes, false); shaped != nil {
+			glyphs += len(shaped)
+		} else {
+			for _, ch := range runes {
+				if !isDefaultIgnorable(ch) || run.font.hasGlyph(ch) {
+					glyphs++
+				}
+			}
+		}
 	}
+	return spaces, glyphs
+}
 
-	activeFont := font
+// fontRun is a part of the text drawn with the main font or with the fallback font.
+type fontRun struct {
+	font *Font
+	text string
+}
+
+// getFontRuns splits the text into the runs drawn with the fonts.
+// Each character is drawn with the first font in the chain that has the glyph or else
+// with the first font other than the active font. The spaces, the digits, the punctuation
+// and the combining marks stay with the active font if it has the glyph, and the invisible
+// characters like ZWJ stay with the characters around them.
+func getFontRuns(fonts []*Font, text string) []fontRun {
+	if len(fonts) == 1 {
+		return []fontRun{{fonts[0], text}}
+	}
+
+	var runs []fontRun
+	activeFont := fonts[0]
 	var buf strings.Builder
 	for _, ch := range text {
-		if activeFont.unicodeToGID[ch] == 0 {
-			width += activeFont.stringWidth(buf.String())
-			buf.Reset()
-			// Switch the active font
-			if activeFont == font {
-				activeFont = fallbackFont
-			} else {
-				activeFont = font
+		if !isDefaultIgnorable(ch) &&
+			!(activeFont.hasGlyph(ch) && unicode.In(ch, unicode.Common, unicode.Inherited)) {
+			if nextFont := getCoveringFont(fonts, activeFont, ch); nextFont != activeFont {
+				if buf.Len() > 0 {
+					runs = append(runs, fontRun{activeFont, buf.String()})
+				}
+				buf.Reset()
+				activeFont = nextFont
 			}
 		}
 		buf.WriteRune(ch)
 	}
-	width += activeFont.stringWidth(buf.String())
+	if buf.Len() > 0 {
+		runs = append(runs, fontRun{activeFont, buf.String()})
+	}
+	return runs
+}
 
-	return width
+// getCoveringFont returns the first font that has a glyph for the character
+// or the first font other than the active font when none of them has.
+func getCoveringFont(fonts []*Font, activeFont *Font, ch rune) *Font {
+	for _, f := range fonts {
+		if f.hasGlyph(ch) {
+			return f
+		}
+	}
+	if fonts[0] == activeFont {
+		return fonts[1]
+	}
+	return fonts[0]
 }
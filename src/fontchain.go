@@ -0,0 +1,106 @@
+package pdfjet
+
+/**
+ * fontchain.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"fmt"
+	"log"
+)
+
+// FontChain is an ordered list of fonts used to draw text that mixes scripts.
+// Each character is drawn with the first font in the chain that has a glyph for it.
+// The text components accept the chain with SetFontChain.
+type FontChain struct {
+	fonts []*Font
+}
+
+// NewFontChain creates a font chain from the fonts in the order they are tried.
+// The program exits if the fonts cannot be chained. Use NewFontChainErr to handle the error.
+func NewFontChain(fonts ...*Font) *FontChain {
+	chain, err := NewFontChainErr(fonts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return chain
+}
+
+// NewFontChainErr creates a font chain from the fonts in the order they are tried.
+// Returns an error if there are no fonts or if a core font or a CJK font is chained
+// with other fonts - their text is drawn with different encoding.
+func NewFontChainErr(fonts ...*Font) (*FontChain, error) {
+	if len(fonts) == 0 {
+		return nil, fmt.Errorf("pdfjet: the font chain has no fonts")
+	}
+	if len(fonts) > 1 {
+		for _, font := range fonts {
+			if font.isCoreFont || font.isCJK {
+				return nil, fmt.Errorf("pdfjet: the core font or CJK font %s can not be chained with other fonts", font.name)
+			}
+		}
+	}
+	return &FontChain{fonts: fonts}, nil
+}
+
+// GetFont returns the first font in the chain.
+func (chain *FontChain) GetFont() *Font {
+	return chain.fonts[0]
+}
+
+// GetFonts returns the fonts in the chain.
+func (chain *FontChain) GetFonts() []*Font {
+	return chain.fonts
+}
+
+// SetSize sets the size of all fonts in the chain.
+func (chain *FontChain) SetSize(fontSize float32) *FontChain {
+	for _, font := range chain.fonts {
+		font.SetSize(fontSize)
+	}
+	return chain
+}
+
+// StringWidth returns the width of the text drawn with the fonts of the chain.
+func (chain *FontChain) StringWidth(text string) float32 {
+	return getTextWidth(chain.fonts, text)
+}
+
+// getFonts returns the fonts used by the text components - the font chain if it is set
+// or else the font followed by the fallback font.
+func getFonts(chain *FontChain, font, fallbackFont *Font) []*Font {
+	if chain != nil {
+		return chain.fonts
+	}
+	return font.getFallbackChain(fallbackFont)
+}
+
+// getFallbackChain returns the font followed by the fallback font if they can be mixed.
+// The text drawn with core font or CJK font does not use the fallback font.
+func (font *Font) getFallbackChain(fallbackFont *Font) []*Font {
+	if fallbackFont == nil || fallbackFont == font ||
+		font.isCoreFont || font.isCJK || fallbackFont.isCoreFont || fallbackFont.isCJK {
+		return []*Font{font}
+	}
+	return []*Font{font, fallbackFont}
+}
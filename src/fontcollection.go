@@ -0,0 +1,187 @@
+package pdfjet
+
+/**
+ * fontcollection.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/edragoev1/pdfjet/src/content"
+)
+
+// NewFontFromCollection constructs font object from the face of TrueType or OpenType
+// font collection (.ttc or .otc). The faces are numbered from 0.
+// The program exits if the font cannot be loaded. Use NewFontFromCollectionErr to handle the error.
+func NewFontFromCollection(pdf *PDF, reader io.Reader, faceIndex int) *Font {
+	font, err := NewFontFromCollectionErr(pdf, reader, faceIndex)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// NewFontFromCollectionErr constructs font object from the face of TrueType or OpenType
+// font collection (.ttc or .otc). The face is extracted to a standalone font that is
+// embedded like the fonts loaded with NewFont. The font that is not a collection is
+// accepted as collection with one face.
+func NewFontFromCollectionErr(pdf *PDF, reader io.Reader, faceIndex int) (*Font, error) {
+	buf, err := content.GetFromReaderErr(reader)
+	if err != nil {
+		return nil, &IOError{Op: "read", Err: err}
+	}
+	face, err := extractCollectionFace(buf, faceIndex)
+	if err != nil {
+		return nil, err
+	}
+	return NewFontErr(pdf, bytes.NewReader(face))
+}
+
+// NewFontFromCollectionFile constructs font object from the face of .ttc or .otc file.
+// The program exits if the font cannot be loaded. Use NewFontFromCollectionFileErr to handle the error.
+func NewFontFromCollectionFile(pdf *PDF, filePath string, faceIndex int) *Font {
+	font, err := NewFontFromCollectionFileErr(pdf, filePath, faceIndex)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// NewFontFromCollectionFileErr constructs font object from the face of .ttc or .otc file.
+func NewFontFromCollectionFileErr(pdf *PDF, filePath string, faceIndex int) (*Font, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
+	}
+	defer f.Close()
+	return NewFontFromCollectionErr(pdf, bufio.NewReader(f), faceIndex)
+}
+
+// GetCollectionFaceNames returns the full names of the faces in the font collection
+// in the order of the face indexes, for example "Noto Sans CJK JP Regular".
+// The PostScript name is returned for the faces without full name.
+func GetCollectionFaceNames(reader io.Reader) ([]string, error) {
+	buf, err := content.GetFromReaderErr(reader)
+	if err != nil {
+		return nil, &IOError{Op: "read", Err: err}
+	}
+	return getCollectionFaceNames(buf)
+}
+
+// GetCollectionFaceNamesFromFile returns the full names of the faces in .ttc or .otc file.
+func GetCollectionFaceNamesFromFile(filePath string) ([]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
+	}
+	defer f.Close()
+	return GetCollectionFaceNames(bufio.NewReader(f))
+}
+
+func getCollectionFaceNames(buf []byte) (_ []string, err error) {
+	defer recoverMalformedInput("font collection", &err)
+
+	offsets, err := getCollectionFaceOffsets(buf)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(offsets))
+	for i, offset := range offsets {
+		tables := getCollectionFaceTables(buf, offset)
+		names[i] = getFontNameString(tables["name"], 4)
+		if names[i] == "" {
+			names[i] = getFontNameString(tables["name"], 6)
+		}
+	}
+	return names, nil
+}
+
+// getCollectionFaceOffsets returns the offsets of the table directories of the faces.
+func getCollectionFaceOffsets(buf []byte) ([]int, error) {
+	if len(buf) < 12 {
+		return nil, malformed("font collection", "file is too short")
+	}
+	if string(buf[0:4]) != "ttcf" {
+		return []int{0}, nil // Single font
+	}
+	numFonts := u32(buf, 8)
+	if numFonts == 0 || 12+4*numFonts > len(buf) {
+		return nil, malformed("font collection", "invalid number of fonts %d", numFonts)
+	}
+	offsets := make([]int, numFonts)
+	for i := range offsets {
+		offsets[i] = u32(buf, 12+4*i)
+	}
+	return offsets, nil
+}
+
+// getCollectionFaceTables returns the tables of the face. The table offsets
+// in the collection are relative to the beginning of the file.
+func getCollectionFaceTables(buf []byte, offset int) map[string][]byte {
+	tables := make(map[string][]byte)
+	numTables := u16(buf, offset+4)
+	for i := 0; i < numTables; i++ {
+		entry := buf[offset+12+16*i:]
+		tableOffset := int(binary.BigEndian.Uint32(entry[8:]))
+		length := int(binary.BigEndian.Uint32(entry[12:]))
+		tables[string(entry[0:4])] = buf[tableOffset : tableOffset+length]
+	}
+	return tables
+}
+
+// extractCollectionFace returns the face of the font collection as standalone font file.
+func extractCollectionFace(buf []byte, faceIndex int) (_ []byte, err error) {
+	defer recoverMalformedInput("font collection", &err)
+
+	offsets, err := getCollectionFaceOffsets(buf)
+	if err != nil {
+		return nil, err
+	}
+	if faceIndex < 0 || faceIndex >= len(offsets) {
+		return nil, fmt.Errorf(
+			"pdfjet: face index %d is out of range 0 to %d", faceIndex, len(offsets)-1)
+	}
+	if string(buf[0:4]) != "ttcf" {
+		return buf, nil
+	}
+	tables := getCollectionFaceTables(buf, offsets[faceIndex])
+	if tables["head"] == nil {
+		return nil, malformed("font collection", "face %d has no head table", faceIndex)
+	}
+	head := append([]byte(nil), tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0) // checkSumAdjustment
+	tables["head"] = head
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return writeTrueType(tables, names, head), nil
+}
@@ -27,6 +27,7 @@ SOFTWARE.
 import (
 	"bytes"
 	"io"
+	"log"
 	"math"
 	"strconv"
 	"strings"
@@ -37,8 +38,36 @@ import (
 
 // FontStream1 is used to add stream fonts to the PDF.
 func FontStream1(pdf *PDF, font *Font, reader io.Reader) {
-	getFontData(font, reader)
-	embedFontFile(pdf, font, reader)
+	if err := fontStream1(pdf, font, reader); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fontStream1(pdf *PDF, font *Font, reader io.Reader) error {
+	if err := getFontData(font, reader); err != nil {
+		return err
+	}
+	buf := make([]byte, font.compressedSize)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return malformedWrap("font stream", "truncated font program", err)
+	}
+	program, err := decompressor.InflateErr(buf)
+	if err != nil {
+		return malformedWrap("font stream", "bad font program", err)
+	}
+	if !font.cff {
+		// The font is drawn without shaping when the layout tables cannot be read.
+		if layout, err := newOTLayout(program); err == nil {
+			font.layout = layout
+		}
+	}
+	if pdf.usesFontSubsetting() {
+		registerFontSubset(pdf, font, program)
+		return nil
+	}
+	if err := embedFontFile(pdf, font, bytes.NewReader(buf)); err != nil {
+		return err
+	}
 	addFontDescriptorObject(pdf, font)
 	addCIDFontDictionaryObject(pdf, font)
 	addToUnicodeCMapObject(pdf, font)
@@ -63,17 +92,30 @@ func FontStream1(pdf *PDF, font *Font, reader io.Reader) {
 
 	font.objNumber = pdf.getObjNumber()
 	pdf.fonts = append(pdf.fonts, font)
+	return nil
 }
 
-func embedFontFile(pdf *PDF, font *Font, reader io.Reader) {
+func embedFontFile(pdf *PDF, font *Font, reader io.Reader) error {
 	// Check if the font file is already embedded
 	for _, f := range pdf.fonts {
 		if f.fileObjNumber != 0 && f.name == font.name {
 			font.fileObjNumber = f.fileObjNumber
-			return
+			return nil
 		}
 	}
 
+	compressedSize := font.compressedSize
+	if pdf.encryption != nil {
+		// The encrypted stream length is not known until we read the whole stream
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return &IOError{Op: "read", Err: err}
+		}
+		data = pdf.encrypt(data)
+		compressedSize = len(data)
+		reader = bytes.NewReader(data)
+	}
+
 	metadataObjNumber := pdf.addMetadataObject(font.info, true)
 
 	pdf.newobj()
@@ -88,7 +130,7 @@ func embedFontFile(pdf *PDF, font *Font, reader io.Reader) {
 	}
 	pdf.appendString("/Filter /FlateDecode\n")
 	pdf.appendString("/Length ")
-	pdf.appendInteger(font.compressedSize)
+	pdf.appendInteger(compressedSize)
 	pdf.appendString("\n")
 
 	if !font.cff {
@@ -107,12 +149,16 @@ func embedFontFile(pdf *PDF, font *Font, reader io.Reader) {
 		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return &IOError{Op: "read", Err: err}
+		}
 	}
 
 	pdf.appendString("\nendstream\n")
 	pdf.endobj()
 
 	font.fileObjNumber = pdf.getObjNumber()
+	return nil
 }
 
 func addFontDescriptorObject(pdf *PDF, font *Font) {
@@ -210,14 +256,15 @@ func addToUnicodeCMapObject(pdf *PDF, font *Font) {
 	sb.WriteString("CMapName currentdict /CMap defineresource pop\n")
 	sb.WriteString("end\nend")
 
+	cmap := pdf.encrypt([]byte(sb.String()))
 	pdf.newobj()
 	pdf.appendString("<<\n")
 	pdf.appendString("/Length ")
-	pdf.appendInteger(sb.Len())
+	pdf.appendInteger(len(cmap))
 	pdf.appendString("\n")
 	pdf.appendString(">>\n")
 	pdf.appendString("stream\n")
-	pdf.appendString(sb.String())
+	pdf.appendByteArray(cmap)
 	pdf.appendString("\nendstream\n")
 	pdf.endobj()
 
@@ -243,7 +290,11 @@ func addCIDFontDictionaryObject(pdf *PDF, font *Font) {
 	pdf.appendString("/BaseFont /")
 	pdf.appendString(font.name)
 	pdf.appendByteArray(token.Newline)
-	pdf.appendString("/CIDSystemInfo <</Registry (Adobe) /Ordering (Identity) /Supplement 0>>\n")
+	pdf.appendString("/CIDSystemInfo <</Registry ")
+	pdf.appendLiteral("Adobe")
+	pdf.appendString(" /Ordering ")
+	pdf.appendLiteral("Identity")
+	pdf.appendString(" /Supplement 0>>\n")
 	pdf.appendString("/FontDescriptor ")
 	pdf.appendInteger(font.fontDescriptorObjNumber)
 	pdf.appendByteArray(token.ObjRef)
@@ -277,22 +328,33 @@ func writeListTo(sb *strings.Builder, list []string) {
 	sb.WriteString("endbfchar\n")
 }
 
-func getFontData(font *Font, reader io.Reader) {
+func getFontData(font *Font, reader io.Reader) (err error) {
+	defer recoverMalformedInput("font stream", &err)
+
 	length := int(getUint8(reader))
 	fontName := make([]byte, length)
-	io.ReadFull(reader, fontName)
+	if _, err := io.ReadFull(reader, fontName); err != nil {
+		return malformedWrap("font stream", "truncated font name", err)
+	}
 	font.name = string(fontName)
 
 	length = int(getUint24(reader))
 	fontInfo := make([]byte, length)
-	io.ReadFull(reader, fontInfo)
+	if _, err := io.ReadFull(reader, fontInfo); err != nil {
+		return malformedWrap("font stream", "truncated font info", err)
+	}
 	font.info = string(fontInfo)
 
 	length = int(getUint32(reader))
 	buf := make([]byte, length)
-	io.ReadFull(reader, buf)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return malformedWrap("font stream", "truncated font metrics", err)
+	}
 
-	inflated := decompressor.Inflate(buf)
+	inflated, err := decompressor.InflateErr(buf)
+	if err != nil {
+		return malformedWrap("font stream", "bad font metrics", err)
+	}
 	r2 := bytes.NewReader(inflated)
 
 	font.unitsPerEm = int(getInt32(r2))
@@ -327,4 +389,5 @@ func getFontData(font *Font, reader io.Reader) {
 
 	font.uncompressedSize = int(getUint32(reader))
 	font.compressedSize = int(getUint32(reader))
+	return nil
 }
@@ -26,6 +26,7 @@ SOFTWARE.
 
 import (
 	"io"
+	"log"
 	"math"
 	"strconv"
 	"strings"
@@ -33,8 +34,18 @@ import (
 
 // FontStream2 constructs font object and adds it to the PDF objects slice.
 func FontStream2(objects *[]*PDFobj, font *Font, reader io.Reader) {
-	getFontData(font, reader)
-	embedFontFile2(objects, font, reader)
+	if err := fontStream2(objects, font, reader); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fontStream2(objects *[]*PDFobj, font *Font, reader io.Reader) error {
+	if err := getFontData(font, reader); err != nil {
+		return err
+	}
+	if err := embedFontFile2(objects, font, reader); err != nil {
+		return err
+	}
 	addFontDescriptorObject2(objects, font)
 	addCIDFontDictionaryObject2(objects, font)
 	addToUnicodeCMapObject2(objects, font)
@@ -64,6 +75,7 @@ func FontStream2(objects *[]*PDFobj, font *Font, reader io.Reader) {
 	obj.number = len(*objects) + 1
 	*objects = append(*objects, obj)
 	font.objNumber = obj.number
+	return nil
 }
 
 func addMetadataObject2(objects *[]*PDFobj, font *Font) int {
@@ -103,7 +115,7 @@ func addMetadataObject2(objects *[]*PDFobj, font *Font) int {
 	return obj.number
 }
 
-func embedFontFile2(objects *[]*PDFobj, font *Font, reader io.Reader) {
+func embedFontFile2(objects *[]*PDFobj, font *Font, reader io.Reader) error {
 	metadataObjNumber := addMetadataObject2(objects, font)
 
 	obj := NewPDFobj()
@@ -133,12 +145,16 @@ func embedFontFile2(objects *[]*PDFobj, font *Font, reader io.Reader) {
 		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return &IOError{Op: "read", Err: err}
+		}
 	}
 
 	obj.SetStream(buf1)
 	obj.number = len(*objects) + 1
 	*objects = append(*objects, obj)
 	font.fileObjNumber = obj.number
+	return nil
 }
 
 func addFontDescriptorObject2(objects *[]*PDFobj, font *Font) {
@@ -0,0 +1,587 @@
+package pdfjet
+
+/**
+ * fontsubset.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/edragoev1/pdfjet/src/compressor"
+)
+
+// fontSubset is the embedded font program shared by all fonts with the same name.
+// The font objects are written by Complete when the used glyphs are known.
+// The glyph IDs are not changed - the unused glyphs are removed from the font program,
+// so the content streams, the CIDToGIDMap and the widths stay the same.
+type fontSubset struct {
+	name                    string // The font name without the subset tag
+	program                 []byte // TrueType font file or bare CFF font
+	cff                     bool
+	used                    []bool         // Indexed by glyph ID
+	toUnicode               map[int]string // The text of the used glyphs not in cmap - ligatures and alternates
+	runes                   map[int]rune   // The code points drawn with the used glyphs
+	codePoints              []int          // The lowest code point of each glyph in cmap
+	fonts                   []*Font
+	metadataObjNumber       int
+	fileObjNumber           int
+	fontDescriptorObjNumber int
+	cidFontDictObjNumber    int
+	toUnicodeCMapObjNumber  int
+}
+
+// SetFontSubsetting enables or disables the subsetting of the embedded OpenType and TrueType fonts.
+// When enabled - this is the default - only the glyphs used on the pages are embedded
+// and the font name is prefixed with subset tag, for example "EOODIA+NotoSans-Regular".
+// Must be called before the fonts are created.
+func (pdf *PDF) SetFontSubsetting(subset bool) {
+	pdf.noFontSubsetting = !subset
+}
+
+// usesFontSubsetting returns true if the fonts created from now on are subset.
+// The fonts added to incremental update are written right away.
+func (pdf *PDF) usesFontSubsetting() bool {
+	return !pdf.noFontSubsetting && !pdf.incremental
+}
+
+// registerFontSubset reserves the object numbers of the font.
+// The font objects are written by addFontSubsets.
+func registerFontSubset(pdf *PDF, font *Font, program []byte) {
+	for _, f := range pdf.fonts {
+		if f.subset != nil && f.name == font.name {
+			font.subset = f.subset
+			break
+		}
+	}
+	if font.subset == nil {
+		subset := new(fontSubset)
+		subset.name = font.name
+		subset.program = program
+		subset.cff = font.cff
+		subset.used = make([]bool, 0x10000)
+		subset.used[0] = true // .notdef
+		subset.metadataObjNumber = pdf.addMetadataObject(font.info, true)
+		subset.fileObjNumber = pdf.reserveObjNumber()
+		subset.fontDescriptorObjNumber = pdf.reserveObjNumber()
+		subset.cidFontDictObjNumber = pdf.reserveObjNumber()
+		subset.toUnicodeCMapObjNumber = pdf.reserveObjNumber()
+		pdf.fontSubsets = append(pdf.fontSubsets, subset)
+		font.subset = subset
+	}
+	font.fileObjNumber = font.subset.fileObjNumber
+	font.fontDescriptorObjNumber = font.subset.fontDescriptorObjNumber
+	font.cidFontDictObjNumber = font.subset.cidFontDictObjNumber
+	font.toUnicodeCMapObjNumber = font.subset.toUnicodeCMapObjNumber
+	font.objNumber = pdf.reserveObjNumber()
+	font.subset.fonts = append(font.subset.fonts, font)
+	pdf.fonts = append(pdf.fonts, font)
+}
+
+// addFontSubsets writes the font objects of all subset fonts.
+func (pdf *PDF) addFontSubsets() {
+	for _, subset := range pdf.fontSubsets {
+		pdf.addFontSubset(subset)
+	}
+	pdf.fontSubsets = nil
+}
+
+func (pdf *PDF) addFontSubset(subset *fontSubset) {
+	font := subset.fonts[0]
+	var program []byte
+	var err error
+	if subset.cff {
+		program, err = subsetCFF(subset.program, subset.used)
+	} else {
+		program, err = subsetTrueType(subset.program, subset.used)
+	}
+	fontName := subset.name
+	if err == nil {
+		fontName = subset.getTag() + "+" + subset.name
+	} else {
+		program = subset.program // Embed the whole font
+	}
+	subset.program = nil
+
+	// Font file
+	stream := pdf.encrypt(compressor.Deflate(program))
+	pdf.newobjReserved(subset.fileObjNumber)
+	pdf.appendString("<<\n")
+	if subset.cff {
+		pdf.appendString("/Subtype /CIDFontType0C\n")
+	}
+	pdf.appendString("/Filter /FlateDecode\n")
+	pdf.appendString("/Length ")
+	pdf.appendInteger(len(stream))
+	pdf.appendString("\n")
+	if !subset.cff {
+		pdf.appendString("/Length1 ")
+		pdf.appendInteger(len(program))
+		pdf.appendString("\n")
+	}
+	pdf.appendString("/Metadata ")
+	pdf.appendInteger(subset.metadataObjNumber)
+	pdf.appendString(" 0 R\n")
+	pdf.appendString(">>\n")
+	pdf.appendString("stream\n")
+	pdf.appendByteArray(stream)
+	pdf.appendString("\nendstream\n")
+	pdf.endobj()
+
+	// Font descriptor
+	pdf.newobjReserved(subset.fontDescriptorObjNumber)
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /FontDescriptor\n")
+	pdf.appendString("/FontName /")
+	pdf.appendString(fontName)
+	pdf.appendString("\n")
+	if subset.cff {
+		pdf.appendString("/FontFile3 ")
+	} else {
+		pdf.appendString("/FontFile2 ")
+	}
+	pdf.appendInteger(subset.fileObjNumber)
+	pdf.appendString(" 0 R\n")
+	pdf.appendString("/Flags 32\n")
+	pdf.appendString("/FontBBox [")
+	pdf.appendInteger(int(font.bBoxLLx))
+	pdf.appendString(" ")
+	pdf.appendInteger(int(font.bBoxLLy))
+	pdf.appendString(" ")
+	pdf.appendInteger(int(font.bBoxURx))
+	pdf.appendString(" ")
+	pdf.appendInteger(int(font.bBoxURy))
+	pdf.appendString("]\n")
+	pdf.appendString("/Ascent ")
+	pdf.appendInteger(int(font.fontAscent))
+	pdf.appendString("\n")
+	pdf.appendString("/Descent ")
+	pdf.appendInteger(int(font.fontDescent))
+	pdf.appendString("\n")
+	pdf.appendString("/ItalicAngle 0\n")
+	pdf.appendString("/CapHeight ")
+	pdf.appendInteger(int(font.capHeight))
+	pdf.appendString("\n")
+	pdf.appendString("/StemV 79\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+
+	// CIDFont dictionary with the widths of the used glyphs only
+	k := float32(1000.0) / float32(font.unitsPerEm)
+	pdf.newobjReserved(subset.cidFontDictObjNumber)
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /Font\n")
+	if subset.cff {
+		pdf.appendString("/Subtype /CIDFontType0\n")
+	} else {
+		pdf.appendString("/Subtype /CIDFontType2\n")
+	}
+	pdf.appendString("/BaseFont /")
+	pdf.appendString(fontName)
+	pdf.appendString("\n")
+	pdf.appendString("/CIDSystemInfo <</Registry ")
+	pdf.appendLiteral("Adobe")
+	pdf.appendString(" /Ordering ")
+	pdf.appendLiteral("Identity")
+	pdf.appendString(" /Supplement 0>>\n")
+	pdf.appendString("/FontDescriptor ")
+	pdf.appendInteger(subset.fontDescriptorObjNumber)
+	pdf.appendString(" 0 R\n")
+	pdf.appendString("/DW ")
+	pdf.appendInteger(int(math.Round(float64(k * float32(font.getAdvanceWidth(0))))))
+	pdf.appendString("\n")
+	pdf.appendString("/W [")
+	for gid := 0; gid < len(subset.used); gid++ {
+		if !subset.used[gid] {
+			continue
+		}
+		pdf.appendInteger(gid)
+		pdf.appendString("[")
+		for ; gid < len(subset.used) && subset.used[gid]; gid++ {
+			pdf.appendInteger(int(math.Round(float64(k * float32(font.getAdvanceWidth(gid))))))
+			pdf.appendString(" ")
+		}
+		pdf.appendString("]\n")
+	}
+	pdf.appendString("]\n")
+	pdf.appendString("/CIDToGIDMap /Identity\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+
+	// ToUnicode CMap for the used glyphs
+	cmap := pdf.encrypt([]byte(subset.getToUnicodeCMap(font.unicodeToGID)))
+	pdf.newobjReserved(subset.toUnicodeCMapObjNumber)
+	pdf.appendString("<<\n")
+	pdf.appendString("/Length ")
+	pdf.appendInteger(len(cmap))
+	pdf.appendString("\n")
+	pdf.appendString(">>\n")
+	pdf.appendString("stream\n")
+	pdf.appendByteArray(cmap)
+	pdf.appendString("\nendstream\n")
+	pdf.endobj()
+
+	// Type0 font dictionaries
+	for _, font := range subset.fonts {
+		pdf.newobjReserved(font.objNumber)
+		pdf.appendString("<<\n")
+		pdf.appendString("/Type /Font\n")
+		pdf.appendString("/Subtype /Type0\n")
+		pdf.appendString("/BaseFont /")
+		pdf.appendString(fontName)
+		pdf.appendString("\n")
+		pdf.appendString("/Encoding /Identity-H\n")
+		pdf.appendString("/DescendantFonts [")
+		pdf.appendInteger(subset.cidFontDictObjNumber)
+		pdf.appendString(" 0 R]\n")
+		pdf.appendString("/ToUnicode ")
+		pdf.appendInteger(subset.toUnicodeCMapObjNumber)
+		pdf.appendString(" 0 R\n")
+		pdf.appendString(">>\n")
+		pdf.endobj()
+	}
+}
+
+// getAdvanceWidth returns the advance width of the glyph.
+// The glyphs after the last hmtx entry have the same width as the last entry.
+func (font *Font) getAdvanceWidth(gid int) uint16 {
+	if len(font.advanceWidth) == 0 {
+		return 0
+	}
+	if gid >= len(font.advanceWidth) {
+		gid = len(font.advanceWidth) - 1
+	}
+	return font.advanceWidth[gid]
+}
+
+// addGlyph marks the shaped glyph as used.
+// The glyphs that are not in cmap are mapped to the text they are made from.
+// The first glyph of multiple substitution gets the text of the cluster
+// and the other glyphs are mapped to empty string.
+func (subset *fontSubset) addGlyph(font *Font, g otGlyph, runes []rune) {
+	subset.used[g.gid] = true
+	if g.cluster+g.length > len(runes) {
+		return
+	}
+	text := runes[g.cluster : g.cluster+g.length]
+	if len(text) == 1 && font.getGlyphID(text[0]) == g.gid {
+		subset.addRune(font, g.gid, text[0])
+		return
+	}
+	if subset.toUnicode == nil {
+		subset.toUnicode = make(map[int]string)
+	}
+	if old, ok := subset.toUnicode[g.gid]; !ok || (old == "" && len(text) > 0) {
+		subset.toUnicode[g.gid] = string(text)
+	}
+}
+
+// addRune marks the glyph as used and remembers the first code point drawn with it.
+// The CJK fonts map the same glyph to the unified ideograph and to the radicals
+// or the compatibility ideographs, so the ToUnicode CMap uses the code point from the text.
+func (subset *fontSubset) addRune(font *Font, gid int, c rune) {
+	subset.used[gid] = true
+	if gid == 0 || !font.hasGlyph(c) || font.getGlyphID(c) != gid {
+		return
+	}
+	if subset.runes == nil {
+		subset.runes = make(map[int]rune)
+	}
+	if _, ok := subset.runes[gid]; !ok {
+		subset.runes[gid] = c
+	}
+}
+
+// getCodePoints returns the lowest code point of each glyph in cmap or 0 if the glyph is not in cmap.
+// The control characters are used only when the glyph has no other code point.
+func (subset *fontSubset) getCodePoints(unicodeToGID []int) []int {
+	if subset.codePoints == nil {
+		subset.codePoints = make([]int, len(subset.used))
+		for i := len(unicodeToGID) - 1; i >= 0; i-- {
+			gid := unicodeToGID[i]
+			if gid > 0 && gid < len(subset.used) && (subset.codePoints[gid] == 0 || i >= 0x20) {
+				subset.codePoints[gid] = i
+			}
+		}
+	}
+	return subset.codePoints
+}
+
+// mapsGlyphTo returns true if the ToUnicode CMap of the font maps the glyph to the text.
+// The glyph shared by several characters is mapped to the first character drawn with it.
+func (font *Font) mapsGlyphTo(gid int, text []rune) bool {
+	subset := font.subset
+	if len(text) == 1 && font.hasGlyph(text[0]) && font.getGlyphID(text[0]) == gid {
+		return subset == nil || subset.runes[gid] == text[0]
+	}
+	if subset == nil || len(text) == 0 || subset.toUnicode[gid] != string(text) {
+		return false
+	}
+	if _, ok := subset.runes[gid]; ok {
+		return false
+	}
+	cid := subset.getCodePoints(font.unicodeToGID)[gid]
+	return cid == 0 || isPresentationForm(cid)
+}
+
+// getTag returns six uppercase letters computed from the font name and the used glyphs.
+func (subset *fontSubset) getTag() string {
+	h := fnv.New64a()
+	h.Write([]byte(subset.name))
+	buf := make([]byte, 2)
+	for gid, used := range subset.used {
+		if used {
+			binary.BigEndian.PutUint16(buf, uint16(gid))
+			h.Write(buf)
+		}
+	}
+	sum := h.Sum64()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = byte('A' + sum%26)
+		sum /= 26
+	}
+	return string(tag)
+}
+
+// getToUnicodeCMap returns the ToUnicode CMap for the used glyphs.
+func (subset *fontSubset) getToUnicodeCMap(unicodeToGID []int) string {
+	var sb strings.Builder
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n")
+	sb.WriteString("12 dict begin\n")
+	sb.WriteString("begincmap\n")
+	sb.WriteString("/CIDSystemInfo <</Registry (Adobe) /Ordering (Identity) /Supplement 0>> def\n")
+	sb.WriteString("/CMapName /Adobe-Identity def\n")
+	sb.WriteString("/CMapType 2 def\n")
+
+	sb.WriteString("1 begincodespacerange\n")
+	sb.WriteString("<0000> <FFFF>\n")
+	sb.WriteString("endcodespacerange\n")
+
+	// Map every glyph to the code point drawn with it, otherwise to the lowest code point.
+	cids := append([]int(nil), subset.getCodePoints(unicodeToGID)...)
+	for gid, c := range subset.runes {
+		cids[gid] = int(c)
+	}
+	list := make([]string, 0)
+	for gid, cid := range cids {
+		if !subset.used[gid] {
+			continue
+		}
+		// The ligatures and the Arabic forms are mapped to the text they are made from.
+		text, ok := subset.toUnicode[gid]
+		if cid != 0 && (!ok || !isPresentationForm(cid)) {
+			list = append(list, "<"+toHexString(gid)+"> <"+toHexString(cid)+">\n")
+		} else if ok {
+			list = append(list, "<"+toHexString(gid)+"> <"+toUTF16HexString(text)+">\n")
+		}
+		if len(list) == 100 {
+			writeListTo(&sb, list)
+			list = list[:0]
+		}
+	}
+	if len(list) > 0 {
+		writeListTo(&sb, list)
+	}
+
+	sb.WriteString("endcmap\n")
+	sb.WriteString("CMapName currentdict /CMap defineresource pop\n")
+	sb.WriteString("end\nend")
+	return sb.String()
+}
+
+// isPresentationForm returns true for the compatibility characters of the ligatures and the Arabic forms.
+func isPresentationForm(code int) bool {
+	return (code >= 0xFB00 && code <= 0xFDFF) || (code >= 0xFE70 && code <= 0xFEFF)
+}
+
+// toUTF16HexString returns the text encoded as UTF-16BE hex string.
+func toUTF16HexString(text string) string {
+	var sb strings.Builder
+	for _, code := range utf16.Encode([]rune(text)) {
+		sb.WriteString(toHexString(int(code)))
+	}
+	return sb.String()
+}
+
+// The tables required in TrueType font program embedded in PDF.
+var trueTypeSubsetTables = []string{"cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "prep"}
+
+// subsetTrueType returns the TrueType font with the unused glyphs removed from the glyf table.
+// The composite glyph components are added to the used glyphs.
+func subsetTrueType(buf []byte, used []bool) (_ []byte, err error) {
+	defer recoverMalformedInput("TrueType font", &err)
+
+	tables := getSfntTables(buf)
+	head, maxp, loca, glyf := tables["head"], tables["maxp"], tables["loca"], tables["glyf"]
+	if head != nil && maxp != nil && loca == nil && glyf == nil && (tables["CBDT"] != nil || tables["sbix"] != nil) {
+		// The glyphs of the bitmap only font get empty outlines.
+		glyf = []byte{}
+		loca = make([]byte, 4*(int(binary.BigEndian.Uint16(maxp[4:]))+1))
+	}
+	if head == nil || maxp == nil || loca == nil || glyf == nil {
+		return nil, malformed("TrueType font", "missing glyf, loca, head or maxp table")
+	}
+	longOffsets := binary.BigEndian.Uint16(head[50:]) == 1
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	offsets := make([]int, numGlyphs+1)
+	for i := range offsets {
+		if longOffsets {
+			offsets[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		} else {
+			offsets[i] = 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+		}
+	}
+
+	// Add the components of the used composite glyphs.
+	queue := make([]int, 0)
+	for gid := 0; gid < numGlyphs && gid < len(used); gid++ {
+		if used[gid] {
+			queue = append(queue, gid)
+		}
+	}
+	for len(queue) > 0 {
+		gid := queue[0]
+		queue = queue[1:]
+		glyph := glyf[offsets[gid]:offsets[gid+1]]
+		if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+			continue
+		}
+		for i := 10; ; {
+			flags := binary.BigEndian.Uint16(glyph[i:])
+			component := int(binary.BigEndian.Uint16(glyph[i+2:]))
+			if component < numGlyphs && component < len(used) && !used[component] {
+				used[component] = true
+				queue = append(queue, component)
+			}
+			i += 4
+			if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+				i += 4
+			} else {
+				i += 2
+			}
+			if flags&0x0008 != 0 { // WE_HAVE_A_SCALE
+				i += 2
+			} else if flags&0x0040 != 0 { // WE_HAVE_AN_X_AND_Y_SCALE
+				i += 4
+			} else if flags&0x0080 != 0 { // WE_HAVE_A_TWO_BY_TWO
+				i += 8
+			}
+			if flags&0x0020 == 0 { // MORE_COMPONENTS
+				break
+			}
+		}
+	}
+
+	newGlyf := make([]byte, 0, len(glyf)/4)
+	newLoca := make([]byte, 0, len(loca))
+	for gid := 0; gid <= numGlyphs; gid++ {
+		if longOffsets {
+			newLoca = binary.BigEndian.AppendUint32(newLoca, uint32(len(newGlyf)))
+		} else {
+			newLoca = binary.BigEndian.AppendUint16(newLoca, uint16(len(newGlyf)/2))
+		}
+		if gid < numGlyphs && gid < len(used) && used[gid] {
+			newGlyf = append(newGlyf, glyf[offsets[gid]:offsets[gid+1]]...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	tables["glyf"] = newGlyf
+	tables["loca"] = newLoca
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint32(newHead[8:], 0) // checkSumAdjustment
+	tables["head"] = newHead
+
+	names := make([]string, 0, len(trueTypeSubsetTables))
+	for _, name := range trueTypeSubsetTables {
+		if tables[name] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return writeTrueType(tables, names, newHead), nil
+}
+
+// writeTrueType writes the font file with the specified tables.
+// The fonts with CFF table are written as OpenType fonts with CFF outlines.
+// The head table checkSumAdjustment is updated in place.
+func writeTrueType(tables map[string][]byte, names []string, head []byte) []byte {
+	numTables := len(names)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	out := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(out, 0x00010000)
+	if tables["CFF "] != nil {
+		binary.BigEndian.PutUint32(out, 0x4F54544F) // OTTO
+	}
+	binary.BigEndian.PutUint16(out[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(out[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(out[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(out[10:], uint16(16*numTables-searchRange))
+	headOffset := 0
+	for i, name := range names {
+		table := tables[name]
+		if name == "head" {
+			headOffset = len(out)
+		}
+		entry := out[12+16*i:]
+		copy(entry, name)
+		binary.BigEndian.PutUint32(entry[4:], tableChecksum(table))
+		binary.BigEndian.PutUint32(entry[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(entry[12:], uint32(len(table)))
+		out = append(out, table...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	if headOffset != 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-tableChecksum(out))
+	}
+	return out
+}
+
+// tableChecksum returns the sum of the table as uint32 values.
+func tableChecksum(table []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(table); i += 4 {
+		var value uint32
+		for j := 0; j < 4; j++ {
+			value <<= 8
+			if i+j < len(table) {
+				value |= uint32(table[i+j])
+			}
+		}
+		sum += value
+	}
+	return sum
+}
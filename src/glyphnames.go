@@ -0,0 +1,510 @@
+package pdfjet
+
+/**
+ * glyphnames.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// winAnsiEncoding maps the WinAnsiEncoding codes to Unicode.
+var winAnsiEncoding = [256]rune{
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0020, 0x0021, 0x0022, 0x0023, 0x0024, 0x0025, 0x0026, 0x0027,
+	0x0028, 0x0029, 0x002A, 0x002B, 0x002C, 0x002D, 0x002E, 0x002F,
+	0x0030, 0x0031, 0x0032, 0x0033, 0x0034, 0x0035, 0x0036, 0x0037,
+	0x0038, 0x0039, 0x003A, 0x003B, 0x003C, 0x003D, 0x003E, 0x003F,
+	0x0040, 0x0041, 0x0042, 0x0043, 0x0044, 0x0045, 0x0046, 0x0047,
+	0x0048, 0x0049, 0x004A, 0x004B, 0x004C, 0x004D, 0x004E, 0x004F,
+	0x0050, 0x0051, 0x0052, 0x0053, 0x0054, 0x0055, 0x0056, 0x0057,
+	0x0058, 0x0059, 0x005A, 0x005B, 0x005C, 0x005D, 0x005E, 0x005F,
+	0x0060, 0x0061, 0x0062, 0x0063, 0x0064, 0x0065, 0x0066, 0x0067,
+	0x0068, 0x0069, 0x006A, 0x006B, 0x006C, 0x006D, 0x006E, 0x006F,
+	0x0070, 0x0071, 0x0072, 0x0073, 0x0074, 0x0075, 0x0076, 0x0077,
+	0x0078, 0x0079, 0x007A, 0x007B, 0x007C, 0x007D, 0x007E, 0x0000,
+	0x20AC, 0x0000, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x0000, 0x017D, 0x0000,
+	0x0000, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x0000, 0x017E, 0x0178,
+	0x00A0, 0x00A1, 0x00A2, 0x00A3, 0x00A4, 0x00A5, 0x00A6, 0x00A7,
+	0x00A8, 0x00A9, 0x00AA, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x00AF,
+	0x00B0, 0x00B1, 0x00B2, 0x00B3, 0x00B4, 0x00B5, 0x00B6, 0x00B7,
+	0x00B8, 0x00B9, 0x00BA, 0x00BB, 0x00BC, 0x00BD, 0x00BE, 0x00BF,
+	0x00C0, 0x00C1, 0x00C2, 0x00C3, 0x00C4, 0x00C5, 0x00C6, 0x00C7,
+	0x00C8, 0x00C9, 0x00CA, 0x00CB, 0x00CC, 0x00CD, 0x00CE, 0x00CF,
+	0x00D0, 0x00D1, 0x00D2, 0x00D3, 0x00D4, 0x00D5, 0x00D6, 0x00D7,
+	0x00D8, 0x00D9, 0x00DA, 0x00DB, 0x00DC, 0x00DD, 0x00DE, 0x00DF,
+	0x00E0, 0x00E1, 0x00E2, 0x00E3, 0x00E4, 0x00E5, 0x00E6, 0x00E7,
+	0x00E8, 0x00E9, 0x00EA, 0x00EB, 0x00EC, 0x00ED, 0x00EE, 0x00EF,
+	0x00F0, 0x00F1, 0x00F2, 0x00F3, 0x00F4, 0x00F5, 0x00F6, 0x00F7,
+	0x00F8, 0x00F9, 0x00FA, 0x00FB, 0x00FC, 0x00FD, 0x00FE, 0x00FF,
+}
+
+// macRomanEncoding maps the MacRomanEncoding codes to Unicode.
+var macRomanEncoding = [256]rune{
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0020, 0x0021, 0x0022, 0x0023, 0x0024, 0x0025, 0x0026, 0x0027,
+	0x0028, 0x0029, 0x002A, 0x002B, 0x002C, 0x002D, 0x002E, 0x002F,
+	0x0030, 0x0031, 0x0032, 0x0033, 0x0034, 0x0035, 0x0036, 0x0037,
+	0x0038, 0x0039, 0x003A, 0x003B, 0x003C, 0x003D, 0x003E, 0x003F,
+	0x0040, 0x0041, 0x0042, 0x0043, 0x0044, 0x0045, 0x0046, 0x0047,
+	0x0048, 0x0049, 0x004A, 0x004B, 0x004C, 0x004D, 0x004E, 0x004F,
+	0x0050, 0x0051, 0x0052, 0x0053, 0x0054, 0x0055, 0x0056, 0x0057,
+	0x0058, 0x0059, 0x005A, 0x005B, 0x005C, 0x005D, 0x005E, 0x005F,
+	0x0060, 0x0061, 0x0062, 0x0063, 0x0064, 0x0065, 0x0066, 0x0067,
+	0x0068, 0x0069, 0x006A, 0x006B, 0x006C, 0x006D, 0x006E, 0x006F,
+	0x0070, 0x0071, 0x0072, 0x0073, 0x0074, 0x0075, 0x0076, 0x0077,
+	0x0078, 0x0079, 0x007A, 0x007B, 0x007C, 0x007D, 0x007E, 0x0000,
+	0x00C4, 0x00C5, 0x00C7, 0x00C9, 0x00D1, 0x00D6, 0x00DC, 0x00E1,
+	0x00E0, 0x00E2, 0x00E4, 0x00E3, 0x00E5, 0x00E7, 0x00E9, 0x00E8,
+	0x00EA, 0x00EB, 0x00ED, 0x00EC, 0x00EE, 0x00EF, 0x00F1, 0x00F3,
+	0x00F2, 0x00F4, 0x00F6, 0x00F5, 0x00FA, 0x00F9, 0x00FB, 0x00FC,
+	0x2020, 0x00B0, 0x00A2, 0x00A3, 0x00A7, 0x2022, 0x00B6, 0x00DF,
+	0x00AE, 0x00A9, 0x2122, 0x00B4, 0x00A8, 0x2260, 0x00C6, 0x00D8,
+	0x221E, 0x00B1, 0x2264, 0x2265, 0x00A5, 0x00B5, 0x2202, 0x2211,
+	0x220F, 0x03C0, 0x222B, 0x00AA, 0x00BA, 0x03A9, 0x00E6, 0x00F8,
+	0x00BF, 0x00A1, 0x00AC, 0x221A, 0x0192, 0x2248, 0x2206, 0x00AB,
+	0x00BB, 0x2026, 0x00A0, 0x00C0, 0x00C3, 0x00D5, 0x0152, 0x0153,
+	0x2013, 0x2014, 0x201C, 0x201D, 0x2018, 0x2019, 0x00F7, 0x25CA,
+	0x00FF, 0x0178, 0x2044, 0x00A4, 0x2039, 0x203A, 0xFB01, 0xFB02,
+	0x2021, 0x00B7, 0x201A, 0x201E, 0x2030, 0x00C2, 0x00CA, 0x00C1,
+	0x00CB, 0x00C8, 0x00CD, 0x00CE, 0x00CF, 0x00CC, 0x00D3, 0x00D4,
+	0xF8FF, 0x00D2, 0x00DA, 0x00DB, 0x00D9, 0x0131, 0x02C6, 0x02DC,
+	0x00AF, 0x02D8, 0x02D9, 0x02DA, 0x00B8, 0x02DD, 0x02DB, 0x02C7,
+}
+
+// standardEncoding maps the Adobe StandardEncoding codes to Unicode.
+var standardEncoding = [256]rune{
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0020, 0x0021, 0x0022, 0x0023, 0x0024, 0x0025, 0x0026, 0x2019,
+	0x0028, 0x0029, 0x002A, 0x002B, 0x002C, 0x002D, 0x002E, 0x002F,
+	0x0030, 0x0031, 0x0032, 0x0033, 0x0034, 0x0035, 0x0036, 0x0037,
+	0x0038, 0x0039, 0x003A, 0x003B, 0x003C, 0x003D, 0x003E, 0x003F,
+	0x0040, 0x0041, 0x0042, 0x0043, 0x0044, 0x0045, 0x0046, 0x0047,
+	0x0048, 0x0049, 0x004A, 0x004B, 0x004C, 0x004D, 0x004E, 0x004F,
+	0x0050, 0x0051, 0x0052, 0x0053, 0x0054, 0x0055, 0x0056, 0x0057,
+	0x0058, 0x0059, 0x005A, 0x005B, 0x005C, 0x005D, 0x005E, 0x005F,
+	0x2018, 0x0061, 0x0062, 0x0063, 0x0064, 0x0065, 0x0066, 0x0067,
+	0x0068, 0x0069, 0x006A, 0x006B, 0x006C, 0x006D, 0x006E, 0x006F,
+	0x0070, 0x0071, 0x0072, 0x0073, 0x0074, 0x0075, 0x0076, 0x0077,
+	0x0078, 0x0079, 0x007A, 0x007B, 0x007C, 0x007D, 0x007E, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x00A1, 0x00A2, 0x00A3, 0x2044, 0x00A5, 0x0192, 0x00A7,
+	0x00A4, 0x0027, 0x201C, 0x00AB, 0x2039, 0x203A, 0xFB01, 0xFB02,
+	0x0000, 0x2013, 0x2020, 0x2021, 0x00B7, 0x0000, 0x00B6, 0x2022,
+	0x201A, 0x201E, 0x201D, 0x00BB, 0x2026, 0x2030, 0x0000, 0x00BF,
+	0x0000, 0x0060, 0x00B4, 0x02C6, 0x02DC, 0x00AF, 0x02D8, 0x02D9,
+	0x00A8, 0x0000, 0x02DA, 0x00B8, 0x0000, 0x02DD, 0x02DB, 0x02C7,
+	0x2014, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x00C6, 0x0000, 0x00AA, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0141, 0x00D8, 0x0152, 0x00BA, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x00E6, 0x0000, 0x0000, 0x0000, 0x0131, 0x0000, 0x0000,
+	0x0142, 0x00F8, 0x0153, 0x00DF, 0x0000, 0x0000, 0x0000, 0x0000,
+}
+
+// glyphNames maps the glyph names of the Latin fonts to Unicode.
+// The other names are read from uniXXXX and uXXXX[XX] forms.
+var glyphNames = map[string]rune{
+	"A":              0x0041,
+	"AE":             0x00C6,
+	"Aacute":         0x00C1,
+	"Abreve":         0x0102,
+	"Acircumflex":    0x00C2,
+	"Adieresis":      0x00C4,
+	"Agrave":         0x00C0,
+	"Amacron":        0x0100,
+	"Aogonek":        0x0104,
+	"Aring":          0x00C5,
+	"Atilde":         0x00C3,
+	"B":              0x0042,
+	"C":              0x0043,
+	"Cacute":         0x0106,
+	"Ccaron":         0x010C,
+	"Ccedilla":       0x00C7,
+	"Ccircumflex":    0x0108,
+	"Cdotaccent":     0x010A,
+	"D":              0x0044,
+	"Dcaron":         0x010E,
+	"Dcroat":         0x0110,
+	"Delta":          0x2206,
+	"E":              0x0045,
+	"Eacute":         0x00C9,
+	"Ebreve":         0x0114,
+	"Ecaron":         0x011A,
+	"Ecircumflex":    0x00CA,
+	"Edieresis":      0x00CB,
+	"Edotaccent":     0x0116,
+	"Egrave":         0x00C8,
+	"Emacron":        0x0112,
+	"Eng":            0x014A,
+	"Eogonek":        0x0118,
+	"Eth":            0x00D0,
+	"Euro":           0x20AC,
+	"F":              0x0046,
+	"G":              0x0047,
+	"Gbreve":         0x011E,
+	"Gcircumflex":    0x011C,
+	"Gcommaaccent":   0x0122,
+	"Gdotaccent":     0x0120,
+	"H":              0x0048,
+	"Hbar":           0x0126,
+	"Hcircumflex":    0x0124,
+	"I":              0x0049,
+	"IJ":             0x0132,
+	"Iacute":         0x00CD,
+	"Ibreve":         0x012C,
+	"Icircumflex":    0x00CE,
+	"Idieresis":      0x00CF,
+	"Idotaccent":     0x0130,
+	"Igrave":         0x00CC,
+	"Imacron":        0x012A,
+	"Iogonek":        0x012E,
+	"Itilde":         0x0128,
+	"J":              0x004A,
+	"Jcircumflex":    0x0134,
+	"K":              0x004B,
+	"Kcommaaccent":   0x0136,
+	"L":              0x004C,
+	"Lacute":         0x0139,
+	"Lcaron":         0x013D,
+	"Lcommaaccent":   0x013B,
+	"Ldot":           0x013F,
+	"Lslash":         0x0141,
+	"M":              0x004D,
+	"N":              0x004E,
+	"Nacute":         0x0143,
+	"Ncaron":         0x0147,
+	"Ncommaaccent":   0x0145,
+	"Ntilde":         0x00D1,
+	"O":              0x004F,
+	"OE":             0x0152,
+	"Oacute":         0x00D3,
+	"Obreve":         0x014E,
+	"Ocircumflex":    0x00D4,
+	"Odieresis":      0x00D6,
+	"Ograve":         0x00D2,
+	"Ohungarumlaut":  0x0150,
+	"Omacron":        0x014C,
+	"Omega":          0x03A9,
+	"Oslash":         0x00D8,
+	"Otilde":         0x00D5,
+	"P":              0x0050,
+	"Q":              0x0051,
+	"R":              0x0052,
+	"Racute":         0x0154,
+	"Rcaron":         0x0158,
+	"Rcommaaccent":   0x0156,
+	"S":              0x0053,
+	"Sacute":         0x015A,
+	"Scaron":         0x0160,
+	"Scedilla":       0x015E,
+	"Scircumflex":    0x015C,
+	"Scommaaccent":   0x0218,
+	"T":              0x0054,
+	"Tbar":           0x0166,
+	"Tcaron":         0x0164,
+	"Tcommaaccent":   0x0162,
+	"Thorn":          0x00DE,
+	"U":              0x0055,
+	"Uacute":         0x00DA,
+	"Ubreve":         0x016C,
+	"Ucircumflex":    0x00DB,
+	"Udieresis":      0x00DC,
+	"Ugrave":         0x00D9,
+	"Uhungarumlaut":  0x0170,
+	"Umacron":        0x016A,
+	"Uogonek":        0x0172,
+	"Uring":          0x016E,
+	"Utilde":         0x0168,
+	"V":              0x0056,
+	"W":              0x0057,
+	"Wcircumflex":    0x0174,
+	"X":              0x0058,
+	"Y":              0x0059,
+	"Yacute":         0x00DD,
+	"Ycircumflex":    0x0176,
+	"Ydieresis":      0x0178,
+	"Z":              0x005A,
+	"Zacute":         0x0179,
+	"Zcaron":         0x017D,
+	"Zdotaccent":     0x017B,
+	"a":              0x0061,
+	"aacute":         0x00E1,
+	"abreve":         0x0103,
+	"acircumflex":    0x00E2,
+	"acute":          0x00B4,
+	"adieresis":      0x00E4,
+	"ae":             0x00E6,
+	"agrave":         0x00E0,
+	"amacron":        0x0101,
+	"ampersand":      0x0026,
+	"aogonek":        0x0105,
+	"apple":          0xF8FF,
+	"approxequal":    0x2248,
+	"aring":          0x00E5,
+	"asciicircum":    0x005E,
+	"asciitilde":     0x007E,
+	"asterisk":       0x002A,
+	"at":             0x0040,
+	"atilde":         0x00E3,
+	"b":              0x0062,
+	"backslash":      0x005C,
+	"bar":            0x007C,
+	"braceleft":      0x007B,
+	"braceright":     0x007D,
+	"bracketleft":    0x005B,
+	"bracketright":   0x005D,
+	"breve":          0x02D8,
+	"brokenbar":      0x00A6,
+	"bullet":         0x2022,
+	"c":              0x0063,
+	"cacute":         0x0107,
+	"caron":          0x02C7,
+	"ccaron":         0x010D,
+	"ccedilla":       0x00E7,
+	"ccircumflex":    0x0109,
+	"cdotaccent":     0x010B,
+	"cedilla":        0x00B8,
+	"cent":           0x00A2,
+	"circumflex":     0x02C6,
+	"colon":          0x003A,
+	"comma":          0x002C,
+	"commaaccent":    0x0326,
+	"copyright":      0x00A9,
+	"currency":       0x00A4,
+	"d":              0x0064,
+	"dagger":         0x2020,
+	"daggerdbl":      0x2021,
+	"dcaron":         0x010F,
+	"dcroat":         0x0111,
+	"degree":         0x00B0,
+	"dieresis":       0x00A8,
+	"divide":         0x00F7,
+	"dollar":         0x0024,
+	"dotaccent":      0x02D9,
+	"dotlessi":       0x0131,
+	"e":              0x0065,
+	"eacute":         0x00E9,
+	"ebreve":         0x0115,
+	"ecaron":         0x011B,
+	"ecircumflex":    0x00EA,
+	"edieresis":      0x00EB,
+	"edotaccent":     0x0117,
+	"egrave":         0x00E8,
+	"eight":          0x0038,
+	"ellipsis":       0x2026,
+	"emacron":        0x0113,
+	"emdash":         0x2014,
+	"endash":         0x2013,
+	"eng":            0x014B,
+	"eogonek":        0x0119,
+	"equal":          0x003D,
+	"eth":            0x00F0,
+	"exclam":         0x0021,
+	"exclamdown":     0x00A1,
+	"f":              0x0066,
+	"ff":             0xFB00,
+	"ffi":            0xFB03,
+	"ffl":            0xFB04,
+	"fi":             0xFB01,
+	"five":           0x0035,
+	"fl":             0xFB02,
+	"florin":         0x0192,
+	"four":           0x0034,
+	"fraction":       0x2044,
+	"g":              0x0067,
+	"gbreve":         0x011F,
+	"gcircumflex":    0x011D,
+	"gcommaaccent":   0x0123,
+	"gdotaccent":     0x0121,
+	"germandbls":     0x00DF,
+	"grave":          0x0060,
+	"greater":        0x003E,
+	"greaterequal":   0x2265,
+	"guillemotleft":  0x00AB,
+	"guillemotright": 0x00BB,
+	"guilsinglleft":  0x2039,
+	"guilsinglright": 0x203A,
+	"h":              0x0068,
+	"hbar":           0x0127,
+	"hcircumflex":    0x0125,
+	"hungarumlaut":   0x02DD,
+	"hyphen":         0x002D,
+	"i":              0x0069,
+	"iacute":         0x00ED,
+	"ibreve":         0x012D,
+	"icircumflex":    0x00EE,
+	"idieresis":      0x00EF,
+	"igrave":         0x00EC,
+	"ij":             0x0133,
+	"imacron":        0x012B,
+	"infinity":       0x221E,
+	"integral":       0x222B,
+	"iogonek":        0x012F,
+	"itilde":         0x0129,
+	"j":              0x006A,
+	"jcircumflex":    0x0135,
+	"k":              0x006B,
+	"kcommaaccent":   0x0137,
+	"kgreenlandic":   0x0138,
+	"l":              0x006C,
+	"lacute":         0x013A,
+	"lcaron":         0x013E,
+	"lcommaaccent":   0x013C,
+	"ldot":           0x0140,
+	"less":           0x003C,
+	"lessequal":      0x2264,
+	"logicalnot":     0x00AC,
+	"longs":          0x017F,
+	"lozenge":        0x25CA,
+	"lslash":         0x0142,
+	"m":              0x006D,
+	"macron":         0x00AF,
+	"minus":          0x2212,
+	"mu":             0x00B5,
+	"multiply":       0x00D7,
+	"n":              0x006E,
+	"nacute":         0x0144,
+	"napostrophe":    0x0149,
+	"nbspace":        0x00A0,
+	"ncaron":         0x0148,
+	"ncommaaccent":   0x0146,
+	"nine":           0x0039,
+	"notequal":       0x2260,
+	"ntilde":         0x00F1,
+	"numbersign":     0x0023,
+	"o":              0x006F,
+	"oacute":         0x00F3,
+	"obreve":         0x014F,
+	"ocircumflex":    0x00F4,
+	"odieresis":      0x00F6,
+	"oe":             0x0153,
+	"ogonek":         0x02DB,
+	"ograve":         0x00F2,
+	"ohungarumlaut":  0x0151,
+	"omacron":        0x014D,
+	"one":            0x0031,
+	"onehalf":        0x00BD,
+	"onequarter":     0x00BC,
+	"onesuperior":    0x00B9,
+	"ordfeminine":    0x00AA,
+	"ordmasculine":   0x00BA,
+	"oslash":         0x00F8,
+	"otilde":         0x00F5,
+	"p":              0x0070,
+	"paragraph":      0x00B6,
+	"parenleft":      0x0028,
+	"parenright":     0x0029,
+	"partialdiff":    0x2202,
+	"percent":        0x0025,
+	"period":         0x002E,
+	"periodcentered": 0x00B7,
+	"perthousand":    0x2030,
+	"pi":             0x03C0,
+	"plus":           0x002B,
+	"plusminus":      0x00B1,
+	"product":        0x220F,
+	"q":              0x0071,
+	"question":       0x003F,
+	"questiondown":   0x00BF,
+	"quotedbl":       0x0022,
+	"quotedblbase":   0x201E,
+	"quotedblleft":   0x201C,
+	"quotedblright":  0x201D,
+	"quoteleft":      0x2018,
+	"quoteright":     0x2019,
+	"quotesinglbase": 0x201A,
+	"quotesingle":    0x0027,
+	"r":              0x0072,
+	"racute":         0x0155,
+	"radical":        0x221A,
+	"rcaron":         0x0159,
+	"rcommaaccent":   0x0157,
+	"registered":     0x00AE,
+	"ring":           0x02DA,
+	"s":              0x0073,
+	"sacute":         0x015B,
+	"scaron":         0x0161,
+	"scedilla":       0x015F,
+	"scircumflex":    0x015D,
+	"scommaaccent":   0x0219,
+	"section":        0x00A7,
+	"semicolon":      0x003B,
+	"seven":          0x0037,
+	"sfthyphen":      0x00AD,
+	"six":            0x0036,
+	"slash":          0x002F,
+	"space":          0x0020,
+	"sterling":       0x00A3,
+	"summation":      0x2211,
+	"t":              0x0074,
+	"tbar":           0x0167,
+	"tcaron":         0x0165,
+	"tcommaaccent":   0x0163,
+	"thorn":          0x00FE,
+	"three":          0x0033,
+	"threequarters":  0x00BE,
+	"threesuperior":  0x00B3,
+	"tilde":          0x02DC,
+	"trademark":      0x2122,
+	"two":            0x0032,
+	"twosuperior":    0x00B2,
+	"u":              0x0075,
+	"uacute":         0x00FA,
+	"ubreve":         0x016D,
+	"ucircumflex":    0x00FB,
+	"udieresis":      0x00FC,
+	"ugrave":         0x00F9,
+	"uhungarumlaut":  0x0171,
+	"umacron":        0x016B,
+	"underscore":     0x005F,
+	"uni00A0":        0x00A0,
+	"uni00AD":        0x00AD,
+	"uogonek":        0x0173,
+	"uring":          0x016F,
+	"utilde":         0x0169,
+	"v":              0x0076,
+	"w":              0x0077,
+	"wcircumflex":    0x0175,
+	"x":              0x0078,
+	"y":              0x0079,
+	"yacute":         0x00FD,
+	"ycircumflex":    0x0177,
+	"ydieresis":      0x00FF,
+	"yen":            0x00A5,
+	"z":              0x007A,
+	"zacute":         0x017A,
+	"zcaron":         0x017E,
+	"zdotaccent":     0x017C,
+	"zero":           0x0030,
+}
@@ -0,0 +1,269 @@
+package pdfjet
+
+/**
+ * gpos.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// The GPOS lookup types.
+const (
+	gposSingle       = 1
+	gposPair         = 2
+	gposCursive      = 3
+	gposMarkToBase   = 4
+	gposMarkToLig    = 5
+	gposMarkToMark   = 6
+	gposContext      = 7
+	gposChainContext = 8
+)
+
+// applyPositioningLookup applies the GPOS lookup to the glyphs with the lookup mask.
+func (s *otShaper) applyPositioningLookup(ref otLookupRef) {
+	lookup := s.layout.gpos.lookups[ref.index]
+	for i := 0; i < len(s.glyphs); {
+		if s.glyphs[i].mask&ref.mask == 0 || s.layout.ignores(lookup, &s.glyphs[i]) {
+			i++
+			continue
+		}
+		if next := s.positionAt(lookup, i, 0); next > i {
+			i = next
+		} else {
+			i++
+		}
+	}
+}
+
+// positionAt applies the first matching subtable of the lookup at position i.
+// Returns the position of the next glyph to process or -1 if no subtable matches.
+func (s *otShaper) positionAt(lookup *otLookup, i, depth int) int {
+	for _, data := range lookup.subtables {
+		if next := s.positionSubtableAt(lookup, data, i, depth); next >= 0 {
+			return next
+		}
+	}
+	return -1
+}
+
+func (s *otShaper) positionSubtableAt(lookup *otLookup, data []byte, i, depth int) int {
+	g := &s.glyphs[i]
+	switch lookup.kind {
+	case gposSingle:
+		index := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if index < 0 {
+			return -1
+		}
+		valueFormat := u16(data, 4)
+		if u16(data, 0) == 1 {
+			s.adjust(i, data[6:], valueFormat)
+		} else if index < u16(data, 6) {
+			s.adjust(i, data[8+index*getValueRecordSize(valueFormat):], valueFormat)
+		} else {
+			return -1
+		}
+		return i + 1
+
+	case gposPair:
+		index := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if index < 0 {
+			return -1
+		}
+		j := s.nextGlyph(lookup, i)
+		if j < 0 {
+			return -1
+		}
+		valueFormat1, valueFormat2 := u16(data, 4), u16(data, 6)
+		size1, size2 := getValueRecordSize(valueFormat1), getValueRecordSize(valueFormat2)
+		var record []byte
+		if u16(data, 0) == 1 {
+			if index >= u16(data, 8) {
+				return -1
+			}
+			pairSet := data[u16(data, 10+2*index):]
+			count := u16(pairSet, 0)
+			recordSize := 2 + size1 + size2
+			second := s.glyphs[j].gid
+			k := sort.Search(count, func(k int) bool { return u16(pairSet, 2+k*recordSize) >= second })
+			if k == count || u16(pairSet, 2+k*recordSize) != second {
+				return -1
+			}
+			record = pairSet[4+k*recordSize:]
+		} else {
+			class1 := getClass(data[u16(data, 8):], g.gid)
+			class2 := getClass(data[u16(data, 10):], s.glyphs[j].gid)
+			class1Count, class2Count := u16(data, 12), u16(data, 14)
+			if class1 >= class1Count || class2 >= class2Count ||
+				getCoverageIndex(data[u16(data, 2):], g.gid) < 0 {
+				return -1
+			}
+			record = data[16+(class1*class2Count+class2)*(size1+size2):]
+		}
+		s.adjust(i, record, valueFormat1)
+		s.adjust(j, record[size1:], valueFormat2)
+		if valueFormat2 != 0 {
+			return j + 1
+		}
+		return j
+
+	case gposMarkToBase, gposMarkToLig:
+		if g.class != glyphClassMark && s.layout.glyphClassDef != nil {
+			return -1
+		}
+		markIndex := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if markIndex < 0 {
+			return -1
+		}
+		// The base is the previous glyph that is not mark.
+		j := i - 1
+		for j >= 0 && s.glyphs[j].class == glyphClassMark {
+			j--
+		}
+		if j < 0 {
+			return -1
+		}
+		baseIndex := getCoverageIndex(data[u16(data, 4):], s.glyphs[j].gid)
+		classCount := u16(data, 6)
+		markArray := data[u16(data, 8):]
+		if baseIndex < 0 || markIndex >= u16(markArray, 0) {
+			return -1
+		}
+		markClass := u16(markArray, 2+4*markIndex)
+		markAnchor := markArray[u16(markArray, 4+4*markIndex):]
+		array := data[u16(data, 10):]
+		if baseIndex >= u16(array, 0) || markClass >= classCount {
+			return -1
+		}
+		var baseAnchor []byte
+		if lookup.kind == gposMarkToBase {
+			offset := u16(array, 2+2*(baseIndex*classCount+markClass))
+			if offset == 0 {
+				return -1
+			}
+			baseAnchor = array[offset:]
+		} else {
+			// The mark is attached to the last ligature component.
+			attach := array[u16(array, 2+2*baseIndex):]
+			componentCount := u16(attach, 0)
+			if componentCount == 0 {
+				return -1
+			}
+			offset := u16(attach, 2+2*((componentCount-1)*classCount+markClass))
+			if offset == 0 {
+				return -1
+			}
+			baseAnchor = attach[offset:]
+		}
+		s.attachMark(i, j, baseAnchor, markAnchor)
+		return i + 1
+
+	case gposMarkToMark:
+		mark1Index := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if mark1Index < 0 {
+			return -1
+		}
+		j := s.prevGlyph(lookup, i)
+		if j < 0 || (s.layout.glyphClassDef != nil && s.glyphs[j].class != glyphClassMark) {
+			return -1
+		}
+		mark2Index := getCoverageIndex(data[u16(data, 4):], s.glyphs[j].gid)
+		classCount := u16(data, 6)
+		mark1Array := data[u16(data, 8):]
+		mark2Array := data[u16(data, 10):]
+		if mark2Index < 0 || mark1Index >= u16(mark1Array, 0) || mark2Index >= u16(mark2Array, 0) {
+			return -1
+		}
+		markClass := u16(mark1Array, 2+4*mark1Index)
+		if markClass >= classCount {
+			return -1
+		}
+		markAnchor := mark1Array[u16(mark1Array, 4+4*mark1Index):]
+		offset := u16(mark2Array, 2+2*(mark2Index*classCount+markClass))
+		if offset == 0 {
+			return -1
+		}
+		s.attachMark(i, j, mark2Array[offset:], markAnchor)
+		return i + 1
+
+	case gposContext:
+		return s.applyContext(lookup, data, i, s.nestedPositioning(depth))
+
+	case gposChainContext:
+		return s.applyChainContext(lookup, data, i, s.nestedPositioning(depth))
+	}
+	return -1
+}
+
+// nestedPositioning returns the function that applies the nested lookups of contextual positioning.
+func (s *otShaper) nestedPositioning(depth int) func(index, pos int) {
+	return func(index, pos int) {
+		if depth < 8 && index < len(s.layout.gpos.lookups) && pos < len(s.glyphs) {
+			lookup := s.layout.gpos.lookups[index]
+			if !s.layout.ignores(lookup, &s.glyphs[pos]) {
+				s.positionAt(lookup, pos, depth+1)
+			}
+		}
+	}
+}
+
+// attachMark positions the mark at position i so that its anchor is on the anchor of the glyph at position j.
+// In the right-to-left text the mark is drawn before the glyphs between it and the base.
+func (s *otShaper) attachMark(i, j int, baseAnchor, markAnchor []byte) {
+	base := &s.glyphs[j]
+	mark := &s.glyphs[i]
+	mark.xOffset = base.xOffset + s16(baseAnchor, 2) - s16(markAnchor, 2)
+	mark.yOffset = base.yOffset + s16(baseAnchor, 4) - s16(markAnchor, 4)
+	if s.rtl {
+		for k := j + 1; k <= i; k++ {
+			mark.xOffset += s.glyphs[k].advance
+		}
+	} else {
+		for k := j; k < i; k++ {
+			mark.xOffset -= s.glyphs[k].advance
+		}
+	}
+}
+
+// adjust applies the value record to the glyph at position i.
+func (s *otShaper) adjust(i int, record []byte, valueFormat int) {
+	g := &s.glyphs[i]
+	offset := 0
+	if valueFormat&0x0001 != 0 {
+		g.xOffset += s16(record, offset)
+		offset += 2
+	}
+	if valueFormat&0x0002 != 0 {
+		g.yOffset += s16(record, offset)
+		offset += 2
+	}
+	if valueFormat&0x0004 != 0 {
+		g.advance += s16(record, offset)
+	}
+}
+
+// getValueRecordSize returns the size of the value record in bytes.
+func getValueRecordSize(valueFormat int) int {
+	return 2 * bits.OnesCount16(uint16(valueFormat))
+}
@@ -0,0 +1,214 @@
+package pdfjet
+
+/**
+ * gsub.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// The GSUB lookup types.
+const (
+	gsubSingle              = 1
+	gsubMultiple            = 2
+	gsubAlternate           = 3
+	gsubLigature            = 4
+	gsubContext             = 5
+	gsubChainContext        = 6
+	gsubReverseChainContext = 8
+)
+
+// applySubstitutionLookup applies the GSUB lookup to the glyphs with the lookup mask.
+func (s *otShaper) applySubstitutionLookup(ref otLookupRef) {
+	lookup := s.layout.gsub.lookups[ref.index]
+	if lookup.kind == gsubReverseChainContext {
+		for i := len(s.glyphs) - 1; i >= 0; i-- {
+			if s.glyphs[i].mask&ref.mask != 0 && !s.layout.ignores(lookup, &s.glyphs[i]) {
+				s.substituteAt(lookup, i, 0)
+			}
+		}
+		return
+	}
+	for i := 0; i < len(s.glyphs); {
+		if s.glyphs[i].mask&ref.mask == 0 || s.layout.ignores(lookup, &s.glyphs[i]) {
+			i++
+			continue
+		}
+		if next := s.substituteAt(lookup, i, 0); next >= 0 {
+			i = next
+		} else {
+			i++
+		}
+	}
+}
+
+// substituteAt applies the first matching subtable of the lookup at position i.
+// Returns the position of the next glyph to process or -1 if no subtable matches.
+func (s *otShaper) substituteAt(lookup *otLookup, i, depth int) int {
+	for _, data := range lookup.subtables {
+		if next := s.substituteSubtableAt(lookup, data, i, depth); next >= 0 {
+			return next
+		}
+	}
+	return -1
+}
+
+func (s *otShaper) substituteSubtableAt(lookup *otLookup, data []byte, i, depth int) int {
+	g := &s.glyphs[i]
+	switch lookup.kind {
+	case gsubSingle:
+		index := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if index < 0 {
+			return -1
+		}
+		if u16(data, 0) == 1 {
+			s.setGlyph(i, (g.gid+s16(data, 4))&0xFFFF)
+		} else if index < u16(data, 4) {
+			s.setGlyph(i, u16(data, 6+2*index))
+		} else {
+			return -1
+		}
+		return i + 1
+
+	case gsubMultiple:
+		index := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if index < 0 || index >= u16(data, 4) {
+			return -1
+		}
+		sequence := data[u16(data, 6+2*index):]
+		count := u16(sequence, 0)
+		if count == 0 {
+			s.glyphs = append(s.glyphs[:i], s.glyphs[i+1:]...)
+			return i
+		}
+		glyphs := make([]otGlyph, count)
+		for j := range glyphs {
+			glyphs[j] = *g
+			if j > 0 {
+				glyphs[j].length = 0
+			}
+			glyphs[j].gid = u16(sequence, 2+2*j)
+			glyphs[j].class = s.getSubstitutedClass(glyphs[j].gid, glyphClassBase)
+		}
+		s.glyphs = append(s.glyphs[:i], append(glyphs, s.glyphs[i+1:]...)...)
+		return i + count
+
+	case gsubAlternate:
+		index := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if index < 0 || index >= u16(data, 4) {
+			return -1
+		}
+		alternates := data[u16(data, 6+2*index):]
+		if u16(alternates, 0) == 0 {
+			return -1
+		}
+		s.setGlyph(i, u16(alternates, 2))
+		return i + 1
+
+	case gsubLigature:
+		index := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if index < 0 || index >= u16(data, 4) {
+			return -1
+		}
+		ligatureSet := data[u16(data, 6+2*index):]
+		for j := 0; j < u16(ligatureSet, 0); j++ {
+			ligature := ligatureSet[u16(ligatureSet, 2+2*j):]
+			componentCount := u16(ligature, 2)
+			positions := s.matchInput(lookup, i, componentCount, func(k, gid int) bool {
+				return u16(ligature, 4+2*(k-1)) == gid
+			})
+			if positions == nil {
+				continue
+			}
+			// The ligature is made from the text of all components. The components
+			// are not in logical order when the Indic reph was moved after the base.
+			start, end := g.cluster, g.cluster+g.length
+			for _, p := range positions[1:] {
+				start = minInt(start, s.glyphs[p].cluster)
+				end = maxInt(end, s.glyphs[p].cluster+s.glyphs[p].length)
+			}
+			g.cluster, g.length = start, end-start
+			g.gid = u16(ligature, 0)
+			g.class = s.getSubstitutedClass(g.gid, glyphClassLigature)
+			for k := len(positions) - 1; k > 0; k-- {
+				p := positions[k]
+				s.glyphs = append(s.glyphs[:p], s.glyphs[p+1:]...)
+			}
+			return i + 1
+		}
+		return -1
+
+	case gsubContext:
+		return s.applyContext(lookup, data, i, s.nestedSubstitution(depth))
+
+	case gsubChainContext:
+		return s.applyChainContext(lookup, data, i, s.nestedSubstitution(depth))
+
+	case gsubReverseChainContext:
+		index := getCoverageIndex(data[u16(data, 2):], g.gid)
+		if index < 0 {
+			return -1
+		}
+		backtrackCount := u16(data, 4)
+		lookahead := data[6+2*backtrackCount:]
+		lookaheadCount := u16(lookahead, 0)
+		substitutes := lookahead[2+2*lookaheadCount:]
+		if index >= u16(substitutes, 0) ||
+			!s.matchBacktrack(lookup, i, backtrackCount, func(k, gid int) bool {
+				return getCoverageIndex(data[u16(data, 6+2*k):], gid) >= 0
+			}) ||
+			!s.matchLookahead(lookup, i, lookaheadCount, func(k, gid int) bool {
+				return getCoverageIndex(data[u16(lookahead, 2+2*k):], gid) >= 0
+			}) {
+			return -1
+		}
+		s.setGlyph(i, u16(substitutes, 2+2*index))
+		return i + 1
+	}
+	return -1
+}
+
+// nestedSubstitution returns the function that applies the nested lookups of contextual substitution.
+func (s *otShaper) nestedSubstitution(depth int) func(index, pos int) {
+	return func(index, pos int) {
+		if depth < 8 && index < len(s.layout.gsub.lookups) && pos < len(s.glyphs) {
+			lookup := s.layout.gsub.lookups[index]
+			if !s.layout.ignores(lookup, &s.glyphs[pos]) {
+				s.substituteAt(lookup, pos, depth+1)
+			}
+		}
+	}
+}
+
+// setGlyph replaces the glyph at position i keeping its text.
+func (s *otShaper) setGlyph(i, gid int) {
+	g := &s.glyphs[i]
+	g.gid = gid
+	g.class = s.getSubstitutedClass(gid, g.class)
+}
+
+// getSubstitutedClass returns the GDEF class of the substituted glyph.
+// The class is kept when the font has no glyph classes.
+func (s *otShaper) getSubstitutedClass(gid, class int) int {
+	if s.layout.glyphClassDef != nil {
+		return s.layout.getGlyphClass(gid)
+	}
+	return class
+}
@@ -0,0 +1,783 @@
+package pdfjet
+
+/**
+ * gvar.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// varInstancer applies the glyph variations of the instance to the glyf and hmtx tables.
+type varInstancer struct {
+	coords []float64 // The normalized coordinates of the instance
+}
+
+// varGlyph is the decoded TrueType glyph. The glyphs without contours have no points.
+type varGlyph struct {
+	numberOfContours int
+	xMin, yMin       int
+	xMax, yMax       int
+	endPts           []int
+	flags            []byte // The on curve and overlap flags of the points
+	xs, ys           []float64
+	components       []varComponent
+	instructions     []byte
+}
+
+// varComponent is the component of composite glyph.
+type varComponent struct {
+	flags      int
+	glyphIndex int
+	arg1, arg2 float64 // The offset or the point numbers
+	transform  []byte  // The scale or the 2x2 transformation
+}
+
+const (
+	argsAreWords       = 0x0001
+	argsAreXYValues    = 0x0002
+	weHaveAScale       = 0x0008
+	moreComponents     = 0x0020
+	weHaveXYScale      = 0x0040
+	weHaveTwoByTwo     = 0x0080
+	weHaveInstructions = 0x0100
+	onCurvePoint       = 0x01
+	overlapSimple      = 0x40
+	xShortVector       = 0x02
+	yShortVector       = 0x04
+	repeatFlag         = 0x08
+	xIsSameOrPositive  = 0x10
+	yIsSameOrPositive  = 0x20
+)
+
+// instantiate replaces the glyf, loca, hmtx, hhea and head tables with the tables of the instance.
+func (instancer *varInstancer) instantiate(tables map[string][]byte) error {
+	head, hhea, hmtx, maxp := tables["head"], tables["hhea"], tables["hmtx"], tables["maxp"]
+	loca, glyf, gvar := tables["loca"], tables["glyf"], tables["gvar"]
+	if head == nil || hhea == nil || hmtx == nil || maxp == nil || loca == nil {
+		return malformed("variable font", "missing head, hhea, hmtx, maxp or loca table")
+	}
+	numGlyphs := u16(maxp, 4)
+	longOffsets := u16(head, 50) == 1
+	offsets := make([]int, numGlyphs+1)
+	for i := range offsets {
+		if longOffsets {
+			offsets[i] = u32(loca, 4*i)
+		} else {
+			offsets[i] = 2 * u16(loca, 2*i)
+		}
+	}
+	numberOfHMetrics := u16(hhea, 34)
+	advances := make([]int, numGlyphs)
+	lsbs := make([]int, numGlyphs)
+	for gid := 0; gid < numGlyphs; gid++ {
+		if gid < numberOfHMetrics {
+			advances[gid] = u16(hmtx, 4*gid)
+			lsbs[gid] = s16(hmtx, 4*gid+2)
+		} else {
+			advances[gid] = advances[numberOfHMetrics-1]
+			lsbs[gid] = s16(hmtx, 4*numberOfHMetrics+2*(gid-numberOfHMetrics))
+		}
+	}
+
+	glyphs := make([]*varGlyph, numGlyphs)
+	origins := make([]int, numGlyphs)
+	for gid := 0; gid < numGlyphs; gid++ {
+		glyph := decodeGlyph(glyf[offsets[gid]:offsets[gid+1]])
+		glyphs[gid] = glyph
+		origins[gid] = glyph.xMin - lsbs[gid]
+		n := len(glyph.xs)
+		if glyph.numberOfContours < 0 {
+			n = len(glyph.components)
+		}
+		// The phantom points give the horizontal and the vertical metrics.
+		xs := make([]float64, n+4)
+		ys := make([]float64, n+4)
+		if glyph.numberOfContours < 0 {
+			for i, c := range glyph.components {
+				xs[i], ys[i] = c.arg1, c.arg2
+			}
+		} else {
+			copy(xs, glyph.xs)
+			copy(ys, glyph.ys)
+		}
+		xs[n] = float64(origins[gid])
+		xs[n+1] = xs[n] + float64(advances[gid])
+		dx, dy := instancer.getGlyphDeltas(gvar, gid, xs, ys, glyph)
+		if dx == nil {
+			continue
+		}
+		if glyph.numberOfContours < 0 {
+			for i := range glyph.components {
+				if glyph.components[i].flags&argsAreXYValues != 0 {
+					glyph.components[i].arg1 = roundDelta(xs[i] + dx[i])
+					glyph.components[i].arg2 = roundDelta(ys[i] + dy[i])
+				}
+			}
+		} else {
+			for i := range glyph.xs {
+				glyph.xs[i] = roundDelta(xs[i] + dx[i])
+				glyph.ys[i] = roundDelta(ys[i] + dy[i])
+			}
+		}
+		if tables["HVAR"] == nil {
+			advances[gid] = int(roundDelta(xs[n+1]+dx[n+1]) - roundDelta(xs[n]+dx[n]))
+		}
+	}
+	if hvar := tables["HVAR"]; hvar != nil {
+		// The HVAR deltas are more precise than the phantom point deltas
+		instancer.applyAdvanceDeltas(hvar, advances)
+	}
+
+	// The bounding boxes and the left side bearings
+	done := make([]bool, numGlyphs)
+	for gid := range glyphs {
+		getGlyphBounds(glyphs, gid, done)
+	}
+	newGlyf := make([]byte, 0, len(glyf))
+	newLoca := make([]byte, 0, 4*(numGlyphs+1))
+	newHmtx := make([]byte, 0, 4*numGlyphs)
+	newHead := append([]byte(nil), head...)
+	newHhea := append([]byte(nil), hhea...)
+	xMin, yMin, xMax, yMax := math.MaxInt16, math.MaxInt16, math.MinInt16, math.MinInt16
+	advanceWidthMax, minLsb, minRsb, maxExtent := 0, math.MaxInt16, math.MaxInt16, math.MinInt16
+	for gid, glyph := range glyphs {
+		newLoca = binary.BigEndian.AppendUint32(newLoca, uint32(len(newGlyf)))
+		newGlyf = append(newGlyf, encodeGlyph(glyph)...)
+		for len(newGlyf)%4 != 0 {
+			newGlyf = append(newGlyf, 0)
+		}
+		lsb := lsbs[gid]
+		if glyph.numberOfContours != 0 {
+			lsb = glyph.xMin - origins[gid] // The glyph origin does not move
+			xMin, yMin = minInt(xMin, glyph.xMin), minInt(yMin, glyph.yMin)
+			xMax, yMax = maxInt(xMax, glyph.xMax), maxInt(yMax, glyph.yMax)
+			minLsb = minInt(minLsb, lsb)
+			minRsb = minInt(minRsb, advances[gid]-lsb-(glyph.xMax-glyph.xMin))
+			maxExtent = maxInt(maxExtent, lsb+(glyph.xMax-glyph.xMin))
+		}
+		advanceWidthMax = maxInt(advanceWidthMax, advances[gid])
+		newHmtx = binary.BigEndian.AppendUint16(newHmtx, uint16(advances[gid]))
+		newHmtx = binary.BigEndian.AppendUint16(newHmtx, uint16(int16(lsb)))
+	}
+	newLoca = binary.BigEndian.AppendUint32(newLoca, uint32(len(newGlyf)))
+
+	binary.BigEndian.PutUint32(newHead[8:], 0) // checkSumAdjustment
+	if xMin <= xMax {
+		binary.BigEndian.PutUint16(newHead[36:], uint16(int16(xMin)))
+		binary.BigEndian.PutUint16(newHead[38:], uint16(int16(yMin)))
+		binary.BigEndian.PutUint16(newHead[40:], uint16(int16(xMax)))
+		binary.BigEndian.PutUint16(newHead[42:], uint16(int16(yMax)))
+		binary.BigEndian.PutUint16(newHhea[12:], uint16(int16(minLsb)))
+		binary.BigEndian.PutUint16(newHhea[14:], uint16(int16(minRsb)))
+		binary.BigEndian.PutUint16(newHhea[16:], uint16(int16(maxExtent)))
+	}
+	binary.BigEndian.PutUint16(newHead[50:], 1) // indexToLocFormat
+	binary.BigEndian.PutUint16(newHhea[10:], uint16(advanceWidthMax))
+	binary.BigEndian.PutUint16(newHhea[34:], uint16(numGlyphs))
+	tables["glyf"] = newGlyf
+	tables["loca"] = newLoca
+	tables["hmtx"] = newHmtx
+	tables["head"] = newHead
+	tables["hhea"] = newHhea
+	return nil
+}
+
+// getGlyphDeltas returns the deltas of the glyph points followed by the four phantom points,
+// or nil when the glyph has no variations.
+func (instancer *varInstancer) getGlyphDeltas(
+	gvar []byte, gid int, xs, ys []float64, glyph *varGlyph) ([]float64, []float64) {
+	if gvar == nil || gid >= u16(gvar, 12) {
+		return nil, nil
+	}
+	axisCount := u16(gvar, 4)
+	sharedTuples := gvar[u32(gvar, 8):]
+	dataArray := gvar[u32(gvar, 16):]
+	var start, end int
+	if u16(gvar, 14)&1 != 0 {
+		start, end = u32(gvar, 20+4*gid), u32(gvar, 24+4*gid)
+	} else {
+		start, end = 2*u16(gvar, 20+2*gid), 2*u16(gvar, 22+2*gid)
+	}
+	if start == end {
+		return nil, nil
+	}
+	data := dataArray[start:end]
+	tupleCount := u16(data, 0)
+	serialized := data[u16(data, 2):]
+	numPoints := len(xs)
+	var sharedPoints []int
+	pos := 0
+	if tupleCount&0x8000 != 0 {
+		sharedPoints, pos = readPackedPoints(serialized, 0)
+	}
+	dx := make([]float64, numPoints)
+	dy := make([]float64, numPoints)
+	header := 4
+	for t := 0; t < tupleCount&0x0FFF; t++ {
+		size := u16(data, header)
+		index := u16(data, header+2)
+		header += 4
+		var peak, startTuple, endTuple []byte
+		if index&0x8000 != 0 {
+			peak = data[header:]
+			header += 2 * axisCount
+		} else {
+			peak = sharedTuples[2*axisCount*(index&0x0FFF):]
+		}
+		if index&0x4000 != 0 {
+			startTuple = data[header:]
+			endTuple = data[header+2*axisCount:]
+			header += 4 * axisCount
+		}
+		tupleData := serialized[pos : pos+size]
+		pos += size
+
+		scalar := 1.0
+		for i := 0; i < axisCount; i++ {
+			p := f2Dot14ToFloat(peak[2*i:])
+			s, e := math.Min(p, 0), math.Max(p, 0)
+			if startTuple != nil {
+				s, e = f2Dot14ToFloat(startTuple[2*i:]), f2Dot14ToFloat(endTuple[2*i:])
+			}
+			scalar *= getAxisScalar(instancer.getCoord(i), s, p, e)
+		}
+		if scalar == 0 {
+			continue
+		}
+		points := sharedPoints
+		k := 0
+		if index&0x2000 != 0 {
+			points, k = readPackedPoints(tupleData, 0)
+		}
+		count := len(points)
+		if points == nil {
+			count = numPoints
+		}
+		xDeltas, k := readPackedDeltas(tupleData, k, count)
+		yDeltas, _ := readPackedDeltas(tupleData, k, count)
+		if points == nil {
+			for i := 0; i < numPoints; i++ {
+				dx[i] += scalar * xDeltas[i]
+				dy[i] += scalar * yDeltas[i]
+			}
+			continue
+		}
+		tupleDx := make([]float64, numPoints)
+		tupleDy := make([]float64, numPoints)
+		referenced := make([]bool, numPoints)
+		for i, point := range points {
+			if point < numPoints {
+				tupleDx[point] = xDeltas[i]
+				tupleDy[point] = yDeltas[i]
+				referenced[point] = true
+			}
+		}
+		if glyph.numberOfContours > 0 {
+			inferDeltas(tupleDx, xs, referenced, glyph.endPts)
+			inferDeltas(tupleDy, ys, referenced, glyph.endPts)
+		}
+		for i := 0; i < numPoints; i++ {
+			dx[i] += scalar * tupleDx[i]
+			dy[i] += scalar * tupleDy[i]
+		}
+	}
+	return dx, dy
+}
+
+func (instancer *varInstancer) getCoord(axis int) float64 {
+	if axis < len(instancer.coords) {
+		return instancer.coords[axis]
+	}
+	return 0
+}
+
+// getAxisScalar returns the contribution of the axis to the scalar of the region.
+func getAxisScalar(value, start, peak, end float64) float64 {
+	if peak == 0 || start > peak || peak > end || (start < 0 && end > 0) {
+		return 1
+	}
+	if value < start || value > end {
+		return 0
+	}
+	if value == peak {
+		return 1
+	}
+	if value < peak {
+		return (value - start) / (peak - start)
+	}
+	return (end - value) / (end - peak)
+}
+
+// readPackedPoints returns the packed point numbers, or nil when the tuple applies to all points.
+func readPackedPoints(data []byte, pos int) ([]int, int) {
+	count := int(data[pos])
+	pos++
+	if count == 0 {
+		return nil, pos
+	}
+	if count&0x80 != 0 {
+		count = (count&0x7F)<<8 | int(data[pos])
+		pos++
+	}
+	points := make([]int, 0, count)
+	point := 0
+	for len(points) < count {
+		control := int(data[pos])
+		pos++
+		run := control&0x7F + 1
+		for i := 0; i < run && len(points) < count; i++ {
+			if control&0x80 != 0 {
+				point += u16(data, pos)
+				pos += 2
+			} else {
+				point += int(data[pos])
+				pos++
+			}
+			points = append(points, point)
+		}
+	}
+	return points, pos
+}
+
+func readPackedDeltas(data []byte, pos, count int) ([]float64, int) {
+	deltas := make([]float64, 0, count)
+	for len(deltas) < count {
+		control := int(data[pos])
+		pos++
+		run := control&0x3F + 1
+		for i := 0; i < run && len(deltas) < count; i++ {
+			switch control & 0xC0 {
+			case 0x80:
+				deltas = append(deltas, 0)
+			case 0x40:
+				deltas = append(deltas, float64(s16(data, pos)))
+				pos += 2
+			case 0xC0:
+				deltas = append(deltas, float64(int32(binary.BigEndian.Uint32(data[pos:]))))
+				pos += 4
+			default:
+				deltas = append(deltas, float64(int8(data[pos])))
+				pos++
+			}
+		}
+	}
+	return deltas, pos
+}
+
+// inferDeltas interpolates the deltas of the points that are not referenced by the tuple
+// from the nearest referenced points on the same contour.
+func inferDeltas(deltas, coords []float64, referenced []bool, endPts []int) {
+	start := 0
+	for _, end := range endPts {
+		var refs []int
+		for i := start; i <= end; i++ {
+			if referenced[i] {
+				refs = append(refs, i)
+			}
+		}
+		if len(refs) > 0 && len(refs) <= end-start {
+			for j, ref := range refs {
+				next := refs[(j+1)%len(refs)]
+				for i := ref + 1; i != next; i++ {
+					if i > end {
+						i = start
+						if i == next {
+							break
+						}
+					}
+					deltas[i] = interpolateDelta(
+						coords[i], coords[ref], coords[next], deltas[ref], deltas[next])
+				}
+			}
+		}
+		start = end + 1
+	}
+}
+
+func interpolateDelta(coord, coord1, coord2, delta1, delta2 float64) float64 {
+	if coord1 > coord2 {
+		coord1, coord2 = coord2, coord1
+		delta1, delta2 = delta2, delta1
+	}
+	if coord1 == coord2 {
+		if delta1 == delta2 {
+			return delta1
+		}
+		return 0
+	}
+	if coord <= coord1 {
+		return delta1
+	}
+	if coord >= coord2 {
+		return delta2
+	}
+	return delta1 + (coord-coord1)*(delta2-delta1)/(coord2-coord1)
+}
+
+func roundDelta(value float64) float64 {
+	return math.Floor(value + 0.5)
+}
+
+// applyAdvanceDeltas adds the HVAR deltas to the advance widths.
+func (instancer *varInstancer) applyAdvanceDeltas(hvar []byte, advances []int) {
+	store := hvar[u32(hvar, 4):]
+	var mapping []byte
+	if offset := u32(hvar, 8); offset != 0 {
+		mapping = hvar[offset:]
+	}
+	for gid := range advances {
+		outer, inner := 0, gid
+		if mapping != nil {
+			outer, inner = getDeltaSetIndex(mapping, gid)
+		}
+		advances[gid] += int(roundDelta(instancer.getStoreDelta(store, outer, inner)))
+	}
+}
+
+// getDeltaSetIndex returns the outer and the inner index of the glyph in the item variation store.
+func getDeltaSetIndex(mapping []byte, gid int) (int, int) {
+	entryFormat := int(mapping[1])
+	count, pos := u16(mapping, 2), 4
+	if mapping[0] == 1 {
+		count, pos = u32(mapping, 2), 6
+	}
+	if count == 0 {
+		return 0, gid
+	}
+	if gid >= count {
+		gid = count - 1
+	}
+	size := (entryFormat>>4)&3 + 1
+	innerBits := entryFormat&0x0F + 1
+	entry := 0
+	for i := 0; i < size; i++ {
+		entry = entry<<8 | int(mapping[pos+gid*size+i])
+	}
+	return entry >> innerBits, entry & (1<<innerBits - 1)
+}
+
+// getStoreDelta returns the delta of the item in the item variation store.
+func (instancer *varInstancer) getStoreDelta(store []byte, outer, inner int) float64 {
+	if outer >= u16(store, 6) {
+		return 0
+	}
+	regionList := store[u32(store, 2):]
+	axisCount := u16(regionList, 0)
+	data := store[u32(store, 8+4*outer):]
+	itemCount := u16(data, 0)
+	wordDeltaCount := u16(data, 2)
+	regionCount := u16(data, 4)
+	if inner >= itemCount {
+		return 0
+	}
+	wordCount := wordDeltaCount & 0x7FFF
+	wordSize, smallSize := 2, 1
+	if wordDeltaCount&0x8000 != 0 {
+		wordSize, smallSize = 4, 2
+	}
+	rowSize := wordCount*wordSize + (regionCount-wordCount)*smallSize
+	pos := 6 + 2*regionCount + inner*rowSize
+	delta := 0.0
+	for r := 0; r < regionCount; r++ {
+		value := 0
+		size := smallSize
+		if r < wordCount {
+			size = wordSize
+		}
+		switch size {
+		case 4:
+			value = int(int32(binary.BigEndian.Uint32(data[pos:])))
+		case 2:
+			value = s16(data, pos)
+		default:
+			value = int(int8(data[pos]))
+		}
+		pos += size
+		region := regionList[4+6*axisCount*u16(data, 6+2*r):]
+		scalar := 1.0
+		for i := 0; i < axisCount && scalar != 0; i++ {
+			scalar *= getAxisScalar(instancer.getCoord(i),
+				f2Dot14ToFloat(region[6*i:]),
+				f2Dot14ToFloat(region[6*i+2:]),
+				f2Dot14ToFloat(region[6*i+4:]))
+		}
+		delta += scalar * float64(value)
+	}
+	return delta
+}
+
+// decodeGlyph decodes the glyph data from the glyf table.
+func decodeGlyph(data []byte) *varGlyph {
+	glyph := &varGlyph{}
+	if len(data) == 0 {
+		return glyph
+	}
+	glyph.numberOfContours = s16(data, 0)
+	glyph.xMin, glyph.yMin = s16(data, 2), s16(data, 4)
+	glyph.xMax, glyph.yMax = s16(data, 6), s16(data, 8)
+	pos := 10
+	if glyph.numberOfContours < 0 {
+		for {
+			flags := u16(data, pos)
+			c := varComponent{flags: flags, glyphIndex: u16(data, pos+2)}
+			pos += 4
+			if flags&argsAreWords != 0 {
+				if flags&argsAreXYValues != 0 {
+					c.arg1, c.arg2 = float64(s16(data, pos)), float64(s16(data, pos+2))
+				} else {
+					c.arg1, c.arg2 = float64(u16(data, pos)), float64(u16(data, pos+2))
+				}
+				pos += 4
+			} else {
+				if flags&argsAreXYValues != 0 {
+					c.arg1, c.arg2 = float64(int8(data[pos])), float64(int8(data[pos+1]))
+				} else {
+					c.arg1, c.arg2 = float64(data[pos]), float64(data[pos+1])
+				}
+				pos += 2
+			}
+			size := 0
+			if flags&weHaveAScale != 0 {
+				size = 2
+			} else if flags&weHaveXYScale != 0 {
+				size = 4
+			} else if flags&weHaveTwoByTwo != 0 {
+				size = 8
+			}
+			c.transform = data[pos : pos+size]
+			pos += size
+			glyph.components = append(glyph.components, c)
+			if flags&moreComponents == 0 {
+				if flags&weHaveInstructions != 0 {
+					n := u16(data, pos)
+					glyph.instructions = data[pos+2 : pos+2+n]
+				}
+				break
+			}
+		}
+		return glyph
+	}
+
+	glyph.endPts = make([]int, glyph.numberOfContours)
+	for i := range glyph.endPts {
+		glyph.endPts[i] = u16(data, pos)
+		pos += 2
+	}
+	numPoints := 0
+	if glyph.numberOfContours > 0 {
+		numPoints = glyph.endPts[glyph.numberOfContours-1] + 1
+	}
+	n := u16(data, pos)
+	glyph.instructions = data[pos+2 : pos+2+n]
+	pos += 2 + n
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		flag := data[pos]
+		pos++
+		flags = append(flags, flag)
+		if flag&repeatFlag != 0 {
+			count := int(data[pos])
+			pos++
+			for i := 0; i < count; i++ {
+				flags = append(flags, flag)
+			}
+		}
+	}
+	glyph.xs = make([]float64, numPoints)
+	glyph.ys = make([]float64, numPoints)
+	glyph.flags = make([]byte, numPoints)
+	pos = decodeCoords(data, pos, flags, glyph.xs, xShortVector, xIsSameOrPositive)
+	decodeCoords(data, pos, flags, glyph.ys, yShortVector, yIsSameOrPositive)
+	for i, flag := range flags[:numPoints] {
+		glyph.flags[i] = flag & (onCurvePoint | overlapSimple)
+	}
+	return glyph
+}
+
+func decodeCoords(data []byte, pos int, flags []byte, coords []float64, short, same byte) int {
+	value := 0
+	for i := range coords {
+		flag := flags[i]
+		if flag&short != 0 {
+			if flag&same != 0 {
+				value += int(data[pos])
+			} else {
+				value -= int(data[pos])
+			}
+			pos++
+		} else if flag&same == 0 {
+			value += s16(data, pos)
+			pos += 2
+		}
+		coords[i] = float64(value)
+	}
+	return pos
+}
+
+// encodeGlyph encodes the glyph for the glyf table.
+func encodeGlyph(glyph *varGlyph) []byte {
+	if glyph.numberOfContours == 0 {
+		return nil
+	}
+	var buf []byte
+	buf = binary.BigEndian.AppendUint16(buf, uint16(int16(glyph.numberOfContours)))
+	for _, value := range []int{glyph.xMin, glyph.yMin, glyph.xMax, glyph.yMax} {
+		buf = binary.BigEndian.AppendUint16(buf, uint16(int16(value)))
+	}
+	if glyph.numberOfContours < 0 {
+		for _, c := range glyph.components {
+			flags := c.flags &^ argsAreWords
+			if c.arg1 < -128 || c.arg1 > 127 || c.arg2 < -128 || c.arg2 > 127 ||
+				(flags&argsAreXYValues == 0 && (c.arg1 > 255 || c.arg2 > 255)) {
+				flags |= argsAreWords
+			}
+			buf = binary.BigEndian.AppendUint16(buf, uint16(flags))
+			buf = binary.BigEndian.AppendUint16(buf, uint16(c.glyphIndex))
+			if flags&argsAreWords != 0 {
+				buf = binary.BigEndian.AppendUint16(buf, uint16(int(c.arg1)))
+				buf = binary.BigEndian.AppendUint16(buf, uint16(int(c.arg2)))
+			} else {
+				buf = append(buf, byte(int(c.arg1)), byte(int(c.arg2)))
+			}
+			buf = append(buf, c.transform...)
+		}
+		if len(glyph.instructions) > 0 {
+			buf = binary.BigEndian.AppendUint16(buf, uint16(len(glyph.instructions)))
+			buf = append(buf, glyph.instructions...)
+		}
+		return buf
+	}
+
+	for _, endPt := range glyph.endPts {
+		buf = binary.BigEndian.AppendUint16(buf, uint16(endPt))
+	}
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(glyph.instructions)))
+	buf = append(buf, glyph.instructions...)
+	var pointFlags, xBuf, yBuf []byte
+	x, y := 0, 0
+	for i := range glyph.xs {
+		flag := glyph.flags[i]
+		dx := int(glyph.xs[i]) - x
+		dy := int(glyph.ys[i]) - y
+		x, y = int(glyph.xs[i]), int(glyph.ys[i])
+		flag, xBuf = encodeCoord(flag, dx, xBuf, xShortVector, xIsSameOrPositive)
+		flag, yBuf = encodeCoord(flag, dy, yBuf, yShortVector, yIsSameOrPositive)
+		pointFlags = append(pointFlags, flag)
+	}
+	var flags []byte
+	for i := 0; i < len(pointFlags); {
+		j := i + 1
+		for j < len(pointFlags) && pointFlags[j] == pointFlags[i] && j-i < 256 {
+			j++
+		}
+		if j-i > 1 {
+			flags = append(flags, pointFlags[i]|repeatFlag, byte(j-i-1))
+		} else {
+			flags = append(flags, pointFlags[i])
+		}
+		i = j
+	}
+	buf = append(buf, flags...)
+	buf = append(buf, xBuf...)
+	return append(buf, yBuf...)
+}
+
+func encodeCoord(flag byte, delta int, buf []byte, short, same byte) (byte, []byte) {
+	if delta == 0 {
+		return flag | same, buf
+	}
+	if delta >= -255 && delta <= 255 {
+		if delta > 0 {
+			return flag | short | same, append(buf, byte(delta))
+		}
+		return flag | short, append(buf, byte(-delta))
+	}
+	return flag, binary.BigEndian.AppendUint16(buf, uint16(int16(delta)))
+}
+
+// getGlyphBounds updates the bounding box of the glyph after the points have moved.
+func getGlyphBounds(glyphs []*varGlyph, gid int, done []bool) {
+	if done[gid] {
+		return
+	}
+	done[gid] = true
+	glyph := glyphs[gid]
+	if glyph.numberOfContours > 0 {
+		if len(glyph.xs) == 0 {
+			return
+		}
+		xMin, yMin := glyph.xs[0], glyph.ys[0]
+		xMax, yMax := xMin, yMin
+		for i := range glyph.xs {
+			xMin, xMax = math.Min(xMin, glyph.xs[i]), math.Max(xMax, glyph.xs[i])
+			yMin, yMax = math.Min(yMin, glyph.ys[i]), math.Max(yMax, glyph.ys[i])
+		}
+		glyph.xMin, glyph.yMin = int(xMin), int(yMin)
+		glyph.xMax, glyph.yMax = int(xMax), int(yMax)
+		return
+	}
+	if glyph.numberOfContours == 0 {
+		return
+	}
+	xMin, yMin := math.Inf(1), math.Inf(1)
+	xMax, yMax := math.Inf(-1), math.Inf(-1)
+	for _, c := range glyph.components {
+		if c.glyphIndex >= len(glyphs) {
+			continue
+		}
+		getGlyphBounds(glyphs, c.glyphIndex, done)
+		component := glyphs[c.glyphIndex]
+		if component.numberOfContours == 0 {
+			continue
+		}
+		a, b, cc, d := 1.0, 0.0, 0.0, 1.0
+		switch len(c.transform) {
+		case 2:
+			a = f2Dot14ToFloat(c.transform)
+			d = a
+		case 4:
+			a, d = f2Dot14ToFloat(c.transform), f2Dot14ToFloat(c.transform[2:])
+		case 8:
+			a, b = f2Dot14ToFloat(c.transform), f2Dot14ToFloat(c.transform[2:])
+			cc, d = f2Dot14ToFloat(c.transform[4:]), f2Dot14ToFloat(c.transform[6:])
+		}
+		dx, dy := 0.0, 0.0
+		if c.flags&argsAreXYValues != 0 {
+			dx, dy = c.arg1, c.arg2
+		}
+		for _, x := range []float64{float64(component.xMin), float64(component.xMax)} {
+			for _, y := range []float64{float64(component.yMin), float64(component.yMax)} {
+				tx := a*x + cc*y + dx
+				ty := b*x + d*y + dy
+				xMin, xMax = math.Min(xMin, tx), math.Max(xMax, tx)
+				yMin, yMax = math.Min(yMin, ty), math.Max(yMax, ty)
+			}
+		}
+	}
+	if xMin <= xMax {
+		glyph.xMin, glyph.yMin = int(math.Floor(xMin)), int(math.Floor(yMin))
+		glyph.xMax, glyph.yMax = int(math.Ceil(xMax)), int(math.Ceil(yMax))
+	}
+}
@@ -0,0 +1,241 @@
+package pdfjet
+
+/**
+ * hyphenator.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Hyphenator finds the hyphenation points of the words using Liang's algorithm
+// and the TeX hyphenation patterns. The patterns for many languages are
+// available from the hyph-utf8 project.
+type Hyphenator struct {
+	patterns   map[string][]byte // The letters of the pattern and the values between them
+	exceptions map[string][]int  // The hyphenation points of the exception words
+	maxLength  int               // The number of letters of the longest pattern
+	leftMin    int
+	rightMin   int
+}
+
+// NewHyphenatorFromFile loads the hyphenation patterns from file.
+// The program exits if the file cannot be read. Use NewHyphenatorFromFileErr to handle the error.
+func NewHyphenatorFromFile(filePath string) *Hyphenator {
+	hyphenator, err := NewHyphenatorFromFileErr(filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return hyphenator
+}
+
+// NewHyphenatorFromFileErr loads the hyphenation patterns from file.
+// Returns IOError if the file cannot be opened.
+func NewHyphenatorFromFileErr(filePath string) (*Hyphenator, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
+	}
+	defer file.Close()
+	return NewHyphenatorErr(file)
+}
+
+// NewHyphenator loads the hyphenation patterns from the reader.
+// The program exits if the patterns cannot be read. Use NewHyphenatorErr to handle the error.
+func NewHyphenator(reader io.Reader) *Hyphenator {
+	hyphenator, err := NewHyphenatorErr(reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return hyphenator
+}
+
+// NewHyphenatorErr loads the hyphenation patterns from the reader.
+// The reader provides UTF-8 text with the patterns in the TeX format - inside
+// \patterns{...} and the exceptions inside \hyphenation{...} - or one pattern per line.
+// The text after % is a comment. The other TeX commands are ignored.
+// Returns MalformedInputError if a pattern is invalid or there are no patterns
+// and IOError if the reader fails.
+func NewHyphenatorErr(reader io.Reader) (*Hyphenator, error) {
+	hyphenator := &Hyphenator{
+		patterns:   make(map[string][]byte),
+		exceptions: make(map[string][]int),
+		leftMin:    2,
+		rightMin:   3,
+	}
+	const (
+		plainList = iota
+		patterns
+		exceptions
+		otherCommand
+	)
+	section := plainList
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '%'); i >= 0 {
+			line = line[:i]
+		}
+		for _, token := range strings.Fields(line) {
+			if strings.HasPrefix(token, "\\") {
+				i := strings.IndexByte(token, '{')
+				if i < 0 {
+					continue // The command has no argument
+				}
+				switch token[1:i] {
+				case "patterns":
+					section = patterns
+				case "hyphenation":
+					section = exceptions
+				default:
+					section = otherCommand
+				}
+				token = token[i+1:]
+			}
+			if section == otherCommand {
+				if strings.Contains(token, "}") {
+					section = plainList
+				}
+				continue
+			}
+			closing := strings.HasSuffix(token, "}")
+			token = strings.TrimSuffix(token, "}")
+			if token != "" {
+				var err error
+				if section == exceptions {
+					hyphenator.addException(token)
+				} else {
+					err = hyphenator.addPattern(token)
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			if closing {
+				section = plainList
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &IOError{Op: "read", Err: err}
+	}
+	if len(hyphenator.patterns) == 0 {
+		return nil, malformed("hyphenation patterns", "no patterns found")
+	}
+	return hyphenator, nil
+}
+
+// addPattern adds pattern like "a1b2c" - the digits are the values between the letters.
+func (hyphenator *Hyphenator) addPattern(pattern string) error {
+	var letters strings.Builder
+	values := []byte{0}
+	for _, ch := range pattern {
+		if ch >= '0' && ch <= '9' {
+			values[len(values)-1] = byte(ch - '0')
+		} else if ch == '.' || unicode.IsLetter(ch) || unicode.IsMark(ch) || ch == '\'' || ch == 0x2019 {
+			letters.WriteRune(unicode.ToLower(ch))
+			values = append(values, 0)
+		} else {
+			return malformed("hyphenation patterns", "invalid pattern %q", pattern)
+		}
+	}
+	key := letters.String()
+	hyphenator.patterns[key] = values
+	if length := len(values) - 1; length > hyphenator.maxLength {
+		hyphenator.maxLength = length
+	}
+	return nil
+}
+
+// addException adds the word with the hyphenation points marked with hyphens like "ta-ble".
+func (hyphenator *Hyphenator) addException(word string) {
+	var letters strings.Builder
+	points := make([]int, 0)
+	n := 0
+	for _, ch := range word {
+		if ch == '-' {
+			points = append(points, n)
+		} else {
+			letters.WriteRune(unicode.ToLower(ch))
+			n++
+		}
+	}
+	hyphenator.exceptions[letters.String()] = points
+}
+
+// SetMinimums sets the minimum number of letters before the first and after the last hyphenation point.
+// The default values are 2 and 3.
+func (hyphenator *Hyphenator) SetMinimums(leftMin, rightMin int) {
+	hyphenator.leftMin = leftMin
+	hyphenator.rightMin = rightMin
+}
+
+// Hyphenate returns the positions in the word, counted in characters,
+// where the word can be broken with a hyphen.
+func (hyphenator *Hyphenator) Hyphenate(word string) []int {
+	runes := []rune(word)
+	for i, ch := range runes {
+		runes[i] = unicode.ToLower(ch)
+	}
+	n := len(runes)
+	points := make([]int, 0)
+	if n < hyphenator.leftMin+hyphenator.rightMin {
+		return points
+	}
+	if exception, ok := hyphenator.exceptions[string(runes)]; ok {
+		for _, k := range exception {
+			if k >= hyphenator.leftMin && n-k >= hyphenator.rightMin {
+				points = append(points, k)
+			}
+		}
+		return points
+	}
+	// The values between the characters of the word enclosed in dots
+	padded := append(append([]rune{'.'}, runes...), '.')
+	values := make([]byte, len(padded)+1)
+	for i := range padded {
+		for j := i + 1; j <= len(padded) && j-i <= hyphenator.maxLength; j++ {
+			pattern, ok := hyphenator.patterns[string(padded[i:j])]
+			if !ok {
+				continue
+			}
+			for k, value := range pattern {
+				if value > values[i+k] {
+					values[i+k] = value
+				}
+			}
+		}
+	}
+	// The odd values are the hyphenation points. The value at k+1 is between runes[k-1] and runes[k].
+	for k := hyphenator.leftMin; k <= n-hyphenator.rightMin; k++ {
+		if values[k+1]%2 == 1 {
+			points = append(points, k)
+		}
+	}
+	return points
+}
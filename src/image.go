@@ -26,6 +26,7 @@ SOFTWARE.
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"log"
 	"math"
@@ -61,7 +62,20 @@ type Image struct {
 	actualText     string
 }
 
+// NewImageFromFile constructs image from the file at the specified path.
+// The image type is determined by the file extension.
+// The program exits if the image cannot be read. Use NewImageFromFileErr to handle the error.
 func NewImageFromFile(pdf *PDF, filePath string) *Image {
+	image, err := NewImageFromFileErr(pdf, filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return image
+}
+
+// NewImageFromFileErr constructs image from the file at the specified path.
+// The image type is determined by the file extension.
+func NewImageFromFileErr(pdf *PDF, filePath string) (*Image, error) {
 	var imageType int
 	if strings.HasSuffix(strings.ToLower(filePath), ".png") {
 		imageType = imagetype.PNG
@@ -71,28 +85,45 @@ func NewImageFromFile(pdf *PDF, filePath string) *Image {
 		strings.HasSuffix(strings.ToLower(filePath), ".jpeg") {
 		imageType = imagetype.JPG
 	} else {
-		log.Fatal("Invalid image file extension.")
+		return nil, unsupported("image file extension: %s", filePath)
 	}
 	file, err := os.Open(filePath)
 	if err != nil {
-		log.Fatal(err)
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
 	}
 	defer file.Close()
-	return NewImage(pdf, bufio.NewReader(file), imageType)
+	return NewImageErr(pdf, bufio.NewReader(file), imageType)
 }
 
 // NewImage the main constructor for the Image class.
+// The program exits if the image cannot be decoded. Use NewImageErr to handle the error.
 // @param pdf the PDF to which we add this image.
 // @param inputStream the input stream to read the image from.
 // @param imageType imagetype.JPG, imagetype.PNG or imagetype.BMP.
 func NewImage(pdf *PDF, reader io.Reader, imageType int) *Image {
+	image, err := NewImageErr(pdf, reader, imageType)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return image
+}
+
+// NewImageErr is the error returning variant of NewImage.
+// Nothing is written to the PDF unless the image is decoded successfully.
+// @param pdf the PDF to which we add this image.
+// @param inputStream the input stream to read the image from.
+// @param imageType imagetype.JPG, imagetype.PNG or imagetype.BMP.
+func NewImageErr(pdf *PDF, reader io.Reader, imageType int) (*Image, error) {
 	image := new(Image)
 	image.altDescription = single.Space
 	image.actualText = single.Space
 
 	switch imageType {
 	case imagetype.JPG:
-		jpg := NewJPGImage(reader)
+		jpg, err := NewJPGImageErr(reader)
+		if err != nil {
+			return nil, err
+		}
 		data := jpg.GetData()
 		image.w = float32(jpg.GetWidth())
 		image.h = float32(jpg.GetHeight())
@@ -104,7 +135,10 @@ func NewImage(pdf *PDF, reader io.Reader, imageType int) *Image {
 			image.addImageToPDF(pdf, data, nil, imageType, device.CMYK, 8)
 		}
 	case imagetype.PNG:
-		png := NewPNGImage(reader)
+		png, err := NewPNGImageErr(reader)
+		if err != nil {
+			return nil, err
+		}
 		data := png.GetData()
 		image.w = float32(png.GetWidth())
 		image.h = float32(png.GetHeight())
@@ -118,26 +152,47 @@ func NewImage(pdf *PDF, reader io.Reader, imageType int) *Image {
 			image.addImageToPDF(pdf, data, png.GetAlpha(), imageType, device.RGB, bitDepth)
 		}
 	case imagetype.BMP:
-		bmp := NewBMPImage(reader)
+		bmp, err := NewBMPImageErr(reader)
+		if err != nil {
+			return nil, err
+		}
 		data := bmp.GetData()
 		image.w = float32(bmp.GetWidth())
 		image.h = float32(bmp.GetHeight())
 		image.addImageToPDF(pdf, data, nil, imageType, device.RGB, 8)
+	default:
+		return nil, unsupported("image type %d", imageType)
 	}
 
-	return image
+	return image, nil
 }
 
 // NewImage2 adds this image to the existing PDF objects.
+// The program exits if the image cannot be decoded. Use NewImage2Err to handle the error.
 // @param objects the map to which we add this image.
 // @param inputStream the input stream to read the image from.
 // @param imageType ImageType.JPG, ImageType.PNG and ImageType.BMP.
 func NewImage2(objects *[]*PDFobj, reader io.Reader, imageType int) *Image {
+	image, err := NewImage2Err(objects, reader, imageType)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return image
+}
+
+// NewImage2Err is the error returning variant of NewImage2.
+// @param objects the map to which we add this image.
+// @param inputStream the input stream to read the image from.
+// @param imageType ImageType.JPG, ImageType.PNG and ImageType.BMP.
+func NewImage2Err(objects *[]*PDFobj, reader io.Reader, imageType int) (*Image, error) {
 	image := new(Image)
 
 	switch imageType {
 	case imagetype.JPG:
-		jpg := NewJPGImage(reader)
+		jpg, err := NewJPGImageErr(reader)
+		if err != nil {
+			return nil, err
+		}
 		data := jpg.GetData()
 		image.w = float32(jpg.GetWidth())
 		image.h = float32(jpg.GetHeight())
@@ -149,7 +204,10 @@ func NewImage2(objects *[]*PDFobj, reader io.Reader, imageType int) *Image {
 			image.addImageToObjects(objects, data, nil, imageType, device.CMYK, 8)
 		}
 	case imagetype.PNG:
-		png := NewPNGImage(reader)
+		png, err := NewPNGImageErr(reader)
+		if err != nil {
+			return nil, err
+		}
 		data := png.GetData()
 		image.w = png.GetWidth()
 		image.h = png.GetHeight()
@@ -163,31 +221,47 @@ func NewImage2(objects *[]*PDFobj, reader io.Reader, imageType int) *Image {
 			image.addImageToObjects(objects, data, png.GetAlpha(), imageType, device.RGB, bitDepth)
 		}
 	case imagetype.BMP:
-		bmp := NewBMPImage(reader)
+		bmp, err := NewBMPImageErr(reader)
+		if err != nil {
+			return nil, err
+		}
 		data := bmp.GetData()
 		image.w = bmp.GetWidth()
 		image.h = bmp.GetHeight()
 		image.addImageToObjects(objects, data, nil, imageType, device.RGB, 8)
+	default:
+		return nil, unsupported("image type %d", imageType)
 	}
 
-	return image
+	return image, nil
 }
 
 // NewImageFromPDFobj constructs new image from an existing PDF object.
+// The program exits if the object is not a valid image. Use NewImageFromPDFobjErr to handle the error.
 func NewImageFromPDFobj(pdf *PDF, obj *PDFobj) *Image {
+	image, err := NewImageFromPDFobjErr(pdf, obj)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return image
+}
+
+// NewImageFromPDFobjErr constructs new image from an existing PDF object.
+// Returns MalformedInputError if the object is missing valid /Width or /Height.
+func NewImageFromPDFobjErr(pdf *PDF, obj *PDFobj) (*Image, error) {
 	image := new(Image)
 	image.altDescription = single.Space
 	image.actualText = single.Space
 
 	val, err := strconv.ParseFloat(obj.getValue("/Width"), 32)
 	if err != nil {
-		log.Fatal(err)
+		return nil, malformedWrap("image object", "bad /Width", err)
 	}
 	image.w = float32(val)
 
 	val, err = strconv.ParseFloat(obj.getValue("/Height"), 32)
 	if err != nil {
-		log.Fatal(err)
+		return nil, malformedWrap("image object", "bad /Height", err)
 	}
 	image.h = float32(val)
 
@@ -225,18 +299,19 @@ func NewImageFromPDFobj(pdf *PDF, obj *PDFobj) *Image {
 		pdf.appendString(imageMask)
 		pdf.appendString("\n")
 	}
+	stream := pdf.encrypt(obj.stream)
 	pdf.appendString("/Length ")
-	pdf.appendInteger(len(obj.stream))
+	pdf.appendInteger(len(stream))
 	pdf.appendString("\n")
 	pdf.appendString(">>\n")
 	pdf.appendString("stream\n")
-	pdf.appendByteArray(obj.stream)
+	pdf.appendByteArray(stream)
 	pdf.appendString("\nendstream\n")
 	pdf.endobj()
 	pdf.images = append(pdf.images, image)
 	image.objNumber = pdf.getObjNumber()
 
-	return image
+	return image, nil
 }
 
 // SetLocation sets the location of this image on the page to (x, y).
@@ -308,13 +383,23 @@ func (image *Image) SetGoToAction(key *string) {
 	image.key = key
 }
 
-// SetRotate sets the image rotation to the specified number of degrees.
+// RotateClockwise sets the image rotation to the specified number of degrees.
+// The program exits if the angle is not 0, 90, 180 or 270. Use RotateClockwiseErr to handle the error.
 // @param degrees the number of degrees.
 func (image *Image) RotateClockwise(degrees int) {
+	if err := image.RotateClockwiseErr(degrees); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// RotateClockwiseErr sets the image rotation to the specified number of degrees.
+// Returns an error if the angle is not 0, 90, 180 or 270.
+func (image *Image) RotateClockwiseErr(degrees int) error {
 	if degrees != 0 && degrees != 90 && degrees != 180 && degrees != 270 {
-		log.Fatal("The rotation angle must be 0, 90, 180 or 270")
+		return fmt.Errorf("pdfjet: the rotation angle must be 0, 90, 180 or 270")
 	}
 	image.degrees = degrees
+	return nil
 }
 
 // SetAltDescription sets the alternate description of this image.
@@ -460,6 +545,7 @@ func (image *Image) addSoftMask(pdf *PDF, data []byte, colorSpace string, bitsPe
 	pdf.appendString("/BitsPerComponent ")
 	pdf.appendInteger(bitsPerComponent)
 	pdf.appendString("\n")
+	data = pdf.encrypt(data)
 	pdf.appendString("/Length ")
 	pdf.appendInteger(len(data))
 	pdf.appendString("\n")
@@ -512,6 +598,7 @@ func (image *Image) addImageToPDF(
 		// If the image was created with Photoshop - invert the colors:
 		pdf.appendString("/Decode [1.0 0.0 1.0 0.0 1.0 0.0 1.0 0.0]\n")
 	}
+	data = pdf.encrypt(data)
 	pdf.appendString("/Length ")
 	pdf.appendInteger(len(data))
 	pdf.appendString("\n")
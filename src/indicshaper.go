@@ -0,0 +1,449 @@
+package pdfjet
+
+/**
+ * indicshaper.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// The Indic character categories.
+const (
+	indicOther       = iota
+	indicConsonant   // Consonant
+	indicRa          // The consonant RA that forms reph
+	indicVowel       // Independent vowel
+	indicNukta       // Nukta
+	indicHalant      // Halant or virama
+	indicMatra       // Dependent vowel sign
+	indicPreMatra    // Dependent vowel sign drawn before the base consonant
+	indicModifier    // Candrabindu, anusvara, visarga and the Vedic signs
+	indicZWJ         // Zero width joiner
+	indicZWNJ        // Zero width non-joiner
+	indicPlaceholder // NBSP and dotted circle used as the base of the marks
+)
+
+// The positions of the characters in the Indic syllable.
+// The order is the visual order after the final reordering.
+const (
+	indicPosStart = iota
+	indicPosReph
+	indicPosPreMatra
+	indicPosPref // Pre-base reordering consonant form
+	indicPosPreBase
+	indicPosBase
+	indicPosBelowBase
+	indicPosMatra
+	indicPosPostBase
+	indicPosModifier
+)
+
+// The masks of the Indic features applied to some of the glyphs of the syllable.
+const (
+	indicRphfMask uint32 = 1 << (iota + 1)
+	indicPrefMask
+	indicBlwfMask
+	indicAbvfMask
+	indicHalfMask
+	indicPstfMask
+)
+
+// The Indic features applied one after another before the final reordering.
+var indicBasicFeatures = []otFeature{
+	{"nukt", globalMask},
+	{"akhn", globalMask},
+	{"rphf", indicRphfMask},
+	{"rkrf", globalMask},
+	{"pref", indicPrefMask},
+	{"blwf", indicBlwfMask},
+	{"abvf", indicAbvfMask},
+	{"half", indicHalfMask},
+	{"pstf", indicPstfMask},
+	{"vatu", globalMask},
+	{"cjct", globalMask},
+}
+
+// The Indic features applied after the final reordering.
+var indicPresentationFeatures = []string{"pres", "abvs", "blws", "psts", "haln", "rlig", "rclt", "calt", "liga", "clig"}
+
+// getIndicCategory returns the category of Devanagari character.
+func getIndicCategory(ch rune) int {
+	switch ch {
+	case 0x200C:
+		return indicZWNJ
+	case 0x200D:
+		return indicZWJ
+	case 0x00A0, 0x25CC:
+		return indicPlaceholder
+	case 0x0930:
+		return indicRa
+	case 0x093C:
+		return indicNukta
+	case 0x094D:
+		return indicHalant
+	case 0x093F, 0x094E:
+		return indicPreMatra
+	case 0x093D, 0x0950:
+		return indicOther
+	}
+	switch {
+	case ch < 0x0900 || ch > 0x097F:
+		return indicOther
+	case ch <= 0x0903 || (ch >= 0x0951 && ch <= 0x0954):
+		return indicModifier
+	case ch <= 0x0914 || ch == 0x0960 || ch == 0x0961 || (ch >= 0x0972 && ch <= 0x0977):
+		return indicVowel
+	case ch <= 0x0939 || (ch >= 0x0958 && ch <= 0x095F) || ch >= 0x0978:
+		return indicConsonant
+	case ch <= 0x094F || (ch >= 0x0955 && ch <= 0x0957) || ch == 0x0962 || ch == 0x0963:
+		return indicMatra
+	}
+	return indicOther
+}
+
+// isIndicConsonant returns true if the category can be the base of the syllable.
+func isIndicConsonant(category int) bool {
+	return category == indicConsonant || category == indicRa || category == indicPlaceholder
+}
+
+// shapeIndic finds the syllables, reorders them and applies the Indic features.
+// See the OpenType specification, "Creating and supporting OpenType fonts for Devanagari".
+func (s *otShaper) shapeIndic() {
+	for i := range s.glyphs {
+		s.glyphs[i].category = getIndicCategory(s.runes[s.glyphs[i].cluster])
+	}
+	s.findIndicSyllables()
+	for start := 0; start < len(s.glyphs); {
+		end := s.getSyllableEnd(start)
+		if isIndicConsonant(s.glyphs[start].category) {
+			s.reorderIndicSyllable(start, end)
+		}
+		start = end
+	}
+
+	s.substitute(getGlobalFeatures([]string{"locl", "ccmp"}))
+	for _, feature := range indicBasicFeatures {
+		if feature.mask == indicPrefMask {
+			s.substitutePref()
+		} else {
+			s.substitute([]otFeature{feature})
+		}
+	}
+	for start := 0; start < len(s.glyphs); {
+		end := s.getSyllableEnd(start)
+		s.reorderIndicGlyphs(start, end)
+		start = end
+	}
+	s.substitute(getGlobalFeatures(indicPresentationFeatures))
+}
+
+// findIndicSyllables numbers the syllables. The consonant syllable is
+// (C N? H ZW?)* C N? (ZW? M N?)* (H ZW?)? SM* and the vowel syllable is V N? (ZW? M N?)* SM*.
+func (s *otShaper) findIndicSyllables() {
+	category := func(i int) int {
+		if i < len(s.glyphs) {
+			return s.glyphs[i].category
+		}
+		return -1
+	}
+	skip := func(i, category1, category2 int) int {
+		if c := category(i); c == category1 || c == category2 {
+			return i + 1
+		}
+		return i
+	}
+	syllable := 0
+	for i := 0; i < len(s.glyphs); syllable++ {
+		start := i
+		c := category(i)
+		if isIndicConsonant(c) || c == indicVowel {
+			i = skip(i+1, indicNukta, indicNukta)
+			for isIndicConsonant(c) && category(i) == indicHalant {
+				j := skip(i+1, indicZWJ, indicZWNJ)
+				if !isIndicConsonant(category(j)) {
+					break
+				}
+				i = skip(j+1, indicNukta, indicNukta)
+			}
+			for {
+				j := skip(i, indicZWJ, indicZWNJ)
+				if c := category(j); c != indicMatra && c != indicPreMatra {
+					break
+				}
+				i = skip(j+1, indicNukta, indicNukta)
+			}
+			if isIndicConsonant(c) && category(i) == indicHalant {
+				i = skip(i+1, indicZWJ, indicZWNJ)
+			}
+			for category(i) == indicModifier {
+				i++
+			}
+		} else {
+			i++
+		}
+		for k := start; k < i; k++ {
+			s.glyphs[k].syllable = syllable
+		}
+	}
+}
+
+// getSyllableEnd returns the position after the last glyph of the syllable that starts at position start.
+func (s *otShaper) getSyllableEnd(start int) int {
+	end := start + 1
+	for end < len(s.glyphs) && s.glyphs[end].syllable == s.glyphs[start].syllable {
+		end++
+	}
+	return end
+}
+
+// reorderIndicSyllable finds the base consonant and the reph, sets the positions
+// and the feature masks and moves the pre-base matras before the consonants.
+func (s *otShaper) reorderIndicSyllable(start, end int) {
+	glyphs := s.glyphs[start:end]
+	// The syllable that starts with RA and halant has reph if there is other consonant after them.
+	limit := 0
+	if len(glyphs) >= 3 && glyphs[0].category == indicRa && glyphs[1].category == indicHalant &&
+		glyphs[2].category != indicZWJ && s.wouldSubstitute("rphf", glyphs[0].gid, glyphs[1].gid) {
+		limit = 2
+	}
+	base := s.findIndicBase(glyphs, limit)
+	if base == len(glyphs) && limit > 0 {
+		limit = 0
+		base = s.findIndicBase(glyphs, limit)
+	}
+	if base == len(glyphs) {
+		base = 0
+	}
+
+	for i := range glyphs {
+		g := &glyphs[i]
+		switch {
+		case i < limit:
+			g.position = indicPosReph
+			g.mask |= indicRphfMask
+		case g.category == indicPreMatra:
+			g.position = indicPosPreMatra
+		case g.category == indicMatra:
+			g.position = indicPosMatra
+		case g.category == indicModifier:
+			g.position = indicPosModifier
+		case i == base:
+			g.position = indicPosBase
+		case isIndicConsonant(g.category):
+			if i < base {
+				g.position = indicPosPreBase
+			} else {
+				g.position = s.getConsonantPosition(g.gid)
+			}
+		case i > 0:
+			// Nukta, halant and the joiners belong to the character before them.
+			g.position = glyphs[i-1].position
+		}
+		if i < base {
+			g.mask |= indicHalfMask | indicBlwfMask
+		} else if i > base {
+			g.mask |= indicBlwfMask | indicAbvfMask | indicPstfMask | indicPrefMask
+		}
+	}
+
+	// Move the pre-base matras after the reph.
+	for i := limit; i < len(glyphs); i++ {
+		if glyphs[i].category == indicPreMatra {
+			matra := glyphs[i]
+			copy(glyphs[limit+1:i+1], glyphs[limit:i])
+			glyphs[limit] = matra
+			limit++
+		}
+	}
+}
+
+// findIndicBase returns the position of the base consonant in the glyphs of the syllable.
+// The base is the last consonant that does not have below-base or post-base form.
+// Returns the number of glyphs if there is no consonant after the limit.
+func (s *otShaper) findIndicBase(glyphs []otGlyph, limit int) int {
+	base := len(glyphs)
+	seenBelow := false
+	for i := len(glyphs) - 1; i >= limit; i-- {
+		g := glyphs[i]
+		if isIndicConsonant(g.category) {
+			base = i
+			position := s.getConsonantPosition(g.gid)
+			if position != indicPosBelowBase && (position != indicPosPostBase || seenBelow) {
+				break
+			}
+			if position == indicPosBelowBase {
+				seenBelow = true
+			}
+		} else if i > 0 && g.category == indicZWJ && glyphs[i-1].category == indicHalant {
+			// Halant followed by ZWJ requests the half form of the consonant before it.
+			break
+		}
+	}
+	return base
+}
+
+// getConsonantPosition returns the position of the consonant when it follows the base consonant.
+// The consonants that have below-base or post-base forms in the font are not the base.
+func (s *otShaper) getConsonantPosition(gid int) int {
+	key := [2]int{s.script, gid}
+	if position, ok := s.layout.consonantPositions[key]; ok {
+		return position
+	}
+	halant := s.font.getGlyphID(0x094D)
+	position := indicPosBase
+	if s.wouldSubstitute("blwf", halant, gid) || s.wouldSubstitute("blwf", gid, halant) {
+		position = indicPosBelowBase
+	} else if s.wouldSubstitute("pstf", halant, gid) || s.wouldSubstitute("pstf", gid, halant) ||
+		s.wouldSubstitute("pref", halant, gid) || s.wouldSubstitute("pref", gid, halant) {
+		position = indicPosPostBase
+	}
+	if s.layout.consonantPositions == nil {
+		s.layout.consonantPositions = make(map[[2]int]int)
+	}
+	s.layout.consonantPositions[key] = position
+	return position
+}
+
+// wouldSubstitute returns true if the feature changes the glyphs.
+func (s *otShaper) wouldSubstitute(tag string, gids ...int) bool {
+	t := &otShaper{font: s.font, layout: s.layout, script: s.script}
+	for _, gid := range gids {
+		g := otGlyph{gid: gid, length: 1, mask: globalMask, class: glyphClassBase}
+		g.class = t.getSubstitutedClass(gid, g.class)
+		t.glyphs = append(t.glyphs, g)
+	}
+	t.substitute([]otFeature{{tag, globalMask}})
+	if len(t.glyphs) != len(gids) {
+		return true
+	}
+	for i, g := range t.glyphs {
+		if g.gid != gids[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// substitutePref applies the pref feature and marks the glyphs it has formed
+// so that they are moved before the base consonant.
+func (s *otShaper) substitutePref() {
+	before := make(map[int]int)
+	for _, g := range s.glyphs {
+		if g.mask&indicPrefMask != 0 {
+			before[g.cluster] = g.gid
+		}
+	}
+	s.substitute([]otFeature{{"pref", indicPrefMask}})
+	for i := range s.glyphs {
+		g := &s.glyphs[i]
+		if gid, ok := before[g.cluster]; ok && g.gid != gid {
+			g.position = indicPosPref
+		}
+	}
+}
+
+// reorderIndicGlyphs moves the pre-base matras, the reph and the pre-base reordering
+// consonants of the syllable to their final positions after the basic features were applied.
+func (s *otShaper) reorderIndicGlyphs(start, end int) {
+	glyphs := s.glyphs[start:end]
+	base := 0
+	for base < len(glyphs) && (glyphs[base].position < indicPosBase || glyphs[base].position == indicPosPref) {
+		base++
+	}
+	hasReph := len(glyphs) > 1 && glyphs[0].position == indicPosReph && glyphs[1].position != indicPosReph
+
+	// The pre-base matras are moved after the last halant before the base that did not form half form.
+	first := 0
+	if hasReph {
+		first = 1
+	}
+	last := first
+	for last < len(glyphs) && glyphs[last].position == indicPosPreMatra {
+		last++
+	}
+	if last > first {
+		if target := getHalantTarget(glyphs, last, base, false); target >= 0 {
+			moveGlyphs(glyphs, first, last, target)
+		}
+	}
+
+	// The reph is moved after the first visible halant before the base
+	// or before the post-base consonants and the syllable modifiers.
+	if hasReph {
+		target := getHalantTarget(glyphs, 1, base, true)
+		if target < 0 {
+			target = len(glyphs)
+			for i := base + 1; i < len(glyphs); i++ {
+				if glyphs[i].position >= indicPosPostBase {
+					target = i
+					break
+				}
+			}
+		}
+		moveGlyphs(glyphs, 0, 1, target)
+		base--
+	}
+
+	// The pre-base reordering consonants are moved like the pre-base matras or just before the base.
+	for i := base + 1; i < len(glyphs); i++ {
+		if glyphs[i].position == indicPosPref {
+			target := getHalantTarget(glyphs, 0, base, false)
+			if target < 0 {
+				target = base
+			}
+			moveGlyphs(glyphs, i, i+1, target)
+			base++
+		}
+	}
+}
+
+// getHalantTarget returns the position after the first or the last halant glyph
+// between the positions from and to, and after ZWJ or ZWNJ that follows it. Returns -1 if there is no halant.
+func getHalantTarget(glyphs []otGlyph, from, to int, first bool) int {
+	halant := -1
+	for i := from; i < to; i++ {
+		if glyphs[i].category == indicHalant && glyphs[i].position != indicPosReph {
+			halant = i
+			if first {
+				break
+			}
+		}
+	}
+	if halant < 0 {
+		return -1
+	}
+	target := halant + 1
+	for target < to && (glyphs[target].category == indicZWJ || glyphs[target].category == indicZWNJ) {
+		target++
+	}
+	return target
+}
+
+// moveGlyphs moves the glyphs from positions start to end so that they are before the glyph at position target.
+func moveGlyphs(glyphs []otGlyph, start, end, target int) {
+	moved := append([]otGlyph(nil), glyphs[start:end]...)
+	if target > end {
+		copy(glyphs[start:], glyphs[end:target])
+		copy(glyphs[target-len(moved):], moved)
+	} else if target < start {
+		copy(glyphs[target+len(moved):], glyphs[target:start])
+		copy(glyphs[target:], moved)
+	}
+}
@@ -73,10 +73,29 @@ const (
 )
 
 // NewJPGImage is the constructor.
+// The program exits if the image cannot be read. Use NewJPGImageErr to handle the error.
 func NewJPGImage(reader io.Reader) *JPGImage {
+	image, err := NewJPGImageErr(reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return image
+}
+
+// NewJPGImageErr is the constructor that returns IOError or MalformedInputError
+// when the image cannot be read.
+func NewJPGImageErr(reader io.Reader) (_ *JPGImage, err error) {
+	defer recoverMalformedInput("JPEG image", &err)
+
 	image := new(JPGImage)
-	image.data = content.GetFromReader(reader)
-	return image.readJPGImage(image.data)
+	image.data, err = content.GetFromReaderErr(reader)
+	if err != nil {
+		return nil, &IOError{Op: "read", Err: err}
+	}
+	if err := image.readJPGImage(image.data); err != nil {
+		return nil, err
+	}
+	return image, nil
 }
 
 // GetWidth returns the width of the image.
@@ -104,14 +123,17 @@ func (image *JPGImage) GetData() []byte {
 	return image.data
 }
 
-func (image *JPGImage) readJPGImage(buffer []byte) *JPGImage {
-	if buffer[image.index] != 0xFF || buffer[image.index+1] != 0xD8 {
-		log.Fatal("Error: Invalid JPEG header.")
+func (image *JPGImage) readJPGImage(buffer []byte) error {
+	if len(buffer) < 2 || buffer[0] != 0xFF || buffer[1] != 0xD8 {
+		return malformed("JPEG image", "invalid JPEG header")
 	}
 
 	image.index += 2
 	for {
-		ch := image.nextMarker(buffer)
+		ch, err := image.nextMarker(buffer)
+		if err != nil {
+			return err
+		}
 		// Note that marker codes 0xC4, 0xC8, 0xCC are not,
 		// and must not be treated as SOFn. C4 in particular
 		// is actually DHT.
@@ -136,12 +158,12 @@ func (image *JPGImage) readJPGImage(buffer []byte) *JPGImage {
 			image.index += 2
 			image.colorComponents = buffer[image.index]
 			break
-		} else {
-			image.skipVariable(buffer)
+		} else if err := image.skipVariable(buffer); err != nil {
+			return err
 		}
 	}
 
-	return image
+	return nil
 }
 
 // Find the next JPEG marker and return its marker code.
@@ -153,12 +175,12 @@ func (image *JPGImage) readJPGImage(buffer []byte) *JPGImage {
 // NB: this routine must not be used after seeing SOS marker, since
 // it will not deal correctly with FF/00 sequences in the compressed
 // image data...
-func (image *JPGImage) nextMarker(buffer []byte) uint8 {
+func (image *JPGImage) nextMarker(buffer []byte) (uint8, error) {
 	// Find 0xFF byte; count and skip any non-FFs.
 	ch := buffer[image.index]
 	image.index++
 	if ch != 0xFF {
-		log.Fatal("0xFF byte expected.")
+		return 0, malformed("JPEG image", "0xFF byte expected")
 	}
 
 	// Get marker code byte, swallowing any duplicate FF bytes.
@@ -170,7 +192,7 @@ func (image *JPGImage) nextMarker(buffer []byte) uint8 {
 			break
 		}
 	}
-	return ch
+	return ch, nil
 }
 
 // Most types of marker are followed by a variable-length parameter
@@ -179,13 +201,13 @@ func (image *JPGImage) nextMarker(buffer []byte) uint8 {
 // Note that we MUST skip the parameter segment explicitly in order
 // not to be fooled by 0xFF bytes that might appear within the
 // parameter segment such bytes do NOT introduce new markers.
-func (image *JPGImage) skipVariable(buffer []byte) {
+func (image *JPGImage) skipVariable(buffer []byte) error {
 	// Get the marker parameter length
 	length := image.getUint16(buffer)
 	image.index += 2
 
 	if length < 2 {
-		log.Fatal("Length includes itself, so must be at least 2.")
+		return malformed("JPEG image", "marker length must be at least 2")
 	}
 	length -= 2
 
@@ -194,6 +216,7 @@ func (image *JPGImage) skipVariable(buffer []byte) {
 		image.index++
 		length--
 	}
+	return nil
 }
 
 func (image *JPGImage) getUint16(buffer []byte) uint16 {
@@ -0,0 +1,578 @@
+package pdfjet
+
+/**
+ * linebreak.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The line breaking classes. See Unicode Standard Annex #14, table 1.
+// The classes resolved by rule LB1 - AI, SA, SG, XX and CJ - are not listed.
+const (
+	lbAL  = iota // Alphabetic
+	lbBK         // Mandatory break
+	lbCR         // Carriage return
+	lbLF         // Line feed
+	lbNL         // Next line
+	lbSP         // Space
+	lbZW         // Zero width space
+	lbWJ         // Word joiner
+	lbGL         // Non-breaking glue
+	lbCM         // Combining mark
+	lbZWJ        // Zero width joiner
+	lbBA         // Break after
+	lbBB         // Break before
+	lbHY         // Hyphen
+	lbB2         // Break on either side
+	lbCB         // Contingent break
+	lbCL         // Close punctuation
+	lbCP         // Close parenthesis
+	lbEX         // Exclamation and interrogation
+	lbIN         // Inseparable
+	lbNS         // Nonstarter
+	lbOP         // Open punctuation
+	lbQU         // Quotation
+	lbIS         // Infix separator
+	lbNU         // Numeric
+	lbPO         // Postfix numeric
+	lbPR         // Prefix numeric
+	lbSY         // Symbols allowing break after
+	lbHL         // Hebrew letter
+	lbID         // Ideographic
+	lbEB         // Emoji base
+	lbEM         // Emoji modifier
+	lbH2         // Hangul LV syllable
+	lbH3         // Hangul LVT syllable
+	lbJL         // Hangul L jamo
+	lbJV         // Hangul V jamo
+	lbJT         // Hangul T jamo
+	lbRI         // Regional indicator
+)
+
+// The break actions between two characters.
+const (
+	breakProhibited = iota
+	breakAllowed
+	breakMandatory
+)
+
+// The ideographic characters - CJK ideographs, kana, Yi, the fullwidth forms and the emoji.
+var lineBreakIdeographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x231A, 0x231B, 1}, {0x23F0, 0x23F3, 1}, {0x2600, 0x2603, 1}, {0x2614, 0x2615, 1},
+		{0x2618, 0x2639, 33}, {0x263A, 0x263C, 1}, {0x2668, 0x267F, 23}, {0x26BD, 0x26C8, 1},
+		{0x26CD, 0x26CF, 2}, {0x26D0, 0x26D1, 1}, {0x26D3, 0x26D4, 1}, {0x26D8, 0x26D9, 1},
+		{0x26DC, 0x26DF, 3}, {0x26E0, 0x26E1, 1}, {0x26EA, 0x26F1, 7}, {0x26F2, 0x26F5, 1},
+		{0x26F7, 0x26F8, 1}, {0x26FA, 0x26FD, 3}, {0x26FE, 0x2704, 1}, {0x2708, 0x2709, 1},
+		{0x2E80, 0x2FFF, 1}, {0x3003, 0x3004, 1}, {0x3006, 0x3007, 1}, {0x3012, 0x3013, 1},
+		{0x3020, 0x3029, 1}, {0x3030, 0x303A, 1}, {0x303D, 0x303F, 1}, {0x3042, 0x3062, 2},
+		{0x3064, 0x3082, 1}, {0x3084, 0x3088, 2}, {0x3089, 0x308D, 1}, {0x308F, 0x3094, 1},
+		{0x309F, 0x30A2, 3}, {0x30A4, 0x30AA, 2}, {0x30AB, 0x30C2, 1}, {0x30C4, 0x30E2, 1},
+		{0x30E4, 0x30E8, 2}, {0x30E9, 0x30ED, 1}, {0x30EF, 0x30F4, 1}, {0x30F7, 0x30FA, 1},
+		{0x30FF, 0x3100, 1}, {0x3105, 0x31EF, 1}, {0x3200, 0x4DBF, 1}, {0x4E00, 0xA014, 1},
+		{0xA016, 0xA4CF, 1}, {0xF900, 0xFAFF, 1}, {0xFE30, 0xFE34, 1}, {0xFE45, 0xFE46, 1},
+		{0xFE49, 0xFE4F, 1}, {0xFE51, 0xFE58, 7}, {0xFE5F, 0xFE66, 1}, {0xFE68, 0xFE6B, 3},
+		{0xFF02, 0xFF03, 1}, {0xFF06, 0xFF07, 1}, {0xFF0A, 0xFF0B, 1}, {0xFF0D, 0xFF0F, 2},
+		{0xFF10, 0xFF19, 1}, {0xFF1C, 0xFF1E, 1}, {0xFF20, 0xFF3A, 1}, {0xFF3C, 0xFF3E, 2},
+		{0xFF3F, 0xFF5A, 1}, {0xFF5C, 0xFF5E, 2}, {0xFFE2, 0xFFE4, 1},
+	},
+	R32: []unicode.Range32{
+		{0x17000, 0x18AFF, 1}, {0x1B000, 0x1B2FF, 1}, {0x1F000, 0x1F0FF, 1}, {0x1F200, 0x1F2FF, 1},
+		{0x1F300, 0x1F3FA, 1}, {0x1F400, 0x1F64F, 1}, {0x1F680, 0x1F6FF, 1}, {0x1F900, 0x1FAFF, 1},
+		{0x20000, 0x3FFFD, 1},
+	},
+}
+
+// The scripts written without spaces between the words. Breaking them needs a dictionary,
+// their words are kept together and broken only when they do not fit on the line.
+var lineBreakComplexContext = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0E01, 0x0E3A, 1}, {0x0E40, 0x0E4E, 1}, {0x0E81, 0x0EDF, 1}, {0x1000, 0x103F, 1},
+		{0x1050, 0x108F, 1}, {0x109A, 0x109F, 1}, {0x1780, 0x17D3, 1}, {0x17D7, 0x17DC, 5},
+		{0x17DD, 0x1950, 371}, {0x1951, 0x19DA, 1}, {0x1A20, 0x1AAD, 1}, {0xA9E0, 0xA9EF, 1},
+		{0xA9FA, 0xA9FE, 1}, {0xAA60, 0xAADF, 1},
+	},
+}
+
+// getLineBreakClass returns the line breaking class of the character
+// with the classes resolved by rule LB1.
+func getLineBreakClass(ch rune) int {
+	switch ch {
+	case 0x000B, 0x000C, 0x2028, 0x2029:
+		return lbBK
+	case '\r':
+		return lbCR
+	case '\n':
+		return lbLF
+	case 0x0085:
+		return lbNL
+	case ' ':
+		return lbSP
+	case 0x200B:
+		return lbZW
+	case 0x200D:
+		return lbZWJ
+	case 0x2060, 0xFEFF:
+		return lbWJ
+	case 0x00A0, 0x034F, 0x0F08, 0x0F0C, 0x0F12, 0x180E, 0x2007, 0x2011, 0x202F:
+		return lbGL
+	case '\t', 0x00AD, 0x058A, 0x05BE, 0x0964, 0x0965, 0x0E5A, 0x0E5B, 0x1680, 0x2010,
+		0x2012, 0x2013, 0x2027, 0x205F, 0x3000, '|':
+		return lbBA
+	case 0x00B4, 0x02C8, 0x02CC, 0x02DF, 0x0F01, 0x0F02, 0x0F03, 0x0F04, 0x1806, 0xA874:
+		return lbBB
+	case '-':
+		return lbHY
+	case 0x2014, 0x2E3A, 0x2E3B:
+		return lbB2
+	case 0xFFFC:
+		return lbCB
+	case ')', ']':
+		return lbCP
+	case 0x3001, 0x3002, 0xFE11, 0xFE12, 0xFE50, 0xFE52, 0xFF0C, 0xFF0E, 0xFF61, 0xFF64:
+		return lbCL
+	case '!', '?', 0x05C6, 0x061B, 0x061E, 0x061F, 0x06D4, 0x07F9, 0x0F0D, 0x0F0E, 0x0F0F,
+		0x0F10, 0x0F11, 0x1802, 0x1803, 0x1808, 0x1809, 0x1944, 0x1945, 0x2762, 0x2763,
+		0x2CF9, 0x2CFE, 0x2E2E, 0xA60E, 0xA876, 0xA877, 0xFE15, 0xFE16, 0xFE56, 0xFE57,
+		0xFF01, 0xFF1F:
+		return lbEX
+	case 0x2024, 0x2025, 0x2026, 0x22EF, 0xFE19:
+		return lbIN
+	case 0x17D6, 0x203C, 0x203D, 0x2047, 0x2048, 0x2049, 0x3005, 0x301C, 0x303B, 0x303C,
+		0x309B, 0x309C, 0x309D, 0x309E, 0x30A0, 0x30FB, 0x30FC, 0x30FD, 0x30FE, 0xA015,
+		0xFE54, 0xFE55, 0xFF1A, 0xFF1B, 0xFF65, 0xFF70, 0xFF9E, 0xFF9F:
+		return lbNS
+	case 0x00A1, 0x00BF, 0x2E18, 0x201A, 0x201E:
+		return lbOP
+	case '"', '\'', 0x275B, 0x275C, 0x275D, 0x275E, 0x275F, 0x2760, 0x2E00, 0x2E01, 0x2E06,
+		0x2E07, 0x2E08, 0x2E0B:
+		return lbQU
+	case ',', '.', ':', ';', 0x037E, 0x0589, 0x060C, 0x060D, 0x07F8, 0x2044, 0xFE10, 0xFE13, 0xFE14:
+		return lbIS
+	case '%', 0x00A2, 0x00B0, 0x060B, 0x066A, 0x09F2, 0x09F3, 0x0D79, 0x2030, 0x2031, 0x2032,
+		0x2033, 0x2034, 0x2035, 0x2036, 0x2037, 0x20A7, 0x20B6, 0x20BB, 0x20BE, 0x20C0,
+		0x2103, 0x2109, 0xFE6A, 0xFF05, 0xFFE0:
+		return lbPO
+	case '$', '+', '\\', 0x00A3, 0x00A4, 0x00A5, 0x00B1, 0x0E3F, 0x2116, 0x2212, 0x2213,
+		0xFE69, 0xFF04, 0xFFE1, 0xFFE5, 0xFFE6:
+		return lbPR
+	case '/':
+		return lbSY
+	}
+	switch {
+	case ch >= 0x3041 && ch <= 0x30FA && isSmallKana(ch), ch >= 0x31F0 && ch <= 0x31FF,
+		ch >= 0xFF67 && ch <= 0xFF6F:
+		return lbNS // The small kana are resolved from CJ to NS
+	case ch >= 0x20A0 && ch <= 0x20CF:
+		return lbPR
+	case ch >= 0x1F1E6 && ch <= 0x1F1FF:
+		return lbRI
+	case ch >= 0x1F3FB && ch <= 0x1F3FF:
+		return lbEM
+	case ch >= 0xAC00 && ch <= 0xD7A3:
+		if (ch-0xAC00)%28 == 0 {
+			return lbH2
+		}
+		return lbH3
+	case (ch >= 0x1100 && ch <= 0x115F) || (ch >= 0xA960 && ch <= 0xA97C):
+		return lbJL
+	case (ch >= 0x1160 && ch <= 0x11A7) || (ch >= 0xD7B0 && ch <= 0xD7C6):
+		return lbJV
+	case (ch >= 0x11A8 && ch <= 0x11FF) || (ch >= 0xD7CB && ch <= 0xD7FB):
+		return lbJT
+	case unicode.In(ch, unicode.Mn, unicode.Mc, unicode.Me):
+		return lbCM
+	case unicode.Is(lineBreakComplexContext, ch):
+		return lbAL // The SA class is resolved to AL
+	case unicode.Is(unicode.Nd, ch):
+		return lbNU
+	case unicode.Is(unicode.Hebrew, ch) && unicode.IsLetter(ch):
+		return lbHL
+	case unicode.Is(lineBreakIdeographic, ch):
+		return lbID
+	case unicode.Is(unicode.Ps, ch):
+		return lbOP
+	case unicode.Is(unicode.Pe, ch):
+		return lbCL
+	case unicode.In(ch, unicode.Pi, unicode.Pf):
+		return lbQU
+	case unicode.In(ch, unicode.Cc, unicode.Cf):
+		return lbCM
+	case ch >= 0x2000 && ch <= 0x200A && ch != 0x2007:
+		return lbBA
+	}
+	return lbAL
+}
+
+// isSmallKana returns true if the character is small hiragana or katakana.
+func isSmallKana(ch rune) bool {
+	switch ch {
+	case 0x3041, 0x3043, 0x3045, 0x3047, 0x3049, 0x3063, 0x3083, 0x3085, 0x3087, 0x308E,
+		0x3095, 0x3096, 0x30A1, 0x30A3, 0x30A5, 0x30A7, 0x30A9, 0x30C3, 0x30E3, 0x30E5,
+		0x30E7, 0x30EE, 0x30F5, 0x30F6:
+		return true
+	}
+	return false
+}
+
+// getLineBreaks returns the break action before each character and at the end
+// of the text. The action at position i is between runes[i-1] and runes[i].
+// See Unicode Standard Annex #14 "Unicode Line Breaking Algorithm".
+func getLineBreaks(runes []rune) []int {
+	n := len(runes)
+	breaks := make([]int, n+1)
+	if n == 0 {
+		return breaks
+	}
+	classes := make([]int, n)
+	for i, ch := range runes {
+		classes[i] = getLineBreakClass(ch)
+	}
+	if classes[0] == lbCM || classes[0] == lbZWJ {
+		classes[0] = lbAL // LB10
+	}
+	regionalIndicators := 0 // The regional indicators before the position
+	if classes[0] == lbRI {
+		regionalIndicators = 1
+	}
+	for i := 1; i < n; i++ {
+		if classes[i] == lbCM || classes[i] == lbZWJ {
+			switch classes[i-1] {
+			case lbBK, lbCR, lbLF, lbNL, lbSP, lbZW:
+				classes[i] = lbAL // LB10
+			default:
+				// LB9 - the combining marks take the class of the character before them
+				classes[i] = classes[i-1]
+				breaks[i] = breakProhibited
+				continue
+			}
+		}
+		if runes[i-1] == 0x200D {
+			breaks[i] = breakProhibited // LB8a
+		} else {
+			breaks[i] = getLineBreak(classes, i, regionalIndicators)
+		}
+		if classes[i] == lbRI {
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+	}
+	breaks[n] = breakMandatory // LB3
+	return breaks
+}
+
+// getLineBreak returns the break action between the characters at positions i-1 and i.
+// The rules LB4 to LB31 are applied in order.
+func getLineBreak(classes []int, i, regionalIndicators int) int {
+	before, after := classes[i-1], classes[i]
+	switch {
+	case before == lbBK: // LB4
+		return breakMandatory
+	case before == lbCR && after == lbLF: // LB5
+		return breakProhibited
+	case before == lbCR || before == lbLF || before == lbNL:
+		return breakMandatory
+	case after == lbBK || after == lbCR || after == lbLF || after == lbNL: // LB6
+		return breakProhibited
+	case after == lbSP || after == lbZW: // LB7
+		return breakProhibited
+	}
+	// The class before the spaces for the rules LB8 and LB14 to LB17
+	j := i - 1
+	for j > 0 && classes[j] == lbSP {
+		j--
+	}
+	beforeSpaces := classes[j]
+	switch {
+	case beforeSpaces == lbZW: // LB8
+		return breakAllowed
+	case before == lbWJ || after == lbWJ: // LB11
+		return breakProhibited
+	case before == lbGL: // LB12
+		return breakProhibited
+	case after == lbGL && before != lbSP && before != lbBA && before != lbHY: // LB12a
+		return breakProhibited
+	case after == lbCL || after == lbCP || after == lbEX || after == lbIS || after == lbSY: // LB13
+		return breakProhibited
+	case beforeSpaces == lbOP: // LB14
+		return breakProhibited
+	case beforeSpaces == lbQU && after == lbOP: // LB15
+		return breakProhibited
+	case (beforeSpaces == lbCL || beforeSpaces == lbCP) && after == lbNS: // LB16
+		return breakProhibited
+	case beforeSpaces == lbB2 && after == lbB2: // LB17
+		return breakProhibited
+	case before == lbSP: // LB18
+		return breakAllowed
+	case before == lbQU || after == lbQU: // LB19
+		return breakProhibited
+	case before == lbCB || after == lbCB: // LB20
+		return breakAllowed
+	case after == lbBA || after == lbHY || after == lbNS || before == lbBB: // LB21
+		return breakProhibited
+	case (before == lbHY || before == lbBA) && i > 1 && classes[i-2] == lbHL: // LB21a
+		return breakProhibited
+	case before == lbSY && after == lbHL: // LB21b
+		return breakProhibited
+	case after == lbIN: // LB22
+		return breakProhibited
+	case (before == lbAL || before == lbHL) && after == lbNU, // LB23
+		before == lbNU && (after == lbAL || after == lbHL):
+		return breakProhibited
+	case before == lbPR && (after == lbID || after == lbEB || after == lbEM), // LB23a
+		(before == lbID || before == lbEB || before == lbEM) && after == lbPO:
+		return breakProhibited
+	case (before == lbPR || before == lbPO) && (after == lbAL || after == lbHL), // LB24
+		(before == lbAL || before == lbHL) && (after == lbPR || after == lbPO):
+		return breakProhibited
+	case isNumericPair(before, after): // LB25
+		return breakProhibited
+	case before == lbJL && (after == lbJL || after == lbJV || after == lbH2 || after == lbH3), // LB26
+		(before == lbJV || before == lbH2) && (after == lbJV || after == lbJT),
+		(before == lbJT || before == lbH3) && after == lbJT:
+		return breakProhibited
+	case isKorean(before) && after == lbPO, before == lbPR && isKorean(after): // LB27
+		return breakProhibited
+	case (before == lbAL || before == lbHL) && (after == lbAL || after == lbHL): // LB28
+		return breakProhibited
+	case before == lbIS && (after == lbAL || after == lbHL): // LB29
+		return breakProhibited
+	case (before == lbAL || before == lbHL || before == lbNU) && after == lbOP, // LB30
+		before == lbCP && (after == lbAL || after == lbHL || after == lbNU):
+		return breakProhibited
+	case before == lbRI && after == lbRI && regionalIndicators%2 == 1: // LB30a
+		return breakProhibited
+	case before == lbEB && after == lbEM: // LB30b
+		return breakProhibited
+	}
+	return breakAllowed // LB31
+}
+
+// isNumericPair returns true if the pair of classes is inside a number. (LB25)
+func isNumericPair(before, after int) bool {
+	switch after {
+	case lbNU:
+		return before == lbPO || before == lbPR || before == lbHY || before == lbIS ||
+			before == lbNU || before == lbSY
+	case lbPO, lbPR:
+		return before == lbCL || before == lbCP || before == lbNU
+	case lbOP:
+		return before == lbPO || before == lbPR
+	}
+	return false
+}
+
+// isKorean returns true if the class is one of the Hangul classes.
+func isKorean(class int) bool {
+	return class == lbJL || class == lbJV || class == lbJT || class == lbH2 || class == lbH3
+}
+
+// wrappedLine is a line of the text broken by breakLines.
+type wrappedLine struct {
+	text  string
+	level int  // The embedding level of the paragraph the line is from
+	last  bool // The line ends the paragraph or is followed by a mandatory break
+}
+
+// breakLines breaks the text into lines not wider than the width. The lines are broken
+// at the break opportunities found by the Unicode line breaking algorithm. The words
+// wider than the line are hyphenated when hyphenator is not nil and broken between
+// the characters when they still do not fit.
+func breakLines(fonts []*Font, text string, width float32, hyphenator *Hyphenator) []wrappedLine {
+	lines := make([]wrappedLine, 0)
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		runes := []rune(paragraph)
+		level := getParagraphLevel(runes)
+		if !strings.ContainsAny(paragraph, "\u00AD\u000B\u000C\u0085\u2028\u2029\r") &&
+			getTextWidth(fonts, paragraph) <= width {
+			lines = append(lines, wrappedLine{paragraph, level, true})
+			continue
+		}
+		b := &lineBreaker{fonts: fonts, runes: runes, width: width, hyphenator: hyphenator}
+		for _, line := range b.breakParagraph() {
+			line.level = level
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// lineBreaker breaks one paragraph into lines.
+type lineBreaker struct {
+	fonts      []*Font
+	runes      []rune
+	width      float32
+	hyphenator *Hyphenator
+	lines      []wrappedLine
+}
+
+// breakParagraph fills each line with the text up to the last break opportunity that fits.
+func (b *lineBreaker) breakParagraph() []wrappedLine {
+	breaks := getLineBreaks(b.runes)
+	start := 0     // The start of the current line
+	lastBreak := 0 // The last break opportunity on the current line
+	for i := 1; i <= len(b.runes); i++ {
+		if breaks[i] == breakProhibited {
+			continue
+		}
+		if !b.fits(start, i, false) {
+			if lastBreak > start {
+				// The word after the last break opportunity may be hyphenated to fill the line.
+				if k := b.hyphenate(start, lastBreak, i); k > 0 {
+					b.addLine(start, k, true, false)
+					start = k
+				} else {
+					b.addLine(start, lastBreak, false, false)
+					start = lastBreak
+				}
+			}
+			for !b.fits(start, i, false) {
+				// The text from the start of the line is too wide for one line.
+				if k := b.hyphenate(start, start, i); k > 0 {
+					b.addLine(start, k, true, false)
+					start = k
+				} else {
+					k := b.getFitChars(start, i)
+					b.addLine(start, k, false, false)
+					start = k
+				}
+			}
+		}
+		lastBreak = i
+		if breaks[i] == breakMandatory && i < len(b.runes) {
+			b.addLine(start, i, false, true)
+			start = i
+		}
+	}
+	if start < len(b.runes) || len(b.lines) == 0 {
+		b.addLine(start, len(b.runes), false, true)
+	}
+	return b.lines
+}
+
+// getLineText returns the text from start to end without the spaces at the end.
+// The soft hyphens are removed except at the end of the line where they are drawn
+// as hyphen. The hyphen is added when the word is hyphenated.
+func (b *lineBreaker) getLineText(start, end int, hyphenated bool) string {
+	var buf strings.Builder
+	for i, ch := range b.runes[start:end] {
+		if ch == 0x00AD && start+i < end-1 {
+			continue
+		}
+		if ch == 0x00AD {
+			ch = '-'
+		}
+		buf.WriteRune(ch)
+	}
+	text := strings.TrimRightFunc(buf.String(), isTrailingSpace)
+	if hyphenated {
+		text += "-"
+	}
+	return text
+}
+
+// isTrailingSpace returns true for the characters that are removed from the end of the line.
+func isTrailingSpace(ch rune) bool {
+	switch getLineBreakClass(ch) {
+	case lbSP, lbBK, lbCR, lbLF, lbNL, lbZW:
+		return true
+	}
+	return ch == '\t'
+}
+
+// fits returns true if the text from start to end fits on the line.
+func (b *lineBreaker) fits(start, end int, hyphenated bool) bool {
+	return getTextWidth(b.fonts, b.getLineText(start, end, hyphenated)) <= b.width
+}
+
+// addLine adds the text from start to end as a line. The last flag is set
+// when the line ends the paragraph or with a mandatory break.
+// The spaces that would be the only text of the line are skipped.
+func (b *lineBreaker) addLine(start, end int, hyphenated, last bool) {
+	text := b.getLineText(start, end, hyphenated)
+	if text == "" && !last {
+		return
+	}
+	b.lines = append(b.lines, wrappedLine{text: text, last: last})
+}
+
+// hyphenate returns the last hyphenation point of the words between from and to
+// that fits on the line starting at start with the hyphen added or 0 if there is none.
+func (b *lineBreaker) hyphenate(start, from, to int) int {
+	if b.hyphenator == nil {
+		return 0
+	}
+	best := 0
+	for i := from; i < to; {
+		if !unicode.IsLetter(b.runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < to && (unicode.IsLetter(b.runes[j]) || unicode.IsMark(b.runes[j])) {
+			j++
+		}
+		for _, k := range b.hyphenator.Hyphenate(string(b.runes[i:j])) {
+			if i+k > start && b.fits(start, i+k, true) {
+				best = i + k
+			}
+		}
+		i = j
+	}
+	return best
+}
+
+// getFitChars returns the end of the longest text from start to end that fits on the line.
+// At least one character with its combining marks is placed on each line.
+func (b *lineBreaker) getFitChars(start, end int) int {
+	k := start + 1
+	for k < end && isClusterContinuation(b.runes, k) {
+		k++
+	}
+	for i := k + 1; i < end; i++ {
+		if isClusterContinuation(b.runes, i) {
+			continue
+		}
+		if !b.fits(start, i, false) {
+			break
+		}
+		k = i
+	}
+	// The spaces after the last cluster stay on the line.
+	for k < end && isTrailingSpace(b.runes[k]) {
+		k++
+	}
+	return k
+}
+
+// isClusterContinuation returns true if the character at position i
+// cannot be separated from the character before it.
+func isClusterContinuation(runes []rune, i int) bool {
+	ch := runes[i]
+	return unicode.IsMark(ch) || ch == 0x200D || runes[i-1] == 0x200D ||
+		(ch >= 0xFE00 && ch <= 0xFE0F)
+}
@@ -0,0 +1,851 @@
+package pdfjet
+
+/**
+ * merge.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The page attributes that can be inherited from the parent page tree nodes.
+var inheritedPageAttributes = []string{"/Resources", "/MediaBox", "/CropBox", "/Rotate"}
+
+// mergeKey identifies object in one of the merged documents.
+type mergeKey struct {
+	doc    int
+	number int
+}
+
+// mergeName identifies named destination in one of the merged documents.
+type mergeName struct {
+	doc  int
+	name string
+}
+
+// mergeEntry is named destination read from one of the merged documents.
+type mergeEntry struct {
+	doc   int
+	name  string   // The decoded name
+	value []string // The destination tokens
+}
+
+// override replaces the value of the key with reference to new object.
+// The key is removed when the number is 0.
+type override struct {
+	key    string
+	number int
+}
+
+// merger combines the objects of several documents into one object graph.
+type merger struct {
+	docs         [][]*PDFobj
+	pages        [][]*PDFobj // The page objects of each document
+	selected     map[mergeKey]bool
+	outlines     [][]*PDFobj // The top level outline items of each document
+	counts       []int       // The number of visible outline items of each document
+	names        []mergeEntry
+	dests        []mergeEntry
+	renamedNames map[mergeName]string   // The new names of the destinations in the name trees
+	renamedDests map[mergeName]string   // The new names of the destinations in the /Dests dictionaries
+	skip         map[*PDFobj][]string   // The keys replaced by the merger
+	overrides    map[*PDFobj][]override // The new values of the replaced keys
+	alias        map[mergeKey]mergeKey  // The duplicate fonts and images
+	seen         map[string]mergeKey    // The fonts and images by content hash
+	hashes       map[mergeKey]string
+	kept         map[mergeKey]bool
+	numbers      map[mergeKey]int
+}
+
+// Merge merges the PDF documents read from the readers into this PDF.
+// The program exits if any of the documents cannot be read. Use MergeErr to handle the error.
+func (pdf *PDF) Merge(readers ...io.Reader) {
+	if err := pdf.MergeErr(readers...); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// MergeErr merges the PDF documents read from the readers into this PDF.
+// The objects are renumbered, identical fonts and images are written only once.
+// The pages, the bookmarks and the named destinations of all documents are kept.
+// When two documents have destination with the same name, the destination of the later
+// document gets the document number as prefix - for example "doc2-intro" - and the links
+// and the bookmarks of that document are changed to use the new name.
+// Must be called once before Complete and the PDF must not have any other pages.
+// Returns MalformedInputError if any of the documents cannot be parsed.
+func (pdf *PDF) MergeErr(readers ...io.Reader) error {
+	if pdf.incremental || pdf.streaming != nil || pdf.pagesObjNumber != 0 || len(pdf.pages) > 0 {
+		return errors.New("pdfjet: Merge must be called once on PDF without pages")
+	}
+	m := newMerger()
+	for _, reader := range readers {
+		buf, err := io.ReadAll(reader)
+		if err != nil {
+			return &IOError{Op: "read", Err: err}
+		}
+		objects, err := pdf.ReadErr(buf)
+		if err != nil {
+			return err
+		}
+		if err := m.addDocument(pdf, objects, nil); err != nil {
+			return err
+		}
+	}
+	m.renameDestinations()
+	m.findKeptObjects()
+	pdf.writeMergedObjects(m)
+	return nil
+}
+
+// ExtractPages adds the specified pages of PDF read using Read to this PDF.
+// The program exits if the page numbers are out of range. Use ExtractPagesErr to handle the error.
+func (pdf *PDF) ExtractPages(objects []*PDFobj, pageNumbers ...int) {
+	if err := pdf.ExtractPagesErr(objects, pageNumbers...); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// ExtractPagesErr adds the specified pages of PDF read using Read to this PDF.
+// The page numbers start from 1. Only the objects reachable from the pages - contents,
+// fonts, images and annotations - are written. The links to other pages are removed.
+// The objects are not changed, so the same objects can be used to split one PDF
+// into several files - for example using one PDF for each page range.
+// Must be called once before Complete and the PDF must not have any other pages.
+func (pdf *PDF) ExtractPagesErr(objects []*PDFobj, pageNumbers ...int) error {
+	if pdf.incremental || pdf.streaming != nil || pdf.pagesObjNumber != 0 || len(pdf.pages) > 0 {
+		return errors.New("pdfjet: ExtractPages must be called once on PDF without pages")
+	}
+	if len(pageNumbers) == 0 {
+		return errors.New("pdfjet: no pages to extract")
+	}
+	copies := make([]*PDFobj, len(objects))
+	for i, obj := range objects {
+		obj2 := *obj
+		obj2.dict = append([]string{}, obj.dict...)
+		copies[i] = &obj2
+	}
+	m := newMerger()
+	if err := m.addDocument(pdf, copies, pageNumbers); err != nil {
+		return err
+	}
+	m.findKeptObjects()
+	pdf.writeMergedObjects(m)
+	return nil
+}
+
+func newMerger() *merger {
+	m := new(merger)
+	m.selected = make(map[mergeKey]bool)
+	m.skip = make(map[*PDFobj][]string)
+	m.overrides = make(map[*PDFobj][]override)
+	m.alias = make(map[mergeKey]mergeKey)
+	m.seen = make(map[string]mergeKey)
+	m.hashes = make(map[mergeKey]string)
+	m.kept = make(map[mergeKey]bool)
+	m.numbers = make(map[mergeKey]int)
+	m.renamedNames = make(map[mergeName]string)
+	m.renamedDests = make(map[mergeName]string)
+	return m
+}
+
+// addDocument adds the objects of one document. When the page numbers are nil
+// all pages are added together with the outlines and the named destinations.
+func (m *merger) addDocument(pdf *PDF, objects []*PDFobj, pageNumbers []int) error {
+	for _, obj := range objects {
+		obj.dict = joinStrings(obj.dict)
+	}
+	doc := len(m.docs)
+	m.docs = append(m.docs, objects)
+	if err := m.addPages(pdf, doc, pageNumbers); err != nil {
+		return err
+	}
+	if pageNumbers == nil {
+		m.addOutlines(doc)
+		m.addNamedDestinations(doc)
+	}
+	return nil
+}
+
+// addPages adds the page objects of the document.
+// The inherited attributes are copied to the pages, because the page tree is flattened.
+func (m *merger) addPages(pdf *PDF, doc int, pageNumbers []int) error {
+	if pdf.getPagesObject(m.docs[doc]) == nil {
+		return malformed("PDF", "no root /Pages object")
+	}
+	pages, err := pdf.GetPageObjectsErr(m.docs[doc])
+	if err != nil {
+		return err
+	}
+	if pageNumbers != nil {
+		selected := make([]*PDFobj, 0, len(pageNumbers))
+		for _, number := range pageNumbers {
+			if number < 1 || number > len(pages) {
+				return fmt.Errorf("pdfjet: page number %d is out of range 1 to %d", number, len(pages))
+			}
+			selected = append(selected, pages[number-1])
+		}
+		pages = selected
+	}
+	for _, page := range pages {
+		m.selected[mergeKey{doc, page.number}] = true
+		for _, key := range inheritedPageAttributes {
+			if findKey(page.dict, key) != -1 {
+				continue
+			}
+			parent := m.getReference(doc, page, "/Parent")
+			for depth := 0; parent != nil && depth < 32; depth++ {
+				if value := getValueTokens(parent.dict, key); value != nil {
+					page.dict = setValueTokens(page.dict, key, value)
+					break
+				}
+				parent = m.getReference(doc, parent, "/Parent")
+			}
+		}
+		m.skip[page] = []string{"/Parent"}
+	}
+	m.pages = append(m.pages, pages)
+	return nil
+}
+
+// addOutlines adds the top level outline items of the document.
+func (m *merger) addOutlines(doc int) {
+	items := make([]*PDFobj, 0)
+	count := 0
+	if root := m.getReference(doc, m.getCatalog(doc), "/Outlines"); root != nil {
+		item := m.getReference(doc, root, "/First")
+		visited := make(map[*PDFobj]bool)
+		for item != nil && !visited[item] {
+			visited[item] = true
+			items = append(items, item)
+			m.skip[item] = []string{"/Parent", "/Prev", "/Next"}
+			item = m.getReference(doc, item, "/Next")
+		}
+		count, _ = strconv.Atoi(getSingleValue(root.dict, "/Count"))
+		if count <= 0 {
+			count = len(items)
+		}
+	}
+	m.outlines = append(m.outlines, items)
+	m.counts = append(m.counts, count)
+}
+
+// addNamedDestinations adds the named destinations from the name tree
+// and from the /Dests dictionary of the document catalog.
+func (m *merger) addNamedDestinations(doc int) {
+	catalog := m.getCatalog(doc)
+	if catalog == nil {
+		return
+	}
+	names := m.getDictionary(doc, catalog.dict, "/Names")
+	if names != nil {
+		tree := m.getDictionary(doc, names, "/Dests")
+		if tree != nil {
+			m.addNameTree(doc, tree, 0)
+		}
+	}
+	dests := m.getDictionary(doc, catalog.dict, "/Dests")
+	if dests != nil {
+		i := indexOf(dests, "<<") + 1
+		for i > 0 && i < len(dests) && dests[i] != ">>" {
+			end := valueEnd(dests, i+1)
+			m.dests = append(m.dests, mergeEntry{
+				doc: doc, name: dests[i], value: dests[i+1 : end]})
+			i = end
+		}
+	}
+}
+
+// addNameTree adds the entries of the name tree node and its kids.
+func (m *merger) addNameTree(doc int, node []string, depth int) {
+	if depth > 32 {
+		return
+	}
+	if array := getValueTokens(node, "/Names"); len(array) > 2 {
+		for i := 1; i < len(array)-1; {
+			end := valueEnd(array, i)
+			valueStart := end
+			end = valueEnd(array, valueStart)
+			if end > len(array)-1 {
+				break
+			}
+			m.names = append(m.names, mergeEntry{
+				doc: doc, name: string(decodeString(array[i])), value: array[valueStart:end]})
+			i = end
+		}
+	}
+	if kids := getValueTokens(node, "/Kids"); len(kids) > 2 {
+		for i := 1; i+2 < len(kids); i += 3 {
+			if number, ok := getReferenceNumber(kids, i); ok {
+				if kid := m.getObject(doc, number); kid != nil {
+					m.addNameTree(doc, kid.dict, depth+1)
+				}
+			}
+		}
+	}
+}
+
+// renameDestinations renames the destinations that have the same name
+// as destination of earlier document.
+func (m *merger) renameDestinations() {
+	renameEntries(m.names, m.renamedNames, "")
+	renameEntries(m.dests, m.renamedDests, "/")
+}
+
+// renameEntries adds the document prefix to the names used by earlier documents.
+// The names of the /Dests dictionary entries start with the slash.
+func renameEntries(entries []mergeEntry, renamed map[mergeName]string, slash string) {
+	owners := make(map[string]int)
+	for _, entry := range entries {
+		if _, ok := owners[entry.name]; !ok {
+			owners[entry.name] = entry.doc
+		}
+	}
+	for i := range entries {
+		entry := &entries[i]
+		key := mergeName{entry.doc, entry.name}
+		if name, ok := renamed[key]; ok {
+			entry.name = name
+			continue
+		}
+		if owners[entry.name] == entry.doc {
+			continue
+		}
+		prefix := "doc" + strconv.Itoa(entry.doc+1) + "-"
+		name := entry.name
+		for {
+			name = slash + prefix + strings.TrimPrefix(name, slash)
+			if _, ok := owners[name]; !ok {
+				break
+			}
+		}
+		owners[name] = entry.doc
+		renamed[key] = name
+		entry.name = name
+	}
+}
+
+// findKeptObjects finds the objects reachable from the pages, the outlines and the
+// named destinations. The fonts and images that are identical to already kept ones are aliased.
+func (m *merger) findKeptObjects() {
+	queue := make([]mergeKey, 0)
+	for doc, pages := range m.pages {
+		for _, page := range pages {
+			queue = append(queue, mergeKey{doc, page.number})
+		}
+	}
+	for doc, items := range m.outlines {
+		for _, item := range items {
+			queue = append(queue, mergeKey{doc, item.number})
+		}
+	}
+	for _, entry := range append(append([]mergeEntry{}, m.names...), m.dests...) {
+		for _, number := range getReferenceNumbers(entry.value) {
+			queue = append(queue, mergeKey{entry.doc, number})
+		}
+	}
+
+	// Breadth first, so the fonts and images of the first document are kept
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if m.kept[key] {
+			continue
+		}
+		if _, ok := m.alias[key]; ok {
+			continue
+		}
+		obj := m.getObject(key.doc, key.number)
+		if obj == nil {
+			continue
+		}
+		if !m.selected[key] && isPageObject(obj) {
+			continue // The links to pages that were not selected are removed
+		}
+		if obj.getValue("/Type") == "/Font" || obj.getValue("/Subtype") == "/Image" {
+			hash := m.hash(key, make(map[mergeKey]bool))
+			if first, ok := m.seen[hash]; ok && first != key {
+				m.alias[key] = first
+				continue
+			}
+			m.seen[hash] = key
+		}
+		m.kept[key] = true
+		for _, number := range getReferenceNumbers(m.tokensToFollow(obj)) {
+			queue = append(queue, mergeKey{key.doc, number})
+		}
+	}
+}
+
+// tokensToFollow returns the object tokens without the values of the keys replaced by the merger.
+func (m *merger) tokensToFollow(obj *PDFobj) []string {
+	skip := m.skip[obj]
+	if len(skip) == 0 {
+		return obj.dict
+	}
+	tokens := obj.dict
+	for _, key := range skip {
+		i := findKey(tokens, key)
+		if i != -1 {
+			end := valueEnd(tokens, i+1)
+			tokens = append(append([]string{}, tokens[:i]...), tokens[end:]...)
+		}
+	}
+	return tokens
+}
+
+// hash returns the hash of the object content including the objects it references.
+func (m *merger) hash(key mergeKey, visiting map[mergeKey]bool) string {
+	if hash, ok := m.hashes[key]; ok {
+		return hash
+	}
+	obj := m.getObject(key.doc, key.number)
+	if obj == nil {
+		return "null"
+	}
+	if visiting[key] {
+		return "cycle " + strconv.Itoa(key.doc) + " " + strconv.Itoa(key.number)
+	}
+	visiting[key] = true
+	h := sha256.New()
+	tokens := obj.dict
+	if len(tokens) > 2 && tokens[2] == "obj" {
+		tokens = tokens[3:]
+	}
+	for i := 0; i < len(tokens); i++ {
+		if number, ok := getReferenceNumber(tokens, i); ok {
+			h.Write([]byte(m.hash(mergeKey{key.doc, number}, visiting)))
+			i += 2
+		} else {
+			h.Write([]byte(tokens[i]))
+		}
+		h.Write([]byte{0})
+	}
+	h.Write(obj.stream)
+	delete(visiting, key)
+	hash := hex.EncodeToString(h.Sum(nil))
+	m.hashes[key] = hash
+	return hash
+}
+
+// resolve returns the new number of the object or 0 if the object was not kept.
+func (m *merger) resolve(key mergeKey) int {
+	if first, ok := m.alias[key]; ok {
+		key = first
+	}
+	return m.numbers[key]
+}
+
+// writeMergedObjects renumbers and writes the kept objects followed by
+// the new page tree, outlines and named destinations.
+func (pdf *PDF) writeMergedObjects(m *merger) {
+	keys := make([]mergeKey, 0, len(m.kept))
+	for key := range m.kept {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].doc != keys[j].doc {
+			return keys[i].doc < keys[j].doc
+		}
+		return keys[i].number < keys[j].number
+	})
+	number := pdf.getObjNumber()
+	for _, key := range keys {
+		number++
+		m.numbers[key] = number
+	}
+
+	objects := make([]*PDFobj, 0, len(keys)+4)
+	newObject := func(tokens ...string) *PDFobj {
+		number++
+		obj := NewPDFobj()
+		obj.number = number
+		obj.dict = tokens
+		objects = append(objects, obj)
+		return obj
+	}
+
+	// The page tree with all pages as kids of the root
+	kids := []string{"["}
+	count := 0
+	for doc, pages := range m.pages {
+		for _, page := range pages {
+			kids = append(kids, strconv.Itoa(m.resolve(mergeKey{doc, page.number})), "0", "R")
+			count++
+		}
+	}
+	kids = append(kids, "]")
+	pagesObj := newObject(append(append([]string{"<<", "/Type", "/Pages", "/Kids"}, kids...),
+		"/Count", strconv.Itoa(count), ">>")...)
+	for _, pages := range m.pages {
+		for _, page := range pages {
+			m.overrides[page] = []override{{"/Parent", pagesObj.number}}
+		}
+	}
+
+	// The outlines with the top level items of all documents
+	items := make([]mergeKey, 0)
+	count = 0
+	for doc, list := range m.outlines {
+		for _, item := range list {
+			items = append(items, mergeKey{doc, item.number})
+		}
+		count += m.counts[doc]
+	}
+	if len(items) > 0 {
+		outlines := newObject("<<", "/Type", "/Outlines",
+			"/First", strconv.Itoa(m.resolve(items[0])), "0", "R",
+			"/Last", strconv.Itoa(m.resolve(items[len(items)-1])), "0", "R",
+			"/Count", strconv.Itoa(count), ">>")
+		for i, key := range items {
+			prev, next := 0, 0
+			if i > 0 {
+				prev = m.resolve(items[i-1])
+			}
+			if i < len(items)-1 {
+				next = m.resolve(items[i+1])
+			}
+			item := m.getObject(key.doc, key.number)
+			m.overrides[item] = []override{
+				{"/Parent", outlines.number}, {"/Prev", prev}, {"/Next", next}}
+		}
+		pdf.outlinesObjNumber = outlines.number
+	}
+
+	// The named destinations - the first destination with given name in the document is used
+	if len(m.names) > 0 {
+		sort.SliceStable(m.names, func(i, j int) bool {
+			return m.names[i].name < m.names[j].name
+		})
+		tokens := []string{"<<", "/Names", "["}
+		for i, entry := range m.names {
+			if i > 0 && entry.name == m.names[i-1].name {
+				continue
+			}
+			tokens = append(tokens, escapeLiteral([]byte(entry.name)))
+			tokens = append(tokens, m.remap(entry.doc, entry.value)...)
+		}
+		tokens = append(tokens, "]", ">>")
+		pdf.namesObjNumber = newObject(tokens...).number
+	}
+	if len(m.dests) > 0 {
+		tokens := []string{"<<"}
+		used := make(map[string]bool)
+		for _, entry := range m.dests {
+			if used[entry.name] {
+				continue
+			}
+			used[entry.name] = true
+			tokens = append(tokens, entry.name)
+			tokens = append(tokens, m.remap(entry.doc, entry.value)...)
+		}
+		tokens = append(tokens, ">>")
+		pdf.destsObjNumber = newObject(tokens...).number
+	}
+
+	kept := make([]*PDFobj, 0, len(keys)+len(objects))
+	for _, key := range keys {
+		obj := m.getObject(key.doc, key.number)
+		if len(obj.dict) > 2 && obj.dict[2] == "obj" {
+			dict := m.remap(key.doc, obj.dict[3:])
+			obj.dict = append([]string{strconv.Itoa(m.numbers[key]), "0", "obj"}, dict...)
+		} else {
+			obj.offset = 0 // Object number without object
+			obj.dict = []string{"null"}
+		}
+		for _, o := range m.overrides[obj] {
+			if o.number == 0 {
+				obj.dict = removeKey(obj.dict, o.key)
+			} else {
+				obj.dict = setValueTokens(obj.dict, o.key, []string{strconv.Itoa(o.number), "0", "R"})
+			}
+		}
+		obj.number = m.numbers[key]
+		kept = append(kept, obj)
+	}
+	kept = append(kept, objects...)
+	pdf.addObjectsToPDF(&kept)
+	pdf.pagesObjNumber = pagesObj.number
+}
+
+// remap returns copy of the tokens with the references renumbered.
+// The references to objects that were not kept are replaced with null
+// and the renamed destinations of the links and the bookmarks get their new names.
+func (m *merger) remap(doc int, tokens []string) []string {
+	list := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if number, ok := getReferenceNumber(tokens, i); ok {
+			if n := m.resolve(mergeKey{doc, number}); n != 0 {
+				list = append(list, strconv.Itoa(n), "0", "R")
+			} else {
+				list = append(list, "null")
+			}
+			i += 2
+		} else if name, ok := m.getNewName(doc, tokens, i); ok {
+			list = append(list, tokens[i], name)
+			i++
+		} else {
+			list = append(list, tokens[i])
+		}
+	}
+	return list
+}
+
+// getNewName returns the new name of the renamed destination if the tokens at the index
+// are /Dest or /D key followed by the old name.
+func (m *merger) getNewName(doc int, tokens []string, i int) (string, bool) {
+	if (tokens[i] != "/Dest" && tokens[i] != "/D") || i+1 >= len(tokens) {
+		return "", false
+	}
+	token := tokens[i+1]
+	if strings.HasPrefix(token, "/") {
+		name, ok := m.renamedDests[mergeName{doc, token}]
+		return name, ok
+	}
+	if strings.HasPrefix(token, "(") || (strings.HasPrefix(token, "<") && !strings.HasPrefix(token, "<<")) {
+		if name, ok := m.renamedNames[mergeName{doc, string(decodeString(token))}]; ok {
+			return escapeLiteral([]byte(name)), true
+		}
+	}
+	return "", false
+}
+
+func (m *merger) getObject(doc, number int) *PDFobj {
+	objects := m.docs[doc]
+	if number < 1 || number > len(objects) {
+		return nil
+	}
+	return objects[number-1]
+}
+
+func (m *merger) getCatalog(doc int) *PDFobj {
+	for _, obj := range m.docs[doc] {
+		if obj.getValue("/Type") == "/Catalog" {
+			return obj
+		}
+	}
+	return nil
+}
+
+// getReference returns the object referenced by the value of the key.
+func (m *merger) getReference(doc int, obj *PDFobj, key string) *PDFobj {
+	if obj == nil {
+		return nil
+	}
+	value := getValueTokens(obj.dict, key)
+	if number, ok := getReferenceNumber(value, 0); ok {
+		return m.getObject(doc, number)
+	}
+	return nil
+}
+
+// getDictionary returns the tokens of direct or indirect dictionary value of the key.
+func (m *merger) getDictionary(doc int, dict []string, key string) []string {
+	value := getValueTokens(dict, key)
+	if number, ok := getReferenceNumber(value, 0); ok {
+		if obj := m.getObject(doc, number); obj != nil {
+			return obj.dict
+		}
+		return nil
+	}
+	if len(value) > 0 && value[0] == "<<" {
+		return value
+	}
+	return nil
+}
+
+// findKey returns the index of the key in the top level dictionary or -1 if not found.
+func findKey(dict []string, key string) int {
+	i := indexOf(dict, "<<")
+	if i == -1 {
+		return -1
+	}
+	for i++; i < len(dict) && dict[i] != ">>"; {
+		if dict[i] == key {
+			return i
+		}
+		i = valueEnd(dict, i+1)
+	}
+	return -1
+}
+
+func indexOf(list []string, token string) int {
+	for i, str := range list {
+		if str == token {
+			return i
+		}
+	}
+	return -1
+}
+
+// valueEnd returns the index after the value that starts at the index.
+func valueEnd(dict []string, i int) int {
+	if i >= len(dict) {
+		return len(dict)
+	}
+	token := dict[i]
+	if token == "<<" || token == "[" {
+		closing := ">>"
+		if token == "[" {
+			closing = "]"
+		}
+		level := 0
+		for ; i < len(dict); i++ {
+			if dict[i] == token {
+				level++
+			} else if dict[i] == closing {
+				level--
+				if level == 0 {
+					return i + 1
+				}
+			}
+		}
+		return len(dict)
+	}
+	if strings.HasPrefix(token, "<") {
+		// The reader splits the hexadecimal string before the closing > and at the whitespace.
+		for !strings.HasSuffix(dict[i], ">") && i+1 < len(dict) {
+			i++
+		}
+		return i + 1
+	}
+	if _, ok := getReferenceNumber(dict, i); ok {
+		return i + 3
+	}
+	return i + 1
+}
+
+// getValueTokens returns the value tokens of the key in the top level dictionary.
+func getValueTokens(dict []string, key string) []string {
+	i := findKey(dict, key)
+	if i == -1 {
+		return nil
+	}
+	return dict[i+1 : valueEnd(dict, i+1)]
+}
+
+// getSingleValue returns the value of the key if the value is single token.
+func getSingleValue(dict []string, key string) string {
+	value := getValueTokens(dict, key)
+	if len(value) == 1 {
+		return value[0]
+	}
+	return ""
+}
+
+// setValueTokens replaces the value of the key or adds the key at the end of the dictionary.
+func setValueTokens(dict []string, key string, value []string) []string {
+	list := make([]string, 0, len(dict)+len(value)+1)
+	i := findKey(dict, key)
+	if i != -1 {
+		list = append(list, dict[:i+1]...)
+		list = append(list, value...)
+		return append(list, dict[valueEnd(dict, i+1):]...)
+	}
+	end := valueEnd(dict, indexOf(dict, "<<")) - 1
+	if end < 0 {
+		return dict
+	}
+	list = append(list, dict[:end]...)
+	list = append(list, key)
+	list = append(list, value...)
+	return append(list, dict[end:]...)
+}
+
+// removeKey removes the key and its value from the top level dictionary.
+func removeKey(dict []string, key string) []string {
+	i := findKey(dict, key)
+	if i == -1 {
+		return dict
+	}
+	return append(append([]string{}, dict[:i]...), dict[valueEnd(dict, i+1):]...)
+}
+
+// getReferenceNumber returns the object number if the tokens at the index are indirect reference.
+func getReferenceNumber(tokens []string, i int) (int, bool) {
+	if i+2 >= len(tokens) || tokens[i+2] != "R" {
+		return 0, false
+	}
+	number, err := strconv.Atoi(tokens[i])
+	if err != nil {
+		return 0, false
+	}
+	if _, err := strconv.Atoi(tokens[i+1]); err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+// getReferenceNumbers returns the numbers of all objects referenced by the tokens.
+func getReferenceNumbers(tokens []string) []int {
+	numbers := make([]int, 0)
+	for i := 0; i < len(tokens); i++ {
+		if number, ok := getReferenceNumber(tokens, i); ok {
+			numbers = append(numbers, number)
+			i += 2
+		}
+	}
+	return numbers
+}
+
+// joinStrings joins the hex string tokens split by the tokenizer into one token.
+// The hex string "<48656C6C6F>" is split into "<48656C6C6F" and ">", and when the string
+// is at the end of dictionary the closing bracket is joined with the dictionary delimiter.
+func joinStrings(dict []string) []string {
+	list := make([]string, 0, len(dict))
+	for i := 0; i < len(dict); i++ {
+		token := dict[i]
+		if strings.HasPrefix(token, "<") && !strings.HasPrefix(token, "<<") &&
+			!strings.HasSuffix(token, ">") && i+1 < len(dict) && strings.HasPrefix(dict[i+1], ">") {
+			list = append(list, token+">")
+			i++
+			if dict[i] == ">>" {
+				if i+1 < len(dict) && dict[i+1] == ">" {
+					list = append(list, ">>")
+					i++
+				} else {
+					list = append(list, ">")
+				}
+			}
+			continue
+		}
+		list = append(list, token)
+	}
+	return list
+}
+
+// decodeString returns the bytes of literal or hex string token.
+func decodeString(token string) []byte {
+	if strings.HasPrefix(token, "(") {
+		return unescapeLiteral(strings.TrimSuffix(token[1:], ")"))
+	}
+	data, _ := hex.DecodeString(strings.TrimSuffix(strings.TrimPrefix(token, "<"), ">"))
+	return data
+}
@@ -0,0 +1,269 @@
+package pdfjet
+
+/**
+ * objectstream.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+
+	"github.com/edragoev1/pdfjet/src/compliance"
+	"github.com/edragoev1/pdfjet/src/compressor"
+)
+
+// The maximum number of objects packed in one object stream.
+const objectsPerStream = 100
+
+// objectStreams collects the objects without stream and packs them into compressed object streams.
+// See ISO 32000-2, 7.5.7 Object streams and 7.5.8 Cross-reference streams.
+type objectStreams struct {
+	writer     *bufio.Writer  // The PDF writer while the object is captured
+	buffer     bytes.Buffer   // The bytes of the captured object
+	capture    *bufio.Writer  // Writes to the buffer
+	number     int            // The number of the captured object, 0 when not capturing
+	numbers    []int          // The numbers of the objects waiting to be packed
+	bodies     [][]byte       // The objects waiting to be packed - without "obj" and "endobj"
+	compressed map[int][2]int // Object number -> object stream number and index
+}
+
+// SetObjectStreams enables or disables the compressed object streams.
+// When enabled the objects without stream are packed into compressed object streams
+// and the cross-reference table is written as compressed cross-reference stream.
+// This makes the documents with many small objects - tables, annotations, structure elements - much smaller.
+// The object streams are not used for encrypted, signed, PDF/A-1 and incrementally updated documents.
+func (pdf *PDF) SetObjectStreams(enabled bool) {
+	if !enabled {
+		pdf.objStm = nil
+		return
+	}
+	if pdf.objStm == nil {
+		objStm := new(objectStreams)
+		objStm.capture = bufio.NewWriter(&objStm.buffer)
+		objStm.compressed = make(map[int][2]int)
+		pdf.objStm = objStm
+	}
+}
+
+// usesObjectStreams returns true if the objects written from now on are packed into object streams.
+func (pdf *PDF) usesObjectStreams() bool {
+	return pdf.objStm != nil &&
+		pdf.encryption == nil &&
+		pdf.signature == nil &&
+		!pdf.incremental &&
+		pdf.compliance != compliance.PDF_A_1A &&
+		pdf.compliance != compliance.PDF_A_1B
+}
+
+// startCapture redirects the output to buffer until endCapture is called.
+// Must be called after the offset of the object is added to objOffsets.
+func (pdf *PDF) startCapture(number int) {
+	if !pdf.usesObjectStreams() || pdf.objStm.number != 0 {
+		return
+	}
+	objStm := pdf.objStm
+	objStm.number = number
+	objStm.buffer.Reset()
+	objStm.writer = pdf.writer
+	pdf.writer = objStm.capture
+}
+
+// endCapture writes the captured object to the output when it has stream.
+// Otherwise the object is kept to be packed into object stream.
+func (pdf *PDF) endCapture() {
+	objStm := pdf.objStm
+	if objStm == nil || objStm.number == 0 {
+		return
+	}
+	objStm.capture.Flush()
+	pdf.writer = objStm.writer
+	number := objStm.number
+	objStm.number = 0
+
+	data := objStm.buffer.Bytes()
+	body := getObjectBody(data)
+	if body == nil || bytes.Contains(data, []byte("endstream")) {
+		pdf.writer.Write(data) // The byteCount already includes the data
+		return
+	}
+	pdf.byteCount -= len(data)
+	objStm.numbers = append(objStm.numbers, number)
+	objStm.bodies = append(objStm.bodies, append([]byte(nil), body...))
+	if len(objStm.numbers) == objectsPerStream {
+		pdf.addObjectStream()
+	}
+}
+
+// getObjectBody returns the object without the "N 0 obj" header and the "endobj" keyword.
+// Returns nil if the object has unexpected format.
+func getObjectBody(data []byte) []byte {
+	i := bytes.Index(data, []byte("obj"))
+	if i == -1 {
+		return nil
+	}
+	body := bytes.TrimSpace(data[i+3:])
+	if !bytes.HasSuffix(body, []byte("endobj")) {
+		return nil
+	}
+	return bytes.TrimSpace(body[:len(body)-6])
+}
+
+// addObjectStream writes the objects waiting to be packed as compressed object stream.
+func (pdf *PDF) addObjectStream() {
+	objStm := pdf.objStm
+	if objStm == nil || len(objStm.numbers) == 0 {
+		return
+	}
+	var header bytes.Buffer
+	var objects bytes.Buffer
+	for i, number := range objStm.numbers {
+		header.WriteString(strconv.Itoa(number))
+		header.WriteString(" ")
+		header.WriteString(strconv.Itoa(objects.Len()))
+		header.WriteString(" ")
+		objects.Write(objStm.bodies[i])
+		objects.WriteString("\n")
+	}
+	first := header.Len()
+	header.Write(objects.Bytes())
+	stream := compressor.Deflate(header.Bytes())
+
+	// Written directly - the object stream must not be captured.
+	pdf.objOffsets = append(pdf.objOffsets, pdf.byteCount)
+	objStmNumber := len(pdf.objOffsets)
+	for i, number := range objStm.numbers {
+		objStm.compressed[number] = [2]int{objStmNumber, i}
+	}
+	pdf.appendInteger(objStmNumber)
+	pdf.appendString(" 0 obj\n")
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /ObjStm\n")
+	pdf.appendString("/N ")
+	pdf.appendInteger(len(objStm.numbers))
+	pdf.appendString("\n")
+	pdf.appendString("/First ")
+	pdf.appendInteger(first)
+	pdf.appendString("\n")
+	pdf.appendString("/Filter /FlateDecode\n")
+	pdf.appendString("/Length ")
+	pdf.appendInteger(len(stream))
+	pdf.appendString("\n")
+	pdf.appendString(">>\n")
+	pdf.appendString("stream\n")
+	pdf.appendByteArray(stream)
+	pdf.appendString("\nendstream\n")
+	pdf.appendString("endobj\n")
+
+	objStm.numbers = objStm.numbers[:0]
+	objStm.bodies = objStm.bodies[:0]
+}
+
+// usesXRefStream returns true if the cross-reference stream must be written instead of xref table.
+func (pdf *PDF) usesXRefStream() bool {
+	return pdf.usesObjectStreams() || (pdf.objStm != nil && len(pdf.objStm.compressed) > 0)
+}
+
+// addXRefStream writes the compressed cross-reference stream that replaces the xref table and the trailer.
+// Returns the offset of the cross-reference stream.
+func (pdf *PDF) addXRefStream(infoObjNumber, rootObjNumber, encryptObjNumber int) int {
+	pdf.addObjectStream()
+
+	startxref := pdf.byteCount
+	pdf.objOffsets = append(pdf.objOffsets, startxref)
+	xrefObjNumber := len(pdf.objOffsets)
+	size := xrefObjNumber + 1
+
+	// The second field holds the offset or the object stream number.
+	w2 := 1
+	for max := startxref; max > 0xFF; max >>= 8 {
+		w2++
+	}
+	row := make([]byte, 1+w2+2)
+	var entries bytes.Buffer
+	entries.Grow(size * len(row))
+	putEntry := func(kind, field2, field3 int) {
+		row[0] = byte(kind)
+		for i := w2; i > 0; i-- {
+			row[i] = byte(field2)
+			field2 >>= 8
+		}
+		row[w2+1] = byte(field3 >> 8)
+		row[w2+2] = byte(field3)
+		entries.Write(row)
+	}
+	putEntry(0, 0, 0xFFFF)
+	for i, offset := range pdf.objOffsets {
+		if pdf.objStm != nil {
+			if location, ok := pdf.objStm.compressed[i+1]; ok {
+				putEntry(2, location[0], location[1])
+				continue
+			}
+		}
+		putEntry(1, offset, 0)
+	}
+	stream := compressor.Deflate(entries.Bytes())
+
+	pdf.appendInteger(xrefObjNumber)
+	pdf.appendString(" 0 obj\n")
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /XRef\n")
+	pdf.appendString("/Size ")
+	pdf.appendInteger(size)
+	pdf.appendString("\n")
+	pdf.appendString("/W [1 ")
+	pdf.appendInteger(w2)
+	pdf.appendString(" 2]\n")
+
+	pdf.appendString("/ID[<")
+	pdf.appendString(pdf.uuid)
+	pdf.appendString("><")
+	pdf.appendString(pdf.uuid)
+	pdf.appendString(">]\n")
+
+	pdf.appendString("/Info ")
+	pdf.appendInteger(infoObjNumber)
+	pdf.appendString(" 0 R\n")
+
+	pdf.appendString("/Root ")
+	pdf.appendInteger(rootObjNumber)
+	pdf.appendString(" 0 R\n")
+
+	if encryptObjNumber != 0 {
+		pdf.appendString("/Encrypt ")
+		pdf.appendInteger(encryptObjNumber)
+		pdf.appendString(" 0 R\n")
+	}
+
+	pdf.appendString("/Filter /FlateDecode\n")
+	pdf.appendString("/Length ")
+	pdf.appendInteger(len(stream))
+	pdf.appendString("\n")
+	pdf.appendString(">>\n")
+	pdf.appendString("stream\n")
+	pdf.appendByteArray(stream)
+	pdf.appendString("\nendstream\n")
+	pdf.appendString("endobj\n")
+
+	return startxref
+}
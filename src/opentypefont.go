@@ -30,8 +30,11 @@ import (
 	"strings"
 )
 
-func registerOpenTypeFont(pdf *PDF, font *Font, reader io.Reader) {
-	otf := NewOTF(reader)
+func registerOpenTypeFont(pdf *PDF, font *Font, reader io.Reader) error {
+	otf, err := NewOTFErr(reader)
+	if err != nil {
+		return err
+	}
 
 	font.name = otf.fontName
 	font.firstChar = otf.firstChar
@@ -47,8 +50,29 @@ func registerOpenTypeFont(pdf *PDF, font *Font, reader io.Reader) {
 	font.fontUnderlinePosition = otf.underlinePosition
 	font.fontUnderlineThickness = otf.underlineThickness
 	font.advanceWidth = otf.advanceWidth
+	font.capHeight = otf.capHeight
+	font.info = otf.fontInfo
+	font.cff = otf.cff
 	font.SetSize(font.size)
 
+	// The font is drawn without shaping when the layout tables cannot be read.
+	if layout, err := newOTLayout(otf.buf); err == nil {
+		font.layout = layout
+	}
+	// The color glyphs are drawn with their outlines when the color tables cannot be read.
+	if color, err := newColorGlyphs(otf.buf); err == nil {
+		font.color = color
+	}
+
+	if pdf.usesFontSubsetting() {
+		program := otf.buf
+		if otf.cff {
+			program = otf.buf[otf.cffOff : otf.cffOff+otf.cffLen]
+		}
+		registerFontSubset(pdf, font, program)
+		return nil
+	}
+
 	embedOpenTypeFontFile(pdf, font, otf)
 	addOpenTypeFontDescriptorObject(pdf, font, otf)
 	addOpenTypeFontCIDFontDictionaryObject(pdf, font, otf)
@@ -74,6 +98,7 @@ func registerOpenTypeFont(pdf *PDF, font *Font, reader io.Reader) {
 
 	font.objNumber = pdf.getObjNumber()
 	pdf.fonts = append(pdf.fonts, font)
+	return nil
 }
 
 func embedOpenTypeFontFile(pdf *PDF, font *Font, otf *OTF) {
@@ -94,8 +119,9 @@ func embedOpenTypeFontFile(pdf *PDF, font *Font, otf *OTF) {
 	}
 	pdf.appendString("/Filter /FlateDecode\n")
 
+	stream := pdf.encrypt(otf.compressed.Bytes())
 	pdf.appendString("/Length ")
-	pdf.appendInteger(otf.compressed.Len()) // The compressed size
+	pdf.appendInteger(len(stream)) // The compressed size
 	pdf.appendString("\n")
 
 	if !otf.cff {
@@ -112,7 +138,7 @@ func embedOpenTypeFontFile(pdf *PDF, font *Font, otf *OTF) {
 
 	pdf.appendString(">>\n")
 	pdf.appendString("stream\n")
-	pdf.appendByteArray(otf.compressed.Bytes())
+	pdf.appendByteArray(stream)
 	pdf.appendString("\nendstream\n")
 	pdf.endobj()
 
@@ -214,14 +240,15 @@ func addOpenTypeFontToUnicodeCMapObject(pdf *PDF, font *Font, otf *OTF) {
 	sb.WriteString("CMapName currentdict /CMap defineresource pop\n")
 	sb.WriteString("end\nend")
 
+	cmap := pdf.encrypt([]byte(sb.String()))
 	pdf.newobj()
 	pdf.appendString("<<\n")
 	pdf.appendString("/Length ")
-	pdf.appendInteger(sb.Len())
+	pdf.appendInteger(len(cmap))
 	pdf.appendString("\n")
 	pdf.appendString(">>\n")
 	pdf.appendString("stream\n")
-	pdf.appendString(sb.String())
+	pdf.appendByteArray(cmap)
 	pdf.appendString("\nendstream\n")
 	pdf.endobj()
 
@@ -247,7 +274,11 @@ func addOpenTypeFontCIDFontDictionaryObject(pdf *PDF, font *Font, otf *OTF) {
 	pdf.appendString("/BaseFont /")
 	pdf.appendString(otf.fontName)
 	pdf.appendString("\n")
-	pdf.appendString("/CIDSystemInfo <</Registry (Adobe) /Ordering (Identity) /Supplement 0>>\n")
+	pdf.appendString("/CIDSystemInfo <</Registry ")
+	pdf.appendLiteral("Adobe")
+	pdf.appendString(" /Ordering ")
+	pdf.appendLiteral("Identity")
+	pdf.appendString(" /Supplement 0>>\n")
 	pdf.appendString("/FontDescriptor ")
 	pdf.appendInteger(font.fontDescriptorObjNumber)
 	pdf.appendString(" 0 R\n")
@@ -76,7 +76,9 @@ func (ocg *OptionalContentGroup) DrawOn(page *Page) {
 		page.pdf.newobj()
 		page.pdf.appendString("<<\n")
 		page.pdf.appendString("/Type /OCG\n")
-		page.pdf.appendString("/Name (" + ocg.name + ")\n")
+		page.pdf.appendString("/Name ")
+		page.pdf.appendLiteral(ocg.name)
+		page.pdf.appendString("\n")
 		page.pdf.appendString("/Usage <<\n")
 		if ocg.visible {
 			page.pdf.appendString("/View << /ViewState /ON >>\n")
@@ -27,7 +27,6 @@ SOFTWARE.
 import (
 	"bytes"
 	"compress/zlib"
-	"fmt"
 	"io"
 	"log"
 	"strings"
@@ -76,9 +75,30 @@ type OTF struct {
 }
 
 // NewOTF is the constructor for TTF and OTF fonts.
+// The program exits if the font cannot be parsed. Use NewOTFErr to handle the error.
 func NewOTF(reader io.Reader) *OTF {
+	otf, err := NewOTFErr(reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return otf
+}
+
+// NewOTFErr is the constructor for TTF and OTF fonts.
+// The WOFF and WOFF2 fonts are decoded to TTF or OTF first.
+// Returns IOError, MalformedInputError or UnsupportedFeatureError when the font cannot be parsed.
+func NewOTFErr(reader io.Reader) (_ *OTF, err error) {
+	defer recoverMalformedInput("OpenType font", &err)
+
 	otf := new(OTF)
-	otf.buf = content.GetFromReader(reader)
+	otf.buf, err = content.GetFromReaderErr(reader)
+	if err != nil {
+		return nil, &IOError{Op: "read", Err: err}
+	}
+	otf.buf, err = decodeWebFont(otf.buf)
+	if err != nil {
+		return nil, err
+	}
 	otf.unicodeToGID = make([]int, 0x10000)
 
 	// Extract the OTF metadata
@@ -88,7 +108,7 @@ func NewOTF(reader io.Reader) *OTF {
 		version == 0x4F54544F { // CFF OTF
 		// We should be able to read this font.
 	} else {
-		fmt.Println("OTF version == " + fmt.Sprint(version) + " is not supported.")
+		return nil, unsupported("OTF version == 0x%08X", version)
 	}
 
 	numOfTables := int(readUint16(otf))
@@ -127,23 +147,25 @@ func NewOTF(reader io.Reader) *OTF {
 	}
 
 	// This table must be processed last
-	getCmapTable(otf, cmapTable)
+	if cmapTable == nil {
+		return nil, malformed("OpenType font", "cmap table not found")
+	}
+	if err := getCmapTable(otf, cmapTable); err != nil {
+		return nil, err
+	}
 
 	writer := zlib.NewWriter(&otf.compressed)
 	if otf.cff {
-		_, err := writer.Write(otf.buf[otf.cffOff : otf.cffOff+otf.cffLen])
-		if err != nil {
-			log.Fatal(err)
-		}
+		_, err = writer.Write(otf.buf[otf.cffOff : otf.cffOff+otf.cffLen])
 	} else {
-		_, err := writer.Write(otf.buf)
-		if err != nil {
-			log.Fatal(err)
-		}
+		_, err = writer.Write(otf.buf)
+	}
+	if err != nil {
+		return nil, err
 	}
 	writer.Close()
 
-	return otf
+	return otf, nil
 }
 
 func getHeadTable(otf *OTF, table *FontTable) {
@@ -221,7 +243,7 @@ func getNameTable(otf *OTF, table *FontTable) {
 	}
 }
 
-func getCmapTable(otf *OTF, table *FontTable) {
+func getCmapTable(otf *OTF, table *FontTable) error {
 	otf.index = table.offset
 	tableOffset := otf.index
 	otf.index += 2
@@ -240,7 +262,7 @@ func getCmapTable(otf *OTF, table *FontTable) {
 		}
 	}
 	if !format4subtable {
-		log.Fatal("Format 4 subtable not found in this font.")
+		return unsupported("font without format 4 cmap subtable")
 	}
 
 	otf.index = tableOffset + subtableOffset
@@ -295,6 +317,7 @@ func getCmapTable(otf *OTF, table *FontTable) {
 			otf.unicodeToGID[ch] = gid
 		}
 	}
+	return nil
 }
 
 func getHmtxTable(otf *OTF, table *FontTable) {
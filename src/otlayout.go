@@ -0,0 +1,547 @@
+package pdfjet
+
+/**
+ * otlayout.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The GDEF glyph classes.
+const (
+	glyphClassBase      = 1
+	glyphClassLigature  = 2
+	glyphClassMark      = 3
+	glyphClassComponent = 4
+)
+
+// The lookup flags.
+const (
+	lookupIgnoreBaseGlyphs    = 0x0002
+	lookupIgnoreLigatures     = 0x0004
+	lookupIgnoreMarks         = 0x0008
+	lookupUseMarkFilteringSet = 0x0010
+	lookupMarkAttachmentType  = 0xFF00
+)
+
+// otLayout holds the OpenType layout tables - GDEF, GSUB and GPOS - used for shaping.
+// The tables are kept in their binary form and read when the text is shaped.
+// See the OpenType specification, chapter "Advanced Typographic Tables".
+type otLayout struct {
+	glyphClassDef      []byte   // GDEF glyph class definitions, nil if not present
+	markAttachClassDef []byte   // GDEF mark attachment classes, nil if not present
+	markGlyphSets      [][]byte // GDEF mark glyph sets - coverage tables
+	gsub               *otLayoutTable
+	gpos               *otLayoutTable
+	consonantPositions map[[2]int]int // The Indic consonant positions by script and glyph ID
+}
+
+// otLayoutTable is GSUB or GPOS table.
+type otLayoutTable struct {
+	scriptList  []byte
+	featureList []byte
+	lookups     []*otLookup
+	cache       map[string][]otLookupRef // The lookups for script, language and features
+}
+
+// otLookup is lookup with the extension subtables resolved.
+type otLookup struct {
+	kind          int
+	flag          int
+	markFilterSet int
+	subtables     [][]byte
+}
+
+// otLookupRef is the lookup to apply with the mask of the glyphs it is applied to.
+type otLookupRef struct {
+	index int
+	mask  uint32
+}
+
+// otFeature is feature to apply to the glyphs with the mask.
+type otFeature struct {
+	tag  string
+	mask uint32
+}
+
+// getSfntTables returns the tables of TrueType or OpenType font file by tag.
+func getSfntTables(buf []byte) map[string][]byte {
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(buf[4:]))
+	for i := 0; i < numTables; i++ {
+		entry := buf[12+16*i:]
+		offset := int(binary.BigEndian.Uint32(entry[8:]))
+		length := int(binary.BigEndian.Uint32(entry[12:]))
+		tables[string(entry[0:4])] = buf[offset : offset+length]
+	}
+	return tables
+}
+
+// newOTLayout returns the layout tables of the font file or nil if the font has no GSUB and GPOS tables.
+func newOTLayout(buf []byte) (_ *otLayout, err error) {
+	defer recoverMalformedInput("OpenType layout tables", &err)
+
+	tables := getSfntTables(buf)
+	if tables["GSUB"] == nil && tables["GPOS"] == nil {
+		return nil, nil
+	}
+	layout := new(otLayout)
+	if gdef := tables["GDEF"]; gdef != nil {
+		if offset := u16(gdef, 4); offset != 0 {
+			layout.glyphClassDef = gdef[offset:]
+		}
+		if offset := u16(gdef, 10); offset != 0 {
+			layout.markAttachClassDef = gdef[offset:]
+		}
+		if u16(gdef, 2) >= 2 { // Version 1.2 and later
+			if offset := u16(gdef, 12); offset != 0 {
+				sets := gdef[offset:]
+				for i := 0; i < u16(sets, 2); i++ {
+					layout.markGlyphSets = append(layout.markGlyphSets, sets[u32(sets, 4+4*i):])
+				}
+			}
+		}
+	}
+	if gsub := tables["GSUB"]; gsub != nil {
+		layout.gsub = newOTLayoutTable(gsub, 7)
+	}
+	if gpos := tables["GPOS"]; gpos != nil {
+		layout.gpos = newOTLayoutTable(gpos, 9)
+	}
+	return layout, nil
+}
+
+// newOTLayoutTable reads the lookup list of GSUB or GPOS table.
+func newOTLayoutTable(data []byte, extensionType int) *otLayoutTable {
+	table := new(otLayoutTable)
+	table.scriptList = data[u16(data, 4):]
+	table.featureList = data[u16(data, 6):]
+	table.cache = make(map[string][]otLookupRef)
+	lookupList := data[u16(data, 8):]
+	count := u16(lookupList, 0)
+	table.lookups = make([]*otLookup, count)
+	for i := 0; i < count; i++ {
+		data := lookupList[u16(lookupList, 2+2*i):]
+		lookup := new(otLookup)
+		lookup.kind = u16(data, 0)
+		lookup.flag = u16(data, 2)
+		subtableCount := u16(data, 4)
+		lookup.markFilterSet = -1
+		if lookup.flag&lookupUseMarkFilteringSet != 0 {
+			lookup.markFilterSet = u16(data, 6+2*subtableCount)
+		}
+		extension := lookup.kind == extensionType
+		for j := 0; j < subtableCount; j++ {
+			subtable := data[u16(data, 6+2*j):]
+			if extension {
+				lookup.kind = u16(subtable, 2)
+				subtable = subtable[u32(subtable, 4):]
+			}
+			lookup.subtables = append(lookup.subtables, subtable)
+		}
+		table.lookups[i] = lookup
+	}
+	return table
+}
+
+// getLookups returns the lookups of the features for the first of the scripts found in the font
+// in the order they must be applied.
+func (table *otLayoutTable) getLookups(scripts []string, language string, features []otFeature) []otLookupRef {
+	var key strings.Builder
+	key.WriteString(strings.Join(scripts, ","))
+	key.WriteString("|")
+	key.WriteString(language)
+	for _, feature := range features {
+		key.WriteString("|")
+		key.WriteString(feature.tag)
+		key.WriteString(strconv.FormatUint(uint64(feature.mask), 16))
+	}
+	if lookups, ok := table.cache[key.String()]; ok {
+		return lookups
+	}
+
+	langSys := table.getLangSys(scripts, language)
+	masks := make(map[int]uint32)
+	if langSys != nil {
+		featureIndexes := make([]int, 0)
+		if required := u16(langSys, 2); required != 0xFFFF {
+			featureIndexes = append(featureIndexes, required)
+		}
+		for i := 0; i < u16(langSys, 4); i++ {
+			featureIndexes = append(featureIndexes, u16(langSys, 6+2*i))
+		}
+		for _, index := range featureIndexes {
+			tag := string(table.featureList[2+6*index : 6+6*index])
+			feature := table.featureList[u16(table.featureList, 6+6*index):]
+			for _, f := range features {
+				if f.tag == tag {
+					for j := 0; j < u16(feature, 2); j++ {
+						masks[u16(feature, 4+2*j)] |= f.mask
+					}
+				}
+			}
+		}
+	}
+	lookups := make([]otLookupRef, 0, len(masks))
+	for index, mask := range masks {
+		if index < len(table.lookups) {
+			lookups = append(lookups, otLookupRef{index, mask})
+		}
+	}
+	sort.Slice(lookups, func(i, j int) bool { return lookups[i].index < lookups[j].index })
+	table.cache[key.String()] = lookups
+	return lookups
+}
+
+// getLangSys returns the language system table for the script and the language.
+// Falls back to the default script and the default language system.
+func (table *otLayoutTable) getLangSys(scripts []string, language string) []byte {
+	candidates := append(append([]string(nil), scripts...), "DFLT", "dflt", "latn")
+	for _, tag := range candidates {
+		for i := 0; i < u16(table.scriptList, 0); i++ {
+			if string(table.scriptList[2+6*i:6+6*i]) != tag {
+				continue
+			}
+			script := table.scriptList[u16(table.scriptList, 6+6*i):]
+			for j := 0; j < u16(script, 2); j++ {
+				if string(script[4+6*j:8+6*j]) == language {
+					return script[u16(script, 8+6*j):]
+				}
+			}
+			if offset := u16(script, 0); offset != 0 {
+				return script[offset:]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// getGlyphClass returns the GDEF class of the glyph or zero if the font has no glyph classes.
+func (layout *otLayout) getGlyphClass(gid int) int {
+	if layout.glyphClassDef == nil {
+		return 0
+	}
+	return getClass(layout.glyphClassDef, gid)
+}
+
+// ignores returns true if the lookup flag requires to skip the glyph.
+func (layout *otLayout) ignores(lookup *otLookup, g *otGlyph) bool {
+	flag := lookup.flag
+	switch g.class {
+	case glyphClassBase:
+		return flag&lookupIgnoreBaseGlyphs != 0
+	case glyphClassLigature:
+		return flag&lookupIgnoreLigatures != 0
+	case glyphClassMark:
+		if flag&lookupIgnoreMarks != 0 {
+			return true
+		}
+		if lookup.markFilterSet >= 0 {
+			return lookup.markFilterSet >= len(layout.markGlyphSets) ||
+				getCoverageIndex(layout.markGlyphSets[lookup.markFilterSet], g.gid) < 0
+		}
+		if attachmentType := (flag & lookupMarkAttachmentType) >> 8; attachmentType != 0 {
+			return layout.markAttachClassDef == nil ||
+				getClass(layout.markAttachClassDef, g.gid) != attachmentType
+		}
+	}
+	return false
+}
+
+// getCoverageIndex returns the coverage index of the glyph or -1 if the glyph is not covered.
+func getCoverageIndex(coverage []byte, gid int) int {
+	switch u16(coverage, 0) {
+	case 1:
+		count := u16(coverage, 2)
+		i := sort.Search(count, func(i int) bool { return u16(coverage, 4+2*i) >= gid })
+		if i < count && u16(coverage, 4+2*i) == gid {
+			return i
+		}
+	case 2:
+		count := u16(coverage, 2)
+		i := sort.Search(count, func(i int) bool { return u16(coverage, 6+6*i) >= gid })
+		if i < count && u16(coverage, 4+6*i) <= gid {
+			return u16(coverage, 8+6*i) + gid - u16(coverage, 4+6*i)
+		}
+	}
+	return -1
+}
+
+// getClass returns the class of the glyph from class definition table.
+func getClass(classDef []byte, gid int) int {
+	switch u16(classDef, 0) {
+	case 1:
+		start := u16(classDef, 2)
+		if gid >= start && gid < start+u16(classDef, 4) {
+			return u16(classDef, 6+2*(gid-start))
+		}
+	case 2:
+		count := u16(classDef, 2)
+		i := sort.Search(count, func(i int) bool { return u16(classDef, 6+6*i) >= gid })
+		if i < count && u16(classDef, 4+6*i) <= gid {
+			return u16(classDef, 8+6*i)
+		}
+	}
+	return 0
+}
+
+// applyContext applies the contextual lookup subtable - GSUB type 5 or GPOS type 7 - at position i.
+// The nested lookups are applied by the apply function.
+// Returns the position after the matched input sequence or -1 if the subtable does not match.
+func (s *otShaper) applyContext(lookup *otLookup, data []byte, i int, apply func(index, pos int)) int {
+	gid := s.glyphs[i].gid
+	switch u16(data, 0) {
+	case 1:
+		index := getCoverageIndex(data[u16(data, 2):], gid)
+		if index < 0 || index >= u16(data, 4) {
+			return -1
+		}
+		ruleSet := data[u16(data, 6+2*index):]
+		for j := 0; j < u16(ruleSet, 0); j++ {
+			rule := ruleSet[u16(ruleSet, 2+2*j):]
+			glyphCount, recordCount := u16(rule, 0), u16(rule, 2)
+			input := rule[4:]
+			positions := s.matchInput(lookup, i, glyphCount, func(k, gid int) bool {
+				return u16(input, 2*(k-1)) == gid
+			})
+			if positions != nil {
+				return s.applyNested(positions, rule[4+2*(glyphCount-1):], recordCount, apply)
+			}
+		}
+	case 2:
+		if getCoverageIndex(data[u16(data, 2):], gid) < 0 {
+			return -1
+		}
+		classDef := data[u16(data, 4):]
+		class := getClass(classDef, gid)
+		if class >= u16(data, 6) || u16(data, 8+2*class) == 0 {
+			return -1
+		}
+		ruleSet := data[u16(data, 8+2*class):]
+		for j := 0; j < u16(ruleSet, 0); j++ {
+			rule := ruleSet[u16(ruleSet, 2+2*j):]
+			glyphCount, recordCount := u16(rule, 0), u16(rule, 2)
+			input := rule[4:]
+			positions := s.matchInput(lookup, i, glyphCount, func(k, gid int) bool {
+				return u16(input, 2*(k-1)) == getClass(classDef, gid)
+			})
+			if positions != nil {
+				return s.applyNested(positions, rule[4+2*(glyphCount-1):], recordCount, apply)
+			}
+		}
+	case 3:
+		glyphCount, recordCount := u16(data, 2), u16(data, 4)
+		if glyphCount == 0 || getCoverageIndex(data[u16(data, 6):], gid) < 0 {
+			return -1
+		}
+		positions := s.matchInput(lookup, i, glyphCount, func(k, gid int) bool {
+			return getCoverageIndex(data[u16(data, 6+2*k):], gid) >= 0
+		})
+		if positions != nil {
+			return s.applyNested(positions, data[6+2*glyphCount:], recordCount, apply)
+		}
+	}
+	return -1
+}
+
+// applyChainContext applies the chained contextual lookup subtable - GSUB type 6 or GPOS type 8 - at position i.
+// Returns the position after the matched input sequence or -1 if the subtable does not match.
+func (s *otShaper) applyChainContext(lookup *otLookup, data []byte, i int, apply func(index, pos int)) int {
+	gid := s.glyphs[i].gid
+	switch u16(data, 0) {
+	case 1:
+		index := getCoverageIndex(data[u16(data, 2):], gid)
+		if index < 0 || index >= u16(data, 4) {
+			return -1
+		}
+		ruleSet := data[u16(data, 6+2*index):]
+		for j := 0; j < u16(ruleSet, 0); j++ {
+			rule := ruleSet[u16(ruleSet, 2+2*j):]
+			glyphsEqual := func(array []byte) func(k, gid int) bool {
+				return func(k, gid int) bool { return u16(array, 2*k) == gid }
+			}
+			if next := s.applyChainRule(lookup, rule, i, glyphsEqual, glyphsEqual, glyphsEqual, apply); next >= 0 {
+				return next
+			}
+		}
+	case 2:
+		if getCoverageIndex(data[u16(data, 2):], gid) < 0 {
+			return -1
+		}
+		backtrackClassDef := data[u16(data, 4):]
+		inputClassDef := data[u16(data, 6):]
+		lookaheadClassDef := data[u16(data, 8):]
+		class := getClass(inputClassDef, gid)
+		if class >= u16(data, 10) || u16(data, 12+2*class) == 0 {
+			return -1
+		}
+		ruleSet := data[u16(data, 12+2*class):]
+		classesEqual := func(classDef []byte) func(array []byte) func(k, gid int) bool {
+			return func(array []byte) func(k, gid int) bool {
+				return func(k, gid int) bool { return u16(array, 2*k) == getClass(classDef, gid) }
+			}
+		}
+		for j := 0; j < u16(ruleSet, 0); j++ {
+			rule := ruleSet[u16(ruleSet, 2+2*j):]
+			if next := s.applyChainRule(lookup, rule, i,
+				classesEqual(backtrackClassDef),
+				classesEqual(inputClassDef),
+				classesEqual(lookaheadClassDef), apply); next >= 0 {
+				return next
+			}
+		}
+	case 3:
+		backtrackCount := u16(data, 2)
+		backtrack := data[4:]
+		input := data[4+2*backtrackCount:]
+		inputCount := u16(input, 0)
+		lookahead := input[2+2*inputCount:]
+		lookaheadCount := u16(lookahead, 0)
+		records := lookahead[2+2*lookaheadCount:]
+		if inputCount == 0 || getCoverageIndex(data[u16(input, 2):], gid) < 0 {
+			return -1
+		}
+		positions := s.matchInput(lookup, i, inputCount, func(k, gid int) bool {
+			return getCoverageIndex(data[u16(input, 2+2*k):], gid) >= 0
+		})
+		if positions == nil ||
+			!s.matchBacktrack(lookup, i, backtrackCount, func(k, gid int) bool {
+				return getCoverageIndex(data[u16(backtrack, 2*k):], gid) >= 0
+			}) ||
+			!s.matchLookahead(lookup, positions[len(positions)-1], lookaheadCount, func(k, gid int) bool {
+				return getCoverageIndex(data[u16(lookahead, 2+2*k):], gid) >= 0
+			}) {
+			return -1
+		}
+		return s.applyNested(positions, records[2:], u16(records, 0), apply)
+	}
+	return -1
+}
+
+// applyChainRule applies ChainSequenceRule or ChainClassSequenceRule.
+// The input array does not include the first glyph.
+func (s *otShaper) applyChainRule(
+	lookup *otLookup,
+	rule []byte,
+	i int,
+	backtrackMatch, inputMatch, lookaheadMatch func(array []byte) func(k, gid int) bool,
+	apply func(index, pos int)) int {
+	backtrackCount := u16(rule, 0)
+	backtrack := rule[2:]
+	input := rule[2+2*backtrackCount:]
+	inputCount := u16(input, 0)
+	lookahead := input[2*inputCount:]
+	lookaheadCount := u16(lookahead, 0)
+	records := lookahead[2+2*lookaheadCount:]
+	if inputCount == 0 {
+		return -1
+	}
+	inputGlyphs := input[2:]
+	match := inputMatch(inputGlyphs)
+	positions := s.matchInput(lookup, i, inputCount, func(k, gid int) bool { return match(k-1, gid) })
+	if positions == nil ||
+		!s.matchBacktrack(lookup, i, backtrackCount, backtrackMatch(backtrack)) ||
+		!s.matchLookahead(lookup, positions[len(positions)-1], lookaheadCount, lookaheadMatch(lookahead[2:])) {
+		return -1
+	}
+	return s.applyNested(positions, records[2:], u16(records, 0), apply)
+}
+
+// matchInput returns the positions of the input sequence starting at position i
+// or nil if the sequence does not match. The first glyph is already matched.
+func (s *otShaper) matchInput(lookup *otLookup, i, count int, match func(k, gid int) bool) []int {
+	positions := make([]int, 1, count)
+	positions[0] = i
+	for k := 1; k < count; k++ {
+		i = s.nextGlyph(lookup, i)
+		if i < 0 || !match(k, s.glyphs[i].gid) {
+			return nil
+		}
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+// matchBacktrack returns true if the glyphs before position i match.
+func (s *otShaper) matchBacktrack(lookup *otLookup, i, count int, match func(k, gid int) bool) bool {
+	for k := 0; k < count; k++ {
+		i = s.prevGlyph(lookup, i)
+		if i < 0 || !match(k, s.glyphs[i].gid) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLookahead returns true if the glyphs after position i match.
+func (s *otShaper) matchLookahead(lookup *otLookup, i, count int, match func(k, gid int) bool) bool {
+	for k := 0; k < count; k++ {
+		i = s.nextGlyph(lookup, i)
+		if i < 0 || !match(k, s.glyphs[i].gid) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyNested applies the nested lookups of the SequenceLookupRecords.
+// Returns the position after the input sequence.
+func (s *otShaper) applyNested(positions []int, records []byte, count int, apply func(index, pos int)) int {
+	end := positions[len(positions)-1] + 1
+	for j := 0; j < count; j++ {
+		sequenceIndex, lookupIndex := u16(records, 4*j), u16(records, 2+4*j)
+		if sequenceIndex >= len(positions) {
+			continue
+		}
+		pos := positions[sequenceIndex]
+		n := len(s.glyphs)
+		apply(lookupIndex, pos)
+		if delta := len(s.glyphs) - n; delta != 0 {
+			// The nested lookup changed the number of glyphs after pos.
+			for k := range positions {
+				if positions[k] > pos {
+					positions[k] += delta
+				}
+			}
+			end += delta
+		}
+	}
+	return end
+}
+
+func u16(data []byte, offset int) int {
+	return int(binary.BigEndian.Uint16(data[offset:]))
+}
+
+func s16(data []byte, offset int) int {
+	return int(int16(binary.BigEndian.Uint16(data[offset:])))
+}
+
+func u32(data []byte, offset int) int {
+	return int(binary.BigEndian.Uint32(data[offset:]))
+}
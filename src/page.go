@@ -82,6 +82,14 @@ type Page struct {
 	savedStates   []*State
 	mcid          int
 	savedHeight   float32
+	textRise      float32
+	rightToLeft   bool    // The text run is drawn from right to left
+	wordSpacing   float32 // The spacing added after each space of the justified line
+	charSpacing   float32 // The spacing added after each glyph of the justified line
+
+	textX            float32             // The position in the text object drawn by drawString
+	drawsGlyphImages bool                // The images of the bitmap glyphs are drawn after the text object
+	glyphImages      []pendingGlyphImage // The images of the bitmap glyphs to draw
 }
 
 // Constants from Android's Matrix object:
@@ -151,12 +159,27 @@ func newPage(pdf *PDF, pageSize [2]float32, addToPDF bool) *Page {
 }
 
 // NewPageFromObject creates page object from PDFobj.
+// The program exits if the page MediaBox is malformed. Use NewPageFromObjectErr to handle the error.
 func NewPageFromObject(pdf *PDF, pageObj *PDFobj) *Page {
+	page, err := NewPageFromObjectErr(pdf, pageObj)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return page
+}
+
+// NewPageFromObjectErr creates page object from PDFobj.
+// Returns MalformedInputError if the page MediaBox is malformed.
+func NewPageFromObjectErr(pdf *PDF, pageObj *PDFobj) (*Page, error) {
+	size, err := pageObj.GetPageSizeErr()
+	if err != nil {
+		return nil, err
+	}
 	page := new(Page)
 	page.pdf = pdf
 	page.pageObj = page.removeComments(pageObj)
-	page.width = pageObj.GetPageSize()[0]
-	page.height = pageObj.GetPageSize()[1]
+	page.width = size[0]
+	page.height = size[1]
 	page.tm = [4]float32{1.0, 0.0, 0.0, 1.0}
 	page.buf = []byte{}
 	page.tm0 = formatFloat32(page.tm[0])
@@ -169,7 +192,7 @@ func NewPageFromObject(pdf *PDF, pageObj *PDFobj) *Page {
 		appendInteger(&page.buf, pageObj.gsNumber+1)
 		appendString(&page.buf, " gs\n")
 	}
-	return page
+	return page, nil
 }
 
 // removeComments removes object dictionary comments.
@@ -195,24 +218,62 @@ func (page *Page) removeComments(obj *PDFobj) *PDFobj {
 }
 
 // AddCoreFontResource adds core font to the PDF objects.
+// The program exits if the page resources are malformed. Use AddCoreFontResourceErr to handle the error.
 func (page *Page) AddCoreFontResource(coreFont *corefont.CoreFont, objects *[]*PDFobj) *Font {
+	font, err := page.AddCoreFontResourceErr(coreFont, objects)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// AddCoreFontResourceErr adds core font to the PDF objects.
+// Returns MalformedInputError if the page resources are malformed.
+func (page *Page) AddCoreFontResourceErr(coreFont *corefont.CoreFont, objects *[]*PDFobj) (*Font, error) {
 	return page.pageObj.addCoreFontResource(coreFont, objects)
 }
 
 // AddImageResource adds an image to the PDF objects.
+// The program exits if the page resources are malformed. Use AddImageResourceErr to handle the error.
 func (page *Page) AddImageResource(image *Image, objects *[]*PDFobj) {
-	page.pageObj.AddImageResource(image, objects)
+	if err := page.AddImageResourceErr(image, objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// AddImageResourceErr adds an image to the PDF objects.
+// Returns MalformedInputError if the page resources are malformed.
+func (page *Page) AddImageResourceErr(image *Image, objects *[]*PDFobj) error {
+	return page.pageObj.AddImageResourceErr(image, objects)
 }
 
 // AddFontResource adds font to the PDF objects.
+// The program exits if the page resources are malformed. Use AddFontResourceErr to handle the error.
 func (page *Page) AddFontResource(font *Font, objects *[]*PDFobj) {
-	page.pageObj.AddFontResource(font, objects)
+	if err := page.AddFontResourceErr(font, objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// AddFontResourceErr adds font to the PDF objects.
+// Returns MalformedInputError if the page resources are malformed.
+func (page *Page) AddFontResourceErr(font *Font, objects *[]*PDFobj) error {
+	return page.pageObj.AddFontResourceErr(font, objects)
 }
 
 // Complete completes adding content to the existing PDF.
+// The program exits if the /Contents entry is malformed. Use CompleteErr to handle the error.
 func (page *Page) Complete(objects *[]*PDFobj) {
+	if err := page.CompleteErr(objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// CompleteErr completes adding content to the existing PDF.
+// Returns MalformedInputError if the /Contents entry is malformed.
+func (page *Page) CompleteErr(objects *[]*PDFobj) error {
 	appendString(&page.buf, "Q\n")
-	page.pageObj.addContent(page.getContent(), objects)
+	return page.pageObj.addContent(page.getContent(), objects)
 }
 
 func (page *Page) getContent() []byte {
@@ -257,27 +318,31 @@ func (page *Page) DrawString(font1 *Font, font2 *Font, text string, x, y float32
 // The baseline of the leftmost character is at position (x, y) on the page.
 func (page *Page) DrawStringUsingColorMap(
 	font, fallbackFont *Font, text string, x, y float32, brush int32, colors map[string]int32) {
-	if font.isCoreFont || font.isCJK || fallbackFont == nil || fallbackFont.isCoreFont || fallbackFont.isCJK {
-		page.drawString(font, text, x, y, brush, colors)
-	} else {
-		activeFont := font
-		var buf strings.Builder
-		for _, ch := range text {
-			if activeFont.unicodeToGID[ch] == 0 {
-				page.drawString(activeFont, buf.String(), x, y, brush, colors)
-				x += activeFont.stringWidth(buf.String())
-				buf.Reset()
-				// Switch the active font
-				if activeFont == font {
-					activeFont = fallbackFont
-				} else {
-					activeFont = font
-				}
+	page.drawStringUsingColorMap(font.getFallbackChain(fallbackFont), text, -1, x, y, brush, colors)
+}
+
+// drawStringUsingColorMap draws the line of text in the visual order given by the
+// bidirectional algorithm. The level is the embedding level of the paragraph the line
+// is from or -1 to get it from the line. The right-to-left runs are drawn reversed.
+func (page *Page) drawStringUsingColorMap(
+	fonts []*Font, text string, level int, x, y float32, brush int32, colors map[string]int32) {
+	runs := getBidiRuns(text, level)
+	for i, run := range runs {
+		fontRuns := getFontRuns(fonts, run.text)
+		page.rightToLeft = run.level%2 == 1
+		if page.rightToLeft {
+			for a, b := 0, len(fontRuns)-1; a < b; a, b = a+1, b-1 {
+				fontRuns[a], fontRuns[b] = fontRuns[b], fontRuns[a]
+			}
+		}
+		for j, fontRun := range fontRuns {
+			page.drawString(fontRun.font, fontRun.text, x, y, brush, colors)
+			if i < len(runs)-1 || j < len(fontRuns)-1 {
+				x += fontRun.font.stringWidth(fontRun.text) + page.getSpacingWidth([]*Font{fontRun.font}, fontRun.text)
 			}
-			buf.WriteRune(ch)
 		}
-		page.drawString(activeFont, buf.String(), x, y, brush, colors)
 	}
+	page.rightToLeft = false
 }
 
 // drawString draws the text given by the specified string,
@@ -294,12 +359,25 @@ func (page *Page) drawString(font *Font, str string, x, y float32, brush int32,
 	}
 	appendString(&page.buf, "BT\n")
 	page.SetTextFont(font)
+	page.textX = 0.0
+	page.drawsGlyphImages = true
 
 	if page.renderingMode != 0 {
 		appendInteger(&page.buf, page.renderingMode)
 		appendString(&page.buf, " Tr\n")
 	}
 
+	// Tw applies only to the single byte code 32 - the text drawn with
+	// two byte codes gets the word spacing from the TJ adjustments.
+	if page.charSpacing != 0.0 {
+		appendFloat32(&page.buf, page.charSpacing)
+		appendString(&page.buf, " Tc\n")
+	}
+	if page.wordSpacing != 0.0 && font.isCoreFont {
+		appendFloat32(&page.buf, page.wordSpacing)
+		appendString(&page.buf, " Tw\n")
+	}
+
 	if font.skew15 &&
 		page.tm[0] == 1.0 &&
 		page.tm[1] == 0.0 &&
@@ -333,7 +411,7 @@ func (page *Page) drawString(font *Font, str string, x, y float32, brush int32,
 		appendString(&page.buf, " Tm\n")
 	}
 
-	if colors == nil {
+	if len(colors) == 0 {
 		page.SetBrushColor(brush)
 		appendString(&page.buf, "[<")
 		if font.isCoreFont {
@@ -345,11 +423,25 @@ func (page *Page) drawString(font *Font, str string, x, y float32, brush int32,
 	} else {
 		page.drawColoredString(font, str, brush, colors)
 	}
+	// The text state is part of the graphics state and outlives the text object.
+	if page.charSpacing != 0.0 {
+		appendString(&page.buf, "0 Tc\n")
+	}
+	if page.wordSpacing != 0.0 && font.isCoreFont {
+		appendString(&page.buf, "0 Tw\n")
+	}
 	appendString(&page.buf, "ET\n")
+	page.drawsGlyphImages = false
+	if len(page.glyphImages) > 0 {
+		page.drawGlyphImages(x, y)
+	}
 }
 
 func (page *Page) drawASCIIString(font *Font, text string) {
 	runes := []rune(text)
+	if page.rightToLeft {
+		runes = reverse(runes)
+	}
 	for i, c1 := range runes {
 		if c1 < font.firstChar || c1 > font.lastChar {
 			appendString(&page.buf, fmt.Sprintf("%02X", 0x20))
@@ -377,6 +469,9 @@ func (page *Page) drawASCIIString(font *Font, text string) {
 func (page *Page) drawUnicodeString(font *Font, text string) {
 	runes := []rune(text)
 	if font.isCJK {
+		if page.rightToLeft {
+			runes = reverse(runes)
+		}
 		for _, c1 := range runes {
 			if c1 == 0xFEFF { // BOM marker
 				continue
@@ -386,18 +481,82 @@ func (page *Page) drawUnicodeString(font *Font, text string) {
 			} else {
 				appendString(&page.buf, fmt.Sprintf("%04X", c1))
 			}
+			if c1 == 0x0020 {
+				page.appendWordSpacing(font)
+			}
 		}
+	} else if glyphs := font.shape(runes, page.rightToLeft); glyphs != nil {
+		if page.rightToLeft {
+			for i, j := 0, len(glyphs)-1; i < j; i, j = i+1, j-1 {
+				glyphs[i], glyphs[j] = glyphs[j], glyphs[i]
+			}
+		}
+		page.drawShapedString(font, glyphs, runes)
 	} else {
+		if page.rightToLeft {
+			runes = reverse(runes)
+		}
 		for _, c1 := range runes {
-			if c1 < font.firstChar || c1 > font.lastChar {
-				appendString(&page.buf, fmt.Sprintf("%04X", font.unicodeToGID[0x0020]))
-			} else {
-				appendString(&page.buf, fmt.Sprintf("%04X", font.unicodeToGID[c1]))
+			if isDefaultIgnorable(c1) && !font.hasGlyph(c1) {
+				continue
+			}
+			gid := font.unicodeToGID[0x0020]
+			if c1 >= font.firstChar && c1 <= font.lastChar {
+				gid = font.unicodeToGID[c1]
+			}
+			if font.subset != nil {
+				font.subset.addRune(font, gid, c1)
+			}
+			page.appendGlyph(font, gid, 0.0)
+			if c1 == 0x0020 {
+				page.appendWordSpacing(font)
 			}
 		}
 	}
 }
 
+// appendWordSpacing adds the word spacing after the space drawn with two byte code.
+func (page *Page) appendWordSpacing(font *Font) {
+	if page.wordSpacing != 0.0 {
+		appendString(&page.buf, ">")
+		appendFloat32(&page.buf, -page.wordSpacing*1000.0/font.size)
+		appendString(&page.buf, "<")
+		page.textX += page.wordSpacing
+	}
+}
+
+// setJustification sets the word and the character spacing that stretch the line of text
+// to the width. The extra space goes between the words or between the glyphs when
+// the line has no spaces. The spacing is used by the text drawn until clearJustification.
+func (page *Page) setJustification(fonts []*Font, text string, width float32) {
+	page.clearJustification()
+	slack := width - getTextWidth(fonts, text)
+	if slack <= 0.0 {
+		return
+	}
+	spaces, glyphs := countGlyphs(fonts, text)
+	if spaces > 0 {
+		page.wordSpacing = slack / float32(spaces)
+	} else if glyphs > 1 {
+		page.charSpacing = slack / float32(glyphs-1)
+	}
+}
+
+// clearJustification removes the word and the character spacing.
+func (page *Page) clearJustification() {
+	page.wordSpacing = 0.0
+	page.charSpacing = 0.0
+}
+
+// getSpacingWidth returns the width the word and the character spacing add to the text.
+func (page *Page) getSpacingWidth(fonts []*Font, text string) float32 {
+	if page.wordSpacing == 0.0 && page.charSpacing == 0.0 {
+		return 0.0
+	}
+	spaces, glyphs := countGlyphs(fonts, text)
+	return float32(spaces)*page.wordSpacing + float32(glyphs)*page.charSpacing
+}
+
 // SetGraphicsState sets the graphics state. Please see Example_31.
 // @param gs the graphics state to use.
 func (page *Page) SetGraphicsState(gs *GraphicsState) {
@@ -685,11 +844,20 @@ func (page *Page) FillRect(x, y, w, h float32) {
 }
 
 // DrawPath draws or fills the specified path using the current pen or brush.
+// The program exits if the path has less than 2 points. Use DrawPathErr to handle the error.
 // @param path the path.
 // @param operation specifies 'stroke' or 'fill' operation.
 func (page *Page) DrawPath(path []*Point, operation string) {
+	if err := page.DrawPathErr(path, operation); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// DrawPathErr draws or fills the specified path using the current pen or brush.
+// Returns an error if the path has less than 2 points.
+func (page *Page) DrawPathErr(path []*Point, operation string) error {
 	if len(path) < 2 {
-		log.Fatal("The Path object must contain at least 2 points.")
+		return fmt.Errorf("pdfjet: the path must contain at least 2 points")
 	}
 	point := path[0]
 	page.MoveTo(point.x, point.y)
@@ -711,6 +879,7 @@ func (page *Page) DrawPath(path []*Point, operation string) {
 	}
 	appendString(&page.buf, operation)
 	appendString(&page.buf, "\n")
+	return nil
 }
 
 // DrawCircle sdraws a circle on the page.
@@ -899,11 +1068,19 @@ func (page *Page) DrawPoint(p *Point) {
  *  @param mode the rendering mode.
  */
 func (page *Page) SetTextRenderingMode(mode int) {
-	if mode >= 0 && mode <= 7 {
-		page.renderingMode = mode
-	} else {
-		log.Fatal("Invalid text rendering mode: " + fmt.Sprint(mode))
+	if err := page.SetTextRenderingModeErr(mode); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// SetTextRenderingModeErr sets the text rendering mode.
+// Returns an error if the mode is not between 0 and 7.
+func (page *Page) SetTextRenderingModeErr(mode int) error {
+	if mode < 0 || mode > 7 {
+		return fmt.Errorf("pdfjet: invalid text rendering mode %d", mode)
 	}
+	page.renderingMode = mode
+	return nil
 }
 
 // SetTextDirection sets the text direction.
@@ -1122,38 +1299,55 @@ func (page *Page) appendPoint(point *Point) {
 	appendString(&page.buf, " ")
 }
 
-func (page *Page) drawWord(font *Font, buf *strings.Builder, brush int32, colors map[string]int32) {
-	if buf.Len() > 0 {
-		if brushColor, ok := colors[buf.String()]; ok {
-			page.SetBrushColor(brushColor)
-		} else {
-			page.SetBrushColor(brush)
-		}
-		appendString(&page.buf, "[<")
-		if font.isCoreFont {
-			page.drawASCIIString(font, buf.String())
-		} else {
-			page.drawUnicodeString(font, buf.String())
-		}
-		appendString(&page.buf, ">] TJ\n")
-		buf.Reset()
+func (page *Page) drawWord(font *Font, word string, brush int32, colors map[string]int32) {
+	if brushColor, ok := colors[word]; ok {
+		page.SetBrushColor(brushColor)
+	} else {
+		page.SetBrushColor(brush)
+	}
+	appendString(&page.buf, "[<")
+	if font.isCoreFont {
+		page.drawASCIIString(font, word)
+	} else {
+		page.drawUnicodeString(font, word)
 	}
+	appendString(&page.buf, ">] TJ\n")
 }
 
+// drawColoredString draws the words and the text between them separately
+// so that the words found in the color map are drawn in their color.
+// The words of the right-to-left text are drawn from the last to the first.
 func (page *Page) drawColoredString(font *Font, str string, brush int32, colors map[string]int32) {
+	var words []string
 	var buf1 strings.Builder
 	var buf2 strings.Builder
 	for _, ch := range str {
-		if unicode.IsLetter(ch) || unicode.IsDigit(ch) {
-			page.drawWord(font, &buf2, brush, colors)
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) || unicode.IsMark(ch) {
+			appendWord(&words, &buf2)
 			buf1.WriteRune(ch)
 		} else {
-			page.drawWord(font, &buf1, brush, colors)
+			appendWord(&words, &buf1)
 			buf2.WriteRune(ch)
 		}
 	}
-	page.drawWord(font, &buf1, brush, colors)
-	page.drawWord(font, &buf2, brush, colors)
+	appendWord(&words, &buf1)
+	appendWord(&words, &buf2)
+	if page.rightToLeft {
+		for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+			words[i], words[j] = words[j], words[i]
+		}
+	}
+	for _, word := range words {
+		page.drawWord(font, word, brush, colors)
+	}
+}
+
+// appendWord moves the text in the buffer to the words.
+func appendWord(words *[]string, buf *strings.Builder) {
+	if buf.Len() > 0 {
+		*words = append(*words, buf.String())
+		buf.Reset()
+	}
 }
 
 func (page *Page) setStructElementsPageObjNumber(pageObjNumber int) {
@@ -1204,6 +1398,9 @@ func (page *Page) AddAnnotation(annotation *Annotation) {
 	if page.pdf.compliance == compliance.PDF_UA {
 		element := NewStructElem()
 		element.structure = "Link"
+		if annotation.signature != nil || annotation.widget != nil {
+			element.structure = "Form"
+		}
 		element.language = annotation.language
 		element.actualText = *annotation.actualText
 		element.altDescription = *annotation.altDescription
@@ -1370,6 +1567,7 @@ func (page *Page) SetTextScaling(scaling float32) {
 }
 
 func (page *Page) SetTextRise(rise float32) {
+	page.textRise = rise
 	appendFloat32(&page.buf, rise)
 	appendString(&page.buf, " Ts\n")
 }
@@ -26,7 +26,11 @@ SOFTWARE.
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"sort"
@@ -72,6 +76,27 @@ type PDF struct {
 	extGState             string
 	uuid                  string
 	prevPage              *Page
+	incremental           bool           // Append-only save of existing PDF
+	baseChecksums         map[int]uint64 // Checksums of the objects read from the original PDF
+	baseSize              int            // The /Size of the original PDF
+	baseStartXRef         int            // The startxref offset of the original PDF
+	baseXRefStream        bool           // The original PDF uses cross-reference stream
+	baseRoot              int            // The /Root object number of the original PDF
+	baseInfo              int            // The /Info object number of the original PDF
+	baseID                string         // The first part of the original file identifier
+	updateOffsets         map[int]int    // The offsets of the changed and new objects
+	prologue              [][]byte       // The bytes written by the constructor
+	signature             *Signature
+	formFields            []*widget
+	encryption            *encryption
+	outlinesObjNumber     int // The outlines of the merged documents
+	namesObjNumber        int // The named destinations tree of the merged documents
+	destsObjNumber        int // The named destinations dictionary of the merged documents
+	acroFormObjNumber     int // The interactive form of the objects added by AddObjects
+	objStm                *objectStreams
+	streaming             *streaming
+	fontSubsets           []*fontSubset // The fonts written by Complete
+	noFontSubsetting      bool
 }
 
 // NewPDF the constructor.
@@ -113,6 +138,16 @@ type PDF struct {
  *  @param compliance must be: compliance.PDF_UA or compliance.PDF_A_1A to compliance.PDF_A_3B
  */
 func NewPDF(w *bufio.Writer) *PDF {
+	pdf := newPDF(w)
+
+	header := []byte("%PDF-1.5\n%\xF2\xF3\xF4\xF5\xF6\n")
+	pdf.appendByteArray(header)
+	pdf.prologue = [][]byte{header}
+
+	return pdf
+}
+
+func newPDF(w *bufio.Writer) *PDF {
 	pdf := new(PDF)
 	pdf.writer = w
 	pdf.producer = "PDFjet v8.0.4"
@@ -132,38 +167,199 @@ func NewPDF(w *bufio.Writer) *PDF {
 
 	pdf.states = make(map[string]int)
 
-	pdf.appendString("%PDF-1.5\n")
-	pdf.appendString("%")
-	pdf.appendByte(0xF2)
-	pdf.appendByte(0xF3)
-	pdf.appendByte(0xF4)
-	pdf.appendByte(0xF5)
-	pdf.appendByte(0xF6)
-	pdf.appendString("\n")
+	return pdf
+}
 
+// NewIncrementalPDF creates PDF that updates the existing PDF document in buf.
+// The original bytes are written to w untouched and Complete appends only
+// the changed and new objects followed by new xref section and trailer.
+// Use Read with the same buf to get the objects, modify them and then call AddObjects and Complete.
+// Only the objects in the slice returned by Read are saved - use NewFontStream2 and NewImage2
+// to add fonts and images. Streams of existing objects must be replaced using SetStream.
+func NewIncrementalPDF(w *bufio.Writer, buf []byte) *PDF {
+	pdf := newPDF(w)
+	pdf.incremental = true
+	pdf.appendByteArray(buf)
+	pdf.prologue = [][]byte{buf}
+	if len(buf) > 0 && buf[len(buf)-1] != '\n' && buf[len(buf)-1] != '\r' {
+		eol := []byte("\n")
+		pdf.appendByteArray(eol)
+		pdf.prologue = append(pdf.prologue, eol)
+	}
 	return pdf
 }
 
+// hasObjects returns true if any objects were written after the constructor.
+func (pdf *PDF) hasObjects() bool {
+	length := 0
+	for _, buf := range pdf.prologue {
+		length += len(buf)
+	}
+	return pdf.byteCount != length
+}
+
+// SetEncryption encrypts the PDF using AES-256 - the standard security handler revision 6.
+// All strings and streams are encrypted. The permissions are set using the constants
+// from the permission package combined with the | operator.
+// The owner password gives full access - when empty random password is used.
+// Must be called right after the PDF is created - before adding any pages, fonts or images.
+func (pdf *PDF) SetEncryption(userPassword, ownerPassword string, permissions int) error {
+	if pdf.hasObjects() {
+		return errors.New("pdfjet: SetEncryption must be called before adding any objects")
+	}
+	if pdf.incremental {
+		return unsupported("encryption of incremental update")
+	}
+	enc, err := newEncryption(userPassword, ownerPassword, permissions)
+	if err != nil {
+		return err
+	}
+	pdf.encryption = enc
+	return nil
+}
+
+// encrypt returns the encrypted string or stream data.
+// Returns the data unchanged when the PDF is not encrypted.
+func (pdf *PDF) encrypt(data []byte) []byte {
+	if pdf.encryption == nil {
+		return data
+	}
+	return pdf.encryption.encrypt(data)
+}
+
+// appendLiteral appends literal string.
+// Encrypted strings are written as hex strings.
+func (pdf *PDF) appendLiteral(text string) {
+	if pdf.encryption == nil {
+		pdf.appendString("(")
+		pdf.appendString(text)
+		pdf.appendString(")")
+		return
+	}
+	pdf.appendString("<")
+	pdf.appendString(hex.EncodeToString(pdf.encryption.encrypt(unescapeLiteral(text))))
+	pdf.appendString(">")
+}
+
+// appendHexString appends hex string - for example the result of encodeToHex.
+func (pdf *PDF) appendHexString(text string) {
+	pdf.appendString("<")
+	if pdf.encryption == nil {
+		pdf.appendString(text)
+	} else {
+		data, _ := hex.DecodeString(text)
+		pdf.appendString(hex.EncodeToString(pdf.encryption.encrypt(data)))
+	}
+	pdf.appendString(">")
+}
+
+func (pdf *PDF) addEncryptObject() int {
+	enc := pdf.encryption
+	pdf.newobj()
+	pdf.appendString("<<\n")
+	pdf.appendString("/Filter /Standard\n")
+	pdf.appendString("/V 5\n")
+	pdf.appendString("/R 6\n")
+	pdf.appendString("/Length 256\n")
+	pdf.appendString("/CF << /StdCF << /AuthEvent /DocOpen /CFM /AESV3 /Length 32 >> >>\n")
+	pdf.appendString("/StmF /StdCF\n")
+	pdf.appendString("/StrF /StdCF\n")
+	pdf.appendString("/O <")
+	pdf.appendString(hex.EncodeToString(enc.o))
+	pdf.appendString(">\n")
+	pdf.appendString("/U <")
+	pdf.appendString(hex.EncodeToString(enc.u))
+	pdf.appendString(">\n")
+	pdf.appendString("/OE <")
+	pdf.appendString(hex.EncodeToString(enc.oe))
+	pdf.appendString(">\n")
+	pdf.appendString("/UE <")
+	pdf.appendString(hex.EncodeToString(enc.ue))
+	pdf.appendString(">\n")
+	pdf.appendString("/P ")
+	pdf.appendInteger(int(enc.p))
+	pdf.appendString("\n")
+	pdf.appendString("/Perms <")
+	pdf.appendString(hex.EncodeToString(enc.perms))
+	pdf.appendString(">\n")
+	pdf.appendString("/EncryptMetadata true\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+	return pdf.getObjNumber()
+}
+
+// SetSignature signs the PDF with the specified signature when it is completed.
+// Must be called right after the PDF is created - before adding any pages, fonts or images.
+// To sign existing PDF create it using NewIncrementalPDF.
+func (pdf *PDF) SetSignature(sig *Signature) error {
+	if pdf.hasObjects() {
+		return errors.New("pdfjet: SetSignature must be called before adding any objects")
+	}
+	if pdf.streaming != nil {
+		return unsupported("signature in streaming mode")
+	}
+	if _, _, err := sig.getAlgorithms(); err != nil {
+		return err
+	}
+	sig.digest = sig.hash.New()
+	for _, buf := range pdf.prologue {
+		sig.digest.Write(buf)
+	}
+	// Digest everything written from now on until the signature value.
+	sig.writer = pdf.writer
+	pdf.writer = bufio.NewWriter(io.MultiWriter(sig.writer, sig.digest))
+	pdf.signature = sig
+	return nil
+}
+
 func (pdf *PDF) SetCompliance(compliance int) {
 	pdf.compliance = compliance
 }
 
+// NewPDFFile creates PDF that is written to the file at the specified path.
+// The program exits if the file cannot be created. Use NewPDFFileErr to handle the error.
 func NewPDFFile(filePath string) *PDF {
-	file, err := os.Create(filePath)
+	pdf, err := NewPDFFileErr(filePath)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return NewPDF(bufio.NewWriter(file))
+	return pdf
+}
+
+// NewPDFFileErr creates PDF that is written to the file at the specified path.
+// Returns IOError if the file cannot be created.
+func NewPDFFileErr(filePath string) (*PDF, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, &IOError{Op: "create", Path: filePath, Err: err}
+	}
+	return NewPDF(bufio.NewWriter(file)), nil
 }
 
 func (pdf *PDF) newobj() {
 	pdf.objOffsets = append(pdf.objOffsets, pdf.byteCount)
+	pdf.startCapture(len(pdf.objOffsets))
 	pdf.appendInteger(len(pdf.objOffsets))
 	pdf.appendString(" 0 obj\n")
 }
 
+// reserveObjNumber returns the number of object that is written later using newobjReserved.
+func (pdf *PDF) reserveObjNumber() int {
+	pdf.objOffsets = append(pdf.objOffsets, 0)
+	return len(pdf.objOffsets)
+}
+
+// newobjReserved starts the object with number returned by reserveObjNumber.
+func (pdf *PDF) newobjReserved(number int) {
+	pdf.objOffsets[number-1] = pdf.byteCount
+	pdf.startCapture(number)
+	pdf.appendInteger(number)
+	pdf.appendString(" 0 obj\n")
+}
+
 func (pdf *PDF) endobj() {
 	pdf.appendString("endobj\n")
+	pdf.endCapture()
 }
 
 func (pdf *PDF) getObjNumber() int {
@@ -272,7 +468,7 @@ func (pdf *PDF) addMetadataObject(notice string, fontMetadataObject bool) int {
 	sb.WriteString("</x:xmpmeta>\n")
 	sb.WriteString("<?xpacket end=\"w\"?>")
 
-	xml := []byte(sb.String())
+	xml := pdf.encrypt([]byte(sb.String()))
 	// This is the metadata object
 	pdf.newobj()
 	pdf.appendByteArray(token.BeginDictionary)
@@ -291,18 +487,19 @@ func (pdf *PDF) addMetadataObject(notice string, fontMetadataObject bool) int {
 }
 
 func (pdf *PDF) addOutputIntentObject() int {
+	profile := pdf.encrypt(ICCBlackScaledProfile)
 	pdf.newobj()
 	pdf.appendByteArray(token.BeginDictionary)
 	pdf.appendString("/N 3\n")
 
 	pdf.appendByteArray(token.Length)
-	pdf.appendInteger(len(ICCBlackScaledProfile))
+	pdf.appendInteger(len(profile))
 	pdf.appendByteArray(token.Newline)
 
 	pdf.appendString("/Filter /FlateDecode\n")
 	pdf.appendByteArray(token.EndDictionary)
 	pdf.appendByteArray(token.Stream)
-	pdf.appendByteArray(ICCBlackScaledProfile)
+	pdf.appendByteArray(profile)
 	pdf.appendByteArray(token.Endstream)
 	pdf.endobj()
 
@@ -311,9 +508,15 @@ func (pdf *PDF) addOutputIntentObject() int {
 	pdf.appendByteArray(token.BeginDictionary)
 	pdf.appendString("/Type /OutputIntent\n")
 	pdf.appendString("/S /GTS_PDFA1\n")
-	pdf.appendString("/OutputCondition (sRGB IEC61966-2.1)\n")
-	pdf.appendString("/OutputConditionIdentifier (sRGB IEC61966-2.1)\n")
-	pdf.appendString("/Info (sRGB IEC61966-2.1)\n")
+	pdf.appendString("/OutputCondition ")
+	pdf.appendLiteral("sRGB IEC61966-2.1")
+	pdf.appendString("\n")
+	pdf.appendString("/OutputConditionIdentifier ")
+	pdf.appendLiteral("sRGB IEC61966-2.1")
+	pdf.appendString("\n")
+	pdf.appendString("/Info ")
+	pdf.appendLiteral("sRGB IEC61966-2.1")
+	pdf.appendString("\n")
 	pdf.appendString("/DestOutputProfile ")
 	pdf.appendInteger(pdf.getObjNumber() - 1)
 	pdf.appendByteArray(token.ObjRef)
@@ -325,6 +528,12 @@ func (pdf *PDF) addOutputIntentObject() int {
 
 func (pdf *PDF) addResourcesObject() int {
 	pdf.newobj()
+	pdf.appendResources()
+	pdf.endobj()
+	return pdf.getObjNumber()
+}
+
+func (pdf *PDF) appendResources() {
 	pdf.appendByteArray(token.BeginDictionary)
 	if pdf.extGState != "" {
 		pdf.appendString(pdf.extGState)
@@ -386,8 +595,6 @@ func (pdf *PDF) addResourcesObject() int {
 		pdf.appendByteArray(token.EndDictionary)
 	}
 	pdf.appendByteArray(token.EndDictionary)
-	pdf.endobj()
-	return pdf.getObjNumber()
 }
 
 func (pdf *PDF) addPagesObject() int {
@@ -396,13 +603,7 @@ func (pdf *PDF) addPagesObject() int {
 	pdf.appendString("/Type /Pages\n")
 	pdf.appendString("/Kids [\n")
 	for _, page := range pdf.pages {
-		if pdf.compliance == compliance.PDF_UA ||
-			pdf.compliance == compliance.PDF_A_1A ||
-			pdf.compliance == compliance.PDF_A_1B ||
-			pdf.compliance == compliance.PDF_A_2A ||
-			pdf.compliance == compliance.PDF_A_2B ||
-			pdf.compliance == compliance.PDF_A_3A ||
-			pdf.compliance == compliance.PDF_A_3B {
+		if pdf.isTagged() {
 			page.setStructElementsPageObjNumber(page.objNumber)
 		}
 		pdf.appendInteger(page.objNumber)
@@ -421,24 +622,24 @@ func (pdf *PDF) addInfoObject() int {
 	// Add the info object
 	pdf.newobj()
 	pdf.appendString("<<\n")
-	pdf.appendString("/Title (")
-	pdf.appendString(pdf.title)
-	pdf.appendString(")\n")
-	pdf.appendString("/Author (")
-	pdf.appendString(pdf.author)
-	pdf.appendString(")\n")
-	pdf.appendString("/Subject (")
-	pdf.appendString(pdf.subject)
-	pdf.appendString(")\n")
-	pdf.appendString("/Producer (")
-	pdf.appendString(pdf.producer)
-	pdf.appendString(")\n")
-	pdf.appendString("/Creator (")
-	pdf.appendString(pdf.creator)
-	pdf.appendString(")\n")
-	pdf.appendString("/CreationDate (D:")
-	pdf.appendString(pdf.creationDate)
-	pdf.appendString("-05'00')\n")
+	pdf.appendString("/Title ")
+	pdf.appendLiteral(pdf.title)
+	pdf.appendString("\n")
+	pdf.appendString("/Author ")
+	pdf.appendLiteral(pdf.author)
+	pdf.appendString("\n")
+	pdf.appendString("/Subject ")
+	pdf.appendLiteral(pdf.subject)
+	pdf.appendString("\n")
+	pdf.appendString("/Producer ")
+	pdf.appendLiteral(pdf.producer)
+	pdf.appendString("\n")
+	pdf.appendString("/Creator ")
+	pdf.appendLiteral(pdf.creator)
+	pdf.appendString("\n")
+	pdf.appendString("/CreationDate ")
+	pdf.appendLiteral("D:" + pdf.creationDate + "-05'00'")
+	pdf.appendString("\n")
 	pdf.appendString(">>\n")
 	pdf.endobj()
 	return pdf.getObjNumber()
@@ -505,17 +706,17 @@ func (pdf *PDF) addStructElementObjects() {
 				pdf.appendString("/K ")
 				pdf.appendInteger(element.mcid)
 			}
-			pdf.appendString("\n/Lang (")
+			pdf.appendString("\n/Lang ")
 			if element.language != "" {
-				pdf.appendString(element.language)
+				pdf.appendLiteral(element.language)
 			} else {
-				pdf.appendString(pdf.language)
+				pdf.appendLiteral(pdf.language)
 			}
-			pdf.appendString(")\n/Alt <")
-			pdf.appendString(encodeToHex(element.altDescription))
-			pdf.appendString(">\n/ActualText <")
-			pdf.appendString(encodeToHex(element.actualText))
-			pdf.appendString(">\n>>\n")
+			pdf.appendString("\n/Alt ")
+			pdf.appendHexString(encodeToHex(element.altDescription))
+			pdf.appendString("\n/ActualText ")
+			pdf.appendHexString(encodeToHex(element.actualText))
+			pdf.appendString("\n>>\n")
 			pdf.endobj()
 		}
 	}
@@ -579,16 +780,10 @@ func (pdf *PDF) addRootObject(structTreeRootObjNumber, outlineDictNumber int) in
 	pdf.appendString("<<\n")
 	pdf.appendString("/Type /Catalog\n")
 
-	if pdf.compliance == compliance.PDF_UA ||
-		pdf.compliance == compliance.PDF_A_1A ||
-		pdf.compliance == compliance.PDF_A_1B ||
-		pdf.compliance == compliance.PDF_A_2A ||
-		pdf.compliance == compliance.PDF_A_2B ||
-		pdf.compliance == compliance.PDF_A_3A ||
-		pdf.compliance == compliance.PDF_A_3B {
-		pdf.appendString("/Lang (")
-		pdf.appendString(pdf.language)
-		pdf.appendString(")\n")
+	if pdf.isTagged() {
+		pdf.appendString("/Lang ")
+		pdf.appendLiteral(pdf.language)
+		pdf.appendString("\n")
 
 		pdf.appendString("/StructTreeRoot ")
 		pdf.appendInteger(structTreeRootObjNumber)
@@ -612,17 +807,17 @@ func (pdf *PDF) addRootObject(structTreeRootObjNumber, outlineDictNumber int) in
 
 	pdf.addOCProperties()
 
+	if pdf.encryption != nil {
+		// AES-256 encryption is PDF 2.0 feature
+		pdf.appendString("/Version /1.7\n")
+		pdf.appendString("/Extensions << /ADBE << /BaseVersion /1.7 /ExtensionLevel 8 >> >>\n")
+	}
+
 	pdf.appendString("/Pages ")
 	pdf.appendInteger(pdf.pagesObjNumber)
 	pdf.appendString(" 0 R\n")
 
-	if pdf.compliance == compliance.PDF_UA ||
-		pdf.compliance == compliance.PDF_A_1A ||
-		pdf.compliance == compliance.PDF_A_1B ||
-		pdf.compliance == compliance.PDF_A_2A ||
-		pdf.compliance == compliance.PDF_A_2B ||
-		pdf.compliance == compliance.PDF_A_3A ||
-		pdf.compliance == compliance.PDF_A_3B {
+	if pdf.isTagged() {
 		pdf.appendString("/Metadata ")
 		pdf.appendInteger(pdf.metadataObjNumber)
 		pdf.appendString(" 0 R\n")
@@ -632,12 +827,32 @@ func (pdf *PDF) addRootObject(structTreeRootObjNumber, outlineDictNumber int) in
 		pdf.appendString(" 0 R]\n")
 	}
 
+	if (pdf.signature != nil && pdf.signature.annotation != nil) || len(pdf.formFields) > 0 {
+		pdf.appendAcroForm()
+	} else if pdf.acroFormObjNumber > 0 {
+		pdf.appendString("/AcroForm ")
+		pdf.appendInteger(pdf.acroFormObjNumber)
+		pdf.appendString(" 0 R\n")
+	}
+
 	if outlineDictNumber > 0 {
 		pdf.appendString("/Outlines ")
 		pdf.appendInteger(outlineDictNumber)
 		pdf.appendString(" 0 R\n")
 	}
 
+	if pdf.namesObjNumber > 0 {
+		pdf.appendString("/Names << /Dests ")
+		pdf.appendInteger(pdf.namesObjNumber)
+		pdf.appendString(" 0 R >>\n")
+	}
+
+	if pdf.destsObjNumber > 0 {
+		pdf.appendString("/Dests ")
+		pdf.appendInteger(pdf.destsObjNumber)
+		pdf.appendString(" 0 R\n")
+	}
+
 	pdf.appendString(">>\n")
 	pdf.endobj()
 	return pdf.getObjNumber()
@@ -682,70 +897,69 @@ func (pdf *PDF) addAllPages(resObjNumber int) {
 		// Page object
 		pdf.newobj()
 		page.objNumber = pdf.getObjNumber()
-		pdf.appendString("<<\n")
-		pdf.appendString("/Type /Page\n")
-		pdf.appendString("/Parent ")
-		pdf.appendInteger(pdf.pagesObjNumber)
-		pdf.appendString(" 0 R\n")
-		pdf.appendString("/MediaBox [0 0 ")
-		pdf.appendFloat32(page.width)
-		pdf.appendString(" ")
-		pdf.appendFloat32(page.height)
-		pdf.appendString("]\n")
+		pdf.appendPageDictionary(page, pdf.pagesObjNumber, resObjNumber, i)
+		pdf.endobj()
+	}
+}
 
-		if page.cropBox != nil {
-			pdf.addPageBox("CropBox", page, page.cropBox)
-		}
-		if page.bleedBox != nil {
-			pdf.addPageBox("BleedBox", page, page.bleedBox)
-		}
-		if page.trimBox != nil {
-			pdf.addPageBox("TrimBox", page, page.trimBox)
-		}
-		if page.artBox != nil {
-			pdf.addPageBox("ArtBox", page, page.artBox)
-		}
+// appendPageDictionary appends the dictionary of the page object.
+func (pdf *PDF) appendPageDictionary(page *Page, parent, resObjNumber, structParents int) {
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /Page\n")
+	pdf.appendString("/Parent ")
+	pdf.appendInteger(parent)
+	pdf.appendString(" 0 R\n")
+	pdf.appendString("/MediaBox [0 0 ")
+	pdf.appendFloat32(page.width)
+	pdf.appendString(" ")
+	pdf.appendFloat32(page.height)
+	pdf.appendString("]\n")
 
-		pdf.appendString("/Resources ")
-		pdf.appendInteger(resObjNumber)
-		pdf.appendString(" 0 R\n")
+	if page.cropBox != nil {
+		pdf.addPageBox("CropBox", page, page.cropBox)
+	}
+	if page.bleedBox != nil {
+		pdf.addPageBox("BleedBox", page, page.bleedBox)
+	}
+	if page.trimBox != nil {
+		pdf.addPageBox("TrimBox", page, page.trimBox)
+	}
+	if page.artBox != nil {
+		pdf.addPageBox("ArtBox", page, page.artBox)
+	}
 
-		pdf.appendString("/Contents [ ")
-		for _, n := range page.contents {
-			pdf.appendInteger(n)
-			pdf.appendString(" 0 R ")
-		}
-		pdf.appendString("]\n")
+	pdf.appendString("/Resources ")
+	pdf.appendInteger(resObjNumber)
+	pdf.appendString(" 0 R\n")
 
-		if len(page.annots) > 0 {
-			pdf.appendString("/Annots [ ")
-			for _, annot := range page.annots {
-				pdf.appendInteger(annot.objNumber)
-				pdf.appendString(" 0 R ")
-			}
-			pdf.appendString("]\n")
-		}
+	pdf.appendString("/Contents [ ")
+	for _, n := range page.contents {
+		pdf.appendInteger(n)
+		pdf.appendString(" 0 R ")
+	}
+	pdf.appendString("]\n")
 
-		if pdf.compliance == compliance.PDF_UA ||
-			pdf.compliance == compliance.PDF_A_1A ||
-			pdf.compliance == compliance.PDF_A_1B ||
-			pdf.compliance == compliance.PDF_A_2A ||
-			pdf.compliance == compliance.PDF_A_2B ||
-			pdf.compliance == compliance.PDF_A_3A ||
-			pdf.compliance == compliance.PDF_A_3B {
-			pdf.appendString("/Tabs /S\n")
-			pdf.appendString("/StructParents ")
-			pdf.appendInteger(i)
-			pdf.appendString("\n")
+	if len(page.annots) > 0 {
+		pdf.appendString("/Annots [ ")
+		for _, annot := range page.annots {
+			pdf.appendInteger(annot.objNumber)
+			pdf.appendString(" 0 R ")
 		}
+		pdf.appendString("]\n")
+	}
 
-		pdf.appendString(">>\n")
-		pdf.endobj()
+	if pdf.isTagged() {
+		pdf.appendString("/Tabs /S\n")
+		pdf.appendString("/StructParents ")
+		pdf.appendInteger(structParents)
+		pdf.appendString("\n")
 	}
+
+	pdf.appendString(">>\n")
 }
 
 func (pdf *PDF) addPageContent(page *Page) {
-	compressed := compressor.Deflate(page.buf)
+	compressed := pdf.encrypt(compressor.Deflate(page.buf))
 	page.buf = nil // Release the page content memory!
 
 	pdf.newobj()
@@ -806,18 +1020,33 @@ func (pdf *PDF) addAnnotationObject(annot *Annotation, index int) int {
 	pdf.appendString("/Type /Annot\n")
 	if annot.fileAttachment != nil {
 		pdf.appendString("/Subtype /FileAttachment\n")
-		pdf.appendString("/T (")
-		pdf.appendString(annot.fileAttachment.title)
-		pdf.appendString(")\n")
-		pdf.appendString("/Contents (")
-		pdf.appendString(annot.fileAttachment.contents)
-		pdf.appendString(")\n")
+		pdf.appendString("/T ")
+		pdf.appendLiteral(annot.fileAttachment.title)
+		pdf.appendString("\n")
+		pdf.appendString("/Contents ")
+		pdf.appendLiteral(annot.fileAttachment.contents)
+		pdf.appendString("\n")
 		pdf.appendString("/FS ")
 		pdf.appendInteger(annot.fileAttachment.embeddedFile.objNumber)
 		pdf.appendString(" 0 R\n")
 		pdf.appendString("/Name /")
 		pdf.appendString(annot.fileAttachment.icon)
 		pdf.appendString("\n")
+	} else if annot.signature != nil {
+		pdf.appendString("/Subtype /Widget\n")
+		pdf.appendString("/FT /Sig\n")
+		pdf.appendString("/T ")
+		pdf.appendHexString(encodeToHex(annot.signature.fieldName))
+		pdf.appendString("\n")
+		pdf.appendString("/V ")
+		pdf.appendInteger(annot.signature.objNumber)
+		pdf.appendString(" 0 R\n")
+		pdf.appendString("/F 132\n") // Print and Locked
+	} else if annot.widget != nil {
+		if annot.widget.parent == nil {
+			annot.widget.objNumber = annot.objNumber
+		}
+		pdf.appendWidget(annot.widget)
 	} else {
 		pdf.appendString("/Subtype /Link\n")
 	}
@@ -835,10 +1064,16 @@ func (pdf *PDF) addAnnotationObject(annot *Annotation, index int) int {
 		pdf.appendString("/F 4\n")
 		pdf.appendString("/A <<\n")
 		pdf.appendString("/S /URI\n")
-		pdf.appendString("/URI (")
-		pdf.appendString(*annot.uri)
-		pdf.appendString(")\n")
+		pdf.appendString("/URI ")
+		pdf.appendLiteral(*annot.uri)
+		pdf.appendString("\n")
 		pdf.appendString(">>\n")
+	} else if annot.key != nil && pdf.streaming != nil {
+		// The destination may be on page that is not written yet.
+		pdf.appendString("/F 4\n")
+		pdf.appendString("/Dest ")
+		pdf.appendLiteral(*annot.key)
+		pdf.appendString("\n")
 	} else if annot.key != nil {
 		destination := pdf.destinations[*annot.key]
 		if destination != nil {
@@ -911,7 +1146,8 @@ func (pdf *PDF) addOCProperties() {
 		pdf.appendString(" ] >>\n")
 		pdf.appendString("]\n")
 
-		pdf.appendString("/Order [[ ()")
+		pdf.appendString("/Order [[ ")
+		pdf.appendLiteral("")
 		pdf.appendString(buf.String())
 		pdf.appendString(" ]]\n")
 
@@ -922,6 +1158,13 @@ func (pdf *PDF) addOCProperties() {
 
 // AddPage adds page to the PDF.
 func (pdf *PDF) AddPage(page *Page) {
+	if pdf.streaming != nil {
+		if pdf.prevPage != nil {
+			pdf.flushPage(pdf.prevPage)
+		}
+		pdf.prevPage = page
+		return
+	}
 	pdf.pages = append(pdf.pages, page)
 	if pdf.prevPage != nil {
 		pdf.addPageContent(pdf.prevPage)
@@ -930,41 +1173,59 @@ func (pdf *PDF) AddPage(page *Page) {
 }
 
 // Complete writes the PDF to the bufio.Writer and calls the Flush method.
+// The program exits if the writer fails. Use CompleteErr to handle the error.
 func (pdf *PDF) Complete() {
-	if pdf.prevPage != nil {
+	if err := pdf.CompleteErr(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// CompleteErr writes the PDF to the bufio.Writer and calls the Flush method.
+// Returns IOError if writing to the underlying writer fails.
+func (pdf *PDF) CompleteErr() error {
+	if pdf.incremental {
+		return pdf.completeUpdate()
+	}
+	if pdf.streaming != nil && pdf.prevPage != nil {
+		pdf.flushPage(pdf.prevPage)
+	} else if pdf.prevPage != nil {
 		pdf.addPageContent(pdf.prevPage)
 	}
-	if pdf.compliance == compliance.PDF_UA ||
-		pdf.compliance == compliance.PDF_A_1A ||
-		pdf.compliance == compliance.PDF_A_1B ||
-		pdf.compliance == compliance.PDF_A_2A ||
-		pdf.compliance == compliance.PDF_A_2B ||
-		pdf.compliance == compliance.PDF_A_3A ||
-		pdf.compliance == compliance.PDF_A_3B {
+	if pdf.isTagged() {
 		pdf.metadataObjNumber = pdf.addMetadataObject("", false)
 		pdf.outputIntentObjNumber = pdf.addOutputIntentObject()
 	}
 
-	if pdf.pagesObjNumber == 0 {
+	if pdf.signature != nil {
+		if pdf.signature.annotation == nil && len(pdf.pages) > 0 {
+			pdf.signature.DrawOn(pdf.pages[0])
+		}
+		pdf.addSignatureObject()
+	}
+
+	pdf.addFontSubsets()
+
+	if pdf.streaming != nil {
+		pdf.completeStreamedPages()
+	} else if pdf.pagesObjNumber == 0 {
 		pdf.addAllPages(pdf.addResourcesObject())
 		pdf.addPagesObject()
 	}
+	pdf.addFormFieldObjects()
 
 	structTreeRootObjNumber := 0
-	if pdf.compliance == compliance.PDF_UA ||
-		pdf.compliance == compliance.PDF_A_1A ||
-		pdf.compliance == compliance.PDF_A_1B ||
-		pdf.compliance == compliance.PDF_A_2A ||
-		pdf.compliance == compliance.PDF_A_2B ||
-		pdf.compliance == compliance.PDF_A_3A ||
-		pdf.compliance == compliance.PDF_A_3B {
-		pdf.addStructElementObjects()
-		structTreeRootObjNumber = pdf.addStructTreeRootObject()
-		pdf.addNumsParentTree()
-		pdf.addStructDocumentObject(structTreeRootObjNumber)
-	}
-
-	var outlineDictNum int = 0
+	if pdf.isTagged() {
+		if pdf.streaming != nil {
+			structTreeRootObjNumber = pdf.addStreamedStructTree()
+		} else {
+			pdf.addStructElementObjects()
+			structTreeRootObjNumber = pdf.addStructTreeRootObject()
+			pdf.addNumsParentTree()
+			pdf.addStructDocumentObject(structTreeRootObjNumber)
+		}
+	}
+
+	var outlineDictNum int = pdf.outlinesObjNumber
 	if pdf.toc != nil && pdf.toc.getChildren() != nil {
 		list := pdf.toc.toArrayList()
 		outlineDictNum = pdf.addOutlineDict(pdf.toc)
@@ -972,53 +1233,418 @@ func (pdf *PDF) Complete() {
 			pdf.addOutlineItem(outlineDictNum, i, list[i])
 		}
 	}
+	if pdf.streaming != nil {
+		pdf.addStreamedDestinations()
+	}
 
+	encryptObjNumber := 0
+	if pdf.encryption != nil {
+		encryptObjNumber = pdf.addEncryptObject()
+	}
 	infoObjNumber := pdf.addInfoObject()
 	rootObjNumber := pdf.addRootObject(structTreeRootObjNumber, outlineDictNum)
 
+	var startxref int
+	if pdf.usesXRefStream() {
+		startxref = pdf.addXRefStream(infoObjNumber, rootObjNumber, encryptObjNumber)
+	} else {
+		startxref = pdf.byteCount
+
+		// Create the xref table
+		pdf.appendString("xref\n")
+		pdf.appendString("0 ")
+		pdf.appendInteger(rootObjNumber + 1)
+		pdf.appendString("\n")
+		pdf.appendString("0000000000 65535 f \n")
+		for _, offset := range pdf.objOffsets {
+			pdf.appendXRefEntry(offset)
+		}
+		pdf.appendString("trailer\n")
+		pdf.appendString("<<\n")
+		pdf.appendString("/Size ")
+		pdf.appendInteger(rootObjNumber + 1)
+		pdf.appendString("\n")
+
+		pdf.appendString("/ID[<")
+		pdf.appendString(pdf.uuid)
+		pdf.appendString("><")
+		pdf.appendString(pdf.uuid)
+		pdf.appendString(">]\n")
+
+		pdf.appendString("/Info ")
+		pdf.appendInteger(infoObjNumber)
+		pdf.appendString(" 0 R\n")
+
+		pdf.appendString("/Root ")
+		pdf.appendInteger(rootObjNumber)
+		pdf.appendString(" 0 R\n")
+
+		if encryptObjNumber != 0 {
+			pdf.appendString("/Encrypt ")
+			pdf.appendInteger(encryptObjNumber)
+			pdf.appendString(" 0 R\n")
+		}
+
+		pdf.appendString(">>\n")
+	}
+	pdf.appendString("startxref\n")
+	pdf.appendInteger(startxref)
+	pdf.appendString("\n")
+	pdf.appendString("%%EOF\n")
+
+	if pdf.signature != nil {
+		return pdf.completeSignature()
+	}
+	if err := pdf.writer.Flush(); err != nil {
+		return &IOError{Op: "write", Err: err}
+	}
+	return nil
+}
+
+func (pdf *PDF) appendXRefEntry(offset int) {
+	str := strconv.Itoa(offset)
+	for i := 0; i < 10-len(str); i++ {
+		pdf.appendString("0")
+	}
+	pdf.appendString(str)
+	pdf.appendString(" 00000 n \n")
+}
+
+// completeUpdate appends the xref section and the trailer of the incremental update.
+// The original file identifier is kept and the trailer points to the previous xref section.
+func (pdf *PDF) completeUpdate() error {
+	if pdf.updateOffsets == nil {
+		return malformed("PDF", "incremental update requires Read before Complete")
+	}
+	numbers := make([]int, 0, len(pdf.updateOffsets)+1)
+	numbers = append(numbers, 0)
+	for number := range pdf.updateOffsets {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	if pdf.signature != nil {
+		pdf.addSignatureObject()
+		numbers = append(numbers, pdf.signature.objNumber)
+		sort.Ints(numbers)
+	}
+
+	size := pdf.baseSize
+	if numbers[len(numbers)-1]+1 > size {
+		size = numbers[len(numbers)-1] + 1
+	}
+
+	var startxref int
+	if pdf.baseXRefStream {
+		startxref = pdf.addUpdateXRefStream(numbers, size)
+	} else {
+		startxref = pdf.addUpdateXRefSection(numbers, size)
+	}
+	pdf.appendString("startxref\n")
+	pdf.appendInteger(startxref)
+	pdf.appendString("\n")
+	pdf.appendString("%%EOF\n")
+
+	if pdf.signature != nil {
+		return pdf.completeSignature()
+	}
+	if err := pdf.writer.Flush(); err != nil {
+		return &IOError{Op: "write", Err: err}
+	}
+	return nil
+}
+
+// addUpdateXRefSection writes the xref section and the trailer of the incremental update.
+// Returns the offset of the xref section.
+func (pdf *PDF) addUpdateXRefSection(numbers []int, size int) int {
 	startxref := pdf.byteCount
 
-	// Create the xref table
+	// Create the xref section with subsection for each run of consecutive object numbers
 	pdf.appendString("xref\n")
-	pdf.appendString("0 ")
-	pdf.appendInteger(rootObjNumber + 1)
-	pdf.appendString("\n")
-	pdf.appendString("0000000000 65535 f \n")
-	for _, offset := range pdf.objOffsets {
-		str := strconv.Itoa(offset)
-		for i := 0; i < 10-len(str); i++ {
-			pdf.appendString("0")
+	for i := 0; i < len(numbers); {
+		j := i
+		for j+1 < len(numbers) && numbers[j+1] == numbers[j]+1 {
+			j++
+		}
+		pdf.appendInteger(numbers[i])
+		pdf.appendString(" ")
+		pdf.appendInteger(j - i + 1)
+		pdf.appendString("\n")
+		for k := i; k <= j; k++ {
+			if numbers[k] == 0 {
+				pdf.appendString("0000000000 65535 f \n")
+			} else {
+				pdf.appendXRefEntry(pdf.updateOffsets[numbers[k]])
+			}
 		}
-		pdf.appendString(str)
-		pdf.appendString(" 00000 n \n")
+		i = j + 1
 	}
 	pdf.appendString("trailer\n")
 	pdf.appendString("<<\n")
 	pdf.appendString("/Size ")
-	pdf.appendInteger(rootObjNumber + 1)
+	pdf.appendInteger(size)
+	pdf.appendString("\n")
+
+	pdf.appendString("/Prev ")
+	pdf.appendInteger(pdf.baseStartXRef)
 	pdf.appendString("\n")
 
 	pdf.appendString("/ID[<")
-	pdf.appendString(pdf.uuid)
+	pdf.appendString(pdf.baseID)
 	pdf.appendString("><")
 	pdf.appendString(pdf.uuid)
 	pdf.appendString(">]\n")
 
-	pdf.appendString("/Info ")
-	pdf.appendInteger(infoObjNumber)
+	if pdf.baseInfo != 0 {
+		pdf.appendString("/Info ")
+		pdf.appendInteger(pdf.baseInfo)
+		pdf.appendString(" 0 R\n")
+	}
+
+	pdf.appendString("/Root ")
+	pdf.appendInteger(pdf.baseRoot)
 	pdf.appendString(" 0 R\n")
 
+	pdf.appendString(">>\n")
+	return startxref
+}
+
+// addUpdateXRefStream writes the cross-reference stream of the incremental update.
+// The original PDF that uses cross-reference stream may not have xref table and trailer,
+// so the update must not add them - see 7.5.8.4 in PDF32000_2008.pdf
+// Returns the offset of the cross-reference stream.
+func (pdf *PDF) addUpdateXRefStream(numbers []int, size int) int {
+	startxref := pdf.byteCount
+	xrefObjNumber := size
+	pdf.updateOffsets[xrefObjNumber] = startxref
+	numbers = append(numbers, xrefObjNumber)
+	size++
+
+	w2 := 1
+	for max := startxref; max > 0xFF; max >>= 8 {
+		w2++
+	}
+	row := make([]byte, 1+w2+2)
+	var entries bytes.Buffer
+	var index strings.Builder
+	for i := 0; i < len(numbers); {
+		j := i
+		for j+1 < len(numbers) && numbers[j+1] == numbers[j]+1 {
+			j++
+		}
+		index.WriteString(strconv.Itoa(numbers[i]))
+		index.WriteString(" ")
+		index.WriteString(strconv.Itoa(j - i + 1))
+		index.WriteString(" ")
+		for k := i; k <= j; k++ {
+			kind, field2, field3 := 1, pdf.updateOffsets[numbers[k]], 0
+			if numbers[k] == 0 {
+				kind, field2, field3 = 0, 0, 0xFFFF
+			}
+			row[0] = byte(kind)
+			for n := w2; n > 0; n-- {
+				row[n] = byte(field2)
+				field2 >>= 8
+			}
+			row[w2+1] = byte(field3 >> 8)
+			row[w2+2] = byte(field3)
+			entries.Write(row)
+		}
+		i = j + 1
+	}
+	stream := compressor.Deflate(entries.Bytes())
+
+	pdf.appendInteger(xrefObjNumber)
+	pdf.appendString(" 0 obj\n")
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /XRef\n")
+	pdf.appendString("/Size ")
+	pdf.appendInteger(size)
+	pdf.appendString("\n")
+	pdf.appendString("/Index [")
+	pdf.appendString(strings.TrimSpace(index.String()))
+	pdf.appendString("]\n")
+	pdf.appendString("/W [1 ")
+	pdf.appendInteger(w2)
+	pdf.appendString(" 2]\n")
+
+	pdf.appendString("/Prev ")
+	pdf.appendInteger(pdf.baseStartXRef)
+	pdf.appendString("\n")
+
+	pdf.appendString("/ID[<")
+	pdf.appendString(pdf.baseID)
+	pdf.appendString("><")
+	pdf.appendString(pdf.uuid)
+	pdf.appendString(">]\n")
+
+	if pdf.baseInfo != 0 {
+		pdf.appendString("/Info ")
+		pdf.appendInteger(pdf.baseInfo)
+		pdf.appendString(" 0 R\n")
+	}
+
 	pdf.appendString("/Root ")
-	pdf.appendInteger(rootObjNumber)
+	pdf.appendInteger(pdf.baseRoot)
 	pdf.appendString(" 0 R\n")
 
+	pdf.appendString("/Filter /FlateDecode\n")
+	pdf.appendString("/Length ")
+	pdf.appendInteger(len(stream))
+	pdf.appendString("\n")
 	pdf.appendString(">>\n")
-	pdf.appendString("startxref\n")
-	pdf.appendInteger(startxref)
+	pdf.appendString("stream\n")
+	pdf.appendByteArray(stream)
+	pdf.appendString("\nendstream\n")
+	pdf.appendString("endobj\n")
+
+	return startxref
+}
+
+// addSignatureObject adds the signature dictionary.
+// The bytes before the /Contents value are digested while they are written.
+// Everything after it is kept in memory until the signature value is known.
+func (pdf *PDF) addSignatureObject() {
+	sig := pdf.signature
+	if pdf.incremental {
+		pdf.updateOffsets[sig.objNumber] = pdf.byteCount
+		pdf.appendInteger(sig.objNumber)
+		pdf.appendString(" 0 obj\n")
+	} else {
+		pdf.newobj()
+		sig.objNumber = pdf.getObjNumber()
+	}
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /Sig\n")
+	pdf.appendString("/Filter /Adobe.PPKLite\n")
+	pdf.appendString("/SubFilter /ETSI.CAdES.detached\n")
+	pdf.appendString("/M ")
+	pdf.appendLiteral(sig.getSigningTime())
 	pdf.appendString("\n")
-	pdf.appendString("%%EOF\n")
+	if sig.name != "" {
+		pdf.appendString("/Name ")
+		pdf.appendHexString(encodeToHex(sig.name))
+		pdf.appendString("\n")
+	}
+	if sig.reason != "" {
+		pdf.appendString("/Reason ")
+		pdf.appendHexString(encodeToHex(sig.reason))
+		pdf.appendString("\n")
+	}
+	if sig.location != "" {
+		pdf.appendString("/Location ")
+		pdf.appendHexString(encodeToHex(sig.location))
+		pdf.appendString("\n")
+	}
+	if sig.contactInfo != "" {
+		pdf.appendString("/ContactInfo ")
+		pdf.appendHexString(encodeToHex(sig.contactInfo))
+		pdf.appendString("\n")
+	}
+	pdf.appendString("/Contents ")
 
 	pdf.writer.Flush()
+	sig.contents = pdf.byteCount
+	pdf.writer = bufio.NewWriter(&sig.tail)
+
+	pdf.appendString("<")
+	pdf.appendString(strings.Repeat("0", 2*sig.size))
+	pdf.appendString(">\n")
+	// The values are filled in by completeSignature
+	pdf.appendString("/ByteRange [0 ")
+	sig.byteRange = pdf.byteCount
+	pdf.appendString(strings.Repeat(" ", 32))
+	pdf.appendString("]\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+}
+
+// completeSignature fills in the byte range, signs the digest of the PDF
+// and writes the part of the PDF that was kept in memory.
+func (pdf *PDF) completeSignature() error {
+	sig := pdf.signature
+	pdf.writer.Flush()
+	pdf.writer = sig.writer
+
+	tail := sig.tail.Bytes()
+	start := sig.contents + 2*sig.size + 2 // The offset after the /Contents value
+	copy(tail[sig.byteRange-sig.contents:], fmt.Sprintf(
+		"%d %d %d", sig.contents, start, pdf.byteCount-start))
+	sig.digest.Write(tail[start-sig.contents:])
+
+	cms, err := sig.sign(sig.digest.Sum(nil))
+	if err != nil {
+		return err
+	}
+	if len(cms) > sig.size {
+		return fmt.Errorf("pdfjet: the signature of %d bytes is larger than the reserved space", len(cms))
+	}
+	hex.Encode(tail[1:], cms)
+
+	pdf.writer.Write(tail)
+	if err := pdf.writer.Flush(); err != nil {
+		return &IOError{Op: "write", Err: err}
+	}
+	return nil
+}
+
+// addSignatureField adds the signature field to existing PDF.
+// The widget is added to the page the signature was drawn on or to the first page.
+func (pdf *PDF) addSignatureField(objects *[]*PDFobj) error {
+	sig := pdf.signature
+	var pageObj *PDFobj
+	var rect [4]float32
+	if sig.page != nil && sig.page.pageObj != nil {
+		pageObj = sig.page.pageObj
+		annot := sig.annotation
+		rect = [4]float32{annot.x1, annot.y1, annot.x2, annot.y2}
+	} else {
+		pages, err := pdf.GetPageObjectsErr(*objects)
+		if err != nil {
+			return err
+		}
+		if len(pages) == 0 {
+			return malformed("PDF", "no pages to add the signature field to")
+		}
+		pageObj = pages[0]
+	}
+
+	// Reserve the number of the signature dictionary written by completeUpdate.
+	obj := NewPDFobj()
+	obj.number = len(*objects) + 1
+	*objects = append(*objects, obj)
+	sig.objNumber = obj.number
+
+	widget := NewPDFobj()
+	widget.dict = append(widget.dict,
+		"<<", "/Type", "/Annot", "/Subtype", "/Widget", "/FT", "/Sig",
+		"/T", "<"+encodeToHex(sig.fieldName)+">",
+		"/V", strconv.Itoa(sig.objNumber), "0", "R",
+		"/P", strconv.Itoa(pageObj.number), "0", "R",
+		"/Rect", "[",
+		string(formatFloat32(rect[0])),
+		string(formatFloat32(rect[1])),
+		string(formatFloat32(rect[2])),
+		string(formatFloat32(rect[3])),
+		"]", "/F", "132", ">>")
+	widget.number = len(*objects) + 1
+	*objects = append(*objects, widget)
+	pageObj.addReference("/Annots", widget.number, *objects)
+
+	var root *PDFobj
+	for _, obj := range *objects {
+		if obj.number == pdf.baseRoot {
+			root = obj
+			break
+		}
+	}
+	if root == nil {
+		return malformed("PDF", "catalog object %d not found", pdf.baseRoot)
+	}
+	form := root.getAcroForm(objects)
+	form.addReference("/Fields", widget.number, *objects)
+	form.setValue("/SigFlags", "3")
+	return nil
 }
 
 // SetLanguage sets the "Language" document property of the PDF file.
@@ -1094,49 +1720,126 @@ func contains(slice []string, text string) bool {
 }
 
 // Read returns a list of objects of type PDFobj read from input stream.
+// The program exits if the PDF cannot be parsed. Use ReadErr to handle the error.
 // @param inputStream the PDF input stream.
 // @return List<PDFobj> the list of PDF objects.
 func (pdf *PDF) Read(buf []byte) []*PDFobj {
+	objects, err := pdf.ReadErr(buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return objects
+}
+
+// ReadErr returns a list of objects of type PDFobj read from input stream.
+// Returns MalformedInputError if the PDF cannot be parsed.
+// @param inputStream the PDF input stream.
+// @return List<PDFobj> the list of PDF objects.
+func (pdf *PDF) ReadErr(buf []byte) ([]*PDFobj, error) {
+	return pdf.ReadWithPasswordErr(buf, "")
+}
+
+// ReadWithPassword returns a list of objects of type PDFobj read from encrypted PDF.
+// The program exits if the PDF cannot be parsed. Use ReadWithPasswordErr to handle the error.
+// @param inputStream the PDF input stream.
+// @param password the user or the owner password.
+// @return List<PDFobj> the list of decrypted PDF objects.
+func (pdf *PDF) ReadWithPassword(buf []byte, password string) []*PDFobj {
+	objects, err := pdf.ReadWithPasswordErr(buf, password)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return objects
+}
+
+// ReadWithPasswordErr returns a list of objects of type PDFobj read from encrypted PDF.
+// RC4 40 and 128 bit, AES-128 and AES-256 encryption are supported.
+// The strings and streams are decrypted - the objects can be used the same way
+// as the objects read from unencrypted PDF. The owner restricted PDF that has
+// empty user password can be read using empty password.
+// Returns ErrIncorrectPassword if the password is not the user or the owner password.
+// @param inputStream the PDF input stream.
+// @param password the user or the owner password.
+// @return List<PDFobj> the list of decrypted PDF objects.
+func (pdf *PDF) ReadWithPasswordErr(buf []byte, password string) (_ []*PDFobj, err error) {
+	defer recoverMalformedInput("PDF", &err)
+
 	objects1 := make([]*PDFobj, 0)
-	xref := pdf.getStartXRef(buf)
+	xref, err := pdf.getStartXRef(buf)
+	if err != nil {
+		return nil, err
+	}
 
 	obj1 := getObject(buf, xref, len(buf))
+	if len(obj1.dict) == 0 {
+		return nil, malformed("PDF", "no cross-reference section at offset %d", xref)
+	}
 	if obj1.dict[0] == "xref" {
 		// Get the objects using xref table
-		getObjects1(buf, obj1, &objects1)
+		err = getObjects1(buf, obj1, &objects1)
 	} else {
 		// Get the objects using XRef stream
-		getObjects2(buf, obj1, &objects1)
+		err = getObjects2(buf, obj1, &objects1)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dec, err := getDecryption(buf, obj1, objects1, password)
+	if err != nil {
+		return nil, err
+	}
+	if dec != nil {
+		if pdf.incremental {
+			return nil, unsupported("incremental update of encrypted PDF")
+		}
+		for _, obj := range objects1 {
+			dec.decryptStrings(buf, obj)
+		}
 	}
 
 	objects2 := make([]*PDFobj, 0)
 	for _, obj := range objects1 {
 		if contains(obj.dict, "stream") {
-			length := obj.GetLength(objects1)
-			obj.SetStreamAndData(buf, length)
+			length, err := obj.GetLengthErr(objects1)
+			if err != nil {
+				return nil, err
+			}
+			if dec == nil {
+				err = obj.SetStreamAndDataErr(buf, length)
+			} else {
+				err = obj.setStream(buf, length)
+				if err == nil {
+					dec.decryptStream(obj)
+					err = obj.setData()
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		if obj.getValue("/Type") == "/ObjStm" {
 			first, err := strconv.Atoi(obj.getValue("/First"))
 			if err != nil {
-				log.Fatal(err)
+				return nil, malformedWrap("PDF", "bad /First in object stream", err)
 			}
 			o2 := getObject(obj.GetData(), 0, first)
 			count := len(o2.dict)
 			for i := 0; i < count; i += 2 {
 				num, err := strconv.Atoi(o2.dict[i])
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("PDF", "bad object number in object stream", err)
 				}
 				off, err := strconv.Atoi(o2.dict[i+1])
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("PDF", "bad object offset in object stream", err)
 				}
 				end := len(obj.GetData())
 				if i <= count-4 {
 					tmp, err := strconv.Atoi(o2.dict[i+3])
 					if err != nil {
-						log.Fatal(err)
+						return nil, malformedWrap("PDF", "bad object offset in object stream", err)
 					}
 					end = first + tmp
 				}
@@ -1154,7 +1857,105 @@ func (pdf *PDF) Read(buf []byte) []*PDFobj {
 		}
 	}
 
-	return pdf.getSortedObjects(objects2)
+	objects := pdf.getSortedObjects(objects2)
+	if pdf.incremental {
+		// The object streams and the cross-reference streams are not in the objects.
+		// Their numbers are reserved, so that the new objects do not replace them.
+		size, _ := strconv.Atoi(getTrailerValue(buf, obj1, "/Size"))
+		for len(objects) < size-1 {
+			obj := NewPDFobj()
+			obj.SetNumber(len(objects) + 1)
+			objects = append(objects, obj)
+		}
+		for _, obj := range objects {
+			obj.updated = true
+		}
+		if err := pdf.setUpdateBase(buf, obj1, xref, objects); err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// getDecryption returns the decryption for encrypted PDF or nil if the PDF is not encrypted.
+func getDecryption(buf []byte, trailer *PDFobj, objects []*PDFobj, password string) (*decryption, error) {
+	value := getTrailerValue(buf, trailer, "/Encrypt")
+	if value == "" {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, unsupported("direct encryption dictionary")
+	}
+	var encrypt *PDFobj
+	for _, obj := range objects {
+		if obj.number == number {
+			encrypt = obj
+		}
+	}
+	if encrypt == nil {
+		return nil, malformed("PDF", "encryption dictionary %d not found", number)
+	}
+	end := bytes.Index(buf[trailer.offset:], []byte("startxref"))
+	if end == -1 {
+		end = len(buf) - trailer.offset
+	}
+	id := getRawString(buf[trailer.offset:trailer.offset+end], "/ID")
+	return newDecryption(buf, encrypt, id, password)
+}
+
+// setUpdateBase saves the trailer values and the object checksums
+// of the original PDF. They are used by the incremental update.
+func (pdf *PDF) setUpdateBase(buf []byte, trailer *PDFobj, startxref int, objects []*PDFobj) error {
+	root, err := strconv.Atoi(getTrailerValue(buf, trailer, "/Root"))
+	if err != nil {
+		return malformed("PDF", "trailer without /Root")
+	}
+	pdf.baseRoot = root
+	pdf.baseInfo, _ = strconv.Atoi(getTrailerValue(buf, trailer, "/Info"))
+	pdf.baseSize, _ = strconv.Atoi(getTrailerValue(buf, trailer, "/Size"))
+	if pdf.baseSize < len(objects)+1 {
+		pdf.baseSize = len(objects) + 1
+	}
+	pdf.baseStartXRef = startxref
+	pdf.baseXRefStream = len(trailer.dict) > 0 && trailer.dict[0] != "xref"
+	pdf.baseID = ""
+	id := strings.ReplaceAll(getTrailerValue(buf, trailer, "/ID"), " ", "")
+	if strings.HasPrefix(id, "[<") {
+		end := strings.Index(id, ">")
+		if end == -1 {
+			return malformed("PDF", "unterminated /ID in trailer")
+		}
+		pdf.baseID = id[2:end]
+	}
+	if pdf.baseID == "" {
+		pdf.baseID = pdf.uuid
+	}
+	pdf.baseChecksums = make(map[int]uint64)
+	for _, obj := range objects {
+		if len(obj.dict) > 0 {
+			pdf.baseChecksums[obj.number] = obj.checksum()
+		}
+	}
+	pdf.updateOffsets = make(map[int]int)
+	return nil
+}
+
+// getTrailerValue returns the trailer value for the specified key.
+// Follows the /Prev chain when the latest trailer does not have the key.
+func getTrailerValue(buf []byte, trailer *PDFobj, key string) string {
+	for trailer != nil {
+		value := trailer.getValue(key)
+		if value != "" {
+			return value
+		}
+		prev, err := strconv.Atoi(trailer.getValue("/Prev"))
+		if err != nil {
+			return ""
+		}
+		trailer = getObject(buf, prev, len(buf))
+	}
+	return ""
 }
 
 func process(obj *PDFobj, sb *strings.Builder, buf []byte, off int) bool {
@@ -1210,6 +2011,10 @@ func getObject(buf []byte, off, length int) *PDFobj {
 			if p == 0 {
 				done = process(obj, &token, buf, off)
 			}
+		} else if p > 0 {
+			// The whitespace and the slashes in literal string are kept
+			token.WriteByte(b2)
+			b1 = b2
 		} else if b2 == 0x00 || // Null
 			b2 == 0x09 || // Horizontal Tab
 			b2 == 0x0A || // Line Feed (LF)
@@ -1227,36 +2032,26 @@ func getObject(buf []byte, off, length int) *PDFobj {
 				b1 = b2
 			}
 		} else if b2 == byte('<') || b2 == byte('>') || b2 == byte('%') {
-			if p > 0 {
-				token.WriteByte(b2)
-				b1 = b2
-			} else {
-				if b2 != b1 {
-					done = process(obj, &token, buf, off)
-					if !done {
-						token.WriteByte(b2)
-						b1 = b2
-					}
-				} else {
+			if b2 != b1 {
+				done = process(obj, &token, buf, off)
+				if !done {
 					token.WriteByte(b2)
-					done = process(obj, &token, buf, off)
-					if !done {
-						b1 = byte(' ')
-					}
+					b1 = b2
 				}
-			}
-		} else if b2 == byte('[') || b2 == byte(']') ||
-			b2 == byte('{') || b2 == byte('}') {
-			if p > 0 {
-				token.WriteByte(b2)
-				b1 = b2
 			} else {
+				token.WriteByte(b2)
 				done = process(obj, &token, buf, off)
 				if !done {
-					obj.dict = append(obj.dict, string(b2))
-					b1 = b2
+					b1 = byte(' ')
 				}
 			}
+		} else if b2 == byte('[') || b2 == byte(']') ||
+			b2 == byte('{') || b2 == byte('}') {
+			done = process(obj, &token, buf, off)
+			if !done {
+				obj.dict = append(obj.dict, string(b2))
+				b1 = b2
+			}
 		} else {
 			token.WriteByte(b2)
 			b1 = b2
@@ -1278,17 +2073,12 @@ func toInt(buf []byte, off, length int) int {
 	return i
 }
 
-func getObjects1(buf []byte, obj *PDFobj, objects *[]*PDFobj) {
+func getObjects1(buf []byte, obj *PDFobj, objects *[]*PDFobj) error {
 	xref := obj.getValue("/Prev")
 	if xref != "" {
-		num, err := strconv.Atoi(xref)
-		if err != nil {
-			log.Fatal(err)
+		if err := getPrevObjects(buf, xref, objects); err != nil {
+			return err
 		}
-		getObjects1(
-			buf,
-			getObject(buf, num, len(buf)),
-			objects)
 	}
 
 	i := 1
@@ -1301,7 +2091,7 @@ func getObjects1(buf []byte, obj *PDFobj, objects *[]*PDFobj) {
 
 		n, err := strconv.Atoi(obj.dict[i]) // Number of entries
 		if err != nil {
-			log.Fatal(err)
+			return malformedWrap("PDF", "bad xref subsection", err)
 		}
 		i++
 		for j := 0; j < n; j++ {
@@ -1313,31 +2103,41 @@ func getObjects1(buf []byte, obj *PDFobj, objects *[]*PDFobj) {
 			if status != "f" {
 				off, err := strconv.Atoi(offset)
 				if err != nil {
-					log.Fatal(err)
+					return malformedWrap("PDF", "bad xref entry", err)
 				}
 				o2 := getObject(buf, off, len(buf))
 				num, err := strconv.Atoi(o2.dict[0])
 				if err != nil {
-					log.Fatal(err)
+					return malformedWrap("PDF", "bad object at offset "+offset, err)
 				}
 				o2.number = num
 				*objects = append(*objects, o2)
 			}
 		}
 	}
+	return nil
+}
+
+// getPrevObjects gets the objects from the previous cross-reference section.
+// The sections of incrementally updated PDF can be tables or XRef streams.
+func getPrevObjects(buf []byte, prev string, objects *[]*PDFobj) error {
+	off, err := strconv.Atoi(prev)
+	if err != nil {
+		return malformedWrap("PDF", "bad /Prev in trailer", err)
+	}
+	obj := getObject(buf, off, len(buf))
+	if len(obj.dict) > 0 && obj.dict[0] == "xref" {
+		return getObjects1(buf, obj, objects)
+	}
+	return getObjects2(buf, obj, objects)
 }
 
-func getObjects2(buf []byte, obj *PDFobj, objects *[]*PDFobj) {
+func getObjects2(buf []byte, obj *PDFobj, objects *[]*PDFobj) error {
 	prev := obj.getValue("/Prev")
 	if prev != "" {
-		off, err := strconv.Atoi(prev)
-		if err != nil {
-			log.Fatal(err)
+		if err := getPrevObjects(buf, prev, objects); err != nil {
+			return err
 		}
-		getObjects2(
-			buf,
-			getObject(buf, off, len(buf)),
-			objects)
 	}
 
 	// See page 50 in PDF32000_2008.pdf
@@ -1351,43 +2151,48 @@ func getObjects2(buf []byte, obj *PDFobj, objects *[]*PDFobj) {
 		if token == "/Predictor" {
 			val, err := strconv.Atoi(obj.dict[i+1])
 			if err != nil {
-				log.Fatal(err)
+				return malformedWrap("PDF", "bad /Predictor in XRef stream", err)
 			}
 			predictor = val
 		} else if token == "/Length" {
 			len1, err := strconv.Atoi(obj.dict[i+1])
 			if err != nil {
-				log.Fatal(err)
+				return malformedWrap("PDF", "bad /Length in XRef stream", err)
 			}
 			length = len1
 		} else if token == "/W" {
 			// "/W [ 1 3 1 ]"
 			num, err := strconv.Atoi(obj.dict[i+2])
 			if err != nil {
-				log.Fatal(err)
+				return malformedWrap("PDF", "bad /W in XRef stream", err)
 			}
 			n1 = num
 			num, err = strconv.Atoi(obj.dict[i+3])
 			if err != nil {
-				log.Fatal(err)
+				return malformedWrap("PDF", "bad /W in XRef stream", err)
 			}
 			n2 = num
 			num, err = strconv.Atoi(obj.dict[i+4])
 			if err != nil {
-				log.Fatal(err)
+				return malformedWrap("PDF", "bad /W in XRef stream", err)
 			}
 			n3 = num
 		}
 	}
 
-	obj.SetStreamAndData(buf, length)
+	if err := obj.SetStreamAndDataErr(buf, length); err != nil {
+		return err
+	}
 	n := n1 + n2 + n3 // Number of bytes per entry
 	if predictor > 0 {
 		n++
 	}
+	if n == 0 {
+		return malformed("PDF", "XRef stream without /W")
+	}
 
 	entry := make([]byte, n)
-	for i := 0; i < len(obj.data); i += n {
+	for i := 0; i+n <= len(obj.data); i += n {
 		if predictor == 12 {
 			// Apply the 'Up' filter.
 			for j := 1; j < n; j++ {
@@ -1405,7 +2210,7 @@ func getObjects2(buf []byte, obj *PDFobj, objects *[]*PDFobj) {
 				o2 := getObject(buf, toInt(entry, 1+n1, n2), len(buf))
 				num, err := strconv.Atoi(o2.dict[0])
 				if err != nil {
-					log.Fatal(err)
+					return malformedWrap("PDF", "bad object in XRef stream", err)
 				}
 				o2.number = num
 				*objects = append(*objects, o2)
@@ -1415,16 +2220,17 @@ func getObjects2(buf []byte, obj *PDFobj, objects *[]*PDFobj) {
 				o2 := getObject(buf, toInt(entry, n1, n2), len(buf))
 				num, err := strconv.Atoi(o2.dict[0])
 				if err != nil {
-					log.Fatal(err)
+					return malformedWrap("PDF", "bad object in XRef stream", err)
 				}
 				o2.number = num
 				*objects = append(*objects, o2)
 			}
 		}
 	}
+	return nil
 }
 
-func (pdf *PDF) getStartXRef(buf []byte) int {
+func (pdf *PDF) getStartXRef(buf []byte) (int, error) {
 	var sb strings.Builder
 	for i := (len(buf) - 10); i > 10; i-- {
 		if buf[i] == 's' &&
@@ -1450,9 +2256,9 @@ func (pdf *PDF) getStartXRef(buf []byte) int {
 
 	objNumber, err := strconv.Atoi(sb.String())
 	if err != nil {
-		log.Fatal(err)
+		return 0, malformed("PDF", "startxref not found")
 	}
-	return objNumber
+	return objNumber, nil
 }
 
 func (pdf *PDF) addOutlineDict(toc *Bookmark) int {
@@ -1495,9 +2301,9 @@ func (pdf *PDF) addOutlineItem(parent, i int, bm1 *Bookmark) {
 
 	pdf.newobj()
 	pdf.appendString("<<\n")
-	pdf.appendString("/Title <")
-	pdf.appendString(encodeToHex(bm1.GetTitle()))
-	pdf.appendString(">\n")
+	pdf.appendString("/Title ")
+	pdf.appendHexString(encodeToHex(bm1.GetTitle()))
+	pdf.appendString("\n")
 	pdf.appendString("/Parent ")
 	pdf.appendInteger(parent)
 	pdf.appendString(" 0 R\n")
@@ -1548,16 +2354,45 @@ func getNumOfChildren(numOfChildren int, bm1 *Bookmark) int {
 }
 
 // AddObjects adds the specified objects to the PDF.
+// The program exits if the objects have no root /Pages object. Use AddObjectsErr to handle the error.
 func (pdf *PDF) AddObjects(objects *[]*PDFobj) {
+	if err := pdf.AddObjectsErr(objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// AddObjectsErr adds the objects read from existing PDF to this PDF.
+// Returns MalformedInputError if the objects have no root /Pages object.
+func (pdf *PDF) AddObjectsErr(objects *[]*PDFobj) error {
+	if pdf.incremental {
+		if pdf.signature != nil {
+			if err := pdf.addSignatureField(objects); err != nil {
+				return err
+			}
+		}
+		pdf.addUpdatedObjectsToPDF(objects)
+		return nil
+	}
 	pagesObject := pdf.getPagesObject(*objects)
-	number := pagesObject.dict[0]
-	objNumber, err := strconv.Atoi(number)
+	if pagesObject == nil {
+		return malformed("PDF", "no root /Pages object")
+	}
+	objNumber, err := strconv.Atoi(pagesObject.dict[0])
 	if err != nil {
-		log.Fatal(err)
-	} else {
-		pdf.pagesObjNumber = objNumber
-		pdf.addObjectsToPDF(objects)
+		return malformedWrap("PDF", "bad /Pages object number", err)
+	}
+	pdf.pagesObjNumber = objNumber
+	for _, obj := range *objects {
+		if obj.getValue("/Type") == "/Catalog" {
+			if findKey(obj.dict, "/AcroForm") != -1 {
+				// The new catalog refers to the interactive form of the original catalog.
+				pdf.acroFormObjNumber = obj.getAcroForm(objects).number
+			}
+			break
+		}
 	}
+	pdf.addObjectsToPDF(objects)
+	return nil
 }
 
 func (pdf *PDF) getPagesObject(objects []*PDFobj) *PDFobj {
@@ -1570,22 +2405,49 @@ func (pdf *PDF) getPagesObject(objects []*PDFobj) *PDFobj {
 }
 
 // GetPageObjects returns all page objects.
+// The program exits if the page tree is malformed. Use GetPageObjectsErr to handle the error.
 func (pdf *PDF) GetPageObjects(objects []*PDFobj) []*PDFobj {
-	pages := make([]*PDFobj, 0)
-	pdf.getPageObjects(pdf.getPagesObject(objects), objects, &pages)
+	pages, err := pdf.GetPageObjectsErr(objects)
+	if err != nil {
+		log.Fatal(err)
+	}
 	return pages
 }
 
-func (pdf *PDF) getPageObjects(pdfObj *PDFobj, objects []*PDFobj, pages *[]*PDFobj) {
-	kids := pdfObj.GetObjectNumbers("/Kids")
+// GetPageObjectsErr returns all page objects.
+// Returns MalformedInputError if the page tree is malformed.
+func (pdf *PDF) GetPageObjectsErr(objects []*PDFobj) ([]*PDFobj, error) {
+	pages := make([]*PDFobj, 0)
+	root := pdf.getPagesObject(objects)
+	if root == nil {
+		return nil, malformed("PDF", "missing page tree")
+	}
+	if err := pdf.getPageObjects(root, objects, &pages, 0); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+func (pdf *PDF) getPageObjects(pdfObj *PDFobj, objects []*PDFobj, pages *[]*PDFobj, depth int) error {
+	if depth > 64 {
+		return malformed("PDF", "page tree is too deep")
+	}
+	kids, err := pdfObj.GetObjectNumbersErr("/Kids")
+	if err != nil {
+		return err
+	}
 	for _, number := range kids {
+		if number < 1 || number > len(objects) {
+			return malformed("PDF", "bad /Kids reference %d", number)
+		}
 		obj := objects[number-1]
 		if isPageObject(obj) {
 			*pages = append(*pages, obj)
-		} else {
-			pdf.getPageObjects(obj, objects, pages)
+		} else if err := pdf.getPageObjects(obj, objects, pages, depth+1); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 func isPageObject(obj *PDFobj) bool {
@@ -1628,26 +2490,24 @@ func (pdf *PDF) getExtGState(resources *PDFobj) string {
 	return buf.String()
 }
 
-func (pdf *PDF) getFontObjects(resources *PDFobj, objects []*PDFobj) []*PDFobj {
+func (pdf *PDF) getFontObjects(resources *PDFobj, objects []*PDFobj) ([]*PDFobj, error) {
 	fonts := make([]*PDFobj, 0)
 
 	dict := resources.GetDict()
 	for i, token := range dict {
 		if token == "/Font" {
 			if dict[i+2] != ">>" {
-				token := dict[i+3]
-				objNumber, err := strconv.Atoi(token)
+				font, err := getReferencedObject(dict[i+3], objects)
 				if err != nil {
-					log.Fatal(err)
-				} else {
-					fonts = append(fonts, objects[objNumber-1])
+					return nil, err
 				}
+				fonts = append(fonts, font)
 			}
 		}
 	}
 
 	if len(fonts) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	i := 4
@@ -1663,26 +2523,26 @@ func (pdf *PDF) getFontObjects(resources *PDFobj, objects []*PDFobj) []*PDFobj {
 		i++
 	}
 
-	return fonts
+	return fonts, nil
 }
 
-func (pdf *PDF) getDescendantFonts(font *PDFobj, objects []*PDFobj) []*PDFobj {
+func (pdf *PDF) getDescendantFonts(font *PDFobj, objects []*PDFobj) ([]*PDFobj, error) {
 	descendantFonts := make([]*PDFobj, 0)
 	dict := font.GetDict()
 	for i, token := range dict {
 		if token == "/DescendantFonts" {
 			token = dict[i+2]
-			objNumber, err := strconv.Atoi(token)
+			if token == "]" {
+				continue
+			}
+			descendantFont, err := getReferencedObject(token, objects)
 			if err != nil {
-				log.Fatal(err)
-			} else {
-				if token != "]" {
-					descendantFonts = append(descendantFonts, objects[objNumber-1])
-				}
+				return nil, err
 			}
+			descendantFonts = append(descendantFonts, descendantFont)
 		}
 	}
-	return descendantFonts
+	return descendantFonts, nil
 }
 
 func (pdf *PDF) getObjectFromObjects(name string, obj *PDFobj, objects []*PDFobj) *PDFobj {
@@ -1702,20 +2562,45 @@ func (pdf *PDF) getObjectFromObjects(name string, obj *PDFobj, objects []*PDFobj
 }
 
 // AddResourceObjects adds the resource objects to the PDF.
+// The program exits if the resources are malformed. Use AddResourceObjectsErr to handle the error.
 func (pdf *PDF) AddResourceObjects(objects []*PDFobj) {
+	if err := pdf.AddResourceObjectsErr(objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// AddResourceObjectsErr adds the resource objects to the PDF.
+// Returns MalformedInputError if the resources are malformed.
+func (pdf *PDF) AddResourceObjectsErr(objects []*PDFobj) (err error) {
+	defer recoverMalformedInput("PDF", &err)
 	resources := make([]*PDFobj, 0)
 
-	pages := pdf.GetPageObjects(objects)
+	pages, err := pdf.GetPageObjectsErr(objects)
+	if err != nil {
+		return err
+	}
 	for _, page := range pages {
-		resObj := page.getResourcesObject(objects)
-		fonts := pdf.getFontObjects(resObj, objects)
+		resObj, err := page.getResourcesObject(objects)
+		if err != nil {
+			return err
+		}
+		if resObj == nil {
+			continue
+		}
+		fonts, err := pdf.getFontObjects(resObj, objects)
+		if err != nil {
+			return err
+		}
 		for _, font := range fonts {
 			resources = append(resources, font)
 			obj := pdf.getObjectFromObjects("/ToUnicode", font, objects)
 			if obj != nil {
 				resources = append(resources, obj)
 			}
-			descendantFonts := pdf.getDescendantFonts(font, objects)
+			descendantFonts, err := pdf.getDescendantFonts(font, objects)
+			if err != nil {
+				return err
+			}
 			for _, descendantFont := range descendantFonts {
 				resources = append(resources, descendantFont)
 				obj = pdf.getObjectFromObjects("/FontDescriptor", descendantFont, objects)
@@ -1735,64 +2620,120 @@ func (pdf *PDF) AddResourceObjects(objects []*PDFobj) {
 	})
 
 	pdf.addObjectsToPDF(&resources)
+	return nil
 }
 
 func (pdf *PDF) addObjectsToPDF(objects *[]*PDFobj) {
 	for _, obj := range *objects {
-		if obj.offset == 0 {
-			// Create new object.
-			pdf.objOffsets = append(pdf.objOffsets, pdf.byteCount)
-			pdf.appendInteger(obj.number)
-			pdf.appendString(" 0 obj\n")
-			if obj.dict != nil {
-				for _, token := range obj.dict {
-					pdf.appendString(token)
-					pdf.appendString(" ")
-				}
+		pdf.objOffsets = append(pdf.objOffsets, pdf.byteCount)
+		pdf.startCapture(len(pdf.objOffsets))
+		pdf.addObjectToPDF(obj)
+		pdf.endCapture()
+	}
+}
+
+// addUpdatedObjectsToPDF adds only the new objects and the objects
+// that were changed after they were read from the original PDF.
+func (pdf *PDF) addUpdatedObjectsToPDF(objects *[]*PDFobj) {
+	for _, obj := range *objects {
+		checksum, ok := pdf.baseChecksums[obj.number]
+		if ok && checksum == obj.checksum() {
+			continue
+		}
+		if !ok && len(obj.dict) == 0 && obj.stream == nil {
+			continue // Unused object number
+		}
+		pdf.updateOffsets[obj.number] = pdf.byteCount
+		pdf.addObjectToPDF(obj)
+	}
+}
+
+func (pdf *PDF) addObjectToPDF(obj *PDFobj) {
+	if pdf.encryption != nil {
+		obj = pdf.encryptObject(obj)
+	}
+	if obj.offset == 0 {
+		// Create new object.
+		pdf.appendInteger(obj.number)
+		pdf.appendString(" 0 obj\n")
+		if obj.dict != nil {
+			for _, token := range obj.dict {
+				pdf.appendString(token)
+				pdf.appendString(" ")
 			}
-			if obj.stream != nil {
-				if len(obj.dict) == 0 {
-					pdf.appendString("<< /Length ")
-					pdf.appendInteger(len(obj.stream))
-					pdf.appendString(" >>")
-				}
-				pdf.appendString("\nstream\n")
-				pdf.appendByteArray(obj.stream)
-				pdf.appendString("\nendstream\n")
+		}
+		if obj.stream != nil {
+			if len(obj.dict) == 0 {
+				pdf.appendString("<< /Length ")
+				pdf.appendInteger(len(obj.stream))
+				pdf.appendString(" >>")
+			}
+			pdf.appendString("\nstream\n")
+			pdf.appendByteArray(obj.stream)
+			pdf.appendString("\nendstream\n")
+		}
+		pdf.appendString("endobj\n")
+	} else {
+		// Uncomment to see the format of the objects.
+		// fmt.Println(obj.dict)
+		n := len(obj.dict)
+		var token string
+		for i := 0; i < n; i++ {
+			token = obj.dict[i]
+			pdf.appendString(token)
+			if i < (n - 1) {
+				pdf.appendString(" ")
+			} else {
+				pdf.appendString("\n")
 			}
+		}
+		if obj.stream != nil {
+			pdf.appendByteArray(obj.stream)
+			pdf.appendString("\nendstream\n")
+		}
+		if token != "endobj" {
 			pdf.appendString("endobj\n")
-		} else {
-			pdf.objOffsets = append(pdf.objOffsets, pdf.byteCount)
-			// Uncomment to see the format of the objects.
-			// fmt.Println(obj.dict)
-			var link bool = false
-			n := len(obj.dict)
-			var token string
-			for i := 0; i < n; i++ {
-				token = obj.dict[i]
-				pdf.appendString(token)
-				if strings.HasPrefix(token, "(http:") {
-					link = true
-				} else if link && strings.HasSuffix(token, ")") {
-					link = false
-				}
-				if i < (n - 1) {
-					if !link {
-						pdf.appendString(" ")
-					}
-				} else {
-					pdf.appendString("\n")
-				}
+		}
+	}
+}
+
+// encryptObject returns copy of the object with encrypted strings and stream.
+func (pdf *PDF) encryptObject(obj *PDFobj) *PDFobj {
+	obj2 := *obj
+	obj2.dict = make([]string, 0, len(obj.dict))
+	if obj.stream != nil {
+		obj2.stream = pdf.encrypt(obj.stream)
+	}
+	for i := 0; i < len(obj.dict); i++ {
+		token := obj.dict[i]
+		if strings.HasPrefix(token, "(") {
+			text := strings.TrimSuffix(token[1:], ")")
+			obj2.dict = append(obj2.dict,
+				"<"+hex.EncodeToString(pdf.encryption.encrypt(unescapeLiteral(text)))+">")
+		} else if strings.HasPrefix(token, "<") && !strings.HasPrefix(token, "<<") {
+			text := strings.TrimSuffix(token[1:], ">")
+			rest := ""
+			if !strings.HasSuffix(token, ">") && i+1 < len(obj.dict) && strings.HasPrefix(obj.dict[i+1], ">") {
+				i++
+				rest = obj.dict[i][1:]
 			}
-			if obj.stream != nil {
-				pdf.appendByteArray(obj.stream)
-				pdf.appendString("\nendstream\n")
+			data, _ := hex.DecodeString(text)
+			obj2.dict = append(obj2.dict,
+				"<"+hex.EncodeToString(pdf.encryption.encrypt(data))+">")
+			if rest != "" {
+				obj2.dict = append(obj2.dict, rest)
 			}
-			if token != "endobj" {
-				pdf.appendString("endobj\n")
+		} else if token == "/Length" && obj.stream != nil && i+1 < len(obj.dict) {
+			obj2.dict = append(obj2.dict, token, strconv.Itoa(len(obj2.stream)))
+			i++
+			if i+2 < len(obj.dict) && obj.dict[i+2] == "R" {
+				i += 2 // Indirect length
 			}
+		} else {
+			obj2.dict = append(obj2.dict, token)
 		}
 	}
+	return &obj2
 }
 
 func (pdf *PDF) appendInteger(value int) {
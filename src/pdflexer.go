@@ -0,0 +1,671 @@
+package pdfjet
+
+/**
+ * pdflexer.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/edragoev1/pdfjet/src/decompressor"
+)
+
+// The values read by pdfLexer are float64 numbers, bool, nil for the null object,
+// PDFName, PDFString, pdfRef, pdfOperator, []any arrays and PDFDict dictionaries.
+
+// PDFName is PDF name without the leading slash.
+type PDFName string
+
+// PDFString is PDF literal or hexadecimal string.
+type PDFString []byte
+
+// pdfRef is indirect reference to the object with the number.
+type pdfRef int
+
+// pdfOperator is content stream operator or other keyword.
+type pdfOperator string
+
+// PDFDict is PDF dictionary. The keys are without the leading slash.
+type PDFDict map[string]any
+
+// pdfLexer reads the values of PDF objects and content streams.
+type pdfLexer struct {
+	buf []byte
+	pos int
+}
+
+func newPDFLexer(buf []byte) *pdfLexer {
+	return &pdfLexer{buf: buf}
+}
+
+func isPDFWhitespace(b byte) bool {
+	return b == 0x00 || b == 0x09 || b == 0x0A || b == 0x0C || b == 0x0D || b == 0x20
+}
+
+func isPDFDelimiter(b byte) bool {
+	return b == '(' || b == ')' || b == '<' || b == '>' || b == '[' || b == ']' ||
+		b == '{' || b == '}' || b == '/' || b == '%'
+}
+
+// skipWhitespace skips the whitespace and the comments.
+func (lx *pdfLexer) skipWhitespace() {
+	for lx.pos < len(lx.buf) {
+		b := lx.buf[lx.pos]
+		if b == '%' {
+			for lx.pos < len(lx.buf) && lx.buf[lx.pos] != '\n' && lx.buf[lx.pos] != '\r' {
+				lx.pos++
+			}
+		} else if isPDFWhitespace(b) {
+			lx.pos++
+		} else {
+			return
+		}
+	}
+}
+
+// next returns the next value or operator. Returns io.EOF at the end of the data.
+func (lx *pdfLexer) next() (any, error) {
+	lx.skipWhitespace()
+	if lx.pos >= len(lx.buf) {
+		return nil, io.EOF
+	}
+	b := lx.buf[lx.pos]
+	switch {
+	case b == '/':
+		lx.pos++
+		return PDFName(lx.readName()), nil
+	case b == '(':
+		lx.pos++
+		return lx.readLiteralString()
+	case b == '<' && lx.pos+1 < len(lx.buf) && lx.buf[lx.pos+1] == '<':
+		lx.pos += 2
+		return lx.readDict()
+	case b == '<':
+		lx.pos++
+		return lx.readHexString()
+	case b == '[':
+		lx.pos++
+		return lx.readArray()
+	case b == ']' || b == '>' || b == ')' || b == '{' || b == '}':
+		// The closing delimiters are returned as operators - the callers check for them.
+		lx.pos++
+		if b == '>' && lx.pos < len(lx.buf) && lx.buf[lx.pos] == '>' {
+			lx.pos++
+			return pdfOperator(">>"), nil
+		}
+		return pdfOperator(string(b)), nil
+	}
+	start := lx.pos
+	for lx.pos < len(lx.buf) && !isPDFWhitespace(lx.buf[lx.pos]) && !isPDFDelimiter(lx.buf[lx.pos]) {
+		lx.pos++
+	}
+	token := string(lx.buf[start:lx.pos])
+	if c := token[0]; c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9') {
+		number, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			// Some writers produce numbers like "--5" or "5-". Such numbers are read as 0.
+			return 0.0, nil
+		}
+		return number, nil
+	}
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfOperator(token), nil
+}
+
+// readName reads the name after the slash. The #xx escapes are replaced by the characters.
+func (lx *pdfLexer) readName() string {
+	var sb strings.Builder
+	for lx.pos < len(lx.buf) && !isPDFWhitespace(lx.buf[lx.pos]) && !isPDFDelimiter(lx.buf[lx.pos]) {
+		b := lx.buf[lx.pos]
+		lx.pos++
+		if b == '#' && lx.pos+2 <= len(lx.buf) {
+			if n, err := strconv.ParseUint(string(lx.buf[lx.pos:lx.pos+2]), 16, 8); err == nil {
+				sb.WriteByte(byte(n))
+				lx.pos += 2
+				continue
+			}
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String()
+}
+
+// readLiteralString reads the string after the opening parenthesis.
+func (lx *pdfLexer) readLiteralString() (PDFString, error) {
+	str := make([]byte, 0, 16)
+	level := 1
+	for lx.pos < len(lx.buf) {
+		b := lx.buf[lx.pos]
+		lx.pos++
+		switch b {
+		case '(':
+			level++
+		case ')':
+			level--
+			if level == 0 {
+				return str, nil
+			}
+		case '\r':
+			// The end of line is read as single line feed.
+			if lx.pos < len(lx.buf) && lx.buf[lx.pos] == '\n' {
+				lx.pos++
+			}
+			b = '\n'
+		case '\\':
+			if lx.pos >= len(lx.buf) {
+				continue
+			}
+			b = lx.buf[lx.pos]
+			lx.pos++
+			switch b {
+			case 'n':
+				b = '\n'
+			case 'r':
+				b = '\r'
+			case 't':
+				b = '\t'
+			case 'b':
+				b = '\b'
+			case 'f':
+				b = '\f'
+			case '\r':
+				if lx.pos < len(lx.buf) && lx.buf[lx.pos] == '\n' {
+					lx.pos++
+				}
+				continue // The line continuation
+			case '\n':
+				continue
+			default:
+				if b >= '0' && b <= '7' {
+					n := int(b - '0')
+					for i := 0; i < 2 && lx.pos < len(lx.buf) && lx.buf[lx.pos] >= '0' && lx.buf[lx.pos] <= '7'; i++ {
+						n = 8*n + int(lx.buf[lx.pos]-'0')
+						lx.pos++
+					}
+					b = byte(n)
+				}
+			}
+		}
+		str = append(str, b)
+	}
+	return nil, malformed("PDF", "unterminated literal string")
+}
+
+// readHexString reads the hexadecimal string after the opening angle bracket.
+func (lx *pdfLexer) readHexString() (PDFString, error) {
+	str := make([]byte, 0, 16)
+	n := 0
+	digits := 0
+	for lx.pos < len(lx.buf) {
+		b := lx.buf[lx.pos]
+		lx.pos++
+		switch {
+		case b >= '0' && b <= '9':
+			n = n<<4 | int(b-'0')
+		case b >= 'A' && b <= 'F':
+			n = n<<4 | int(b-'A'+10)
+		case b >= 'a' && b <= 'f':
+			n = n<<4 | int(b-'a'+10)
+		case b == '>':
+			if digits%2 == 1 {
+				str = append(str, byte(n<<4)) // The missing last digit is 0.
+			}
+			return str, nil
+		default:
+			continue // The whitespace is ignored.
+		}
+		digits++
+		if digits%2 == 0 {
+			str = append(str, byte(n))
+			n = 0
+		}
+	}
+	return nil, malformed("PDF", "unterminated hexadecimal string")
+}
+
+// readArray reads the array after the opening bracket.
+func (lx *pdfLexer) readArray() ([]any, error) {
+	array := make([]any, 0)
+	for {
+		value, err := lx.next()
+		if err == io.EOF {
+			return nil, malformed("PDF", "unterminated array")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value == pdfOperator("]") {
+			return array, nil
+		}
+		if value == pdfOperator("R") && len(array) >= 2 {
+			if number, ok := array[len(array)-2].(float64); ok {
+				array = append(array[:len(array)-2], pdfRef(number))
+				continue
+			}
+		}
+		array = append(array, value)
+	}
+}
+
+// readDict reads the dictionary after the opening double angle brackets.
+func (lx *pdfLexer) readDict() (PDFDict, error) {
+	values, err := lx.readArrayUntil(">>")
+	if err != nil {
+		return nil, err
+	}
+	dict := make(PDFDict)
+	for i := 0; i+1 < len(values); i += 2 {
+		if key, ok := values[i].(PDFName); ok {
+			dict[string(key)] = values[i+1]
+		}
+	}
+	return dict, nil
+}
+
+// readArrayUntil reads the values until the closing delimiter.
+// The indirect references are read as pdfRef.
+func (lx *pdfLexer) readArrayUntil(end pdfOperator) ([]any, error) {
+	values := make([]any, 0)
+	for {
+		value, err := lx.next()
+		if err == io.EOF {
+			return nil, malformed("PDF", "unterminated dictionary")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value == end {
+			return values, nil
+		}
+		if value == pdfOperator("R") && len(values) >= 2 {
+			if number, ok := values[len(values)-2].(float64); ok {
+				values = append(values[:len(values)-2], pdfRef(number))
+				continue
+			}
+		}
+		values = append(values, value)
+	}
+}
+
+// readInlineImage reads the inline image after the BI operator.
+// Returns the image dictionary with the abbreviated keys and the image data.
+func (lx *pdfLexer) readInlineImage() (PDFDict, []byte, error) {
+	values, err := lx.readArrayUntil("ID")
+	if err != nil {
+		return nil, nil, malformed("PDF", "inline image without ID")
+	}
+	dict := make(PDFDict)
+	for i := 0; i+1 < len(values); i += 2 {
+		if key, ok := values[i].(PDFName); ok {
+			dict[string(key)] = values[i+1]
+		}
+	}
+	if lx.pos < len(lx.buf) && isPDFWhitespace(lx.buf[lx.pos]) {
+		lx.pos++ // The single whitespace after ID
+	}
+	start := lx.pos
+	if length, ok := dict["L"].(float64); ok && start+int(length) <= len(lx.buf) {
+		lx.pos = start + int(length)
+	}
+	// The data ends with whitespace followed by EI and whitespace or the end of the data.
+	for i := lx.pos; i+2 <= len(lx.buf); i++ {
+		if lx.buf[i] == 'E' && lx.buf[i+1] == 'I' &&
+			(i == start || isPDFWhitespace(lx.buf[i-1])) &&
+			(i+2 == len(lx.buf) || isPDFWhitespace(lx.buf[i+2])) {
+			end := i
+			if end > start && isPDFWhitespace(lx.buf[end-1]) {
+				end--
+			}
+			lx.pos = i + 2
+			return dict, lx.buf[start:end], nil
+		}
+	}
+	return nil, nil, malformed("PDF", "inline image without EI")
+}
+
+// pdfObjects resolves the indirect references to the objects read from PDF.
+type pdfObjects struct {
+	objects []*PDFobj
+	values  map[int]any
+}
+
+func newPDFObjects(objects []*PDFobj) *pdfObjects {
+	return &pdfObjects{objects: objects, values: make(map[int]any)}
+}
+
+// getObject returns the object with the number or nil if there is no such object.
+func (o *pdfObjects) getObject(number int) *PDFobj {
+	if number < 1 || number > len(o.objects) {
+		return nil
+	}
+	return o.objects[number-1]
+}
+
+// getValue returns the value of the object - usually dictionary.
+func (o *pdfObjects) getValue(obj *PDFobj) any {
+	if value, ok := o.values[obj.number]; ok {
+		return value
+	}
+	var value any
+	tokens := obj.dict
+	if len(tokens) > 2 && tokens[2] == "obj" {
+		tokens = tokens[3:] // The objects read from PDF start with the object header
+	}
+	if len(tokens) > 0 {
+		// The tokens are joined and read again as single value.
+		for i, token := range tokens {
+			if token == "stream" || token == "endobj" {
+				tokens = tokens[:i]
+				break
+			}
+		}
+		value, _ = newPDFLexer([]byte(strings.Join(tokens, " "))).next()
+	}
+	o.values[obj.number] = value
+	return value
+}
+
+// resolve returns the value of the referenced object or the value itself if it is not reference.
+func (o *pdfObjects) resolve(value any) any {
+	for i := 0; i < 8; i++ {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			return value
+		}
+		obj := o.getObject(int(ref))
+		if obj == nil {
+			return nil
+		}
+		value = o.getValue(obj)
+	}
+	return nil
+}
+
+// getDict returns the resolved dictionary value or nil if the value is not dictionary.
+func (o *pdfObjects) getDict(value any) PDFDict {
+	dict, _ := o.resolve(value).(PDFDict)
+	return dict
+}
+
+// getArray returns the resolved array value or nil if the value is not array.
+func (o *pdfObjects) getArray(value any) []any {
+	array, _ := o.resolve(value).([]any)
+	return array
+}
+
+// getNumber returns the resolved number value or the default value if the value is not number.
+func (o *pdfObjects) getNumber(value any, defaultValue float64) float64 {
+	if number, ok := o.resolve(value).(float64); ok {
+		return number
+	}
+	return defaultValue
+}
+
+// getName returns the resolved name value or empty string if the value is not name.
+func (o *pdfObjects) getName(value any) string {
+	name, _ := o.resolve(value).(PDFName)
+	return string(name)
+}
+
+// getStreamData returns the decoded data of the referenced stream object.
+// The FlateDecode and LZWDecode filters with the PNG predictors, ASCIIHexDecode and ASCII85Decode
+// filters are supported - the content streams and the CMaps use no other filters.
+func (o *pdfObjects) getStreamData(value any) ([]byte, error) {
+	ref, ok := value.(pdfRef)
+	if !ok {
+		return nil, malformed("PDF", "stream is not indirect object")
+	}
+	obj := o.getObject(int(ref))
+	if obj == nil {
+		return nil, malformed("PDF", "missing stream object %d", ref)
+	}
+	dict, _ := o.getValue(obj).(PDFDict)
+	filters := []any{o.resolve(dict["Filter"])}
+	params := []any{o.resolve(dict["DecodeParms"])}
+	if array, ok := filters[0].([]any); ok {
+		filters = array
+		params, _ = params[0].([]any)
+	}
+	if len(filters) == 1 && filters[0] == PDFName("FlateDecode") && obj.getValue("/Filter") == "/FlateDecode" &&
+		params[0] == nil {
+		return obj.data, nil // Inflated when the PDF was read
+	}
+	data := obj.stream
+	for i, filter := range filters {
+		var param PDFDict
+		if i < len(params) {
+			param = o.getDict(params[i])
+		}
+		var err error
+		switch o.resolve(filter) {
+		case nil:
+			continue
+		case PDFName("FlateDecode"), PDFName("Fl"):
+			data, err = decompressor.InflateErr(data)
+			if err == nil {
+				data, err = o.decodePredictor(data, param)
+			}
+		case PDFName("LZWDecode"), PDFName("LZW"):
+			data, err = decodeLZW(data, o.getNumber(param["EarlyChange"], 1.0) != 0.0)
+			if err == nil {
+				data, err = o.decodePredictor(data, param)
+			}
+		case PDFName("ASCIIHexDecode"), PDFName("AHx"):
+			data, err = decodeASCIIHex(data)
+		case PDFName("ASCII85Decode"), PDFName("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, unsupported("stream filter %v", filter)
+		}
+		if err != nil {
+			return nil, malformedWrap("PDF", fmt.Sprintf("cannot decode stream %d", ref), err)
+		}
+	}
+	return data, nil
+}
+
+// decodePredictor reverses the PNG predictors of the FlateDecode and LZWDecode filters.
+// Please see page 27 in PDF32000_2008.pdf
+func (o *pdfObjects) decodePredictor(data []byte, param PDFDict) ([]byte, error) {
+	predictor := int(o.getNumber(param["Predictor"], 1.0))
+	if predictor == 1 {
+		return data, nil
+	}
+	if predictor < 10 {
+		return nil, unsupported("predictor %d", predictor)
+	}
+	colors := int(o.getNumber(param["Colors"], 1.0))
+	bitsPerComponent := int(o.getNumber(param["BitsPerComponent"], 8.0))
+	columns := int(o.getNumber(param["Columns"], 1.0))
+	if colors < 1 || bitsPerComponent < 1 || columns < 1 {
+		return nil, errors.New("bad predictor parameters")
+	}
+	bytesPerPixel := (colors*bitsPerComponent + 7) / 8
+	bytesPerLine := (colors*bitsPerComponent*columns + 7) / 8
+	rows := len(data) / (bytesPerLine + 1)
+	filters := make([]byte, rows)
+	image := make([]byte, rows*bytesPerLine)
+	for row := 0; row < rows; row++ {
+		filters[row] = data[row*(bytesPerLine+1)]
+		copy(image[row*bytesPerLine:], data[row*(bytesPerLine+1)+1:(row+1)*(bytesPerLine+1)])
+	}
+	applyFilters(filters, image, bytesPerLine/bytesPerPixel, rows, bytesPerPixel)
+	return image, nil
+}
+
+// decodeLZW decodes the data compressed with LZWDecode filter. The code width changes
+// one code early unless the EarlyChange parameter of the stream is 0.
+func decodeLZW(data []byte, earlyChange bool) ([]byte, error) {
+	const clearTable, endOfData = 256, 257
+	out := make([]byte, 0, 4*len(data))
+	table := make([][]byte, 258, 4096)
+	for i := 0; i < 256; i++ {
+		table[i] = []byte{byte(i)}
+	}
+	width := 9
+	bits, nbits := 0, 0
+	var prev []byte
+	for _, b := range data {
+		bits = bits<<8 | int(b)
+		nbits += 8
+		if nbits < width {
+			continue
+		}
+		code := (bits >> (nbits - width)) & (1<<width - 1)
+		nbits -= width
+		bits &= 1<<nbits - 1
+		switch {
+		case code == clearTable:
+			table = table[:258]
+			width = 9
+			prev = nil
+			continue
+		case code == endOfData:
+			return out, nil
+		}
+		var entry []byte
+		if code < len(table) {
+			entry = table[code]
+		} else if code == len(table) && prev != nil {
+			entry = append(append([]byte(nil), prev...), prev[0])
+		} else {
+			return nil, errors.New("invalid LZW code")
+		}
+		out = append(out, entry...)
+		if prev != nil && len(table) < 4096 {
+			table = append(table, append(append([]byte(nil), prev...), entry[0]))
+		}
+		prev = entry
+		n := len(table)
+		if earlyChange {
+			n++
+		}
+		if n >= 1<<width && width < 12 {
+			width++
+		}
+	}
+	return out, nil
+}
+
+// decodeASCIIHex decodes the data encoded with ASCIIHexDecode filter.
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	i := bytes.IndexByte(data, '>')
+	if i < 0 {
+		i = len(data)
+	}
+	return newPDFLexer(append(data[:i:i], '>')).readHexString()
+}
+
+// decodeASCII85 decodes the data encoded with ASCII85Decode filter.
+func decodeASCII85(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	group := make([]byte, 0, 5)
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case isPDFWhitespace(b):
+			continue
+		case b == '~':
+			i = len(data) // The end of data marker
+			continue
+		case b == 'z' && len(group) == 0:
+			out = append(out, 0, 0, 0, 0)
+			continue
+		case b < '!' || b > 'u':
+			return nil, errors.New("invalid ASCII85 character")
+		}
+		group = append(group, b)
+		if len(group) == 5 {
+			out = appendASCII85Group(out, group, 4)
+			group = group[:0]
+		}
+	}
+	if len(group) == 1 {
+		return nil, errors.New("invalid ASCII85 final group")
+	}
+	if n := len(group); n > 0 {
+		for len(group) < 5 {
+			group = append(group, 'u')
+		}
+		out = appendASCII85Group(out, group, n-1)
+	}
+	return out, nil
+}
+
+func appendASCII85Group(out, group []byte, n int) []byte {
+	var value uint32
+	for _, b := range group {
+		value = value*85 + uint32(b-'!')
+	}
+	for i := 0; i < n; i++ {
+		out = append(out, byte(value>>(24-8*i)))
+	}
+	return out
+}
+
+// getPageContents returns the content streams of the page joined together.
+func (o *pdfObjects) getPageContents(page PDFDict) ([]byte, error) {
+	contents := page["Contents"]
+	streams := []any{contents}
+	if array := o.getArray(contents); array != nil {
+		streams = array
+	}
+	var buf bytes.Buffer
+	for _, stream := range streams {
+		if stream == nil {
+			continue
+		}
+		data, err := o.getStreamData(stream)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n') // The streams are separated like the content of single stream.
+	}
+	return buf.Bytes(), nil
+}
+
+// getInherited returns the page attribute - Resources, MediaBox, CropBox or Rotate -
+// from the page or from the nearest page tree node that has it.
+func (o *pdfObjects) getInherited(page PDFDict, key string) any {
+	node := page
+	for i := 0; node != nil && i < 32; i++ {
+		if value, ok := node[key]; ok {
+			return value
+		}
+		node = o.getDict(node["Parent"])
+	}
+	return nil
+}
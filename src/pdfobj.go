@@ -26,6 +26,7 @@ SOFTWARE.
 
 import (
 	"fmt"
+	"hash/fnv"
 	"log"
 	"strconv"
 	"strings"
@@ -46,6 +47,7 @@ type PDFobj struct {
 	stream       []byte   // The compressed stream
 	data         []byte   // The decompressed data
 	gsNumber     int      // Graphics State Number
+	updated      bool     // Read for incremental update - the original bytes stay in the file
 }
 
 // NewPDFobj is used to create Java or .NET objects that represent the objects in PDF document.
@@ -76,18 +78,60 @@ func (obj *PDFobj) GetData() []byte {
 }
 
 // SetStreamAndData sets the object stream.
+// The program exits if the stream is malformed. Use SetStreamAndDataErr to handle the error.
 func (obj *PDFobj) SetStreamAndData(buf []byte, length int) {
+	if err := obj.SetStreamAndDataErr(buf, length); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// SetStreamAndDataErr sets the object stream.
+// Returns MalformedInputError if the stream extends past the end of the buffer or cannot be inflated.
+func (obj *PDFobj) SetStreamAndDataErr(buf []byte, length int) error {
+	if err := obj.setStream(buf, length); err != nil {
+		return err
+	}
+	return obj.setData()
+}
+
+// setStream sets the raw object stream.
+func (obj *PDFobj) setStream(buf []byte, length int) error {
+	if length < 0 || obj.streamOffset+length > len(buf) {
+		return malformed("PDF", "stream of object %d extends past the end of file", obj.number)
+	}
 	obj.stream = make([]byte, length)
 	for i := 0; i < length; i++ {
 		obj.stream[i] = buf[obj.streamOffset+i]
 	}
+	return nil
+}
+
+// setData sets the uncompressed stream data.
+func (obj *PDFobj) setData() error {
 	if obj.getValue("/Filter") == "/FlateDecode" {
-		obj.data = decompressor.Inflate(obj.stream)
+		data, err := decompressor.InflateErr(obj.stream)
+		if err != nil {
+			return malformedWrap("PDF", "cannot inflate stream", err)
+		}
+		obj.data = data
 	} else {
 		// Assume no compression for now.
 		// In the future we may handle LZW compression ...
 		obj.data = obj.stream
 	}
+	return nil
+}
+
+// checksum returns hash of the object dictionary and stream.
+// Used to find the objects changed after they were read.
+func (obj *PDFobj) checksum() uint64 {
+	h := fnv.New64a()
+	for _, token := range obj.dict {
+		h.Write([]byte(token))
+		h.Write([]byte{0})
+	}
+	h.Write(obj.stream)
+	return h.Sum64()
 }
 
 // SetStream sets the object stream.
@@ -136,23 +180,40 @@ func (obj *PDFobj) getValue(key string) string {
 }
 
 // GetObjectNumbers returns the object numbers.
+// The program exits if the references are malformed. Use GetObjectNumbersErr to handle the error.
 func (obj *PDFobj) GetObjectNumbers(key string) []int {
+	numbers, err := obj.GetObjectNumbersErr(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return numbers
+}
+
+// GetObjectNumbersErr returns the object numbers.
+// Returns MalformedInputError if the references are malformed.
+func (obj *PDFobj) GetObjectNumbersErr(key string) ([]int, error) {
 	numbers := make([]int, 0)
 	for i := 0; i < len(obj.dict); i++ {
 		token := obj.dict[i]
 		if token == key {
 			i++
+			if i >= len(obj.dict) {
+				return nil, malformed("PDF", "missing value of %s", key)
+			}
 			str := obj.dict[i]
 			if str == "[" {
 				for {
 					i++
+					if i >= len(obj.dict) {
+						return nil, malformed("PDF", "unterminated %s array", key)
+					}
 					str = obj.dict[i]
 					if str == "]" {
 						break
 					}
 					objNumber, err := strconv.Atoi(str)
 					if err != nil {
-						log.Fatal(err)
+						return nil, malformedWrap("PDF", "bad "+key+" reference", err)
 					}
 					numbers = append(numbers, objNumber)
 					i++ // 0
@@ -161,108 +222,233 @@ func (obj *PDFobj) GetObjectNumbers(key string) []int {
 			} else {
 				objNumber, err := strconv.Atoi(str)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("PDF", "bad "+key+" reference", err)
 				}
 				numbers = append(numbers, objNumber)
 			}
 			break
 		}
 	}
-	return numbers
+	return numbers, nil
+}
+
+// insertEntries inserts the key value pairs at the start of the dictionary.
+func (obj *PDFobj) insertEntries(entries ...string) {
+	for i, token := range obj.dict {
+		if token == "<<" {
+			obj.dict = insertArrayAt(obj.dict, entries, i+1)
+			return
+		}
+	}
+}
+
+// setValue sets the value of the specified key.
+// The key is added to the dictionary if missing.
+func (obj *PDFobj) setValue(key, value string) {
+	for i, token := range obj.dict {
+		if token == key {
+			obj.dict[i+1] = value
+			return
+		}
+	}
+	obj.insertEntries(key, value)
+}
+
+// addReference adds indirect reference to the array value of the specified key.
+// The array can be direct or indirect. New array is added if the key is missing.
+func (obj *PDFobj) addReference(key string, number int, objects []*PDFobj) {
+	ref := []string{strconv.Itoa(number), "0", "R"}
+	for i, token := range obj.dict {
+		if token == key {
+			array := obj
+			j := i + 1
+			if obj.dict[j] != "[" {
+				index, err := strconv.Atoi(obj.dict[j])
+				if err != nil {
+					break
+				}
+				array = objects[index-1]
+				j = 0
+			}
+			for j < len(array.dict) && array.dict[j] != "]" {
+				j++
+			}
+			if j < len(array.dict) {
+				array.dict = insertArrayAt(array.dict, ref, j)
+				return
+			}
+			break
+		}
+	}
+	obj.insertEntries(append(append([]string{key, "["}, ref...), "]")...)
+}
+
+// getAcroForm returns the interactive form dictionary of the document catalog.
+// Direct dictionary is moved to new object and the form is created if missing.
+func (obj *PDFobj) getAcroForm(objects *[]*PDFobj) *PDFobj {
+	for i, token := range obj.dict {
+		if token == "/AcroForm" {
+			if obj.dict[i+1] != "<<" {
+				number, err := strconv.Atoi(obj.dict[i+1])
+				if err == nil {
+					return (*objects)[number-1]
+				}
+				break
+			}
+			level := 0
+			j := i + 1
+			for ; j < len(obj.dict); j++ {
+				if obj.dict[j] == "<<" {
+					level++
+				} else if obj.dict[j] == ">>" {
+					level--
+					if level == 0 {
+						break
+					}
+				}
+			}
+			form := NewPDFobj()
+			form.dict = append(form.dict, obj.dict[i+1:j+1]...)
+			form.number = len(*objects) + 1
+			*objects = append(*objects, form)
+			dict := append([]string{}, obj.dict[:i+1]...)
+			dict = append(dict, strconv.Itoa(form.number), "0", "R")
+			obj.dict = append(dict, obj.dict[j+1:]...)
+			return form
+		}
+	}
+	form := NewPDFobj()
+	form.dict = append(form.dict, "<<", ">>")
+	form.number = len(*objects) + 1
+	*objects = append(*objects, form)
+	obj.insertEntries("/AcroForm", strconv.Itoa(form.number), "0", "R")
+	return form
 }
 
 // GetPageSize returns the page size.
+// The program exits if the MediaBox is malformed. Use GetPageSizeErr to handle the error.
 func (obj *PDFobj) GetPageSize() [2]float32 {
+	size, err := obj.GetPageSizeErr()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return size
+}
+
+// GetPageSizeErr returns the page size.
+// Returns MalformedInputError if the MediaBox is malformed.
+func (obj *PDFobj) GetPageSizeErr() ([2]float32, error) {
 	for i := 0; i < len(obj.dict); i++ {
 		if obj.dict[i] == "/MediaBox" {
+			if i+5 >= len(obj.dict) {
+				return [2]float32{}, malformed("PDF", "truncated /MediaBox")
+			}
 			f1, err1 := strconv.ParseFloat(obj.dict[i+4], 32)
 			if err1 != nil {
-				log.Fatal(err1)
+				return [2]float32{}, malformedWrap("PDF", "bad /MediaBox", err1)
 			}
 			f2, err2 := strconv.ParseFloat(obj.dict[i+5], 32)
 			if err2 != nil {
-				log.Fatal(err2)
+				return [2]float32{}, malformedWrap("PDF", "bad /MediaBox", err2)
 			}
-			return [2]float32{float32(f1), float32(f2)}
+			return [2]float32{float32(f1), float32(f2)}, nil
 		}
 	}
-	return letter.Portrait
+	return letter.Portrait, nil
 }
 
 // GetLength return the length value.
+// The program exits if the /Length is malformed. Use GetLengthErr to handle the error.
 func (obj *PDFobj) GetLength(objects []*PDFobj) int {
+	length, err := obj.GetLengthErr(objects)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return length
+}
+
+// GetLengthErr return the length value.
+// Returns MalformedInputError if the /Length is malformed.
+func (obj *PDFobj) GetLengthErr(objects []*PDFobj) (int, error) {
 	for i := 0; i < len(obj.dict); i++ {
 		token := obj.dict[i]
 		if token == "/Length" {
 			number, err := strconv.Atoi(obj.dict[i+1])
 			if err != nil {
-				log.Fatal(err)
+				return 0, malformedWrap("PDF", "bad /Length", err)
 			}
 			if obj.dict[i+2] == "0" &&
 				obj.dict[i+3] == "R" {
 				return obj.getLength(objects, number)
 			}
-			return number
+			return number, nil
 		}
 	}
-	return 0
+	return 0, nil
 }
 
-func (obj *PDFobj) getLength(objects []*PDFobj, number int) int {
+func (obj *PDFobj) getLength(objects []*PDFobj, number int) (int, error) {
 	for _, obj := range objects {
 		if obj.number == number {
 			length, err := strconv.Atoi(obj.dict[3])
 			if err != nil {
-				log.Fatal(obj.dict[3])
+				return 0, malformedWrap("PDF", "bad indirect /Length", err)
 			}
-			return length
+			return length, nil
 		}
 	}
-	return 0
+	return 0, nil
 }
 
 // GetContentsObject returns the contect object.
+// The program exits if the /Contents reference is malformed. Use GetContentsObjectErr to handle the error.
 func (obj *PDFobj) GetContentsObject(objects []*PDFobj) *PDFobj {
-	for i := 0; i < len(obj.dict); i++ {
+	contents, err := obj.GetContentsObjectErr(objects)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return contents
+}
+
+// GetContentsObjectErr returns the contect object.
+// Returns MalformedInputError if the /Contents reference is malformed.
+func (obj *PDFobj) GetContentsObjectErr(objects []*PDFobj) (*PDFobj, error) {
+	for i := 0; i < len(obj.dict)-1; i++ {
 		if obj.dict[i] == "/Contents" {
-			if obj.dict[i+1] == "[" {
-				token := obj.dict[i+2]
-				index, err := strconv.Atoi(token)
-				if err != nil {
-					log.Fatal(err)
-				}
-				return objects[index-1]
+			if obj.dict[i+1] == "[" && i+2 < len(obj.dict) {
+				return getReferencedObject(obj.dict[i+2], objects)
 			}
-			token := obj.dict[i+1]
-			index, err := strconv.Atoi(token)
-			if err != nil {
-				log.Fatal(err)
-			}
-			return objects[index-1]
+			return getReferencedObject(obj.dict[i+1], objects)
 		}
 	}
-	return nil
+	return nil, nil
 }
 
-func (obj *PDFobj) getResourcesObject(objects []*PDFobj) *PDFobj {
-	for i, token := range obj.dict {
-		if token == "/Resources" {
-			token = obj.dict[i+1]
-			if token == "<<" {
-				return obj
-			}
-			objNumber, err := strconv.Atoi(token)
-			if err != nil {
-				log.Fatal(err)
+// getReferencedObject returns the object with the number given by the token.
+// Returns MalformedInputError if the token is not the number of one of the objects.
+func getReferencedObject(token string, objects []*PDFobj) (*PDFobj, error) {
+	number, err := strconv.Atoi(token)
+	if err != nil || number < 1 || number > len(objects) {
+		return nil, malformed("PDF", "bad object reference %q", token)
+	}
+	return objects[number-1], nil
+}
+
+func (obj *PDFobj) getResourcesObject(objects []*PDFobj) (*PDFobj, error) {
+	for i := 0; i < len(obj.dict)-1; i++ {
+		if obj.dict[i] == "/Resources" {
+			if obj.dict[i+1] == "<<" {
+				return obj, nil
 			}
-			return objects[objNumber-1]
+			return getReferencedObject(obj.dict[i+1], objects)
 		}
 	}
-	return nil
+	return nil, nil
 }
 
-func (obj *PDFobj) addCoreFontResource(coreFont *corefont.CoreFont, objects *[]*PDFobj) *Font {
-	font := NewCoreFontForPDFobj(coreFont)
+func (obj *PDFobj) addCoreFontResource(coreFont *corefont.CoreFont, objects *[]*PDFobj) (font *Font, err error) {
+	defer recoverMalformedInput("PDF", &err)
+	font = NewCoreFontForPDFobj(coreFont)
 	font.fontID = strings.ToUpper(strings.ReplaceAll(font.name, "-", "_"))
 	obj2 := NewPDFobj()
 	obj2.dict = append(obj2.dict, "<<")
@@ -285,22 +471,25 @@ func (obj *PDFobj) addCoreFontResource(coreFont *corefont.CoreFont, objects *[]*
 			i++
 			token := obj.dict[i]
 			if token == "<<" { // Direct resources object
-				obj.addFontResource(obj, objects, font.fontID, obj2.number)
+				err = obj.addFontResource(obj, objects, font.fontID, obj2.number)
 			} else if unicode.IsDigit(rune(token[0])) { // Indirect resources object
-				objNumber, err := strconv.Atoi(token)
-				if err != nil {
-					log.Fatal(err)
+				var resources *PDFobj
+				resources, err = getReferencedObject(token, *objects)
+				if err == nil {
+					err = obj.addFontResource(resources, objects, font.fontID, obj2.number)
 				}
-				obj.addFontResource((*objects)[objNumber-1], objects, font.fontID, obj2.number)
+			}
+			if err != nil {
+				return nil, err
 			}
 		}
 	}
 
-	return font
+	return font, nil
 }
 
 // addFontResource adds font resource.
-func (obj *PDFobj) addFontResource(obj2 *PDFobj, objects *[]*PDFobj, fontID string, number int) {
+func (obj *PDFobj) addFontResource(obj2 *PDFobj, objects *[]*PDFobj, fontID string, number int) error {
 	fonts := false
 	for _, token := range obj2.dict {
 		if token == "/Font" {
@@ -327,25 +516,25 @@ func (obj *PDFobj) addFontResource(obj2 *PDFobj, objects *[]*PDFobj, fontID stri
 				obj2.dict = insertStringAt(obj2.dict, strconv.Itoa(number), i+3)
 				obj2.dict = insertStringAt(obj2.dict, "0", i+4)
 				obj2.dict = insertStringAt(obj2.dict, "R", i+5)
-				return
+				return nil
 			} else if unicode.IsDigit(rune(token[0])) {
-				index, err := strconv.Atoi(token)
+				obj3, err := getReferencedObject(token, *objects)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
-				obj3 := (*objects)[index-1]
 				for j := 0; j < len(obj3.dict); j++ {
 					if obj3.dict[j] == "<<" {
 						obj3.dict = insertStringAt(obj3.dict, "/"+fontID, j+1)
 						obj3.dict = insertStringAt(obj3.dict, strconv.Itoa(number), j+2)
 						obj3.dict = insertStringAt(obj3.dict, "0", j+3)
 						obj3.dict = insertStringAt(obj3.dict, "R", j+4)
-						return
+						return nil
 					}
 				}
 			}
 		}
 	}
+	return nil
 }
 
 func insertNewObject(dict, list []string, objType string) []string {
@@ -366,7 +555,7 @@ func insertNewObject(dict, list []string, objType string) []string {
 	return dict
 }
 
-func addResource(objType string, obj *PDFobj, objects *[]*PDFobj, objNumber int) {
+func addResource(objType string, obj *PDFobj, objects *[]*PDFobj, objNumber int) error {
 	tag := "/Im"
 	if objType == "/Font" {
 		tag = "/F"
@@ -381,13 +570,13 @@ func addResource(objType string, obj *PDFobj, objects *[]*PDFobj, objNumber int)
 			if token == "<<" {
 				obj.dict = insertNewObject(obj.dict, list, objType)
 			} else {
-				objNumber, err := strconv.Atoi(token)
+				obj2, err := getReferencedObject(token, *objects)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
-				obj.dict = insertNewObject((*objects)[objNumber-1].dict, list, objType)
+				obj.dict = insertNewObject(obj2.dict, list, objType)
 			}
-			return
+			return nil
 		}
 	}
 
@@ -396,56 +585,78 @@ func addResource(objType string, obj *PDFobj, objects *[]*PDFobj, objNumber int)
 	for i, token := range obj.dict {
 		if token == "/Resources" {
 			obj.dict = insertArrayAt(obj.dict, list, i+2)
-			return
+			return nil
 		}
 	}
 	for i, token := range obj.dict {
 		if token == "<<" {
 			obj.dict = insertArrayAt(obj.dict, list, i+1)
-			return
+			return nil
 		}
 	}
+	return nil
 }
 
 // AddImageResource adds an image resource.
+// The program exits if the resources reference is malformed. Use AddImageResourceErr to handle the error.
 func (obj *PDFobj) AddImageResource(image *Image, objects *[]*PDFobj) {
+	if err := obj.AddImageResourceErr(image, objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// AddImageResourceErr adds an image resource.
+// Returns MalformedInputError if the resources reference is malformed.
+func (obj *PDFobj) AddImageResourceErr(image *Image, objects *[]*PDFobj) (err error) {
+	defer recoverMalformedInput("PDF", &err)
 	for i, token := range obj.dict {
 		if token == "/Resources" {
 			token = obj.dict[i+1]
 			if token == "<<" { // Direct resources object
-				addResource("/XObject", obj, objects, image.objNumber)
-			} else { // Indirect resources object
-				objNumber, err := strconv.Atoi(token)
-				if err != nil {
-					log.Fatal(err)
-				}
-				addResource("/XObject", (*objects)[objNumber-1], objects, image.objNumber)
+				return addResource("/XObject", obj, objects, image.objNumber)
 			}
-			return
+			// Indirect resources object
+			resources, err := getReferencedObject(token, *objects)
+			if err != nil {
+				return err
+			}
+			return addResource("/XObject", resources, objects, image.objNumber)
 		}
 	}
+	return nil
 }
 
 // AddFontResource adds font resource.
+// The program exits if the resources reference is malformed. Use AddFontResourceErr to handle the error.
 func (obj *PDFobj) AddFontResource(font *Font, objects *[]*PDFobj) {
+	if err := obj.AddFontResourceErr(font, objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// AddFontResourceErr adds font resource.
+// Returns MalformedInputError if the resources reference is malformed.
+func (obj *PDFobj) AddFontResourceErr(font *Font, objects *[]*PDFobj) (err error) {
+	defer recoverMalformedInput("PDF", &err)
 	for i, token := range obj.dict {
 		if token == "/Resources" {
 			token = obj.dict[i+1]
 			if token == "<<" { // Direct resources object
-				addResource("/Font", obj, objects, font.objNumber)
-			} else { // Indirect resources object
-				objNumber, err := strconv.Atoi(token)
-				if err != nil {
-					log.Fatal(err)
-				}
-				addResource("/Font", (*objects)[objNumber-1], objects, font.objNumber)
+				return addResource("/Font", obj, objects, font.objNumber)
 			}
-			return
+			// Indirect resources object
+			resources, err := getReferencedObject(token, *objects)
+			if err != nil {
+				return err
+			}
+			return addResource("/Font", resources, objects, font.objNumber)
 		}
 	}
+	return nil
 }
 
-func (obj *PDFobj) addContent(content []byte, objects *[]*PDFobj) {
+func (obj *PDFobj) addContent(content []byte, objects *[]*PDFobj) (err error) {
+	defer recoverMalformedInput("PDF", &err)
 	obj2 := NewPDFobj()
 	obj2.SetNumber(len(*objects) + 1)
 	obj2.SetStream(content)
@@ -465,17 +676,16 @@ func (obj *PDFobj) addContent(content []byte, objects *[]*PDFobj) {
 						obj.dict = insertStringAt(obj.dict, "R", i)
 						obj.dict = insertStringAt(obj.dict, "0", i)
 						obj.dict = insertStringAt(obj.dict, objNumber, i)
-						return
+						return nil
 					}
 					i += 2 // Skip the 0 and R
 				}
 			} else {
 				// Single content object
-				index, err := strconv.Atoi(token)
+				obj3, err := getReferencedObject(token, *objects)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
-				obj3 := (*objects)[index-1]
 				if obj3.data == nil && obj3.stream == nil {
 					// This is not a stream object!
 					for j := 0; j < len(obj3.dict); j++ {
@@ -483,7 +693,7 @@ func (obj *PDFobj) addContent(content []byte, objects *[]*PDFobj) {
 							obj3.dict = insertStringAt(obj3.dict, "R", j)
 							obj3.dict = insertStringAt(obj3.dict, "0", j)
 							obj3.dict = insertStringAt(obj3.dict, objNumber, j)
-							return
+							return nil
 						}
 					}
 				}
@@ -492,10 +702,11 @@ func (obj *PDFobj) addContent(content []byte, objects *[]*PDFobj) {
 				obj.dict = insertStringAt(obj.dict, "R", i+4)
 				obj.dict = insertStringAt(obj.dict, "0", i+4)
 				obj.dict = insertStringAt(obj.dict, objNumber, i+4)
-				return
+				return nil
 			}
 		}
 	}
+	return nil
 }
 
 /**
@@ -506,7 +717,7 @@ func (obj *PDFobj) addContent(content []byte, objects *[]*PDFobj) {
  * @param content
  * @param objects
  */
-func (obj *PDFobj) addPrefixContent(content []byte, objects *[]*PDFobj) {
+func (obj *PDFobj) addPrefixContent(content []byte, objects *[]*PDFobj) error {
 	obj2 := NewPDFobj()
 	obj2.SetNumber(len(*objects) + 1)
 	obj2.SetStream(content)
@@ -523,14 +734,13 @@ func (obj *PDFobj) addPrefixContent(content []byte, objects *[]*PDFobj) {
 				obj.dict = insertStringAt(obj.dict, "R", i)
 				obj.dict = insertStringAt(obj.dict, "0", i)
 				obj.dict = insertStringAt(obj.dict, objNumber, i)
-				return
+				return nil
 			}
 			// Single content object
-			index, err := strconv.Atoi(token)
+			obj3, err := getReferencedObject(token, *objects)
 			if err != nil {
-				log.Fatal(err)
+				return err
 			}
-			obj3 := (*objects)[index-1]
 			if obj3.data == nil && obj3.stream == nil {
 				// This is not a stream object!
 				for j := 0; j < len(obj3.dict); j++ {
@@ -539,7 +749,7 @@ func (obj *PDFobj) addPrefixContent(content []byte, objects *[]*PDFobj) {
 						obj3.dict = insertStringAt(obj3.dict, "R", j)
 						obj3.dict = insertStringAt(obj3.dict, "0", j)
 						obj3.dict = insertStringAt(obj3.dict, objNumber, j)
-						return
+						return nil
 					}
 				}
 			}
@@ -549,9 +759,10 @@ func (obj *PDFobj) addPrefixContent(content []byte, objects *[]*PDFobj) {
 			obj.dict = insertStringAt(obj.dict, "R", i)
 			obj.dict = insertStringAt(obj.dict, "0", i)
 			obj.dict = insertStringAt(obj.dict, objNumber, i)
-			return
+			return nil
 		}
 	}
+	return nil
 }
 
 func getMaxGSNumber(obj *PDFobj) int {
@@ -560,7 +771,7 @@ func getMaxGSNumber(obj *PDFobj) int {
 		if strings.HasPrefix(token, "/GS") {
 			number, err := strconv.Atoi(token[3:])
 			if err != nil {
-				log.Fatal(err)
+				continue // Not one of the /GSn names
 			}
 			numbers = append(numbers, number)
 		}
@@ -580,7 +791,17 @@ func getMaxGSNumber(obj *PDFobj) int {
 }
 
 // SetGraphicsState sets the graphics state.
+// The program exits if the resources reference is malformed. Use SetGraphicsStateErr to handle the error.
 func (obj *PDFobj) SetGraphicsState(gs *GraphicsState, objects *[]*PDFobj) {
+	if err := obj.SetGraphicsStateErr(gs, objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// SetGraphicsStateErr sets the graphics state.
+// Returns MalformedInputError if the resources reference is malformed.
+func (obj *PDFobj) SetGraphicsStateErr(gs *GraphicsState, objects *[]*PDFobj) (err error) {
+	defer recoverMalformedInput("PDF", &err)
 	var obj2 *PDFobj
 	index := -1
 	for i, token := range obj.dict {
@@ -590,11 +811,10 @@ func (obj *PDFobj) SetGraphicsState(gs *GraphicsState, objects *[]*PDFobj) {
 				obj2 = obj
 				index = i + 2
 			} else {
-				index2, err := strconv.Atoi(token2)
+				obj2, err = getReferencedObject(token2, *objects)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
-				obj2 = (*objects)[index2-1]
 				for j := 0; j < len(obj2.dict); j++ {
 					if obj2.dict[j] == "<<" {
 						index = j + 1
@@ -606,7 +826,7 @@ func (obj *PDFobj) SetGraphicsState(gs *GraphicsState, objects *[]*PDFobj) {
 		}
 	}
 	if obj == nil || index == -1 {
-		return
+		return nil
 	}
 	gsNumber := getMaxGSNumber(obj)
 	if gsNumber == 0 { // No existing ExtGState dictionary
@@ -645,5 +865,5 @@ func (obj *PDFobj) SetGraphicsState(gs *GraphicsState, objects *[]*PDFobj) {
 	var buf strings.Builder
 	buf.WriteString("q\n")
 	buf.WriteString("/GS" + strconv.Itoa(gsNumber+1) + " gs\n")
-	obj.addPrefixContent([]byte(buf.String()), objects)
+	return obj.addPrefixContent([]byte(buf.String()), objects)
 }
@@ -0,0 +1,40 @@
+package permission
+
+/**
+ * permission.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Constants used to specify the user access permissions of encrypted PDF.
+// Combine them using the | operator. See PDF.SetEncryption.
+const (
+	Print            = 1 << 2  // Print the document
+	Modify           = 1 << 3  // Modify the contents of the document
+	Copy             = 1 << 4  // Copy or extract text and graphics
+	Annotate         = 1 << 5  // Add or modify annotations and fill in form fields
+	FillForms        = 1 << 8  // Fill in existing form fields
+	Extract          = 1 << 9  // Extract text and graphics for accessibility
+	Assemble         = 1 << 10 // Insert, rotate or delete pages and create bookmarks
+	PrintHighQuality = 1 << 11 // Print in high quality
+	All              = Print | Modify | Copy | Annotate | FillForms | Extract | Assemble | PrintHighQuality
+	None             = 0
+)
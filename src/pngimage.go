@@ -65,15 +65,33 @@ type PNGImage struct {
 }
 
 // NewPNGImage is used to embed PNG images in a PDF document.
+// The program exits if the image cannot be decoded. Use NewPNGImageErr to handle the error.
 func NewPNGImage(reader io.Reader) *PNGImage {
+	image, err := NewPNGImageErr(reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return image
+}
+
+// NewPNGImageErr is used to embed PNG images in a PDF document.
+// Returns MalformedInputError or UnsupportedFeatureError when the image cannot be decoded.
+func NewPNGImageErr(reader io.Reader) (_ *PNGImage, err error) {
+	defer recoverMalformedInput("PNG image", &err)
+
 	image := new(PNGImage)
 	image.bitDepth = 8
 	image.colorType = 0
 	image.iDAT = make([]byte, 0)
 
-	image.validatePNG(reader)
+	if err := image.validatePNG(reader); err != nil {
+		return nil, err
+	}
 
-	chunks := image.processPNG(reader)
+	chunks, err := image.processPNG(reader)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, chunk := range chunks {
 		chunkType := string(chunk.ChunkType)
@@ -101,7 +119,7 @@ func NewPNGImage(reader io.Reader) *PNGImage {
 		case "PLTE":
 			image.pLTE = chunk.ChunkData
 			if len(image.pLTE)%3 != 0 {
-				log.Fatal("Incorrect palette length.")
+				return nil, malformed("PNG image", "incorrect palette length")
 			}
 		case "gAMA":
 			// fmt.Println("gAMA chunk found!")
@@ -119,7 +137,10 @@ func NewPNGImage(reader io.Reader) *PNGImage {
 	}
 
 	// Decompress the IDAT chunk data.
-	inflatedIDAT := decompressor.Inflate(image.iDAT)
+	inflatedIDAT, err := decompressor.InflateErr(image.iDAT)
+	if err != nil {
+		return nil, malformedWrap("PNG image", "bad IDAT data", err)
+	}
 
 	var imageData []byte
 	switch image.colorType {
@@ -137,13 +158,13 @@ func NewPNGImage(reader io.Reader) *PNGImage {
 		case 1:
 			imageData = image.getImageColorType0BitDepth1(inflatedIDAT)
 		default:
-			log.Fatal("Image with unsupported bit depth == " + fmt.Sprint(image.bitDepth))
+			return nil, unsupported("PNG image with bit depth == %d", image.bitDepth)
 		}
 	case 6:
 		if image.bitDepth == 8 {
 			imageData = image.getImageColorType6BitDepth8(inflatedIDAT)
 		} else {
-			log.Fatal("Image with unsupported bit depth == " + fmt.Sprint(image.bitDepth))
+			return nil, unsupported("PNG image with bit depth == %d", image.bitDepth)
 		}
 	default:
 		// Color Image
@@ -166,7 +187,7 @@ func NewPNGImage(reader io.Reader) *PNGImage {
 			case 1:
 				imageData = image.getImageColorType3BitDepth1(inflatedIDAT)
 			default:
-				log.Fatal("Image with unsupported bit depth == " + fmt.Sprint(image.bitDepth))
+				return nil, unsupported("PNG image with bit depth == %d", image.bitDepth)
 			}
 		}
 	}
@@ -174,7 +195,7 @@ func NewPNGImage(reader io.Reader) *PNGImage {
 	// Compress the reconstructed image data.
 	image.deflatedImageData = compressor.Deflate(imageData)
 
-	return image
+	return image, nil
 }
 
 // GetWidth returns the width of the image.
@@ -207,22 +228,25 @@ func (image *PNGImage) GetAlpha() []byte {
 	return image.deflatedAlphaData
 }
 
-func (image *PNGImage) processPNG(reader io.Reader) []*Chunk {
+func (image *PNGImage) processPNG(reader io.Reader) ([]*Chunk, error) {
 	chunks := make([]*Chunk, 0)
 	for {
-		chunk := image.getChunk(reader)
+		chunk, err := image.getChunk(reader)
+		if err != nil {
+			return nil, err
+		}
 		if string(chunk.ChunkType) == "IEND" {
 			break
 		}
 		chunks = append(chunks, chunk)
 	}
-	return chunks
+	return chunks, nil
 }
 
-func (image *PNGImage) validatePNG(reader io.Reader) {
+func (image *PNGImage) validatePNG(reader io.Reader) error {
 	buf := make([]byte, 8)
 	if _, err := io.ReadFull(reader, buf); err != nil {
-		log.Fatal("File is too short!")
+		return malformedWrap("PNG image", "file is too short", err)
 	}
 	if ((buf[0] & 0xFF) == 0x89) &&
 		buf[1] == 0x50 &&
@@ -234,11 +258,12 @@ func (image *PNGImage) validatePNG(reader io.Reader) {
 		buf[7] == 0x0A {
 		// The PNG signature is correct.
 	} else {
-		log.Fatal("Wrong PNG signature.")
+		return malformed("PNG image", "wrong PNG signature")
 	}
+	return nil
 }
 
-func (image *PNGImage) getChunk(reader io.Reader) *Chunk {
+func (image *PNGImage) getChunk(reader io.Reader) (*Chunk, error) {
 	chunk := NewChunk()
 	chunk.ChunkLength = getUint32(reader)                       // The length of the data chunk.
 	chunk.ChunkType = getNBytes(reader, 4)                      // The chunk type.
@@ -249,9 +274,9 @@ func (image *PNGImage) getChunk(reader io.Reader) *Chunk {
 	crc32.Update(chunk.ChunkType)
 	crc32.Update(chunk.ChunkData)
 	if crc32.GetValue() != chunk.ChunkCRC {
-		log.Fatal("PNGImage chunk has bad CRC.")
+		return nil, malformed("PNG image", "chunk has bad CRC")
 	}
-	return chunk
+	return chunk, nil
 }
 
 func toUint32(buf []byte, off int) uint32 {
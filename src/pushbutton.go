@@ -0,0 +1,126 @@
+package pdfjet
+
+/**
+ * pushbutton.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"github.com/edragoev1/pdfjet/src/align"
+	"github.com/edragoev1/pdfjet/src/color"
+)
+
+// PushButton is interactive button that opens URI, submits or resets the form when clicked.
+// The button is written as widget annotation with appearance stream by PDF.Complete.
+type PushButton struct {
+	interactiveField
+	caption string
+	uri     *string
+	submit  *string
+	reset   bool
+}
+
+// NewPushButton constructs push button with the specified font, field name and caption.
+func NewPushButton(font *Font, name, caption string) *PushButton {
+	button := new(PushButton)
+	button.init(font, name)
+	button.flags = fieldPushButton
+	button.caption = caption
+	button.w = font.stringWidth(caption) + 12.0
+	button.h = font.bodyHeight + 8.0
+	button.backgroundColor = color.LightGray
+	return button
+}
+
+// SetLocation sets the location of the top left corner of the button on the page.
+func (button *PushButton) SetLocation(x, y float32) *PushButton {
+	button.x = x
+	button.y = y
+	return button
+}
+
+// SetSize sets the width and height of the button.
+func (button *PushButton) SetSize(w, h float32) *PushButton {
+	button.w = w
+	button.h = h
+	return button
+}
+
+// SetURIAction sets the URI opened when the button is clicked.
+func (button *PushButton) SetURIAction(uri *string) *PushButton {
+	button.uri = uri
+	return button
+}
+
+// SetSubmitAction sets the URL the form fields are submitted to in HTML form format when the button is clicked.
+func (button *PushButton) SetSubmitAction(url *string) *PushButton {
+	button.submit = url
+	return button
+}
+
+// SetResetAction sets the button to reset the form fields to their default values when clicked.
+func (button *PushButton) SetResetAction() *PushButton {
+	button.reset = true
+	return button
+}
+
+// SetToolTip sets the text shown by the viewers when the mouse is over the button.
+// The text is also used as alternate description of the button.
+func (button *PushButton) SetToolTip(toolTip string) *PushButton {
+	button.toolTip = toolTip
+	return button
+}
+
+// SetBorderColor sets the color of the border. Use color.Transparent for no border.
+func (button *PushButton) SetBorderColor(borderColor int32) *PushButton {
+	button.borderColor = borderColor
+	return button
+}
+
+// SetBackgroundColor sets the background color. The default is color.LightGray.
+func (button *PushButton) SetBackgroundColor(backgroundColor int32) *PushButton {
+	button.backgroundColor = backgroundColor
+	return button
+}
+
+// SetTextColor sets the color of the caption.
+func (button *PushButton) SetTextColor(textColor int32) *PushButton {
+	button.textColor = textColor
+	return button
+}
+
+// DrawOn adds the button to the page and returns the coordinates of the bottom right corner.
+func (button *PushButton) DrawOn(page *Page) [2]float32 {
+	w := button.newWidget("Btn")
+	w.caption = button.caption
+	w.uri = button.uri
+	w.submit = button.submit
+	w.reset = button.reset
+
+	appearance := button.newAppearance(page.pdf)
+	button.drawLine(appearance, button.caption, align.Center, button.getCenteredBaseline())
+	w.normal = page.pdf.addAppearanceStream(appearance, button.font)
+
+	button.addWidget(page, w, button.x, button.y, button.w, button.h)
+	page.pdf.formFields = append(page.pdf.formFields, w)
+	return [2]float32{button.x + button.w, button.y + button.h}
+}
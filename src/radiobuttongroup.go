@@ -0,0 +1,166 @@
+package pdfjet
+
+/**
+ * radiobuttongroup.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"github.com/edragoev1/pdfjet/src/color"
+)
+
+// RadioButtonGroup is group of interactive radio buttons - only one of them can be selected.
+// The group is written as field with the widget annotations of the buttons as kids by PDF.Complete.
+type RadioButtonGroup struct {
+	interactiveField
+	values    []string
+	locations [][2]float32
+	selected  string
+	markColor int32
+}
+
+// NewRadioButtonGroup constructs group of radio buttons with the specified field name.
+func NewRadioButtonGroup(name string) *RadioButtonGroup {
+	group := new(RadioButtonGroup)
+	group.init(nil, name)
+	group.w = 12.0
+	group.h = 12.0
+	group.markColor = color.Black
+	return group
+}
+
+// AddButton adds radio button with the top left corner at the specified location.
+// The value is the value of the field when the button is selected - must be unique within the group.
+func (group *RadioButtonGroup) AddButton(value string, x, y float32) *RadioButtonGroup {
+	group.values = append(group.values, value)
+	group.locations = append(group.locations, [2]float32{x, y})
+	return group
+}
+
+// SetSize sets the size of the radio buttons.
+func (group *RadioButtonGroup) SetSize(size float32) *RadioButtonGroup {
+	group.w = size
+	group.h = size
+	return group
+}
+
+// Select selects the radio button with the specified value.
+func (group *RadioButtonGroup) Select(value string) *RadioButtonGroup {
+	group.selected = value
+	return group
+}
+
+// SetMarkColor sets the color of the dot of the selected button.
+func (group *RadioButtonGroup) SetMarkColor(markColor int32) *RadioButtonGroup {
+	group.markColor = markColor
+	return group
+}
+
+// SetToolTip sets the text shown by the viewers when the mouse is over the buttons.
+// The text is also used as alternate description of the buttons.
+func (group *RadioButtonGroup) SetToolTip(toolTip string) *RadioButtonGroup {
+	group.toolTip = toolTip
+	return group
+}
+
+// SetReadOnly sets the flag that prevents the user from changing the value.
+func (group *RadioButtonGroup) SetReadOnly(readOnly bool) *RadioButtonGroup {
+	group.setFlag(fieldReadOnly, readOnly)
+	return group
+}
+
+// SetRequired sets the flag that requires value before the form is submitted.
+func (group *RadioButtonGroup) SetRequired(required bool) *RadioButtonGroup {
+	group.setFlag(fieldRequired, required)
+	return group
+}
+
+// SetBorderColor sets the color of the border. Use color.Transparent for no border.
+func (group *RadioButtonGroup) SetBorderColor(borderColor int32) *RadioButtonGroup {
+	group.borderColor = borderColor
+	return group
+}
+
+// SetBackgroundColor sets the background color. The default is color.Transparent.
+func (group *RadioButtonGroup) SetBackgroundColor(backgroundColor int32) *RadioButtonGroup {
+	group.backgroundColor = backgroundColor
+	return group
+}
+
+// DrawOn adds the radio buttons to the page and returns the coordinates of the bottom right corner of the last button.
+func (group *RadioButtonGroup) DrawOn(page *Page) [2]float32 {
+	if len(group.values) == 0 {
+		return [2]float32{0.0, 0.0}
+	}
+	field := group.newWidget("Btn")
+	field.flags |= fieldRadio | fieldNoToggle
+	for _, value := range group.values {
+		if value == group.selected {
+			field.state = value
+			field.checked = true
+		}
+	}
+	field.objNumber = page.pdf.reserveObjNumber()
+
+	// All buttons share the same on and off appearances.
+	on := group.newAppearance(page.pdf)
+	c := group.w / 2
+	on.SetBrushColor(group.markColor)
+	on.FillEllipse(c, c, c/2, c/2)
+	normal := page.pdf.addAppearanceStream(on, nil)
+	off := page.pdf.addAppearanceStream(group.newAppearance(page.pdf), nil)
+
+	xy := [2]float32{}
+	for i, value := range group.values {
+		w := new(widget)
+		w.parent = field
+		w.state = value
+		w.checked = value == group.selected
+		w.normal = normal
+		w.off = off
+		w.caption = "l" // The ZapfDingbats bullet
+		w.borderColor = group.borderColor
+		w.backgroundColor = group.backgroundColor
+		x, y := group.locations[i][0], group.locations[i][1]
+		field.kids = append(field.kids, group.addWidget(page, w, x, y, group.w, group.h))
+		xy = [2]float32{x + group.w, y + group.h}
+	}
+	page.pdf.formFields = append(page.pdf.formFields, field)
+	return xy
+}
+
+// newAppearance returns detached page with the background and the border of the round button drawn on it.
+func (group *RadioButtonGroup) newAppearance(pdf *PDF) *Page {
+	appearance := NewPageDetached(pdf, [2]float32{group.w, group.h})
+	c := group.w / 2
+	r := c - group.borderWidth/2
+	if group.backgroundColor != color.Transparent {
+		appearance.SetBrushColor(group.backgroundColor)
+		appearance.FillEllipse(c, c, r, r)
+	}
+	if group.borderColor != color.Transparent {
+		appearance.SetPenColor(group.borderColor)
+		appearance.SetPenWidth(group.borderWidth)
+		appearance.DrawCircle(c, c, r)
+	}
+	return appearance
+}
@@ -0,0 +1,839 @@
+package pdfjet
+
+/**
+ * redaction.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/edragoev1/pdfjet/src/color"
+	"github.com/edragoev1/pdfjet/src/compressor"
+)
+
+// The glyph box used to find the glyphs inside the redaction areas in text space units of font size 1.
+const (
+	glyphDescent = -0.2
+	glyphAscent  = 0.9
+)
+
+// Redaction is area of page read by PDF.Read where PDFobj.Redact removes the text and the images.
+type Redaction struct {
+	x, y, w, h float32
+	color      int32
+	overlay    bool
+}
+
+// NewRedaction creates redaction of the area with the top left corner at x, y.
+// The coordinates are the same as the coordinates used when drawing with pdfjet
+// and as the coordinates of the glyphs returned by PDFobj.ExtractText.
+func NewRedaction(x, y, w, h float32) *Redaction {
+	return &Redaction{x: x, y: y, w: w, h: h, color: color.Black, overlay: true}
+}
+
+// SetColor sets the color of the overlay box drawn over the redacted area.
+// The default color is black.
+func (redaction *Redaction) SetColor(color int32) {
+	redaction.color = color
+}
+
+// SetOverlay sets whether the overlay box is drawn over the redacted area.
+// The overlay is drawn by default.
+func (redaction *Redaction) SetOverlay(overlay bool) {
+	redaction.overlay = overlay
+}
+
+// Redact removes the text and the images inside the redaction areas from the page.
+// The program exits if the page cannot be redacted. Use RedactErr to handle the error.
+func (obj *PDFobj) Redact(redactions []*Redaction, objects *[]*PDFobj) {
+	if err := obj.RedactErr(redactions, objects); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// RedactErr removes the text and the images inside the redaction areas from the page read by PDF.Read,
+// draws the overlay boxes over the areas and removes the annotations that overlap the areas.
+// The glyphs that overlap the areas are removed from the text showing operators without moving
+// the other glyphs, and the marked content around them loses its ActualText and Alt entries.
+// The pixels of the images inside the areas are cleared in redacted copies of the images.
+// The images that cannot be decoded - for example JPXDecode images - and the inline images
+// that overlap the areas are removed. The form XObjects are redacted in copies too.
+// The page content objects and the redacted images and forms not used by other pages are emptied,
+// so that the removed content is not left in the file. Use PDF.AddObjects to write the objects.
+// Returns MalformedInputError if the content stream or the MediaBox cannot be parsed and
+// UnsupportedFeatureError if the content stream uses unsupported filter or if the page
+// was read for incremental update - the original bytes would keep the removed content.
+func (obj *PDFobj) RedactErr(redactions []*Redaction, objects *[]*PDFobj) (err error) {
+	defer recoverMalformedInput("PDF content stream", &err)
+
+	if obj.updated {
+		return unsupported("redaction in incremental update")
+	}
+	o := newPDFObjects(*objects)
+	page, _ := o.getValue(obj).(PDFDict)
+	if page == nil || o.getName(page["Type"]) != "Page" {
+		return malformed("PDF", "object %d is not page", obj.number)
+	}
+	top, err := o.getPageTop(page)
+	if err != nil {
+		return err
+	}
+	r := &redactor{
+		ex:       &textExtractor{objects: o, fonts: make(map[pdfRef]*textFont)},
+		objects:  objects,
+		replaced: make(map[pdfRef]bool),
+	}
+	overlay := make([]*ContentOperation, 0)
+	for _, redaction := range redactions {
+		x := roundCoordinate(float64(redaction.x))
+		y := roundCoordinate(top - float64(redaction.y) - float64(redaction.h))
+		w := roundCoordinate(float64(redaction.w))
+		h := roundCoordinate(float64(redaction.h))
+		r.areas = append(r.areas, [4]float64{x, y, x + w, y + h})
+		if redaction.overlay {
+			rgb := []float64{
+				float64((redaction.color>>16)&0xff) / 255.0,
+				float64((redaction.color>>8)&0xff) / 255.0,
+				float64(redaction.color&0xff) / 255.0,
+			}
+			overlay = append(overlay,
+				NewContentOperation("q"),
+				NewContentOperation("rg", roundCoordinate(rgb[0]), roundCoordinate(rgb[1]), roundCoordinate(rgb[2])),
+				NewContentOperation("re", x, y, w, h),
+				NewContentOperation("f"),
+				NewContentOperation("Q"))
+		}
+	}
+
+	contents, err := o.getPageContents(page)
+	if err != nil {
+		return err
+	}
+	stream, err := NewContentStreamErr(contents)
+	if err != nil {
+		return err
+	}
+	resources := newRedactedResources(o.getDict(o.getInherited(page, "Resources")))
+	operations, err := r.run(stream.Operations, resources, newTextState())
+	if err != nil {
+		return err
+	}
+	stream.Operations = append([]*ContentOperation{NewContentOperation("q")}, operations...)
+	stream.Operations = append(stream.Operations, NewContentOperation("Q"))
+	stream.Operations = append(stream.Operations, overlay...)
+	if err := obj.SetContentStreamErr(stream, objects); err != nil {
+		return err
+	}
+	if resources.xobjects != nil {
+		dict := r.newObject(appendPDFTokens(nil, resources.getDict()))
+		obj.removeEntries("/Resources")
+		obj.insertEntries("/Resources", strconv.Itoa(dict.number), "0", "R")
+	}
+	obj.removeEntries("/Thumb") // The thumbnail image shows the removed content
+	r.removeAnnotations(obj, page)
+	r.emptyReplacedObjects(obj)
+	return nil
+}
+
+func roundCoordinate(value float64) float64 {
+	return math.Round(value*1000.0) / 1000.0
+}
+
+// redactor runs the content streams and removes the content inside the redaction areas.
+type redactor struct {
+	ex       *textExtractor
+	objects  *[]*PDFobj
+	areas    [][4]float64    // The redaction areas in the default user space
+	replaced map[pdfRef]bool // The objects replaced with redacted copies
+	changes  int             // The number of the removed glyphs, images and cleared images
+	removed  int             // The number of the removed glyphs
+	depth    int             // The depth of the form XObjects
+}
+
+// redactedResources is resource dictionary with the XObjects replaced with redacted copies.
+type redactedResources struct {
+	resources PDFDict
+	xobjects  PDFDict // The copy of the XObject dictionary or nil if no XObject is replaced
+	redacted  map[string]*redactedXObject
+}
+
+func newRedactedResources(resources PDFDict) *redactedResources {
+	return &redactedResources{resources: resources, redacted: make(map[string]*redactedXObject)}
+}
+
+// getDict returns the resource dictionary with the redacted copies.
+func (res *redactedResources) getDict() PDFDict {
+	if res.xobjects == nil {
+		return res.resources
+	}
+	dict := make(PDFDict, len(res.resources)+1)
+	for key, value := range res.resources {
+		dict[key] = value
+	}
+	dict["XObject"] = res.xobjects
+	return dict
+}
+
+// redactedXObject is redacted copy of image or form XObject. The copy is redacted again
+// when the XObject is drawn more than once.
+type redactedXObject struct {
+	obj           *PDFobj
+	dict          PDFDict
+	data          []byte             // The content stream or the image samples
+	resources     *redactedResources // The resources of the form
+	width, height int                // The image size in pixels
+	bitsPerPixel  int
+	masks         []*redactedXObject // The SMask and Mask images
+}
+
+// run runs the content stream operations and returns the redacted operations.
+func (r *redactor) run(operations []*ContentOperation, res *redactedResources, state *textState) ([]*ContentOperation, error) {
+	redacted := make([]*ContentOperation, 0, len(operations))
+	stack := make([]*textState, 0)
+	marked := make([]int, 0)  // The marked content operations of the open sequences
+	markedRemoved := []bool{} // Whether glyphs are removed from the open sequences
+	for _, op := range operations {
+		removed := r.removed
+		if r.ex.setTextState(op, res.resources, state) {
+			redacted = append(redacted, op)
+			continue
+		}
+		switch op.Operator {
+		case "q":
+			saved := *state
+			stack = append(stack, &saved)
+		case "Q":
+			if len(stack) > 0 {
+				*state = *stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "Tj", "'", "\"", "TJ":
+			if text := r.redactText(op, state); text != nil {
+				redacted = append(redacted, text...)
+				op = nil
+			}
+		case "Do":
+			keep, err := r.redactXObject(op.GetName(0), res, state)
+			if err != nil {
+				return nil, err
+			}
+			if !keep {
+				op = nil
+			}
+		case "BI":
+			if r.overlaps(state.ctm, 0.0, 0.0, 1.0, 1.0) {
+				r.changes++
+				op = nil
+			}
+		case "BMC", "BDC":
+			marked = append(marked, len(redacted))
+			markedRemoved = append(markedRemoved, false)
+		case "EMC":
+			if n := len(marked); n > 0 {
+				if markedRemoved[n-1] {
+					removeActualText(redacted[marked[n-1]])
+				}
+				marked = marked[:n-1]
+				markedRemoved = markedRemoved[:n-1]
+			}
+		}
+		if r.removed > removed {
+			for i := range markedRemoved {
+				markedRemoved[i] = true
+			}
+		}
+		if op != nil {
+			redacted = append(redacted, op)
+		}
+	}
+	return redacted, nil
+}
+
+// removeActualText replaces the BDC operation with copy without the replacement text entries.
+func removeActualText(op *ContentOperation) {
+	if op.Operator != "BDC" || len(op.Operands) != 2 {
+		return
+	}
+	properties, ok := op.Operands[1].(PDFDict)
+	if !ok {
+		return
+	}
+	dict := make(PDFDict, len(properties))
+	for key, value := range properties {
+		if key != "ActualText" && key != "Alt" && key != "E" {
+			dict[key] = value
+		}
+	}
+	op.Operands = []any{op.Operands[0], dict}
+}
+
+// redactText returns the text showing operation without the glyphs inside the redaction areas
+// or nil if no glyph is removed. The removed glyphs are replaced with TJ adjustments.
+func (r *redactor) redactText(op *ContentOperation, state *textState) []*ContentOperation {
+	state.nextLine(op)
+	n := len(op.Operands)
+	if n == 0 {
+		return nil
+	}
+	var elements []any
+	if op.Operator == "TJ" {
+		elements, _ = op.Operands[n-1].([]any)
+	} else if str, ok := op.Operands[n-1].(PDFString); ok {
+		elements = []any{str}
+	}
+	font := state.getFont()
+	array := make([]any, 0, len(elements))
+	removed := false
+	for _, element := range elements {
+		switch element := element.(type) {
+		case PDFString:
+			str := []byte(element)
+			kept := make([]byte, 0, len(str))
+			for len(str) > 0 {
+				code, length := font.nextCode(str)
+				m, w0, tx := state.showGlyph(font, code, length)
+				if state.size != 0.0 && r.overlapsGlyph(m, w0, state) {
+					if len(kept) > 0 {
+						array = append(array, PDFString(kept))
+						kept = make([]byte, 0, len(str))
+					}
+					array = appendAdjustment(array, -tx*1000.0/state.size)
+					removed = true
+				} else {
+					kept = append(kept, str[:length]...)
+				}
+				str = str[length:]
+			}
+			if len(kept) > 0 {
+				array = append(array, PDFString(kept))
+			}
+		case float64:
+			state.moveText(element)
+			array = appendAdjustment(array, element)
+		}
+	}
+	if !removed {
+		return nil
+	}
+	r.changes++
+	r.removed++
+	text := make([]*ContentOperation, 0)
+	if op.Operator == "\"" && n == 3 {
+		text = append(text, NewContentOperation("Tw", op.Operands[0]), NewContentOperation("Tc", op.Operands[1]))
+	}
+	if op.Operator != "Tj" && op.Operator != "TJ" {
+		text = append(text, NewContentOperation("T*"))
+	}
+	return append(text, NewContentOperation("TJ", array))
+}
+
+// appendAdjustment appends the TJ adjustment and joins it with the previous adjustment.
+func appendAdjustment(array []any, adjustment float64) []any {
+	if n := len(array); n > 0 {
+		if previous, ok := array[n-1].(float64); ok {
+			array[n-1] = previous + adjustment
+			return array
+		}
+	}
+	return append(array, adjustment)
+}
+
+// overlapsGlyph returns true if the glyph box overlaps the redaction areas.
+func (r *redactor) overlapsGlyph(m [6]float64, w0 float64, state *textState) bool {
+	if w0 <= 0.0 {
+		w0 = 0.5 // The fonts without glyph widths
+	}
+	trm := multiplyMatrix([6]float64{state.size * state.scaling, 0, 0, state.size, 0, state.rise}, m)
+	return r.overlaps(trm, 0.0, glyphDescent, w0, glyphAscent)
+}
+
+// transformBox returns the bounding box of the transformed rectangle.
+func transformBox(m [6]float64, x1, y1, x2, y2 float64) [4]float64 {
+	box := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, point := range [4][2]float64{{x1, y1}, {x2, y1}, {x1, y2}, {x2, y2}} {
+		x := point[0]*m[0] + point[1]*m[2] + m[4]
+		y := point[0]*m[1] + point[1]*m[3] + m[5]
+		box[0] = math.Min(box[0], x)
+		box[1] = math.Min(box[1], y)
+		box[2] = math.Max(box[2], x)
+		box[3] = math.Max(box[3], y)
+	}
+	return box
+}
+
+// overlaps returns true if the transformed rectangle overlaps the redaction areas.
+func (r *redactor) overlaps(m [6]float64, x1, y1, x2, y2 float64) bool {
+	box := transformBox(m, x1, y1, x2, y2)
+	for _, area := range r.areas {
+		if box[0] < area[2] && area[0] < box[2] && box[1] < area[3] && area[1] < box[3] {
+			return true
+		}
+	}
+	return false
+}
+
+// redactXObject redacts the XObject with the resource name.
+// Returns false if the XObject must be removed.
+func (r *redactor) redactXObject(name string, res *redactedResources, state *textState) (bool, error) {
+	o := r.ex.objects
+	value := o.getDict(res.resources["XObject"])[name]
+	ref, ok := value.(pdfRef)
+	dict := o.getDict(value)
+	if name == "" || !ok || dict == nil {
+		return true, nil
+	}
+	switch o.getName(dict["Subtype"]) {
+	case "Form":
+		return true, r.redactForm(name, ref, dict, res, state)
+	case "Image":
+		return r.redactImage(name, ref, dict, res, state.ctm), nil
+	}
+	return true, nil
+}
+
+// setXObject replaces the XObject with the resource name with the redacted copy.
+func (r *redactor) setXObject(res *redactedResources, name string, xobject *redactedXObject, ref pdfRef) {
+	if res.xobjects == nil {
+		res.xobjects = make(PDFDict)
+		for key, value := range r.ex.objects.getDict(res.resources["XObject"]) {
+			res.xobjects[key] = value
+		}
+	}
+	r.writeXObject(xobject)
+	res.xobjects[name] = pdfRef(xobject.obj.number)
+	res.redacted[name] = xobject
+	r.replaced[ref] = true
+}
+
+// redactForm runs the content stream of the form XObject and replaces the form with redacted copy
+// if content is removed.
+func (r *redactor) redactForm(name string, ref pdfRef, dict PDFDict, res *redactedResources, state *textState) error {
+	o := r.ex.objects
+	if r.depth >= maxFormDepth {
+		return nil
+	}
+	form := res.redacted[name]
+	if form == nil {
+		data, err := o.getStreamData(ref)
+		if err != nil {
+			return err
+		}
+		resources := o.getDict(dict["Resources"])
+		if resources == nil {
+			resources = res.resources
+		}
+		form = &redactedXObject{dict: copyStreamDict(dict), data: data, resources: newRedactedResources(resources)}
+	}
+	stream, err := NewContentStreamErr(form.data)
+	if err != nil {
+		return err
+	}
+	formState := *state
+	if m, ok := getMatrix(o, o.getArray(form.dict["Matrix"])); ok {
+		formState.ctm = multiplyMatrix(m, state.ctm)
+	}
+	changes := r.changes
+	r.depth++
+	operations, err := r.run(stream.Operations, form.resources, &formState)
+	r.depth--
+	if err != nil || r.changes == changes {
+		return err
+	}
+	stream.Operations = operations
+	form.data = stream.Bytes()
+	if form.resources.xobjects != nil {
+		form.dict["Resources"] = form.resources.getDict()
+	}
+	if form.obj == nil {
+		r.setXObject(res, name, form, ref)
+	} else {
+		r.writeXObject(form)
+	}
+	return nil
+}
+
+// copyStreamDict returns copy of the stream dictionary without the stream filters.
+func copyStreamDict(dict PDFDict) PDFDict {
+	copied := make(PDFDict, len(dict))
+	for key, value := range dict {
+		if key != "Length" && key != "Filter" && key != "DecodeParms" {
+			copied[key] = value
+		}
+	}
+	return copied
+}
+
+// writeXObject writes the redacted copy in compressed stream object.
+func (r *redactor) writeXObject(xobject *redactedXObject) {
+	compressed := compressor.Deflate(xobject.data)
+	xobject.dict["Filter"] = PDFName("FlateDecode")
+	xobject.dict["Length"] = float64(len(compressed))
+	if xobject.obj == nil {
+		xobject.obj = r.newObject(nil)
+	}
+	xobject.obj.dict = appendPDFTokens(nil, xobject.dict)
+	xobject.obj.stream = compressed
+	xobject.obj.data = xobject.data
+}
+
+// newObject adds new object with the dictionary tokens.
+func (r *redactor) newObject(dict []string) *PDFobj {
+	obj := NewPDFobj()
+	obj.dict = dict
+	obj.number = len(*r.objects) + 1
+	*r.objects = append(*r.objects, obj)
+	return obj
+}
+
+// redactImage clears the pixels of the image inside the redaction areas in redacted copy.
+// Returns false if the image overlaps the areas but cannot be decoded.
+func (r *redactor) redactImage(name string, ref pdfRef, dict PDFDict, res *redactedResources, ctm [6]float64) bool {
+	areas := r.getImageAreas(ctm)
+	if len(areas) == 0 {
+		return true
+	}
+	r.changes++
+	image := res.redacted[name]
+	if image == nil {
+		image = r.readImage(ref, dict)
+		if image == nil {
+			return false
+		}
+		r.setXObject(res, name, image, ref)
+	}
+	r.clearPixels(image, areas)
+	return true
+}
+
+// getImageAreas returns the parts of the redaction areas inside the image in the unit square
+// of the image space.
+func (r *redactor) getImageAreas(ctm [6]float64) [][4]float64 {
+	det := ctm[0]*ctm[3] - ctm[1]*ctm[2]
+	if det == 0.0 {
+		return nil
+	}
+	inverse := [6]float64{
+		ctm[3] / det, -ctm[1] / det,
+		-ctm[2] / det, ctm[0] / det,
+		(ctm[2]*ctm[5] - ctm[3]*ctm[4]) / det, (ctm[1]*ctm[4] - ctm[0]*ctm[5]) / det,
+	}
+	areas := make([][4]float64, 0)
+	for _, area := range r.areas {
+		box := transformBox(inverse, area[0], area[1], area[2], area[3])
+		box[0] = math.Max(box[0], 0.0)
+		box[1] = math.Max(box[1], 0.0)
+		box[2] = math.Min(box[2], 1.0)
+		box[3] = math.Min(box[3], 1.0)
+		if box[0] < box[2] && box[1] < box[3] {
+			areas = append(areas, box)
+		}
+	}
+	return areas
+}
+
+// readImage returns redacted copy of the image with the decoded samples
+// or nil if the image cannot be decoded.
+func (r *redactor) readImage(ref pdfRef, dict PDFDict) *redactedXObject {
+	o := r.ex.objects
+	image := &redactedXObject{dict: copyStreamDict(dict)}
+	image.width = int(o.getNumber(dict["Width"], 0.0))
+	image.height = int(o.getNumber(dict["Height"], 0.0))
+	bitsPerComponent := int(o.getNumber(dict["BitsPerComponent"], 8.0))
+	components := r.getComponents(dict["ColorSpace"])
+	if o.resolve(dict["ImageMask"]) == true {
+		bitsPerComponent, components = 1, 1
+	}
+	filter := o.resolve(dict["Filter"])
+	if array, ok := filter.([]any); ok && len(array) == 1 {
+		filter = o.resolve(array[0])
+	}
+	if filter == PDFName("DCTDecode") || filter == PDFName("DCT") {
+		data, n := decodeJPEG(o.getObject(int(ref)).stream)
+		if data == nil {
+			return nil
+		}
+		if n != components {
+			image.dict["ColorSpace"] = PDFName("DeviceGray")
+			if n == 3 {
+				image.dict["ColorSpace"] = PDFName("DeviceRGB")
+			}
+			delete(image.dict, "Decode")
+		}
+		image.data, bitsPerComponent, components = data, 8, n
+	} else {
+		data, err := o.getStreamData(ref)
+		if err != nil {
+			return nil
+		}
+		image.data = data
+	}
+	switch bitsPerComponent {
+	case 1, 2, 4, 8, 16:
+	default:
+		return nil
+	}
+	image.bitsPerPixel = components * bitsPerComponent
+	if image.width <= 0 || image.height <= 0 || components <= 0 ||
+		len(image.data) < image.height*((image.width*image.bitsPerPixel+7)/8) {
+		return nil
+	}
+	for _, key := range []string{"SMask", "Mask"} {
+		maskRef, ok := dict[key].(pdfRef)
+		if !ok || o.getDict(maskRef) == nil {
+			continue // Color key masking
+		}
+		mask := r.readImage(maskRef, o.getDict(maskRef))
+		if mask == nil {
+			return nil
+		}
+		r.writeXObject(mask)
+		r.replaced[maskRef] = true
+		image.dict[key] = pdfRef(mask.obj.number)
+		image.masks = append(image.masks, mask)
+	}
+	return image
+}
+
+// getComponents returns the number of color components of the image color space
+// or 0 if the color space is not supported.
+func (r *redactor) getComponents(value any) int {
+	o := r.ex.objects
+	if array := o.getArray(value); len(array) > 0 {
+		switch o.getName(array[0]) {
+		case "ICCBased":
+			if len(array) > 1 {
+				return int(o.getNumber(o.getDict(array[1])["N"], 0.0))
+			}
+		case "Indexed", "I", "Separation", "CalGray":
+			return 1
+		case "CalRGB", "Lab":
+			return 3
+		case "DeviceN":
+			if len(array) > 1 {
+				return len(o.getArray(array[1]))
+			}
+		}
+		return 0
+	}
+	switch o.getName(value) {
+	case "DeviceGray", "G", "CalGray":
+		return 1
+	case "DeviceRGB", "RGB", "CalRGB":
+		return 3
+	case "DeviceCMYK", "CMYK":
+		return 4
+	}
+	return 0
+}
+
+// decodeJPEG returns the samples and the number of color components of grayscale or RGB JPEG image.
+func decodeJPEG(buf []byte) ([]byte, int) {
+	img, err := jpeg.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, 0
+	}
+	bounds := img.Bounds()
+	switch img := img.(type) {
+	case *image.Gray:
+		data := make([]byte, 0, bounds.Dx()*bounds.Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			offset := img.PixOffset(bounds.Min.X, y)
+			data = append(data, img.Pix[offset:offset+bounds.Dx()]...)
+		}
+		return data, 1
+	case *image.YCbCr:
+		data := make([]byte, 0, 3*bounds.Dx()*bounds.Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				red, green, blue, _ := img.At(x, y).RGBA()
+				data = append(data, byte(red>>8), byte(green>>8), byte(blue>>8))
+			}
+		}
+		return data, 3
+	}
+	return nil, 0
+}
+
+// clearPixels clears the pixels of the image and of its masks inside the areas.
+func (r *redactor) clearPixels(image *redactedXObject, areas [][4]float64) {
+	bytesPerLine := (image.width*image.bitsPerPixel + 7) / 8
+	for _, area := range areas {
+		col1 := int(math.Floor(area[0] * float64(image.width)))
+		col2 := int(math.Ceil(area[2] * float64(image.width)))
+		row1 := int(math.Floor((1.0 - area[3]) * float64(image.height))) // The rows are from the top
+		row2 := int(math.Ceil((1.0 - area[1]) * float64(image.height)))
+		for row := row1; row < row2 && row < image.height; row++ {
+			line := image.data[row*bytesPerLine : (row+1)*bytesPerLine]
+			for bit := col1 * image.bitsPerPixel; bit < col2*image.bitsPerPixel && bit < 8*bytesPerLine; bit++ {
+				line[bit/8] &^= 0x80 >> (bit % 8)
+			}
+		}
+	}
+	for _, mask := range image.masks {
+		r.clearPixels(mask, areas)
+	}
+	r.writeXObject(image)
+}
+
+// removeAnnotations removes the annotations that overlap the redaction areas from the page
+// together with their popup annotations.
+func (r *redactor) removeAnnotations(obj *PDFobj, page PDFDict) {
+	o := r.ex.objects
+	annotations := o.getArray(page["Annots"])
+	removed := make(map[pdfRef]bool)
+	remove := make([]bool, len(annotations))
+	for i, annotation := range annotations {
+		rect := o.getArray(o.getDict(annotation)["Rect"])
+		if len(rect) == 4 {
+			x1, y1 := o.getNumber(rect[0], 0.0), o.getNumber(rect[1], 0.0)
+			x2, y2 := o.getNumber(rect[2], 0.0), o.getNumber(rect[3], 0.0)
+			if r.overlaps([6]float64{1, 0, 0, 1, 0, 0}, x1, y1, x2, y2) {
+				remove[i] = true
+				if ref, ok := annotation.(pdfRef); ok {
+					removed[ref] = true
+				}
+			}
+		}
+	}
+	kept := make([]any, 0, len(annotations))
+	for i, annotation := range annotations {
+		dict := o.getDict(annotation)
+		if parent, ok := dict["Parent"].(pdfRef); ok && removed[parent] && o.getName(dict["Subtype"]) == "Popup" {
+			remove[i] = true
+			if ref, ok := annotation.(pdfRef); ok {
+				removed[ref] = true
+			}
+		}
+		if !remove[i] {
+			kept = append(kept, annotation)
+		}
+	}
+	if len(kept) == len(annotations) {
+		return
+	}
+	obj.removeEntries("/Annots")
+	if len(kept) > 0 {
+		obj.insertEntries(appendPDFTokens([]string{"/Annots"}, kept)...)
+	}
+	for ref := range removed {
+		r.emptyObject(ref)
+	}
+	r.removeReferences(removed)
+}
+
+// emptyObject replaces the object with empty dictionary.
+func (r *redactor) emptyObject(ref pdfRef) {
+	obj := r.ex.objects.getObject(int(ref))
+	if obj == nil || obj.number != int(ref) {
+		return
+	}
+	obj.dict = []string{"<<", ">>"}
+	if obj.offset != 0 {
+		obj.dict = append([]string{strconv.Itoa(obj.number), "0", "obj"}, obj.dict...)
+	}
+	obj.stream = nil
+	obj.data = nil
+}
+
+// removeReferences removes the references to the objects from the annotation arrays,
+// from the form field arrays and from the array objects.
+func (r *redactor) removeReferences(refs map[pdfRef]bool) {
+	for _, obj := range *r.objects {
+		dict := make([]string, 0, len(obj.dict))
+		arrays := make([]bool, 0) // Whether the references are removed from the open arrays
+		for i := 0; i < len(obj.dict); i++ {
+			token := obj.dict[i]
+			if token == "[" {
+				key := ""
+				if i > 0 {
+					key = obj.dict[i-1]
+				}
+				arrays = append(arrays, key == "/Annots" || key == "/Fields" || key == "/Kids" || key == "obj")
+			} else if token == "]" && len(arrays) > 0 {
+				arrays = arrays[:len(arrays)-1]
+			} else if len(arrays) > 0 && arrays[len(arrays)-1] &&
+				i+2 < len(obj.dict) && obj.dict[i+1] == "0" && obj.dict[i+2] == "R" {
+				if number, err := strconv.Atoi(token); err == nil && refs[pdfRef(number)] {
+					i += 2
+					continue
+				}
+			}
+			dict = append(dict, token)
+		}
+		obj.dict = dict
+	}
+}
+
+// emptyReplacedObjects empties the objects replaced with redacted copies
+// unless the other pages or the annotations use them.
+func (r *redactor) emptyReplacedObjects(page *PDFobj) {
+	if len(r.replaced) == 0 {
+		return
+	}
+	o := newPDFObjects(*r.objects)
+	used := make(map[pdfRef]bool)
+	for _, obj := range *r.objects {
+		if isPageObject(obj) {
+			dict, _ := o.getValue(obj).(PDFDict)
+			findReferences(o, dict, used)
+			if obj != page {
+				findReferences(o, o.getInherited(dict, "Resources"), used)
+			}
+		}
+	}
+	for ref := range r.replaced {
+		if !used[ref] {
+			r.emptyObject(ref)
+		}
+	}
+}
+
+// findReferences adds the objects referenced by the value and by the referenced objects.
+// The parent references are not followed.
+func findReferences(o *pdfObjects, value any, refs map[pdfRef]bool) {
+	switch value := value.(type) {
+	case pdfRef:
+		if refs[value] {
+			return
+		}
+		refs[value] = true
+		if obj := o.getObject(int(value)); obj != nil {
+			findReferences(o, o.getValue(obj), refs)
+		}
+	case []any:
+		for _, element := range value {
+			findReferences(o, element, refs)
+		}
+	case PDFDict:
+		for key, element := range value {
+			if key != "Parent" && key != "P" {
+				findReferences(o, element, refs)
+			}
+		}
+	}
+}
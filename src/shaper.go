@@ -0,0 +1,479 @@
+package pdfjet
+
+/**
+ * shaper.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// The mask of the glyphs the default features are applied to.
+const globalMask uint32 = 1
+
+// The shaping models. The complex scripts need the characters classified
+// and reordered and the features applied to some of the glyphs only.
+const (
+	otModelDefault = iota
+	otModelArabic
+	otModelIndic
+	otModelThai
+)
+
+// The GSUB features applied to all text.
+var defaultSubstitutionFeatures = []string{"ccmp", "locl", "rlig", "rclt", "calt", "liga", "clig"}
+
+// The GPOS features applied to all text.
+var defaultPositioningFeatures = []string{"kern", "dist", "abvm", "blwm", "mark", "mkmk"}
+
+// otGlyph is glyph in the shaping buffer.
+// The positions are in font units.
+type otGlyph struct {
+	gid     int
+	cluster int // The index of the first rune the glyph is made from
+	length  int // The number of runes the glyph is made from
+	class   int // The GDEF glyph class
+	mask    uint32
+	advance int
+	xOffset int
+	yOffset int
+
+	// Set by the Indic shaper
+	syllable int
+	category int
+	position int
+}
+
+// otShaper applies the GSUB and GPOS lookups to the glyphs of one text run.
+type otShaper struct {
+	font   *Font
+	layout *otLayout
+	script int  // The index in otScripts or -1
+	rtl    bool // The glyphs are drawn from right to left
+	runes  []rune
+	glyphs []otGlyph
+}
+
+// The scripts with their OpenType script tags. The first tag found in the font is used.
+var otScripts = []struct {
+	table *unicode.RangeTable
+	tags  []string
+	model int
+}{
+	{unicode.Latin, []string{"latn"}, otModelDefault},
+	{unicode.Cyrillic, []string{"cyrl"}, otModelDefault},
+	{unicode.Greek, []string{"grek"}, otModelDefault},
+	{unicode.Arabic, []string{"arab"}, otModelArabic},
+	{unicode.Hebrew, []string{"hebr"}, otModelDefault},
+	{unicode.Devanagari, []string{"dev2", "deva"}, otModelIndic},
+	{unicode.Thai, []string{"thai"}, otModelThai},
+	{unicode.Lao, []string{"lao "}, otModelThai},
+	{unicode.Han, []string{"hani"}, otModelDefault},
+	{unicode.Hiragana, []string{"kana"}, otModelDefault},
+	{unicode.Katakana, []string{"kana"}, otModelDefault},
+	{unicode.Hangul, []string{"hang"}, otModelDefault},
+}
+
+// otScriptRun is run of text in one script.
+type otScriptRun struct {
+	start  int
+	end    int
+	script int // The index in otScripts or -1 if the run has no script specific characters
+}
+
+// The OpenType language system tags for the ISO 639 language codes.
+var otLanguageTags = map[string]string{
+	"az": "AZE ", "bg": "BGR ", "ca": "CAT ", "cs": "CSY ", "da": "DAN ",
+	"de": "DEU ", "el": "ELL ", "en": "ENG ", "es": "ESP ", "fi": "FIN ",
+	"fr": "FRA ", "hi": "HIN ", "hu": "HUN ", "it": "ITA ", "ja": "JAN ",
+	"kk": "KAZ ", "ko": "KOR ", "mk": "MKD ", "mr": "MAR ", "nl": "NLD ",
+	"no": "NOR ", "pl": "PLK ", "pt": "PTG ", "ro": "ROM ", "ru": "RUS ",
+	"sr": "SRB ", "sv": "SVE ", "th": "THA ", "tr": "TRK ", "uk": "UKR ",
+	"zh": "ZHS ",
+}
+
+// SetLanguage sets the language used to select the language specific glyphs, for example "tr" or "sr-Cyrl".
+// Only applies to OpenType and TrueType fonts with GSUB table.
+func (font *Font) SetLanguage(language string) {
+	language = strings.ToLower(language)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	font.language = otLanguageTags[language]
+}
+
+// getScript returns the index of the script of the character in otScripts or -1.
+func getScript(ch rune) int {
+	for i, script := range otScripts {
+		if unicode.Is(script.table, ch) {
+			return i
+		}
+	}
+	return -1
+}
+
+// getScriptRuns splits the text into runs of one script. The characters common
+// to all scripts - spaces, punctuation, digits and combining marks - belong to the run before them.
+func getScriptRuns(runes []rune) []otScriptRun {
+	runs := make([]otScriptRun, 0, 1)
+	run := otScriptRun{script: -1}
+	for i, ch := range runes {
+		script := getScript(ch)
+		if script < 0 || script == run.script {
+			continue
+		}
+		if run.script < 0 {
+			run.script = script
+		} else {
+			run.end = i
+			runs = append(runs, run)
+			run = otScriptRun{start: i, script: script}
+		}
+	}
+	run.end = len(runes)
+	return append(runs, run)
+}
+
+// shape returns the glyphs for the text with the GSUB and GPOS features applied.
+// The glyphs are in logical order - the right-to-left text is reversed when drawn.
+// Returns nil if the font has no layout tables.
+func (font *Font) shape(runes []rune, rtl bool) []otGlyph {
+	if font.layout == nil || len(runes) == 0 {
+		return nil
+	}
+	glyphs := make([]otGlyph, 0, len(runes))
+	for _, run := range getScriptRuns(runes) {
+		s := &otShaper{font: font, layout: font.layout, script: run.script, rtl: rtl, runes: runes[run.start:run.end]}
+		s.mapGlyphs()
+		if !s.applyFeatures() {
+			// Malformed layout tables - draw the glyphs from cmap.
+			s.glyphs = s.glyphs[:0]
+			s.mapGlyphs()
+			s.setAdvances(true)
+		}
+		for _, g := range s.glyphs {
+			g.cluster += run.start
+			glyphs = append(glyphs, g)
+		}
+	}
+	return glyphs
+}
+
+// getModel returns the shaping model of the script.
+func (s *otShaper) getModel() int {
+	if s.script < 0 {
+		return otModelDefault
+	}
+	return otScripts[s.script].model
+}
+
+// getScriptTags returns the OpenType script tags of the script.
+func (s *otShaper) getScriptTags() []string {
+	if s.script < 0 {
+		return nil
+	}
+	return otScripts[s.script].tags
+}
+
+// mapGlyphs fills the buffer with the glyphs from cmap and their advance widths.
+func (s *otShaper) mapGlyphs() {
+	for i, ch := range s.runes {
+		g := otGlyph{gid: s.font.getGlyphID(ch), cluster: i, length: 1, mask: globalMask}
+		s.glyphs = append(s.glyphs, g)
+	}
+	s.setGlyphClasses()
+}
+
+// setGlyphClasses sets the GDEF glyph classes. When the font has no glyph classes
+// the nonspacing marks are recognized by their Unicode category.
+func (s *otShaper) setGlyphClasses() {
+	for i := range s.glyphs {
+		g := &s.glyphs[i]
+		if s.layout.glyphClassDef != nil {
+			g.class = s.layout.getGlyphClass(g.gid)
+		} else if g.length == 1 && unicode.Is(unicode.Mn, s.runes[g.cluster]) {
+			g.class = glyphClassMark
+		} else if g.length > 1 {
+			g.class = glyphClassLigature
+		} else {
+			g.class = glyphClassBase
+		}
+	}
+}
+
+// applyFeatures applies the GSUB features of the shaping model and the GPOS features.
+// Returns false if the layout tables are malformed.
+func (s *otShaper) applyFeatures() (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isRuntimeError := r.(interface{ RuntimeError() }); !isRuntimeError {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+
+	model := s.getModel()
+	switch model {
+	case otModelArabic:
+		s.shapeArabic()
+	case otModelIndic:
+		s.shapeIndic()
+	case otModelThai:
+		s.shapeThai()
+	default:
+		s.substitute(getGlobalFeatures(defaultSubstitutionFeatures))
+	}
+	s.removeDefaultIgnorables()
+	// The Indic marks like the spacing vowel signs keep their widths.
+	s.setAdvances(model != otModelIndic)
+	if s.layout.gpos != nil {
+		features := getGlobalFeatures(defaultPositioningFeatures)
+		for _, ref := range s.layout.gpos.getLookups(s.getScriptTags(), s.font.language, features) {
+			s.applyPositioningLookup(ref)
+		}
+	}
+	return true
+}
+
+// getGlobalFeatures returns the features applied to all glyphs.
+func getGlobalFeatures(tags []string) []otFeature {
+	features := make([]otFeature, len(tags))
+	for i, tag := range tags {
+		features[i] = otFeature{tag, globalMask}
+	}
+	return features
+}
+
+// substitute applies the GSUB lookups of the features in one stage.
+func (s *otShaper) substitute(features []otFeature) {
+	if s.layout.gsub == nil {
+		return
+	}
+	for _, ref := range s.layout.gsub.getLookups(s.getScriptTags(), s.font.language, features) {
+		s.applySubstitutionLookup(ref)
+	}
+}
+
+// removeDefaultIgnorables removes the invisible characters like ZWJ and ZWNJ the font has no glyphs for.
+func (s *otShaper) removeDefaultIgnorables() {
+	glyphs := s.glyphs[:0]
+	for _, g := range s.glyphs {
+		if g.length == 1 {
+			ch := s.runes[g.cluster]
+			if isDefaultIgnorable(ch) && !s.font.hasGlyph(ch) {
+				continue
+			}
+		}
+		glyphs = append(glyphs, g)
+	}
+	s.glyphs = glyphs
+}
+
+// setAdvances sets the advance widths of the glyphs from hmtx.
+func (s *otShaper) setAdvances(zeroWidthMarks bool) {
+	for i := range s.glyphs {
+		g := &s.glyphs[i]
+		if zeroWidthMarks && g.class == glyphClassMark {
+			g.advance = 0
+		} else {
+			g.advance = int(s.font.getAdvanceWidth(g.gid))
+		}
+	}
+}
+
+// nextGlyph returns the position of the next glyph not ignored by the lookup or -1.
+func (s *otShaper) nextGlyph(lookup *otLookup, i int) int {
+	for i++; i < len(s.glyphs); i++ {
+		if !s.layout.ignores(lookup, &s.glyphs[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// prevGlyph returns the position of the previous glyph not ignored by the lookup or -1.
+func (s *otShaper) prevGlyph(lookup *otLookup, i int) int {
+	for i--; i >= 0; i-- {
+		if !s.layout.ignores(lookup, &s.glyphs[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// getGlyphID returns the glyph ID from cmap. The characters not in the font are drawn as space.
+func (font *Font) getGlyphID(ch rune) int {
+	if ch < font.firstChar || ch > font.lastChar || int(ch) >= len(font.unicodeToGID) {
+		return font.unicodeToGID[0x0020]
+	}
+	return font.unicodeToGID[ch]
+}
+
+// hasGlyph returns true if the font has glyph for the character.
+func (font *Font) hasGlyph(ch rune) bool {
+	return ch >= font.firstChar && ch <= font.lastChar &&
+		int(ch) < len(font.unicodeToGID) && font.unicodeToGID[ch] != 0
+}
+
+// isDefaultIgnorable returns true if the character is not displayed unless the font has glyph for it.
+func isDefaultIgnorable(ch rune) bool {
+	if unicode.In(ch, unicode.White_Space, unicode.Prepended_Concatenation_Mark) ||
+		(ch >= 0xFFF9 && ch <= 0xFFFB) {
+		return false
+	}
+	return unicode.In(ch, unicode.Cf, unicode.Other_Default_Ignorable_Code_Point, unicode.Variation_Selector)
+}
+
+// shapedWidth returns the advance width of the glyphs in font units.
+func shapedWidth(glyphs []otGlyph) int {
+	width := 0
+	for _, g := range glyphs {
+		width += g.advance
+	}
+	return width
+}
+
+// glyphSpan is the run of glyphs drawn for the characters from runes[first] to runes[last-1].
+type glyphSpan struct {
+	end         int // The position after the last glyph of the span
+	first, last int
+}
+
+// getGlyphSpans splits the glyphs into the shortest runs that are drawn for characters
+// not shared with the other runs and that are followed only by the glyphs of the characters
+// after them or only by the glyphs of the characters before them. The decomposed characters,
+// the glyphs made from several characters and the reordered glyphs of Indic syllable are in one span.
+func getGlyphSpans(glyphs []otGlyph, numOfRunes int) []glyphSpan {
+	lastGlyph := make([]int, numOfRunes) // The position of the last glyph drawn for each character
+	for i, g := range glyphs {
+		for c := g.cluster; c < g.cluster+maxInt(g.length, 1) && c < numOfRunes; c++ {
+			lastGlyph[c] = i
+		}
+	}
+	// The first and the last character of the glyphs from each position to the end
+	minFirst := make([]int, len(glyphs)+1)
+	maxLast := make([]int, len(glyphs)+1)
+	minFirst[len(glyphs)] = numOfRunes
+	for i := len(glyphs) - 1; i >= 0; i-- {
+		minFirst[i] = minInt(minFirst[i+1], glyphs[i].cluster)
+		maxLast[i] = maxInt(maxLast[i+1], glyphs[i].cluster+maxInt(glyphs[i].length, 1))
+	}
+	spans := make([]glyphSpan, 0, len(glyphs))
+	for start := 0; start < len(glyphs); {
+		span := glyphSpan{end: start + 1, first: numOfRunes, last: 0}
+		for i := start; i < span.end; i++ {
+			g := glyphs[i]
+			span.first = minInt(span.first, g.cluster)
+			span.last = minInt(maxInt(span.last, g.cluster+maxInt(g.length, 1)), numOfRunes)
+			for c := span.first; c < span.last; c++ {
+				span.end = maxInt(span.end, lastGlyph[c]+1)
+			}
+			if i == span.end-1 && span.end < len(glyphs) &&
+				minFirst[span.end] < span.last && maxLast[span.end] > span.first {
+				span.end++
+			}
+		}
+		spans = append(spans, span)
+		start = span.end
+	}
+	return spans
+}
+
+// drawShapedString draws the glyphs of the shaped text inside TJ array.
+// The kerning and the glyph offsets that differ from the widths in the font
+// dictionary are written as TJ adjustments and text rise. The glyphs that
+// the ToUnicode CMap cannot map back to the text - the decomposed characters,
+// the reordered Indic syllables and the glyphs shared by several characters -
+// are drawn inside marked content with the text as replacement text.
+func (page *Page) drawShapedString(font *Font, glyphs []otGlyph, runes []rune) {
+	k := 1000.0 / float64(font.unitsPerEm)
+	shift := 0 // The current position minus the shaped position in thousandths of text space unit
+	start := 0
+	for _, span := range getGlyphSpans(glyphs, len(runes)) {
+		if font.subset != nil {
+			for _, g := range glyphs[start:span.end] {
+				font.subset.addGlyph(font, g, runes)
+			}
+		}
+		text := runes[span.first:span.last]
+		actualText := span.end-start > 1 || !font.mapsGlyphTo(glyphs[start].gid, text)
+		if actualText {
+			appendString(&page.buf, ">] TJ\n/Span <</ActualText <FEFF")
+			appendString(&page.buf, toUTF16HexString(string(text)))
+			appendString(&page.buf, ">>>\nBDC\n[<")
+		}
+		for _, g := range glyphs[start:span.end] {
+			width := int(math.Round(k * float64(font.getAdvanceWidth(g.gid))))
+			advance := width + int(math.Round(k*float64(g.advance-int(font.getAdvanceWidth(g.gid)))))
+			if g.yOffset != 0 {
+				appendString(&page.buf, ">] TJ\n")
+				appendFloat32(&page.buf, page.textRise+float32(g.yOffset)*font.size/float32(font.unitsPerEm))
+				appendString(&page.buf, " Ts\n[<")
+			}
+			if adjust := shift - int(math.Round(k*float64(g.xOffset))); adjust != 0 {
+				appendString(&page.buf, ">")
+				appendInteger(&page.buf, adjust)
+				appendString(&page.buf, "<")
+				shift -= adjust
+				page.textX -= float32(adjust) * font.size / 1000.0
+			}
+			page.appendGlyph(font, g.gid, float32(g.yOffset)*font.size/float32(font.unitsPerEm))
+			if runes[g.cluster] == 0x0020 {
+				page.appendWordSpacing(font)
+			}
+			shift += width - advance
+			if g.yOffset != 0 {
+				appendString(&page.buf, ">] TJ\n")
+				appendFloat32(&page.buf, page.textRise)
+				appendString(&page.buf, " Ts\n[<")
+			}
+		}
+		if actualText {
+			appendString(&page.buf, ">] TJ\nEMC\n[<")
+		}
+		start = span.end
+	}
+	if shift != 0 {
+		appendString(&page.buf, ">")
+		appendInteger(&page.buf, shift)
+		appendString(&page.buf, "<")
+		page.textX -= float32(shift) * font.size / 1000.0
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
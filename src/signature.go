@@ -0,0 +1,344 @@
+package pdfjet
+
+/**
+ * signature.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // Registers crypto.SHA384 and crypto.SHA512
+	"crypto/x509"
+	"encoding/asn1"
+	"hash"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// Signature is used to create PAdES B-B digital signatures.
+// The signature value is detached CMS created using the caller supplied crypto.Signer.
+type Signature struct {
+	signer      crypto.Signer
+	chain       []*x509.Certificate
+	hash        crypto.Hash
+	fieldName   string
+	name        string
+	reason      string
+	location    string
+	contactInfo string
+	signingTime time.Time
+	x, y, w, h  float32
+	page        *Page
+	annotation  *Annotation
+	objNumber   int
+	size        int           // The size of the space reserved for the CMS
+	digest      hash.Hash     // Digest of the signed byte ranges
+	writer      *bufio.Writer // The writer that receives the signed PDF
+	tail        bytes.Buffer  // Everything after the start of the /Contents value
+	contents    int           // The offset of the /Contents value
+	byteRange   int           // The offset of the /ByteRange values
+}
+
+// NewSignature constructs signature object.
+// The chain must start with the certificate of the signer.
+// @param signer the signer that has access to the private key.
+// @param chain the certificate chain.
+func NewSignature(signer crypto.Signer, chain []*x509.Certificate) *Signature {
+	sig := new(Signature)
+	sig.signer = signer
+	sig.chain = chain
+	sig.hash = crypto.SHA256
+	sig.fieldName = "Signature1"
+	sig.signingTime = time.Now()
+	sig.size = 8192
+	for _, cert := range chain {
+		sig.size += len(cert.Raw)
+	}
+	return sig
+}
+
+// SetDigestAlgorithm sets the digest algorithm.
+// Supported are crypto.SHA256, crypto.SHA384 and crypto.SHA512.
+func (sig *Signature) SetDigestAlgorithm(hash crypto.Hash) {
+	sig.hash = hash
+}
+
+// SetFieldName sets the name of the signature field.
+func (sig *Signature) SetFieldName(fieldName string) {
+	sig.fieldName = fieldName
+}
+
+// SetName sets the name of the person signing the document.
+func (sig *Signature) SetName(name string) {
+	sig.name = name
+}
+
+// SetReason sets the reason for signing.
+func (sig *Signature) SetReason(reason string) {
+	sig.reason = reason
+}
+
+// SetLocation sets the location of the signature widget on the page.
+func (sig *Signature) SetLocation(x, y float32) {
+	sig.x = x
+	sig.y = y
+}
+
+// SetSize sets the size of the signature widget.
+// The default size is zero - invisible signature.
+func (sig *Signature) SetSize(w, h float32) {
+	sig.w = w
+	sig.h = h
+}
+
+// SetSigningLocation sets the place where the document was signed.
+func (sig *Signature) SetSigningLocation(location string) {
+	sig.location = location
+}
+
+// SetContactInfo sets the contact information of the signer.
+func (sig *Signature) SetContactInfo(contactInfo string) {
+	sig.contactInfo = contactInfo
+}
+
+// SetSigningTime sets the claimed signing time.
+func (sig *Signature) SetSigningTime(signingTime time.Time) {
+	sig.signingTime = signingTime
+}
+
+// DrawOn adds the signature widget to the page.
+// When the signature is not drawn on any page an invisible widget is added to the first page.
+func (sig *Signature) DrawOn(page *Page) [2]float32 {
+	sig.page = page
+	sig.annotation = NewAnnotation(
+		nil,
+		nil,
+		sig.x,
+		sig.y,
+		sig.x+sig.w,
+		sig.y+sig.h,
+		"",
+		sig.fieldName,
+		sig.fieldName)
+	sig.annotation.signature = sig
+	page.AddAnnotation(sig.annotation)
+	return [2]float32{sig.x + sig.w, sig.y + sig.h}
+}
+
+func (sig *Signature) getSigningTime() string {
+	return "D:" + sig.signingTime.UTC().Format("20060102150405") + "+00'00'"
+}
+
+var (
+	oidData                 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidSHA256               = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384               = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512               = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidSHA256WithRSA        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidECDSAWithSHA256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384      = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512      = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+)
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+}
+
+type signerInfo struct {
+	Version            int
+	SID                issuerAndSerialNumber
+	DigestAlgorithm    algorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm algorithmIdentifier
+	Signature          []byte
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []algorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      []signerInfo `asn1:"set"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+// getAlgorithms returns the digest and signature algorithm identifiers.
+func (sig *Signature) getAlgorithms() (algorithmIdentifier, algorithmIdentifier, error) {
+	var digestOID, rsaOID, ecdsaOID asn1.ObjectIdentifier
+	switch sig.hash {
+	case crypto.SHA256:
+		digestOID, rsaOID, ecdsaOID = oidSHA256, oidSHA256WithRSA, oidECDSAWithSHA256
+	case crypto.SHA384:
+		digestOID, rsaOID, ecdsaOID = oidSHA384, oidSHA384WithRSA, oidECDSAWithSHA384
+	case crypto.SHA512:
+		digestOID, rsaOID, ecdsaOID = oidSHA512, oidSHA512WithRSA, oidECDSAWithSHA512
+	default:
+		return algorithmIdentifier{}, algorithmIdentifier{},
+			unsupported("signature digest algorithm %v", sig.hash)
+	}
+	digestAlgorithm := algorithmIdentifier{Algorithm: digestOID, Parameters: asn1.NullRawValue}
+	switch sig.signer.Public().(type) {
+	case *rsa.PublicKey:
+		return digestAlgorithm, algorithmIdentifier{Algorithm: rsaOID, Parameters: asn1.NullRawValue}, nil
+	case *ecdsa.PublicKey:
+		return digestAlgorithm, algorithmIdentifier{Algorithm: ecdsaOID}, nil
+	}
+	return algorithmIdentifier{}, algorithmIdentifier{},
+		unsupported("signature key type %T", sig.signer.Public())
+}
+
+func newAttribute(attrType asn1.ObjectIdentifier, value interface{}) ([]byte, error) {
+	encoded, err := asn1.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(attribute{
+		Type:   attrType,
+		Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: encoded},
+	})
+}
+
+// getSignedAttributes returns the DER encoded content of the signed attributes SET.
+func (sig *Signature) getSignedAttributes(messageDigest []byte) ([]byte, error) {
+	certHash := sha256.Sum256(sig.chain[0].Raw)
+	attrs := make([][]byte, 0)
+	attr, err := newAttribute(oidContentType, oidData)
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, attr)
+	attr, err = newAttribute(oidMessageDigest, messageDigest)
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, attr)
+	attr, err = newAttribute(oidSigningCertificateV2, signingCertificateV2{
+		Certs: []essCertIDv2{{CertHash: certHash[:]}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, attr)
+
+	// DER requires the elements of SET OF sorted by their encoding
+	sort.Slice(attrs, func(i, j int) bool {
+		return bytes.Compare(attrs[i], attrs[j]) < 0
+	})
+	return bytes.Join(attrs, nil), nil
+}
+
+// sign returns the detached CMS SignedData for the specified message digest.
+func (sig *Signature) sign(messageDigest []byte) ([]byte, error) {
+	if len(sig.chain) == 0 {
+		return nil, malformed("certificate chain", "the chain is empty")
+	}
+	digestAlgorithm, signatureAlgorithm, err := sig.getAlgorithms()
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := sig.getSignedAttributes(messageDigest)
+	if err != nil {
+		return nil, err
+	}
+	signedAttrs, err := asn1.Marshal(
+		asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attrs})
+	if err != nil {
+		return nil, err
+	}
+	h := sig.hash.New()
+	h.Write(signedAttrs)
+	signature, err := sig.signer.Sign(rand.Reader, h.Sum(nil), sig.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []byte
+	for _, cert := range sig.chain {
+		certs = append(certs, cert.Raw...)
+	}
+	cert := sig.chain[0]
+	data, err := asn1.Marshal(signedData{
+		Version:          1,
+		DigestAlgorithms: []algorithmIdentifier{digestAlgorithm},
+		EncapContentInfo: encapsulatedContentInfo{ContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certs},
+		SignerInfos: []signerInfo{{
+			Version: 1,
+			SID: issuerAndSerialNumber{
+				Issuer:       asn1.RawValue{FullBytes: cert.RawIssuer},
+				SerialNumber: cert.SerialNumber,
+			},
+			DigestAlgorithm:    digestAlgorithm,
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrs},
+			SignatureAlgorithm: signatureAlgorithm,
+			Signature:          signature,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: data},
+	})
+}
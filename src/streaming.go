@@ -0,0 +1,362 @@
+package pdfjet
+
+/**
+ * streaming.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/edragoev1/pdfjet/src/compliance"
+)
+
+// The maximum number of kids of the page tree nodes.
+const pageTreeFanout = 32
+
+// streaming holds the state of the streaming mode.
+// Only the open nodes of the page tree are kept - at most pageTreeFanout kids per level.
+type streaming struct {
+	resObjNumber      int             // The reserved number of the resources object
+	nodes             []*pageTreeNode // The open page tree node on each level, nil if none
+	nextStructParent  int             // The next key in the structure parent tree
+	documentObjNumber int             // The reserved number of the /Document structure element
+	structElements    []int           // The object numbers of the structure elements
+	parentTree        []int           // Key and object number pairs of the structure parent tree
+}
+
+type pageTreeNode struct {
+	objNumber int
+	kids      []int
+	count     int
+}
+
+// SetStreaming enables the constant-memory mode for very large documents.
+// Each page together with its annotations and structure elements is written
+// to the bufio.Writer as soon as the next page is added and the page memory is released.
+// The resources dictionary and the page tree are written by Complete.
+// Only few integers are kept per page for the tagged PDF structure tree.
+// The links to named destinations are written as named destinations - see Page.AddDestination.
+// Must be called before adding any pages. Cannot be combined with SetSignature.
+func (pdf *PDF) SetStreaming() error {
+	if pdf.prevPage != nil || len(pdf.pages) > 0 || pdf.pagesObjNumber != 0 {
+		return errors.New("pdfjet: SetStreaming must be called before adding any pages")
+	}
+	if pdf.incremental {
+		return unsupported("streaming mode for incremental update")
+	}
+	if pdf.signature != nil {
+		return unsupported("streaming mode for signed PDF")
+	}
+	pdf.streaming = new(streaming)
+	return nil
+}
+
+// isTagged returns true if the structure tree is written for the compliance level.
+func (pdf *PDF) isTagged() bool {
+	return pdf.compliance == compliance.PDF_UA ||
+		pdf.compliance == compliance.PDF_A_1A ||
+		pdf.compliance == compliance.PDF_A_1B ||
+		pdf.compliance == compliance.PDF_A_2A ||
+		pdf.compliance == compliance.PDF_A_2B ||
+		pdf.compliance == compliance.PDF_A_3A ||
+		pdf.compliance == compliance.PDF_A_3B
+}
+
+// getPageTreeNode returns the open page tree node on the level.
+// The object number of new node is reserved, because its kids reference it before it is written.
+func (pdf *PDF) getPageTreeNode(level int) *pageTreeNode {
+	st := pdf.streaming
+	if level == len(st.nodes) {
+		st.nodes = append(st.nodes, nil)
+	}
+	if st.nodes[level] == nil {
+		node := new(pageTreeNode)
+		node.objNumber = pdf.reserveObjNumber()
+		st.nodes[level] = node
+	}
+	return st.nodes[level]
+}
+
+// addPageTreeNode writes the page tree node and adds it to its parent.
+// The root node has no parent.
+func (pdf *PDF) addPageTreeNode(level int, root bool) {
+	st := pdf.streaming
+	node := st.nodes[level]
+	st.nodes[level] = nil
+	parent := 0
+	if !root {
+		parentNode := pdf.getPageTreeNode(level + 1)
+		parentNode.kids = append(parentNode.kids, node.objNumber)
+		parentNode.count += node.count
+		parent = parentNode.objNumber
+	}
+
+	pdf.newobjReserved(node.objNumber)
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /Pages\n")
+	if parent != 0 {
+		pdf.appendString("/Parent ")
+		pdf.appendInteger(parent)
+		pdf.appendString(" 0 R\n")
+	}
+	pdf.appendString("/Kids [\n")
+	for _, kid := range node.kids {
+		pdf.appendInteger(kid)
+		pdf.appendString(" 0 R\n")
+	}
+	pdf.appendString("]\n")
+	pdf.appendString("/Count ")
+	pdf.appendInteger(node.count)
+	pdf.appendString("\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+
+	if !root && len(st.nodes[level+1].kids) == pageTreeFanout {
+		pdf.addPageTreeNode(level+1, false)
+	}
+}
+
+// flushPage writes the page content, annotations, page object and structure elements
+// and releases the page memory.
+func (pdf *PDF) flushPage(page *Page) {
+	st := pdf.streaming
+	if st.resObjNumber == 0 {
+		st.resObjNumber = pdf.reserveObjNumber()
+	}
+	tagged := pdf.isTagged()
+	if tagged && st.documentObjNumber == 0 {
+		st.documentObjNumber = pdf.reserveObjNumber()
+	}
+	pdf.addPageContent(page)
+
+	structParents := st.nextStructParent
+	st.nextStructParent++
+	if len(page.structures) > 0 {
+		for _, element := range page.structures {
+			if element.annotation != nil {
+				st.nextStructParent = pdf.addAnnotationObject(element.annotation, st.nextStructParent)
+			}
+		}
+	} else {
+		for _, annotation := range page.annots {
+			if annotation != nil {
+				pdf.addAnnotationObject(annotation, 0)
+			}
+		}
+	}
+
+	node := pdf.getPageTreeNode(0)
+	pdf.newobj()
+	page.objNumber = pdf.getObjNumber()
+	pdf.appendPageDictionary(page, node.objNumber, st.resObjNumber, structParents)
+	pdf.endobj()
+	node.kids = append(node.kids, page.objNumber)
+	node.count++
+	if len(node.kids) == pageTreeFanout {
+		pdf.addPageTreeNode(0, false)
+	}
+
+	for _, destination := range page.destinations {
+		destination.pageObjNumber = page.objNumber
+		pdf.destinations[*destination.name] = destination
+	}
+
+	if tagged {
+		page.setStructElementsPageObjNumber(page.objNumber)
+		pdf.addPageStructElements(page, structParents)
+	}
+
+	page.annots = nil
+	page.structures = nil
+	page.destinations = nil
+	page.contents = nil
+}
+
+// addPageStructElements writes the structure elements of the page
+// and the array of the marked content elements for the structure parent tree.
+func (pdf *PDF) addPageStructElements(page *Page, structParents int) {
+	st := pdf.streaming
+	for _, element := range page.structures {
+		pdf.newobj()
+		element.objNumber = pdf.getObjNumber()
+		pdf.appendString("<<\n/Type /StructElem /S /")
+		pdf.appendString(element.structure)
+		pdf.appendString("\n/P ")
+		pdf.appendInteger(st.documentObjNumber)
+		pdf.appendString(" 0 R\n/Pg ")
+		pdf.appendInteger(element.pageObjNumber)
+		pdf.appendString(" 0 R\n")
+		if element.annotation != nil {
+			pdf.appendString("/K <</Type /OBJR /Obj ")
+			pdf.appendInteger(element.annotation.objNumber)
+			pdf.appendString(" 0 R>>")
+		} else {
+			pdf.appendString("/K ")
+			pdf.appendInteger(element.mcid)
+		}
+		pdf.appendString("\n/Lang ")
+		if element.language != "" {
+			pdf.appendLiteral(element.language)
+		} else {
+			pdf.appendLiteral(pdf.language)
+		}
+		pdf.appendString("\n/Alt ")
+		pdf.appendHexString(encodeToHex(element.altDescription))
+		pdf.appendString("\n/ActualText ")
+		pdf.appendHexString(encodeToHex(element.actualText))
+		pdf.appendString("\n>>\n")
+		pdf.endobj()
+		st.structElements = append(st.structElements, element.objNumber)
+	}
+
+	pdf.newobj()
+	pdf.appendString("[\n")
+	for _, element := range page.structures {
+		if element.annotation == nil {
+			pdf.appendInteger(element.objNumber)
+			pdf.appendString(" 0 R\n")
+		}
+	}
+	pdf.appendString("]\n")
+	pdf.endobj()
+	st.parentTree = append(st.parentTree, structParents, pdf.getObjNumber())
+
+	index := structParents + 1
+	for _, element := range page.structures {
+		if element.annotation != nil {
+			st.parentTree = append(st.parentTree, index, element.objNumber)
+			index++
+		}
+	}
+}
+
+// completeStreamedPages writes the resources object and the rest of the page tree.
+func (pdf *PDF) completeStreamedPages() {
+	st := pdf.streaming
+	if st.resObjNumber == 0 {
+		st.resObjNumber = pdf.reserveObjNumber()
+	}
+	pdf.newobjReserved(st.resObjNumber)
+	pdf.appendResources()
+	pdf.endobj()
+
+	if len(st.nodes) == 0 {
+		pdf.getPageTreeNode(0) // Empty page tree
+	}
+	for level := 0; level < len(st.nodes); level++ {
+		if st.nodes[level] != nil {
+			root := level == len(st.nodes)-1
+			if root {
+				pdf.pagesObjNumber = st.nodes[level].objNumber
+			}
+			pdf.addPageTreeNode(level, root)
+		}
+	}
+}
+
+// addStreamedStructTree writes the structure parent tree, the structure tree root
+// and the /Document structure element. Returns the structure tree root object number.
+func (pdf *PDF) addStreamedStructTree() int {
+	st := pdf.streaming
+	if st.documentObjNumber == 0 {
+		st.documentObjNumber = pdf.reserveObjNumber()
+	}
+
+	pdf.newobj()
+	pdf.appendString("<<\n")
+	pdf.appendString("/Nums [\n")
+	for i := 0; i < len(st.parentTree); i += 2 {
+		pdf.appendInteger(st.parentTree[i])
+		pdf.appendString(" ")
+		pdf.appendInteger(st.parentTree[i+1])
+		pdf.appendString(" 0 R\n")
+	}
+	pdf.appendString("]\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+	parentTreeObjNumber := pdf.getObjNumber()
+
+	pdf.newobj()
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /StructTreeRoot\n")
+	pdf.appendString("/ParentTree ")
+	pdf.appendInteger(parentTreeObjNumber)
+	pdf.appendString(" 0 R\n")
+	pdf.appendString("/K [\n")
+	pdf.appendInteger(st.documentObjNumber)
+	pdf.appendString(" 0 R\n")
+	pdf.appendString("]\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+	structTreeRootObjNumber := pdf.getObjNumber()
+
+	pdf.newobjReserved(st.documentObjNumber)
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /StructElem\n")
+	pdf.appendString("/S /Document\n")
+	pdf.appendString("/P ")
+	pdf.appendInteger(structTreeRootObjNumber)
+	pdf.appendString(" 0 R\n")
+	pdf.appendString("/K [\n")
+	for _, objNumber := range st.structElements {
+		pdf.appendInteger(objNumber)
+		pdf.appendString(" 0 R\n")
+	}
+	pdf.appendString("]\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+
+	return structTreeRootObjNumber
+}
+
+// addStreamedDestinations writes the named destinations tree used by the links.
+func (pdf *PDF) addStreamedDestinations() {
+	if len(pdf.destinations) == 0 {
+		return
+	}
+	names := make([]string, 0, len(pdf.destinations))
+	for name := range pdf.destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pdf.newobj()
+	pdf.appendString("<<\n")
+	pdf.appendString("/Names [\n")
+	for _, name := range names {
+		destination := pdf.destinations[name]
+		pdf.appendLiteral(name)
+		pdf.appendString(" [")
+		pdf.appendInteger(destination.pageObjNumber)
+		pdf.appendString(" 0 R /XYZ ")
+		pdf.appendFloat32(destination.xPosition)
+		pdf.appendString(" ")
+		pdf.appendFloat32(destination.yPosition)
+		pdf.appendString(" 0]\n")
+	}
+	pdf.appendString("]\n")
+	pdf.appendString(">>\n")
+	pdf.endobj()
+	pdf.namesObjNumber = pdf.getObjNumber()
+}
@@ -107,7 +107,21 @@ func (svg *SVG) GetOperations(path string) []*PathOp {
 	return operations
 }
 
+// ToPDF converts the SVG path operations to PDF path operations.
+// The program exits if an argument is not a valid number. Use ToPDFErr to handle the error.
 func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
+	operations, err := svg.ToPDFErr(list)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return operations
+}
+
+// ToPDFErr converts the SVG path operations to PDF path operations.
+// Returns MalformedInputError if an argument is not a valid number.
+func (svg *SVG) ToPDFErr(list []*PathOp) (_ []*PathOp, err error) {
+	defer recoverMalformedInput("SVG path", &err)
+
 	operations := make([]*PathOp, 0)
 	var lastOp *PathOp
 	var x0 float32 = 0.0 // Start of subpath
@@ -119,11 +133,11 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 				var pathOp *PathOp
 				x, err := strconv.ParseFloat(op.args[i], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y, err := strconv.ParseFloat(op.args[i+1], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				if op.cmd == 'm' && lastOp != nil {
 					x += float64(lastOp.x)
@@ -144,11 +158,11 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 				var pathOp *PathOp
 				x, err := strconv.ParseFloat(op.args[i], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y, err := strconv.ParseFloat(op.args[i+1], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				if op.cmd == 'l' && lastOp != nil {
 					x += float64(lastOp.x)
@@ -163,7 +177,7 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 				var pathOp *PathOp
 				x, err := strconv.ParseFloat(op.args[i], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				if op.cmd == 'h' && lastOp != nil {
 					x += float64(lastOp.x)
@@ -177,7 +191,7 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 				var pathOp *PathOp
 				y, err := strconv.ParseFloat(op.args[i], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				if op.cmd == 'v' && lastOp != nil {
 					y += float64(lastOp.y)
@@ -191,19 +205,19 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 				pathOp := NewPathOp('C')
 				x1, err := strconv.ParseFloat(op.args[i], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y1, err := strconv.ParseFloat(op.args[i+1], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				x, err := strconv.ParseFloat(op.args[i+2], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y, err := strconv.ParseFloat(op.args[i+3], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				if op.cmd == 'q' {
 					x1 += float64(lastOp.x)
@@ -235,11 +249,11 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 				}
 				x, err := strconv.ParseFloat(op.args[i], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y, err := strconv.ParseFloat(op.args[i+1], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				if op.cmd == 't' {
 					x += float64(lastOp.x)
@@ -259,27 +273,27 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 				pathOp := NewPathOp('C')
 				x1, err := strconv.ParseFloat(op.args[i], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y1, err := strconv.ParseFloat(op.args[i+1], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				x2, err := strconv.ParseFloat(op.args[i+2], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y2, err := strconv.ParseFloat(op.args[i+3], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				x, err := strconv.ParseFloat(op.args[i+4], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y, err := strconv.ParseFloat(op.args[i+5], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				if op.cmd == 'c' {
 					x1 += float64(lastOp.x)
@@ -308,19 +322,19 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 				}
 				x2, err := strconv.ParseFloat(op.args[i], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y2, err := strconv.ParseFloat(op.args[i+1], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				x, err := strconv.ParseFloat(op.args[i+2], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				y, err := strconv.ParseFloat(op.args[i+3], 32)
 				if err != nil {
-					log.Fatal(err)
+					return nil, malformedWrap("SVG path", "bad number", err)
 				}
 				if op.cmd == 's' {
 					x2 += float64(lastOp.x)
@@ -345,5 +359,5 @@ func (svg *SVG) ToPDF(list []*PathOp) []*PathOp {
 			lastOp = pathOp
 		}
 	}
-	return operations
+	return operations, nil
 }
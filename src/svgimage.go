@@ -50,29 +50,53 @@ type SVGImage struct {
 	structureType  string
 }
 
+// NewSVGImageFromFile constructs SVG image from the file at the specified path.
+// The program exits if the image cannot be read. Use NewSVGImageFromFileErr to handle the error.
 func NewSVGImageFromFile(filePath string) *SVGImage {
-	file, err := os.Open(filePath)
+	image, err := NewSVGImageFromFileErr(filePath)
 	if err != nil {
 		log.Fatal(err)
 	}
+	return image
+}
+
+// NewSVGImageFromFileErr constructs SVG image from the file at the specified path.
+func NewSVGImageFromFileErr(filePath string) (*SVGImage, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
+	}
 	defer file.Close()
 	reader := bufio.NewReader(file)
-	return NewSVGImage(reader)
+	return NewSVGImageErr(reader)
 }
 
 /**
  * Used to embed SVG images in the PDF document.
+ * The program exits if the image cannot be parsed. Use NewSVGImageErr to handle the error.
  *
  * @param stream the input stream.
  */
 func NewSVGImage(reader io.Reader) *SVGImage {
+	image, err := NewSVGImageErr(reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return image
+}
+
+// NewSVGImageErr is used to embed SVG images in the PDF document.
+// Returns IOError or MalformedInputError when the image cannot be read.
+func NewSVGImageErr(reader io.Reader) (_ *SVGImage, err error) {
+	defer recoverMalformedInput("SVG image", &err)
+
 	image := new(SVGImage)
 	colorMap := NewColorMap()
 	image.paths = make([]*SVGPath, 0)
 	var path *SVGPath
 	buffer, err := io.ReadAll(reader)
 	if err != nil {
-		log.Fatal(err)
+		return nil, &IOError{Op: "read", Err: err}
 	}
 	var builder = strings.Builder{}
 	var token = false
@@ -123,31 +147,35 @@ func NewSVGImage(reader io.Reader) *SVGImage {
 			token = false
 			if param == "width" {
 				width, err := strconv.ParseFloat(builder.String(), 32)
-				if err == nil {
-					image.w = float32(width)
-				} else {
-					log.Fatal(err)
+				if err != nil {
+					return nil, malformedWrap("SVG image", "bad width", err)
 				}
+				image.w = float32(width)
 			} else if param == "height" {
 				height, err := strconv.ParseFloat(builder.String(), 32)
-				if err == nil {
-					image.h = float32(height)
-				} else {
-					log.Fatal(err)
+				if err != nil {
+					return nil, malformedWrap("SVG image", "bad height", err)
 				}
+				image.h = float32(height)
 			} else if param == "viewBox" {
 				image.viewBox = builder.String()
 			} else if param == "data" {
 				path.data = builder.String()
 			} else if param == "fill" {
-				var fillColor = getColor(colorMap, builder.String())
+				fillColor, err := getColor(colorMap, builder.String())
+				if err != nil {
+					return nil, err
+				}
 				if header {
 					image.fill = fillColor
 				} else {
 					path.fill = fillColor
 				}
 			} else if param == "stroke" {
-				var strokeColor = getColor(colorMap, builder.String())
+				strokeColor, err := getColor(colorMap, builder.String())
+				if err != nil {
+					return nil, err
+				}
 				if header {
 					image.stroke = strokeColor
 				} else {
@@ -177,26 +205,34 @@ func NewSVGImage(reader io.Reader) *SVGImage {
 	if path != nil {
 		image.paths = append(image.paths, path)
 	}
-	image.processPaths(image.paths)
-	return image
+	if err := image.processPaths(image.paths); err != nil {
+		return nil, err
+	}
+	return image, nil
 }
 
-func (image *SVGImage) processPaths(paths []*SVGPath) {
+func (image *SVGImage) processPaths(paths []*SVGPath) error {
 	box := make([]float32, 4)
 	if image.viewBox != "" {
 		list := strings.Fields(strings.TrimSpace(image.viewBox))
+		if len(list) < len(box) {
+			return malformed("SVG image", "bad viewBox: %s", image.viewBox)
+		}
 		for i := 0; i < len(box); i++ {
 			val, err := strconv.ParseFloat(list[i], 32)
 			if err != nil {
-				log.Fatal(err)
+				return malformedWrap("SVG image", "bad viewBox", err)
 			}
 			box[i] = float32(val)
 		}
 	}
 	svg := NewSVG()
 	for _, path := range paths {
-		path.operations = svg.GetOperations(path.data)
-		path.operations = svg.ToPDF(path.operations)
+		operations, err := svg.ToPDFErr(svg.GetOperations(path.data))
+		if err != nil {
+			return err
+		}
+		path.operations = operations
 		if image.viewBox != "" {
 			for _, op := range path.operations {
 				op.x = (op.x - box[0]) * image.w / box[2]
@@ -208,16 +244,17 @@ func (image *SVGImage) processPaths(paths []*SVGPath) {
 			}
 		}
 	}
+	return nil
 }
 
-func getColor(colorMap map[string]int32, colorName string) int32 {
+func getColor(colorMap map[string]int32, colorName string) (int32, error) {
 	if strings.HasPrefix(colorName, "#") {
 		if len(colorName) == 7 {
 			color, err := strconv.ParseInt(colorName[1:], 16, 32)
 			if err != nil {
-				log.Fatal(err)
+				return 0, malformedWrap("SVG image", "bad color", err)
 			}
-			return int32(color)
+			return int32(color), nil
 		} else if len(colorName) == 4 {
 			str := string([]byte{
 				colorName[1], colorName[1],
@@ -226,18 +263,18 @@ func getColor(colorMap map[string]int32, colorName string) int32 {
 			})
 			color, err := strconv.ParseInt(str, 16, 32)
 			if err != nil {
-				log.Fatal(err)
+				return 0, malformedWrap("SVG image", "bad color", err)
 			}
-			return int32(color)
+			return int32(color), nil
 		} else {
-			return color.Transparent
+			return color.Transparent, nil
 		}
 	}
 	value, ok := colorMap[colorName]
 	if ok {
-		return value
+		return value, nil
 	}
-	return int32(color.Transparent)
+	return int32(color.Transparent), nil
 }
 
 func (image *SVGImage) ScaleBy(factor float32) {
@@ -45,6 +45,7 @@ type Table struct {
 	x1FirstPage     float32
 	y1FirstPage     float32
 	bottomMargin    float32
+	hyphenator      *Hyphenator
 }
 
 // Constants
@@ -68,7 +69,18 @@ func NewTable() *Table {
 	return table
 }
 
+// NewTableFromFile creates table from delimited text file.
+// The program exits if the file cannot be read. Use NewTableFromFileErr to handle the error.
 func NewTableFromFile(f1, f2 *Font, fileName string) *Table {
+	table, err := NewTableFromFileErr(f1, f2, fileName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return table
+}
+
+// NewTableFromFileErr creates table from delimited text file.
+func NewTableFromFileErr(f1, f2 *Font, fileName string) (*Table, error) {
 	table := new(Table)
 	table.numOfHeaderRows = 1
 	table.tableData = make([][]*Cell, 0)
@@ -77,7 +89,7 @@ func NewTableFromFile(f1, f2 *Font, fileName string) *Table {
 	lineNumber := 0
 	f, err := os.Open(fileName)
 	if err != nil {
-		log.Fatal(err)
+		return nil, &IOError{Op: "open", Path: fileName, Err: err}
 	}
 	defer f.Close()
 	scanner := bufio.NewScanner(f)
@@ -117,7 +129,10 @@ func NewTableFromFile(f1, f2 *Font, fileName string) *Table {
 		}
 		lineNumber++
 	}
-	return table
+	if err := scanner.Err(); err != nil {
+		return nil, &IOError{Op: "read", Path: fileName, Err: err}
+	}
+	return table, nil
 }
 
 // SetLocation sets the location (x, y) of the top left corner of table on the page.
@@ -134,6 +149,12 @@ func (table *Table) SetBottomMargin(bottomMargin float32) {
 	table.bottomMargin = bottomMargin
 }
 
+// SetHyphenator sets the hyphenator used to hyphenate the words that do not fit in the cells.
+// @param hyphenator the hyphenator.
+func (table *Table) SetHyphenator(hyphenator *Hyphenator) {
+	table.hyphenator = hyphenator
+}
+
 // SetData sets the table data and specifies the number of header rows in table data.
 func (table *Table) SetData(tableData [][]*Cell, numOfHeaderRows int) {
 	table.tableData = tableData
@@ -553,7 +574,7 @@ func (table *Table) SetColumnWidths() {
 				if cell.textBox != nil {
 					tokens := strings.Fields(cell.textBox.text)
 					for _, token := range tokens {
-						tokenWidth := cell.textBox.font.StringWidth(cell.textBox.fallbackFont, token)
+						tokenWidth := getTextWidth(cell.textBox.getFonts(), token)
 						tokenWidth += cell.leftPadding + cell.rightPadding
 						if tokenWidth > maxColWidths[i] {
 							maxColWidths[i] = tokenWidth
@@ -570,7 +591,7 @@ func (table *Table) SetColumnWidths() {
 						maxColWidths[i] = barcodeWidth
 					}
 				} else if cell.text != nil {
-					textWidth := cell.font.StringWidth(cell.fallbackFont, *cell.text)
+					textWidth := getTextWidth(cell.getFonts(), *cell.text)
 					textWidth += cell.leftPadding + cell.rightPadding
 					if textWidth > maxColWidths[i] {
 						maxColWidths[i] = textWidth
@@ -592,7 +613,7 @@ func (table *Table) addExtraTableRows() [][]*Cell {
 		tableData2 = append(tableData2, row) // Add the original row
 		maxNumVerCells := 0
 		for i := 0; i < len(row); i++ {
-			numVerCells := getNumVerCells(row, i)
+			numVerCells := table.getNumVerCells(row, i)
 			if numVerCells > maxNumVerCells {
 				maxNumVerCells = numVerCells
 			}
@@ -602,6 +623,9 @@ func (table *Table) addExtraTableRows() [][]*Cell {
 			for _, cell := range row {
 				cell2 := NewEmptyCell(cell.GetFont())
 				cell2.SetFallbackFont(cell.GetFallbackFont())
+				if cell.fontChain != nil {
+					cell2.SetFontChain(cell.fontChain)
+				}
 				cell2.SetWidth(cell.GetWidth())
 				cell2.SetLeftPadding(cell.leftPadding)
 				cell2.SetRightPadding(cell.rightPadding)
@@ -641,79 +665,23 @@ func (table *Table) wrapAroundCellText() {
 		for j := 0; j < len(row); j++ {
 			cell := row[j]
 			if cell.text != nil {
-				cellWidth := getTotalWidth(row, j)
-				tokens := strings.Fields(cell.GetText())
-				var n = 0
-				var buf strings.Builder
-				for _, token := range tokens {
-					if cell.font.StringWidth(cell.fallbackFont, token) > cellWidth {
-						if len(buf.String()) > 0 {
-							buf.WriteString(" ")
-						}
-						for _, ch := range token {
-							if cell.font.StringWidth(cell.fallbackFont, strings.TrimSpace(buf.String()+" "+string(ch))) > cellWidth {
-								tableData2[i+n][j].SetText(buf.String())
-								buf.Reset()
-								n++
-							}
-							buf.WriteRune(ch)
-						}
-					} else {
-						if cell.font.StringWidth(cell.fallbackFont, strings.TrimSpace(buf.String()+" "+token)) > cellWidth {
-							tableData2[i+n][j].SetText(strings.TrimSpace(buf.String()))
-							buf.Reset()
-							buf.WriteString(token)
-							n++
-						} else {
-							if len(buf.String()) > 0 {
-								buf.WriteString(" ")
-							}
-							buf.WriteString(token)
-						}
-					}
+				lines := breakLines(cell.getFonts(), cell.GetText(), getTotalWidth(row, j), table.hyphenator)
+				for n, line := range lines {
+					tableData2[i+n][j].SetText(line.text)
 				}
-				tableData2[i+n][j].SetText(strings.TrimSpace(buf.String()))
 			}
 		}
 	}
 	table.tableData = tableData2
 }
 
-func getNumVerCells(row []*Cell, index int) int {
+func (table *Table) getNumVerCells(row []*Cell, index int) int {
 	cell := row[index]
-	numOfVerCells := 1
 	if cell.text == nil {
-		return numOfVerCells
-	}
-	cellWidth := getTotalWidth(row, index)
-	tokens := strings.Fields(*cell.text)
-	var buf strings.Builder
-	for _, token := range tokens {
-		if cell.font.StringWidth(cell.fallbackFont, token) > cellWidth {
-			if len(buf.String()) > 0 {
-				buf.WriteString(" ")
-			}
-			for _, ch := range token {
-				if cell.font.StringWidth(cell.fallbackFont, strings.TrimSpace(buf.String()+" "+string(ch))) > cellWidth {
-					numOfVerCells++
-					buf.Reset()
-				}
-				buf.WriteRune(ch)
-			}
-		} else {
-			if cell.font.StringWidth(cell.fallbackFont, strings.TrimSpace(buf.String()+" "+token)) > cellWidth {
-				numOfVerCells++
-				buf.Reset()
-				buf.WriteString(token)
-			} else {
-				if len(buf.String()) > 0 {
-					buf.WriteString(" ")
-				}
-				buf.WriteString(token)
-			}
-		}
+		return 1
 	}
-	return numOfVerCells
+	lines := breakLines(cell.getFonts(), *cell.text, getTotalWidth(row, index), table.hyphenator)
+	return len(lines)
 }
 
 func getDelimiterRegex(str string) string {
@@ -49,7 +49,7 @@ func NewText(paragraphs []*Paragraph) *Text {
 	text.fallbackFont = paragraphs[0].lines[0].GetFallbackFont()
 	text.leading = text.font.GetBodyHeight()
 	text.paragraphLeading = 2 * text.leading
-	text.spaceBetweenTextLines = text.font.StringWidth(text.fallbackFont, single.Space)
+	text.spaceBetweenTextLines = getTextWidth(paragraphs[0].lines[0].getFonts(), single.Space)
 	text.border = false
 	return text
 }
@@ -151,14 +151,15 @@ func (text *Text) drawTextLine(page *Page, x, y float32, textLine *TextLine) []f
 		if i > 0 {
 			token = single.Space + tokens[i]
 		}
-		lineWidth := textLine.font.StringWidth(textLine.fallbackFont, buf.String())
-		tokenWidth := textLine.font.StringWidth(textLine.fallbackFont, token)
+		lineWidth := getTextWidth(textLine.getFonts(), buf.String())
+		tokenWidth := getTextWidth(textLine.getFonts(), token)
 		if (lineWidth + tokenWidth) < (text.x1+text.width)-text.xText {
 			buf.WriteString(token)
 		} else {
 			if page != nil {
 				textLine2 := NewTextLine(textLine.font, buf.String())
 				textLine2.SetFallbackFont(textLine.fallbackFont)
+				textLine2.fontChain = textLine.fontChain
 				textLine2.SetLocation(text.xText, text.yText+textLine.GetVerticalOffset())
 				textLine2.SetColor(textLine.GetColor())
 				textLine2.SetColorMap(textLine.GetColorMap())
@@ -176,6 +177,7 @@ func (text *Text) drawTextLine(page *Page, x, y float32, textLine *TextLine) []f
 	if page != nil {
 		textLine2 := NewTextLine(textLine.font, buf.String())
 		textLine2.SetFallbackFont(textLine.fallbackFont)
+		textLine2.fontChain = textLine.fontChain
 		textLine2.SetLocation(text.xText, text.yText+textLine.GetVerticalOffset())
 		textLine2.SetColor(textLine.GetColor())
 		textLine2.SetColorMap(textLine.GetColorMap())
@@ -185,7 +187,7 @@ func (text *Text) drawTextLine(page *Page, x, y float32, textLine *TextLine) []f
 		textLine2.DrawOn(page)
 	}
 
-	return []float32{text.xText + textLine.font.StringWidth(textLine.fallbackFont, buf.String()), text.yText}
+	return []float32{text.xText + getTextWidth(textLine.getFonts(), buf.String()), text.yText}
 }
 
 func (text *Text) textIsCJK(str string) bool {
@@ -210,7 +212,7 @@ func (text *Text) tokenizeCJK(textLine *TextLine, textWidth float32) []string {
 	tokens := make([]string, 0)
 	var sb strings.Builder
 	for _, ch := range textLine.text {
-		if text.font.StringWidth(text.fallbackFont, sb.String()+string(ch)) < textWidth {
+		if getTextWidth(textLine.getFonts(), sb.String()+string(ch)) < textWidth {
 			sb.WriteRune(ch)
 		} else {
 			tokens = append(tokens, sb.String())
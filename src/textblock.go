@@ -39,6 +39,7 @@ type TextBlock struct {
 	width              float32
 	height             float32
 	font, fallbackFont *Font
+	fontChain          *FontChain
 	textContent        string
 	textLineHeight     float32
 	textColor          int32
@@ -57,6 +58,7 @@ type TextBlock struct {
 	textAlignment      int
 	underline          bool
 	strikeout          bool
+	hyphenator         *Hyphenator
 
 	colors map[string]int32
 }
@@ -96,17 +98,41 @@ func NewTextBlock(font *Font, textContent string) *TextBlock {
 //	@param font the font.
 func (textBlock *TextBlock) SetFont(font *Font) {
 	textBlock.font = font
+	textBlock.fontChain = nil
 }
 
 // SetFallbackFont sets the fallback font.
 func (textBlock *TextBlock) SetFallbackFont(font *Font) {
 	textBlock.fallbackFont = font
+	textBlock.fontChain = nil
+}
+
+// SetFontChain sets the fonts used to wrap and draw the text of the text block.
+// SetFontSize changes the size of all fonts in the chain.
+func (textBlock *TextBlock) SetFontChain(chain *FontChain) {
+	textBlock.font = chain.GetFont()
+	textBlock.fallbackFont = nil
+	textBlock.fontChain = chain
+}
+
+// getFonts returns the font chain or the font followed by the fallback font.
+func (textBlock *TextBlock) getFonts() []*Font {
+	return getFonts(textBlock.fontChain, textBlock.font, textBlock.fallbackFont)
+}
+
+// SetHyphenator sets the hyphenator used to hyphenate the words that do not fit on a line.
+func (textBlock *TextBlock) SetHyphenator(hyphenator *Hyphenator) {
+	textBlock.hyphenator = hyphenator
 }
 
 // SetFontSize sets the font size for the text block.
 //
 // @param size the font size.
 func (textBlock *TextBlock) SetFontSize(size float32) {
+	if textBlock.fontChain != nil {
+		textBlock.fontChain.SetSize(size)
+		return
+	}
 	textBlock.font.SetSize(size)
 }
 
@@ -201,83 +227,26 @@ func (textBlock *TextBlock) SetHighlightColors(colors map[string]int32) {
 	textBlock.colors = colors
 }
 
-// Sets the brush color.
-// @param color the color specified as 0xRRGGBB integer.
+// SetTextAlignment sets the text alignment.
+// Supported values: alignment.Left, alignment.Right, alignment.Center and alignment.Justify
 func (textBlock *TextBlock) SetTextAlignment(textAlignment int) {
 	textBlock.textAlignment = textAlignment
 }
 
-func (text *TextBlock) textIsCJK(str string) bool {
-	// CJK Unified Ideographs Range: 4E00–9FD5
-	// Hiragana Range: 3040–309F
-	// Katakana Range: 30A0–30FF
-	// Hangul Jamo Range: 1100–11FF
-	numOfCJK := 0
-	runes := []rune(str)
-	for _, ch := range runes {
-		if (ch >= 0x4E00 && ch <= 0x9FD5) ||
-			(ch >= 0x3040 && ch <= 0x309F) ||
-			(ch >= 0x30A0 && ch <= 0x30FF) ||
-			(ch >= 0x1100 && ch <= 0x11FF) {
-			numOfCJK++
-		}
+// getTextAreaWidth returns the length of the text lines.
+func (textBlock *TextBlock) getTextAreaWidth() float32 {
+	if textBlock.textDirection == direction.LeftToRight {
+		return textBlock.width - 2*textBlock.textPadding
 	}
-	return numOfCJK > (len(runes) / 2)
+	// When writting text vertically!
+	return textBlock.height - 2*textBlock.textPadding
 }
 
-func (textBlock *TextBlock) getTextLines() []string {
-	list := make([]string, 0)
-
-	var textAreaWidth float32
-	if textBlock.textDirection == direction.LeftToRight {
-		textAreaWidth = textBlock.width - 2*textBlock.textPadding
-	} else {
-		// When writting text vertically!
-		textAreaWidth = textBlock.height - 2*textBlock.textPadding
-	}
+// getTextLines breaks the text into lines that fit in the text block.
+func (textBlock *TextBlock) getTextLines() []wrappedLine {
 	textBlock.textContent = strings.ReplaceAll(textBlock.textContent, "\r\n", "\n")
 	textBlock.textContent = strings.TrimRight(textBlock.textContent, "\n")
-	lines := strings.Split(textBlock.textContent, "\n")
-	for _, line := range lines {
-		if textBlock.font.StringWidth(textBlock.fallbackFont, line) <= textAreaWidth {
-			list = append(list, line)
-		} else {
-			if textBlock.textIsCJK(line) {
-				var sb strings.Builder
-				for _, ch := range line {
-					if textBlock.font.StringWidth(
-						textBlock.fallbackFont, sb.String()+string(ch)) <= textAreaWidth {
-						sb.WriteRune(ch)
-					} else {
-						list = append(list, sb.String())
-						sb.Reset()
-						sb.WriteRune(ch)
-					}
-				}
-				if sb.Len() > 0 {
-					list = append(list, sb.String())
-				}
-			} else {
-				var sb strings.Builder
-				var tokens = strings.Fields(line)
-				for _, token := range tokens {
-					if textBlock.font.StringWidth(
-						textBlock.fallbackFont, sb.String()+token) <= textAreaWidth {
-						sb.WriteString(token + " ")
-					} else {
-						list = append(list, strings.TrimSpace(sb.String()))
-						sb.Reset()
-						sb.WriteString(token + " ")
-					}
-				}
-				if len(strings.TrimSpace(sb.String())) > 0 {
-					list = append(list, strings.TrimSpace(sb.String()))
-				}
-			}
-		}
-	}
-
-	return list
+	return breakLines(textBlock.getFonts(), textBlock.textContent, textBlock.getTextAreaWidth(), textBlock.hyphenator)
 }
 
 // DrawOn draws text block on the specified page at specified location.
@@ -308,34 +277,49 @@ func (textBlock *TextBlock) DrawOn(page *Page) [2]float32 {
 				xText = textBlock.x + textBlock.textPadding
 			case alignment.Right:
 				xText = (textBlock.x + textBlock.width) -
-					(textBlock.font.StringWidth(textBlock.fallbackFont, line) + textBlock.textPadding)
+					(getTextWidth(textBlock.getFonts(), line.text) + textBlock.textPadding)
 			case alignment.Center:
-				xText = textBlock.x + (textBlock.width-textBlock.font.StringWidth(textBlock.fallbackFont, line))/2
+				xText = textBlock.x + (textBlock.width-getTextWidth(textBlock.getFonts(), line.text))/2
+			case alignment.Justify:
+				// The last line of right-to-left paragraph is aligned to the right.
+				xText = textBlock.x + textBlock.textPadding
+				if line.last && line.level%2 == 1 {
+					xText = (textBlock.x + textBlock.width) -
+						(getTextWidth(textBlock.getFonts(), line.text) + textBlock.textPadding)
+				}
+			}
+			if textBlock.textAlignment == alignment.Justify && !line.last {
+				page.setJustification(textBlock.getFonts(), line.text, textBlock.getTextAreaWidth())
 			}
 			textBlock.drawTextLine(
 				page,
-				textBlock.font,
-				textBlock.fallbackFont,
-				line,
+				textBlock.getFonts(),
+				line.text,
+				line.level,
 				xText,
 				yText,
 				textBlock.textColor,
 				textBlock.colors)
+			page.clearJustification()
 			yText += leading
 		}
 	case direction.BottomToTop:
 		xText = textBlock.x + textBlock.textPadding + ascent
 		yText = textBlock.y + textBlock.height - textBlock.textPadding
 		for _, line := range lines {
+			if textBlock.textAlignment == alignment.Justify && !line.last {
+				page.setJustification(textBlock.getFonts(), line.text, textBlock.getTextAreaWidth())
+			}
 			textBlock.drawTextLine(
 				page,
-				textBlock.font,
-				textBlock.fallbackFont,
-				line,
+				textBlock.getFonts(),
+				line.text,
+				line.level,
 				xText,
 				yText,
 				textBlock.textColor,
 				textBlock.colors)
+			page.clearJustification()
 			xText += leading
 		}
 	}
@@ -370,24 +354,26 @@ func (textBlock *TextBlock) DrawOn(page *Page) [2]float32 {
 	return [2]float32{textBlock.x + textBlock.width, textBlock.y + textBlock.height}
 }
 
-// DrawTextLine draws text line on the page.
+// drawTextLine draws the line of text from paragraph with the specified embedding level.
 func (textBlock *TextBlock) drawTextLine(
 	page *Page,
-	font *Font,
-	fallbackFont *Font,
+	fonts []*Font,
 	text string,
+	level int,
 	xText float32,
 	yText float32,
 	brush int32,
 	colors map[string]int32) {
+	font := fonts[0]
 	page.AddBMC("P", textBlock.language, text, textBlock.altDescription)
 	if textBlock.textDirection == direction.BottomToTop {
 		page.SetTextDirection(90)
 	}
-	page.DrawStringUsingColorMap(font, fallbackFont, text, xText, yText, brush, colors)
+	page.drawStringUsingColorMap(fonts, text, level, xText, yText, brush, colors)
 	page.AddEMC()
 	if textBlock.textDirection == direction.LeftToRight {
-		lineLength := textBlock.font.StringWidth(fallbackFont, text)
+		// The character spacing after the last glyph is not part of the line.
+		lineLength := getTextWidth(fonts, text) + page.getSpacingWidth(fonts, text) - page.charSpacing
 		if textBlock.underline {
 			page.AddArtifactBMC()
 			page.MoveTo(xText, yText+font.underlinePosition)
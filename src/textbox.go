@@ -25,8 +25,6 @@ SOFTWARE.
 */
 
 import (
-	"strings"
-
 	"github.com/edragoev1/pdfjet/src/align"
 	"github.com/edragoev1/pdfjet/src/border"
 	"github.com/edragoev1/pdfjet/src/color"
@@ -48,6 +46,7 @@ import (
 // It was completely rewritten in 2013 by Eugene Dragoev.
 type TextBox struct {
 	font, fallbackFont *Font
+	fontChain          *FontChain
 	text               string
 	x, y               float32
 	width              float32
@@ -60,6 +59,7 @@ type TextBox struct {
 	brush              int32
 	valign             int
 	colors             map[string]int32
+	hyphenator         *Hyphenator
 	// TextBox properties
 	// Future use:
 	// bits 0 to 15
@@ -113,12 +113,17 @@ func NewTextBox(font *Font) *TextBox {
 //	@param font the font.
 func (textBox *TextBox) SetFont(font *Font) {
 	textBox.font = font
+	textBox.fontChain = nil
 }
 
 // SetFontSize sets the font size for the text box.
 //
 // @param size the font size.
 func (textBox *TextBox) SetFontSize(size float32) {
+	if textBox.fontChain != nil {
+		textBox.fontChain.SetSize(size)
+		return
+	}
 	textBox.font.SetSize(size)
 }
 
@@ -361,7 +366,7 @@ func (textBox *TextBox) SetBorders(borders bool) {
 
 // SetTextAlignment sets the cell text alignment.
 // @param alignment the alignment code.
-// Supported values: align.Left, align.Right and align.Center
+// Supported values: align.Left, align.Right, align.Center and align.Justify
 func (textBox *TextBox) SetTextAlignment(alignment int) {
 	textBox.properties &= 0x00CFFFFF
 	textBox.properties |= (alignment & 0x00300000)
@@ -369,7 +374,7 @@ func (textBox *TextBox) SetTextAlignment(alignment int) {
 
 // GetTextAlignment returns the text alignment.
 // @return alignment the alignment code.
-// Supported values: align.Left, align.Right and align.Center
+// Supported values: align.Left, align.Right, align.Center and align.Justify
 func (textBox *TextBox) GetTextAlignment() int {
 	return (textBox.properties & 0x00300000)
 }
@@ -407,6 +412,20 @@ func (textBox *TextBox) GetStrikeout() bool {
 // SetFallbackFont sets the fallback font.
 func (textBox *TextBox) SetFallbackFont(font *Font) {
 	textBox.fallbackFont = font
+	textBox.fontChain = nil
+}
+
+// SetFontChain sets the fonts used to wrap and draw the text of the text box.
+// The line spacing is computed from the first font in the chain.
+func (textBox *TextBox) SetFontChain(chain *FontChain) {
+	textBox.font = chain.GetFont()
+	textBox.fallbackFont = nil
+	textBox.fontChain = chain
+}
+
+// getFonts returns the font chain or the font followed by the fallback font.
+func (textBox *TextBox) getFonts() []*Font {
+	return getFonts(textBox.fontChain, textBox.font, textBox.fallbackFont)
 }
 
 // GetFallbackFont returns the fallback font.
@@ -414,6 +433,11 @@ func (textBox *TextBox) GetFallbackFont() *Font {
 	return textBox.fallbackFont
 }
 
+// SetHyphenator sets the hyphenator used to hyphenate the words that do not fit on a line.
+func (textBox *TextBox) SetHyphenator(hyphenator *Hyphenator) {
+	textBox.hyphenator = hyphenator
+}
+
 // SetVerticalAlignment sets the vertical alignment of the text in textBox TextBox.
 // Valid values are align.Top, align.Bottom and align.Center
 func (textBox *TextBox) SetVerticalAlignment(valign int) {
@@ -464,74 +488,17 @@ func (textBox *TextBox) drawBorders(page *Page) {
 	}
 }
 
-func (text *TextBox) textIsCJK(str string) bool {
-	// CJK Unified Ideographs Range: 4E00–9FD5
-	// Hiragana Range: 3040–309F
-	// Katakana Range: 30A0–30FF
-	// Hangul Jamo Range: 1100–11FF
-	numOfCJK := 0
-	runes := []rune(str)
-	for _, ch := range runes {
-		if (ch >= 0x4E00 && ch <= 0x9FD5) ||
-			(ch >= 0x3040 && ch <= 0x309F) ||
-			(ch >= 0x30A0 && ch <= 0x30FF) ||
-			(ch >= 0x1100 && ch <= 0x11FF) {
-			numOfCJK++
-		}
-	}
-	return numOfCJK > (len(runes) / 2)
-}
-
-func (textBox *TextBox) getTextLines() []string {
-	list := make([]string, 0)
-
-	var textAreaWidth float32
+// getTextAreaWidth returns the length of the text lines.
+func (textBox *TextBox) getTextAreaWidth() float32 {
 	if textBox.textDirection == direction.LeftToRight {
-		textAreaWidth = textBox.width - 2*textBox.margin
-	} else {
-		textAreaWidth = textBox.height - 2*textBox.margin
-	}
-	lines := strings.Split(strings.ReplaceAll(textBox.text, "\r\n", "\n"), "\n")
-	for _, line := range lines {
-		if textBox.font.StringWidth(textBox.fallbackFont, line) <= textAreaWidth {
-			list = append(list, line)
-		} else {
-			if textBox.textIsCJK(line) {
-				var sb strings.Builder
-				for _, ch := range line {
-					if textBox.font.StringWidth(
-						textBox.fallbackFont, sb.String()+string(ch)) <= textAreaWidth {
-						sb.WriteRune(ch)
-					} else {
-						list = append(list, sb.String())
-						sb.Reset()
-						sb.WriteRune(ch)
-					}
-				}
-				if sb.Len() > 0 {
-					list = append(list, sb.String())
-				}
-			} else {
-				var sb strings.Builder
-				var tokens = strings.Fields(line)
-				for _, token := range tokens {
-					if textBox.font.StringWidth(
-						textBox.fallbackFont, sb.String()+token) <= textAreaWidth {
-						sb.WriteString(token + " ")
-					} else {
-						list = append(list, strings.TrimSpace(sb.String()))
-						sb.Reset()
-						sb.WriteString(token + " ")
-					}
-				}
-				if len(strings.TrimSpace(sb.String())) > 0 {
-					list = append(list, strings.TrimSpace(sb.String()))
-				}
-			}
-		}
+		return textBox.width - 2*textBox.margin
 	}
+	return textBox.height - 2*textBox.margin
+}
 
-	return list
+// getTextLines breaks the text into lines that fit in the text box.
+func (textBox *TextBox) getTextLines() []wrappedLine {
+	return breakLines(textBox.getFonts(), textBox.text, textBox.getTextAreaWidth(), textBox.hyphenator)
 }
 
 // DrawOn draws textBox text box on the specified page.
@@ -544,7 +511,7 @@ func (textBox *TextBox) DrawOn(page *Page) [2]float32 {
 
 	if textBox.height > 0.0 { // TextBox with fixed height
 		if float32(len(lines))*leading > (textBox.height - 2*textBox.margin) {
-			list := make([]string, 0)
+			list := make([]wrappedLine, 0)
 			for _, line := range lines {
 				if float32(len(list)+1)*leading > (textBox.height - 2*textBox.margin) {
 					break
@@ -552,13 +519,14 @@ func (textBox *TextBox) DrawOn(page *Page) [2]float32 {
 				list = append(list, line)
 			}
 			if len(list) > 0 {
-				lastLine := list[len(list)-1]
+				lastLine := list[len(list)-1].text
 				runes := []rune(lastLine)
 				if len(runes) > 3 {
 					runes = runes[:len(runes)-3]
 				}
 				lastLine = string(runes)
-				list[len(list)-1] = lastLine + "..."
+				list[len(list)-1].text = lastLine + "..."
+				list[len(list)-1].last = true
 				lines = list
 			}
 		}
@@ -592,15 +560,25 @@ func (textBox *TextBox) DrawOn(page *Page) [2]float32 {
 				if textBox.GetTextAlignment() == align.Left {
 					xText = textBox.x + textBox.margin
 				} else if textBox.GetTextAlignment() == align.Right {
-					xText = (textBox.x + textBox.width) - (textBox.font.StringWidth(textBox.fallbackFont, line) + textBox.margin)
+					xText = (textBox.x + textBox.width) - (getTextWidth(textBox.getFonts(), line.text) + textBox.margin)
 				} else if textBox.GetTextAlignment() == align.Right {
-					xText = textBox.x + (textBox.width-textBox.font.StringWidth(textBox.fallbackFont, line))/2
+					xText = textBox.x + (textBox.width-getTextWidth(textBox.getFonts(), line.text))/2
+				} else if textBox.GetTextAlignment() == align.Justify {
+					// The last line of right-to-left paragraph is aligned to the right.
+					xText = textBox.x + textBox.margin
+					if line.last && line.level%2 == 1 {
+						xText = (textBox.x + textBox.width) - (getTextWidth(textBox.getFonts(), line.text) + textBox.margin)
+					}
 				}
 			} else {
 				xText = textBox.y + textBox.margin
 			}
 			if page != nil {
-				textBox.DrawTextLine(page, textBox.font, textBox.fallbackFont, line, xText, yText, textBox.brush, textBox.colors)
+				if textBox.GetTextAlignment() == align.Justify && !line.last {
+					page.setJustification(textBox.getFonts(), line.text, textBox.getTextAreaWidth())
+				}
+				textBox.drawTextLine(page, textBox.getFonts(), line.text, line.level, xText, yText, textBox.brush, textBox.colors)
+				page.clearJustification()
 			}
 			if textBox.textDirection == direction.LeftToRight ||
 				textBox.textDirection == direction.BottomToTop {
@@ -626,15 +604,25 @@ func (textBox *TextBox) DrawOn(page *Page) [2]float32 {
 				if textBox.GetTextAlignment() == align.Left {
 					xText = textBox.x + textBox.margin
 				} else if textBox.GetTextAlignment() == align.Right {
-					xText = (textBox.x + textBox.width) - (textBox.font.StringWidth(textBox.fallbackFont, line) + textBox.margin)
+					xText = (textBox.x + textBox.width) - (getTextWidth(textBox.getFonts(), line.text) + textBox.margin)
 				} else if textBox.GetTextAlignment() == align.Center {
-					xText = textBox.x + (textBox.width-textBox.font.StringWidth(textBox.fallbackFont, line))/2
+					xText = textBox.x + (textBox.width-getTextWidth(textBox.getFonts(), line.text))/2
+				} else if textBox.GetTextAlignment() == align.Justify {
+					// The last line of right-to-left paragraph is aligned to the right.
+					xText = textBox.x + textBox.margin
+					if line.last && line.level%2 == 1 {
+						xText = (textBox.x + textBox.width) - (getTextWidth(textBox.getFonts(), line.text) + textBox.margin)
+					}
 				}
 			} else {
 				xText = textBox.x + textBox.margin
 			}
 			if page != nil {
-				textBox.DrawTextLine(page, textBox.font, textBox.fallbackFont, line, xText, yText, textBox.brush, textBox.colors)
+				if textBox.GetTextAlignment() == align.Justify && !line.last {
+					page.setJustification(textBox.getFonts(), line.text, textBox.getTextAreaWidth())
+				}
+				textBox.drawTextLine(page, textBox.getFonts(), line.text, line.level, xText, yText, textBox.brush, textBox.colors)
+				page.clearJustification()
 			}
 			if textBox.textDirection == direction.LeftToRight ||
 				textBox.textDirection == direction.BottomToTop {
@@ -674,21 +662,37 @@ func (textBox *TextBox) DrawTextLine(
 	yText float32,
 	brush int32,
 	colors map[string]int32) {
+	textBox.drawTextLine(page, font.getFallbackChain(fallbackFont), text, -1, xText, yText, brush, colors)
+}
+
+// drawTextLine draws the line of text from paragraph with the specified embedding level.
+// The decoration lines use the metrics of the first font.
+func (textBox *TextBox) drawTextLine(
+	page *Page,
+	fonts []*Font,
+	text string,
+	level int,
+	xText float32,
+	yText float32,
+	brush int32,
+	colors map[string]int32) {
+	font := fonts[0]
 	page.AddBMC("P", textBox.language, text, textBox.altDescription)
 	switch textBox.textDirection {
 	case direction.LeftToRight:
-		page.DrawStringUsingColorMap(font, fallbackFont, text, xText, yText, brush, colors)
+		page.drawStringUsingColorMap(fonts, text, level, xText, yText, brush, colors)
 	case direction.BottomToTop:
 		page.SetTextDirection(90)
-		page.DrawStringUsingColorMap(font, fallbackFont, text, yText, xText+textBox.height, textBox.brush, colors)
+		page.drawStringUsingColorMap(fonts, text, level, yText, xText+textBox.height, textBox.brush, colors)
 	case direction.TopToBottom:
 		page.SetTextDirection(270)
-		page.DrawStringUsingColorMap(font, fallbackFont, text,
+		page.drawStringUsingColorMap(fonts, text, level,
 			(yText+textBox.width)-(textBox.margin+2*font.ascent), xText, textBox.brush, colors)
 	}
 	page.AddEMC()
 	if textBox.textDirection == direction.LeftToRight {
-		lineLength := textBox.font.StringWidth(textBox.fallbackFont, text)
+		// The character spacing after the last glyph is not part of the line.
+		lineLength := getTextWidth(fonts, text) + page.getSpacingWidth(fonts, text) - page.charSpacing
 		if textBox.GetUnderline() {
 			page.AddArtifactBMC()
 			page.MoveTo(xText, yText+font.underlinePosition)
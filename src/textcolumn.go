@@ -62,18 +62,30 @@ func (textColumn *TextColumn) TextColumn() {
 */
 
 // NewTextColumn used to create a text column object and set the rotation angle.
+// The program exits if the rotation angle is invalid. Use NewTextColumnErr to handle the error.
 // @param rotateByDegrees the specified rotation angle in degrees.
 func NewTextColumn(rotateByDegrees int) *TextColumn {
+	textColumn, err := NewTextColumnErr(rotateByDegrees)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return textColumn
+}
+
+// NewTextColumnErr used to create a text column object and set the rotation angle.
+// Returns UnsupportedFeatureError if the angle is not 0, 90 or 270 degrees.
+// @param rotateByDegrees the specified rotation angle in degrees.
+func NewTextColumnErr(rotateByDegrees int) (*TextColumn, error) {
+	if rotateByDegrees != 0 && rotateByDegrees != 90 && rotateByDegrees != 270 {
+		return nil, unsupported("text column rotation angle %d, please use 0, 90 or 270 degrees", rotateByDegrees)
+	}
 	textColumn := new(TextColumn)
 	textColumn.alignment = align.Left
 	textColumn.spaceBetweenLines = 1.0
 	textColumn.spaceBetweenParagraphs = 2.0
 	textColumn.rotate = rotateByDegrees
-	if rotateByDegrees != 0 && rotateByDegrees != 90 && rotateByDegrees != 270 {
-		log.Fatal("Invalid rotation angle. Please use 0, 90 or 270 degrees.")
-	}
 	textColumn.paragraphs = make([]*Paragraph, 0)
-	return textColumn
+	return textColumn, nil
 }
 
 // SetLineBetweenParagraphs sets the lineBetweenParagraphs private variable value.
@@ -189,20 +201,21 @@ func (textColumn *TextColumn) drawParagraphOn(page *Page, paragraph *Paragraph)
 			text.SetURIAction(line.GetURIAction())
 			text.SetGoToAction(line.GetGoToAction())
 			text.SetFallbackFont(line.GetFallbackFont())
-			runLength += line.font.StringWidth(line.GetFallbackFont(), token)
+			text.fontChain = line.fontChain
+			runLength += getTextWidth(line.getFonts(), token)
 			if runLength < textColumn.w {
 				list = append(list, text)
-				runLength += line.font.StringWidth(line.GetFallbackFont(), single.Space)
+				runLength += getTextWidth(line.getFonts(), single.Space)
 			} else {
 				textColumn.drawLineOfText(page, list)
 				textColumn.moveToNextLine()
 				list = make([]*TextLine, 0)
 				list = append(list, text)
-				runLength = line.font.StringWidth(line.GetFallbackFont(), token+single.Space)
+				runLength = getTextWidth(line.getFonts(), token+single.Space)
 			}
 		}
 		if !line.GetTrailingSpace() {
-			runLength -= line.font.StringWidth(line.GetFallbackFont(), single.Space)
+			runLength -= getTextWidth(line.getFonts(), single.Space)
 			text.SetTrailingSpace(false)
 		}
 	}
@@ -247,7 +260,7 @@ func (textColumn *TextColumn) drawLineOfText(page *Page, textLines []*TextLine)
 	if textColumn.alignment == align.Justify {
 		var sumOfWordWidths float32
 		for _, textLine := range textLines {
-			sumOfWordWidths += textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text)
+			sumOfWordWidths += getTextWidth(textLine.getFonts(), textLine.text)
 		}
 		dx := (textColumn.w - sumOfWordWidths) / float32(len(textLines)-1)
 		for _, textLine := range textLines {
@@ -258,7 +271,7 @@ func (textColumn *TextColumn) drawLineOfText(page *Page, textLines []*TextLine)
 					textLine.key, // The destination name
 					textColumn.x,
 					page.height-(textColumn.y-textLine.font.ascent),
-					textColumn.x+textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text),
+					textColumn.x+getTextWidth(textLine.getFonts(), textLine.text),
 					page.height-(textColumn.y+textLine.font.descent),
 					"",
 					"",
@@ -268,15 +281,15 @@ func (textColumn *TextColumn) drawLineOfText(page *Page, textLines []*TextLine)
 			if textColumn.rotate == 0 {
 				textLine.SetTextDirection(0)
 				textLine.DrawOn(page)
-				textColumn.x1 += textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text) + dx
+				textColumn.x1 += getTextWidth(textLine.getFonts(), textLine.text) + dx
 			} else if textColumn.rotate == 90 {
 				textLine.SetTextDirection(90)
 				textLine.DrawOn(page)
-				textColumn.y1 -= textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text) + dx
+				textColumn.y1 -= getTextWidth(textLine.getFonts(), textLine.text) + dx
 			} else if textColumn.rotate == 270 {
 				textLine.SetTextDirection(270)
 				textLine.DrawOn(page)
-				textColumn.y1 += textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text) + dx
+				textColumn.y1 += getTextWidth(textLine.getFonts(), textLine.text) + dx
 			}
 		}
 	} else {
@@ -293,7 +306,7 @@ func (textColumn *TextColumn) drawNonJustifiedLine(page *Page, textLines []*Text
 				textLine.text += single.Space
 			}
 		}
-		runLength += textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text)
+		runLength += getTextWidth(textLine.getFonts(), textLine.text)
 	}
 
 	if textColumn.alignment == align.Center {
@@ -322,7 +335,7 @@ func (textColumn *TextColumn) drawNonJustifiedLine(page *Page, textLines []*Text
 				textLine.GetGoToAction(), // The destination name
 				textColumn.x,
 				textColumn.y-textLine.font.ascent,
-				textColumn.x+textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text),
+				textColumn.x+getTextWidth(textLine.getFonts(), textLine.text),
 				textColumn.y+textLine.font.descent,
 				"",
 				"",
@@ -332,15 +345,15 @@ func (textColumn *TextColumn) drawNonJustifiedLine(page *Page, textLines []*Text
 		if textColumn.rotate == 0 {
 			textLine.SetTextDirection(0)
 			textLine.DrawOn(page)
-			textColumn.x1 += textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text)
+			textColumn.x1 += getTextWidth(textLine.getFonts(), textLine.text)
 		} else if textColumn.rotate == 90 {
 			textLine.SetTextDirection(90)
 			textLine.DrawOn(page)
-			textColumn.y1 -= textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text)
+			textColumn.y1 -= getTextWidth(textLine.getFonts(), textLine.text)
 		} else if textColumn.rotate == 270 {
 			textLine.SetTextDirection(270)
 			textLine.DrawOn(page)
-			textColumn.y1 += textLine.font.StringWidth(textLine.GetFallbackFont(), textLine.text)
+			textColumn.y1 += getTextWidth(textLine.getFonts(), textLine.text)
 		}
 	}
 }
@@ -0,0 +1,577 @@
+package pdfjet
+
+/**
+ * textextract.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"log"
+	"math"
+	"strings"
+
+	"github.com/edragoev1/pdfjet/src/letter"
+)
+
+// TextGlyph is glyph of the text read from PDF page.
+// The position is the glyph origin on the baseline in the coordinates used when drawing
+// with pdfjet - the y coordinate grows from the top of the page down.
+type TextGlyph struct {
+	Text     string  // The Unicode text of the glyph - usually single character
+	X        float32 // The x coordinate of the glyph origin
+	Y        float32 // The y coordinate of the glyph origin
+	Width    float32 // The advance width of the glyph
+	Font     string  // The font name without the subset tag
+	FontSize float32 // The font size scaled by the text and the transformation matrices
+
+	dirX, dirY float64 // The direction of the baseline
+}
+
+// PageText is the text of PDF page. The glyphs are in the order they are drawn
+// by the content stream of the page and the form XObjects it uses. The right-to-left
+// text is usually drawn in visual order - see String for the text in logical order.
+type PageText struct {
+	Glyphs []TextGlyph
+}
+
+// String returns the text of the page. The glyphs on the same baseline are joined
+// in lines and the gaps between the words are replaced with spaces. The lines with
+// right-to-left text are reordered from the visual order to the logical order.
+func (text *PageText) String() string {
+	var sb, line strings.Builder
+	var prev *TextGlyph
+	for i := range text.Glyphs {
+		g := &text.Glyphs[i]
+		if prev != nil {
+			// The distance from the end of the previous glyph along and across its baseline
+			size := math.Max(float64(g.FontSize), float64(prev.FontSize))
+			dx := float64(g.X) - (float64(prev.X) + float64(prev.Width)*prev.dirX)
+			dy := float64(g.Y) - (float64(prev.Y) + float64(prev.Width)*prev.dirY)
+			along := dx*prev.dirX + dy*prev.dirY
+			across := dy*prev.dirX - dx*prev.dirY
+			if math.Abs(across) > 0.5*size || along < -size {
+				sb.WriteString(getLogicalOrder(line.String()))
+				sb.WriteString("\n")
+				line.Reset()
+			} else if along > 0.2*size &&
+				!strings.HasSuffix(prev.Text, " ") && !strings.HasPrefix(g.Text, " ") {
+				line.WriteString(" ")
+			}
+		}
+		line.WriteString(getVisualText(g.Text))
+		prev = g
+	}
+	sb.WriteString(getLogicalOrder(line.String()))
+	return sb.String()
+}
+
+// getVisualText returns the text of glyph made from several right-to-left characters reversed,
+// like the text of lam-alef ligature or the replacement text of marked content.
+// The text is reversed back to the logical order together with the right-to-left run.
+func getVisualText(text string) string {
+	runes := []rune(text)
+	if len(runes) < 2 || !hasRightToLeft(text) {
+		return text
+	}
+	return string(reverse(runes))
+}
+
+// getLogicalOrder returns the line of text drawn in visual order in logical order.
+// The right-to-left runs found by the bidirectional algorithm are reversed back.
+// The line is right-to-left paragraph if most of its strong characters are right-to-left.
+func getLogicalOrder(line string) string {
+	if !hasRightToLeft(line) {
+		return line
+	}
+	numLeftToRight, numRightToLeft := 0, 0
+	for _, ch := range line {
+		switch getBidiClass(ch) {
+		case bidiL:
+			numLeftToRight++
+		case bidiR, bidiAL:
+			numRightToLeft++
+		}
+	}
+	level := 0
+	if numRightToLeft > numLeftToRight {
+		level = 1
+	}
+	var sb strings.Builder
+	for _, run := range getBidiRuns(line, level) {
+		if run.level%2 == 0 {
+			sb.WriteString(run.text)
+			continue
+		}
+		runes := []rune(run.text)
+		for i := len(runes) - 1; i >= 0; i-- {
+			sb.WriteRune(runes[i])
+		}
+	}
+	return sb.String()
+}
+
+// ExtractText returns the text of the page object read by PDF.Read.
+// The program exits if the content stream cannot be read. Use ExtractTextErr to handle the error.
+func (obj *PDFobj) ExtractText(objects []*PDFobj) *PageText {
+	text, err := obj.ExtractTextErr(objects)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return text
+}
+
+// ExtractTextErr returns the text of the page object read by PDF.Read.
+// The text is decoded with the ToUnicode CMaps of the fonts, or with their encodings
+// when the fonts have no ToUnicode CMap. The codes that cannot be decoded and the codes
+// mapped to empty string are skipped - their width is added to the previous glyph.
+// The glyphs inside marked content with ActualText are replaced with one glyph with that text.
+// The glyphs inside Artifact marked content, like the layers of color glyphs, are skipped.
+// Returns MalformedInputError if the content stream or the MediaBox cannot be read and
+// UnsupportedFeatureError if the content stream uses filter other than FlateDecode.
+func (obj *PDFobj) ExtractTextErr(objects []*PDFobj) (_ *PageText, err error) {
+	defer recoverMalformedInput("PDF content stream", &err)
+
+	o := newPDFObjects(objects)
+	page, _ := o.getValue(obj).(PDFDict)
+	if page == nil {
+		return nil, malformed("PDF", "object %d is not page", obj.number)
+	}
+	contents, err := o.getPageContents(page)
+	if err != nil {
+		return nil, err
+	}
+	height, err := o.getPageTop(page)
+	if err != nil {
+		return nil, err
+	}
+	extractor := &textExtractor{objects: o, fonts: make(map[pdfRef]*textFont), height: height}
+	resources := o.getDict(o.getInherited(page, "Resources"))
+	if err := extractor.run(contents, resources, newTextState()); err != nil {
+		return nil, err
+	}
+	return &PageText{Glyphs: extractor.glyphs}, nil
+}
+
+// getPageTop returns the y coordinate of the top of the page - the larger y coordinate
+// of the MediaBox. The MediaBox can have non-zero origin and the corners in any order.
+// The height of Letter page is used when the page has no MediaBox.
+func (o *pdfObjects) getPageTop(page PDFDict) (float64, error) {
+	value := o.getInherited(page, "MediaBox")
+	if value == nil {
+		return float64(letter.Portrait[1]), nil
+	}
+	box := o.getArray(value)
+	if len(box) != 4 {
+		return 0.0, malformed("PDF", "bad /MediaBox")
+	}
+	y1, ok1 := o.resolve(box[1]).(float64)
+	y2, ok2 := o.resolve(box[3]).(float64)
+	if !ok1 || !ok2 {
+		return 0.0, malformed("PDF", "bad /MediaBox")
+	}
+	return math.Max(y1, y2), nil
+}
+
+// ExtractText returns the text of all pages read by PDF.Read.
+// The program exits if the text cannot be extracted. Use ExtractTextErr to handle the error.
+func (pdf *PDF) ExtractText(objects []*PDFobj) []*PageText {
+	texts, err := pdf.ExtractTextErr(objects)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return texts
+}
+
+// ExtractTextErr returns the text of all pages read by PDF.Read.
+// See PDFobj.ExtractTextErr for the errors returned.
+func (pdf *PDF) ExtractTextErr(objects []*PDFobj) ([]*PageText, error) {
+	texts := make([]*PageText, 0)
+	pages, err := pdf.GetPageObjectsErr(objects)
+	if err != nil {
+		return nil, err
+	}
+	for _, page := range pages {
+		text, err := page.ExtractTextErr(objects)
+		if err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+	return texts, nil
+}
+
+// The maximum depth of the form XObjects drawn by other form XObjects.
+const maxFormDepth = 12
+
+// textExtractor runs the content stream and collects the glyphs it draws.
+type textExtractor struct {
+	objects    *pdfObjects
+	fonts      map[pdfRef]*textFont
+	glyphs     []TextGlyph
+	height     float64 // The top of the page
+	depth      int     // The depth of the form XObjects
+	actualText int     // The depth of the marked content with replacement text
+	artifact   int     // The depth of the Artifact marked content
+}
+
+// markedContent is the marked content sequence started by BMC or BDC.
+type markedContent struct {
+	start      int    // The position of the first glyph drawn inside the marked content
+	actualText string // The replacement text of the glyphs
+	replaced   bool
+	artifact   bool
+}
+
+// textState is the part of the graphics state used for the text positions.
+type textState struct {
+	ctm         [6]float64
+	font        *textFont
+	size        float64
+	charSpacing float64
+	wordSpacing float64
+	scaling     float64
+	leading     float64
+	rise        float64
+	tm          [6]float64 // The text matrix
+	tlm         [6]float64 // The text line matrix
+}
+
+func newTextState() *textState {
+	return &textState{ctm: [6]float64{1, 0, 0, 1, 0, 0}, scaling: 1.0}
+}
+
+// multiplyMatrix returns m1 × m2 for the matrices [a b c d e f] applied to row vectors.
+func multiplyMatrix(m1, m2 [6]float64) [6]float64 {
+	return [6]float64{
+		m1[0]*m2[0] + m1[1]*m2[2],
+		m1[0]*m2[1] + m1[1]*m2[3],
+		m1[2]*m2[0] + m1[3]*m2[2],
+		m1[2]*m2[1] + m1[3]*m2[3],
+		m1[4]*m2[0] + m1[5]*m2[2] + m2[4],
+		m1[4]*m2[1] + m1[5]*m2[3] + m2[5],
+	}
+}
+
+// getMatrix returns the matrix from six numbers.
+func getMatrix(o *pdfObjects, values []any) ([6]float64, bool) {
+	var m [6]float64
+	if len(values) != 6 {
+		return m, false
+	}
+	for i, value := range values {
+		number, ok := o.resolve(value).(float64)
+		if !ok {
+			return m, false
+		}
+		m[i] = number
+	}
+	return m, true
+}
+
+// run interprets the content stream with the resources.
+func (ex *textExtractor) run(content []byte, resources PDFDict, state *textState) error {
+	stream, err := NewContentStreamErr(content)
+	if err != nil {
+		return err
+	}
+	stack := make([]*textState, 0)
+	marked := make([]markedContent, 0)
+	for _, op := range stream.Operations {
+		if ex.setTextState(op, resources, state) {
+			continue
+		}
+		operands := op.Operands
+		n := len(operands)
+		switch op.Operator {
+		case "q":
+			saved := *state
+			stack = append(stack, &saved)
+		case "Q":
+			if len(stack) > 0 {
+				*state = *stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "Tj", "'", "\"":
+			state.nextLine(op)
+			if n > 0 {
+				if str, ok := operands[n-1].(PDFString); ok {
+					ex.showString(str, state)
+				}
+			}
+		case "TJ":
+			if n > 0 {
+				array, _ := operands[n-1].([]any)
+				for _, element := range array {
+					switch element := element.(type) {
+					case PDFString:
+						ex.showString(element, state)
+					case float64:
+						state.moveText(element)
+					}
+				}
+			}
+		case "Do":
+			if n > 0 {
+				if name, ok := operands[0].(PDFName); ok {
+					if err := ex.drawForm(resources, name, state); err != nil {
+						return err
+					}
+				}
+			}
+		case "BMC", "BDC":
+			mc := markedContent{start: len(ex.glyphs)}
+			if n > 0 && operands[0] == PDFName("Artifact") {
+				mc.artifact = true
+				ex.artifact++
+			}
+			if op.Operator == "BDC" && n == 2 {
+				properties := ex.objects.getDict(operands[1])
+				if name, ok := operands[1].(PDFName); ok {
+					properties = ex.objects.getDict(ex.objects.getDict(resources["Properties"])[string(name)])
+				}
+				if str, ok := ex.objects.resolve(properties["ActualText"]).(PDFString); ok {
+					mc.actualText = decodeTextString(str)
+					mc.replaced = true
+					ex.actualText++
+				}
+			}
+			marked = append(marked, mc)
+		case "EMC":
+			if k := len(marked); k > 0 {
+				mc := marked[k-1]
+				if mc.replaced {
+					ex.replaceGlyphs(mc.start, mc.actualText)
+					ex.actualText--
+				}
+				if mc.artifact {
+					ex.artifact--
+				}
+				marked = marked[:k-1]
+			}
+		}
+	}
+	// The marked content is not closed at the end of the content stream.
+	for _, mc := range marked {
+		if mc.replaced {
+			ex.actualText--
+		}
+		if mc.artifact {
+			ex.artifact--
+		}
+	}
+	return nil
+}
+
+// replaceGlyphs replaces the glyphs drawn after the position start with one glyph with the replacement text.
+// The new glyph covers the replaced glyphs along the baseline of the first one.
+func (ex *textExtractor) replaceGlyphs(start int, text string) {
+	if start >= len(ex.glyphs) {
+		return
+	}
+	g := ex.glyphs[start]
+	first, last := 0.0, 0.0
+	for _, glyph := range ex.glyphs[start:] {
+		along := float64(glyph.X-g.X)*g.dirX + float64(glyph.Y-g.Y)*g.dirY
+		first = math.Min(first, along)
+		last = math.Max(last, along+float64(glyph.Width))
+	}
+	g.Text = text
+	g.X += float32(first * g.dirX)
+	g.Y += float32(first * g.dirY)
+	g.Width = float32(last - first)
+	ex.glyphs = append(ex.glyphs[:start], g)
+}
+
+// setTextState runs the operators that change the text state, the text matrices
+// or the current transformation matrix. Returns false for the other operators.
+func (ex *textExtractor) setTextState(op *ContentOperation, resources PDFDict, state *textState) bool {
+	o := ex.objects
+	switch op.Operator {
+	case "cm":
+		if m, ok := getMatrix(o, op.Operands); ok {
+			state.ctm = multiplyMatrix(m, state.ctm)
+		}
+	case "BT":
+		state.tm = [6]float64{1, 0, 0, 1, 0, 0}
+		state.tlm = state.tm
+	case "Tc":
+		state.charSpacing = op.GetNumber(0)
+	case "Tw":
+		state.wordSpacing = op.GetNumber(0)
+	case "Tz":
+		state.scaling = op.GetNumber(0) / 100.0
+	case "TL":
+		state.leading = op.GetNumber(0)
+	case "Ts":
+		state.rise = op.GetNumber(0)
+	case "Tf":
+		if len(op.Operands) == 2 {
+			state.font = ex.getFont(resources, op.Operands[0])
+			state.size = op.GetNumber(1)
+		}
+	case "Td", "TD":
+		if op.Operator == "TD" {
+			state.leading = -op.GetNumber(1)
+		}
+		state.tlm = multiplyMatrix([6]float64{1, 0, 0, 1, op.GetNumber(0), op.GetNumber(1)}, state.tlm)
+		state.tm = state.tlm
+	case "Tm":
+		if m, ok := getMatrix(o, op.Operands); ok {
+			state.tlm = m
+			state.tm = m
+		}
+	case "T*":
+		state.nextLine(op)
+	default:
+		return false
+	}
+	return true
+}
+
+// nextLine moves to the start of the next line for the T*, ' and " operators
+// and sets the spacing of the " operator.
+func (state *textState) nextLine(op *ContentOperation) {
+	if op.Operator != "T*" && op.Operator != "'" && op.Operator != "\"" {
+		return
+	}
+	if op.Operator == "\"" && len(op.Operands) == 3 {
+		state.wordSpacing = op.GetNumber(0)
+		state.charSpacing = op.GetNumber(1)
+	}
+	state.tlm = multiplyMatrix([6]float64{1, 0, 0, 1, 0, -state.leading}, state.tlm)
+	state.tm = state.tlm
+}
+
+// moveText moves the text matrix by the TJ array number in thousandths of text space unit.
+func (state *textState) moveText(adjustment float64) {
+	tx := -adjustment / 1000.0 * state.size * state.scaling
+	state.tm = multiplyMatrix([6]float64{1, 0, 0, 1, tx, 0}, state.tm)
+}
+
+// getFont returns the font with the resource name.
+// The font missing in the resources is read as font without glyph widths.
+func (ex *textExtractor) getFont(resources PDFDict, name any) *textFont {
+	o := ex.objects
+	key, _ := name.(PDFName)
+	value := o.getDict(resources["Font"])[string(key)]
+	ref, isRef := value.(pdfRef)
+	if isRef {
+		if font, ok := ex.fonts[ref]; ok {
+			return font
+		}
+	}
+	dict := o.getDict(value)
+	if dict == nil {
+		dict = PDFDict{}
+	}
+	font := newTextFont(o, dict)
+	if isRef {
+		ex.fonts[ref] = font
+	}
+	return font
+}
+
+// showString adds the glyphs of the string and moves the text matrix after them.
+func (ex *textExtractor) showString(str []byte, state *textState) {
+	font := state.getFont()
+	first := len(ex.glyphs)
+	for len(str) > 0 {
+		code, length := font.nextCode(str)
+		str = str[length:]
+		m, w0, _ := state.showGlyph(font, code, length)
+		width := float32(w0 * state.size * state.scaling * math.Hypot(m[0], m[1]))
+		if ex.artifact > 0 {
+			continue
+		}
+		text := font.getText(code, length)
+		if text == "" && ex.actualText == 0 {
+			if n := len(ex.glyphs); n > first {
+				ex.glyphs[n-1].Width += width
+			}
+			continue
+		}
+		trm := multiplyMatrix([6]float64{state.size * state.scaling, 0, 0, state.size, 0, state.rise}, m)
+		dirX, dirY := 1.0, 0.0
+		if norm := math.Hypot(m[0], m[1]); norm != 0.0 {
+			dirX, dirY = m[0]/norm, -m[1]/norm
+		}
+		ex.glyphs = append(ex.glyphs, TextGlyph{
+			Text:     text,
+			X:        float32(trm[4]),
+			Y:        float32(ex.height - trm[5]),
+			Width:    width,
+			Font:     font.name,
+			FontSize: float32(state.size * math.Hypot(m[2], m[3])),
+			dirX:     dirX,
+			dirY:     dirY,
+		})
+	}
+}
+
+// getFont returns the current font or font without glyph widths if the font is not set.
+func (state *textState) getFont() *textFont {
+	if state.font == nil {
+		font := &textFont{widths: make(map[int]float64), scale: 0.001}
+		font.simpleText = winAnsiEncoding
+		return font
+	}
+	return state.font
+}
+
+// showGlyph returns the text matrix multiplied by the current transformation matrix,
+// the width of the glyph and the horizontal displacement before the horizontal scaling
+// in text space units, and moves the text matrix after the glyph.
+func (state *textState) showGlyph(font *textFont, code, length int) ([6]float64, float64, float64) {
+	m := multiplyMatrix(state.tm, state.ctm)
+	w0 := font.getWidth(code, length)
+	tx := w0*state.size + state.charSpacing
+	if length == 1 && code == 32 {
+		tx += state.wordSpacing
+	}
+	state.tm = multiplyMatrix([6]float64{1, 0, 0, 1, tx * state.scaling, 0}, state.tm)
+	return m, w0, tx
+}
+
+// drawForm runs the content stream of the form XObject with the resource name.
+func (ex *textExtractor) drawForm(resources PDFDict, name PDFName, state *textState) error {
+	o := ex.objects
+	value := o.getDict(resources["XObject"])[string(name)]
+	form := o.getDict(value)
+	if form == nil || o.getName(form["Subtype"]) != "Form" || ex.depth >= maxFormDepth {
+		return nil
+	}
+	content, err := o.getStreamData(value)
+	if err != nil {
+		return err
+	}
+	formState := *state
+	if m, ok := getMatrix(o, o.getArray(form["Matrix"])); ok {
+		formState.ctm = multiplyMatrix(m, state.ctm)
+	}
+	if formResources := o.getDict(form["Resources"]); formResources != nil {
+		resources = formResources
+	}
+	ex.depth++
+	err = ex.run(content, resources, &formState)
+	ex.depth--
+	return err
+}
@@ -0,0 +1,200 @@
+package pdfjet
+
+/**
+ * textfield.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"strings"
+
+	"github.com/edragoev1/pdfjet/src/align"
+)
+
+// TextField is interactive text field that can be filled in any PDF viewer.
+// The field is written as widget annotation with appearance stream by PDF.Complete.
+// The text is drawn using the font and its size when the field is drawn.
+// Please note: the viewers use the font to show the text typed by the user -
+// core fonts are recommended because the embedded fonts are subset.
+type TextField struct {
+	interactiveField
+	value     string
+	multiline bool
+	password  bool
+	comb      bool
+	maxLen    int
+	alignment int
+}
+
+// NewTextField constructs text field with the specified font and field name.
+func NewTextField(font *Font, name string) *TextField {
+	field := new(TextField)
+	field.init(font, name)
+	field.w = 144.0
+	field.h = font.bodyHeight + 6.0
+	field.alignment = align.Left
+	return field
+}
+
+// SetLocation sets the location of the top left corner of the field on the page.
+func (field *TextField) SetLocation(x, y float32) *TextField {
+	field.x = x
+	field.y = y
+	return field
+}
+
+// SetSize sets the width and height of the field.
+func (field *TextField) SetSize(w, h float32) *TextField {
+	field.w = w
+	field.h = h
+	return field
+}
+
+// SetValue sets the value of the field.
+func (field *TextField) SetValue(value string) *TextField {
+	field.value = value
+	return field
+}
+
+// SetMultiline sets the flag that allows multiple lines of text.
+func (field *TextField) SetMultiline(multiline bool) *TextField {
+	field.multiline = multiline
+	return field
+}
+
+// SetPassword sets the flag that hides the text typed by the user.
+func (field *TextField) SetPassword(password bool) *TextField {
+	field.password = password
+	return field
+}
+
+// SetMaxLength sets the maximum number of characters in the field.
+// The value is truncated to the maximum length.
+func (field *TextField) SetMaxLength(maxLen int) *TextField {
+	field.maxLen = maxLen
+	return field
+}
+
+// SetComb divides the field into as many equally spaced positions as the maximum length.
+// Used only with single line text field that has maximum length.
+func (field *TextField) SetComb(comb bool) *TextField {
+	field.comb = comb
+	return field
+}
+
+// SetTextAlignment sets the alignment of the text - align.Left, align.Center or align.Right.
+func (field *TextField) SetTextAlignment(alignment int) *TextField {
+	field.alignment = alignment
+	return field
+}
+
+// SetToolTip sets the text shown by the viewers when the mouse is over the field.
+// The text is also used as alternate description of the field.
+func (field *TextField) SetToolTip(toolTip string) *TextField {
+	field.toolTip = toolTip
+	return field
+}
+
+// SetReadOnly sets the flag that prevents the user from changing the value.
+func (field *TextField) SetReadOnly(readOnly bool) *TextField {
+	field.setFlag(fieldReadOnly, readOnly)
+	return field
+}
+
+// SetRequired sets the flag that requires value before the form is submitted.
+func (field *TextField) SetRequired(required bool) *TextField {
+	field.setFlag(fieldRequired, required)
+	return field
+}
+
+// SetBorderColor sets the color of the border. Use color.Transparent for no border.
+func (field *TextField) SetBorderColor(borderColor int32) *TextField {
+	field.borderColor = borderColor
+	return field
+}
+
+// SetBackgroundColor sets the background color. The default is color.Transparent.
+func (field *TextField) SetBackgroundColor(backgroundColor int32) *TextField {
+	field.backgroundColor = backgroundColor
+	return field
+}
+
+// SetTextColor sets the color of the text.
+func (field *TextField) SetTextColor(textColor int32) *TextField {
+	field.textColor = textColor
+	return field
+}
+
+// DrawOn adds the field to the page and returns the coordinates of the bottom right corner.
+func (field *TextField) DrawOn(page *Page) [2]float32 {
+	value := field.value
+	runes := []rune(value)
+	if field.maxLen > 0 && len(runes) > field.maxLen {
+		value = string(runes[:field.maxLen])
+	}
+	comb := field.comb && field.maxLen > 0 && !field.multiline && !field.password
+
+	w := field.newWidget("Tx")
+	w.value = value
+	w.maxLen = field.maxLen
+	w.quadding = getQuadding(field.alignment)
+	if field.multiline {
+		w.flags |= fieldMultiline
+	} else if field.password {
+		w.flags |= fieldPassword
+	} else if comb {
+		w.flags |= fieldComb
+	}
+
+	appearance := field.newAppearance(page.pdf)
+	field.beginText(appearance)
+	if field.password {
+		value = strings.Repeat("*", len([]rune(value)))
+	}
+	if comb {
+		cell := field.w / float32(field.maxLen)
+		y := field.getCenteredBaseline()
+		for i, r := range []rune(value) {
+			text := string(r)
+			x := float32(i)*cell + (cell-field.font.stringWidth(text))/2
+			appearance.DrawStringUsingColorMap(field.font, nil, text, x, y, field.textColor, nil)
+		}
+	} else if field.multiline {
+		padding := field.borderWidth + 2.0
+		lines := wrapText(value, float64(field.w-2*padding), 0.0, func(text string, _ float64) float64 {
+			return float64(field.font.stringWidth(text))
+		})
+		y := padding + field.font.ascent
+		for _, line := range lines {
+			field.drawLine(appearance, line, field.alignment, y)
+			y += field.font.bodyHeight
+		}
+	} else {
+		field.drawLine(appearance, value, field.alignment, field.getCenteredBaseline())
+	}
+	field.endText(appearance)
+	w.normal = page.pdf.addAppearanceStream(appearance, field.font)
+
+	field.addWidget(page, w, field.x, field.y, field.w, field.h)
+	page.pdf.formFields = append(page.pdf.formFields, w)
+	return [2]float32{field.x + field.w, field.y + field.h}
+}
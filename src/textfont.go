@@ -0,0 +1,578 @@
+package pdfjet
+
+/**
+ * textfont.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/edragoev1/pdfjet/src/corefont"
+)
+
+// textFont decodes the strings shown with font of PDF read by PDF.Read.
+type textFont struct {
+	name         string // The base font name without the subset tag
+	composite    bool
+	codespaces   []codespaceRange // The code lengths of the composite font
+	cidMap       *pdfCMap         // The CIDs of the composite font codes - nil for Identity encoding
+	unicodeCodes bool             // The composite font codes are UCS-2 or UTF-16 - the predefined Uni CMaps
+	toUnicode    *pdfCMap
+	simpleText   [256]rune       // The text of the simple font codes
+	widths       map[int]float64 // The widths by code of simple font or by CID of composite font
+	defaultWidth float64         // The width of the codes missing in the widths
+	scale        float64         // The glyph space to text space scale
+	codes        map[rune][]byte // The codes of the characters used to encode text - see encode
+}
+
+// codespaceRange is range of the codes with the same length.
+type codespaceRange struct {
+	low, high []byte
+}
+
+// pdfCMap is the mapping of character codes read from CMap - ToUnicode or composite font encoding.
+// The codes of the ranges are mapped to CIDs, UTF-16BE strings or glyph names.
+type pdfCMap struct {
+	codespaces []codespaceRange
+	chars      map[[2]int]any // The mapping by code and code length
+	ranges     []cmapRange
+	parent     string // The name of the CMap used by this CMap
+}
+
+// cmapRange is range of codes mapped to consecutive CIDs or strings or to array of strings.
+type cmapRange struct {
+	low, high, length int
+	dst               any
+}
+
+var coreFontsByName = map[string]func() *corefont.CoreFont{
+	"Courier":               corefont.Courier,
+	"Courier-Bold":          corefont.CourierBold,
+	"Courier-BoldOblique":   corefont.CourierBoldOblique,
+	"Courier-Oblique":       corefont.CourierOblique,
+	"Helvetica":             corefont.Helvetica,
+	"Helvetica-Bold":        corefont.HelveticaBold,
+	"Helvetica-BoldOblique": corefont.HelveticaBoldOblique,
+	"Helvetica-Oblique":     corefont.HelveticaOblique,
+	"Symbol":                corefont.Symbol,
+	"Times-Bold":            corefont.TimesBold,
+	"Times-BoldItalic":      corefont.TimesBoldItalic,
+	"Times-Italic":          corefont.TimesItalic,
+	"Times-Roman":           corefont.TimesRoman,
+	"ZapfDingbats":          corefont.ZapfDingbats,
+}
+
+// newTextFont reads the font dictionary. The fonts that cannot be read
+// completely are still used to move the text position.
+func newTextFont(o *pdfObjects, dict PDFDict) *textFont {
+	font := &textFont{widths: make(map[int]float64), scale: 0.001}
+	font.name = o.getName(dict["BaseFont"])
+	if i := strings.IndexByte(font.name, '+'); i == 6 {
+		font.name = font.name[7:] // The subset tag
+	}
+	if stream, ok := dict["ToUnicode"].(pdfRef); ok {
+		if data, err := o.getStreamData(stream); err == nil {
+			font.toUnicode = parseCMap(data)
+		}
+	}
+	if o.getName(dict["Subtype"]) == "Type0" {
+		font.readCompositeFont(o, dict)
+	} else {
+		font.readSimpleFont(o, dict)
+	}
+	return font
+}
+
+// readCompositeFont reads the encoding CMap and the widths of Type0 font.
+func (font *textFont) readCompositeFont(o *pdfObjects, dict PDFDict) {
+	font.composite = true
+	font.codespaces = []codespaceRange{{[]byte{0x00, 0x00}, []byte{0xFF, 0xFF}}}
+	switch encoding := o.resolve(dict["Encoding"]).(type) {
+	case PDFName:
+		name := string(encoding)
+		font.unicodeCodes = strings.HasPrefix(name, "Uni") &&
+			(strings.Contains(name, "-UCS2-") || strings.Contains(name, "-UTF16-"))
+	case PDFDict:
+		if data, err := o.getStreamData(dict["Encoding"]); err == nil {
+			font.cidMap = parseCMap(data)
+			if len(font.cidMap.codespaces) > 0 {
+				font.codespaces = font.cidMap.codespaces
+			}
+		}
+	}
+
+	descendants := o.getArray(dict["DescendantFonts"])
+	if len(descendants) == 0 {
+		font.defaultWidth = 1000.0
+		return
+	}
+	cidFont := o.getDict(descendants[0])
+	font.defaultWidth = o.getNumber(cidFont["DW"], 1000.0)
+	w := o.getArray(cidFont["W"])
+	for i := 0; i+1 < len(w); {
+		first := int(o.getNumber(w[i], 0.0))
+		if array := o.getArray(w[i+1]); array != nil {
+			for j, width := range array {
+				font.widths[first+j] = o.getNumber(width, font.defaultWidth)
+			}
+			i += 2
+		} else if i+2 < len(w) {
+			last := int(o.getNumber(w[i+1], 0.0))
+			width := o.getNumber(w[i+2], font.defaultWidth)
+			for cid := first; cid <= last && cid-first < 0x10000; cid++ {
+				font.widths[cid] = width
+			}
+			i += 3
+		} else {
+			break
+		}
+	}
+}
+
+// readSimpleFont reads the encoding and the widths of single byte font.
+func (font *textFont) readSimpleFont(o *pdfObjects, dict PDFDict) {
+	subtype := o.getName(dict["Subtype"])
+	descriptor := o.getDict(dict["FontDescriptor"])
+	symbolic := int(o.getNumber(descriptor["Flags"], 0.0))&4 != 0
+
+	var base *[256]rune
+	var differences []any
+	switch encoding := o.resolve(dict["Encoding"]).(type) {
+	case PDFName:
+		base = getBaseEncoding(string(encoding))
+	case PDFDict:
+		base = getBaseEncoding(o.getName(encoding["BaseEncoding"]))
+		differences = o.getArray(encoding["Differences"])
+	}
+	if base == nil && !symbolic && font.name != "Symbol" && font.name != "ZapfDingbats" {
+		if subtype == "TrueType" {
+			base = &winAnsiEncoding
+		} else {
+			base = &standardEncoding
+		}
+	}
+	for code := range font.simpleText {
+		if base != nil {
+			font.simpleText[code] = base[code]
+		} else if code >= 32 {
+			font.simpleText[code] = rune(code) // The codes of the symbolic font without encoding
+		}
+	}
+	code := 0
+	for _, value := range differences {
+		switch value := o.resolve(value).(type) {
+		case float64:
+			code = int(value)
+		case PDFName:
+			if code >= 0 && code < 256 {
+				if text := getGlyphNameText(string(value)); text != "" {
+					font.simpleText[code] = []rune(text)[0]
+				}
+			}
+			code++
+		}
+	}
+
+	if subtype == "Type3" {
+		if matrix := o.getArray(dict["FontMatrix"]); len(matrix) == 6 {
+			font.scale = o.getNumber(matrix[0], 0.001)
+		}
+	}
+	font.defaultWidth = o.getNumber(descriptor["MissingWidth"], 0.0)
+	widths := o.getArray(dict["Widths"])
+	first := int(o.getNumber(dict["FirstChar"], 0.0))
+	for i, width := range widths {
+		font.widths[first+i] = o.getNumber(width, font.defaultWidth)
+	}
+	if widths == nil {
+		// The standard 14 fonts can be used without widths.
+		if coreFont, ok := coreFontsByName[font.name]; ok {
+			for _, metrics := range coreFont().Metrics {
+				font.widths[metrics[0]] = float64(metrics[1])
+			}
+		}
+	}
+}
+
+// getBaseEncoding returns the predefined encoding or nil if the name is not predefined encoding.
+func getBaseEncoding(name string) *[256]rune {
+	switch name {
+	case "WinAnsiEncoding":
+		return &winAnsiEncoding
+	case "MacRomanEncoding":
+		return &macRomanEncoding
+	case "StandardEncoding":
+		return &standardEncoding
+	}
+	return nil
+}
+
+// getGlyphNameText returns the text of the glyph name or empty string if the name is unknown.
+// The ligature names are the names of the components joined with underscores.
+func getGlyphNameText(name string) string {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i] // The suffix of the alternate glyph
+	}
+	var sb strings.Builder
+	for _, component := range strings.Split(name, "_") {
+		if r, ok := glyphNames[component]; ok {
+			sb.WriteRune(r)
+		} else if strings.HasPrefix(component, "uni") && len(component) >= 7 && (len(component)-3)%4 == 0 {
+			for i := 3; i < len(component); i += 4 {
+				n, err := strconv.ParseUint(component[i:i+4], 16, 16)
+				if err != nil {
+					return ""
+				}
+				sb.WriteRune(rune(n))
+			}
+		} else if strings.HasPrefix(component, "u") && len(component) >= 5 && len(component) <= 7 {
+			n, err := strconv.ParseUint(component[1:], 16, 32)
+			if err != nil {
+				return ""
+			}
+			sb.WriteRune(rune(n))
+		} else {
+			return ""
+		}
+	}
+	return sb.String()
+}
+
+// nextCode returns the first code of the string and its length in bytes.
+func (font *textFont) nextCode(str []byte) (int, int) {
+	if !font.composite {
+		return int(str[0]), 1
+	}
+	for n := 1; n <= 4 && n <= len(str); n++ {
+		for _, r := range font.codespaces {
+			if len(r.low) == n && inCodespace(str[:n], r) {
+				if font.unicodeCodes && n == 2 && str[0] >= 0xD8 && str[0] <= 0xDB && len(str) >= 4 {
+					n = 4 // The UTF-16 surrogate pair
+				}
+				return getCode(str[:n]), n
+			}
+		}
+	}
+	// The string that does not match the codespace is read with the shortest code length.
+	n := len(font.codespaces[0].low)
+	for _, r := range font.codespaces {
+		if len(r.low) < n {
+			n = len(r.low)
+		}
+	}
+	if n > len(str) {
+		n = len(str)
+	}
+	return getCode(str[:n]), n
+}
+
+func inCodespace(code []byte, r codespaceRange) bool {
+	for i, b := range code {
+		if b < r.low[i] || b > r.high[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func getCode(str []byte) int {
+	code := 0
+	for _, b := range str {
+		code = code<<8 | int(b)
+	}
+	return code
+}
+
+// getText returns the text of the code. The codes missing in the ToUnicode CMap are decoded
+// with the encoding of the font - the glyph names of the differences or the base encoding.
+// Returns empty string if the text of the code is unknown or the code is mapped to empty
+// string - the extra glyphs of multiple substitution written by pdfjet.
+func (font *textFont) getText(code, length int) string {
+	if font.toUnicode != nil {
+		if value, ok := font.toUnicode.lookup(code, length); ok {
+			switch value := value.(type) {
+			case PDFString:
+				return decodeUTF16String(value)
+			case PDFName:
+				if text := getGlyphNameText(string(value)); text != "" {
+					return text
+				}
+			case float64:
+				return string(rune(value))
+			}
+		}
+	}
+	if font.composite {
+		if font.unicodeCodes {
+			if length == 4 {
+				return string(utf16.DecodeRune(rune(code>>16), rune(code&0xFFFF)))
+			}
+			return string(rune(code))
+		}
+		return ""
+	}
+	if r := font.simpleText[code]; r != 0 {
+		return string(r)
+	}
+	return ""
+}
+
+// getWidth returns the width of the code in text space units.
+func (font *textFont) getWidth(code, length int) float64 {
+	key := code
+	if font.composite && font.cidMap != nil {
+		cid, ok := font.cidMap.lookup(code, length)
+		number, isNumber := cid.(float64)
+		if !ok || !isNumber {
+			return font.defaultWidth * font.scale
+		}
+		key = int(number)
+	} else if font.composite && font.unicodeCodes {
+		return font.defaultWidth * font.scale // The CIDs of the predefined CMaps are not known.
+	}
+	if width, ok := font.widths[key]; ok {
+		return width * font.scale
+	}
+	return font.defaultWidth * font.scale
+}
+
+// getTextWidth returns the width of the string in text space units.
+func (font *textFont) getTextWidth(str []byte) float64 {
+	width := 0.0
+	for len(str) > 0 {
+		code, length := font.nextCode(str)
+		width += font.getWidth(code, length)
+		str = str[length:]
+	}
+	return width
+}
+
+// encode returns the string that shows the text with the font.
+// Returns false if the font has no code for some character of the text.
+func (font *textFont) encode(text string) ([]byte, bool) {
+	if font.codes == nil {
+		font.codes = font.getCodes()
+	}
+	str := make([]byte, 0, len(text))
+	for _, r := range text {
+		if font.composite && font.unicodeCodes {
+			for _, unit := range utf16.Encode([]rune{r}) {
+				str = append(str, byte(unit>>8), byte(unit))
+			}
+			continue
+		}
+		code, ok := font.codes[r]
+		if !ok {
+			return nil, false
+		}
+		str = append(str, code...)
+	}
+	return str, true
+}
+
+// getCodes returns the lowest code of each character that the font can show.
+// The codes of simple font without width are not used - the font subsets have no glyphs for them.
+// The codes of composite font are known only from the ToUnicode CMap.
+func (font *textFont) getCodes() map[rune][]byte {
+	codes := make(map[rune][]byte)
+	add := func(code, length int) {
+		text := []rune(font.getText(code, length))
+		if len(text) != 1 || text[0] == '\uFFFD' {
+			return
+		}
+		if _, ok := codes[text[0]]; ok {
+			return
+		}
+		str := make([]byte, length)
+		for i := range str {
+			str[length-1-i] = byte(code >> (8 * i))
+		}
+		codes[text[0]] = str
+	}
+	if !font.composite {
+		for code := 0; code < 256; code++ {
+			if len(font.widths) == 0 || font.widths[code] != 0.0 {
+				add(code, 1)
+			}
+		}
+		return codes
+	}
+	if font.toUnicode == nil {
+		return codes
+	}
+	keys := make([][2]int, 0, len(font.toUnicode.chars))
+	for key := range font.toUnicode.chars {
+		keys = append(keys, key)
+	}
+	for _, r := range font.toUnicode.ranges {
+		for code := r.low; code <= r.high && code-r.low < 0x10000; code++ {
+			keys = append(keys, [2]int{code, r.length})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][1] != keys[j][1] {
+			return keys[i][1] < keys[j][1]
+		}
+		return keys[i][0] < keys[j][0]
+	})
+	for _, key := range keys {
+		add(key[0], key[1])
+	}
+	return codes
+}
+
+func decodeUTF16String(str []byte) string {
+	if len(str) == 1 {
+		return string(rune(str[0]))
+	}
+	units := make([]uint16, len(str)/2)
+	for i := range units {
+		units[i] = uint16(str[2*i])<<8 | uint16(str[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfDocEncoding is the text of the PDFDocEncoding codes that differ from ISO Latin-1.
+var pdfDocEncoding = map[byte]rune{
+	0x18: 0x02D8, 0x19: 0x02C7, 0x1A: 0x02C6, 0x1B: 0x02D9, 0x1C: 0x02DD, 0x1D: 0x02DB, 0x1E: 0x02DA, 0x1F: 0x02DC,
+	0x80: 0x2022, 0x81: 0x2020, 0x82: 0x2021, 0x83: 0x2026, 0x84: 0x2014, 0x85: 0x2013, 0x86: 0x0192, 0x87: 0x2044,
+	0x88: 0x2039, 0x89: 0x203A, 0x8A: 0x2212, 0x8B: 0x2030, 0x8C: 0x201E, 0x8D: 0x201C, 0x8E: 0x201D, 0x8F: 0x2018,
+	0x90: 0x2019, 0x91: 0x201A, 0x92: 0x2122, 0x93: 0xFB01, 0x94: 0xFB02, 0x95: 0x0141, 0x96: 0x0152, 0x97: 0x0160,
+	0x98: 0x0178, 0x99: 0x017D, 0x9A: 0x0131, 0x9B: 0x0142, 0x9C: 0x0153, 0x9D: 0x0161, 0x9E: 0x017E, 0x9F: 0xFFFD,
+	0xA0: 0x20AC,
+}
+
+// decodeTextString decodes the text string - UTF-16BE or UTF-8 with byte order mark or PDFDocEncoding.
+func decodeTextString(str []byte) string {
+	if len(str) >= 2 && str[0] == 0xFE && str[1] == 0xFF {
+		return decodeUTF16String(str[2:])
+	}
+	if len(str) >= 3 && str[0] == 0xEF && str[1] == 0xBB && str[2] == 0xBF {
+		return string(str[3:])
+	}
+	runes := make([]rune, len(str))
+	for i, b := range str {
+		if r, ok := pdfDocEncoding[b]; ok {
+			runes[i] = r
+		} else {
+			runes[i] = rune(b)
+		}
+	}
+	return string(runes)
+}
+
+// parseCMap reads the CMap stream. The parts that cannot be read are skipped.
+func parseCMap(data []byte) *pdfCMap {
+	cmap := &pdfCMap{chars: make(map[[2]int]any)}
+	lexer := newPDFLexer(data)
+	operands := make([]any, 0)
+	for {
+		value, err := lexer.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return cmap
+		}
+		operator, ok := value.(pdfOperator)
+		if !ok {
+			operands = append(operands, value)
+			continue
+		}
+		switch operator {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				low, ok1 := operands[i].(PDFString)
+				high, ok2 := operands[i+1].(PDFString)
+				if ok1 && ok2 && len(low) == len(high) && len(low) > 0 && len(low) <= 4 {
+					cmap.codespaces = append(cmap.codespaces, codespaceRange{low, high})
+				}
+			}
+		case "endbfchar", "endcidchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if src, ok := operands[i].(PDFString); ok && len(src) > 0 && len(src) <= 4 {
+					cmap.chars[[2]int{getCode(src), len(src)}] = operands[i+1]
+				}
+			}
+		case "endbfrange", "endcidrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok1 := operands[i].(PDFString)
+				high, ok2 := operands[i+1].(PDFString)
+				if ok1 && ok2 && len(low) == len(high) && len(low) > 0 && len(low) <= 4 {
+					cmap.ranges = append(cmap.ranges, cmapRange{getCode(low), getCode(high), len(low), operands[i+2]})
+				}
+			}
+		case "usecmap":
+			if len(operands) > 0 {
+				if name, ok := operands[len(operands)-1].(PDFName); ok {
+					cmap.parent = string(name)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	if len(cmap.codespaces) == 0 && strings.HasPrefix(cmap.parent, "Identity-") {
+		cmap.codespaces = []codespaceRange{{[]byte{0x00, 0x00}, []byte{0xFF, 0xFF}}}
+	}
+	return cmap
+}
+
+// lookup returns the CID, the UTF-16BE string or the glyph name mapped to the code.
+func (cmap *pdfCMap) lookup(code, length int) (any, bool) {
+	if value, ok := cmap.chars[[2]int{code, length}]; ok {
+		return value, true
+	}
+	for _, r := range cmap.ranges {
+		if r.length != length || code < r.low || code > r.high {
+			continue
+		}
+		offset := code - r.low
+		switch dst := r.dst.(type) {
+		case float64:
+			return dst + float64(offset), true
+		case PDFString:
+			// The last two bytes of the string are incremented.
+			str := append(PDFString(nil), dst...)
+			if n := len(str); n >= 2 {
+				unit := (int(str[n-2])<<8 | int(str[n-1])) + offset
+				str[n-2], str[n-1] = byte(unit>>8), byte(unit)
+			} else if n == 1 {
+				str[0] += byte(offset)
+			}
+			return str, true
+		case []any:
+			if offset < len(dst) {
+				return dst[offset], true
+			}
+		}
+		return nil, false
+	}
+	if strings.HasPrefix(cmap.parent, "Identity-") && length == 2 {
+		return float64(code), true
+	}
+	return nil, false
+}
@@ -53,7 +53,7 @@ func NewTextFrame(paragraphs []*TextLine) *TextFrame {
 		textFrame.leading = textFrame.font.GetBodyHeight()
 		textFrame.paragraphLeading = 2 * textFrame.leading
 		textFrame.beginParagraphPoints = make([][]float32, 0)
-		textFrame.spaceBetweenTextLines = textFrame.font.StringWidth(textFrame.fallbackFont, single.Space)
+		textFrame.spaceBetweenTextLines = getTextWidth(textFrame.paragraphs[0].getFonts(), single.Space)
 		// Reverse the paragraphs
 		for i, j := 0, len(paragraphs)-1; i < j; i, j = i+1, j-1 {
 			paragraphs[i], paragraphs[j] = paragraphs[j], paragraphs[i]
@@ -37,6 +37,7 @@ type TextLine struct {
 	text               string
 	x, y, xBox, yBox   float32
 	font, fallbackFont *Font
+	fontChain          *FontChain
 	trailingSpace      bool
 	uri, key           *string
 	underline          bool
@@ -113,6 +114,7 @@ func (textLine *TextLine) SetLocation(x, y float32) *TextLine {
 // @return this TextLine.
 func (textLine *TextLine) SetFont(font *Font) *TextLine {
 	textLine.font = font
+	textLine.fontChain = nil
 	return textLine
 }
 
@@ -126,6 +128,10 @@ func (textLine *TextLine) GetFont() *Font {
 // @param fontSize the fontSize to use.
 // @return this TextLine.
 func (textLine *TextLine) SetFontSize(fontSize float32) *TextLine {
+	if textLine.fontChain != nil {
+		textLine.fontChain.SetSize(fontSize)
+		return textLine
+	}
 	textLine.font.SetSize(fontSize)
 	return textLine
 }
@@ -135,9 +141,27 @@ func (textLine *TextLine) SetFontSize(fontSize float32) *TextLine {
 // @return this TextLine.
 func (textLine *TextLine) SetFallbackFont(fallbackFont *Font) *TextLine {
 	textLine.fallbackFont = fallbackFont
+	textLine.fontChain = nil
+	return textLine
+}
+
+// SetFontChain sets the fonts used to draw this text line.
+// The text line is as high as the tallest font in the chain.
+// SetFont and SetFallbackFont replace the chain.
+// @param chain the font chain.
+// @return this TextLine.
+func (textLine *TextLine) SetFontChain(chain *FontChain) *TextLine {
+	textLine.font = chain.GetFont()
+	textLine.fallbackFont = nil
+	textLine.fontChain = chain
 	return textLine
 }
 
+// getFonts returns the font chain or the font followed by the fallback font.
+func (textLine *TextLine) getFonts() []*Font {
+	return getFonts(textLine.fontChain, textLine.font, textLine.fallbackFont)
+}
+
 // SetFallbackFontSize sets the fallback font size to use for this text line.
 // @param fallbackFontSize the fallback font size.
 // @return this TextLine.
@@ -189,21 +213,31 @@ func (textLine *TextLine) GetDestinationY() float32 {
 // GetWidth returns the width of this TextLine.
 // @return the width.
 func (textLine *TextLine) GetWidth() float32 {
-	return textLine.font.StringWidth(textLine.fallbackFont, textLine.text)
+	return getTextWidth(textLine.getFonts(), textLine.text)
 }
 
 // GetWidth returns the width of this TextLine.
 // @return the width.
 func (textLine *TextLine) GetStringWidth(text string) float32 {
-	return textLine.font.StringWidth(textLine.fallbackFont, text)
+	return getTextWidth(textLine.getFonts(), text)
 }
 
 // GetHeight returns the height of this TextLine.
 // @return the height.
 func (textLine *TextLine) GetHeight() float32 {
-	ascent := math.Max(float64(textLine.font.ascent), float64(textLine.fallbackFont.ascent))
-	descent := math.Max(float64(textLine.font.descent), float64(textLine.fallbackFont.descent))
-	return float32(ascent + descent)
+	fonts := []*Font{textLine.font}
+	if textLine.fontChain != nil {
+		fonts = textLine.fontChain.fonts
+	} else if textLine.fallbackFont != nil {
+		fonts = append(fonts, textLine.fallbackFont)
+	}
+	ascent := fonts[0].ascent
+	descent := fonts[0].descent
+	for _, font := range fonts[1:] {
+		ascent = float32(math.Max(float64(ascent), float64(font.ascent)))
+		descent = float32(math.Max(float64(descent), float64(font.descent)))
+	}
+	return ascent + descent
 }
 
 // SetURIAction sets the URI for the "click text line" action.
@@ -421,14 +455,14 @@ func (textLine *TextLine) DrawOn(page *Page) []float32 {
 
 	page.SetBrushColor(textLine.color)
 	page.AddBMC(textLine.structureType, textLine.language, textLine.actualText, textLine.altDescription)
-	page.DrawStringUsingColorMap(textLine.font, textLine.fallbackFont, textLine.text, textLine.x, textLine.y, textLine.color, textLine.colorMap)
+	page.drawStringUsingColorMap(textLine.getFonts(), textLine.text, -1, textLine.x, textLine.y, textLine.color, textLine.colorMap)
 	page.AddEMC()
 
 	radians := float64(math.Pi) * float64(textLine.degrees) / float64(180.0)
 	if textLine.underline {
 		page.SetPenWidth(textLine.font.underlineThickness)
 		page.SetPenColor(textLine.color)
-		lineLength := textLine.font.StringWidth(textLine.fallbackFont, textLine.text)
+		lineLength := getTextWidth(textLine.getFonts(), textLine.text)
 		xAdjust := textLine.font.underlinePosition*float32(math.Sin(radians)) + textLine.verticalOffset
 		yAdjust := textLine.font.underlinePosition*float32(math.Cos(radians)) + textLine.verticalOffset
 		x2 := textLine.x + lineLength*float32(math.Cos(radians))
@@ -443,7 +477,7 @@ func (textLine *TextLine) DrawOn(page *Page) []float32 {
 	if textLine.strikeout {
 		page.SetPenWidth(textLine.font.underlineThickness)
 		page.SetPenColor(textLine.color)
-		lineLength := textLine.font.StringWidth(textLine.fallbackFont, textLine.text)
+		lineLength := getTextWidth(textLine.getFonts(), textLine.text)
 		xAdjust := (textLine.font.bodyHeight / 4.0) * float32(math.Sin(radians))
 		yAdjust := (textLine.font.bodyHeight / 4.0) * float32(math.Cos(radians))
 		x2 := textLine.x + lineLength*float32(math.Cos(radians))
@@ -461,7 +495,7 @@ func (textLine *TextLine) DrawOn(page *Page) []float32 {
 			textLine.key, // The destination name
 			textLine.x,
 			textLine.y-textLine.font.ascent,
-			textLine.x+textLine.font.StringWidth(textLine.fallbackFont, textLine.text),
+			textLine.x+getTextWidth(textLine.getFonts(), textLine.text),
 			textLine.y+textLine.font.descent,
 			textLine.uriLanguage,
 			textLine.uriActualText,
@@ -470,7 +504,7 @@ func (textLine *TextLine) DrawOn(page *Page) []float32 {
 
 	page.SetTextDirection(0)
 
-	length := textLine.font.StringWidth(textLine.fallbackFont, textLine.text)
+	length := getTextWidth(textLine.getFonts(), textLine.text)
 	xMax := math.Max(float64(textLine.x), float64(textLine.x)+float64(length)*math.Cos(radians))
 	yMax := math.Max(float64(textLine.y), float64(textLine.y)-float64(length)*math.Sin(radians))
 
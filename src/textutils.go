@@ -32,18 +32,33 @@ import (
 	"time"
 )
 
+// ReadTextLines returns the lines of the text file.
+// The program exits if the file cannot be read. Use ReadTextLinesErr to handle the error.
 func ReadTextLines(filePath string) []string {
+	lines, err := ReadTextLinesErr(filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return lines
+}
+
+// ReadTextLinesErr returns the lines of the text file.
+func ReadTextLinesErr(filePath string) ([]string, error) {
 	lines := make([]string, 0)
 	file, err := os.Open(filePath)
 	if err != nil {
-		log.Fatal(err)
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
 	}
+	defer file.Close()
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
 		lines = append(lines, line)
 	}
-	return lines
+	if err := scanner.Err(); err != nil {
+		return nil, &IOError{Op: "read", Path: filePath, Err: err}
+	}
+	return lines, nil
 }
 
 func PrintDuration(example string, duration time.Duration) {
@@ -0,0 +1,70 @@
+package pdfjet
+
+/**
+ * thaishaper.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// shapeThai decomposes SARA AM and applies the default features.
+// The tone marks and the vowels above the consonants are positioned by the GPOS mark features.
+func (s *otShaper) shapeThai() {
+	s.decomposeSaraAm()
+	s.substitute(getGlobalFeatures(defaultSubstitutionFeatures))
+}
+
+// decomposeSaraAm replaces SARA AM with NIKHAHIT and SARA AA so that NIKHAHIT
+// can be placed above the consonant. NIKHAHIT is moved before the tone marks
+// and the other marks above the consonant. The Lao AM is handled the same way.
+func (s *otShaper) decomposeSaraAm() {
+	for i := 0; i < len(s.glyphs); i++ {
+		g := s.glyphs[i]
+		ch := s.runes[g.cluster]
+		if g.length != 1 || (ch != 0x0E33 && ch != 0x0EB3) {
+			continue
+		}
+		nikhahit, saraAa := ch+0x1A, ch-1
+		if !s.font.hasGlyph(nikhahit) || !s.font.hasGlyph(saraAa) {
+			continue
+		}
+		j := i
+		for j > 0 && isThaiAboveMark(s.runes[s.glyphs[j-1].cluster]) {
+			j--
+		}
+		// Both glyphs are in cmap and are extracted as NIKHAHIT and SARA AA.
+		mark := g
+		mark.gid = s.font.getGlyphID(nikhahit)
+		mark.length = 0
+		mark.class = s.getSubstitutedClass(mark.gid, glyphClassMark)
+		g.gid = s.font.getGlyphID(saraAa)
+		g.length = 0
+		g.class = s.getSubstitutedClass(g.gid, glyphClassBase)
+		s.glyphs[i] = g
+		s.glyphs = append(s.glyphs[:j], append([]otGlyph{mark}, s.glyphs[j:]...)...)
+		i++
+	}
+}
+
+// isThaiAboveMark returns true if the character is Thai or Lao mark drawn above the consonant.
+func isThaiAboveMark(ch rune) bool {
+	ch &^= 0x0080 // The Lao characters are 0x80 after the Thai characters
+	return ch == 0x0E31 || (ch >= 0x0E34 && ch <= 0x0E37) || ch == 0x0E3B || (ch >= 0x0E47 && ch <= 0x0E4E)
+}
@@ -0,0 +1,374 @@
+package pdfjet
+
+/**
+ * varfont.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/edragoev1/pdfjet/src/content"
+)
+
+// FontVariation selects the instance of variable font - the named instance
+// and the values of the design axes like weight and width.
+type FontVariation struct {
+	instance string
+	axes     map[string]float32
+}
+
+// NewFontVariation creates font variation that selects the default instance.
+func NewFontVariation() *FontVariation {
+	variation := new(FontVariation)
+	variation.axes = make(map[string]float32)
+	return variation
+}
+
+// SetInstance selects the named instance like "Bold" or "Condensed Light".
+// The axis values set with SetAxis override the values of the instance.
+func (variation *FontVariation) SetInstance(name string) *FontVariation {
+	variation.instance = name
+	return variation
+}
+
+// SetAxis sets the value of the axis with the tag like "wght", "wdth" or "opsz".
+// The values outside of the axis range are clamped to the range.
+func (variation *FontVariation) SetAxis(tag string, value float32) *FontVariation {
+	variation.axes[tag] = value
+	return variation
+}
+
+// NewVariableFont constructs font object from the instance of variable TrueType font.
+// The program exits if the font cannot be loaded. Use NewVariableFontErr to handle the error.
+func NewVariableFont(pdf *PDF, reader io.Reader, variation *FontVariation) *Font {
+	font, err := NewVariableFontErr(pdf, reader, variation)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// NewVariableFontErr constructs font object from the instance of variable TrueType font.
+// The static instance - the outlines and the advance widths computed from the gvar and
+// HVAR tables - is embedded and used to measure the text. The other font metrics are
+// the metrics of the default instance.
+// Returns UnsupportedFeatureError if the font is not variable TrueType font or
+// the variation selects instance or axis that the font does not have.
+func NewVariableFontErr(pdf *PDF, reader io.Reader, variation *FontVariation) (*Font, error) {
+	buf, err := content.GetFromReaderErr(reader)
+	if err != nil {
+		return nil, &IOError{Op: "read", Err: err}
+	}
+	buf, err = decodeWebFont(buf)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := instantiateVariableFont(buf, variation)
+	if err != nil {
+		return nil, err
+	}
+	return NewFontErr(pdf, bytes.NewReader(instance))
+}
+
+// NewVariableFontFromFile constructs font object from the instance of variable .ttf font file.
+// The program exits if the font cannot be loaded. Use NewVariableFontFromFileErr to handle the error.
+func NewVariableFontFromFile(pdf *PDF, filePath string, variation *FontVariation) *Font {
+	font, err := NewVariableFontFromFileErr(pdf, filePath, variation)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return font
+}
+
+// NewVariableFontFromFileErr constructs font object from the instance of variable .ttf font file.
+func NewVariableFontFromFileErr(pdf *PDF, filePath string, variation *FontVariation) (*Font, error) {
+	if strings.HasSuffix(filePath, ".stream") {
+		return nil, unsupported("font variations of .stream fonts")
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, &IOError{Op: "open", Path: filePath, Err: err}
+	}
+	defer f.Close()
+	return NewVariableFontErr(pdf, bufio.NewReader(f), variation)
+}
+
+// The tables of variable font that do not apply to the static instance.
+var variationTables = []string{"HVAR", "MVAR", "STAT", "VVAR", "avar", "cvar", "fvar", "gvar"}
+
+// varAxis is the design axis from the fvar table.
+type varAxis struct {
+	tag                              string
+	minValue, defaultValue, maxValue float64
+}
+
+// instantiateVariableFont returns the static TrueType font with the outlines and
+// the advance widths of the instance selected by the variation.
+func instantiateVariableFont(buf []byte, variation *FontVariation) (_ []byte, err error) {
+	defer recoverMalformedInput("variable font", &err)
+
+	tables := getSfntTables(buf)
+	fvar := tables["fvar"]
+	if fvar == nil {
+		return nil, unsupported("font variations of font without fvar table")
+	}
+	if tables["glyf"] == nil || tables["gvar"] == nil {
+		return nil, unsupported("font variations of font without glyf and gvar tables")
+	}
+
+	// The axes and the user coordinates of the instance
+	axesOffset := u16(fvar, 4)
+	axisCount := u16(fvar, 8)
+	axisSize := u16(fvar, 10)
+	instanceCount := u16(fvar, 12)
+	instanceSize := u16(fvar, 14)
+	axes := make([]varAxis, axisCount)
+	values := make([]float64, axisCount)
+	for i := range axes {
+		record := fvar[axesOffset+i*axisSize:]
+		axes[i] = varAxis{
+			tag:          string(record[0:4]),
+			minValue:     fixedToFloat(record[4:]),
+			defaultValue: fixedToFloat(record[8:]),
+			maxValue:     fixedToFloat(record[12:]),
+		}
+		values[i] = axes[i].defaultValue
+	}
+	names := tables["name"]
+	fontName := getFontNameString(names, 6)
+	if prefix := getFontNameString(names, 25); prefix != "" {
+		fontName = prefix
+	}
+	fontName = strings.TrimSuffix(fontName, "-Regular")
+	instanceName := ""
+	if variation.instance != "" {
+		found := false
+		for i := 0; i < instanceCount && !found; i++ {
+			record := fvar[axesOffset+axisCount*axisSize+i*instanceSize:]
+			subfamily := getFontNameString(names, u16(record, 0))
+			psName := ""
+			if instanceSize >= 6+4*axisCount {
+				psName = getFontNameString(names, u16(record, 4+4*axisCount))
+			}
+			if strings.EqualFold(subfamily, variation.instance) || (psName != "" && psName == variation.instance) {
+				for j := range values {
+					values[j] = fixedToFloat(record[4+4*j:])
+				}
+				instanceName = psName
+				if instanceName == "" {
+					instanceName = fontName + "-" + strings.ReplaceAll(subfamily, " ", "")
+				}
+				found = true
+			}
+		}
+		if !found {
+			return nil, unsupported("named instance %q", variation.instance)
+		}
+	}
+	tags := make([]string, 0, len(variation.axes))
+	for tag := range variation.axes {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		i := 0
+		for i < len(axes) && axes[i].tag != tag {
+			i++
+		}
+		if i == len(axes) {
+			return nil, unsupported("variation axis %q", tag)
+		}
+		values[i] = float64(variation.axes[tag])
+		instanceName = ""
+	}
+	if instanceName == "" {
+		// The PostScript name of arbitrary instance like "SourceSans_700wght_90wdth"
+		var sb strings.Builder
+		sb.WriteString(fontName)
+		for i, axis := range axes {
+			fmt.Fprintf(&sb, "_%s%s", strconv.FormatFloat(clampFloat64(values[i], axis.minValue, axis.maxValue), 'f', -1, 64), strings.TrimRight(axis.tag, " "))
+		}
+		instanceName = sb.String()
+	}
+
+	instancer := &varInstancer{coords: normalizeAxisValues(axes, values, tables["avar"])}
+	if err := instancer.instantiate(tables); err != nil {
+		return nil, err
+	}
+	if names != nil {
+		tables["name"] = renameFont(names, instanceName)
+	}
+	for _, name := range variationTables {
+		delete(tables, name)
+	}
+	sortedNames := make([]string, 0, len(tables))
+	for name := range tables {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+	return writeTrueType(tables, sortedNames, tables["head"]), nil
+}
+
+// normalizeAxisValues returns the normalized coordinates of the user axis values
+// in the range from -1.0 to 1.0 mapped with the avar table if there is one.
+func normalizeAxisValues(axes []varAxis, values []float64, avar []byte) []float64 {
+	coords := make([]float64, len(axes))
+	for i, axis := range axes {
+		value := clampFloat64(values[i], axis.minValue, axis.maxValue)
+		switch {
+		case value < axis.defaultValue && axis.defaultValue > axis.minValue:
+			coords[i] = (value - axis.defaultValue) / (axis.defaultValue - axis.minValue)
+		case value > axis.defaultValue && axis.maxValue > axis.defaultValue:
+			coords[i] = (value - axis.defaultValue) / (axis.maxValue - axis.defaultValue)
+		}
+		coords[i] = roundF2Dot14(coords[i])
+	}
+	if avar == nil || u16(avar, 6) != len(axes) {
+		return coords
+	}
+	offset := 8
+	for i := range coords {
+		count := u16(avar, offset)
+		maps := avar[offset+2:]
+		offset += 2 + 4*count
+		for j := 1; j < count; j++ {
+			from0, to0 := f2Dot14ToFloat(maps[4*j-4:]), f2Dot14ToFloat(maps[4*j-2:])
+			from1, to1 := f2Dot14ToFloat(maps[4*j:]), f2Dot14ToFloat(maps[4*j+2:])
+			if coords[i] <= from1 {
+				if from1 > from0 {
+					coords[i] = roundF2Dot14(to0 + (to1-to0)*(coords[i]-from0)/(from1-from0))
+				} else {
+					coords[i] = to1
+				}
+				break
+			}
+		}
+	}
+	return coords
+}
+
+func fixedToFloat(data []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(data))) / 65536.0
+}
+
+func f2Dot14ToFloat(data []byte) float64 {
+	return float64(int16(binary.BigEndian.Uint16(data))) / 16384.0
+}
+
+func roundF2Dot14(value float64) float64 {
+	return math.Floor(value*16384.0+0.5) / 16384.0
+}
+
+func clampFloat64(value, minValue, maxValue float64) float64 {
+	if value < minValue {
+		return minValue
+	}
+	if value > maxValue {
+		return maxValue
+	}
+	return value
+}
+
+// getFontNameString returns the name with the ID from the name table.
+// The English Windows names are preferred to the Macintosh names.
+func getFontNameString(name []byte, nameID int) string {
+	if name == nil {
+		return ""
+	}
+	count := u16(name, 2)
+	storage := name[u16(name, 4):]
+	result := ""
+	for i := 0; i < count; i++ {
+		record := name[6+12*i:]
+		if u16(record, 6) != nameID {
+			continue
+		}
+		platformID, encodingID, languageID := u16(record, 0), u16(record, 2), u16(record, 4)
+		text := storage[u16(record, 10) : u16(record, 10)+u16(record, 8)]
+		if platformID == 3 && (encodingID == 1 || encodingID == 10) && languageID == 0x409 {
+			return decodeUTF16BE(text)
+		}
+		if platformID == 1 && encodingID == 0 && languageID == 0 && result == "" {
+			result = string(text)
+		}
+	}
+	return result
+}
+
+func decodeUTF16BE(data []byte) string {
+	w := make([]uint16, len(data)/2)
+	for i := range w {
+		w[i] = binary.BigEndian.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(w))
+}
+
+// renameFont returns the name table with the PostScript name replaced.
+// The records with language tags are removed.
+func renameFont(name []byte, psName string) []byte {
+	if name == nil {
+		return nil
+	}
+	count := u16(name, 2)
+	storage := name[u16(name, 4):]
+	records := make([]byte, 0, 12*count)
+	texts := make([]byte, 0, len(storage))
+	for i := 0; i < count; i++ {
+		record := name[6+12*i:]
+		platformID, languageID, nameID := u16(record, 0), u16(record, 4), u16(record, 6)
+		if languageID >= 0x8000 {
+			continue
+		}
+		text := storage[u16(record, 10) : u16(record, 10)+u16(record, 8)]
+		if nameID == 6 {
+			if platformID == 1 {
+				text = []byte(psName)
+			} else {
+				text = make([]byte, 0, 2*len(psName))
+				for _, ch := range utf16.Encode([]rune(psName)) {
+					text = binary.BigEndian.AppendUint16(text, ch)
+				}
+			}
+		}
+		records = append(records, record[:8]...)
+		records = binary.BigEndian.AppendUint16(records, uint16(len(text)))
+		records = binary.BigEndian.AppendUint16(records, uint16(len(texts)))
+		texts = append(texts, text...)
+	}
+	table := make([]byte, 6, 6+len(records)+len(texts))
+	binary.BigEndian.PutUint16(table[2:], uint16(len(records)/12))
+	binary.BigEndian.PutUint16(table[4:], uint16(6+len(records)))
+	table = append(table, records...)
+	return append(table, texts...)
+}
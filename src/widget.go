@@ -0,0 +1,456 @@
+package pdfjet
+
+/**
+ * widget.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/edragoev1/pdfjet/src/align"
+	"github.com/edragoev1/pdfjet/src/color"
+	"github.com/edragoev1/pdfjet/src/compressor"
+)
+
+// interactiveField holds the properties shared by the interactive form fields.
+type interactiveField struct {
+	font            *Font
+	name            string
+	toolTip         string
+	x, y, w, h      float32
+	flags           int
+	borderColor     int32
+	backgroundColor int32
+	textColor       int32
+	borderWidth     float32
+}
+
+// widget holds the entries of the widget annotation and the field written by PDF.Complete.
+// The field and its widget are merged into one object except for the radio button groups.
+type widget struct {
+	fieldType       string // Tx, Btn or Ch - empty for the widgets of radio button group
+	name            string
+	toolTip         string
+	flags           int
+	maxLen          int
+	quadding        int
+	value           string // The value of text field or the selected option of choice field
+	options         []string
+	selected        int    // The index of the selected option of list box or -1
+	state           string // The on state of check box or radio button
+	checked         bool
+	font            *Font
+	da              string // The default appearance
+	caption         string
+	borderColor     int32
+	backgroundColor int32
+	normal          int // The normal appearance or the on appearance of check box and radio button
+	off             int // The off appearance of check box and radio button
+	uri             *string
+	submit          *string
+	reset           bool
+	parent          *widget
+	kids            []*Annotation
+	objNumber       int
+}
+
+func (field *interactiveField) init(font *Font, name string) {
+	field.font = font
+	field.name = name
+	field.borderColor = color.Black
+	field.backgroundColor = color.Transparent
+	field.textColor = color.Black
+	field.borderWidth = 1.0
+}
+
+// setFlag sets or clears the field flag.
+func (field *interactiveField) setFlag(flag int, value bool) {
+	if value {
+		field.flags |= flag
+	} else {
+		field.flags &^= flag
+	}
+}
+
+// newWidget returns the widget with the entries shared by all field types.
+func (field *interactiveField) newWidget(fieldType string) *widget {
+	w := new(widget)
+	w.fieldType = fieldType
+	w.name = field.name
+	w.toolTip = field.toolTip
+	w.flags = field.flags
+	w.selected = -1
+	w.font = field.font
+	w.borderColor = field.borderColor
+	w.backgroundColor = field.backgroundColor
+	if field.font != nil {
+		da := []byte{}
+		appendString(&da, "/")
+		appendString(&da, getFontResourceName(field.font))
+		appendString(&da, " ")
+		appendFloat32(&da, field.font.size)
+		appendString(&da, " Tf ")
+		appendRGB(&da, field.textColor)
+		appendString(&da, " rg")
+		w.da = string(da)
+	}
+	return w
+}
+
+// addWidget adds the widget annotation at the specified location to the page.
+func (field *interactiveField) addWidget(page *Page, w *widget, x, y, width, height float32) *Annotation {
+	text := field.toolTip
+	if text == "" {
+		text = field.name
+	}
+	annotation := NewAnnotation(nil, nil, x, y, x+width, y+height, "", text, text)
+	annotation.widget = w
+	page.AddAnnotation(annotation)
+	return annotation
+}
+
+// newAppearance returns detached page with the background and the border of the field drawn on it.
+// The content of the page becomes the appearance stream of the widget.
+func (field *interactiveField) newAppearance(pdf *PDF) *Page {
+	appearance := NewPageDetached(pdf, [2]float32{field.w, field.h})
+	if field.backgroundColor != color.Transparent {
+		appearance.SetBrushColor(field.backgroundColor)
+		appearance.FillRect(0.0, 0.0, field.w, field.h)
+	}
+	if field.borderColor != color.Transparent {
+		appearance.SetPenColor(field.borderColor)
+		appearance.SetPenWidth(field.borderWidth)
+		appearance.DrawRect(
+			field.borderWidth/2,
+			field.borderWidth/2,
+			field.w-field.borderWidth,
+			field.h-field.borderWidth)
+	}
+	return appearance
+}
+
+// beginText starts the variable text of the appearance clipped to the inside of the border.
+func (field *interactiveField) beginText(appearance *Page) {
+	appendString(&appearance.buf, "/Tx BMC\nq\n")
+	appendFloat32(&appearance.buf, field.borderWidth)
+	appendString(&appearance.buf, " ")
+	appendFloat32(&appearance.buf, field.borderWidth)
+	appendString(&appearance.buf, " ")
+	appendFloat32(&appearance.buf, field.w-2*field.borderWidth)
+	appendString(&appearance.buf, " ")
+	appendFloat32(&appearance.buf, field.h-2*field.borderWidth)
+	appendString(&appearance.buf, " re W n\n")
+}
+
+func (field *interactiveField) endText(appearance *Page) {
+	appendString(&appearance.buf, "Q\nEMC\n")
+}
+
+// drawLine draws single line of text aligned horizontally inside the border.
+// The y is the baseline measured from the top of the field.
+func (field *interactiveField) drawLine(appearance *Page, text string, alignment int, y float32) {
+	padding := field.borderWidth + 2.0
+	x := padding
+	switch alignment {
+	case align.Center:
+		x = (field.w - field.font.stringWidth(text)) / 2
+	case align.Right:
+		x = field.w - padding - field.font.stringWidth(text)
+	}
+	appearance.DrawStringUsingColorMap(field.font, nil, text, x, y, field.textColor, nil)
+}
+
+// getCenteredBaseline returns the baseline of single line of text centered vertically in the field.
+func (field *interactiveField) getCenteredBaseline() float32 {
+	return (field.h-field.font.bodyHeight)/2 + field.font.ascent
+}
+
+// getQuadding returns the /Q value of the alignment.
+func getQuadding(alignment int) int {
+	switch alignment {
+	case align.Center:
+		return 1
+	case align.Right:
+		return 2
+	}
+	return 0
+}
+
+// getFontResourceName returns the name of the font in the resources dictionary.
+func getFontResourceName(font *Font) string {
+	if font.fontID != "" {
+		return font.fontID
+	}
+	return "F" + strconv.Itoa(font.objNumber)
+}
+
+// appendRGB appends the red, green and blue components of the color.
+func appendRGB(buf *[]byte, rgb int32) {
+	appendFloat32(buf, float32((rgb>>16)&0xff)/255.0)
+	appendString(buf, " ")
+	appendFloat32(buf, float32((rgb>>8)&0xff)/255.0)
+	appendString(buf, " ")
+	appendFloat32(buf, float32(rgb&0xff)/255.0)
+}
+
+// getPDFName returns the name in PDF syntax with the special characters escaped.
+func getPDFName(name string) string {
+	var buf bytes.Buffer
+	writePDFValue(&buf, PDFName(name))
+	return buf.String()
+}
+
+// addAppearanceStream writes the content of the detached page as form XObject and returns its object number.
+func (pdf *PDF) addAppearanceStream(appearance *Page, font *Font) int {
+	compressed := pdf.encrypt(compressor.Deflate(appearance.buf))
+	appearance.buf = nil
+
+	pdf.newobj()
+	pdf.appendString("<<\n")
+	pdf.appendString("/Type /XObject\n")
+	pdf.appendString("/Subtype /Form\n")
+	pdf.appendString("/BBox [0 0 ")
+	pdf.appendFloat32(appearance.width)
+	pdf.appendString(" ")
+	pdf.appendFloat32(appearance.height)
+	pdf.appendString("]\n")
+	if font != nil {
+		pdf.appendString("/Resources << /Font << /")
+		pdf.appendString(getFontResourceName(font))
+		pdf.appendString(" ")
+		pdf.appendInteger(font.objNumber)
+		pdf.appendString(" 0 R >> >>\n")
+	}
+	pdf.appendString("/Filter /FlateDecode\n")
+	pdf.appendString("/Length ")
+	pdf.appendInteger(len(compressed))
+	pdf.appendString("\n")
+	pdf.appendString(">>\n")
+	pdf.appendString("stream\n")
+	pdf.appendByteArray(compressed)
+	pdf.appendString("\nendstream\n")
+	pdf.endobj()
+	return pdf.getObjNumber()
+}
+
+// appendWidget appends the entries of the widget annotation.
+// The widget of radio button refers to the field of the group, the other widgets are merged with their fields.
+func (pdf *PDF) appendWidget(w *widget) {
+	pdf.appendString("/Subtype /Widget\n")
+	if w.parent != nil {
+		pdf.appendString("/Parent ")
+		pdf.appendInteger(w.parent.objNumber)
+		pdf.appendString(" 0 R\n")
+	} else {
+		pdf.appendFieldEntries(w)
+	}
+	pdf.appendString("/F 4\n") // Print
+	if w.state != "" {
+		pdf.appendString("/AS ")
+		if w.checked {
+			pdf.appendString(getPDFName(w.state))
+		} else {
+			pdf.appendString("/Off")
+		}
+		pdf.appendString("\n")
+		pdf.appendString("/AP << /N << ")
+		pdf.appendString(getPDFName(w.state))
+		pdf.appendString(" ")
+		pdf.appendInteger(w.normal)
+		pdf.appendString(" 0 R /Off ")
+		pdf.appendInteger(w.off)
+		pdf.appendString(" 0 R >> >>\n")
+	} else {
+		pdf.appendString("/AP << /N ")
+		pdf.appendInteger(w.normal)
+		pdf.appendString(" 0 R >>\n")
+	}
+
+	mk := []byte{}
+	if w.borderColor != color.Transparent {
+		appendString(&mk, " /BC [")
+		appendRGB(&mk, w.borderColor)
+		appendString(&mk, "]")
+	}
+	if w.backgroundColor != color.Transparent {
+		appendString(&mk, " /BG [")
+		appendRGB(&mk, w.backgroundColor)
+		appendString(&mk, "]")
+	}
+	pdf.appendString("/MK <<")
+	pdf.appendByteArray(mk)
+	if w.caption != "" {
+		pdf.appendString(" /CA ")
+		pdf.appendHexString(encodeToHex(w.caption))
+	}
+	pdf.appendString(" >>\n")
+	if w.borderColor != color.Transparent {
+		pdf.appendString("/BS << /W 1 /S /S >>\n")
+	}
+
+	if w.uri != nil {
+		pdf.appendString("/A << /S /URI /URI ")
+		pdf.appendLiteral(*w.uri)
+		pdf.appendString(" >>\n")
+	} else if w.submit != nil {
+		pdf.appendString("/A << /S /SubmitForm /F << /FS /URL /F ")
+		pdf.appendLiteral(*w.submit)
+		pdf.appendString(" >> /Flags 4 >>\n") // ExportFormat - HTML form format
+	} else if w.reset {
+		pdf.appendString("/A << /S /ResetForm >>\n")
+	}
+}
+
+// appendFieldEntries appends the entries of the field dictionary.
+func (pdf *PDF) appendFieldEntries(w *widget) {
+	pdf.appendString("/FT /")
+	pdf.appendString(w.fieldType)
+	pdf.appendString("\n")
+	pdf.appendString("/T ")
+	pdf.appendHexString(encodeToHex(w.name))
+	pdf.appendString("\n")
+	if w.toolTip != "" {
+		pdf.appendString("/TU ")
+		pdf.appendHexString(encodeToHex(w.toolTip))
+		pdf.appendString("\n")
+	}
+	if w.flags != 0 {
+		pdf.appendString("/Ff ")
+		pdf.appendInteger(w.flags)
+		pdf.appendString("\n")
+	}
+	if w.da != "" {
+		pdf.appendString("/DA ")
+		pdf.appendLiteral(w.da)
+		pdf.appendString("\n")
+	}
+	if w.quadding != 0 {
+		pdf.appendString("/Q ")
+		pdf.appendInteger(w.quadding)
+		pdf.appendString("\n")
+	}
+	if w.maxLen > 0 {
+		pdf.appendString("/MaxLen ")
+		pdf.appendInteger(w.maxLen)
+		pdf.appendString("\n")
+	}
+	if w.options != nil {
+		pdf.appendString("/Opt [")
+		for i, option := range w.options {
+			if i > 0 {
+				pdf.appendString(" ")
+			}
+			pdf.appendHexString(encodeToHex(option))
+		}
+		pdf.appendString("]\n")
+	}
+	if w.selected >= 0 {
+		pdf.appendString("/I [")
+		pdf.appendInteger(w.selected)
+		pdf.appendString("]\n")
+	}
+	if w.fieldType == "Btn" && w.flags&fieldPushButton == 0 {
+		pdf.appendString("/V ")
+		if w.checked {
+			pdf.appendString(getPDFName(w.state))
+		} else {
+			pdf.appendString("/Off")
+		}
+		pdf.appendString("\n")
+	} else if w.value != "" {
+		pdf.appendString("/V ")
+		pdf.appendHexString(encodeToHex(w.value))
+		pdf.appendString("\n")
+	}
+}
+
+// addFormFieldObjects writes the fields of the radio button groups.
+// The widgets of the buttons are the kids of the field.
+func (pdf *PDF) addFormFieldObjects() {
+	for _, field := range pdf.formFields {
+		if field.kids == nil {
+			continue
+		}
+		pdf.newobjReserved(field.objNumber)
+		pdf.appendString("<<\n")
+		pdf.appendFieldEntries(field)
+		pdf.appendString("/Kids [")
+		for i, kid := range field.kids {
+			if i > 0 {
+				pdf.appendString(" ")
+			}
+			pdf.appendInteger(kid.objNumber)
+			pdf.appendString(" 0 R")
+		}
+		pdf.appendString("]\n")
+		pdf.appendString(">>\n")
+		pdf.endobj()
+	}
+}
+
+// appendAcroForm appends the interactive form dictionary with the signature field and the form fields.
+func (pdf *PDF) appendAcroForm() {
+	pdf.appendString("/AcroForm <<\n")
+	pdf.appendString("/Fields [")
+	numbers := make([]int, 0)
+	if pdf.signature != nil && pdf.signature.annotation != nil {
+		numbers = append(numbers, pdf.signature.annotation.objNumber)
+	}
+	for _, field := range pdf.formFields {
+		numbers = append(numbers, field.objNumber)
+	}
+	for i, number := range numbers {
+		if i > 0 {
+			pdf.appendString(" ")
+		}
+		pdf.appendInteger(number)
+		pdf.appendString(" 0 R")
+	}
+	pdf.appendString("]\n")
+
+	fonts := make([]*Font, 0)
+	used := make(map[*Font]bool)
+	for _, field := range pdf.formFields {
+		if field.font != nil && !used[field.font] {
+			used[field.font] = true
+			fonts = append(fonts, field.font)
+		}
+	}
+	if len(fonts) > 0 {
+		pdf.appendString("/DR << /Font <<")
+		for _, font := range fonts {
+			pdf.appendString(" /")
+			pdf.appendString(getFontResourceName(font))
+			pdf.appendString(" ")
+			pdf.appendInteger(font.objNumber)
+			pdf.appendString(" 0 R")
+		}
+		pdf.appendString(" >> >>\n")
+	}
+
+	if pdf.signature != nil && pdf.signature.annotation != nil {
+		pdf.appendString("/SigFlags 3\n")
+	}
+	pdf.appendString(">>\n")
+}
@@ -0,0 +1,453 @@
+package pdfjet
+
+/**
+ * woff.go
+ *
+©2025 PDFjet Software
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/edragoev1/pdfjet/src/brotli"
+	"github.com/edragoev1/pdfjet/src/decompressor"
+)
+
+// decodeWebFont returns the sfnt data of WOFF and WOFF2 fonts.
+// The other fonts are returned unchanged.
+func decodeWebFont(buf []byte) ([]byte, error) {
+	if len(buf) < 4 {
+		return buf, nil
+	}
+	switch string(buf[0:4]) {
+	case "wOFF":
+		return decodeWOFF(buf)
+	case "wOF2":
+		return decodeWOFF2(buf)
+	}
+	return buf, nil
+}
+
+// decodeWOFF decodes WOFF 1.0 font - the sfnt tables compressed with zlib.
+func decodeWOFF(buf []byte) (_ []byte, err error) {
+	defer recoverMalformedInput("WOFF font", &err)
+
+	numTables := u16(buf, 12)
+	tables := make(map[string][]byte)
+	for i := 0; i < numTables; i++ {
+		entry := buf[44+20*i:]
+		tag := string(entry[0:4])
+		offset := u32(entry, 4)
+		compLength := u32(entry, 8)
+		origLength := u32(entry, 12)
+		if offset+compLength > len(buf) {
+			return nil, malformed("WOFF font", "%s table is truncated", tag)
+		}
+		data := buf[offset : offset+compLength]
+		if compLength < origLength {
+			data, err = decompressor.InflateErr(data)
+			if err != nil {
+				return nil, malformedWrap("WOFF font", "cannot inflate "+tag+" table", err)
+			}
+		}
+		if len(data) != origLength {
+			return nil, malformed("WOFF font", "%s table length %d, expected %d", tag, len(data), origLength)
+		}
+		tables[tag] = data
+	}
+	return writeWebFont(tables)
+}
+
+// writeWebFont writes the decoded tables as sfnt font.
+func writeWebFont(tables map[string][]byte) ([]byte, error) {
+	head := tables["head"]
+	if len(head) < 54 {
+		return nil, malformed("web font", "missing head table")
+	}
+	head = append([]byte(nil), head...)
+	binary.BigEndian.PutUint32(head[8:], 0) // checkSumAdjustment
+	tables["head"] = head
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return writeTrueType(tables, names, head), nil
+}
+
+// The known table tags of WOFF2 table directory.
+var woff2Tags = []string{
+	"cmap", "head", "hhea", "hmtx", "maxp", "name", "OS/2", "post",
+	"cvt ", "fpgm", "glyf", "loca", "prep", "CFF ", "VORG", "EBDT",
+	"EBLC", "gasp", "hdmx", "kern", "LTSH", "PCLT", "VDMX", "vhea",
+	"vmtx", "BASE", "GDEF", "GPOS", "GSUB", "EBSC", "JSTF", "MATH",
+	"CBDT", "CBLC", "COLR", "CPAL", "SVG ", "sbix", "acnt", "avar",
+	"bdat", "bloc", "bsln", "cvar", "fdsc", "feat", "fmtx", "fvar",
+	"gvar", "hsty", "just", "lcar", "mort", "morx", "opbd", "prop",
+	"trak", "Zapf", "Silf", "Glat", "Gloc", "Feat", "Sill",
+}
+
+// woff2Table is the entry of WOFF2 table directory.
+type woff2Table struct {
+	tag         string
+	transformed bool
+	length      int // The length of the data in the decompressed stream
+	origLength  int
+}
+
+// decodeWOFF2 decodes WOFF2 font - the sfnt tables compressed with Brotli
+// with the glyf, loca and hmtx tables transformed.
+func decodeWOFF2(buf []byte) (_ []byte, err error) {
+	defer recoverMalformedInput("WOFF2 font", &err)
+
+	if string(buf[4:8]) == "ttcf" {
+		return nil, unsupported("WOFF2 font collections")
+	}
+	numTables := u16(buf, 12)
+	totalCompressedSize := u32(buf, 20)
+	pos := 48
+	entries := make([]woff2Table, numTables)
+	for i := range entries {
+		flags := int(buf[pos])
+		pos++
+		entry := &entries[i]
+		if flags&0x3F == 0x3F {
+			entry.tag = string(buf[pos : pos+4])
+			pos += 4
+		} else {
+			entry.tag = woff2Tags[flags&0x3F]
+		}
+		version := flags >> 6
+		entry.origLength, pos, err = readUIntBase128(buf, pos)
+		if err != nil {
+			return nil, err
+		}
+		entry.length = entry.origLength
+		if entry.tag == "glyf" || entry.tag == "loca" {
+			entry.transformed = version == 0
+		} else {
+			entry.transformed = version != 0
+		}
+		if entry.transformed {
+			entry.length, pos, err = readUIntBase128(buf, pos)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if pos+totalCompressedSize > len(buf) {
+		return nil, malformed("WOFF2 font", "compressed data is truncated")
+	}
+	data, err := brotli.DecompressErr(buf[pos : pos+totalCompressedSize])
+	if err != nil {
+		return nil, malformedWrap("WOFF2 font", "cannot decompress the tables", err)
+	}
+
+	tables := make(map[string][]byte)
+	transformed := make(map[string][]byte)
+	offset := 0
+	for _, entry := range entries {
+		table := data[offset : offset+entry.length]
+		offset += entry.length
+		if entry.transformed {
+			transformed[entry.tag] = table
+		} else {
+			tables[entry.tag] = table
+		}
+	}
+	if glyf := transformed["glyf"]; glyf != nil {
+		if err := reconstructGlyf(glyf, tables); err != nil {
+			return nil, err
+		}
+	}
+	if hmtx := transformed["hmtx"]; hmtx != nil {
+		if err := reconstructHmtx(hmtx, tables); err != nil {
+			return nil, err
+		}
+	}
+	for tag := range transformed {
+		if tables[tag] == nil {
+			return nil, unsupported("WOFF2 transform of %s table", tag)
+		}
+	}
+	return writeWebFont(tables)
+}
+
+// readUIntBase128 reads the variable length integer with 7 bits in each byte.
+func readUIntBase128(buf []byte, pos int) (int, int, error) {
+	value := 0
+	for i := 0; i < 5; i++ {
+		b := int(buf[pos])
+		pos++
+		if i == 0 && b == 0x80 {
+			break // Leading zeros are not allowed
+		}
+		value = value<<7 | b&0x7F
+		if b&0x80 == 0 {
+			return value, pos, nil
+		}
+	}
+	return 0, pos, malformed("WOFF2 font", "invalid UIntBase128 value")
+}
+
+// read255UInt16 reads the variable length integer from 0 to 65535.
+func read255UInt16(buf []byte, pos int) (int, int) {
+	code := int(buf[pos])
+	switch code {
+	case 253:
+		return u16(buf, pos+1), pos + 3
+	case 254:
+		return 506 + int(buf[pos+1]), pos + 2
+	case 255:
+		return 253 + int(buf[pos+1]), pos + 2
+	}
+	return code, pos + 1
+}
+
+// reconstructGlyf rebuilds the glyf and loca tables from the transformed glyf table.
+func reconstructGlyf(data []byte, tables map[string][]byte) error {
+	numGlyphs := u16(data, 4)
+	indexFormat := u16(data, 6)
+	var streams [7][]byte
+	pos := 36
+	for i := range streams {
+		size := u32(data, 8+4*i)
+		streams[i] = data[pos : pos+size]
+		pos += size
+	}
+	nContours, nPoints, flagStream, glyphStream := streams[0], streams[1], streams[2], streams[3]
+	compositeStream, bboxStream, instructionStream := streams[4], streams[5], streams[6]
+	var overlapBitmap []byte
+	if u16(data, 2)&1 != 0 {
+		overlapBitmap = data[pos : pos+(numGlyphs+7)/8]
+	}
+	bboxBitmap := bboxStream[:4*((numGlyphs+31)/32)]
+	bboxValues := bboxStream[len(bboxBitmap):]
+	var nPointsPos, flagPos, glyphPos, compositePos, bboxPos, instructionPos int
+
+	glyf := make([]byte, 0, len(data)*2)
+	offsets := make([]int, numGlyphs+1)
+	for gid := 0; gid < numGlyphs; gid++ {
+		offsets[gid] = len(glyf)
+		numberOfContours := s16(nContours, 2*gid)
+		hasBBox := bboxBitmap[gid>>3]&(0x80>>uint(gid&7)) != 0
+		var bbox []byte
+		if hasBBox {
+			bbox = bboxValues[bboxPos : bboxPos+8]
+			bboxPos += 8
+		}
+		if numberOfContours == 0 {
+			if hasBBox {
+				return malformed("WOFF2 font", "empty glyph %d has bounding box", gid)
+			}
+			continue
+		}
+		if numberOfContours < 0 {
+			// The composite glyph is stored as is
+			if !hasBBox {
+				return malformed("WOFF2 font", "composite glyph %d without bounding box", gid)
+			}
+			start := compositePos
+			haveInstructions := false
+			for {
+				flags := u16(compositeStream, compositePos)
+				compositePos += 4
+				if flags&argsAreWords != 0 {
+					compositePos += 4
+				} else {
+					compositePos += 2
+				}
+				if flags&weHaveAScale != 0 {
+					compositePos += 2
+				} else if flags&weHaveXYScale != 0 {
+					compositePos += 4
+				} else if flags&weHaveTwoByTwo != 0 {
+					compositePos += 8
+				}
+				if flags&weHaveInstructions != 0 {
+					haveInstructions = true
+				}
+				if flags&moreComponents == 0 {
+					break
+				}
+			}
+			glyf = binary.BigEndian.AppendUint16(glyf, 0xFFFF)
+			glyf = append(glyf, bbox...)
+			glyf = append(glyf, compositeStream[start:compositePos]...)
+			if haveInstructions {
+				var n int
+				n, glyphPos = read255UInt16(glyphStream, glyphPos)
+				glyf = binary.BigEndian.AppendUint16(glyf, uint16(n))
+				glyf = append(glyf, instructionStream[instructionPos:instructionPos+n]...)
+				instructionPos += n
+			}
+		} else {
+			glyph := &varGlyph{numberOfContours: numberOfContours}
+			glyph.endPts = make([]int, numberOfContours)
+			numPoints := 0
+			for i := range glyph.endPts {
+				var n int
+				n, nPointsPos = read255UInt16(nPoints, nPointsPos)
+				numPoints += n
+				glyph.endPts[i] = numPoints - 1
+			}
+			glyph.flags = make([]byte, numPoints)
+			glyph.xs = make([]float64, numPoints)
+			glyph.ys = make([]float64, numPoints)
+			x, y := 0, 0
+			for i := 0; i < numPoints; i++ {
+				flag := int(flagStream[flagPos])
+				flagPos++
+				var dx, dy int
+				dx, dy, glyphPos = readTriplet(glyphStream, glyphPos, flag&0x7F)
+				x += dx
+				y += dy
+				glyph.xs[i], glyph.ys[i] = float64(x), float64(y)
+				if flag&0x80 == 0 {
+					glyph.flags[i] = onCurvePoint
+				}
+			}
+			if overlapBitmap != nil && numPoints > 0 &&
+				overlapBitmap[gid>>3]&(0x80>>uint(gid&7)) != 0 {
+				glyph.flags[0] |= overlapSimple
+			}
+			var n int
+			n, glyphPos = read255UInt16(glyphStream, glyphPos)
+			glyph.instructions = instructionStream[instructionPos : instructionPos+n]
+			instructionPos += n
+			if hasBBox {
+				glyph.xMin, glyph.yMin = s16(bbox, 0), s16(bbox, 2)
+				glyph.xMax, glyph.yMax = s16(bbox, 4), s16(bbox, 6)
+			} else {
+				getGlyphBounds([]*varGlyph{glyph}, 0, []bool{false})
+			}
+			glyf = append(glyf, encodeGlyph(glyph)...)
+		}
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+	}
+	offsets[numGlyphs] = len(glyf)
+
+	var loca []byte
+	for _, offset := range offsets {
+		if indexFormat == 0 {
+			if offset/2 > 0xFFFF {
+				return malformed("WOFF2 font", "glyf table is too large for short loca format")
+			}
+			loca = binary.BigEndian.AppendUint16(loca, uint16(offset/2))
+		} else {
+			loca = binary.BigEndian.AppendUint32(loca, uint32(offset))
+		}
+	}
+	tables["glyf"] = glyf
+	tables["loca"] = loca
+	return nil
+}
+
+// readTriplet reads the point coordinate deltas encoded with the flag.
+func readTriplet(data []byte, pos, flag int) (int, int, int) {
+	var dx, dy int
+	switch {
+	case flag < 10:
+		dy = withSign(flag, (flag&14)<<7+int(data[pos]))
+		pos++
+	case flag < 20:
+		dx = withSign(flag, ((flag-10)&14)<<7+int(data[pos]))
+		pos++
+	case flag < 84:
+		b0, b1 := flag-20, int(data[pos])
+		dx = withSign(flag, 1+b0&0x30+b1>>4)
+		dy = withSign(flag>>1, 1+(b0&0x0C)<<2+b1&0x0F)
+		pos++
+	case flag < 120:
+		b0 := flag - 84
+		dx = withSign(flag, 1+(b0/12)<<8+int(data[pos]))
+		dy = withSign(flag>>1, 1+((b0%12)>>2)<<8+int(data[pos+1]))
+		pos += 2
+	case flag < 124:
+		b2 := int(data[pos+1])
+		dx = withSign(flag, int(data[pos])<<4+b2>>4)
+		dy = withSign(flag>>1, (b2&0x0F)<<8+int(data[pos+2]))
+		pos += 3
+	default:
+		dx = withSign(flag, u16(data, pos))
+		dy = withSign(flag>>1, u16(data, pos+2))
+		pos += 4
+	}
+	return dx, dy, pos
+}
+
+func withSign(flag, value int) int {
+	if flag&1 != 0 {
+		return value
+	}
+	return -value
+}
+
+// reconstructHmtx rebuilds the hmtx table with the left side bearings
+// that are omitted because they are equal to the glyph xMin.
+func reconstructHmtx(data []byte, tables map[string][]byte) error {
+	glyf, loca, hhea, maxp := tables["glyf"], tables["loca"], tables["hhea"], tables["maxp"]
+	if glyf == nil || loca == nil || hhea == nil || maxp == nil {
+		return malformed("WOFF2 font", "transformed hmtx table without glyf, loca, hhea or maxp table")
+	}
+	flags := data[0]
+	numGlyphs := u16(maxp, 4)
+	numberOfHMetrics := u16(hhea, 34)
+	longOffsets := len(loca) == 4*(numGlyphs+1)
+	xMin := func(gid int) int {
+		var start, end int
+		if longOffsets {
+			start, end = u32(loca, 4*gid), u32(loca, 4*gid+4)
+		} else {
+			start, end = 2*u16(loca, 2*gid), 2*u16(loca, 2*gid+2)
+		}
+		if start == end {
+			return 0
+		}
+		return s16(glyf, start+2)
+	}
+	pos := 1
+	advances := data[pos : pos+2*numberOfHMetrics]
+	pos += 2 * numberOfHMetrics
+	hmtx := make([]byte, 0, 4*numberOfHMetrics+2*(numGlyphs-numberOfHMetrics))
+	for gid := 0; gid < numberOfHMetrics; gid++ {
+		hmtx = append(hmtx, advances[2*gid:2*gid+2]...)
+		if flags&1 != 0 {
+			hmtx = binary.BigEndian.AppendUint16(hmtx, uint16(int16(xMin(gid))))
+		} else {
+			hmtx = append(hmtx, data[pos:pos+2]...)
+			pos += 2
+		}
+	}
+	for gid := numberOfHMetrics; gid < numGlyphs; gid++ {
+		if flags&2 != 0 {
+			hmtx = binary.BigEndian.AppendUint16(hmtx, uint16(int16(xMin(gid))))
+		} else {
+			hmtx = append(hmtx, data[pos:pos+2]...)
+			pos += 2
+		}
+	}
+	tables["hmtx"] = hmtx
+	return nil
+}